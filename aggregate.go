@@ -0,0 +1,48 @@
+package gontext
+
+import (
+	"github.com/shepherrrd/gontext/internal/linq"
+)
+
+// AggregateExpr is an aggregate column for LinqDbSet.Aggregate
+type AggregateExpr = linq.AggregateExpr
+
+// Count - COUNT(field), use "*" to count rows
+func Count(fieldName string) AggregateExpr {
+	return linq.Count(fieldName)
+}
+
+// CountDistinctOf - COUNT(DISTINCT field)
+func CountDistinctOf(fieldName string) AggregateExpr {
+	return linq.CountDistinctOf(fieldName)
+}
+
+// Sum - SUM(field)
+func Sum(fieldName string) AggregateExpr {
+	return linq.Sum(fieldName)
+}
+
+// Average - AVG(field)
+func Average(fieldName string) AggregateExpr {
+	return linq.Average(fieldName)
+}
+
+// Min - MIN(field)
+func Min(fieldName string) AggregateExpr {
+	return linq.Min(fieldName)
+}
+
+// Max - MAX(field)
+func Max(fieldName string) AggregateExpr {
+	return linq.Max(fieldName)
+}
+
+// MinOf returns the smallest value of a field as its Go type: gontext.MinOf[Post, time.Time](ctx.Posts, "CreatedAt")
+func MinOf[T any, R any](ds *LinqDbSet[T], fieldName string) (R, error) {
+	return linq.MinOf[T, R](ds, fieldName)
+}
+
+// MaxOf returns the largest value of a field as its Go type: gontext.MaxOf[Order, float64](ctx.Orders, "Total")
+func MaxOf[T any, R any](ds *LinqDbSet[T], fieldName string) (R, error) {
+	return linq.MaxOf[T, R](ds, fieldName)
+}
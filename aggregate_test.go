@@ -0,0 +1,111 @@
+package gontext_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shepherrrd/gontext"
+)
+
+type aggregateOrder struct {
+	Id        int
+	Customer  string
+	Total     float64
+	Items     int
+	CreatedAt time.Time
+	ShippedAt *time.Time
+}
+
+func newAggregateOrders(t *testing.T) *gontext.LinqDbSet[aggregateOrder] {
+	t.Helper()
+	ctx, err := gontext.NewDbContext(":memory:", "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ctx.Close() })
+	orders := gontext.RegisterEntity[aggregateOrder](ctx)
+	if err := ctx.EnsureCreated(); err != nil {
+		t.Fatal(err)
+	}
+
+	base := time.Date(2024, 3, 1, 12, 30, 15, 500, time.UTC)
+	for i := 1; i <= 3; i++ {
+		shipped := base.Add(time.Duration(i) * 24 * time.Hour)
+		order := aggregateOrder{Id: i, Customer: string(rune('a' + i)), Total: float64(i) * 10.5, Items: i * 2,
+			CreatedAt: base.Add(time.Duration(i) * time.Hour), ShippedAt: &shipped}
+		if err := ctx.GetDB().Create(&order).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return orders
+}
+
+func TestMinMaxOfTimes(t *testing.T) {
+	orders := newAggregateOrders(t)
+	base := time.Date(2024, 3, 1, 12, 30, 15, 500, time.UTC)
+
+	first, err := gontext.MinOf[aggregateOrder, time.Time](orders, "CreatedAt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !first.Equal(base.Add(time.Hour)) {
+		t.Errorf("MinOf CreatedAt = %v, want %v", first, base.Add(time.Hour))
+	}
+
+	last, err := gontext.MaxOf[aggregateOrder, time.Time](orders, "ShippedAt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !last.Equal(base.Add(72 * time.Hour)) {
+		t.Errorf("MaxOf ShippedAt = %v, want %v", last, base.Add(72*time.Hour))
+	}
+
+	pointer, err := gontext.MaxOf[aggregateOrder, *time.Time](orders, "CreatedAt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pointer == nil || !pointer.Equal(base.Add(3*time.Hour)) {
+		t.Errorf("MaxOf CreatedAt as *time.Time = %v, want %v", pointer, base.Add(3*time.Hour))
+	}
+}
+
+func TestMinMaxOfScalars(t *testing.T) {
+	orders := newAggregateOrders(t)
+
+	total, err := gontext.MaxOf[aggregateOrder, float64](orders, "Total")
+	if err != nil || total != 31.5 {
+		t.Errorf("MaxOf Total = %v, %v; want 31.5", total, err)
+	}
+	items, err := gontext.MinOf[aggregateOrder, int](orders, "Items")
+	if err != nil || items != 2 {
+		t.Errorf("MinOf Items = %v, %v; want 2", items, err)
+	}
+	widened, err := gontext.MaxOf[aggregateOrder, int64](orders, "Items")
+	if err != nil || widened != 6 {
+		t.Errorf("MaxOf Items as int64 = %v, %v; want 6", widened, err)
+	}
+	asFloat, err := gontext.MaxOf[aggregateOrder, float64](orders, "Items")
+	if err != nil || asFloat != 6 {
+		t.Errorf("MaxOf Items as float64 = %v, %v; want 6", asFloat, err)
+	}
+	customer, err := gontext.MaxOf[aggregateOrder, string](orders, "Customer")
+	if err != nil || customer != "d" {
+		t.Errorf("MaxOf Customer = %q, %v; want d", customer, err)
+	}
+	filtered, err := gontext.MaxOf[aggregateOrder, float64](orders.Where("Items < ?", 5), "Total")
+	if err != nil || filtered != 21 {
+		t.Errorf("MaxOf filtered Total = %v, %v; want 21", filtered, err)
+	}
+}
+
+func TestMinMaxOfEmpty(t *testing.T) {
+	orders := newAggregateOrders(t).Where("Items > ?", 100)
+
+	if _, err := gontext.MinOf[aggregateOrder, time.Time](orders, "CreatedAt"); !errors.Is(err, gontext.ErrNotFound) {
+		t.Errorf("MinOf of no rows: %v, want ErrNotFound", err)
+	}
+	if _, err := gontext.MaxOf[aggregateOrder, float64](orders, "Total"); !errors.Is(err, gontext.ErrNotFound) {
+		t.Errorf("MaxOf of no rows: %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,21 @@
+package gontext
+
+import (
+	"github.com/shepherrrd/gontext/internal/audit"
+	"github.com/shepherrrd/gontext/internal/query"
+)
+
+// SQLFinding is a raw SQL call site reported by LintRawSQL
+type SQLFinding = audit.Finding
+
+// LintRawSQL lists every raw SQL call site (Where, Or, Having, Joins, Raw, Exec, ...) in the Go files
+// under dir - literal SQL is checked for inline literals and comments, runtime-built SQL is flagged Dynamic
+// Usage: findings, err := gontext.LintRawSQL("."); for _, f := range findings { if f.Unsafe() { log.Println(f) } }
+func LintRawSQL(dir string) ([]SQLFinding, error) {
+	return audit.LintRawSQL(dir)
+}
+
+// AuditSQL reports inline literals, statement separators and comments in a raw SQL fragment
+func AuditSQL(sql string) []string {
+	return query.AuditSQL(sql)
+}
@@ -0,0 +1,259 @@
+package gontext_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/shepherrrd/gontext"
+)
+
+type batchProduct struct {
+	Id         int
+	Name       string
+	Price      float64
+	Stock      int
+	Tags       []string `gorm:"serializer:json"`
+	Revision   int
+	UpdatedAt  time.Time
+	CategoryId *int
+}
+
+// BeforeUpdate must run once per saved product, batched or not
+func (p *batchProduct) BeforeUpdate(tx *gorm.DB) error {
+	p.Revision++
+	return nil
+}
+
+type batchCategory struct {
+	Id   int
+	Name string
+}
+
+type batchAudited struct {
+	Id   int
+	Name string
+}
+
+var auditedUpdates int
+
+// AfterUpdate must follow the row's own write, so audited entities are not batched
+func (a *batchAudited) AfterUpdate(tx *gorm.DB) error {
+	auditedUpdates++
+	return nil
+}
+
+// statementRecorder records the SQL of the statements that wrote rows; the dry runs batched updates
+// are built from are logged too, but never affect any
+type statementRecorder struct {
+	mu         sync.Mutex
+	statements []string
+}
+
+func (r *statementRecorder) LogMode(logger.LogLevel) logger.Interface      { return r }
+func (r *statementRecorder) Info(context.Context, string, ...interface{})  {}
+func (r *statementRecorder) Warn(context.Context, string, ...interface{})  {}
+func (r *statementRecorder) Error(context.Context, string, ...interface{}) {}
+func (r *statementRecorder) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	sql, rows := fc()
+	if rows == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statements = append(r.statements, sql)
+}
+
+// count returns how many recorded statements start with verb
+func (r *statementRecorder) count(verb string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, sql := range r.statements {
+		if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(sql)), verb) {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *statementRecorder) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statements = nil
+}
+
+type batchContext struct {
+	ctx        *gontext.DbContext
+	products   *gontext.LinqDbSet[batchProduct]
+	categories *gontext.LinqDbSet[batchCategory]
+	audited    *gontext.LinqDbSet[batchAudited]
+	recorder   *statementRecorder
+}
+
+// newBatchContext returns an in-memory context with 5 products and 3 audited rows
+func newBatchContext(t *testing.T) *batchContext {
+	t.Helper()
+	ctx, err := gontext.NewDbContext(":memory:", "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ctx.Close() })
+	bc := &batchContext{
+		ctx:        ctx,
+		products:   gontext.RegisterEntity[batchProduct](ctx),
+		categories: gontext.RegisterEntity[batchCategory](ctx),
+		audited:    gontext.RegisterEntity[batchAudited](ctx),
+		recorder:   &statementRecorder{},
+	}
+	if err := ctx.EnsureCreated(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 5; i++ {
+		product := batchProduct{Id: i, Name: "product", Price: 10, Stock: 1, Tags: []string{"new"}}
+		if err := ctx.GetDB().Create(&product).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 1; i <= 3; i++ {
+		if err := ctx.GetDB().Create(&batchAudited{Id: i, Name: "audited"}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx.GetDB().Logger = bc.recorder
+	return bc
+}
+
+func (bc *batchContext) loadProducts(t *testing.T) []batchProduct {
+	t.Helper()
+	products, err := bc.products.OrderBy("Id").ToList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return products
+}
+
+func TestSaveChangesBatchesUpdates(t *testing.T) {
+	bc := newBatchContext(t)
+	products := bc.loadProducts(t)
+	before := products[0].UpdatedAt
+
+	products[0].Name = "renamed"
+	products[1].Price = 12.5
+	products[2].Tags = []string{"sale", "new"}
+	products[3].Name, products[3].Stock = "both", 7
+	products[4].Stock = 99
+	bc.ctx.MarkModified(&products[4], "Stock")
+	bc.recorder.reset()
+
+	if err := bc.ctx.SaveChanges(); err != nil {
+		t.Fatal(err)
+	}
+	if updates := bc.recorder.count("UPDATE"); updates != 1 {
+		t.Errorf("sent %d UPDATE statements, want 1", updates)
+	}
+
+	saved := bc.loadProducts(t)
+	want := []struct {
+		name  string
+		price float64
+		stock int
+		tags  string
+	}{
+		{"renamed", 10, 1, "new"},
+		{"product", 12.5, 1, "new"},
+		{"product", 10, 1, "sale,new"},
+		{"both", 10, 7, "new"},
+		{"product", 10, 99, "new"},
+	}
+	for i, product := range saved {
+		if product.Name != want[i].name || product.Price != want[i].price || product.Stock != want[i].stock ||
+			strings.Join(product.Tags, ",") != want[i].tags {
+			t.Errorf("product %d saved as %+v, want %+v", product.Id, product, want[i])
+		}
+	}
+	for _, product := range saved[:4] {
+		if product.Revision != 1 {
+			t.Errorf("product %d revision %d, want BeforeUpdate to run once", product.Id, product.Revision)
+		}
+	}
+	if !saved[0].UpdatedAt.After(before) {
+		t.Errorf("UpdatedAt %v not advanced past %v", saved[0].UpdatedAt, before)
+	}
+}
+
+func TestSaveChangesBatchesMixedChanges(t *testing.T) {
+	bc := newBatchContext(t)
+	products := bc.loadProducts(t)
+	for i := range products[:3] {
+		products[i].Price += float64(i + 1)
+	}
+	bc.products.Remove(products[3])
+	bc.products.Remove(products[4])
+	for i := 1; i <= 3; i++ {
+		if _, err := bc.categories.Add(batchCategory{Id: i, Name: "category"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bc.recorder.reset()
+
+	if err := bc.ctx.SaveChanges(); err != nil {
+		t.Fatal(err)
+	}
+	for verb, want := range map[string]int{"INSERT": 1, "UPDATE": 1, "DELETE": 1} {
+		if got := bc.recorder.count(verb); got != want {
+			t.Errorf("sent %d %s statements, want %d", got, verb, want)
+		}
+	}
+
+	saved := bc.loadProducts(t)
+	if len(saved) != 3 || saved[0].Price != 11 || saved[1].Price != 12 || saved[2].Price != 13 {
+		t.Errorf("saved products %+v", saved)
+	}
+	if count, err := bc.categories.Count(); err != nil || count != 3 {
+		t.Errorf("saved %d categories, %v; want 3", count, err)
+	}
+}
+
+func TestSaveChangesBatchedUpdateRestoresMissingRow(t *testing.T) {
+	bc := newBatchContext(t)
+	products := bc.loadProducts(t)
+	products[0].Name = "kept"
+	products[1].Name = "restored"
+	if err := bc.ctx.GetDB().Exec(`DELETE FROM batch_products WHERE id = ?`, products[1].Id).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bc.ctx.SaveChanges(); err != nil {
+		t.Fatal(err)
+	}
+	saved := bc.loadProducts(t)
+	if len(saved) != 5 || saved[0].Name != "kept" || saved[1].Name != "restored" {
+		t.Errorf("saved products %+v, want the deleted row saved again like Save does", saved)
+	}
+}
+
+func TestSaveChangesUpdatesAfterHookTypesOneByOne(t *testing.T) {
+	bc := newBatchContext(t)
+	audited, err := bc.audited.ToList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range audited {
+		audited[i].Name = "changed"
+	}
+	auditedUpdates = 0
+	bc.recorder.reset()
+
+	if err := bc.ctx.SaveChanges(); err != nil {
+		t.Fatal(err)
+	}
+	if updates := bc.recorder.count("UPDATE"); updates != 3 || auditedUpdates != 3 {
+		t.Errorf("sent %d UPDATE statements and ran AfterUpdate %d times, want 3 each", updates, auditedUpdates)
+	}
+}
@@ -0,0 +1,31 @@
+package gontext
+
+import (
+	"github.com/shepherrrd/gontext/internal/cache"
+)
+
+// QueryCacheProvider stores the results of Cached() queries; see DbContextOptions.QueryCache
+type QueryCacheProvider = cache.Provider
+
+// QueryCacheStats reports query cache hits, misses, shared loads and provider errors
+type QueryCacheStats = cache.Stats
+
+// MemoryCache is an in-process query cache provider
+type MemoryCache = cache.Memory
+
+// RedisCache is a query cache provider shared by every instance of an application
+type RedisCache = cache.Redis
+
+// RedisCacheOptions configures the Redis address, credentials, key prefix and local near-cache
+type RedisCacheOptions = cache.RedisOptions
+
+// NewMemoryCache creates an in-process provider keeping up to capacity results (0 uses the default)
+func NewMemoryCache(capacity int) *MemoryCache {
+	return cache.NewMemory(capacity)
+}
+
+// NewRedisCache creates a Redis provider; close it when the application stops
+// Usage: gontext.DbContextOptions{QueryCache: gontext.NewRedisCache(gontext.RedisCacheOptions{Addr: "redis:6379"})}
+func NewRedisCache(options RedisCacheOptions) *RedisCache {
+	return cache.NewRedis(options)
+}
@@ -0,0 +1,41 @@
+package gontext
+
+import (
+	stdcontext "context"
+
+	"github.com/shepherrrd/gontext/internal/changefeed"
+)
+
+// ChangeFeed reads committed row changes from a PostgreSQL logical replication slot; see DbContext.ChangeFeed
+type ChangeFeed = changefeed.Feed
+
+// ChangeFeedOptions configures the replication slot, batch size and poll interval of a change feed
+type ChangeFeedOptions = changefeed.Options
+
+// Change is a row change by column name, as read from the WAL
+type Change = changefeed.Change
+
+// ChangeAction is Created, Updated or Deleted
+type ChangeAction = changefeed.Action
+
+const (
+	ChangeCreated = changefeed.Created
+	ChangeUpdated = changefeed.Updated
+	ChangeDeleted = changefeed.Deleted
+)
+
+// ChangeEvent is a change of an entity of type T
+type ChangeEvent[T any] = changefeed.Event[T]
+
+// OnChange calls handler with every Created, Updated or Deleted change of T's table, decoded into a T:
+//
+//	feed, _ := ctx.ChangeFeed(gontext.ChangeFeedOptions{Slot: "search_indexer"})
+//	gontext.OnChange(feed, func(c context.Context, e gontext.ChangeEvent[User]) error {
+//	    return cache.Delete(c, fmt.Sprintf("user:%d", e.Entity.Id))
+//	})
+//	go feed.Run(appContext)
+//
+// A handler error stops the feed before its transaction is acknowledged, so it is delivered again
+func OnChange[T any](feed *ChangeFeed, handler func(ctx stdcontext.Context, event ChangeEvent[T]) error) error {
+	return changefeed.Subscribe(feed, handler)
+}
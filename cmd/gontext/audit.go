@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/shepherrrd/gontext/internal/audit"
+)
+
+func handleAuditCommands() {
+	if len(os.Args) < 3 {
+		fmt.Println("Audit command requires a subcommand")
+		showAuditUsage()
+		os.Exit(1)
+	}
+
+	switch os.Args[2] {
+	case "sql":
+		auditSQL()
+	default:
+		fmt.Printf("Unknown audit subcommand: %s\n\n", os.Args[2])
+		showAuditUsage()
+		os.Exit(1)
+	}
+}
+
+// auditSQL lists raw SQL call sites for a security review; exits 1 when any need attention
+func auditSQL() {
+	dir := "."
+	all := false
+	for _, arg := range os.Args[3:] {
+		switch arg {
+		case "--all":
+			all = true
+		default:
+			dir = arg
+		}
+	}
+
+	findings, err := audit.LintRawSQL(dir)
+	if err != nil {
+		fmt.Printf("❌ Failed to scan %s: %v\n", dir, err)
+		os.Exit(1)
+	}
+
+	unsafe := 0
+	for _, finding := range findings {
+		if finding.Unsafe() {
+			unsafe++
+		} else if !all {
+			continue
+		}
+		fmt.Println(finding)
+	}
+
+	if unsafe > 0 {
+		fmt.Printf("\n⚠️  %d of %d raw SQL call sites need review\n", unsafe, len(findings))
+		os.Exit(1)
+	}
+	fmt.Printf("✅ %d raw SQL call sites, no inline literals or dynamic SQL found\n", len(findings))
+}
+
+func showAuditUsage() {
+	fmt.Println("Audit Commands:")
+	fmt.Println("  audit sql [dir] [--all]  Report raw SQL call sites with inline literals or dynamic SQL")
+}
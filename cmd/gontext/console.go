@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/shepherrrd/gontext"
+	"github.com/shepherrrd/gontext/internal/console"
+	"github.com/shepherrrd/gontext/internal/models"
+)
+
+// runConsole opens the query console against DATABASE_URL
+// Entities come from migrations/ModelSnapshot.json since the CLI cannot load the project's Go types
+func runConsole() {
+	connectionString := getDatabaseConnection()
+	if connectionString == "" {
+		fmt.Println("❌ Database connection not found")
+		fmt.Println("   Set DATABASE_URL or add it to .env")
+		os.Exit(1)
+	}
+
+	ctx, err := gontext.NewDbContext(connectionString, "postgres")
+	if err != nil {
+		fmt.Printf("❌ Error creating database context: %v\n", err)
+		os.Exit(1)
+	}
+	defer ctx.Close()
+
+	c := console.New(ctx, os.Stdin, os.Stdout)
+	if wd, err := os.Getwd(); err == nil {
+		if projectRoot, err := findProjectRoot(wd); err == nil {
+			if data, err := os.ReadFile(filepath.Join(projectRoot, "migrations", "ModelSnapshot.json")); err == nil {
+				var snapshot models.ModelSnapshot
+				if err := json.Unmarshal(data, &snapshot); err == nil {
+					c.WithEntities(snapshot.Entities)
+					fmt.Printf("📋 Loaded %d entities from the model snapshot\n", len(snapshot.Entities))
+				}
+			}
+		}
+	}
+
+	if err := c.Run(); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,515 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/shepherrrd/gontext"
+	"github.com/shepherrrd/gontext/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func handleDataCommands() {
+	if len(os.Args) < 3 {
+		fmt.Println("Data command requires a subcommand")
+		showDataUsage()
+		os.Exit(1)
+	}
+
+	subcommand := os.Args[2]
+
+	switch subcommand {
+	case "export":
+		if len(os.Args) < 4 || strings.HasPrefix(os.Args[3], "-") {
+			fmt.Println("Data export requires an entity name")
+			fmt.Println("Usage: go run github.com/shepherrrd/gontext/cmd/gontext data export <entity> [--format csv|jsonl] [--where \"...\"] [--output file]")
+			os.Exit(1)
+		}
+		exportData(os.Args[3], os.Args[4:])
+	case "import":
+		if len(os.Args) < 5 || strings.HasPrefix(os.Args[3], "-") || strings.HasPrefix(os.Args[4], "-") {
+			fmt.Println("Data import requires an entity name and a file")
+			fmt.Println("Usage: go run github.com/shepherrrd/gontext/cmd/gontext data import <entity> <file.csv|file.jsonl> [--on-conflict error|ignore|update]")
+			os.Exit(1)
+		}
+		importData(os.Args[3], os.Args[4], os.Args[5:])
+	default:
+		fmt.Printf("Unknown data subcommand: %s\n\n", subcommand)
+		showDataUsage()
+		os.Exit(1)
+	}
+}
+
+// dataTable is the table behind an entity, resolved from the model snapshot
+type dataTable struct {
+	Name      string
+	KeyColumn string
+	Columns   map[string]models.FieldSnapshot // Column name -> field, empty when there is no snapshot
+}
+
+// resolveDataTable finds the entity in migrations/ModelSnapshot.json by entity or table name
+// Without a snapshot entry the argument is used as the table name
+func resolveDataTable(entity, keyColumn string) (*dataTable, error) {
+	table := &dataTable{Name: entity, KeyColumn: keyColumn, Columns: map[string]models.FieldSnapshot{}}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("error getting working directory: %w", err)
+	}
+	if projectRoot, err := findProjectRoot(wd); err == nil {
+		if data, err := os.ReadFile(filepath.Join(projectRoot, "migrations", "ModelSnapshot.json")); err == nil {
+			var snapshot models.ModelSnapshot
+			if err := json.Unmarshal(data, &snapshot); err != nil {
+				return nil, fmt.Errorf("failed to read model snapshot: %w", err)
+			}
+			for _, entitySnapshot := range snapshot.Entities {
+				if !strings.EqualFold(entitySnapshot.Name, entity) && !strings.EqualFold(entitySnapshot.TableName, entity) {
+					continue
+				}
+				table.Name = entitySnapshot.TableName
+				for _, field := range entitySnapshot.Fields {
+					table.Columns[field.ColumnName] = field
+					if field.IsPrimary && table.KeyColumn == "" {
+						table.KeyColumn = field.ColumnName
+					}
+				}
+				break
+			}
+		}
+	}
+
+	if table.KeyColumn == "" {
+		return nil, fmt.Errorf("no primary key known for %s; run from a project with migrations/ModelSnapshot.json or pass --key", entity)
+	}
+	return table, nil
+}
+
+// exportData streams a table as CSV or JSON lines, one keyset page at a time
+// Ctrl+C stops after the current row; everything written so far stays valid
+func exportData(entity string, args []string) {
+	flags := flag.NewFlagSet("data export", flag.ExitOnError)
+	format := flags.String("format", "csv", "Output format: csv or jsonl")
+	where := flags.String("where", "", "SQL condition to filter rows")
+	output := flags.String("output", "", "Output file (default stdout)")
+	keyColumn := flags.String("key", "", "Column used for keyset pagination (default primary key)")
+	batchSize := flags.Int("batch-size", 1000, "Rows fetched per query")
+	flags.Parse(args)
+
+	if *format != "csv" && *format != "jsonl" {
+		fmt.Fprintf(os.Stderr, "❌ Unknown format %q (use csv or jsonl)\n", *format)
+		os.Exit(1)
+	}
+	if *batchSize <= 0 {
+		*batchSize = 1000
+	}
+
+	connectionString := getDatabaseConnection()
+	if connectionString == "" {
+		fmt.Fprintln(os.Stderr, "❌ Database connection not found")
+		os.Exit(1)
+	}
+
+	table, err := resolveDataTable(entity, *keyColumn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, err := gontext.NewDbContext(connectionString, "postgres")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error creating database context: %v\n", err)
+		os.Exit(1)
+	}
+	defer ctx.Close()
+
+	out := io.Writer(os.Stdout)
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Error creating %s: %v\n", *output, err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	cancelCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	count, err := streamTable(ctx.GetDB().WithContext(cancelCtx), table, *where, *format, *batchSize, out)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			fmt.Fprintf(os.Stderr, "⚠️ Export cancelled after %d rows\n", count)
+		} else {
+			fmt.Fprintf(os.Stderr, "❌ Error exporting %s after %d rows: %v\n", table.Name, count, err)
+		}
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(os.Stderr, "✅ Exported %d rows from %s\n", count, table.Name)
+}
+
+// streamTable writes every matching row, paging with WHERE key > last ORDER BY key LIMIT batchSize
+func streamTable(db *gorm.DB, table *dataTable, where, format string, batchSize int, out io.Writer) (int, error) {
+	quotedTable := db.Statement.Quote(table.Name)
+	quotedKey := db.Statement.Quote(table.KeyColumn)
+
+	csvWriter := csv.NewWriter(out)
+	jsonEncoder := json.NewEncoder(out)
+	defer csvWriter.Flush()
+
+	var lastKey interface{}
+	var columns []string
+	count := 0
+	for {
+		if err := db.Statement.Context.Err(); err != nil {
+			return count, err
+		}
+
+		conditions := []string{}
+		var args []interface{}
+		if where != "" {
+			conditions = append(conditions, "("+where+")")
+		}
+		if lastKey != nil {
+			conditions = append(conditions, quotedKey+" > ?")
+			args = append(args, lastKey)
+		}
+		sql := "SELECT * FROM " + quotedTable
+		if len(conditions) > 0 {
+			sql += " WHERE " + strings.Join(conditions, " AND ")
+		}
+		sql += fmt.Sprintf(" ORDER BY %s LIMIT %d", quotedKey, batchSize)
+
+		rows, err := db.Raw(sql, args...).Rows()
+		if err != nil {
+			return count, err
+		}
+
+		pageRows := 0
+		for rows.Next() {
+			if columns == nil {
+				if columns, err = rows.Columns(); err != nil {
+					rows.Close()
+					return count, err
+				}
+				if format == "csv" {
+					if err := csvWriter.Write(columns); err != nil {
+						rows.Close()
+						return count, err
+					}
+				}
+			}
+
+			values := make([]interface{}, len(columns))
+			pointers := make([]interface{}, len(columns))
+			for i := range values {
+				pointers[i] = &values[i]
+			}
+			if err := rows.Scan(pointers...); err != nil {
+				rows.Close()
+				return count, err
+			}
+
+			record := make(map[string]interface{}, len(columns))
+			fields := make([]string, len(columns))
+			for i, column := range columns {
+				value := exportValue(values[i])
+				record[column] = value
+				fields[i] = csvValue(value)
+				if column == table.KeyColumn {
+					lastKey = values[i]
+				}
+			}
+
+			if format == "csv" {
+				err = csvWriter.Write(fields)
+			} else {
+				err = jsonEncoder.Encode(record)
+			}
+			if err != nil {
+				rows.Close()
+				return count, err
+			}
+			count++
+			pageRows++
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return count, err
+		}
+
+		csvWriter.Flush()
+		if err := csvWriter.Error(); err != nil {
+			return count, err
+		}
+		if pageRows < batchSize {
+			return count, nil
+		}
+		if lastKey == nil {
+			return count, fmt.Errorf("key column %s not found in %s", table.KeyColumn, table.Name)
+		}
+	}
+}
+
+// exportValue converts driver values into JSON/CSV friendly values
+func exportValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return v
+	}
+}
+
+// csvValue renders a value as a CSV field; NULL becomes an empty field
+func csvValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+func showDataUsage() {
+	fmt.Println("Data Commands:")
+	fmt.Println("  data export <entity>    Stream a table as CSV or JSON lines")
+	fmt.Println("      --format csv|jsonl  Output format (default csv)")
+	fmt.Println("      --where \"...\"       SQL condition, e.g. --where \"\\\"IsActive\\\" = true\"")
+	fmt.Println("      --output <file>     Write to a file instead of stdout")
+	fmt.Println("      --key <column>      Keyset pagination column (default primary key)")
+	fmt.Println("      --batch-size <n>    Rows per query (default 1000)")
+	fmt.Println("  data import <entity> <file>  Load CSV or JSON lines rows")
+	fmt.Println("      --on-conflict <mode>     error (default), ignore or update existing rows")
+	fmt.Println("      --key <column>           Conflict column (default primary key)")
+	fmt.Println("      --batch-size <n>         Rows per INSERT (default 500)")
+}
+
+// importRow is one parsed input row and the line it came from
+type importRow struct {
+	line   int
+	values map[string]interface{}
+}
+
+// importData bulk-loads a CSV or JSON lines file, inserting batches with ON CONFLICT handling
+// A failing batch is retried row by row so every bad row is reported with its line number
+func importData(entity, path string, args []string) {
+	flags := flag.NewFlagSet("data import", flag.ExitOnError)
+	onConflict := flags.String("on-conflict", "error", "Conflict handling: error, ignore or update")
+	format := flags.String("format", "", "Input format: csv or jsonl (default from the file extension)")
+	keyColumn := flags.String("key", "", "Conflict column (default primary key)")
+	batchSize := flags.Int("batch-size", 500, "Rows per INSERT")
+	flags.Parse(args)
+
+	switch *onConflict {
+	case "error", "ignore", "update":
+	default:
+		fmt.Printf("❌ Unknown --on-conflict mode %q (use error, ignore or update)\n", *onConflict)
+		os.Exit(1)
+	}
+	if *format == "" {
+		*format = "csv"
+		if ext := strings.ToLower(filepath.Ext(path)); ext == ".jsonl" || ext == ".ndjson" || ext == ".json" {
+			*format = "jsonl"
+		}
+	}
+	if *batchSize <= 0 {
+		*batchSize = 500
+	}
+
+	connectionString := getDatabaseConnection()
+	if connectionString == "" {
+		fmt.Println("❌ Database connection not found")
+		os.Exit(1)
+	}
+
+	table, err := resolveDataTable(entity, *keyColumn)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		fmt.Printf("❌ Error opening %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	defer file.Close()
+
+	var rows []importRow
+	if *format == "jsonl" {
+		rows, err = readJSONLines(file)
+	} else {
+		rows, err = readCSV(file, table)
+	}
+	if err != nil {
+		fmt.Printf("❌ Error reading %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	if err := validateImportColumns(rows, table); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, err := gontext.NewDbContext(connectionString, "postgres")
+	if err != nil {
+		fmt.Printf("❌ Error creating database context: %v\n", err)
+		os.Exit(1)
+	}
+	defer ctx.Close()
+
+	cancelCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	db := ctx.GetDB().WithContext(cancelCtx)
+
+	imported, failed := 0, 0
+	for start := 0; start < len(rows); start += *batchSize {
+		if cancelCtx.Err() != nil {
+			fmt.Printf("⚠️ Import cancelled after %d rows\n", imported)
+			os.Exit(1)
+		}
+
+		end := start + *batchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		batch := rows[start:end]
+
+		values := make([]map[string]interface{}, len(batch))
+		for i, row := range batch {
+			values[i] = row.values
+		}
+		if err := insertRows(db, table, *onConflict, values); err == nil {
+			imported += len(batch)
+			continue
+		}
+
+		// Find the offending rows
+		for _, row := range batch {
+			if err := insertRows(db, table, *onConflict, []map[string]interface{}{row.values}); err != nil {
+				fmt.Printf("❌ Line %d: %v\n", row.line, err)
+				failed++
+				continue
+			}
+			imported++
+		}
+	}
+
+	fmt.Printf("✅ Imported %d rows into %s", imported, table.Name)
+	if failed > 0 {
+		fmt.Printf(", %d failed\n", failed)
+		os.Exit(1)
+	}
+	fmt.Println()
+}
+
+// insertRows inserts rows into the table, skipping or updating rows whose key already exists
+func insertRows(db *gorm.DB, table *dataTable, onConflict string, rows []map[string]interface{}) error {
+	query := db.Table(table.Name)
+	switch onConflict {
+	case "ignore":
+		query = query.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: table.KeyColumn}}, DoNothing: true})
+	case "update":
+		seen := map[string]bool{table.KeyColumn: true}
+		var updates []string
+		for _, row := range rows {
+			for column := range row {
+				if !seen[column] {
+					seen[column] = true
+					updates = append(updates, column)
+				}
+			}
+		}
+		sort.Strings(updates)
+		query = query.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: table.KeyColumn}}, DoUpdates: clause.AssignmentColumns(updates)})
+	}
+	return query.Create(rows).Error
+}
+
+// validateImportColumns rejects columns the entity does not map, before any row is written
+func validateImportColumns(rows []importRow, table *dataTable) error {
+	if len(table.Columns) == 0 {
+		return nil // No snapshot entry - the database reports unknown columns
+	}
+	for _, row := range rows {
+		for column := range row.values {
+			if _, exists := table.Columns[column]; !exists {
+				return fmt.Errorf("line %d: column %q is not mapped by %s", row.line, column, table.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// readCSV parses a CSV file whose header row names the columns
+// Empty fields become NULL for nullable columns
+func readCSV(r io.Reader, table *dataTable) ([]importRow, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	var rows []importRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		values := make(map[string]interface{}, len(header))
+		for i, column := range header {
+			if record[i] == "" && table.Columns[column].IsNullable {
+				values[column] = nil
+			} else {
+				values[column] = record[i]
+			}
+		}
+		rows = append(rows, importRow{line: line, values: values})
+	}
+}
+
+// readJSONLines parses one JSON object per line
+func readJSONLines(r io.Reader) ([]importRow, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+
+	var rows []importRow
+	for line := 1; ; line++ {
+		var values map[string]interface{}
+		if err := decoder.Decode(&values); err == io.EOF {
+			return rows, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		for column, value := range values {
+			switch v := value.(type) {
+			case json.Number:
+				values[column] = v.String()
+			case map[string]interface{}, []interface{}:
+				encoded, _ := json.Marshal(v) // JSON columns
+				values[column] = string(encoded)
+			}
+		}
+		rows = append(rows, importRow{line: line, values: values})
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shepherrrd/gontext/internal/discovery"
+	"github.com/shepherrrd/gontext/internal/scaffold"
+)
+
+func handleGenCommands() {
+	if len(os.Args) < 3 {
+		fmt.Println("Gen command requires a subcommand")
+		showGenUsage()
+		os.Exit(1)
+	}
+
+	switch os.Args[2] {
+	case "repository":
+		genRepository()
+	default:
+		fmt.Printf("Unknown gen subcommand: %s\n\n", os.Args[2])
+		showGenUsage()
+		os.Exit(1)
+	}
+}
+
+// genRepository scaffolds repositories and a UnitOfWork for the entities of a DbContext
+func genRepository() {
+	contextName := ""
+	output := ""
+	packageName := ""
+	force := false
+	var entities []string
+	args := os.Args[3:]
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--context", "-c":
+			if i+1 < len(args) {
+				contextName = args[i+1]
+				i++
+			}
+		case "--output", "-o":
+			if i+1 < len(args) {
+				output = args[i+1]
+				i++
+			}
+		case "--package", "-p":
+			if i+1 < len(args) {
+				packageName = args[i+1]
+				i++
+			}
+		case "--force":
+			force = true
+		default:
+			if strings.HasPrefix(args[i], "-") {
+				fmt.Printf("❌ Unknown flag: %s\n", args[i])
+				os.Exit(1)
+			}
+			entities = append(entities, args[i])
+		}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		fmt.Printf("❌ Error getting working directory: %v\n", err)
+		os.Exit(1)
+	}
+	projectRoot, err := findProjectRoot(wd)
+	if err != nil {
+		fmt.Printf("❌ Error finding project root: %v\n", err)
+		os.Exit(1)
+	}
+
+	scanner := discovery.NewContextScanner(projectRoot)
+	var contextInfo *discovery.DbContextInfo
+	if contextName != "" {
+		contextInfo, err = scanner.FindContextByName(contextName)
+	} else {
+		contextInfo, err = scanner.FindDefaultContext()
+	}
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	// Without --output the files go next to the context, in its package
+	contextDir := filepath.Dir(contextInfo.FilePath)
+	outputDir := contextDir
+	if output != "" {
+		if outputDir, err = filepath.Abs(output); err != nil {
+			fmt.Printf("❌ Error resolving %s: %v\n", output, err)
+			os.Exit(1)
+		}
+	}
+	if packageName == "" {
+		if outputDir == contextDir {
+			packageName = contextInfo.PackageName
+		} else {
+			packageName = filepath.Base(outputDir)
+		}
+	}
+
+	opts := scaffold.RepositoryOptions{Context: *contextInfo, Entities: entities, PackageName: packageName}
+	if packageName != contextInfo.PackageName {
+		if opts.ContextImport, err = importPath(projectRoot, contextDir); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	files, err := scaffold.GenerateRepositories(opts)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		fmt.Printf("❌ Error creating %s: %v\n", outputDir, err)
+		os.Exit(1)
+	}
+	written := 0
+	for _, file := range files {
+		path := filepath.Join(outputDir, file.Name)
+		if _, err := os.Stat(path); err == nil && !force {
+			fmt.Printf("⚠️  Skipping %s: file exists (use --force to replace it)\n", path)
+			continue
+		}
+		if err := os.WriteFile(path, file.Content, 0644); err != nil {
+			fmt.Printf("❌ Error writing %s: %v\n", path, err)
+			os.Exit(1)
+		}
+		fmt.Printf("📄 %s\n", path)
+		written++
+	}
+	fmt.Printf("✅ %d files generated for %s\n", written, contextInfo.Name)
+}
+
+// importPath derives the import path of dir from the module path in projectRoot's go.mod
+func importPath(projectRoot, dir string) (string, error) {
+	file, err := os.Open(filepath.Join(projectRoot, "go.mod"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read go.mod: %w", err)
+	}
+	defer file.Close()
+
+	modulePath := ""
+	lines := bufio.NewScanner(file)
+	for lines.Scan() {
+		line := strings.TrimSpace(lines.Text())
+		if strings.HasPrefix(line, "module ") {
+			modulePath = strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module ")), `"`)
+			break
+		}
+	}
+	if modulePath == "" {
+		return "", fmt.Errorf("no module directive in %s", filepath.Join(projectRoot, "go.mod"))
+	}
+
+	rel, err := filepath.Rel(projectRoot, dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve package of %s: %w", dir, err)
+	}
+	if rel == "." {
+		return modulePath, nil
+	}
+	return modulePath + "/" + filepath.ToSlash(rel), nil
+}
+
+func showGenUsage() {
+	fmt.Println("Gen Commands:")
+	fmt.Println("  gen repository [Entity...] [--context name] [--output dir] [--package name] [--force]")
+	fmt.Println("                          Scaffold repositories (GetById, List, Add, Update, Delete) and a UnitOfWork")
+}
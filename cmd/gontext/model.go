@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/shepherrrd/gontext/internal/models"
+)
+
+func handleModelCommands() {
+	if len(os.Args) < 3 {
+		fmt.Println("Model command requires a subcommand")
+		showModelUsage()
+		os.Exit(1)
+	}
+
+	switch os.Args[2] {
+	case "diagram":
+		modelDiagram()
+	default:
+		fmt.Printf("Unknown model subcommand: %s\n\n", os.Args[2])
+		showModelUsage()
+		os.Exit(1)
+	}
+}
+
+// modelDiagram renders migrations/ModelSnapshot.json as an ER diagram
+func modelDiagram() {
+	format := string(models.DiagramMermaid)
+	output := ""
+	args := os.Args[3:]
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--format", "-f":
+			if i+1 < len(args) {
+				format = args[i+1]
+				i++
+			}
+		case "--output", "-o":
+			if i+1 < len(args) {
+				output = args[i+1]
+				i++
+			}
+		default:
+			fmt.Printf("❌ Unknown flag: %s\n", args[i])
+			os.Exit(1)
+		}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		fmt.Printf("❌ Error getting working directory: %v\n", err)
+		os.Exit(1)
+	}
+	projectRoot, err := findProjectRoot(wd)
+	if err != nil {
+		fmt.Printf("❌ Error finding project root: %v\n", err)
+		os.Exit(1)
+	}
+	data, err := os.ReadFile(filepath.Join(projectRoot, "migrations", "ModelSnapshot.json"))
+	if err != nil {
+		fmt.Printf("❌ Failed to read model snapshot (run migration add first): %v\n", err)
+		os.Exit(1)
+	}
+	var snapshot models.ModelSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		fmt.Printf("❌ Failed to read model snapshot: %v\n", err)
+		os.Exit(1)
+	}
+
+	diagram, err := snapshot.Diagram(models.DiagramFormat(format))
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	if output == "" {
+		fmt.Print(diagram)
+		return
+	}
+	if err := os.WriteFile(output, []byte(diagram), 0644); err != nil {
+		fmt.Printf("❌ Error writing %s: %v\n", output, err)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ %s diagram of %d entities written to %s\n", format, len(snapshot.Entities), output)
+}
+
+func showModelUsage() {
+	fmt.Println("Model Commands:")
+	fmt.Println("  model diagram [--format mermaid|dot|dbml] [--output file]  Render an ER diagram of the model snapshot")
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/shepherrrd/gontext"
+	"github.com/shepherrrd/gontext/internal/search"
+)
+
+func handleSearchCommands() {
+	if len(os.Args) < 3 {
+		fmt.Println("Search command requires a subcommand")
+		showSearchUsage()
+		os.Exit(1)
+	}
+
+	switch os.Args[2] {
+	case "backfill":
+		if len(os.Args) < 4 || strings.HasPrefix(os.Args[3], "-") {
+			fmt.Println("Search backfill requires an entity name")
+			fmt.Println("Usage: go run github.com/shepherrrd/gontext/cmd/gontext search backfill <entity> --engine elasticsearch|meilisearch --url <url> [--index name]")
+			os.Exit(1)
+		}
+		backfillSearch(os.Args[3], os.Args[4:])
+	default:
+		fmt.Printf("Unknown search subcommand: %s\n\n", os.Args[2])
+		showSearchUsage()
+		os.Exit(1)
+	}
+}
+
+// backfillSearch indexes every row of an entity's table, one keyset page at a time
+func backfillSearch(entity string, args []string) {
+	flags := flag.NewFlagSet("search backfill", flag.ExitOnError)
+	engineName := flags.String("engine", "", "Search engine: elasticsearch or meilisearch")
+	url := flags.String("url", "", "Search engine URL")
+	index := flags.String("index", "", "Index name (default the table name)")
+	apiKey := flags.String("api-key", os.Getenv("SEARCH_API_KEY"), "API key (default $SEARCH_API_KEY)")
+	fields := flags.String("fields", "", "Comma-separated columns to index (default all)")
+	keyColumn := flags.String("key", "", "Document ID and pagination column (default primary key)")
+	batchSize := flags.Int("batch-size", search.DefaultBackfillBatchSize, "Rows indexed per request")
+	flags.Parse(args)
+
+	var engine search.Engine
+	switch *engineName {
+	case "elasticsearch":
+		engine = &search.Elasticsearch{URL: *url, APIKey: *apiKey}
+	case "meilisearch":
+		engine = &search.Meilisearch{URL: *url, APIKey: *apiKey}
+	default:
+		fmt.Printf("❌ Unknown engine %q (use elasticsearch or meilisearch)\n", *engineName)
+		os.Exit(1)
+	}
+	if *url == "" {
+		fmt.Println("❌ --url is required")
+		os.Exit(1)
+	}
+
+	connectionString := getDatabaseConnection()
+	if connectionString == "" {
+		fmt.Println("❌ Database connection not found")
+		os.Exit(1)
+	}
+
+	table, err := resolveDataTable(entity, *keyColumn)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if *index == "" {
+		*index = table.Name
+	}
+	var columns []string
+	for _, column := range strings.Split(*fields, ",") {
+		if column = strings.TrimSpace(column); column != "" {
+			columns = append(columns, column)
+		}
+	}
+
+	ctx, err := gontext.NewDbContext(connectionString, "postgres")
+	if err != nil {
+		fmt.Printf("❌ Error creating database context: %v\n", err)
+		os.Exit(1)
+	}
+	defer ctx.Close()
+
+	cancelCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("🔎 Indexing %s into %s index %q...\n", table.Name, *engineName, *index)
+	count, err := search.Backfill(ctx.GetDB().WithContext(cancelCtx), engine, search.BackfillOptions{
+		Table:     table.Name,
+		KeyColumn: table.KeyColumn,
+		Columns:   columns,
+		Index:     *index,
+		BatchSize: *batchSize,
+		Progress: func(indexed int) {
+			fmt.Printf("   • %d documents indexed\n", indexed)
+		},
+	})
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			fmt.Printf("⚠️ Backfill cancelled after %d documents\n", count)
+		} else {
+			fmt.Printf("❌ Error indexing %s after %d documents: %v\n", table.Name, count, err)
+		}
+		os.Exit(1)
+	}
+
+	fmt.Printf("✅ Indexed %d documents from %s\n", count, table.Name)
+}
+
+func showSearchUsage() {
+	fmt.Println("Search Commands:")
+	fmt.Println("  search backfill <entity>  Index every row of a table in a search engine")
+	fmt.Println("      --engine <name>       elasticsearch or meilisearch")
+	fmt.Println("      --url <url>           Search engine URL, e.g. http://localhost:9200")
+	fmt.Println("      --index <name>        Index name (default the table name)")
+	fmt.Println("      --api-key <key>       API key (default $SEARCH_API_KEY)")
+	fmt.Println("      --fields <columns>    Comma-separated columns to index (default all)")
+	fmt.Println("      --key <column>        Document ID column (default primary key)")
+	fmt.Println("      --batch-size <n>      Rows per request (default 500)")
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/shepherrrd/gontext"
+	"github.com/shepherrrd/gontext/internal/models"
+)
+
+// snapshotManifest describes a saved fixture snapshot
+type snapshotManifest struct {
+	Name    string          `json:"name"`
+	SavedAt time.Time       `json:"saved_at"`
+	Tables  []snapshotTable `json:"tables"`
+}
+
+type snapshotTable struct {
+	Name         string   `json:"name"`
+	File         string   `json:"file"`
+	Rows         int64    `json:"rows"`
+	IdentityKeys []string `json:"identity_keys,omitempty"` // Serial columns whose sequences are reset on restore
+}
+
+func handleSnapshotCommands() {
+	if len(os.Args) < 5 {
+		fmt.Println("Database snapshot requires save or restore and a name")
+		fmt.Println("Usage: go run github.com/shepherrrd/gontext/cmd/gontext database snapshot save|restore <name>")
+		os.Exit(1)
+	}
+
+	name := os.Args[4]
+	switch os.Args[3] {
+	case "save":
+		saveSnapshot(name)
+	case "restore":
+		restoreSnapshot(name)
+	default:
+		fmt.Printf("Unknown snapshot subcommand: %s\n\n", os.Args[3])
+		showDatabaseUsage()
+		os.Exit(1)
+	}
+}
+
+// snapshotDir returns <project>/.gontext/snapshots/<name>
+func snapshotDir(name string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		fmt.Printf("❌ Error getting working directory: %v\n", err)
+		os.Exit(1)
+	}
+	projectRoot, err := findProjectRoot(wd)
+	if err != nil {
+		fmt.Printf("❌ Error finding project root: %v\n", err)
+		os.Exit(1)
+	}
+	return filepath.Join(projectRoot, ".gontext", "snapshots", name)
+}
+
+// snapshotTables lists the entity tables from migrations/ModelSnapshot.json
+func snapshotTables() ([]snapshotTable, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	projectRoot, err := findProjectRoot(wd)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(projectRoot, "migrations", "ModelSnapshot.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model snapshot (run migration add first): %w", err)
+	}
+
+	var snapshot models.ModelSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to read model snapshot: %w", err)
+	}
+
+	var tables []snapshotTable
+	for _, entity := range snapshot.Entities {
+		table := snapshotTable{Name: entity.TableName, File: entity.TableName + ".copy"}
+		for _, field := range entity.Fields {
+			if field.IsPrimary && field.IsIdentity {
+				table.IdentityKeys = append(table.IdentityKeys, field.ColumnName)
+			}
+		}
+		tables = append(tables, table)
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].Name < tables[j].Name })
+	return tables, nil
+}
+
+// withPgConn runs fn on a dedicated pgx connection of the context's pool
+func withPgConn(ctx *gontext.DbContext, fn func(conn *pgx.Conn) error) error {
+	sqlDB, err := ctx.GetDB().DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(context.Background())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn interface{}) error {
+		stdlibConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("snapshots require PostgreSQL, got driver connection %T", driverConn)
+		}
+		return fn(stdlibConn.Conn())
+	})
+}
+
+// saveSnapshot copies every entity table to .gontext/snapshots/<name> with COPY ... TO STDOUT
+// All tables are read in one REPEATABLE READ transaction so the snapshot is consistent
+func saveSnapshot(name string) {
+	fmt.Printf("📸 Saving snapshot %s...\n", name)
+
+	ctx := snapshotContext()
+	defer ctx.Close()
+
+	tables, err := snapshotTables()
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	dir := snapshotDir(name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		fmt.Printf("❌ Error creating %s: %v\n", dir, err)
+		os.Exit(1)
+	}
+
+	err = withPgConn(ctx, func(conn *pgx.Conn) error {
+		background := context.Background()
+		tx, err := conn.BeginTx(background, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(background)
+
+		for i := range tables {
+			file, err := os.Create(filepath.Join(dir, tables[i].File))
+			if err != nil {
+				return err
+			}
+			sql := fmt.Sprintf("COPY %s TO STDOUT", pgx.Identifier{tables[i].Name}.Sanitize())
+			tag, err := conn.PgConn().CopyTo(background, file, sql)
+			file.Close()
+			if err != nil {
+				return fmt.Errorf("failed to copy %s: %w", tables[i].Name, err)
+			}
+			tables[i].Rows = tag.RowsAffected()
+			fmt.Printf("   • %s: %d rows\n", tables[i].Name, tables[i].Rows)
+		}
+		return tx.Commit(background)
+	})
+	if err != nil {
+		fmt.Printf("❌ Error saving snapshot: %v\n", err)
+		os.Exit(1)
+	}
+
+	manifest := snapshotManifest{Name: name, SavedAt: time.Now(), Tables: tables}
+	data, _ := json.MarshalIndent(manifest, "", "  ")
+	if err := os.WriteFile(filepath.Join(dir, "manifest.json"), data, 0644); err != nil {
+		fmt.Printf("❌ Error writing manifest: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✅ Snapshot %s saved to %s\n", name, dir)
+}
+
+// restoreSnapshot replaces the contents of every snapshot table with COPY ... FROM STDIN
+// Runs in one transaction with foreign key triggers disabled (session_replication_role = replica),
+// which needs a superuser or table owner role - typical for local development databases
+func restoreSnapshot(name string) {
+	fmt.Printf("♻️  Restoring snapshot %s...\n", name)
+
+	dir := snapshotDir(name)
+	data, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		fmt.Printf("❌ Snapshot %s not found: %v\n", name, err)
+		os.Exit(1)
+	}
+	var manifest snapshotManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		fmt.Printf("❌ Error reading manifest: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx := snapshotContext()
+	defer ctx.Close()
+
+	err = withPgConn(ctx, func(conn *pgx.Conn) error {
+		background := context.Background()
+		tx, err := conn.Begin(background)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(background)
+
+		if _, err := tx.Exec(background, "SET LOCAL session_replication_role = replica"); err != nil {
+			return fmt.Errorf("failed to disable foreign key checks: %w", err)
+		}
+
+		names := make([]string, len(manifest.Tables))
+		for i, table := range manifest.Tables {
+			names[i] = pgx.Identifier{table.Name}.Sanitize()
+		}
+		if len(names) > 0 {
+			if _, err := tx.Exec(background, "TRUNCATE "+strings.Join(names, ", ")); err != nil {
+				return fmt.Errorf("failed to truncate tables: %w", err)
+			}
+		}
+
+		for i, table := range manifest.Tables {
+			file, err := os.Open(filepath.Join(dir, table.File))
+			if err != nil {
+				return err
+			}
+			_, err = conn.PgConn().CopyFrom(background, file, fmt.Sprintf("COPY %s FROM STDIN", names[i]))
+			file.Close()
+			if err != nil {
+				return fmt.Errorf("failed to restore %s: %w", table.Name, err)
+			}
+
+			// Continue serial keys after the restored rows
+			for _, column := range table.IdentityKeys {
+				quotedColumn := pgx.Identifier{column}.Sanitize()
+				resetSQL := fmt.Sprintf("SELECT setval(pg_get_serial_sequence($1, $2), COALESCE((SELECT MAX(%s) FROM %s), 0) + 1, false)",
+					quotedColumn, names[i])
+				if _, err := tx.Exec(background, resetSQL, names[i], column); err != nil {
+					return fmt.Errorf("failed to reset sequence for %s.%s: %w", table.Name, column, err)
+				}
+			}
+			fmt.Printf("   • %s: %d rows\n", table.Name, table.Rows)
+		}
+		return tx.Commit(background)
+	})
+	if err != nil {
+		fmt.Printf("❌ Error restoring snapshot: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✅ Snapshot %s restored (saved %s)\n", name, manifest.SavedAt.Format("2006-01-02 15:04:05"))
+}
+
+func snapshotContext() *gontext.DbContext {
+	connectionString := getDatabaseConnection()
+	if connectionString == "" {
+		fmt.Println("❌ Database connection not found")
+		os.Exit(1)
+	}
+
+	ctx, err := gontext.NewDbContext(connectionString, "postgres")
+	if err != nil {
+		fmt.Printf("❌ Error creating database context: %v\n", err)
+		os.Exit(1)
+	}
+	return ctx
+}
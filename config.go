@@ -0,0 +1,29 @@
+package gontext
+
+import (
+	"os"
+
+	"github.com/shepherrrd/gontext/internal/config"
+)
+
+// ConnectionStringBuilder assembles a PostgreSQL connection URL from its parts
+type ConnectionStringBuilder = config.ConnectionStringBuilder
+
+// ConnectionConfig is a named connection from gontext.json / gontext.yaml
+type ConnectionConfig = config.ConnectionConfig
+
+// ParseConnectionString reads a postgres:// URL into a ConnectionStringBuilder
+func ParseConnectionString(connectionString string) (ConnectionStringBuilder, error) {
+	return config.ParseConnectionString(connectionString)
+}
+
+// ResolveConnection returns the connection string the CLI would use:
+// the named connection from gontext.json/yaml, else DATABASE_URL, the config default or .env
+// Usage: connectionString, err := gontext.ResolveConnection("staging")
+func ResolveConnection(name string) (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return config.Resolve(wd, name)
+}
@@ -0,0 +1,13 @@
+package gontext
+
+import (
+	"os"
+
+	"github.com/shepherrrd/gontext/internal/console"
+)
+
+// RunConsole starts an interactive query console on stdin/stdout for the registered entities
+// Typically wired to a flag of the design-time program: go run . --gontext-console
+func RunConsole(ctx *DbContext) error {
+	return console.New(ctx, os.Stdin, os.Stdout).Run()
+}
@@ -0,0 +1,33 @@
+package gontext
+
+import (
+	"github.com/shepherrrd/gontext/internal/credentials"
+)
+
+// Credential providers for DbContextOptions.Credentials
+type (
+	Credentials               = credentials.Credentials
+	CredentialProvider        = credentials.Provider
+	CredentialProviderFunc    = credentials.ProviderFunc
+	SecretFetcher             = credentials.SecretFetcher
+	EnvCredentialProvider     = credentials.EnvProvider
+	FileCredentialProvider    = credentials.FileProvider
+	VaultProvider             = credentials.VaultProvider
+	AWSSecretsManagerProvider = credentials.AWSSecretsManagerProvider
+	GCPSecretManagerProvider  = credentials.GCPSecretManagerProvider
+)
+
+// Token authentication for DbContextOptions.TokenProvider and TLS files for DbContextOptions.TLS
+type (
+	TokenProvider        = credentials.TokenProvider
+	TokenProviderFunc    = credentials.TokenProviderFunc
+	AWSCredentials       = credentials.AWSCredentials
+	RDSIAMTokenProvider  = credentials.RDSIAMTokenProvider
+	AzureADTokenProvider = credentials.AzureADTokenProvider
+	TLSOptions           = credentials.TLSOptions
+)
+
+// ParseSecret reads an RDS-style JSON secret, a postgres:// URL, key=value pairs or a bare password
+func ParseSecret(secret string) (Credentials, error) {
+	return credentials.ParseSecret(secret)
+}
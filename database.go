@@ -0,0 +1,32 @@
+package gontext
+
+import (
+	stdcontext "context"
+
+	"github.com/shepherrrd/gontext/internal/context"
+)
+
+// Database runs raw SQL on a context's connection or transaction; see DbContext.Database
+type Database = context.Database
+
+// SqlQuery runs a raw query and scans the rows into T, a struct or a scalar - EF Core: Database.SqlQuery<T>
+// Arguments are bound as parameters (? or @name); loaded entities are not tracked
+// Usage: users, err := gontext.SqlQuery[User](ctx, `SELECT * FROM "User" WHERE "Age" > ?`, 30)
+func SqlQuery[T any](ctx *DbContext, sql string, args ...interface{}) ([]T, error) {
+	return SqlQueryContext[T](stdcontext.Background(), ctx, sql, args...)
+}
+
+// SqlQueryContext is SqlQuery canceled when c is done
+func SqlQueryContext[T any](c stdcontext.Context, ctx *DbContext, sql string, args ...interface{}) ([]T, error) {
+	var results []T
+	err := ctx.Database().QueryContext(c, &results, false, sql, args...)
+	return results, err
+}
+
+// SqlQueryTracked is SqlQuery for registered entity types whose results are tracked like those of
+// LINQ queries, so SaveChanges saves changes made to them
+func SqlQueryTracked[T any](ctx *DbContext, sql string, args ...interface{}) ([]T, error) {
+	var results []T
+	err := ctx.Database().QueryContext(stdcontext.Background(), &results, true, sql, args...)
+	return results, err
+}
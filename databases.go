@@ -0,0 +1,28 @@
+package gontext
+
+// AddDatabase connects to another database and adds it to ctx under name, for entity types registered
+// with RegisterEntityIn; closing ctx closes it. The returned context is the database's own, for its
+// migrations:
+//
+//	if _, err := gontext.AddDatabase(ctx.DbContext, "analytics", "postgres", gontext.DbContextOptions{
+//	    ConnectionString: os.Getenv("ANALYTICS_DATABASE_URL"),
+//	}); err != nil { ... }
+//	ctx.Events = gontext.RegisterEntityIn[Event](ctx.DbContext, "analytics")
+//	analytics := gontext.NewMigrationManager(ctx.DatabaseContext("analytics"), "migrations/analytics", "analytics")
+func AddDatabase(ctx *DbContext, name string, driverType string, options DbContextOptions) (*DbContext, error) {
+	database, err := NewDbContextWithOptions(driverType, options)
+	if err != nil {
+		return nil, err
+	}
+	ctx.AddDatabase(name, database)
+	return database, nil
+}
+
+// RegisterEntityIn registers T like RegisterEntity but stores it in the database added under name:
+// its queries run there, SaveChanges writes it there in a transaction of that database, and that
+// database's migrations create its table; see DbContext.RouteEntity
+func RegisterEntityIn[T any](ctx *DbContext, database string) *LinqDbSet[T] {
+	var zero T
+	ctx.RouteEntity(zero, database)
+	return NewLinqDbSet[T](ctx)
+}
@@ -0,0 +1,188 @@
+package gontext_test
+
+import (
+	"maps"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shepherrrd/gontext"
+)
+
+type cascadeAuthor struct {
+	Id   int
+	Name string
+}
+
+type cascadePost struct {
+	Id       int
+	Title    string
+	AuthorId *int
+}
+
+type cascadeComment struct {
+	Id     int
+	PostId int
+}
+
+// newCascadeContext returns a context where posts reference authors with behavior and comments cascade
+// with their posts, holding authors 1 and 2, posts 1 and 2 of author 1 and post 3 of author 2, and a
+// comment on post 1
+func newCascadeContext(t *testing.T, behavior gontext.DeleteBehavior) *gontext.DbContext {
+	t.Helper()
+	ctx, err := gontext.NewDbContext(filepath.Join(t.TempDir(), "gontext.db"), "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ctx.Close() })
+	gontext.RegisterEntity[cascadeAuthor](ctx)
+	gontext.RegisterEntity[cascadePost](ctx)
+	gontext.RegisterEntity[cascadeComment](ctx)
+	gontext.Entity[cascadePost](ctx).HasForeignKey("AuthorId", "cascadeAuthor").OnDelete(behavior)
+	gontext.Entity[cascadeComment](ctx).HasForeignKey("PostId", "cascadePost").OnDelete(gontext.DeleteCascade)
+	if err := ctx.EnsureCreated(); err != nil {
+		t.Fatal(err)
+	}
+
+	first, second := 1, 2
+	for _, row := range []interface{}{
+		&[]cascadeAuthor{{Id: 1, Name: "ada"}, {Id: 2, Name: "grace"}},
+		&[]cascadePost{{Id: 1, Title: "a", AuthorId: &first}, {Id: 2, Title: "b", AuthorId: &first}, {Id: 3, Title: "c", AuthorId: &second}},
+		&cascadeComment{Id: 1, PostId: 1},
+	} {
+		if err := ctx.GetDB().Create(row).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return ctx
+}
+
+// removeTrackedAuthor loads every row so the context tracks the dependents, then removes author 1
+func removeTrackedAuthor(t *testing.T, ctx *gontext.DbContext) {
+	t.Helper()
+	if _, err := gontext.NewLinqDbSet[cascadePost](ctx).ToList(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gontext.NewLinqDbSet[cascadeComment](ctx).ToList(); err != nil {
+		t.Fatal(err)
+	}
+	gontext.NewLinqDbSet[cascadeAuthor](ctx).Remove(cascadeAuthor{Id: 1, Name: "ada"})
+}
+
+// postAuthors returns the author of each remaining post by post id, 0 for none
+func postAuthors(t *testing.T, ctx *gontext.DbContext) map[int]int {
+	t.Helper()
+	var posts []cascadePost
+	if err := ctx.GetDB().Find(&posts).Error; err != nil {
+		t.Fatal(err)
+	}
+	authors := make(map[int]int, len(posts))
+	for _, post := range posts {
+		authors[post.Id] = 0
+		if post.AuthorId != nil {
+			authors[post.Id] = *post.AuthorId
+		}
+	}
+	return authors
+}
+
+func countRows(t *testing.T, ctx *gontext.DbContext, model interface{}) int64 {
+	t.Helper()
+	var count int64
+	if err := ctx.GetDB().Model(model).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestSaveChangesAppliesDeleteBehaviors(t *testing.T) {
+	tests := []struct {
+		behavior gontext.DeleteBehavior
+		posts    map[int]int
+		comments int64
+	}{
+		// Cascade deletes the tracked posts and, through them, their comments
+		{gontext.DeleteCascade, map[int]int{3: 2}, 0},
+		// SetNull clears the tracked posts' foreign keys
+		{gontext.DeleteSetNull, map[int]int{1: 0, 2: 0, 3: 2}, 1},
+		// Without a behavior the posts are left to the database, which has no constraint here
+		{gontext.DeleteNoAction, map[int]int{1: 1, 2: 1, 3: 2}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.behavior), func(t *testing.T) {
+			ctx := newCascadeContext(t, tt.behavior)
+			removeTrackedAuthor(t, ctx)
+			if err := ctx.SaveChanges(); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := postAuthors(t, ctx); !maps.Equal(got, tt.posts) {
+				t.Errorf("posts by author %v, want %v", got, tt.posts)
+			}
+			if got := countRows(t, ctx, &cascadeComment{}); got != tt.comments {
+				t.Errorf("%d comments left, want %d", got, tt.comments)
+			}
+			if got := countRows(t, ctx, &cascadeAuthor{}); got != 1 {
+				t.Errorf("%d authors left, want 1", got)
+			}
+		})
+	}
+}
+
+func TestSaveChangesRefusesRestrictedDeletes(t *testing.T) {
+	ctx := newCascadeContext(t, gontext.DeleteRestrict)
+	removeTrackedAuthor(t, ctx)
+
+	err := ctx.SaveChanges()
+	if err == nil || !strings.Contains(err.Error(), "Restrict") {
+		t.Fatalf("SaveChanges() = %v, want the Restrict behavior reported", err)
+	}
+	if got := countRows(t, ctx, &cascadeAuthor{}); got != 2 {
+		t.Errorf("%d authors left after the refused save, want 2", got)
+	}
+}
+
+func TestSaveChangesLeavesUntrackedDependents(t *testing.T) {
+	ctx := newCascadeContext(t, gontext.DeleteCascade)
+	gontext.NewLinqDbSet[cascadeAuthor](ctx).Remove(cascadeAuthor{Id: 1, Name: "ada"})
+	if err := ctx.SaveChanges(); err != nil {
+		t.Fatal(err)
+	}
+	if got := postAuthors(t, ctx); len(got) != 3 {
+		t.Errorf("posts by author %v, want the untracked posts left to the database", got)
+	}
+}
+
+type navigationAuthor struct {
+	Id    int
+	Posts []navigationPost `gorm:"foreignKey:AuthorId;constraint:OnDelete:SET NULL,OnUpdate:CASCADE"`
+}
+
+type navigationPost struct {
+	Id       int
+	AuthorId *int
+}
+
+func TestForeignKeysReadNavigationConstraints(t *testing.T) {
+	ctx, err := gontext.NewDbContext(":memory:", "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ctx.Close()
+	gontext.RegisterEntity[navigationAuthor](ctx)
+	gontext.RegisterEntity[navigationPost](ctx)
+
+	foreignKeys := ctx.ForeignKeys()
+	if len(foreignKeys) != 1 {
+		t.Fatalf("resolved %d foreign keys, want the navigation's", len(foreignKeys))
+	}
+	foreignKey := foreignKeys[0]
+	if foreignKey.Dependent.Name != "navigationPost" || foreignKey.Field.Name != "AuthorId" || foreignKey.Principal.Name != "navigationAuthor" {
+		t.Errorf("foreign key %s.%s -> %s, want navigationPost.AuthorId -> navigationAuthor",
+			foreignKey.Dependent.Name, foreignKey.Field.Name, foreignKey.Principal.Name)
+	}
+	if foreignKey.OnDelete != gontext.DeleteSetNull || foreignKey.OnUpdate != gontext.DeleteCascade {
+		t.Errorf("OnDelete %q OnUpdate %q, want SET NULL and CASCADE", foreignKey.OnDelete, foreignKey.OnUpdate)
+	}
+}
@@ -0,0 +1,20 @@
+package gontext
+
+import (
+	"github.com/shepherrrd/gontext/internal/models"
+)
+
+// DiagramFormat selects the ER diagram output of ModelDiagram
+type DiagramFormat = models.DiagramFormat
+
+const (
+	DiagramMermaid = models.DiagramMermaid
+	DiagramDot     = models.DiagramDot
+	DiagramDBML    = models.DiagramDBML
+)
+
+// ModelDiagram renders the registered entities as an ER diagram with keys, foreign keys and indexes
+// Usage: diagram, err := gontext.ModelDiagram(ctx, gontext.DiagramMermaid)
+func ModelDiagram(ctx *DbContext, format DiagramFormat) (string, error) {
+	return models.NewModelSnapshot(ctx.GetEntityModels()).Diagram(format)
+}
@@ -0,0 +1,18 @@
+package gontext
+
+import (
+	"github.com/shepherrrd/gontext/internal/encryption"
+)
+
+// Keyring holds the AES keys of encrypted columns by key ID, for PropertyBuilder.IsEncrypted
+type Keyring = encryption.Keyring
+
+// ErrUnknownEncryptionKey is returned when a value was encrypted under a key ID missing from the keyring
+var ErrUnknownEncryptionKey = encryption.ErrUnknownKey
+
+// NewKeyring creates a keyring encrypting new values under primaryKeyID; keep retired keys in keys so
+// rows written before a rotation still decrypt. Keys are 16, 24 or 32 bytes (AES-128/192/256)
+// Usage: gontext.NewKeyring("2024-06", map[string][]byte{"2024-01": oldKey, "2024-06": newKey})
+func NewKeyring(primaryKeyID string, keys map[string][]byte) (*Keyring, error) {
+	return encryption.NewKeyring(primaryKeyID, keys)
+}
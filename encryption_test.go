@@ -0,0 +1,172 @@
+package gontext_test
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shepherrrd/gontext"
+)
+
+type encryptedPatient struct {
+	Id    int
+	Name  string
+	Ssn   string
+	Email *string
+	Notes []byte
+}
+
+// storedPatient reads the same table without the encryption configuration
+type storedPatient struct {
+	Id    int
+	Ssn   string
+	Email *string
+	Notes []byte
+}
+
+func (storedPatient) TableName() string { return "encrypted_patients" }
+
+func newKeyring(t *testing.T, primary string, ids ...string) *gontext.Keyring {
+	t.Helper()
+	keys := make(map[string][]byte, len(ids))
+	for i, id := range ids {
+		keys[id] = bytes.Repeat([]byte{byte(i + 1)}, 32)
+	}
+	keyring, err := gontext.NewKeyring(primary, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyring
+}
+
+// openEncryptedContext opens dsn with the patient columns encrypted under keyring
+func openEncryptedContext(t *testing.T, dsn string, keyring *gontext.Keyring) (*gontext.DbContext, *gontext.LinqDbSet[encryptedPatient]) {
+	t.Helper()
+	ctx, err := gontext.NewDbContext(dsn, "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ctx.Close() })
+	patients := gontext.RegisterEntity[encryptedPatient](ctx)
+	gontext.Entity[encryptedPatient](ctx).Property("Ssn").IsEncrypted(keyring)
+	gontext.Entity[encryptedPatient](ctx).Property("Email").IsEncryptedDeterministic(keyring)
+	gontext.Entity[encryptedPatient](ctx).Property("Notes").IsEncrypted(keyring)
+	if err := ctx.EnsureCreated(); err != nil {
+		t.Fatal(err)
+	}
+	return ctx, patients
+}
+
+func storedPatients(t *testing.T, ctx *gontext.DbContext) []storedPatient {
+	t.Helper()
+	var rows []storedPatient
+	if err := ctx.GetDB().Order("id").Find(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
+
+func TestEncryptedColumnsRoundTrip(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "gontext.db") + "?_journal_mode=WAL&_busy_timeout=5000"
+	ctx, patients := openEncryptedContext(t, dsn, newKeyring(t, "k1", "k1"))
+
+	email := "ada@example.com"
+	added, err := patients.Add(encryptedPatient{Name: "Ada", Ssn: "123-45-6789", Email: &email, Notes: []byte("allergic")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ctx.SaveChanges(); err != nil {
+		t.Fatal(err)
+	}
+	if added.Ssn != "123-45-6789" || *added.Email != email || string(added.Notes) != "allergic" {
+		t.Errorf("saved entity holds %+v, want its plaintext back after the write", added)
+	}
+
+	stored := storedPatients(t, ctx)
+	if len(stored) != 1 {
+		t.Fatalf("stored %d patients, want 1", len(stored))
+	}
+	for column, value := range map[string]string{"ssn": stored[0].Ssn, "email": *stored[0].Email, "notes": string(stored[0].Notes)} {
+		if !strings.HasPrefix(value, "gx1:k1:") {
+			t.Errorf("%s stored as %q, want ciphertext under k1", column, value)
+		}
+	}
+
+	ctx.ClearChangeTracker()
+	loaded, err := patients.Where("Id", added.Id).FirstOrDefault()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded == nil || loaded.Ssn != "123-45-6789" || *loaded.Email != email || string(loaded.Notes) != "allergic" || loaded.Name != "Ada" {
+		t.Errorf("loaded %+v, want the plaintext", loaded)
+	}
+}
+
+func TestEncryptedColumnsMatchByValue(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "gontext.db") + "?_journal_mode=WAL&_busy_timeout=5000"
+	ctx, patients := openEncryptedContext(t, dsn, newKeyring(t, "k1", "k1"))
+	for _, name := range []string{"ada", "grace", "ada"} {
+		email := name + "@example.com"
+		if _, err := patients.Add(encryptedPatient{Name: name, Ssn: "000-00-0000", Email: &email}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ctx.SaveChanges(); err != nil {
+		t.Fatal(err)
+	}
+
+	matched, err := patients.Where("Email", "ada@example.com").ToList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matched) != 2 || matched[0].Name != "ada" || matched[1].Name != "ada" {
+		t.Errorf("Where(Email) matched %+v, want both of ada's rows", matched)
+	}
+
+	// Randomly encrypted columns store equal values differently and cannot be matched
+	if stored := storedPatients(t, ctx); stored[0].Ssn == stored[1].Ssn {
+		t.Error("equal SSNs were stored as equal ciphertexts")
+	}
+	if _, err := patients.Where("Ssn", "000-00-0000").ToList(); err == nil {
+		t.Error("Where on a randomly encrypted column succeeded, want an error")
+	}
+}
+
+func TestEncryptedColumnsSurviveKeyRotation(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "gontext.db") + "?_journal_mode=WAL&_busy_timeout=5000"
+	before, patients := openEncryptedContext(t, dsn, newKeyring(t, "2024-01", "2024-01"))
+	email := "ada@example.com"
+	if _, err := patients.Add(encryptedPatient{Name: "before", Ssn: "111-11-1111", Email: &email}); err != nil {
+		t.Fatal(err)
+	}
+	if err := before.SaveChanges(); err != nil {
+		t.Fatal(err)
+	}
+
+	after, patients := openEncryptedContext(t, dsn, newKeyring(t, "2024-06", "2024-01", "2024-06"))
+	if _, err := patients.Add(encryptedPatient{Name: "after", Ssn: "222-22-2222", Email: &email}); err != nil {
+		t.Fatal(err)
+	}
+	if err := after.SaveChanges(); err != nil {
+		t.Fatal(err)
+	}
+
+	stored := storedPatients(t, after)
+	if !strings.HasPrefix(stored[0].Ssn, "gx1:2024-01:") || !strings.HasPrefix(stored[1].Ssn, "gx1:2024-06:") {
+		t.Errorf("stored SSNs %q and %q, want them under the key of their write", stored[0].Ssn, stored[1].Ssn)
+	}
+	matched, err := patients.Where("Email", email).ToList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matched) != 2 || matched[0].Ssn != "111-11-1111" || matched[1].Ssn != "222-22-2222" {
+		t.Errorf("Where(Email) after the rotation matched %+v, want the rows of both keys decrypted", matched)
+	}
+
+	_, retired := openEncryptedContext(t, dsn, newKeyring(t, "2024-06", "2024-06"))
+	if _, err := retired.ToList(); !errors.Is(err, gontext.ErrUnknownEncryptionKey) {
+		t.Errorf("loading rows of a dropped key error = %v, want ErrUnknownEncryptionKey", err)
+	}
+}
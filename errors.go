@@ -0,0 +1,32 @@
+package gontext
+
+import (
+	"github.com/shepherrrd/gontext/internal/dberrors"
+	"github.com/shepherrrd/gontext/internal/linq"
+)
+
+// DbError is returned for recognised database failures - use errors.As to read constraint details
+type DbError = dberrors.DbError
+
+// Error kinds returned by SaveChanges and query methods - match with errors.Is
+var (
+	ErrUniqueViolation     = dberrors.ErrUniqueViolation
+	ErrForeignKeyViolation = dberrors.ErrForeignKeyViolation
+	ErrNotFound            = dberrors.ErrNotFound
+	ErrConcurrency         = dberrors.ErrConcurrency
+	// ErrSerializationFailure - the transaction was aborted by a concurrent one and can be retried
+	ErrSerializationFailure = dberrors.ErrSerializationFailure
+	// ErrTimeout - the statement ran past its StatementTimeout / WithTimeout and was cancelled
+	ErrTimeout = dberrors.ErrTimeout
+	// ErrUnsafeSQL - strict SQL mode rejected a raw condition with inline literals, ; or comments
+	ErrUnsafeSQL = dberrors.ErrUnsafeSQL
+)
+
+// QueryError is a mistake found while a query was chained (an unknown navigation, an invalid argument),
+// returned by the terminal method and LinqDbSet.Err - use errors.As to read the failed call
+type QueryError = linq.QueryError
+
+// TranslateError converts a raw GORM/driver error into a gontext error when it is recognised
+func TranslateError(err error) error {
+	return dberrors.Translate(err)
+}
@@ -0,0 +1,47 @@
+package gontext_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shepherrrd/gontext"
+)
+
+type uniqueUser struct {
+	Id    int
+	Email string `gorm:"uniqueIndex"`
+}
+
+func TestSaveChangesTranslatesDriverErrors(t *testing.T) {
+	ctx := newOrderedContext(t)
+	users := gontext.RegisterEntity[uniqueUser](ctx)
+	if err := ctx.EnsureCreated(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, email := range []string{"ada@example.com", "ada@example.com"} {
+		if _, err := users.Add(uniqueUser{Email: email}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := ctx.SaveChanges()
+	var dbErr *gontext.DbError
+	if !errors.Is(err, gontext.ErrUniqueViolation) || !errors.As(err, &dbErr) {
+		t.Fatalf("SaveChanges() = %v, want ErrUniqueViolation", err)
+	}
+	if dbErr.Table != "unique_users" || dbErr.Column != "email" {
+		t.Errorf("violation on %s.%s, want unique_users.email", dbErr.Table, dbErr.Column)
+	}
+
+	ctx.ClearChangeTracker()
+	if _, err := gontext.NewLinqDbSet[orderedPost](ctx).Add(orderedPost{Title: "orphan", AuthorId: 42}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctx.SaveChanges(); !errors.Is(err, gontext.ErrForeignKeyViolation) {
+		t.Errorf("SaveChanges() = %v, want ErrForeignKeyViolation", err)
+	}
+
+	if _, err := users.Where("Email", "grace@example.com").First(); !errors.Is(err, gontext.ErrNotFound) {
+		t.Errorf("First() = %v, want ErrNotFound", err)
+	}
+}
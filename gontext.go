@@ -6,6 +6,8 @@ import (
 
 	"github.com/shepherrrd/gontext/internal/context"
 	"github.com/shepherrrd/gontext/internal/drivers"
+	"github.com/shepherrrd/gontext/internal/models"
+	"github.com/shepherrrd/gontext/internal/query"
 )
 
 type DbContext = context.DbContext
@@ -14,35 +16,52 @@ type DbSet = context.DbSet
 type DbContextOptions = context.DbContextOptions
 
 func NewDbContext(connectionString string, driverType string, logLevel ...string) (*DbContext, error) {
-	var driver drivers.DatabaseDriver
-
-	switch driverType {
-	case "postgres", "postgresql":
-		driver = drivers.NewPostgreSQLDriver()
-	case "mysql":
-		driver = drivers.NewMySQLDriver()
-	case "sqlite", "sqlite3":
-		driver = drivers.NewSQLiteDriver()
-	default:
-		return nil, fmt.Errorf("unsupported driver: %s", driverType)
-	}
+	return NewDbContextWithNaming(connectionString, driverType, DefaultNaming, logLevel...)
+}
 
+// NewDbContextWithNaming creates a context whose tables and columns follow a naming convention,
+// e.g. gontext.SnakeCase maps BlogPost.AuthorId to blog_post.author_id in queries and migrations
+func NewDbContextWithNaming(connectionString string, driverType string, convention NamingConvention, logLevel ...string) (*DbContext, error) {
 	// Default to "silent" if no log level specified
 	level := "silent"
 	if len(logLevel) > 0 {
 		level = logLevel[0]
 	}
 
-	options := DbContextOptions{
+	return NewDbContextWithOptions(driverType, DbContextOptions{
 		ConnectionString: connectionString,
-		Driver:          driver,
-		LogLevel:        level,
+		LogLevel:         level,
+		NamingConvention: convention,
+	})
+}
+
+// NewDbContextWithOptions creates a context from full options, e.g. with a credential provider:
+//
+//	ctx, err := gontext.NewDbContextWithOptions("postgres", gontext.DbContextOptions{
+//	    ConnectionString:  "postgres://app@db.internal/app?sslmode=require",
+//	    Credentials:       gontext.VaultProvider{Path: "database/creds/app"},
+//	    CredentialRefresh: 15 * time.Minute,
+//	})
+func NewDbContextWithOptions(driverType string, options DbContextOptions) (*DbContext, error) {
+	if options.Driver == nil {
+		switch driverType {
+		case "postgres", "postgresql":
+			options.Driver = drivers.NewPostgreSQLDriver()
+		case "mysql":
+			options.Driver = drivers.NewMySQLDriver()
+		case "sqlite", "sqlite3":
+			options.Driver = drivers.NewSQLiteDriver()
+		default:
+			return nil, fmt.Errorf("unsupported driver: %s", driverType)
+		}
+	}
+	if options.LogLevel == "" {
+		options.LogLevel = "silent"
 	}
 
 	return context.NewDbContext(options)
 }
 
-
 func NewDbSet[T any](ctx *DbContext) *DbSet {
 	var zero T
 	return ctx.RegisterEntity(zero)
@@ -62,4 +81,113 @@ func RegisterEntity[T any](ctx *DbContext) *LinqDbSet[T] {
 func GetEntityType[T any]() reflect.Type {
 	var zero T
 	return reflect.TypeOf(zero)
-}
\ No newline at end of file
+}
+type Validatable = context.Validatable
+type StructValidator = context.StructValidator
+type ValidationError = context.ValidationError
+type ValidationViolation = context.ValidationViolation
+
+// Entity lifecycle hooks - implement on the entity type (pointer receivers work)
+type BeforeCreateHook = context.BeforeCreateHook
+type BeforeUpdateHook = context.BeforeUpdateHook
+type BeforeDeleteHook = context.BeforeDeleteHook
+type AfterLoadHook = context.AfterLoadHook
+
+// Transaction isolation - pass to BeginTransaction or SaveOptions.IsolationLevel
+type IsolationLevel = context.IsolationLevel
+type SaveOptions = context.SaveOptions
+
+const (
+	IsolationDefault        = context.IsolationDefault
+	IsolationReadCommitted  = context.IsolationReadCommitted
+	IsolationRepeatableRead = context.IsolationRepeatableRead
+	IsolationSerializable   = context.IsolationSerializable
+)
+
+// Scope is the transaction of a WithTransaction call
+type Scope = context.TransactionScope
+
+// WithTransaction runs fn in a transaction, committing when it returns nil and rolling back on an error or
+// panic. Queries and SaveChanges of scope.Context(), and DbSets obtained from it, run in the transaction;
+// ctx itself stays outside it:
+//
+//	err := gontext.WithTransaction(ctx, func(scope *gontext.Scope) error {
+//		accounts := gontext.NewLinqDbSet[Account](scope.Context())
+//		...
+//		return scope.SaveChanges()
+//	})
+func WithTransaction(ctx *DbContext, fn func(scope *Scope) error, level ...IsolationLevel) error {
+	return ctx.WithTransaction(fn, level...)
+}
+
+// Change tracking - snapshot comparison (default) or explicit MarkModified/SetProperty
+type ChangeTrackingStrategy = context.ChangeTrackingStrategy
+type DirtyTracker = context.DirtyTracker
+
+const (
+	SnapshotTracking = context.SnapshotTracking
+	ExplicitTracking = context.ExplicitTracking
+)
+type ChangeTrackerStats = context.ChangeTrackerStats
+
+// PreparedStatementStats reports DbContextOptions.PrepareStmt cache usage
+type PreparedStatementStats = query.StatementCacheStats
+
+// QueryPlanStats reports how often LINQ chains reused cached translations
+type QueryPlanStats = query.PlanCacheStats
+
+// StrictQueryMode is DbContextOptions.StrictQueries: whether LINQ methods fail the query on arguments
+// they cannot use (unknown fields, unrecognized argument types) instead of ignoring them
+type StrictQueryMode = context.StrictQueryMode
+
+const (
+	StrictQueriesInDevelopment = context.StrictQueriesInDevelopment // Default: strict unless GONTEXT_ENV=production
+	StrictQueriesOn            = context.StrictQueriesOn
+	StrictQueriesOff           = context.StrictQueriesOff
+)
+
+// EnvironmentVariable is GONTEXT_ENV; "production" turns off development defaults such as strict queries
+const EnvironmentVariable = context.EnvironmentVariable
+
+// Naming conventions for DbContextOptions.NamingConvention
+type NamingConvention = models.NamingConvention
+
+const (
+	DefaultNaming = models.DefaultNaming
+	PascalCase    = models.PascalCase
+	SnakeCase     = models.SnakeCase
+	CamelCase     = models.CamelCase
+)
+
+// TableNaming names tables from struct names, for DbContextOptions.TableNaming
+type TableNaming = models.TableNaming
+
+// PluralTableNames pluralizes table names after applying a convention: BlogPost -> "blog_posts" with SnakeCase
+type PluralTableNames = models.PluralTableNames
+
+// DeleteBehavior is what happens to dependents when their principal is deleted, for
+// RelationshipBuilder.OnDelete; the default leaves it to the database (NO ACTION)
+type DeleteBehavior = models.DeleteBehavior
+
+const (
+	DeleteDefault  = models.DeleteDefault
+	DeleteCascade  = models.DeleteCascade
+	DeleteSetNull  = models.DeleteSetNull
+	DeleteRestrict = models.DeleteRestrict
+	DeleteNoAction = models.DeleteNoAction
+)
+
+// ConstraintNaming names generated indexes and constraints, for DbContextOptions.ConstraintNaming
+type ConstraintNaming = models.ConstraintNaming
+
+// DefaultConstraintNaming uses idx_, uni_, fk_ and chk_ prefixes, shortened with a hash beyond 63 characters
+type DefaultConstraintNaming = models.DefaultConstraintNaming
+
+// Column types of time.Time fields for DbContextOptions.TimestampType
+type TimestampType = drivers.TimestampType
+
+const (
+	TimestampDefault         = drivers.TimestampDefault
+	TimestampWithTimeZone    = drivers.TimestampWithTimeZone
+	TimestampWithoutTimeZone = drivers.TimestampWithoutTimeZone
+)
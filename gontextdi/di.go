@@ -0,0 +1,115 @@
+// Package gontextdi provides constructors shaped for dependency injection containers: the application
+// context with its cleanup, a factory of scoped contexts, typed DbSets and a database health check.
+// The google/wire provider set and the uber/fx module built from them are separate modules, so this
+// package has no container dependency:
+//
+//	import "github.com/shepherrrd/gontext/gontextdi/gontextwire" // wire.Build(gontextwire.ProviderSet, ...)
+//	import "github.com/shepherrrd/gontext/gontextdi/gontextfx"   // fx.New(fx.Supply(config), gontextfx.Module, ...)
+package gontextdi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/shepherrrd/gontext"
+)
+
+// Config selects the database of the application context; provide it from the application's own
+// configuration
+type Config struct {
+	Driver  string // postgres, mysql or sqlite
+	Options gontext.DbContextOptions
+}
+
+// New creates the application context; containers with lifecycle hooks close it with Hooks.OnStop
+func New(config Config) (*gontext.DbContext, error) {
+	return gontext.NewDbContextWithOptions(config.Driver, config.Options)
+}
+
+// Open creates the application context and returns the cleanup closing its connection pool, the
+// provider shape wire expects
+func Open(config Config) (*gontext.DbContext, func(), error) {
+	ctx, err := New(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ctx, func() { ctx.Close() }, nil
+}
+
+// Factory creates a scoped context per unit of work - a request, a job, a message - so consumers
+// holding the factory never share a change tracker; see DbContext.NewScopedContext
+type Factory func() *gontext.DbContext
+
+// NewFactory returns the factory of scoped contexts of root
+func NewFactory(root *gontext.DbContext) Factory {
+	return root.NewScopedContext
+}
+
+// Set registers T on ctx and returns its typed DbSet, a provider per entity type:
+// fx.Provide(gontextdi.Set[User]) makes *gontext.LinqDbSet[User] injectable. Sets share the change
+// tracker of ctx; consumers that save per unit of work take a Factory instead
+func Set[T any](ctx *gontext.DbContext) *gontext.LinqDbSet[T] {
+	return gontext.RegisterEntity[T](ctx)
+}
+
+// HealthCheck reports whether the database answers, the func(context.Context) error shape health
+// check libraries register; it also serves as an http.Handler answering 200 or 503
+type HealthCheck func(c context.Context) error
+
+// defaultHealthTimeout bounds a health check whose context has no deadline
+const defaultHealthTimeout = 5 * time.Second
+
+// NewHealthCheck returns the health check of ctx's database, pinging a pooled connection
+func NewHealthCheck(ctx *gontext.DbContext) HealthCheck {
+	return func(c context.Context) error {
+		if _, ok := c.Deadline(); !ok {
+			var cancel context.CancelFunc
+			c, cancel = context.WithTimeout(c, defaultHealthTimeout)
+			defer cancel()
+		}
+		sqlDB, err := ctx.GetDB().DB()
+		if err != nil {
+			return fmt.Errorf("database unavailable: %w", err)
+		}
+		if err := sqlDB.PingContext(c); err != nil {
+			return fmt.Errorf("database unavailable: %w", err)
+		}
+		return nil
+	}
+}
+
+// ServeHTTP answers 200 when the database is healthy and 503 with the error otherwise
+// Usage: mux.Handle("/healthz", healthCheck)
+func (h HealthCheck) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if err := h(r.Context()); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintln(w, err)
+		return
+	}
+	fmt.Fprintln(w, "ok")
+}
+
+// Hooks are the start and stop functions of an fx lifecycle hook: OnStart fails the application
+// start when the database does not answer, OnStop closes the pool
+//
+//	fx.Invoke(func(lc fx.Lifecycle, ctx *gontext.DbContext) {
+//		hooks := gontextdi.NewHooks(ctx)
+//		lc.Append(fx.Hook{OnStart: hooks.OnStart, OnStop: hooks.OnStop})
+//	})
+type Hooks struct {
+	OnStart func(c context.Context) error
+	OnStop  func(c context.Context) error
+}
+
+// NewHooks returns the lifecycle hooks of ctx, for containers without wire's cleanup functions
+func NewHooks(ctx *gontext.DbContext) Hooks {
+	return Hooks{
+		OnStart: NewHealthCheck(ctx),
+		OnStop: func(context.Context) error {
+			return ctx.Close()
+		},
+	}
+}
@@ -0,0 +1,29 @@
+// Package gontextfx is the uber/fx module of gontextdi. Given a gontextdi.Config it provides the
+// application context, its Factory of scoped contexts and its HealthCheck, checks the database when
+// the application starts and closes the pool when it stops:
+//
+//	fx.New(
+//		fx.Supply(gontextdi.Config{Driver: "postgres", Options: options}),
+//		gontextfx.Module,
+//		fx.Provide(gontextdi.Set[User]),
+//	)
+package gontextfx
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/shepherrrd/gontext"
+	"github.com/shepherrrd/gontext/gontextdi"
+)
+
+// Module provides *gontext.DbContext, gontextdi.Factory and gontextdi.HealthCheck and appends the
+// context's gontextdi.Hooks to the lifecycle
+var Module = fx.Module("gontext",
+	fx.Provide(gontextdi.New, gontextdi.NewFactory, gontextdi.NewHealthCheck),
+	fx.Invoke(appendHooks),
+)
+
+func appendHooks(lifecycle fx.Lifecycle, ctx *gontext.DbContext) {
+	hooks := gontextdi.NewHooks(ctx)
+	lifecycle.Append(fx.Hook{OnStart: hooks.OnStart, OnStop: hooks.OnStop})
+}
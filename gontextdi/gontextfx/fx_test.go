@@ -0,0 +1,74 @@
+package gontextfx_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+
+	"github.com/shepherrrd/gontext"
+	"github.com/shepherrrd/gontext/gontextdi"
+	"github.com/shepherrrd/gontext/gontextdi/gontextfx"
+)
+
+type note struct {
+	Id   int
+	Text string
+}
+
+func TestModule(t *testing.T) {
+	var (
+		root    *gontext.DbContext
+		factory gontextdi.Factory
+		health  gontextdi.HealthCheck
+		notes   *gontext.LinqDbSet[note]
+	)
+	app := fxtest.New(t,
+		fx.Supply(gontextdi.Config{Driver: "sqlite", Options: gontext.DbContextOptions{
+			ConnectionString: filepath.Join(t.TempDir(), "gontext.db"),
+		}}),
+		gontextfx.Module,
+		fx.Provide(gontextdi.Set[note]),
+		fx.Populate(&root, &factory, &health, &notes),
+	)
+	app.RequireStart()
+
+	if err := root.EnsureCreated(); err != nil {
+		t.Fatal(err)
+	}
+	scoped := factory()
+	if scoped == root {
+		t.Fatal("Factory returned the application context, want a scoped one")
+	}
+	gontext.NewLinqDbSet[note](scoped).Add(note{Text: "injected"})
+	if err := scoped.SaveChanges(); err != nil {
+		t.Fatal(err)
+	}
+	if count, err := notes.Count(); err != nil || count != 1 {
+		t.Errorf("injected DbSet counted %d notes, %v; want the one the scoped context saved", count, err)
+	}
+	if err := health(context.Background()); err != nil {
+		t.Errorf("health check of the started application failed: %v", err)
+	}
+
+	app.RequireStop()
+	if err := health(context.Background()); err == nil {
+		t.Error("health check passed after the application stopped, want the pool closed")
+	}
+}
+
+func TestModuleFailsStartWithoutDatabase(t *testing.T) {
+	app := fx.New(
+		fx.NopLogger,
+		fx.Supply(gontextdi.Config{Driver: "sqlite", Options: gontext.DbContextOptions{
+			ConnectionString: filepath.Join(t.TempDir(), "missing", "gontext.db"),
+		}}),
+		gontextfx.Module,
+	)
+	if err := app.Start(context.Background()); err == nil {
+		app.Stop(context.Background())
+		t.Fatal("the application started without a reachable database")
+	}
+}
@@ -0,0 +1,25 @@
+// Package wiretest holds an injector generated from gontextwire.ProviderSet, so the set is checked by
+// the wire tool and its result by the tests; go generate regenerates wire_gen.go
+package wiretest
+
+import (
+	"github.com/shepherrrd/gontext"
+	"github.com/shepherrrd/gontext/gontextdi"
+)
+
+type note struct {
+	Id   int
+	Text string
+}
+
+// app is what an application's injector would build
+type app struct {
+	Root    *gontext.DbContext
+	Factory gontextdi.Factory
+	Health  gontextdi.HealthCheck
+	Notes   *gontext.LinqDbSet[note]
+}
+
+func provideNotes(ctx *gontext.DbContext) *gontext.LinqDbSet[note] {
+	return gontextdi.Set[note](ctx)
+}
@@ -0,0 +1,43 @@
+package wiretest
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/shepherrrd/gontext"
+	"github.com/shepherrrd/gontext/gontextdi"
+)
+
+func TestProviderSet(t *testing.T) {
+	config := gontextdi.Config{Driver: "sqlite", Options: gontext.DbContextOptions{
+		ConnectionString: filepath.Join(t.TempDir(), "gontext.db"),
+	}}
+	app, cleanup, err := newApp(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Root.EnsureCreated(); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Health(context.Background()); err != nil {
+		t.Fatalf("health check of the open context failed: %v", err)
+	}
+
+	scoped := app.Factory()
+	if scoped == app.Root {
+		t.Fatal("Factory returned the application context, want a scoped one")
+	}
+	gontext.NewLinqDbSet[note](scoped).Add(note{Text: "injected"})
+	if err := scoped.SaveChanges(); err != nil {
+		t.Fatal(err)
+	}
+	if count, err := app.Notes.Count(); err != nil || count != 1 {
+		t.Errorf("injected DbSet counted %d notes, %v; want the one the scoped context saved", count, err)
+	}
+
+	cleanup()
+	if err := app.Health(context.Background()); err == nil {
+		t.Error("health check passed after the injector's cleanup, want the pool closed")
+	}
+}
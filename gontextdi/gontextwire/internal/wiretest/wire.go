@@ -0,0 +1,15 @@
+//go:build wireinject
+
+package wiretest
+
+import (
+	"github.com/google/wire"
+
+	"github.com/shepherrrd/gontext/gontextdi"
+	"github.com/shepherrrd/gontext/gontextdi/gontextwire"
+)
+
+func newApp(config gontextdi.Config) (*app, func(), error) {
+	wire.Build(gontextwire.ProviderSet, provideNotes, wire.Struct(new(app), "*"))
+	return nil, nil, nil
+}
@@ -0,0 +1,32 @@
+// Code generated by Wire. DO NOT EDIT.
+
+//go:generate go run -mod=mod github.com/google/wire/cmd/wire
+//go:build !wireinject
+// +build !wireinject
+
+package wiretest
+
+import (
+	"github.com/shepherrrd/gontext/gontextdi"
+)
+
+// Injectors from wire.go:
+
+func newApp(config gontextdi.Config) (*app, func(), error) {
+	v, cleanup, err := gontextdi.Open(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	factory := gontextdi.NewFactory(v)
+	healthCheck := gontextdi.NewHealthCheck(v)
+	v2 := provideNotes(v)
+	wiretestApp := &app{
+		Root:    v,
+		Factory: factory,
+		Health:  healthCheck,
+		Notes:   v2,
+	}
+	return wiretestApp, func() {
+		cleanup()
+	}, nil
+}
@@ -0,0 +1,21 @@
+// Package gontextwire is the google/wire provider set of gontextdi. Injectors built with ProviderSet
+// take a gontextdi.Config and get the application context, closed by the injector's cleanup, its
+// Factory of scoped contexts and its HealthCheck:
+//
+//	func InitializeApp(config gontextdi.Config) (*App, func(), error) {
+//		wire.Build(gontextwire.ProviderSet, NewApp)
+//		return nil, nil, nil
+//	}
+//
+// Typed DbSets are added per entity with a provider calling gontextdi.Set[T]
+package gontextwire
+
+import (
+	"github.com/google/wire"
+
+	"github.com/shepherrrd/gontext/gontextdi"
+)
+
+// ProviderSet provides *gontext.DbContext, gontextdi.Factory and gontextdi.HealthCheck from a
+// gontextdi.Config
+var ProviderSet = wire.NewSet(gontextdi.Open, gontextdi.NewFactory, gontextdi.NewHealthCheck)
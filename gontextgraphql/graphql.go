@@ -0,0 +1,418 @@
+// Package gontextgraphql maps GraphQL selection sets and filter, order and pagination arguments onto
+// LinqDbSet chains, so resolvers load only the columns and navigations a query asks for
+//
+// The package does not depend on a GraphQL server library: resolvers pass the selection as []Field,
+// which gqlgen's collected fields convert to in a few lines (see the README)
+package gontextgraphql
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm/schema"
+
+	"github.com/shepherrrd/gontext"
+)
+
+// Field is one field of a GraphQL selection set
+type Field struct {
+	Name       string  // GraphQL field name, e.g. "author" or "createdAt"
+	Selections []Field // Sub-selection of an object field
+}
+
+// Order is one entry of an orderBy argument
+type Order struct {
+	Field string
+	Desc  bool
+}
+
+// Args are the list arguments of a resolver
+type Args struct {
+	// Filter maps fields to a value (equality) or to an operator map:
+	// eq, ne, gt, gte, lt, lte, in, notIn, contains, startsWith, endsWith, isNull
+	// "and" / "or" take lists of filters and "not" takes a filter; a navigation takes a nested filter:
+	//	{"age": {"gte": 18}, "author": {"userName": {"startsWith": "a"}}, "or": [...]}
+	Filter  map[string]interface{}
+	OrderBy []Order
+	First   *int // Rows to return; nil returns every row up to MaxFirst
+	Offset  int
+	// MaxFirst caps First so clients cannot request unbounded pages; 0 means no cap
+	MaxFirst int
+}
+
+// schemas caches parsed entity schemas; only Go field names and relationships are read from them,
+// so the naming strategy does not matter
+var schemas sync.Map
+
+func entitySchema[T any]() (*schema.Schema, error) {
+	return schema.Parse(new(T), &schemas, schema.NamingStrategy{})
+}
+
+// Apply runs Filter, Select, Order and pagination on set in one call:
+//
+//	func (r *queryResolver) Posts(ctx context.Context, where map[string]any, first *int) ([]*Post, error) {
+//		set, err := gontextgraphql.Apply(r.db.Posts, Selection(ctx), gontextgraphql.Args{Filter: where, First: first, MaxFirst: 100})
+//		...
+//		return set.ToList()
+//	}
+func Apply[T any](set *gontext.LinqDbSet[T], selection []Field, args Args) (*gontext.LinqDbSet[T], error) {
+	set, err := Filter(set, args.Filter)
+	if err != nil {
+		return nil, err
+	}
+	// Select after filtering so the columns are qualified against any navigation joins
+	if set, err = Select(set, selection); err != nil {
+		return nil, err
+	}
+	if set, err = OrderBy(set, args.OrderBy); err != nil {
+		return nil, err
+	}
+
+	first := -1
+	if args.First != nil {
+		first = *args.First
+	}
+	if args.MaxFirst > 0 && (first < 0 || first > args.MaxFirst) {
+		first = args.MaxFirst
+	}
+	if first >= 0 {
+		set = set.Take(first)
+	}
+	if args.Offset > 0 {
+		set = set.Skip(args.Offset)
+	}
+	return set, nil
+}
+
+// Select loads only the selected columns, plus the keys navigations need, and includes selected
+// navigations with their own selected columns. Fields that are not columns or navigations
+// (computed fields, __typename) are ignored. An empty selection leaves set unchanged
+func Select[T any](set *gontext.LinqDbSet[T], selection []Field) (*gontext.LinqDbSet[T], error) {
+	if len(selection) == 0 {
+		return set, nil
+	}
+	sch, err := entitySchema[T]()
+	if err != nil {
+		return nil, err
+	}
+
+	var includes []include
+	fields := project(sch, selection, "", nil, &includes)
+	set = set.SelectFields(fields...)
+	for _, include := range includes {
+		set = set.IncludeFields(include.path, include.fields...)
+	}
+	return set, nil
+}
+
+// include is a navigation path to preload with its projected fields
+type include struct {
+	path   string
+	fields []string
+}
+
+// project returns the Go field names to load from sch for a selection and collects the navigations
+// it selects; required are keys the parent navigation matches rows on
+func project(sch *schema.Schema, selection []Field, path string, required []string, includes *[]include) []string {
+	fields := newFieldSet()
+	for _, field := range sch.PrimaryFields {
+		fields.add(field.Name)
+	}
+	fields.add(required...)
+
+	for _, selected := range selection {
+		if relationship := lookUpRelationship(sch, selected.Name); relationship != nil {
+			fields.add(relationKeys(relationship, sch)...)
+
+			navigation := path + relationship.Name
+			*includes = append(*includes, include{path: navigation})
+			index := len(*includes) - 1
+			childFields := project(relationship.FieldSchema, selected.Selections, navigation+".", relationKeys(relationship, relationship.FieldSchema), includes)
+			(*includes)[index].fields = childFields
+			continue
+		}
+		if field := lookUpField(sch, selected.Name); field != nil {
+			fields.add(field.Name)
+		}
+	}
+	return fields.names
+}
+
+// relationKeys returns the fields of sch that a relationship matches rows on
+func relationKeys(relationship *schema.Relationship, sch *schema.Schema) []string {
+	var keys []string
+	for _, reference := range relationship.References {
+		for _, key := range []*schema.Field{reference.PrimaryKey, reference.ForeignKey} {
+			if key != nil && key.Schema != nil && key.Schema.ModelType == sch.ModelType {
+				keys = append(keys, key.Name)
+			}
+		}
+	}
+	return keys
+}
+
+// Filter translates a filter argument (see Args.Filter) into parameterized Where conditions
+// Unknown fields and operators are errors, so a misspelled filter never returns every row
+func Filter[T any](set *gontext.LinqDbSet[T], filter map[string]interface{}) (*gontext.LinqDbSet[T], error) {
+	if len(filter) == 0 {
+		return set, nil
+	}
+	sch, err := entitySchema[T]()
+	if err != nil {
+		return nil, err
+	}
+	predicate, err := buildFilter(sch, "", filter)
+	if err != nil {
+		return nil, err
+	}
+	return set.Where(predicate), nil
+}
+
+func buildFilter(sch *schema.Schema, path string, filter map[string]interface{}) (gontext.Predicate, error) {
+	var predicates []gontext.Predicate
+	for _, key := range sortedKeys(filter) {
+		value := filter[key]
+		switch key {
+		case "and", "or":
+			filters, ok := toSlice(value)
+			if !ok {
+				return nil, fmt.Errorf("filter %q expects a list of filters", key)
+			}
+			var children []gontext.Predicate
+			for _, child := range filters {
+				childFilter, ok := child.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("filter %q expects a list of filters", key)
+				}
+				predicate, err := buildFilter(sch, path, childFilter)
+				if err != nil {
+					return nil, err
+				}
+				children = append(children, predicate)
+			}
+			if key == "and" {
+				predicates = append(predicates, gontext.And(children...))
+			} else {
+				predicates = append(predicates, gontext.Or(children...))
+			}
+			continue
+		case "not":
+			childFilter, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("filter %q expects a filter", key)
+			}
+			predicate, err := buildFilter(sch, path, childFilter)
+			if err != nil {
+				return nil, err
+			}
+			predicates = append(predicates, gontext.Not(predicate))
+			continue
+		}
+
+		if relationship := lookUpRelationship(sch, key); relationship != nil {
+			childFilter, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("filter on navigation %q expects a nested filter", key)
+			}
+			if relationship.Type != schema.BelongsTo && relationship.Type != schema.HasOne {
+				return nil, fmt.Errorf("filter on %q: only single-valued navigations can be filtered", key)
+			}
+			predicate, err := buildFilter(relationship.FieldSchema, path+relationship.Name+".", childFilter)
+			if err != nil {
+				return nil, err
+			}
+			predicates = append(predicates, predicate)
+			continue
+		}
+
+		field := lookUpField(sch, key)
+		if field == nil {
+			return nil, fmt.Errorf("unknown filter field %q on %s", key, sch.Name)
+		}
+		predicate, err := fieldFilter(path+field.Name, value)
+		if err != nil {
+			return nil, err
+		}
+		predicates = append(predicates, predicate)
+	}
+	return gontext.And(predicates...), nil
+}
+
+// fieldFilter builds the conditions of one field: a plain value is equality, a map holds operators
+func fieldFilter(name string, value interface{}) (gontext.Predicate, error) {
+	field := gontext.FieldOf[interface{}](name)
+	operators, ok := value.(map[string]interface{})
+	if !ok {
+		return gontext.Eq(field, value), nil
+	}
+
+	text := gontext.FieldOf[string](name)
+	var predicates []gontext.Predicate
+	for _, operator := range sortedKeys(operators) {
+		operand := operators[operator]
+		switch operator {
+		case "eq":
+			predicates = append(predicates, gontext.Eq(field, operand))
+		case "ne", "neq":
+			predicates = append(predicates, gontext.Ne(field, operand))
+		case "gt":
+			predicates = append(predicates, gontext.Gt(field, operand))
+		case "gte":
+			predicates = append(predicates, gontext.Gte(field, operand))
+		case "lt":
+			predicates = append(predicates, gontext.Lt(field, operand))
+		case "lte":
+			predicates = append(predicates, gontext.Lte(field, operand))
+		case "in", "notIn":
+			values, ok := toSlice(operand)
+			if !ok {
+				return nil, fmt.Errorf("filter %s.%s expects a list", name, operator)
+			}
+			switch {
+			case len(values) == 0 && operator == "notIn":
+				// Excluding nothing matches every row
+			case len(values) == 0:
+				// IN () is invalid SQL; match no row instead
+				predicates = append(predicates, gontext.And(gontext.IsNull(field), gontext.IsNotNull(field)))
+			case operator == "notIn":
+				predicates = append(predicates, gontext.Not(gontext.In(field, values...)))
+			default:
+				predicates = append(predicates, gontext.In(field, values...))
+			}
+		case "contains", "startsWith", "endsWith":
+			pattern, ok := operand.(string)
+			if !ok {
+				return nil, fmt.Errorf("filter %s.%s expects a string", name, operator)
+			}
+			switch operator {
+			case "contains":
+				predicates = append(predicates, gontext.Contains(text, pattern))
+			case "startsWith":
+				predicates = append(predicates, gontext.StartsWith(text, pattern))
+			default:
+				predicates = append(predicates, gontext.EndsWith(text, pattern))
+			}
+		case "isNull":
+			isNull, ok := operand.(bool)
+			if !ok {
+				return nil, fmt.Errorf("filter %s.isNull expects a boolean", name)
+			}
+			if isNull {
+				predicates = append(predicates, gontext.IsNull(field))
+			} else {
+				predicates = append(predicates, gontext.IsNotNull(field))
+			}
+		default:
+			return nil, fmt.Errorf("unknown filter operator %q on %s", operator, name)
+		}
+	}
+	return gontext.And(predicates...), nil
+}
+
+// OrderBy applies an orderBy argument; unknown fields are errors
+func OrderBy[T any](set *gontext.LinqDbSet[T], orders []Order) (*gontext.LinqDbSet[T], error) {
+	if len(orders) == 0 {
+		return set, nil
+	}
+	sch, err := entitySchema[T]()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, order := range orders {
+		field := lookUpField(sch, order.Field)
+		if field == nil {
+			return nil, fmt.Errorf("unknown order field %q on %s", order.Field, sch.Name)
+		}
+		switch {
+		case i == 0 && order.Desc:
+			set = set.OrderByDescending(field.Name)
+		case i == 0:
+			set = set.OrderBy(field.Name)
+		case order.Desc:
+			set = set.ThenByDescending(field.Name)
+		default:
+			set = set.ThenBy(field.Name)
+		}
+	}
+	return set, nil
+}
+
+// lookUpField finds a column field by Go name, column name or json name, ignoring case
+func lookUpField(sch *schema.Schema, name string) *schema.Field {
+	if field := sch.LookUpField(name); field != nil && field.DBName != "" {
+		return field
+	}
+	for _, field := range sch.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		jsonName := strings.Split(field.Tag.Get("json"), ",")[0]
+		if jsonName == name || strings.EqualFold(field.Name, name) || strings.EqualFold(field.DBName, name) {
+			return field
+		}
+	}
+	return nil
+}
+
+// lookUpRelationship finds a navigation by Go name or json name, ignoring case
+func lookUpRelationship(sch *schema.Schema, name string) *schema.Relationship {
+	if relationship, ok := sch.Relationships.Relations[name]; ok {
+		return relationship
+	}
+	for relationName, relationship := range sch.Relationships.Relations {
+		jsonName := strings.Split(relationship.Field.Tag.Get("json"), ",")[0]
+		if jsonName == name || strings.EqualFold(relationName, name) {
+			return relationship
+		}
+	}
+	return nil
+}
+
+// toSlice converts any slice, e.g. []string from a generated resolver, to []interface{}
+func toSlice(value interface{}) ([]interface{}, bool) {
+	if values, ok := value.([]interface{}); ok {
+		return values, true
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice {
+		return nil, false
+	}
+	values := make([]interface{}, v.Len())
+	for i := range values {
+		values[i] = v.Index(i).Interface()
+	}
+	return values, true
+}
+
+// sortedKeys keeps the generated SQL stable for the same filter, which the query plan cache relies on
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// fieldSet collects field names in order without duplicates
+type fieldSet struct {
+	names []string
+	seen  map[string]bool
+}
+
+func newFieldSet() *fieldSet {
+	return &fieldSet{seen: make(map[string]bool)}
+}
+
+func (s *fieldSet) add(names ...string) {
+	for _, name := range names {
+		if !s.seen[name] {
+			s.seen[name] = true
+			s.names = append(s.names, name)
+		}
+	}
+}
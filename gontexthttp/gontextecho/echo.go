@@ -0,0 +1,45 @@
+// Package gontextecho runs every echo request in its own gontexthttp unit of work: a scoped DbContext in
+// the request's context.Context whose changes are saved (with AutoSave) and committed just before a
+// success header is sent, and discarded when the handler fails or panics
+//
+//	scopes := gontexthttp.New(ctx.DbContext, gontexthttp.Options{AutoSave: true, Transactional: true})
+//	e.Use(gontextecho.Middleware(scopes))
+//
+//	// Handlers: gontexthttp.Set[Post](c.Request().Context())
+package gontextecho
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/shepherrrd/gontext/gontexthttp"
+)
+
+// Middleware runs next in a unit of work of scopes. A request succeeds when next returns nil with a
+// status below 400; a failed save or commit answers 500 in place of the handler's response, or is
+// returned to echo's error handler when nothing was written yet
+func Middleware(scopes *gontexthttp.Scopes) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(e echo.Context) error {
+			unit, c, err := scopes.Begin(e.Request().Context())
+			if err != nil {
+				return err
+			}
+			defer unit.Close()
+
+			response := e.Response()
+			writer := response.Writer
+			response.Writer = unit.Writer(writer)
+			e.SetRequest(e.Request().WithContext(c))
+			if err := next(e); err != nil {
+				response.Writer = writer // The error handler answers after the unit is rolled back
+				return err
+			}
+			if response.Committed && response.Status >= http.StatusBadRequest {
+				return nil
+			}
+			return unit.Complete()
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package gontextecho_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/shepherrrd/gontext"
+	"github.com/shepherrrd/gontext/gontexthttp"
+	"github.com/shepherrrd/gontext/gontexthttp/gontextecho"
+)
+
+type echoNote struct {
+	Id   int
+	Text string
+}
+
+// newServer returns a server over a WAL SQLite file whose routes add a note each, and the application
+// context to count them with
+func newServer(t *testing.T) (*echo.Echo, *gontext.DbContext) {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "gontext.db") + "?_journal_mode=WAL&_busy_timeout=5000"
+	root, err := gontext.NewDbContext(dsn, "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { root.Close() })
+	gontext.RegisterEntity[echoNote](root)
+	if err := root.EnsureCreated(); err != nil {
+		t.Fatal(err)
+	}
+	if err := root.GetDB().Create(&echoNote{Id: 1, Text: "taken"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	server := echo.New()
+	server.Use(gontextecho.Middleware(gontexthttp.New(root, gontexthttp.Options{AutoSave: true, Transactional: true})))
+	add := func(e echo.Context, note echoNote) {
+		gontexthttp.Set[echoNote](e.Request().Context()).Add(note)
+	}
+	server.POST("/created", func(e echo.Context) error {
+		add(e, echoNote{Text: "created"})
+		return e.JSON(http.StatusCreated, map[string]bool{"created": true})
+	})
+	server.POST("/silent", func(e echo.Context) error {
+		add(e, echoNote{Text: "silent"})
+		return nil
+	})
+	server.POST("/duplicate", func(e echo.Context) error {
+		add(e, echoNote{Id: 1, Text: "duplicate"})
+		return e.String(http.StatusOK, "saved")
+	})
+	server.POST("/silent-duplicate", func(e echo.Context) error {
+		add(e, echoNote{Id: 1, Text: "duplicate"})
+		return nil
+	})
+	server.POST("/rejected", func(e echo.Context) error {
+		add(e, echoNote{Text: "rejected"})
+		return e.NoContent(http.StatusConflict)
+	})
+	server.POST("/failed", func(e echo.Context) error {
+		add(e, echoNote{Text: "failed"})
+		return errors.New("handler failed")
+	})
+	return server, root
+}
+
+// committedWriter records the notes committed when the header is sent
+type committedWriter struct {
+	*httptest.ResponseRecorder
+	root      *gontext.DbContext
+	committed int64
+}
+
+func (w *committedWriter) WriteHeader(status int) {
+	w.root.GetDB().Model(&echoNote{}).Count(&w.committed)
+	w.ResponseRecorder.WriteHeader(status)
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		path      string
+		status    int
+		committed int64 // Notes when the header was sent
+	}{
+		{"/created", http.StatusCreated, 2},
+		{"/duplicate", http.StatusInternalServerError, 1},
+		{"/rejected", http.StatusConflict, 1},
+		{"/failed", http.StatusInternalServerError, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			server, root := newServer(t)
+			writer := &committedWriter{ResponseRecorder: httptest.NewRecorder(), root: root}
+			server.ServeHTTP(writer, httptest.NewRequest(http.MethodPost, tt.path, nil))
+
+			if writer.Code != tt.status {
+				t.Errorf("answered %d, want %d", writer.Code, tt.status)
+			}
+			if writer.committed != tt.committed {
+				t.Errorf("%d notes committed when the header was sent, want %d", writer.committed, tt.committed)
+			}
+			var saved int64
+			root.GetDB().Model(&echoNote{}).Count(&saved)
+			if saved != tt.committed {
+				t.Errorf("%d notes saved, want %d", saved, tt.committed)
+			}
+			if tt.status == http.StatusInternalServerError && writer.Body.String() == "saved" {
+				t.Error("the handler's body followed the 500 answer")
+			}
+		})
+	}
+}
+
+func TestMiddlewareWithoutResponse(t *testing.T) {
+	tests := []struct {
+		path   string
+		status int
+		saved  int64
+	}{
+		{"/silent", http.StatusOK, 2},
+		{"/silent-duplicate", http.StatusInternalServerError, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			server, root := newServer(t)
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, tt.path, nil))
+
+			if recorder.Code != tt.status {
+				t.Errorf("answered %d, want %d", recorder.Code, tt.status)
+			}
+			var saved int64
+			root.GetDB().Model(&echoNote{}).Count(&saved)
+			if saved != tt.saved {
+				t.Errorf("%d notes saved, want %d", saved, tt.saved)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+// Package gontextfiber runs every fiber request in its own gontexthttp unit of work: a scoped DbContext
+// in the request's user context whose changes are saved (with AutoSave) and committed before the
+// response is sent, and discarded when the handlers fail or panic
+//
+//	scopes := gontexthttp.New(ctx.DbContext, gontexthttp.Options{AutoSave: true, Transactional: true})
+//	app.Use(gontextfiber.Middleware(scopes))
+//
+//	// Handlers: gontexthttp.Set[Post](f.UserContext())
+package gontextfiber
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/shepherrrd/gontext/gontexthttp"
+)
+
+// Middleware runs the rest of the chain in a unit of work of scopes. A request succeeds when the chain
+// returns nil with a status below 400. fasthttp sends the response once the chain has returned, so the
+// unit is completed first and a failed save or commit goes to fiber's error handler, which answers in
+// place of the handlers' response. Streamed bodies are the exception: they are written after the commit
+func Middleware(scopes *gontexthttp.Scopes) fiber.Handler {
+	return func(f *fiber.Ctx) error {
+		unit, c, err := scopes.Begin(f.UserContext())
+		if err != nil {
+			return err
+		}
+		defer unit.Close()
+
+		f.SetUserContext(c)
+		if err := f.Next(); err != nil {
+			return err
+		}
+		if f.Response().StatusCode() >= fiber.StatusBadRequest {
+			return nil
+		}
+		return unit.Complete()
+	}
+}
@@ -0,0 +1,104 @@
+package gontextfiber_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/shepherrrd/gontext"
+	"github.com/shepherrrd/gontext/gontexthttp"
+	"github.com/shepherrrd/gontext/gontexthttp/gontextfiber"
+)
+
+type fiberNote struct {
+	Id   int
+	Text string
+}
+
+// newApp returns an app over a WAL SQLite file whose routes add a note each, and the application
+// context to count them with
+func newApp(t *testing.T) (*fiber.App, *gontext.DbContext) {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "gontext.db") + "?_journal_mode=WAL&_busy_timeout=5000"
+	root, err := gontext.NewDbContext(dsn, "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { root.Close() })
+	gontext.RegisterEntity[fiberNote](root)
+	if err := root.EnsureCreated(); err != nil {
+		t.Fatal(err)
+	}
+	if err := root.GetDB().Create(&fiberNote{Id: 1, Text: "taken"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	app := fiber.New()
+	app.Use(gontextfiber.Middleware(gontexthttp.New(root, gontexthttp.Options{AutoSave: true, Transactional: true})))
+	add := func(f *fiber.Ctx, note fiberNote) {
+		gontexthttp.Set[fiberNote](f.UserContext()).Add(note)
+	}
+	app.Post("/created", func(f *fiber.Ctx) error {
+		add(f, fiberNote{Text: "created"})
+		return f.Status(fiber.StatusCreated).JSON(fiber.Map{"created": true})
+	})
+	app.Post("/silent", func(f *fiber.Ctx) error {
+		add(f, fiberNote{Text: "silent"})
+		return nil
+	})
+	app.Post("/duplicate", func(f *fiber.Ctx) error {
+		add(f, fiberNote{Id: 1, Text: "duplicate"})
+		return f.SendString("saved")
+	})
+	app.Post("/rejected", func(f *fiber.Ctx) error {
+		add(f, fiberNote{Text: "rejected"})
+		return f.SendStatus(fiber.StatusConflict)
+	})
+	app.Post("/failed", func(f *fiber.Ctx) error {
+		add(f, fiberNote{Text: "failed"})
+		return errors.New("handler failed")
+	})
+	return app, root
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		path   string
+		status int
+		saved  int64
+	}{
+		{"/created", http.StatusCreated, 2},
+		{"/silent", http.StatusOK, 2},
+		{"/duplicate", http.StatusInternalServerError, 1},
+		{"/rejected", http.StatusConflict, 1},
+		{"/failed", http.StatusInternalServerError, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			app, root := newApp(t)
+			response, err := app.Test(httptest.NewRequest(http.MethodPost, tt.path, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(response.Body)
+
+			if response.StatusCode != tt.status {
+				t.Errorf("answered %d %q, want %d", response.StatusCode, body, tt.status)
+			}
+			if tt.status == http.StatusInternalServerError && string(body) == "saved" {
+				t.Error("the handler's body followed the 500 answer")
+			}
+			var saved int64
+			root.GetDB().Model(&fiberNote{}).Count(&saved)
+			if saved != tt.saved {
+				t.Errorf("%d notes saved, want %d", saved, tt.saved)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Package gontextgin runs every gin request in its own gontexthttp unit of work: a scoped DbContext in
+// the request's context.Context whose changes are saved (with AutoSave) and committed just before a
+// success header is sent, and discarded when the handlers fail or panic
+//
+//	scopes := gontexthttp.New(ctx.DbContext, gontexthttp.Options{AutoSave: true, Transactional: true})
+//	router.Use(gontextgin.Middleware(scopes))
+//
+//	// Handlers: gontexthttp.Set[Post](g.Request.Context())
+package gontextgin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/shepherrrd/gontext/gontexthttp"
+)
+
+// Middleware runs the rest of the chain in a unit of work of scopes. A request succeeds when its status
+// is below 400 and no handler recorded an error with g.Error; a failed save or commit answers 500 in
+// place of the handlers' response and is recorded with g.Error
+func Middleware(scopes *gontexthttp.Scopes) gin.HandlerFunc {
+	return func(g *gin.Context) {
+		unit, c, err := scopes.Begin(g.Request.Context())
+		if err != nil {
+			g.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		defer unit.Close()
+
+		writer := &responseWriter{ResponseWriter: g.Writer, unit: unit, g: g}
+		g.Writer = writer
+		g.Request = g.Request.WithContext(c)
+		g.Next()
+		if !writer.Written() {
+			writer.WriteHeaderNow() // gin sends the header after the chain, bypassing this writer
+		}
+		if writer.err == nil && !writer.succeeded() {
+			return
+		}
+		if err := unit.Complete(); err != nil {
+			g.Error(err)
+		}
+	}
+}
+
+// responseWriter completes the unit of work before gin sends a success header; gin records the status
+// on WriteHeader and sends it with the first write, so the hooks are the writes
+type responseWriter struct {
+	gin.ResponseWriter
+	unit *gontexthttp.Unit
+	g    *gin.Context
+	err  error
+}
+
+func (w *responseWriter) succeeded() bool {
+	return w.Status() < http.StatusBadRequest && len(w.g.Errors) == 0
+}
+
+// complete runs before the header is sent and reports whether the handlers' response may follow
+func (w *responseWriter) complete() bool {
+	if w.Written() {
+		return w.err == nil
+	}
+	if !w.succeeded() {
+		return true
+	}
+	if err := w.unit.Complete(); err != nil {
+		w.err = err
+		w.ResponseWriter.WriteHeader(http.StatusInternalServerError)
+		w.ResponseWriter.WriteString(http.StatusText(http.StatusInternalServerError))
+		return false
+	}
+	return true
+}
+
+func (w *responseWriter) WriteHeaderNow() {
+	if w.complete() {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *responseWriter) Write(body []byte) (int, error) {
+	if !w.complete() {
+		return len(body), nil // The 500 answer replaced the handlers' response
+	}
+	return w.ResponseWriter.Write(body)
+}
+
+func (w *responseWriter) WriteString(body string) (int, error) {
+	if !w.complete() {
+		return len(body), nil
+	}
+	return w.ResponseWriter.WriteString(body)
+}
+
+func (w *responseWriter) Flush() {
+	w.WriteHeaderNow()
+	w.ResponseWriter.Flush()
+}
@@ -0,0 +1,117 @@
+package gontextgin_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/shepherrrd/gontext"
+	"github.com/shepherrrd/gontext/gontexthttp"
+	"github.com/shepherrrd/gontext/gontexthttp/gontextgin"
+)
+
+type ginNote struct {
+	Id   int
+	Text string
+}
+
+// newRouter returns a router over a WAL SQLite file whose routes add a note each, and the application
+// context to count them with
+func newRouter(t *testing.T) (*gin.Engine, *gontext.DbContext) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	dsn := filepath.Join(t.TempDir(), "gontext.db") + "?_journal_mode=WAL&_busy_timeout=5000"
+	root, err := gontext.NewDbContext(dsn, "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { root.Close() })
+	gontext.RegisterEntity[ginNote](root)
+	if err := root.EnsureCreated(); err != nil {
+		t.Fatal(err)
+	}
+	if err := root.GetDB().Create(&ginNote{Id: 1, Text: "taken"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	router.Use(gontextgin.Middleware(gontexthttp.New(root, gontexthttp.Options{AutoSave: true, Transactional: true})))
+	add := func(g *gin.Context, note ginNote) {
+		gontexthttp.Set[ginNote](g.Request.Context()).Add(note)
+	}
+	router.POST("/created", func(g *gin.Context) {
+		add(g, ginNote{Text: "created"})
+		var committed int64
+		root.GetDB().Model(&ginNote{}).Count(&committed)
+		g.JSON(http.StatusCreated, gin.H{"created": true})
+		if committed != 1 {
+			t.Errorf("%d notes committed while the handler ran, want only the seed", committed)
+		}
+	})
+	router.POST("/silent", func(g *gin.Context) { add(g, ginNote{Text: "silent"}) })
+	router.POST("/duplicate", func(g *gin.Context) {
+		add(g, ginNote{Id: 1, Text: "duplicate"})
+		g.String(http.StatusOK, "saved")
+	})
+	router.POST("/rejected", func(g *gin.Context) {
+		add(g, ginNote{Text: "rejected"})
+		g.AbortWithStatus(http.StatusUnprocessableEntity)
+	})
+	router.POST("/errored", func(g *gin.Context) {
+		add(g, ginNote{Text: "errored"})
+		g.Error(gontexthttp.ErrUnsuccessfulResponse)
+	})
+	return router, root
+}
+
+// committedWriter records the notes committed when the header is sent
+type committedWriter struct {
+	*httptest.ResponseRecorder
+	root      *gontext.DbContext
+	committed int64
+}
+
+func (w *committedWriter) WriteHeader(status int) {
+	w.root.GetDB().Model(&ginNote{}).Count(&w.committed)
+	w.ResponseRecorder.WriteHeader(status)
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		path      string
+		status    int
+		committed int64 // Notes when the header was sent
+	}{
+		{"/created", http.StatusCreated, 2},
+		{"/silent", http.StatusOK, 2},
+		{"/duplicate", http.StatusInternalServerError, 1},
+		{"/rejected", http.StatusUnprocessableEntity, 1},
+		{"/errored", http.StatusOK, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			router, root := newRouter(t)
+			writer := &committedWriter{ResponseRecorder: httptest.NewRecorder(), root: root}
+			router.ServeHTTP(writer, httptest.NewRequest(http.MethodPost, tt.path, nil))
+
+			if writer.Code != tt.status {
+				t.Errorf("answered %d, want %d", writer.Code, tt.status)
+			}
+			if writer.committed != tt.committed {
+				t.Errorf("%d notes committed when the header was sent, want %d", writer.committed, tt.committed)
+			}
+			var saved int64
+			root.GetDB().Model(&ginNote{}).Count(&saved)
+			if saved != tt.committed {
+				t.Errorf("%d notes saved, want %d", saved, tt.committed)
+			}
+			if tt.status == http.StatusInternalServerError && writer.Body.String() == "saved" {
+				t.Error("the handler's body followed the 500 answer")
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+// Package gontexthttp gives every HTTP request its own scoped DbContext: the middleware creates it
+// from the application's context, stores it in the request's context.Context, saves its tracked changes
+// when the handler succeeds (optional) and discards them - rolling back the request's transaction when
+// one is used - when the handler fails or panics
+//
+// The package has no web framework dependency: Middleware wraps net/http handlers, and the gontextgin,
+// gontextecho and gontextfiber modules below it adapt Begin and Complete to those frameworks
+package gontexthttp
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"gorm.io/gorm"
+
+	"github.com/shepherrrd/gontext"
+)
+
+// ErrUnsuccessfulResponse marks a request whose handler answered with a 4xx or 5xx status; Run discards
+// its changes like those of a handler that returned an error, and the middleware does not report it
+var ErrUnsuccessfulResponse = errors.New("handler answered with an error status")
+
+// Options configure the unit of work of each request
+type Options struct {
+	// AutoSave calls SaveChanges on the request's context when the handler succeeds; without it,
+	// handlers save explicitly with SaveChanges and unsaved changes are dropped with the scope
+	AutoSave bool
+	// Transactional runs each request in a transaction, committed when the handler succeeds and rolled
+	// back when it fails or panics, so direct writes (ExecuteUpdate, raw SQL) are undone as well
+	Transactional bool
+	// IsolationLevel of the request transactions; IsolationDefault keeps the database default
+	IsolationLevel gontext.IsolationLevel
+	// OnError receives the save and commit errors of the net/http middleware: those it answered with 500
+	// and those of changes saved after the response was sent (default: logged)
+	OnError func(r *http.Request, err error)
+}
+
+// Scopes creates the per-request contexts of one application context
+type Scopes struct {
+	root    *gontext.DbContext
+	options Options
+}
+
+// errUnitClosed is returned when a unit of work is first completed after Close rolled it back, e.g. by
+// a framework error handler answering once the middleware returned
+var errUnitClosed = errors.New("gontexthttp: the unit of work is closed")
+
+// scopeKey stores the request's scoped context in its context.Context
+type scopeKey struct{}
+
+// New creates request scopes over root, the application's context configured at startup
+//
+//	scopes := gontexthttp.New(ctx.DbContext, gontexthttp.Options{AutoSave: true})
+//	http.ListenAndServe(":8080", scopes.Middleware(mux))
+func New(root *gontext.DbContext, options Options) *Scopes {
+	return &Scopes{root: root, options: options}
+}
+
+// Unit is the unit of work of one request: its scoped context and, with Transactional, the transaction
+// it is enlisted in. Begin starts one, Complete saves and commits it and Close ends it, rolling back
+// what was not completed. Run and Middleware drive units for net/http; framework middleware calls
+// Complete just before its response header is sent, as Writer does for net/http writers
+type Unit struct {
+	options   Options
+	scope     *gontext.DbContext
+	tx        *gorm.DB
+	completed bool
+	closed    bool
+	err       error
+}
+
+// Begin starts the unit of work of a request and returns it with c carrying its scoped context; the
+// caller must Close it
+func (s *Scopes) Begin(c context.Context) (*Unit, context.Context, error) {
+	unit := &Unit{options: s.options, scope: s.root.NewScopedContext()}
+	if s.options.Transactional {
+		tx := unit.scope.BeginTransaction(s.options.IsolationLevel)
+		if tx.Error != nil {
+			unit.scope.Close()
+			return nil, c, gontext.TranslateError(tx.Error)
+		}
+		if err := unit.scope.UseTransaction(tx); err != nil {
+			tx.Rollback()
+			unit.scope.Close()
+			return nil, c, err
+		}
+		unit.tx = tx
+	}
+	return unit, context.WithValue(c, scopeKey{}, unit.scope), nil
+}
+
+// Complete ends a successful unit of work: its changes are saved when AutoSave is set and its
+// transaction is committed. A failed save rolls the transaction back. Later calls return the error of
+// the first; after a successful one they save, with AutoSave, the changes made since, which run in their
+// own transaction because the request's is committed
+func (u *Unit) Complete() error {
+	if u.completed {
+		if u.err != nil || !u.options.AutoSave || u.closed {
+			return u.err
+		}
+		return u.scope.SaveChanges()
+	}
+	if u.closed {
+		return errUnitClosed
+	}
+	u.completed = true
+	if u.options.AutoSave {
+		if u.err = u.scope.SaveChanges(); u.err != nil {
+			u.rollback()
+			return u.err
+		}
+	}
+	if u.tx != nil {
+		u.scope.UseTransaction(nil)
+		u.err = gontext.TranslateError(u.tx.Commit().Error)
+		u.tx = nil
+	}
+	return u.err
+}
+
+// Close ends the unit of work, rolling back its transaction unless Complete committed it, and closes
+// its scoped context. Deferred right after Begin, it also rolls back when the handler panics
+func (u *Unit) Close() {
+	u.closed = true
+	u.rollback()
+	u.scope.Close()
+}
+
+func (u *Unit) rollback() {
+	if u.tx != nil {
+		u.scope.UseTransaction(nil)
+		u.tx.Rollback()
+		u.tx = nil
+	}
+}
+
+// Writer wraps w so that the unit is completed just before the header of a status below 400 is
+// written; when Complete fails, the request is answered with 500 instead and the handler's body dropped.
+// Error statuses are written as they are and leave the unit to be rolled back by Close
+func (u *Unit) Writer(w http.ResponseWriter) http.ResponseWriter {
+	return &responseWriter{ResponseWriter: w, unit: u, status: http.StatusOK}
+}
+
+// Run runs handler with a new scoped context stored in c, for units of work outside net/http. The
+// handler fails by returning an error (ErrUnsuccessfulResponse for error statuses); its changes are
+// then discarded and the error returned. On success the unit is completed, and a failed save or commit
+// is returned. Panics roll back and propagate
+func (s *Scopes) Run(c context.Context, handler func(c context.Context) error) error {
+	unit, c, err := s.Begin(c)
+	if err != nil {
+		return err
+	}
+	defer unit.Close()
+
+	if err := handler(c); err != nil {
+		return err
+	}
+	return unit.Complete()
+}
+
+// Middleware runs each request of next in its own unit of work. A status below 400 is success: the
+// changes are saved (with AutoSave) and the transaction committed just before the response header is
+// written, so a failed save or commit still answers 500 instead of a success the client would trust.
+// With AutoSave, changes made after the header are saved when the handler returns, outside the
+// committed transaction, and their failures go to OnError
+func (s *Scopes) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		unit, c, err := s.Begin(r.Context())
+		if err != nil {
+			s.report(r, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		defer unit.Close()
+
+		writer := &responseWriter{ResponseWriter: w, unit: unit, status: http.StatusOK}
+		next.ServeHTTP(writer, r.WithContext(c))
+		if !writer.wroteHeader {
+			writer.WriteHeader(http.StatusOK) // What net/http would send for a handler that wrote nothing
+		}
+		if writer.status >= http.StatusBadRequest && writer.err == nil {
+			return
+		}
+		if err := unit.Complete(); err != nil {
+			s.report(r, err)
+		}
+	})
+}
+
+// report passes an error that could not be answered to OnError
+func (s *Scopes) report(r *http.Request, err error) {
+	if s.options.OnError != nil {
+		s.options.OnError(r, err)
+		return
+	}
+	log.Printf("gontexthttp: %s %s: %v", r.Method, r.URL.Path, err)
+}
+
+// Context returns the scoped context of the request, or nil outside a scope
+func Context(c context.Context) *gontext.DbContext {
+	scope, _ := c.Value(scopeKey{}).(*gontext.DbContext)
+	return scope
+}
+
+// Set returns a LINQ set of T on the request's scoped context; in a transactional scope its queries
+// run in the request's transaction. It panics outside a scope, where the middleware is missing
+//
+//	users, err := gontexthttp.Set[User](r.Context()).Where("IsActive", true).ToList()
+func Set[T any](c context.Context) *gontext.LinqDbSet[T] {
+	scope := Context(c)
+	if scope == nil {
+		panic("gontexthttp: the request has no scoped DbContext; wrap the handler with the middleware")
+	}
+	return gontext.NewLinqDbSet[T](scope)
+}
+
+// SaveChanges saves the request's tracked changes now, for handlers that answer according to the outcome
+func SaveChanges(c context.Context) error {
+	scope := Context(c)
+	if scope == nil {
+		return errors.New("gontexthttp: the request has no scoped DbContext")
+	}
+	return scope.SaveChanges()
+}
+
+// responseWriter records the status and completes the unit of work before a success header is sent
+type responseWriter struct {
+	http.ResponseWriter
+	unit        *Unit
+	status      int
+	wroteHeader bool
+	err         error
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	if status < http.StatusOK {
+		w.ResponseWriter.WriteHeader(status) // Informational headers precede the answer
+		return
+	}
+	w.wroteHeader = true
+	w.status = status
+	if status < http.StatusBadRequest {
+		if err := w.unit.Complete(); err != nil {
+			w.err = err
+			http.Error(w.ResponseWriter, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(body []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.err != nil {
+		return len(body), nil // The 500 answer replaced the handler's response
+	}
+	return w.ResponseWriter.Write(body)
+}
+
+// Flush sends the header first, so the unit is completed before a flushed success reaches the client
+func (w *responseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer (deadlines, hijacking)
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
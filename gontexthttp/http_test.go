@@ -0,0 +1,214 @@
+package gontexthttp_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/shepherrrd/gontext"
+	"github.com/shepherrrd/gontext/gontexthttp"
+)
+
+type requestNote struct {
+	Id   int
+	Text string
+}
+
+// newRoot returns an application context on a WAL SQLite file, where reads outside a request's
+// transaction see only what it committed
+func newRoot(t *testing.T) *gontext.DbContext {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "gontext.db") + "?_journal_mode=WAL&_busy_timeout=5000"
+	root, err := gontext.NewDbContext(dsn, "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { root.Close() })
+	gontext.RegisterEntity[requestNote](root)
+	if err := root.EnsureCreated(); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func countNotes(t *testing.T, root *gontext.DbContext) int64 {
+	t.Helper()
+	count, err := gontext.NewLinqDbSet[requestNote](root).Count()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+// headerProbe records how many notes were committed when the response header was sent
+type headerProbe struct {
+	*httptest.ResponseRecorder
+	t         *testing.T
+	root      *gontext.DbContext
+	committed int64
+}
+
+func (p *headerProbe) WriteHeader(status int) {
+	p.committed = countNotes(p.t, p.root)
+	p.ResponseRecorder.WriteHeader(status)
+}
+
+func serve(scopes *gontexthttp.Scopes, handler http.HandlerFunc) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	scopes.Middleware(handler).ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/notes", nil))
+	return recorder
+}
+
+func TestMiddlewareCommitsBeforeTheHeader(t *testing.T) {
+	for _, options := range []gontexthttp.Options{
+		{AutoSave: true},
+		{AutoSave: true, Transactional: true},
+		{Transactional: true},
+	} {
+		root := newRoot(t)
+		scopes := gontexthttp.New(root, options)
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gontexthttp.Set[requestNote](r.Context()).Add(requestNote{Text: "tracked"})
+			if err := gontexthttp.Context(r.Context()).GetDB().Exec(`INSERT INTO request_notes (text) VALUES ('direct')`).Error; err != nil {
+				t.Error(err)
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("created"))
+		})
+
+		probe := &headerProbe{ResponseRecorder: httptest.NewRecorder(), t: t, root: root}
+		scopes.Middleware(handler).ServeHTTP(probe, httptest.NewRequest(http.MethodPost, "/notes", nil))
+
+		want := int64(1)
+		if options.AutoSave {
+			want = 2
+		}
+		if probe.Code != http.StatusCreated || probe.Body.String() != "created" {
+			t.Errorf("%+v: answered %d %q, want the handler's 201", options, probe.Code, probe.Body)
+		}
+		if probe.committed != want {
+			t.Errorf("%+v: %d notes committed when the header was sent, want %d", options, probe.committed, want)
+		}
+		if count := countNotes(t, root); count != want {
+			t.Errorf("%+v: %d notes saved, want %d", options, count, want)
+		}
+	}
+}
+
+func TestMiddlewareAnswers500WhenTheSaveFails(t *testing.T) {
+	root := newRoot(t)
+	if err := root.GetDB().Create(&requestNote{Id: 1, Text: "taken"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	var reported error
+	scopes := gontexthttp.New(root, gontexthttp.Options{AutoSave: true, Transactional: true,
+		OnError: func(r *http.Request, err error) { reported = err }})
+
+	recorder := serve(scopes, func(w http.ResponseWriter, r *http.Request) {
+		if err := gontexthttp.Context(r.Context()).GetDB().Exec(`INSERT INTO request_notes (text) VALUES ('direct')`).Error; err != nil {
+			t.Error(err)
+		}
+		gontexthttp.Set[requestNote](r.Context()).Add(requestNote{Id: 1, Text: "duplicate"})
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("created"))
+	})
+
+	if recorder.Code != http.StatusInternalServerError || recorder.Body.String() == "created" {
+		t.Errorf("answered %d %q, want 500 without the handler's body", recorder.Code, recorder.Body)
+	}
+	if reported == nil {
+		t.Error("the failed save was not reported to OnError")
+	}
+	if count := countNotes(t, root); count != 1 {
+		t.Errorf("%d notes after the failed save, want the direct insert rolled back", count)
+	}
+}
+
+func TestMiddlewareRollsBackFailedRequests(t *testing.T) {
+	root := newRoot(t)
+	scopes := gontexthttp.New(root, gontexthttp.Options{AutoSave: true, Transactional: true})
+	write := func(r *http.Request) {
+		gontexthttp.Set[requestNote](r.Context()).Add(requestNote{Text: "tracked"})
+		if err := gontexthttp.Context(r.Context()).GetDB().Exec(`INSERT INTO request_notes (text) VALUES ('direct')`).Error; err != nil {
+			t.Error(err)
+		}
+	}
+
+	recorder := serve(scopes, func(w http.ResponseWriter, r *http.Request) {
+		write(r)
+		http.Error(w, "invalid note", http.StatusBadRequest)
+	})
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("answered %d, want the handler's 400", recorder.Code)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("the handler's panic was not propagated")
+			}
+		}()
+		serve(scopes, func(w http.ResponseWriter, r *http.Request) {
+			write(r)
+			panic("handler failed")
+		})
+	}()
+
+	if count := countNotes(t, root); count != 0 {
+		t.Errorf("%d notes saved by failed requests, want 0", count)
+	}
+	// The rolled back transactions released the database
+	if recorder := serve(scopes, func(w http.ResponseWriter, r *http.Request) { write(r) }); recorder.Code != http.StatusOK {
+		t.Errorf("answered %d after the failed requests, want 200", recorder.Code)
+	}
+	if count := countNotes(t, root); count != 2 {
+		t.Errorf("%d notes saved by the last request, want 2", count)
+	}
+}
+
+func TestMiddlewareSavesChangesAfterTheHeader(t *testing.T) {
+	root := newRoot(t)
+	scopes := gontexthttp.New(root, gontexthttp.Options{AutoSave: true, Transactional: true})
+
+	recorder := serve(scopes, func(w http.ResponseWriter, r *http.Request) {
+		gontexthttp.Set[requestNote](r.Context()).Add(requestNote{Text: "before"})
+		w.Write([]byte("streaming"))
+		gontexthttp.Set[requestNote](r.Context()).Add(requestNote{Text: "after"})
+	})
+	if recorder.Code != http.StatusOK {
+		t.Errorf("answered %d, want 200", recorder.Code)
+	}
+	if count := countNotes(t, root); count != 2 {
+		t.Errorf("%d notes saved, want the changes of both sides of the header", count)
+	}
+}
+
+func TestRun(t *testing.T) {
+	root := newRoot(t)
+	scopes := gontexthttp.New(root, gontexthttp.Options{AutoSave: true, Transactional: true})
+	failed := errors.New("handler failed")
+
+	err := scopes.Run(context.Background(), func(c context.Context) error {
+		gontexthttp.Set[requestNote](c).Add(requestNote{Text: "discarded"})
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Errorf("Run returned %v, want the handler's error", err)
+	}
+	err = scopes.Run(context.Background(), func(c context.Context) error {
+		gontexthttp.Set[requestNote](c).Add(requestNote{Text: "saved"})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count := countNotes(t, root); count != 1 {
+		t.Errorf("%d notes saved, want only the successful run's", count)
+	}
+	if gontexthttp.Context(context.Background()) != nil {
+		t.Error("a context outside a scope returned a scoped DbContext")
+	}
+}
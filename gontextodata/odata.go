@@ -0,0 +1,478 @@
+// Package gontextodata parses OData-style query strings into validated LinqDbSet chains, so CRUD
+// endpoints get filtering, sorting, paging and projections from the URL:
+//
+//	GET /users?filter=age gt 30 and isActive eq true&orderby=createdAt desc&top=20&skip=40
+//
+// Only fields on the allowlist can be filtered, sorted or selected, and every value is a bound parameter
+package gontextodata
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/shepherrrd/gontext"
+	"github.com/shepherrrd/gontext/gontextgraphql"
+)
+
+// ErrInvalidQuery wraps every parse and validation error; handlers can map it to 400 Bad Request
+var ErrInvalidQuery = errors.New("invalid query")
+
+// Options configure which fields a query may reference and how large a page may be
+type Options struct {
+	// Fields lists the fields that may appear in filter, orderby and select, by Go name, column or
+	// json name; navigation paths use a dot or slash: "author.userName"
+	Fields []string
+	// DefaultTop is the page size when top is absent; 0 returns every row up to MaxTop
+	DefaultTop int
+	// MaxTop caps top; 0 means no cap
+	MaxTop int
+}
+
+// Query is a parsed query string
+type Query struct {
+	Filter  map[string]interface{} // In the gontextgraphql filter format
+	OrderBy []gontextgraphql.Order
+	Select  []gontextgraphql.Field
+	Top     *int
+	Skip    int
+}
+
+// Apply parses values and applies them to set:
+//
+//	users, err := gontextodata.Apply(ctx.Users, r.URL.Query(), gontextodata.Options{
+//		Fields: []string{"name", "age", "isActive", "createdAt"}, MaxTop: 100,
+//	})
+//	if errors.Is(err, gontextodata.ErrInvalidQuery) { http.Error(w, err.Error(), http.StatusBadRequest) }
+func Apply[T any](set *gontext.LinqDbSet[T], values url.Values, options Options) (*gontext.LinqDbSet[T], error) {
+	query, err := Parse(values, options)
+	if err != nil {
+		return nil, err
+	}
+
+	set, err = gontextgraphql.Apply(set, query.Select, gontextgraphql.Args{
+		Filter:  query.Filter,
+		OrderBy: query.OrderBy,
+		First:   query.Top,
+		Offset:  query.Skip,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+	}
+	return set, nil
+}
+
+// Parse reads filter, orderby, select, top and skip (each also accepted with a $ prefix) and checks
+// every referenced field against the allowlist
+func Parse(values url.Values, options Options) (*Query, error) {
+	allowed := make(map[string]bool, len(options.Fields))
+	for _, field := range options.Fields {
+		allowed[normalizePath(field)] = true
+	}
+	checkField := func(path string) error {
+		if !allowed[normalizePath(path)] {
+			return fmt.Errorf("%w: field %q is not allowed", ErrInvalidQuery, path)
+		}
+		return nil
+	}
+
+	query := &Query{}
+	if filter := param(values, "filter"); filter != "" {
+		tokens, err := tokenizeFilter(filter)
+		if err != nil {
+			return nil, err
+		}
+		parser := &filterParser{tokens: tokens, checkField: checkField}
+		if query.Filter, err = parser.parse(); err != nil {
+			return nil, err
+		}
+	}
+
+	if orderBy := param(values, "orderby"); orderBy != "" {
+		for _, part := range strings.Split(orderBy, ",") {
+			words := strings.Fields(part)
+			if len(words) == 0 || len(words) > 2 {
+				return nil, fmt.Errorf("%w: invalid orderby %q", ErrInvalidQuery, part)
+			}
+			if err := checkField(words[0]); err != nil {
+				return nil, err
+			}
+			order := gontextgraphql.Order{Field: words[0]}
+			if len(words) == 2 {
+				switch strings.ToLower(words[1]) {
+				case "asc":
+				case "desc":
+					order.Desc = true
+				default:
+					return nil, fmt.Errorf("%w: invalid orderby direction %q", ErrInvalidQuery, words[1])
+				}
+			}
+			if strings.ContainsAny(order.Field, "./") {
+				return nil, fmt.Errorf("%w: cannot order by navigation field %q", ErrInvalidQuery, order.Field)
+			}
+			query.OrderBy = append(query.OrderBy, order)
+		}
+	}
+
+	if selection := param(values, "select"); selection != "" {
+		for _, path := range strings.Split(selection, ",") {
+			path = strings.TrimSpace(path)
+			if err := checkField(path); err != nil {
+				return nil, err
+			}
+			query.Select = addSelection(query.Select, splitPath(path))
+		}
+	}
+
+	top := options.DefaultTop
+	hasTop := top > 0
+	if value := param(values, "top"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("%w: top must be a non-negative integer", ErrInvalidQuery)
+		}
+		top, hasTop = n, true
+	}
+	if options.MaxTop > 0 && (!hasTop || top > options.MaxTop) {
+		top, hasTop = options.MaxTop, true
+	}
+	if hasTop {
+		query.Top = &top
+	}
+
+	if value := param(values, "skip"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("%w: skip must be a non-negative integer", ErrInvalidQuery)
+		}
+		query.Skip = n
+	}
+	return query, nil
+}
+
+// param returns name or $name
+func param(values url.Values, name string) string {
+	if value := values.Get(name); value != "" {
+		return value
+	}
+	return values.Get("$" + name)
+}
+
+func splitPath(path string) []string {
+	return strings.FieldsFunc(path, func(r rune) bool { return r == '.' || r == '/' })
+}
+
+func normalizePath(path string) string {
+	return strings.ToLower(strings.Join(splitPath(strings.TrimSpace(path)), "."))
+}
+
+// addSelection merges a field path into a selection set
+func addSelection(selection []gontextgraphql.Field, path []string) []gontextgraphql.Field {
+	if len(path) == 0 {
+		return selection
+	}
+	for i := range selection {
+		if strings.EqualFold(selection[i].Name, path[0]) {
+			selection[i].Selections = addSelection(selection[i].Selections, path[1:])
+			return selection
+		}
+	}
+	return append(selection, gontextgraphql.Field{Name: path[0], Selections: addSelection(nil, path[1:])})
+}
+
+// filterToken is a token of a filter expression
+type filterToken struct {
+	kind     filterTokenKind
+	text     string
+	value    interface{} // Literal value
+	position int
+}
+
+type filterTokenKind int
+
+const (
+	tokenWord    filterTokenKind = iota // Field name, operator or keyword
+	tokenLiteral                        // String, number, true, false or null
+	tokenOpen
+	tokenClose
+	tokenComma
+	tokenEnd
+)
+
+func tokenizeFilter(filter string) ([]filterToken, error) {
+	var tokens []filterToken
+	runes := []rune(filter)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+		switch {
+		case r == ' ' || r == '\t':
+			i++
+			continue
+		case r == '(':
+			tokens = append(tokens, filterToken{kind: tokenOpen, text: "(", position: start})
+			i++
+		case r == ')':
+			tokens = append(tokens, filterToken{kind: tokenClose, text: ")", position: start})
+			i++
+		case r == ',':
+			tokens = append(tokens, filterToken{kind: tokenComma, text: ",", position: start})
+			i++
+		case r == '\'':
+			var text strings.Builder
+			closed := false
+			for i++; i < len(runes); i++ {
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						text.WriteRune('\'')
+						i++
+						continue
+					}
+					closed = true
+					i++
+					break
+				}
+				text.WriteRune(runes[i])
+			}
+			if !closed {
+				return nil, fmt.Errorf("%w: unterminated string at position %d", ErrInvalidQuery, start)
+			}
+			tokens = append(tokens, filterToken{kind: tokenLiteral, text: string(runes[start:i]), value: text.String(), position: start})
+		case r == '-' || (r >= '0' && r <= '9'):
+			i++
+			for i < len(runes) && (runes[i] >= '0' && runes[i] <= '9' || runes[i] == '.') {
+				i++
+			}
+			text := string(runes[start:i])
+			var value interface{}
+			if strings.Contains(text, ".") {
+				number, err := strconv.ParseFloat(text, 64)
+				if err != nil {
+					return nil, fmt.Errorf("%w: invalid number %q at position %d", ErrInvalidQuery, text, start)
+				}
+				value = number
+			} else {
+				number, err := strconv.ParseInt(text, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("%w: invalid number %q at position %d", ErrInvalidQuery, text, start)
+				}
+				value = number
+			}
+			tokens = append(tokens, filterToken{kind: tokenLiteral, text: text, value: value, position: start})
+		case r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+			for i < len(runes) && (runes[i] == '_' || runes[i] == '.' || runes[i] == '/' ||
+				runes[i] >= 'a' && runes[i] <= 'z' || runes[i] >= 'A' && runes[i] <= 'Z' || runes[i] >= '0' && runes[i] <= '9') {
+				i++
+			}
+			text := string(runes[start:i])
+			switch strings.ToLower(text) {
+			case "true":
+				tokens = append(tokens, filterToken{kind: tokenLiteral, text: text, value: true, position: start})
+			case "false":
+				tokens = append(tokens, filterToken{kind: tokenLiteral, text: text, value: false, position: start})
+			case "null":
+				tokens = append(tokens, filterToken{kind: tokenLiteral, text: text, value: nil, position: start})
+			default:
+				tokens = append(tokens, filterToken{kind: tokenWord, text: text, position: start})
+			}
+		default:
+			return nil, fmt.Errorf("%w: unexpected %q at position %d", ErrInvalidQuery, r, start)
+		}
+	}
+	return append(tokens, filterToken{kind: tokenEnd, position: len(runes)}), nil
+}
+
+// comparisonOperators maps OData operators to gontextgraphql filter operators
+var comparisonOperators = map[string]string{
+	"eq": "eq", "ne": "ne", "gt": "gt", "ge": "gte", "lt": "lt", "le": "lte", "in": "in",
+}
+
+// functionOperators maps OData string functions to gontextgraphql filter operators
+var functionOperators = map[string]string{
+	"contains": "contains", "startswith": "startsWith", "endswith": "endsWith",
+}
+
+// filterParser is a recursive descent parser producing a gontextgraphql filter:
+//
+//	or      = and { "or" and }
+//	and     = unary { "and" unary }
+//	unary   = "not" unary | "(" or ")" | function "(" field "," literal ")" | field operator value
+//	value   = literal | "(" literal { "," literal } ")"
+type filterParser struct {
+	tokens     []filterToken
+	position   int
+	checkField func(path string) error
+}
+
+func (p *filterParser) parse() (map[string]interface{}, error) {
+	filter, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if token := p.peek(); token.kind != tokenEnd {
+		return nil, p.unexpected(token)
+	}
+	return filter, nil
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.position]
+}
+
+func (p *filterParser) next() filterToken {
+	token := p.tokens[p.position]
+	if token.kind != tokenEnd {
+		p.position++
+	}
+	return token
+}
+
+func (p *filterParser) isKeyword(keyword string) bool {
+	token := p.peek()
+	return token.kind == tokenWord && strings.EqualFold(token.text, keyword)
+}
+
+func (p *filterParser) unexpected(token filterToken) error {
+	if token.kind == tokenEnd {
+		return fmt.Errorf("%w: unexpected end of filter", ErrInvalidQuery)
+	}
+	return fmt.Errorf("%w: unexpected %q at position %d", ErrInvalidQuery, token.text, token.position)
+}
+
+func (p *filterParser) expect(kind filterTokenKind) (filterToken, error) {
+	token := p.next()
+	if token.kind != kind {
+		return token, p.unexpected(token)
+	}
+	return token, nil
+}
+
+func (p *filterParser) parseOr() (map[string]interface{}, error) {
+	return p.parseJunction("or", p.parseAnd)
+}
+
+func (p *filterParser) parseAnd() (map[string]interface{}, error) {
+	return p.parseJunction("and", p.parseUnary)
+}
+
+// parseJunction parses operands separated by keyword into {"and": [...]} or {"or": [...]}
+func (p *filterParser) parseJunction(keyword string, operand func() (map[string]interface{}, error)) (map[string]interface{}, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	operands := []interface{}{first}
+	for p.isKeyword(keyword) {
+		p.next()
+		filter, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, filter)
+	}
+	if len(operands) == 1 {
+		return first, nil
+	}
+	return map[string]interface{}{keyword: operands}, nil
+}
+
+func (p *filterParser) parseUnary() (map[string]interface{}, error) {
+	token := p.peek()
+	switch {
+	case p.isKeyword("not"):
+		p.next()
+		filter, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"not": filter}, nil
+	case token.kind == tokenOpen:
+		p.next()
+		filter, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenClose); err != nil {
+			return nil, err
+		}
+		return filter, nil
+	case token.kind != tokenWord:
+		return nil, p.unexpected(token)
+	}
+
+	p.next()
+	if operator, ok := functionOperators[strings.ToLower(token.text)]; ok && p.peek().kind == tokenOpen {
+		p.next()
+		field, err := p.expect(tokenWord)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenComma); err != nil {
+			return nil, err
+		}
+		literal, err := p.expect(tokenLiteral)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := literal.value.(string); !ok {
+			return nil, fmt.Errorf("%w: %s expects a string at position %d", ErrInvalidQuery, token.text, literal.position)
+		}
+		if _, err := p.expect(tokenClose); err != nil {
+			return nil, err
+		}
+		return p.comparison(field.text, operator, literal.value)
+	}
+
+	operatorToken, err := p.expect(tokenWord)
+	if err != nil {
+		return nil, err
+	}
+	operator, ok := comparisonOperators[strings.ToLower(operatorToken.text)]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown operator %q at position %d", ErrInvalidQuery, operatorToken.text, operatorToken.position)
+	}
+
+	if operator == "in" {
+		if _, err := p.expect(tokenOpen); err != nil {
+			return nil, err
+		}
+		var values []interface{}
+		for {
+			literal, err := p.expect(tokenLiteral)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, literal.value)
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+		if _, err := p.expect(tokenClose); err != nil {
+			return nil, err
+		}
+		return p.comparison(token.text, operator, values)
+	}
+
+	literal, err := p.expect(tokenLiteral)
+	if err != nil {
+		return nil, err
+	}
+	return p.comparison(token.text, operator, literal.value)
+}
+
+// comparison builds {"author": {"userName": {"eq": value}}} for author/userName eq value
+func (p *filterParser) comparison(path, operator string, value interface{}) (map[string]interface{}, error) {
+	if err := p.checkField(path); err != nil {
+		return nil, err
+	}
+
+	filter := map[string]interface{}{operator: value}
+	segments := splitPath(path)
+	for i := len(segments) - 1; i >= 0; i-- {
+		filter = map[string]interface{}{segments[i]: filter}
+	}
+	return filter, nil
+}
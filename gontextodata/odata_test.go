@@ -0,0 +1,207 @@
+package gontextodata_test
+
+import (
+	"errors"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shepherrrd/gontext"
+	"github.com/shepherrrd/gontext/gontextgraphql"
+	"github.com/shepherrrd/gontext/gontextodata"
+)
+
+var odataFields = []string{"name", "age", "isActive", "author/userName", "author.id"}
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   map[string]interface{}
+	}{
+		{"age gt 30", map[string]interface{}{"age": map[string]interface{}{"gt": int64(30)}}},
+		{"Age GE -1.5", map[string]interface{}{"Age": map[string]interface{}{"gte": -1.5}}},
+		{"name eq 'O''Brien'", map[string]interface{}{"name": map[string]interface{}{"eq": "O'Brien"}}},
+		{"isActive ne null", map[string]interface{}{"isActive": map[string]interface{}{"ne": nil}}},
+		{"age in (1, 2,3)", map[string]interface{}{"age": map[string]interface{}{"in": []interface{}{int64(1), int64(2), int64(3)}}}},
+		{"startswith(name, 'a')", map[string]interface{}{"name": map[string]interface{}{"startsWith": "a"}}},
+		{"author/userName eq 'ada'", map[string]interface{}{"author": map[string]interface{}{"userName": map[string]interface{}{"eq": "ada"}}}},
+		{"not isActive eq true", map[string]interface{}{"not": map[string]interface{}{"isActive": map[string]interface{}{"eq": true}}}},
+		{"age lt 1 or age gt 9 and isActive eq false", map[string]interface{}{"or": []interface{}{
+			map[string]interface{}{"age": map[string]interface{}{"lt": int64(1)}},
+			map[string]interface{}{"and": []interface{}{
+				map[string]interface{}{"age": map[string]interface{}{"gt": int64(9)}},
+				map[string]interface{}{"isActive": map[string]interface{}{"eq": false}},
+			}},
+		}}},
+		{"(age lt 1 or age gt 9) and contains(name, 'x')", map[string]interface{}{"and": []interface{}{
+			map[string]interface{}{"or": []interface{}{
+				map[string]interface{}{"age": map[string]interface{}{"lt": int64(1)}},
+				map[string]interface{}{"age": map[string]interface{}{"gt": int64(9)}},
+			}},
+			map[string]interface{}{"name": map[string]interface{}{"contains": "x"}},
+		}}},
+	}
+
+	for _, tt := range tests {
+		query, err := gontextodata.Parse(url.Values{"$filter": {tt.filter}}, gontextodata.Options{Fields: odataFields})
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", tt.filter, err)
+			continue
+		}
+		if !reflect.DeepEqual(query.Filter, tt.want) {
+			t.Errorf("Parse(%q) = %#v, want %#v", tt.filter, query.Filter, tt.want)
+		}
+	}
+}
+
+func TestParseOrderSelectAndPaging(t *testing.T) {
+	values := url.Values{
+		"orderby": {"age desc, name"},
+		"select":  {"name, author/userName, author.id"},
+		"top":     {"500"},
+		"$skip":   {"40"},
+	}
+	query, err := gontextodata.Parse(values, gontextodata.Options{Fields: odataFields, MaxTop: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantOrder := []gontextgraphql.Order{{Field: "age", Desc: true}, {Field: "name"}}
+	if !reflect.DeepEqual(query.OrderBy, wantOrder) {
+		t.Errorf("OrderBy = %+v, want %+v", query.OrderBy, wantOrder)
+	}
+	wantSelect := []gontextgraphql.Field{{Name: "name"}, {Name: "author", Selections: []gontextgraphql.Field{{Name: "userName"}, {Name: "id"}}}}
+	if !reflect.DeepEqual(query.Select, wantSelect) {
+		t.Errorf("Select = %+v, want %+v", query.Select, wantSelect)
+	}
+	if query.Top == nil || *query.Top != 100 || query.Skip != 40 {
+		t.Errorf("Top = %v, Skip = %d; want top capped at 100 and skip 40", query.Top, query.Skip)
+	}
+
+	tops := []struct {
+		options gontextodata.Options
+		top     string
+		want    *int
+	}{
+		{gontextodata.Options{}, "", nil},
+		{gontextodata.Options{DefaultTop: 20}, "", intPtr(20)},
+		{gontextodata.Options{DefaultTop: 20, MaxTop: 10}, "", intPtr(10)},
+		{gontextodata.Options{MaxTop: 50}, "", intPtr(50)},
+		{gontextodata.Options{DefaultTop: 20}, "0", intPtr(0)},
+	}
+	for _, tt := range tops {
+		query, err := gontextodata.Parse(url.Values{"top": {tt.top}}, tt.options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(query.Top, tt.want) {
+			t.Errorf("%+v with top %q: Top = %v, want %v", tt.options, tt.top, query.Top, tt.want)
+		}
+	}
+}
+
+func intPtr(n int) *int { return &n }
+
+func TestParseRejectsInvalidQueries(t *testing.T) {
+	tests := []struct {
+		values url.Values
+		err    string
+	}{
+		{url.Values{"filter": {"password eq 'x'"}}, `field "password" is not allowed`},
+		{url.Values{"filter": {"author/email eq 'x'"}}, `field "author/email" is not allowed`},
+		{url.Values{"filter": {"age gt"}}, "unexpected end of filter"},
+		{url.Values{"filter": {"age like 3"}}, `unknown operator "like" at position 4`},
+		{url.Values{"filter": {"age gt 3 age"}}, `unexpected "age" at position 9`},
+		{url.Values{"filter": {"(age gt 3"}}, "unexpected end of filter"},
+		{url.Values{"filter": {"name eq 'open"}}, "unterminated string at position 8"},
+		{url.Values{"filter": {"age gt 1.2.3"}}, `invalid number "1.2.3" at position 7`},
+		{url.Values{"filter": {"age gt 3; drop table users"}}, `unexpected ';' at position 8`},
+		{url.Values{"filter": {"contains(name, 3)"}}, "contains expects a string at position 15"},
+		{url.Values{"filter": {"age in ()"}}, `unexpected ")" at position 8`},
+		{url.Values{"orderby": {"password"}}, `field "password" is not allowed`},
+		{url.Values{"orderby": {"age sideways"}}, `invalid orderby direction "sideways"`},
+		{url.Values{"orderby": {"age,"}}, `invalid orderby ""`},
+		{url.Values{"orderby": {"author/userName"}}, `cannot order by navigation field "author/userName"`},
+		{url.Values{"select": {"name,password"}}, `field "password" is not allowed`},
+		{url.Values{"top": {"-1"}}, "top must be a non-negative integer"},
+		{url.Values{"skip": {"ten"}}, "skip must be a non-negative integer"},
+	}
+
+	for _, tt := range tests {
+		_, err := gontextodata.Parse(tt.values, gontextodata.Options{Fields: odataFields})
+		if !errors.Is(err, gontextodata.ErrInvalidQuery) || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Parse(%v) error = %v, want ErrInvalidQuery with %q", tt.values, err, tt.err)
+		}
+	}
+}
+
+type odataAuthor struct {
+	Id       int
+	UserName string
+}
+
+type odataPost struct {
+	Id       int
+	Title    string
+	Views    int
+	AuthorId int
+	Author   *odataAuthor
+}
+
+func TestApply(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "gontext.db") + "?_journal_mode=WAL&_busy_timeout=5000"
+	ctx, err := gontext.NewDbContext(dsn, "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ctx.Close() })
+	gontext.RegisterEntity[odataAuthor](ctx)
+	posts := gontext.RegisterEntity[odataPost](ctx)
+	if err := ctx.EnsureCreated(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctx.GetDB().Create([]odataAuthor{{Id: 1, UserName: "ada"}, {Id: 2, UserName: "bob"}}).Error; err != nil {
+		t.Fatal(err)
+	}
+	rows := []odataPost{
+		{Id: 1, Title: "Go", Views: 10, AuthorId: 1},
+		{Id: 2, Title: "Draft", Views: 2, AuthorId: 1},
+		{Id: 3, Title: "Generics", Views: 30, AuthorId: 2},
+		{Id: 4, Title: "Rust", Views: 20, AuthorId: 2},
+	}
+	if err := ctx.GetDB().Omit("Author").Create(rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	options := gontextodata.Options{Fields: []string{"title", "views", "author/userName"}, MaxTop: 2}
+
+	set, err := gontextodata.Apply(posts, url.Values{
+		"$filter":  {"views ge 10 and (author/userName eq 'bob' or startswith(title, 'G'))"},
+		"$orderby": {"views desc"},
+		"$skip":    {"1"},
+		"$select":  {"title"},
+	}, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, err := set.ToList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 2 || page[0].Title != "Rust" || page[1].Title != "Go" {
+		t.Fatalf("Apply returned %+v, want Rust and Go: the matches after the most viewed, capped at 2", page)
+	}
+	if page[0].Views != 0 || page[0].AuthorId != 0 {
+		t.Errorf("Apply loaded %+v, want only the selected title and the key", page[0])
+	}
+
+	_, err = gontextodata.Apply(posts, url.Values{"filter": {"authorId eq 1"}}, options)
+	if !errors.Is(err, gontextodata.ErrInvalidQuery) {
+		t.Errorf("Apply with a field off the allowlist error = %v, want ErrInvalidQuery", err)
+	}
+	_, err = gontextodata.Apply(posts, url.Values{"filter": {"rating gt 3"}}, gontextodata.Options{Fields: []string{"rating"}})
+	if !errors.Is(err, gontextodata.ErrInvalidQuery) {
+		t.Errorf("Apply with an allowed field missing from the entity error = %v, want ErrInvalidQuery", err)
+	}
+}
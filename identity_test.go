@@ -0,0 +1,124 @@
+package gontext_test
+
+import (
+	"testing"
+
+	"github.com/shepherrrd/gontext"
+)
+
+type identityAuthor struct {
+	Id    int
+	Name  string
+	Posts []*identityPost `gorm:"foreignKey:AuthorId"`
+}
+
+type identityPost struct {
+	Id       int
+	Title    string
+	AuthorId int
+	Author   *identityAuthor
+}
+
+// newIdentityContext returns an in-memory context with 2 authors of 3 posts each
+func newIdentityContext(t *testing.T) (*gontext.DbContext, *gontext.LinqDbSet[identityAuthor], *gontext.LinqDbSet[identityPost]) {
+	t.Helper()
+	ctx, err := gontext.NewDbContext(":memory:", "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ctx.Close() })
+	authors := gontext.RegisterEntity[identityAuthor](ctx)
+	posts := gontext.RegisterEntity[identityPost](ctx)
+	if err := ctx.EnsureCreated(); err != nil {
+		t.Fatal(err)
+	}
+
+	db := ctx.GetDB()
+	for a := 1; a <= 2; a++ {
+		if err := db.Create(&identityAuthor{Id: a, Name: "author"}).Error; err != nil {
+			t.Fatal(err)
+		}
+		for p := 1; p <= 3; p++ {
+			if err := db.Create(&identityPost{Id: a*10 + p, Title: "post", AuthorId: a}).Error; err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return ctx, authors, posts
+}
+
+func TestIdentityResolutionSharesInstances(t *testing.T) {
+	_, _, posts := newIdentityContext(t)
+
+	loaded, err := posts.Include("Author").WithIdentityResolution().ToList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 6 {
+		t.Fatalf("loaded %d posts, want 6", len(loaded))
+	}
+	for i := range loaded {
+		for j := range loaded {
+			same := loaded[i].Author == loaded[j].Author
+			if want := loaded[i].AuthorId == loaded[j].AuthorId; same != want {
+				t.Fatalf("posts %d and %d share author: %v, want %v", loaded[i].Id, loaded[j].Id, same, want)
+			}
+		}
+	}
+}
+
+func TestIdentityResolutionCyclesAreTracked(t *testing.T) {
+	ctx, authors, _ := newIdentityContext(t)
+
+	loaded, err := authors.Include("Posts.Author").WithIdentityResolution().ToList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 2 || len(loaded[0].Posts) != 3 {
+		t.Fatalf("loaded %d authors, want 2 with 3 posts", len(loaded))
+	}
+	for i := range loaded {
+		for _, post := range loaded[i].Posts {
+			if post.Author != &loaded[i] {
+				t.Fatalf("post %d does not point back at its returned author", post.Id)
+			}
+		}
+	}
+
+	// Snapshots and change detection must follow the cycle without recursing forever
+	if ctx.HasChanges() {
+		t.Fatal("freshly loaded graph reports changes")
+	}
+	loaded[0].Name = "renamed"
+	if !ctx.HasChanges() {
+		t.Fatal("change to a cyclic graph was not detected")
+	}
+	if err := ctx.SaveChanges(); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := authors.Where("Id", loaded[0].Id).FirstOrDefault()
+	if err != nil || reloaded == nil || reloaded.Name != "renamed" {
+		t.Fatalf("reloaded %+v, %v; want the renamed author", reloaded, err)
+	}
+}
+
+func TestIdentityResolutionByBatchLoader(t *testing.T) {
+	_, authors, _ := newIdentityContext(t)
+
+	loader := authors.Include("Posts.Author").WithIdentityResolution().BatchLoader(0)
+	loaded, err := loader.LoadMany(1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, author := range loaded {
+		if author == nil || len(author.Posts) != 3 {
+			t.Fatalf("loaded %+v, want an author with 3 posts", author)
+		}
+		for _, post := range author.Posts {
+			if post.Author != author {
+				t.Fatalf("post %d does not point back at its batch-loaded author", post.Id)
+			}
+		}
+	}
+}
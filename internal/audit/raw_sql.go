@@ -0,0 +1,179 @@
+package audit
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/shepherrrd/gontext/internal/query"
+)
+
+// rawSQLMethods take a SQL fragment as their first argument
+var rawSQLMethods = map[string]bool{
+	"Where": true, "Or": true, "Not": true, "Having": true, "Joins": true,
+	"Raw": true, "Exec": true, "All": true, "None": true, "WhereComplex": true,
+}
+
+// Finding is a raw SQL call site
+type Finding struct {
+	Position token.Position
+	Method   string
+	SQL      string   // The literal SQL, or the source of the expression building it
+	Dynamic  bool     // SQL is built at runtime (fmt.Sprintf, concatenation, variables)
+	Issues   []string // Problems AuditSQL found in literal SQL
+}
+
+// String formats the finding like a compiler diagnostic
+func (f Finding) String() string {
+	status := "ok"
+	switch {
+	case len(f.Issues) > 0:
+		status = strings.Join(f.Issues, "; ")
+	case f.Dynamic:
+		status = "dynamic SQL - review how it is built"
+	}
+	return fmt.Sprintf("%s: %s(%s): %s", f.Position, f.Method, f.SQL, status)
+}
+
+// Unsafe reports whether the call site needs attention in a security review
+func (f Finding) Unsafe() bool {
+	return f.Dynamic || len(f.Issues) > 0
+}
+
+// LintRawSQL reports every raw SQL call site (Where, Or, Having, Joins, Raw, Exec, ...) in the Go files
+// under dir, skipping vendor, testdata, hidden directories and tests
+// Field-name conditions such as Where("Email", value) are not raw SQL and are left out
+func LintRawSQL(dir string) ([]Finding, error) {
+	var findings []Finding
+	fset := token.NewFileSet()
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if path != dir && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		findings = append(findings, lintFile(fset, file)...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i].Position, findings[j].Position
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Line < b.Line
+	})
+	return findings, nil
+}
+
+func lintFile(fset *token.FileSet, file *ast.File) []Finding {
+	// Package functions such as gontext.Or(predicates...) build predicates, not SQL
+	imports := make(map[string]bool)
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = true
+	}
+
+	var findings []Finding
+	ast.Inspect(file, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+		selector, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || !rawSQLMethods[selector.Sel.Name] || call.Ellipsis.IsValid() {
+			return true
+		}
+		if receiver, ok := selector.X.(*ast.Ident); ok && receiver.Obj == nil && imports[receiver.Name] {
+			return true
+		}
+
+		method := selector.Sel.Name
+		finding := Finding{Position: fset.Position(call.Pos()), Method: method}
+		switch arg := call.Args[0].(type) {
+		case *ast.CompositeLit, *ast.UnaryExpr, *ast.FuncLit:
+			// Struct and map conditions are parameterized by GORM
+			return true
+		default:
+			if sql, ok := constantString(arg); ok {
+				if isFieldName(sql) && method != "Raw" && method != "Exec" {
+					return true
+				}
+				finding.SQL = strconv.Quote(sql)
+				finding.Issues = query.AuditSQL(sql)
+			} else {
+				finding.SQL = types.ExprString(arg)
+				finding.Dynamic = true
+			}
+		}
+		findings = append(findings, finding)
+		return true
+	})
+	return findings
+}
+
+// constantString evaluates string literals and + concatenations of them
+func constantString(expr ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.STRING {
+			return "", false
+		}
+		value, err := strconv.Unquote(e.Value)
+		return value, err == nil
+	case *ast.ParenExpr:
+		return constantString(e.X)
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return "", false
+		}
+		left, ok := constantString(e.X)
+		if !ok {
+			return "", false
+		}
+		right, ok := constantString(e.Y)
+		return left + right, ok
+	}
+	return "", false
+}
+
+// isFieldName matches the Where("Email", value) form that names a field instead of holding SQL
+func isFieldName(sql string) bool {
+	if sql == "" {
+		return false
+	}
+	for _, r := range sql {
+		if r != '_' && r != '.' && !('a' <= r && r <= 'z') && !('A' <= r && r <= 'Z') && !('0' <= r && r <= '9') {
+			return false
+		}
+	}
+	return !strings.EqualFold(sql, "TRUE") && !strings.EqualFold(sql, "FALSE")
+}
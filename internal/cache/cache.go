@@ -0,0 +1,159 @@
+package cache
+
+import (
+	stdcontext "context"
+	"errors"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
+)
+
+// DefaultJitter spreads expiries over ±10% of the TTL when no jitter is configured
+const DefaultJitter = 0.1
+
+// Stats reports query cache usage
+type Stats struct {
+	Hits   int64 // Results served from the cache
+	Misses int64 // Results loaded from the database
+	Shared int64 // Concurrent misses that waited for another caller's load instead of querying
+	Errors int64 // Provider errors; failed reads are treated as misses so queries still run
+}
+
+// HitRatio is Hits / (Hits + Misses + Shared), or 0 before the first lookup
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses + s.Shared
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// Cache puts stampede protection and TTL jitter in front of a Provider: concurrent misses of the same key
+// share one load, and expiries are spread so entries cached together do not all reload at once
+type Cache struct {
+	provider Provider
+	jitter   float64
+	group    singleflight.Group
+
+	hits   atomic.Int64
+	misses atomic.Int64
+	shared atomic.Int64
+	errors atomic.Int64
+}
+
+// New wraps provider; jitter is the fraction of the TTL expiries vary by (0 uses DefaultJitter, negative none)
+func New(provider Provider, jitter float64) *Cache {
+	if jitter == 0 {
+		jitter = DefaultJitter
+	}
+	return &Cache{provider: provider, jitter: max(jitter, 0)}
+}
+
+// Provider returns the provider behind the cache
+func (c *Cache) Provider() Provider {
+	return c.provider
+}
+
+// Load returns the value cached under key, or runs load, caches its result for ttl and returns it
+// Provider errors are counted and treated as misses, so an unavailable cache only costs the query
+func (c *Cache) Load(ctx stdcontext.Context, key string, tags []string, ttl time.Duration, load func() ([]byte, error)) ([]byte, error) {
+	if value, found := c.get(ctx, key, tags); found {
+		c.hits.Add(1)
+		return value, nil
+	}
+
+	loaded := false
+	value, err, _ := c.group.Do(key, func() (interface{}, error) {
+		loaded = true
+		// Another caller may have stored it between our miss and the start of this load
+		if value, found := c.get(ctx, key, tags); found {
+			return value, nil
+		}
+		value, err := load()
+		if err != nil {
+			return nil, err
+		}
+		if err := c.provider.Set(ctx, key, tags, value, c.jittered(ttl)); err != nil {
+			c.errors.Add(1)
+		}
+		return value, nil
+	})
+	if loaded {
+		c.misses.Add(1)
+	} else {
+		c.shared.Add(1)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return value.([]byte), nil
+}
+
+// Invalidate drops the entries tagged with any of tags
+func (c *Cache) Invalidate(ctx stdcontext.Context, tags ...string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	err := c.provider.Invalidate(ctx, tags...)
+	if err != nil {
+		c.errors.Add(1)
+	}
+	return err
+}
+
+// Stats returns the current counters
+func (c *Cache) Stats() Stats {
+	return Stats{Hits: c.hits.Load(), Misses: c.misses.Load(), Shared: c.shared.Load(), Errors: c.errors.Load()}
+}
+
+func (c *Cache) get(ctx stdcontext.Context, key string, tags []string) ([]byte, bool) {
+	value, found, err := c.provider.Get(ctx, key, tags)
+	if err != nil {
+		c.errors.Add(1)
+		return nil, false
+	}
+	return value, found
+}
+
+func (c *Cache) jittered(ttl time.Duration) time.Duration {
+	if c.jitter == 0 {
+		return ttl
+	}
+	spread := float64(ttl) * c.jitter
+	return ttl + time.Duration((rand.Float64()*2-1)*spread)
+}
+
+// InvalidationPlugin invalidates a table's cached results whenever GORM writes to it
+type InvalidationPlugin struct {
+	cache *Cache
+}
+
+// NewInvalidationPlugin creates the plugin for cache
+func NewInvalidationPlugin(cache *Cache) *InvalidationPlugin {
+	return &InvalidationPlugin{cache: cache}
+}
+
+// Name returns the plugin name
+func (p *InvalidationPlugin) Name() string {
+	return "gontext:query_cache"
+}
+
+// Initialize invalidates after creates, updates and deletes
+func (p *InvalidationPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	return errors.Join(
+		callbacks.Create().After("gorm:create").Register("gontext:invalidate_create", p.invalidate),
+		callbacks.Update().After("gorm:update").Register("gontext:invalidate_update", p.invalidate),
+		callbacks.Delete().After("gorm:delete").Register("gontext:invalidate_delete", p.invalidate),
+	)
+}
+
+func (p *InvalidationPlugin) invalidate(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Table == "" || db.RowsAffected == 0 {
+		return
+	}
+	p.cache.Invalidate(db.Statement.Context, db.Statement.Table) // Failures are counted in Stats.Errors
+}
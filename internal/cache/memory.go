@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"container/list"
+	stdcontext "context"
+	"sync"
+	"time"
+)
+
+// DefaultMemoryCacheSize is the number of entries a Memory provider keeps when no size is configured
+const DefaultMemoryCacheSize = 1024
+
+// Memory is an in-process Provider evicting the least recently used entry beyond its capacity
+type Memory struct {
+	capacity int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List                     // Front is the most recently used *memoryEntry
+	tagged  map[string]map[string]struct{} // Tag -> keys
+}
+
+type memoryEntry struct {
+	key     string
+	tags    []string
+	value   []byte
+	expires time.Time
+}
+
+// NewMemory creates an in-process provider; capacity <= 0 uses DefaultMemoryCacheSize
+func NewMemory(capacity int) *Memory {
+	if capacity <= 0 {
+		capacity = DefaultMemoryCacheSize
+	}
+	return &Memory{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+		tagged:   make(map[string]map[string]struct{}),
+	}
+}
+
+// Get returns an unexpired entry
+func (m *Memory) Get(_ stdcontext.Context, key string, _ []string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, exists := m.entries[key]
+	if !exists {
+		return nil, false, nil
+	}
+	entry := element.Value.(*memoryEntry)
+	if time.Now().After(entry.expires) {
+		m.remove(element)
+		return nil, false, nil
+	}
+	m.order.MoveToFront(element)
+	return entry.value, true, nil
+}
+
+// Set stores an entry, evicting the least recently used one when full
+func (m *Memory) Set(_ stdcontext.Context, key string, tags []string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, exists := m.entries[key]; exists {
+		m.remove(element)
+	}
+	entry := &memoryEntry{key: key, tags: tags, value: value, expires: time.Now().Add(ttl)}
+	m.entries[key] = m.order.PushFront(entry)
+	for _, tag := range tags {
+		if m.tagged[tag] == nil {
+			m.tagged[tag] = make(map[string]struct{})
+		}
+		m.tagged[tag][key] = struct{}{}
+	}
+
+	for m.order.Len() > m.capacity {
+		m.remove(m.order.Back())
+	}
+	return nil
+}
+
+// Invalidate drops the entries tagged with any of tags
+func (m *Memory) Invalidate(_ stdcontext.Context, tags ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, tag := range tags {
+		for key := range m.tagged[tag] {
+			if element, exists := m.entries[key]; exists {
+				m.remove(element)
+			}
+		}
+		delete(m.tagged, tag)
+	}
+	return nil
+}
+
+// Clear drops every entry
+func (m *Memory) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries = make(map[string]*list.Element)
+	m.order.Init()
+	m.tagged = make(map[string]map[string]struct{})
+}
+
+// Len returns the number of entries, including expired ones not yet dropped
+func (m *Memory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.order.Len()
+}
+
+func (m *Memory) remove(element *list.Element) {
+	entry := m.order.Remove(element).(*memoryEntry)
+	delete(m.entries, entry.key)
+	for _, tag := range entry.tags {
+		if keys := m.tagged[tag]; keys != nil {
+			delete(keys, entry.key)
+			if len(keys) == 0 {
+				delete(m.tagged, tag)
+			}
+		}
+	}
+}
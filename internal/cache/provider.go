@@ -0,0 +1,17 @@
+package cache
+
+import (
+	stdcontext "context"
+	"time"
+)
+
+// Provider stores cached query results. Entries carry tags - the tables the query read - and
+// Invalidate drops every entry carrying one of the given tags
+type Provider interface {
+	// Get returns the value stored under key; found is false for a miss
+	Get(ctx stdcontext.Context, key string, tags []string) (value []byte, found bool, err error)
+	// Set stores value under key for ttl
+	Set(ctx stdcontext.Context, key string, tags []string, value []byte, ttl time.Duration) error
+	// Invalidate drops the entries tagged with any of tags
+	Invalidate(ctx stdcontext.Context, tags ...string) error
+}
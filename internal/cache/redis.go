@@ -0,0 +1,255 @@
+package cache
+
+import (
+	stdcontext "context"
+	"crypto/tls"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RedisOptions configures a Redis provider
+type RedisOptions struct {
+	Addr     string // host:port (default localhost:6379)
+	Username string // ACL user, with Password
+	Password string
+	DB       int
+	TLS      *tls.Config
+	// Prefix namespaces the keys and channels of the cache (default "gontext:cache:")
+	Prefix string
+	// PoolSize caps the idle connections kept for reuse (default 10)
+	PoolSize    int
+	DialTimeout time.Duration // Default 5s
+	ReadTimeout time.Duration // Per command (default 3s)
+	// LocalTTL keeps hot entries in process memory for this long in front of Redis; invalidations are
+	// published on a channel per tag so every instance drops its local copies. 0 disables the local cache
+	LocalTTL  time.Duration
+	LocalSize int // Local cache capacity (default DefaultMemoryCacheSize)
+}
+
+// Redis is a Provider shared by every application instance. Each tag has a version key that is part
+// of the keys of the entries it tags, so invalidating a tag is one INCR: entries under the old version
+// are never read again and expire with their TTL
+type Redis struct {
+	options RedisOptions
+	idle    chan *respConn
+	local   *Memory // Nil without LocalTTL
+
+	closeOnce  sync.Once
+	closed     chan struct{}
+	subscriber *respConn
+	subMu      sync.Mutex
+}
+
+// getScript returns the entry of ARGV[1] under the current versions of the tag keys
+const getScript = `local key = ARGV[1]
+for i = 1, #KEYS do key = key .. ':' .. (redis.call('GET', KEYS[i]) or '0') end
+return redis.call('GET', key)`
+
+// setScript stores ARGV[2] for ARGV[3] milliseconds under the current versions of the tag keys
+const setScript = `local key = ARGV[1]
+for i = 1, #KEYS do key = key .. ':' .. (redis.call('GET', KEYS[i]) or '0') end
+return redis.call('SET', key, ARGV[2], 'PX', ARGV[3])`
+
+// NewRedis creates a Redis provider; connections open on first use
+func NewRedis(options RedisOptions) *Redis {
+	if options.Addr == "" {
+		options.Addr = "localhost:6379"
+	}
+	if options.Prefix == "" {
+		options.Prefix = "gontext:cache:"
+	}
+	if options.PoolSize <= 0 {
+		options.PoolSize = 10
+	}
+	if options.DialTimeout <= 0 {
+		options.DialTimeout = 5 * time.Second
+	}
+	if options.ReadTimeout <= 0 {
+		options.ReadTimeout = 3 * time.Second
+	}
+
+	r := &Redis{options: options, idle: make(chan *respConn, options.PoolSize), closed: make(chan struct{})}
+	if options.LocalTTL > 0 {
+		r.local = NewMemory(options.LocalSize)
+		go r.subscribe()
+	}
+	return r
+}
+
+// Get returns the entry stored under key, from the local cache when it holds it
+func (r *Redis) Get(ctx stdcontext.Context, key string, tags []string) ([]byte, bool, error) {
+	if r.local != nil {
+		if value, found, _ := r.local.Get(ctx, key, tags); found {
+			return value, true, nil
+		}
+	}
+
+	reply, err := r.eval(ctx, getScript, key, tags)
+	if err != nil || reply == nil {
+		return nil, false, err
+	}
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, false, errors.New("redis: unexpected reply to GET")
+	}
+	if r.local != nil {
+		r.local.Set(ctx, key, tags, value, r.options.LocalTTL)
+	}
+	return value, true, nil
+}
+
+// Set stores an entry for ttl
+func (r *Redis) Set(ctx stdcontext.Context, key string, tags []string, value []byte, ttl time.Duration) error {
+	milliseconds := ttl.Milliseconds()
+	if milliseconds <= 0 {
+		return nil
+	}
+	if _, err := r.eval(ctx, setScript, key, tags, value, milliseconds); err != nil {
+		return err
+	}
+	if r.local != nil {
+		r.local.Set(ctx, key, tags, value, min(ttl, r.options.LocalTTL))
+	}
+	return nil
+}
+
+// Invalidate bumps the version of each tag and publishes it on the tag's invalidation channel
+func (r *Redis) Invalidate(ctx stdcontext.Context, tags ...string) error {
+	if r.local != nil {
+		r.local.Invalidate(ctx, tags...)
+	}
+	for _, tag := range tags {
+		if _, err := r.do(ctx, "INCR", r.versionKey(tag)); err != nil {
+			return err
+		}
+		if _, err := r.do(ctx, "PUBLISH", r.options.Prefix+"invalidate:"+tag, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Channel returns the channel invalidations of tag are published on, for other consumers to subscribe to
+func (r *Redis) Channel(tag string) string {
+	return r.options.Prefix + "invalidate:" + tag
+}
+
+// Close closes the pooled connections and stops listening for invalidations
+func (r *Redis) Close() error {
+	r.closeOnce.Do(func() {
+		close(r.closed)
+		r.subMu.Lock()
+		if r.subscriber != nil {
+			r.subscriber.close()
+		}
+		r.subMu.Unlock()
+		for {
+			select {
+			case conn := <-r.idle:
+				conn.close()
+			default:
+				return
+			}
+		}
+	})
+	return nil
+}
+
+func (r *Redis) versionKey(tag string) string {
+	return r.options.Prefix + "version:" + tag
+}
+
+func (r *Redis) eval(ctx stdcontext.Context, script, key string, tags []string, args ...interface{}) (interface{}, error) {
+	command := []interface{}{"EVAL", script, len(tags)}
+	for _, tag := range tags {
+		command = append(command, r.versionKey(tag))
+	}
+	command = append(command, r.options.Prefix+"entry:"+key)
+	command = append(command, args...)
+	return r.do(ctx, command...)
+}
+
+// do runs a command on a pooled connection
+func (r *Redis) do(ctx stdcontext.Context, args ...interface{}) (interface{}, error) {
+	var conn *respConn
+	select {
+	case conn = <-r.idle:
+	default:
+		var err error
+		if conn, err = dialRedis(ctx, r.options); err != nil {
+			return nil, err
+		}
+	}
+
+	reply, err := conn.do(ctx, r.options.ReadTimeout, args...)
+	var replyErr redisError
+	if err != nil && !errors.As(err, &replyErr) {
+		conn.close() // The connection may hold half a reply
+		return nil, err
+	}
+
+	select {
+	case r.idle <- conn:
+	default:
+		conn.close()
+	}
+	return reply, err
+}
+
+// subscribe drops local entries when any instance publishes an invalidation, reconnecting until closed
+// Invalidations sent while disconnected are missed, so the local cache is cleared on every (re)connect
+func (r *Redis) subscribe() {
+	for {
+		err := r.listen()
+		select {
+		case <-r.closed:
+			return
+		case <-time.After(time.Second):
+		}
+		log.Printf("gontext: redis cache invalidation listener reconnecting: %v", err)
+	}
+}
+
+func (r *Redis) listen() error {
+	conn, err := dialRedis(stdcontext.Background(), r.options)
+	if err != nil {
+		return err
+	}
+	r.subMu.Lock()
+	select {
+	case <-r.closed:
+		r.subMu.Unlock()
+		conn.close()
+		return nil
+	default:
+	}
+	r.subscriber = conn
+	r.subMu.Unlock()
+	defer conn.close()
+
+	if _, err := conn.do(stdcontext.Background(), r.options.ReadTimeout, "PSUBSCRIBE", r.options.Prefix+"invalidate:*"); err != nil {
+		return err
+	}
+	r.local.Clear()
+
+	conn.conn.SetDeadline(time.Time{})
+	for {
+		reply, err := conn.read()
+		if err != nil {
+			return err
+		}
+		// ["pmessage", pattern, channel, tag]
+		message, ok := reply.([]interface{})
+		if !ok || len(message) != 4 {
+			continue
+		}
+		if kind, _ := message[0].([]byte); strings.EqualFold(string(kind), "pmessage") {
+			if tag, ok := message[3].([]byte); ok {
+				r.local.Invalidate(stdcontext.Background(), string(tag))
+			}
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package cache
+
+import (
+	"bufio"
+	stdcontext "context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis is a RESP server holding plain string keys; it evaluates the provider's two scripts by
+// their shape and fails any command on a key in failKeys with an error reply
+type fakeRedis struct {
+	listener net.Listener
+
+	mu          sync.Mutex
+	values      map[string][]byte
+	failKeys    map[string]bool
+	dials       int
+	commands    [][]string
+	subscribers []net.Conn
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &fakeRedis{listener: listener, values: make(map[string][]byte), failKeys: make(map[string]bool)}
+	t.Cleanup(func() { listener.Close() })
+	go server.serve()
+	return server
+}
+
+func (s *fakeRedis) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.dials++
+		s.mu.Unlock()
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeRedis) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		command, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.commands = append(s.commands, command)
+		reply := s.reply(conn, command)
+		s.mu.Unlock()
+		if _, err := conn.Write([]byte(reply)); err != nil {
+			return
+		}
+	}
+}
+
+// reply runs command with s.mu held and returns its encoded reply
+func (s *fakeRedis) reply(conn net.Conn, command []string) string {
+	for _, arg := range command[1:] {
+		if s.failKeys[arg] {
+			return "-ERR injected failure\r\n"
+		}
+	}
+	switch strings.ToUpper(command[0]) {
+	case "AUTH", "SELECT":
+		return "+OK\r\n"
+	case "INCR":
+		version, _ := strconv.Atoi(string(s.values[command[1]]))
+		s.values[command[1]] = []byte(strconv.Itoa(version + 1))
+		return fmt.Sprintf(":%d\r\n", version+1)
+	case "PUBLISH":
+		message := bulkArray("pmessage", command[1][:strings.LastIndex(command[1], ":")+1]+"*", command[1], command[2])
+		for _, subscriber := range s.subscribers {
+			subscriber.Write([]byte(message))
+		}
+		return fmt.Sprintf(":%d\r\n", len(s.subscribers))
+	case "PSUBSCRIBE":
+		s.subscribers = append(s.subscribers, conn)
+		return "*3\r\n" + bulk("psubscribe") + bulk(command[1]) + ":1\r\n"
+	case "EVAL":
+		tags, _ := strconv.Atoi(command[2])
+		key := command[3+tags]
+		for _, versionKey := range command[3 : 3+tags] {
+			version := "0"
+			if value, ok := s.values[versionKey]; ok {
+				version = string(value)
+			}
+			key += ":" + version
+		}
+		if strings.Contains(command[1], "'SET'") {
+			s.values[key] = []byte(command[4+tags])
+			return "+OK\r\n"
+		}
+		if value, ok := s.values[key]; ok {
+			return bulk(string(value))
+		}
+		return "$-1\r\n"
+	}
+	return "-ERR unknown command '" + command[0] + "'\r\n"
+}
+
+func (s *fakeRedis) addr() string { return s.listener.Addr().String() }
+
+func (s *fakeRedis) failOn(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failKeys[key] = true
+}
+
+func (s *fakeRedis) dialCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dials
+}
+
+func (s *fakeRedis) subscriberCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subscribers)
+}
+
+// commandNames returns the names of the commands received so far
+func (s *fakeRedis) commandNames() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, len(s.commands))
+	for i, command := range s.commands {
+		names[i] = command[0]
+	}
+	return names
+}
+
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+	command := make([]string, count)
+	for i := range command {
+		if line, err = reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, err
+		}
+		arg := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, arg); err != nil {
+			return nil, err
+		}
+		command[i] = string(arg[:size])
+	}
+	return command, nil
+}
+
+func bulk(value string) string { return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value) }
+
+func bulkArray(values ...string) string {
+	reply := fmt.Sprintf("*%d\r\n", len(values))
+	for _, value := range values {
+		reply += bulk(value)
+	}
+	return reply
+}
+
+func TestRedisSetGetInvalidate(t *testing.T) {
+	server := newFakeRedis(t)
+	r := NewRedis(RedisOptions{Addr: server.addr(), Password: "secret", DB: 2})
+	defer r.Close()
+	ctx := stdcontext.Background()
+
+	if _, found, err := r.Get(ctx, "users:1", []string{"users"}); err != nil || found {
+		t.Fatalf("Get() of a missing entry = %v, %v; want a miss", found, err)
+	}
+	if err := r.Set(ctx, "users:1", []string{"users"}, []byte("ada"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if value, found, err := r.Get(ctx, "users:1", []string{"users"}); err != nil || !found || string(value) != "ada" {
+		t.Fatalf("Get() = %q, %v, %v; want the stored entry", value, found, err)
+	}
+	if err := r.Invalidate(ctx, "users"); err != nil {
+		t.Fatal(err)
+	}
+	if _, found, err := r.Get(ctx, "users:1", []string{"users"}); err != nil || found {
+		t.Errorf("Get() after Invalidate = %v, %v; want a miss", found, err)
+	}
+
+	want := "AUTH SELECT EVAL EVAL EVAL INCR PUBLISH EVAL"
+	if got := strings.Join(server.commandNames(), " "); got != want {
+		t.Errorf("server received %s, want %s", got, want)
+	}
+	if dials := server.dialCount(); dials != 1 {
+		t.Errorf("dialed %d connections for sequential commands, want 1", dials)
+	}
+}
+
+func TestRedisReusesConnectionAfterErrorReply(t *testing.T) {
+	server := newFakeRedis(t)
+	r := NewRedis(RedisOptions{Addr: server.addr()})
+	defer r.Close()
+	ctx := stdcontext.Background()
+	server.failOn(r.versionKey("broken"))
+
+	err := r.Invalidate(ctx, "broken")
+	var replyErr redisError
+	if !errors.As(err, &replyErr) || string(replyErr) != "ERR injected failure" {
+		t.Fatalf("Invalidate() error = %#v, want the error reply", err)
+	}
+	if err := r.Invalidate(ctx, "users"); err != nil {
+		t.Fatal(err)
+	}
+	if dials := server.dialCount(); dials != 1 {
+		t.Errorf("dialed %d connections, want the one that got the error reply reused", dials)
+	}
+}
+
+func TestRedisDropsConnectionOnBrokenReply(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	var dials int
+	var mu sync.Mutex
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			dials++
+			mu.Unlock()
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					if _, err := readCommand(reader); err != nil {
+						return
+					}
+					conn.Write([]byte("$10\r\nhalf")) // A bulk reply cut short
+					conn.SetReadDeadline(time.Now().Add(time.Second))
+				}
+			}()
+		}
+	}()
+
+	r := NewRedis(RedisOptions{Addr: listener.Addr().String(), ReadTimeout: 100 * time.Millisecond})
+	defer r.Close()
+	for i := 0; i < 2; i++ {
+		if _, _, err := r.Get(stdcontext.Background(), "users:1", nil); err == nil {
+			t.Fatal("Get() over a broken reply succeeded")
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if dials != 2 {
+		t.Errorf("dialed %d connections, want a fresh one after the broken reply", dials)
+	}
+}
+
+func TestRedisInvalidationClearsLocalCache(t *testing.T) {
+	server := newFakeRedis(t)
+	reader := NewRedis(RedisOptions{Addr: server.addr(), LocalTTL: time.Minute})
+	defer reader.Close()
+	writer := NewRedis(RedisOptions{Addr: server.addr()})
+	defer writer.Close()
+	ctx := stdcontext.Background()
+
+	waitFor(t, "the invalidation subscription", func() bool { return server.subscriberCount() == 1 })
+	if err := reader.Set(ctx, "users:1", []string{"users"}, []byte("ada"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if _, found, _ := reader.local.Get(ctx, "users:1", []string{"users"}); !found {
+		t.Fatal("Set() did not keep the entry in the local cache")
+	}
+
+	// Another instance invalidates the tag; its Redis entry is gone with the version bump, and the
+	// published message must drop the local copy
+	if err := writer.Invalidate(ctx, "users"); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "the local entry to be invalidated", func() bool {
+		_, found, _ := reader.local.Get(ctx, "users:1", []string{"users"})
+		return !found
+	})
+	if _, found, err := reader.Get(ctx, "users:1", []string{"users"}); err != nil || found {
+		t.Errorf("Get() after another instance invalidated = %v, %v; want a miss", found, err)
+	}
+}
+
+func waitFor(t *testing.T, what string, done func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"bufio"
+	stdcontext "context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// respConn is a connection speaking the Redis serialization protocol (RESP2)
+type respConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	writer *bufio.Writer
+}
+
+// redisError is an error reply from the server
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+func dialRedis(ctx stdcontext.Context, options RedisOptions) (*respConn, error) {
+	dialer := &net.Dialer{Timeout: options.DialTimeout}
+	var conn net.Conn
+	var err error
+	if options.TLS != nil {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: options.TLS}).DialContext(ctx, "tcp", options.Addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", options.Addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to redis at %s: %w", options.Addr, err)
+	}
+
+	c := &respConn{conn: conn, reader: bufio.NewReader(conn), writer: bufio.NewWriter(conn)}
+	setup := [][]interface{}{}
+	if options.Password != "" {
+		if options.Username != "" {
+			setup = append(setup, []interface{}{"AUTH", options.Username, options.Password})
+		} else {
+			setup = append(setup, []interface{}{"AUTH", options.Password})
+		}
+	}
+	if options.DB != 0 {
+		setup = append(setup, []interface{}{"SELECT", options.DB})
+	}
+	for _, command := range setup {
+		if _, err := c.do(ctx, options.ReadTimeout, command...); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// do sends one command and reads its reply
+func (c *respConn) do(ctx stdcontext.Context, timeout time.Duration, args ...interface{}) (interface{}, error) {
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	if err := c.write(args...); err != nil {
+		return nil, err
+	}
+	return c.read()
+}
+
+// write sends a command as an array of bulk strings
+func (c *respConn) write(args ...interface{}) error {
+	fmt.Fprintf(c.writer, "*%d\r\n", len(args))
+	for _, arg := range args {
+		var value []byte
+		switch v := arg.(type) {
+		case string:
+			value = []byte(v)
+		case []byte:
+			value = v
+		case int:
+			value = strconv.AppendInt(nil, int64(v), 10)
+		case int64:
+			value = strconv.AppendInt(nil, v, 10)
+		default:
+			value = []byte(fmt.Sprint(v))
+		}
+		fmt.Fprintf(c.writer, "$%d\r\n", len(value))
+		c.writer.Write(value)
+		c.writer.WriteString("\r\n")
+	}
+	return c.writer.Flush()
+}
+
+// read reads one reply: string (status), int64, []byte or nil (bulk), []interface{} (array) or a redisError
+func (c *respConn) read() (interface{}, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, errors.New("redis: malformed reply")
+	}
+	kind, payload := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return payload, nil
+	case '-':
+		return nil, redisError(payload)
+	case ':':
+		return strconv.ParseInt(payload, 10, 64)
+	case '$':
+		size, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(c.reader, data); err != nil {
+			return nil, err
+		}
+		return data[:size], nil
+	case '*':
+		count, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, err
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			if items[i], err = c.read(); err != nil {
+				var replyErr redisError
+				if !errors.As(err, &replyErr) {
+					return nil, err
+				}
+				items[i] = replyErr
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply type %q", kind)
+	}
+}
+
+func (c *respConn) close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// pipeConn returns a connection whose replies are read from server
+func pipeConn(t *testing.T, server string) *respConn {
+	t.Helper()
+	client, peer := net.Pipe()
+	t.Cleanup(func() { client.Close(); peer.Close() })
+	go func() {
+		peer.Write([]byte(server))
+		peer.Close()
+	}()
+	return &respConn{conn: client, reader: bufio.NewReader(client), writer: bufio.NewWriter(client)}
+}
+
+func TestRespRead(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply string
+		want  interface{}
+		err   string
+	}{
+		{"status", "+OK\r\n", "OK", ""},
+		{"integer", ":-42\r\n", int64(-42), ""},
+		{"bulk", "$5\r\nhello\r\n", []byte("hello"), ""},
+		{"binary bulk", "$4\r\na\r\nb\r\n", []byte("a\r\nb"), ""},
+		{"empty bulk", "$0\r\n\r\n", []byte{}, ""},
+		{"nil bulk", "$-1\r\n", nil, ""},
+		{"nil array", "*-1\r\n", nil, ""},
+		{"array", "*3\r\n$7\r\nmessage\r\n:1\r\n*1\r\n+nested\r\n", []interface{}{[]byte("message"), int64(1), []interface{}{"nested"}}, ""},
+		{"array with error", "*2\r\n-ERR first\r\n$-1\r\n", []interface{}{redisError("ERR first"), nil}, ""},
+		{"error", "-WRONGTYPE Operation against a key\r\n", nil, "redis: WRONGTYPE Operation against a key"},
+		{"malformed", "OK\n", nil, "redis: malformed reply"},
+		{"unknown type", "?1\r\n", nil, `redis: unexpected reply type '?'`},
+		{"truncated bulk", "$10\r\nshort\r\n", nil, "unexpected EOF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reply, err := pipeConn(t, tt.reply).read()
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("read() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(reply, tt.want) {
+				t.Errorf("read() = %#v, want %#v", reply, tt.want)
+			}
+		})
+	}
+}
+
+func TestRespErrorReplyIsRedisError(t *testing.T) {
+	_, err := pipeConn(t, "-NOSCRIPT No matching script\r\n").read()
+	var replyErr redisError
+	if !errors.As(err, &replyErr) || !strings.HasPrefix(string(replyErr), "NOSCRIPT") {
+		t.Errorf("read() error = %#v, want the reply as a redisError", err)
+	}
+}
+
+func TestRespWrite(t *testing.T) {
+	client, peer := net.Pipe()
+	defer client.Close()
+	conn := &respConn{conn: client, reader: bufio.NewReader(client), writer: bufio.NewWriter(client)}
+
+	sent := make(chan []byte)
+	go func() {
+		var buffer bytes.Buffer
+		chunk := make([]byte, 256)
+		for {
+			n, err := peer.Read(chunk)
+			buffer.Write(chunk[:n])
+			if err != nil || bytes.HasSuffix(buffer.Bytes(), []byte("$2\r\n-1\r\n")) {
+				break
+			}
+		}
+		sent <- buffer.Bytes()
+	}()
+	if err := conn.write("SET", []byte("k\r\n"), 5, int64(-1)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "*4\r\n$3\r\nSET\r\n$3\r\nk\r\n\r\n$1\r\n5\r\n$2\r\n-1\r\n"
+	if got := string(<-sent); got != want {
+		t.Errorf("wrote %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,325 @@
+package changefeed
+
+import (
+	stdcontext "context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Action is the kind of row change an event reports
+type Action string
+
+const (
+	Created Action = "Created"
+	Updated Action = "Updated"
+	Deleted Action = "Deleted"
+)
+
+const (
+	// DefaultSlot is the replication slot name used when Options.Slot is empty
+	DefaultSlot = "gontext_changes"
+	// DefaultBatchSize is the number of changes read per poll when Options.BatchSize is 0
+	DefaultBatchSize = 1000
+	// DefaultPollInterval is the wait between polls that found no changes when Options.PollInterval is 0
+	DefaultPollInterval = time.Second
+)
+
+// Options configures a change feed
+type Options struct {
+	// Slot is the logical replication slot the feed reads and advances (default DefaultSlot)
+	// Each independent consumer needs its own slot; a slot keeps WAL on the server until it is read
+	Slot string
+	// BatchSize caps the changes read per poll; whole transactions are always read (default DefaultBatchSize)
+	BatchSize int
+	// PollInterval is the wait after a poll that found no changes (default DefaultPollInterval)
+	PollInterval time.Duration
+}
+
+// Change is one row change decoded from the WAL
+type Change struct {
+	Action Action
+	Schema string
+	Table  string
+	LSN    string
+	// Columns holds the new row by column name; for deletes it holds the replica identity (the primary key
+	// unless the table uses REPLICA IDENTITY FULL)
+	Columns map[string]interface{}
+	// Identity holds the replica identity of the old row for updates and deletes
+	Identity map[string]interface{}
+}
+
+// Handler receives the changes of one table; an error stops the poll before the change's transaction is
+// acknowledged, so the whole transaction is delivered again by the next poll
+type Handler func(ctx stdcontext.Context, change Change) error
+
+// Feed reads row changes from a PostgreSQL logical replication slot using the wal2json output plugin and
+// dispatches them to the handlers subscribed to each table. Delivery is at least once: changes are read
+// without being consumed and the slot is only advanced past transactions whose changes were all handled
+type Feed struct {
+	db       *gorm.DB
+	options  Options
+	mu       sync.RWMutex
+	handlers map[string][]Handler // Table name -> handlers
+	poll     sync.Mutex           // Serializes polls so a transaction is not dispatched twice at once
+}
+
+// New creates a feed reading through db; the database needs wal_level=logical and the wal2json plugin
+func New(db *gorm.DB, options Options) *Feed {
+	if options.Slot == "" {
+		options.Slot = DefaultSlot
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = DefaultBatchSize
+	}
+	if options.PollInterval <= 0 {
+		options.PollInterval = DefaultPollInterval
+	}
+	return &Feed{db: db, options: options, handlers: make(map[string][]Handler)}
+}
+
+// Slot returns the name of the replication slot the feed reads
+func (f *Feed) Slot() string {
+	return f.options.Slot
+}
+
+// Subscribe calls handler for every change of table, in commit order
+func (f *Feed) Subscribe(table string, handler Handler) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.handlers[table] = append(f.handlers[table], handler)
+}
+
+// parse parses a model with the feed's naming strategy, so tables and columns match the context's
+func (f *Feed) parse(model interface{}) (*gorm.Statement, error) {
+	stmt := &gorm.Statement{DB: f.db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+// EnsureSlot creates the replication slot unless it exists; changes are recorded from its creation on
+func (f *Feed) EnsureSlot(ctx stdcontext.Context) error {
+	var count int64
+	err := f.db.WithContext(ctx).
+		Raw("SELECT COUNT(*) FROM pg_replication_slots WHERE slot_name = ?", f.options.Slot).
+		Scan(&count).Error
+	if err != nil {
+		return fmt.Errorf("failed to look up replication slot %s: %w", f.options.Slot, err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	err = f.db.WithContext(ctx).Exec("SELECT pg_create_logical_replication_slot(?, 'wal2json')", f.options.Slot).Error
+	if err != nil {
+		return fmt.Errorf("failed to create replication slot %s (requires wal_level=logical and wal2json): %w", f.options.Slot, err)
+	}
+	return nil
+}
+
+// DropSlot removes the replication slot so the server stops retaining WAL for it
+func (f *Feed) DropSlot(ctx stdcontext.Context) error {
+	err := f.db.WithContext(ctx).Exec("SELECT pg_drop_replication_slot(?)", f.options.Slot).Error
+	if err != nil {
+		return fmt.Errorf("failed to drop replication slot %s: %w", f.options.Slot, err)
+	}
+	return nil
+}
+
+// walRow is a row of pg_logical_slot_peek_changes
+type walRow struct {
+	LSN  string
+	Data string
+}
+
+// walMessage is a wal2json format-version 2 message
+type walMessage struct {
+	Action   string      `json:"action"`
+	Schema   string      `json:"schema"`
+	Table    string      `json:"table"`
+	Columns  []walColumn `json:"columns"`
+	Identity []walColumn `json:"identity"`
+}
+
+type walColumn struct {
+	Name  string      `json:"name"`
+	Value interface{} `json:"value"`
+}
+
+// Poll reads one batch of changes, dispatches them and advances the slot past the transactions handled,
+// returning the number of changes dispatched
+func (f *Feed) Poll(ctx stdcontext.Context) (int, error) {
+	dispatched, _, err := f.pollBatch(ctx)
+	return dispatched, err
+}
+
+// pollBatch is Poll, also reporting how many WAL rows were read
+func (f *Feed) pollBatch(ctx stdcontext.Context) (int, int, error) {
+	f.poll.Lock()
+	defer f.poll.Unlock()
+
+	tables := f.tables()
+	if len(tables) == 0 {
+		return 0, 0, nil
+	}
+
+	var rows []walRow
+	err := f.db.WithContext(ctx).Raw(
+		`SELECT lsn::text AS lsn, data FROM pg_logical_slot_peek_changes(?, NULL, ?, `+
+			`'format-version', '2', 'include-transaction', 'true', 'add-tables', ?)`,
+		f.options.Slot, f.options.BatchSize, addTables(tables),
+	).Scan(&rows).Error
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read changes from replication slot %s: %w", f.options.Slot, err)
+	}
+
+	dispatched := 0
+	committed := "" // LSN of the last transaction whose changes were all handled
+	var dispatchErr error
+	for _, row := range rows {
+		message, err := decodeWalMessage(row.Data)
+		if err != nil {
+			dispatchErr = fmt.Errorf("failed to decode change at %s: %w", row.LSN, err)
+			break
+		}
+
+		if message.Action == "C" {
+			committed = row.LSN
+			continue
+		}
+		change, ok := message.change(row.LSN)
+		if !ok {
+			continue // Transaction begin, truncate or logical message
+		}
+		if err := f.dispatch(ctx, change); err != nil {
+			dispatchErr = err
+			break
+		}
+		dispatched++
+	}
+
+	if committed != "" {
+		err := f.db.WithContext(ctx).Exec("SELECT pg_replication_slot_advance(?, ?::pg_lsn)", f.options.Slot, committed).Error
+		if err != nil {
+			return dispatched, len(rows), errors.Join(dispatchErr, fmt.Errorf("failed to advance replication slot %s: %w", f.options.Slot, err))
+		}
+	}
+	return dispatched, len(rows), dispatchErr
+}
+
+// Run polls until ctx is cancelled, creating the slot first; it returns ctx's error on cancellation or
+// the first error of a poll
+func (f *Feed) Run(ctx stdcontext.Context) error {
+	if err := f.EnsureSlot(ctx); err != nil {
+		return err
+	}
+
+	for {
+		_, read, err := f.pollBatch(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		if read > 0 {
+			continue // More changes may be waiting
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(f.options.PollInterval):
+		}
+	}
+}
+
+func (f *Feed) tables() []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	tables := make([]string, 0, len(f.handlers))
+	for table := range f.handlers {
+		tables = append(tables, table)
+	}
+	return tables
+}
+
+func (f *Feed) dispatch(ctx stdcontext.Context, change Change) error {
+	f.mu.RLock()
+	handlers := f.handlers[change.Table]
+	f.mu.RUnlock()
+
+	for _, handler := range handlers {
+		if err := handler(ctx, change); err != nil {
+			return fmt.Errorf("change handler for %s failed at %s: %w", change.Table, change.LSN, err)
+		}
+	}
+	return nil
+}
+
+// decodeWalMessage decodes a message, keeping numbers as json.Number so bigint and numeric values
+// keep their precision
+func decodeWalMessage(data string) (walMessage, error) {
+	var message walMessage
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	err := decoder.Decode(&message)
+	return message, err
+}
+
+// change converts an insert, update or delete message; other messages report false
+func (m walMessage) change(lsn string) (Change, bool) {
+	var action Action
+	switch m.Action {
+	case "I":
+		action = Created
+	case "U":
+		action = Updated
+	case "D":
+		action = Deleted
+	default:
+		return Change{}, false
+	}
+
+	change := Change{
+		Action:   action,
+		Schema:   m.Schema,
+		Table:    m.Table,
+		LSN:      lsn,
+		Columns:  columnValues(m.Columns),
+		Identity: columnValues(m.Identity),
+	}
+	if action == Deleted {
+		change.Columns = change.Identity
+	}
+	return change, true
+}
+
+func columnValues(columns []walColumn) map[string]interface{} {
+	values := make(map[string]interface{}, len(columns))
+	for _, column := range columns {
+		values[column.Name] = column.Value
+	}
+	return values
+}
+
+// addTables builds wal2json's add-tables filter, matching the tables in any schema; the characters
+// wal2json treats as special (space, single quote, comma, period, asterisk and the backslash itself) are
+// escaped with a backslash
+func addTables(tables []string) string {
+	escape := strings.NewReplacer(`\`, `\\`, " ", `\ `, "'", `\'`, ",", `\,`, ".", `\.`, "*", `\*`)
+	filters := make([]string, len(tables))
+	for i, table := range tables {
+		filters[i] = "*." + escape.Replace(table)
+	}
+	return strings.Join(filters, ",")
+}
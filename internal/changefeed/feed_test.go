@@ -0,0 +1,134 @@
+package changefeed
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// The payloads are wal2json format-version 2 output of pg_logical_slot_peek_changes with
+// include-transaction, for a table created as
+// CREATE TABLE orders (id bigint PRIMARY KEY, customer text, total numeric(10,2), paid boolean, note text)
+func TestWalMessageChange(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want Change
+		ok   bool
+	}{
+		{
+			name: "insert",
+			data: `{"action":"I","schema":"public","table":"orders","columns":[` +
+				`{"name":"id","type":"bigint","value":9007199254740993},` +
+				`{"name":"customer","type":"text","value":"Ada \"the\" Countess"},` +
+				`{"name":"total","type":"numeric(10,2)","value":12.50},` +
+				`{"name":"paid","type":"boolean","value":false},` +
+				`{"name":"note","type":"text","value":null}]}`,
+			want: Change{
+				Action: Created, Schema: "public", Table: "orders", LSN: "0/1A2B3C8",
+				Columns: map[string]interface{}{
+					"id": json.Number("9007199254740993"), "customer": `Ada "the" Countess`,
+					"total": json.Number("12.50"), "paid": false, "note": nil,
+				},
+				Identity: map[string]interface{}{},
+			},
+			ok: true,
+		},
+		{
+			name: "update",
+			data: `{"action":"U","schema":"public","table":"orders","columns":[` +
+				`{"name":"id","type":"bigint","value":7},` +
+				`{"name":"customer","type":"text","value":"Grace"},` +
+				`{"name":"total","type":"numeric(10,2)","value":30.00},` +
+				`{"name":"paid","type":"boolean","value":true},` +
+				`{"name":"note","type":"text","value":"rush"}],` +
+				`"identity":[{"name":"id","type":"bigint","value":7}]}`,
+			want: Change{
+				Action: Updated, Schema: "public", Table: "orders", LSN: "0/1A2B3C8",
+				Columns: map[string]interface{}{
+					"id": json.Number("7"), "customer": "Grace", "total": json.Number("30.00"), "paid": true, "note": "rush",
+				},
+				Identity: map[string]interface{}{"id": json.Number("7")},
+			},
+			ok: true,
+		},
+		{
+			name: "delete with primary key identity",
+			data: `{"action":"D","schema":"public","table":"orders","identity":[{"name":"id","type":"bigint","value":7}]}`,
+			want: Change{
+				Action: Deleted, Schema: "public", Table: "orders", LSN: "0/1A2B3C8",
+				Columns:  map[string]interface{}{"id": json.Number("7")},
+				Identity: map[string]interface{}{"id": json.Number("7")},
+			},
+			ok: true,
+		},
+		{
+			name: "delete with full identity",
+			data: `{"action":"D","schema":"audit","table":"orders","identity":[` +
+				`{"name":"id","type":"bigint","value":8},` +
+				`{"name":"customer","type":"text","value":"Linus"},` +
+				`{"name":"total","type":"numeric(10,2)","value":0.99},` +
+				`{"name":"paid","type":"boolean","value":true},` +
+				`{"name":"note","type":"text","value":null}]}`,
+			want: Change{
+				Action: Deleted, Schema: "audit", Table: "orders", LSN: "0/1A2B3C8",
+				Columns: map[string]interface{}{
+					"id": json.Number("8"), "customer": "Linus", "total": json.Number("0.99"), "paid": true, "note": nil,
+				},
+				Identity: map[string]interface{}{
+					"id": json.Number("8"), "customer": "Linus", "total": json.Number("0.99"), "paid": true, "note": nil,
+				},
+			},
+			ok: true,
+		},
+		{name: "begin", data: `{"action":"B","xid":741}`},
+		{name: "commit", data: `{"action":"C","xid":741}`},
+		{name: "truncate", data: `{"action":"T","schema":"public","table":"orders"}`},
+		{
+			name: "logical message",
+			data: `{"action":"M","transactional":true,"prefix":"gontext","content":"orders rebuilt"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := decodeWalMessage(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			change, ok := message.change("0/1A2B3C8")
+			if ok != tt.ok {
+				t.Fatalf("change() reported %v, want %v", ok, tt.ok)
+			}
+			if ok && !reflect.DeepEqual(change, tt.want) {
+				t.Errorf("change() = %#v, want %#v", change, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeWalMessageRejectsMalformedData(t *testing.T) {
+	if _, err := decodeWalMessage(`{"action":"I","columns":[{"name":"id","value":1}`); err == nil {
+		t.Error("decodeWalMessage accepted a truncated message")
+	}
+}
+
+func TestAddTables(t *testing.T) {
+	tests := []struct {
+		tables []string
+		want   string
+	}{
+		{[]string{"orders"}, `*.orders`},
+		{[]string{"orders", "order_items"}, `*.orders,*.order_items`},
+		{[]string{"order items"}, `*.order\ items`},
+		{[]string{"customer's"}, `*.customer\'s`},
+		{[]string{"a,b", "v1.2", "all*"}, `*.a\,b,*.v1\.2,*.all\*`},
+		{[]string{`back\slash`}, `*.back\\slash`},
+	}
+
+	for _, tt := range tests {
+		if got := addTables(tt.tables); got != tt.want {
+			t.Errorf("addTables(%q) = %s, want %s", tt.tables, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package changefeed
+
+import (
+	stdcontext "context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+// Event is a change of an entity of type T
+type Event[T any] struct {
+	Action Action
+	// Entity holds the new row for creates and updates; for deletes only its replica identity columns
+	// (the primary key unless the table uses REPLICA IDENTITY FULL) are set
+	Entity *T
+	Change Change
+}
+
+// Subscribe calls handler with every change of T's table decoded into a T
+func Subscribe[T any](f *Feed, handler func(ctx stdcontext.Context, event Event[T]) error) error {
+	stmt, err := f.parse(new(T))
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to changes of %T: %w", *new(T), err)
+	}
+
+	sch := stmt.Schema
+	f.Subscribe(sch.Table, func(ctx stdcontext.Context, change Change) error {
+		entity := new(T)
+		if err := decode(ctx, sch, reflect.ValueOf(entity).Elem(), change.Columns); err != nil {
+			return fmt.Errorf("failed to decode %s change: %w", sch.Name, err)
+		}
+		return handler(ctx, Event[T]{Action: change.Action, Entity: entity, Change: change})
+	})
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// timestampLayouts are the text forms PostgreSQL writes date and timestamp values in
+var timestampLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999-07",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// decode sets the fields of entity from column values; columns without a field are ignored
+func decode(ctx stdcontext.Context, sch *schema.Schema, entity reflect.Value, columns map[string]interface{}) error {
+	for column, value := range columns {
+		field := sch.LookUpField(column)
+		if field == nil || value == nil {
+			continue
+		}
+
+		switch v := value.(type) {
+		case json.Number:
+			value = v.String()
+		case string:
+			if field.FieldType == timeType || field.FieldType == reflect.PointerTo(timeType) {
+				parsed, err := parseTimestamp(v)
+				if err != nil {
+					return fmt.Errorf("column %s: %w", column, err)
+				}
+				value = parsed
+			}
+		}
+		if err := field.Set(ctx, entity, value); err != nil {
+			return fmt.Errorf("column %s: %w", column, err)
+		}
+	}
+	return nil
+}
+
+func parseTimestamp(value string) (time.Time, error) {
+	for _, layout := range timestampLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized timestamp %q", value)
+}
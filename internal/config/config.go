@@ -0,0 +1,233 @@
+package config
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FileNames are the configuration files searched for, in order
+var FileNames = []string{"gontext.json", "gontext.yaml", "gontext.yml"}
+
+// Config is the content of gontext.json / gontext.yaml
+//
+//	{
+//	  "default": "dev",
+//	  "connections": {
+//	    "dev":  { "connection_string": "postgres://localhost/app_dev?sslmode=disable" },
+//	    "prod": { "host": "db.internal", "port": 5432, "user": "app", "password": "${PROD_DB_PASSWORD}", "database": "app", "sslmode": "require" }
+//	  }
+//	}
+//
+// ${VAR} references are expanded from the environment, so secrets can stay out of the file
+type Config struct {
+	Default     string                      `json:"default"`
+	Connections map[string]ConnectionConfig `json:"connections"`
+	Path        string                      `json:"-"` // File the config was loaded from
+}
+
+// ConnectionConfig is one named connection: a full connection string or its parts
+type ConnectionConfig struct {
+	ConnectionString string            `json:"connection_string"`
+	Driver           string            `json:"driver"` // Default: postgres
+	Host             string            `json:"host"`
+	Port             int               `json:"port"`
+	User             string            `json:"user"`
+	Password         string            `json:"password"`
+	Database         string            `json:"database"`
+	SSLMode          string            `json:"sslmode"`
+	Options          map[string]string `json:"options"`
+}
+
+// String returns the connection string with environment references expanded
+func (c ConnectionConfig) String() string {
+	if c.ConnectionString != "" {
+		return os.ExpandEnv(c.ConnectionString)
+	}
+	options := make(map[string]string, len(c.Options))
+	for key, value := range c.Options {
+		options[key] = os.ExpandEnv(value)
+	}
+	return ConnectionStringBuilder{
+		Host:     os.ExpandEnv(c.Host),
+		Port:     c.Port,
+		User:     os.ExpandEnv(c.User),
+		Password: os.ExpandEnv(c.Password),
+		Database: os.ExpandEnv(c.Database),
+		SSLMode:  c.SSLMode,
+		Options:  options,
+	}.String()
+}
+
+// DriverName returns the configured driver, defaulting to postgres
+func (c ConnectionConfig) DriverName() string {
+	if c.Driver == "" {
+		return "postgres"
+	}
+	return c.Driver
+}
+
+// UnmarshalJSON accepts either a connection object or a plain connection string
+func (c *ConnectionConfig) UnmarshalJSON(data []byte) error {
+	var connectionString string
+	if err := json.Unmarshal(data, &connectionString); err == nil {
+		*c = ConnectionConfig{ConnectionString: connectionString}
+		return nil
+	}
+	type plain ConnectionConfig
+	return json.Unmarshal(data, (*plain)(c))
+}
+
+// Connection returns a named connection, or the default one when name is empty
+// The default is Default, then a connection named "default", then the only connection
+func (c *Config) Connection(name string) (ConnectionConfig, error) {
+	if name == "" {
+		name = c.Default
+	}
+	if name == "" {
+		if _, exists := c.Connections["default"]; exists {
+			name = "default"
+		} else if len(c.Connections) == 1 {
+			for only := range c.Connections {
+				name = only
+			}
+		}
+	}
+	if name == "" {
+		return ConnectionConfig{}, fmt.Errorf("%s defines several connections and no default", c.Path)
+	}
+
+	connection, exists := c.Connections[name]
+	if !exists {
+		names := make([]string, 0, len(c.Connections))
+		for known := range c.Connections {
+			names = append(names, known)
+		}
+		sort.Strings(names)
+		return ConnectionConfig{}, fmt.Errorf("connection %q not found in %s (available: %s)", name, c.Path, strings.Join(names, ", "))
+	}
+	return connection, nil
+}
+
+// Find looks for a configuration file in dir and its parents up to the module root (go.mod)
+// Returns nil without error when there is none
+func Find(dir string) (*Config, error) {
+	for {
+		for _, name := range FileNames {
+			path := filepath.Join(dir, name)
+			if _, err := os.Stat(path); err == nil {
+				return Load(path)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return nil, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// Load reads a gontext.json or gontext.yaml file
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	config := &Config{}
+	if strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml") {
+		err = parseYAML(data, config)
+	} else {
+		err = json.Unmarshal(data, config)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	config.Path = path
+	return config, nil
+}
+
+// Resolve finds the connection string for the CLI and applications:
+//  1. the named connection from the config file, when name is set
+//  2. the DATABASE_URL environment variable
+//  3. the default connection from the config file
+//  4. DATABASE_URL in a .env file
+func Resolve(dir, name string) (string, error) {
+	config, err := Find(dir)
+	if err != nil {
+		return "", err
+	}
+
+	if name != "" {
+		if config == nil {
+			return "", fmt.Errorf("connection %q requested but no %s found", name, strings.Join(FileNames, "/"))
+		}
+		connection, err := config.Connection(name)
+		if err != nil {
+			return "", err
+		}
+		return connection.String(), nil
+	}
+
+	if databaseURL := os.Getenv("DATABASE_URL"); databaseURL != "" {
+		return databaseURL, nil
+	}
+	if config != nil {
+		connection, err := config.Connection("")
+		if err != nil {
+			return "", err
+		}
+		return connection.String(), nil
+	}
+	return readDotEnv(filepath.Join(dir, ".env"), "DATABASE_URL"), nil
+}
+
+// readDotEnv returns a key from a .env file, handling comments, export prefixes and quotes
+func readDotEnv(path, key string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		name, value, found := strings.Cut(line, "=")
+		if !found || strings.TrimSpace(name) != key {
+			continue
+		}
+		return unquote(strings.TrimSpace(value))
+	}
+	return ""
+}
+
+// unquote strips matching quotes, or a trailing " # comment" from an unquoted value
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') {
+		if end := strings.LastIndexByte(value, value[0]); end > 0 {
+			if value[0] == '"' {
+				if unquoted, err := strconv.Unquote(value[:end+1]); err == nil {
+					return unquoted
+				}
+			}
+			return value[1:end]
+		}
+	}
+	if index := strings.Index(value, " #"); index >= 0 {
+		value = strings.TrimSpace(value[:index])
+	}
+	return value
+}
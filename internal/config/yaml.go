@@ -0,0 +1,124 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseYAML reads the subset of YAML used by gontext.yaml - nested mappings of scalars,
+// no lists, anchors or multi-line strings - which keeps the module free of a YAML dependency
+//
+//	default: dev
+//	connections:
+//	  dev: postgres://localhost/app_dev?sslmode=disable
+//	  prod:
+//	    host: db.internal
+//	    password: ${PROD_DB_PASSWORD}
+func parseYAML(data []byte, config *Config) error {
+	root, err := yamlMapping(data)
+	if err != nil {
+		return err
+	}
+
+	config.Default, _ = root["default"].(string)
+	connections, _ := root["connections"].(map[string]interface{})
+	config.Connections = make(map[string]ConnectionConfig, len(connections))
+	for name, raw := range connections {
+		if connectionString, ok := raw.(string); ok {
+			config.Connections[name] = ConnectionConfig{ConnectionString: connectionString}
+			continue
+		}
+
+		values, _ := raw.(map[string]interface{})
+		connection := ConnectionConfig{Options: make(map[string]string)}
+		for key, value := range values {
+			text, isText := value.(string)
+			switch key {
+			case "connection_string":
+				connection.ConnectionString = text
+			case "driver":
+				connection.Driver = text
+			case "host":
+				connection.Host = text
+			case "port":
+				if connection.Port, err = strconv.Atoi(text); err != nil {
+					return fmt.Errorf("connection %s: invalid port %q", name, text)
+				}
+			case "user":
+				connection.User = text
+			case "password":
+				connection.Password = text
+			case "database":
+				connection.Database = text
+			case "sslmode":
+				connection.SSLMode = text
+			case "options":
+				options, _ := value.(map[string]interface{})
+				for option, optionValue := range options {
+					connection.Options[option], _ = optionValue.(string)
+				}
+				continue
+			default:
+				return fmt.Errorf("connection %s: unknown key %q", name, key)
+			}
+			if !isText {
+				return fmt.Errorf("connection %s: %s must be a value", name, key)
+			}
+		}
+		config.Connections[name] = connection
+	}
+	return nil
+}
+
+// yamlMapping parses indentation-nested "key: value" lines into maps
+func yamlMapping(data []byte) (map[string]interface{}, error) {
+	type level struct {
+		indent  int
+		mapping map[string]interface{}
+	}
+	root := make(map[string]interface{})
+	stack := []level{{indent: -1, mapping: root}}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimRight(scanner.Text(), " \r")
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", lineNumber)
+		}
+		if strings.HasPrefix(trimmed, "- ") {
+			return nil, fmt.Errorf("line %d: lists are not supported", lineNumber)
+		}
+
+		key, value, found := strings.Cut(trimmed, ":")
+		if !found {
+			return nil, fmt.Errorf("line %d: expected key: value", lineNumber)
+		}
+		key = unquote(strings.TrimSpace(key))
+		value = unquote(strings.TrimSpace(value))
+
+		indent := len(line) - len(trimmed)
+		for len(stack) > 1 && indent <= stack[len(stack)-1].indent {
+			stack = stack[:len(stack)-1]
+		}
+		parent := stack[len(stack)-1].mapping
+		if _, exists := parent[key]; exists {
+			return nil, fmt.Errorf("line %d: duplicate key %q", lineNumber, key)
+		}
+
+		if value == "" {
+			child := make(map[string]interface{})
+			parent[key] = child
+			stack = append(stack, level{indent: indent, mapping: child})
+		} else {
+			parent[key] = value
+		}
+	}
+	return root, scanner.Err()
+}
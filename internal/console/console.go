@@ -0,0 +1,456 @@
+package console
+
+import (
+	"bufio"
+	stdcontext "context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/shepherrrd/gontext/internal/context"
+	"github.com/shepherrrd/gontext/internal/models"
+	"gorm.io/gorm"
+)
+
+// maxCellWidth truncates long values so result tables stay readable
+const maxCellWidth = 60
+
+// Console is an interactive prompt for LINQ-style queries and raw SQL - like rails console
+//
+//	gontext> User.Where("Email", "ada@example.com").First()
+//	gontext> Order.Where("\"Total\" > ?", 100).OrderByDescending("CreatedAt").Take(5)
+//	gontext> SELECT count(*) FROM "Orders"
+type Console struct {
+	db       *gorm.DB
+	entities map[string]models.EntitySnapshot // Keyed by lower-case entity name
+	in       io.Reader
+	out      io.Writer
+}
+
+// New creates a console for the entities registered on ctx
+func New(ctx *context.DbContext, in io.Reader, out io.Writer) *Console {
+	c := &Console{db: ctx.GetDB(), entities: make(map[string]models.EntitySnapshot), in: in, out: out}
+	return c.WithEntities(models.NewModelSnapshot(ctx.GetEntityModels()).Entities)
+}
+
+// WithEntities adds entities to query, e.g. from migrations/ModelSnapshot.json when nothing is registered
+func (c *Console) WithEntities(entities map[string]models.EntitySnapshot) *Console {
+	for _, entity := range entities {
+		c.entities[strings.ToLower(entity.Name)] = entity
+	}
+	return c
+}
+
+// Run reads statements until .exit or end of input
+// Ctrl+C cancels the running statement without leaving the console
+func (c *Console) Run() error {
+	fmt.Fprintln(c.out, "gontext console - type .help for commands, .exit to quit")
+
+	scanner := bufio.NewScanner(c.in)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for {
+		fmt.Fprint(c.out, "gontext> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(c.out)
+			return scanner.Err()
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		switch line {
+		case "":
+			continue
+		case ".exit", ".quit", "exit", "quit":
+			return nil
+		}
+		if err := c.Execute(line); err != nil {
+			fmt.Fprintf(c.out, "❌ %v\n", err)
+		}
+	}
+}
+
+// Execute runs one console statement: a dot command, an Entity.Method(...) chain or raw SQL
+func (c *Console) Execute(statement string) error {
+	statement = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(statement), ";"))
+
+	if strings.HasPrefix(statement, ".") {
+		return c.command(strings.Fields(statement))
+	}
+
+	ctx, stop := signal.NotifyContext(stdcontext.Background(), os.Interrupt)
+	defer stop()
+	db := c.db.WithContext(ctx)
+
+	entity, calls, isChain, err := parseChain(statement)
+	if err != nil {
+		return err
+	}
+	if isChain {
+		return c.runChain(db, entity, calls)
+	}
+	return c.runSQL(db, statement)
+}
+
+func (c *Console) command(fields []string) error {
+	switch fields[0] {
+	case ".help":
+		fmt.Fprintln(c.out, "Commands:")
+		fmt.Fprintln(c.out, "  .tables              List entities and their tables")
+		fmt.Fprintln(c.out, "  .describe <Entity>   Show the fields of an entity")
+		fmt.Fprintln(c.out, "  .exit                Leave the console")
+		fmt.Fprintln(c.out)
+		fmt.Fprintln(c.out, "Queries:")
+		fmt.Fprintln(c.out, "  Entity.Where(\"Field\", value) or .Where(\"sql condition ?\", args...), .Or(...)")
+		fmt.Fprintln(c.out, "  .OrderBy(\"Field\"), .OrderByDescending(\"Field\"), .Select(\"Field\", ...), .Skip(n), .Take(n)")
+		fmt.Fprintln(c.out, "  End with .ToList() (default), .First(), .Count() or .Any()")
+		fmt.Fprintln(c.out, "  Anything else runs as raw SQL")
+		return nil
+	case ".tables":
+		for _, entity := range c.sortedEntities() {
+			fmt.Fprintf(c.out, "  %s -> %s\n", entity.Name, entity.TableName)
+		}
+		if len(c.entities) == 0 {
+			fmt.Fprintln(c.out, "  (no entities registered)")
+		}
+		return nil
+	case ".describe":
+		if len(fields) < 2 {
+			return fmt.Errorf(".describe requires an entity name")
+		}
+		entity, err := c.entity(fields[1])
+		if err != nil {
+			return err
+		}
+		header := []string{"field", "column", "type", "key", "nullable"}
+		var rows [][]string
+		for _, field := range sortedFields(entity) {
+			key := ""
+			if field.IsPrimary {
+				key = "PK"
+			} else if field.IsUnique {
+				key = "UK"
+			}
+			rows = append(rows, []string{field.Name, field.ColumnName, field.Type, key, fmt.Sprint(field.IsNullable)})
+		}
+		c.printTable(header, rows)
+		return nil
+	default:
+		return fmt.Errorf("unknown command %s (try .help)", fields[0])
+	}
+}
+
+func (c *Console) runChain(db *gorm.DB, entityName string, calls []call) error {
+	entity, err := c.entity(entityName)
+	if err != nil {
+		return err
+	}
+
+	query := db.Table(entity.TableName)
+	terminal := "tolist"
+	ordered := false
+	for i, cl := range calls {
+		switch method := strings.ToLower(cl.Method); method {
+		case "where", "or":
+			condition, args, err := c.condition(entity, cl)
+			if err != nil {
+				return err
+			}
+			if method == "or" {
+				query = query.Or(condition, args...)
+			} else {
+				query = query.Where(condition, args...)
+			}
+		case "orderby", "orderbydescending", "thenby", "thenbydescending":
+			column, err := c.columnArg(entity, cl)
+			if err != nil {
+				return err
+			}
+			if strings.HasSuffix(method, "descending") {
+				column += " DESC"
+			}
+			query = query.Order(column)
+			ordered = true
+		case "select":
+			if len(cl.Args) == 0 {
+				return fmt.Errorf("%s requires at least one field", cl.Method)
+			}
+			columns := make([]string, len(cl.Args))
+			for a, arg := range cl.Args {
+				name, _ := arg.(string)
+				column, err := c.column(entity, name)
+				if err != nil {
+					return err
+				}
+				columns[a] = column
+			}
+			query = query.Select(strings.Join(columns, ", "))
+		case "skip", "take":
+			n, ok := intArg(cl)
+			if !ok {
+				return fmt.Errorf("%s requires a number", cl.Method)
+			}
+			if method == "skip" {
+				query = query.Offset(n)
+			} else {
+				query = query.Limit(n)
+			}
+		case "tolist", "first", "firstordefault", "count", "any":
+			if i != len(calls)-1 {
+				return fmt.Errorf("%s must be the last method", cl.Method)
+			}
+			terminal = method
+		default:
+			return fmt.Errorf("unknown method %s (try .help)", cl.Method)
+		}
+	}
+
+	switch terminal {
+	case "count", "any":
+		var count int64
+		if err := query.Count(&count).Error; err != nil {
+			return err
+		}
+		if terminal == "any" {
+			fmt.Fprintln(c.out, count > 0)
+		} else {
+			fmt.Fprintln(c.out, count)
+		}
+		return nil
+	case "first", "firstordefault":
+		if !ordered {
+			if key, ok := primaryKey(entity); ok {
+				query = query.Order(c.quote(key.ColumnName))
+			}
+		}
+		query = query.Limit(1)
+	}
+
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	return c.printRows(rows)
+}
+
+// condition supports Where("Field", value) equality and Where("raw condition ?", args...)
+func (c *Console) condition(entity models.EntitySnapshot, cl call) (string, []interface{}, error) {
+	if len(cl.Args) == 0 {
+		return "", nil, fmt.Errorf("%s requires a condition", cl.Method)
+	}
+	condition, ok := cl.Args[0].(string)
+	if !ok {
+		return "", nil, fmt.Errorf("%s condition must be a string", cl.Method)
+	}
+	if len(cl.Args) == 2 && !strings.ContainsAny(condition, " ?=<>") {
+		column, err := c.column(entity, condition)
+		if err != nil {
+			return "", nil, err
+		}
+		if cl.Args[1] == nil {
+			return column + " IS NULL", nil, nil
+		}
+		return column + " = ?", cl.Args[1:], nil
+	}
+	return condition, cl.Args[1:], nil
+}
+
+func (c *Console) columnArg(entity models.EntitySnapshot, cl call) (string, error) {
+	if len(cl.Args) != 1 {
+		return "", fmt.Errorf("%s requires one field", cl.Method)
+	}
+	name, _ := cl.Args[0].(string)
+	return c.column(entity, name)
+}
+
+// column resolves a field or column name to a quoted column
+func (c *Console) column(entity models.EntitySnapshot, name string) (string, error) {
+	for _, field := range entity.Fields {
+		if strings.EqualFold(field.Name, name) || strings.EqualFold(field.ColumnName, name) {
+			return c.quote(field.ColumnName), nil
+		}
+	}
+	return "", fmt.Errorf("unknown field %q on %s", name, entity.Name)
+}
+
+func (c *Console) quote(column string) string {
+	return c.db.Statement.Quote(column)
+}
+
+func (c *Console) runSQL(db *gorm.DB, statement string) error {
+	if !returnsRows(statement) {
+		result := db.Exec(statement)
+		if result.Error != nil {
+			return result.Error
+		}
+		fmt.Fprintf(c.out, "✅ %d rows affected\n", result.RowsAffected)
+		return nil
+	}
+
+	rows, err := db.Raw(statement).Rows()
+	if err != nil {
+		return err
+	}
+	return c.printRows(rows)
+}
+
+// returnsRows reports whether a raw statement is a query rather than a command
+func returnsRows(statement string) bool {
+	fields := strings.Fields(statement)
+	if len(fields) == 0 {
+		return false
+	}
+	switch strings.ToLower(fields[0]) {
+	case "select", "with", "show", "explain", "values", "table":
+		return true
+	}
+	return strings.Contains(strings.ToLower(statement), " returning ")
+}
+
+func (c *Console) printRows(rows *sql.Rows) error {
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	var table [][]string
+	values := make([]interface{}, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return err
+		}
+		row := make([]string, len(columns))
+		for i, value := range values {
+			row[i] = formatValue(value)
+		}
+		table = append(table, row)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	c.printTable(columns, table)
+	return nil
+}
+
+// printTable writes rows in psql style with a row count footer
+func (c *Console) printTable(header []string, rows [][]string) {
+	widths := make([]int, len(header))
+	for i, name := range header {
+		widths[i] = utf8.RuneCountInString(name)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			if width := utf8.RuneCountInString(cell); width > widths[i] {
+				widths[i] = width
+			}
+		}
+	}
+
+	writeRow := func(cells []string) {
+		padded := make([]string, len(cells))
+		for i, cell := range cells {
+			padded[i] = cell + strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+		}
+		fmt.Fprintf(c.out, " %s\n", strings.Join(padded, " | "))
+	}
+
+	writeRow(header)
+	separators := make([]string, len(widths))
+	for i, width := range widths {
+		separators[i] = strings.Repeat("-", width+2)
+	}
+	fmt.Fprintln(c.out, strings.Join(separators, "+"))
+	for _, row := range rows {
+		writeRow(row)
+	}
+
+	if len(rows) == 1 {
+		fmt.Fprintln(c.out, "(1 row)")
+	} else {
+		fmt.Fprintf(c.out, "(%d rows)\n", len(rows))
+	}
+}
+
+func formatValue(value interface{}) string {
+	var text string
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		text = string(v)
+	case time.Time:
+		text = v.Format(time.RFC3339)
+	default:
+		text = fmt.Sprint(v)
+	}
+
+	text = strings.NewReplacer("\n", "\\n", "\t", " ").Replace(text)
+	if utf8.RuneCountInString(text) > maxCellWidth {
+		text = string([]rune(text)[:maxCellWidth-1]) + "…"
+	}
+	return text
+}
+
+func intArg(cl call) (int, bool) {
+	if len(cl.Args) != 1 {
+		return 0, false
+	}
+	n, ok := cl.Args[0].(int64)
+	return int(n), ok
+}
+
+func (c *Console) entity(name string) (models.EntitySnapshot, error) {
+	if entity, exists := c.entities[strings.ToLower(name)]; exists {
+		return entity, nil
+	}
+	for _, entity := range c.entities {
+		if strings.EqualFold(entity.TableName, name) {
+			return entity, nil
+		}
+	}
+	return models.EntitySnapshot{}, fmt.Errorf("unknown entity %s (try .tables)", name)
+}
+
+func (c *Console) sortedEntities() []models.EntitySnapshot {
+	entities := make([]models.EntitySnapshot, 0, len(c.entities))
+	for _, entity := range c.entities {
+		entities = append(entities, entity)
+	}
+	sort.Slice(entities, func(i, j int) bool { return entities[i].Name < entities[j].Name })
+	return entities
+}
+
+// sortedFields lists primary keys first, then fields by name
+func sortedFields(entity models.EntitySnapshot) []models.FieldSnapshot {
+	fields := make([]models.FieldSnapshot, 0, len(entity.Fields))
+	for _, field := range entity.Fields {
+		fields = append(fields, field)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		if fields[i].IsPrimary != fields[j].IsPrimary {
+			return fields[i].IsPrimary
+		}
+		return fields[i].Name < fields[j].Name
+	})
+	return fields
+}
+
+func primaryKey(entity models.EntitySnapshot) (models.FieldSnapshot, bool) {
+	for _, field := range sortedFields(entity) {
+		if field.IsPrimary {
+			return field, true
+		}
+	}
+	return models.FieldSnapshot{}, false
+}
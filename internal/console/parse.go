@@ -0,0 +1,175 @@
+package console
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// call is one method of a query chain, e.g. Where("\"Age\" > ?", 30)
+type call struct {
+	Method string
+	Args   []interface{}
+}
+
+// parseChain parses Entity.Method(args).Method(args)...
+// Returns ok=false when the input does not start with <identifier>. so it can be run as raw SQL
+func parseChain(input string) (entity string, calls []call, ok bool, err error) {
+	p := &parser{input: strings.TrimSpace(input)}
+	entity = p.identifier()
+	if entity == "" || !p.consume('.') {
+		return "", nil, false, nil
+	}
+
+	for {
+		method := p.identifier()
+		if method == "" {
+			return "", nil, true, p.errorf("expected method name")
+		}
+		if !p.consume('(') {
+			return "", nil, true, p.errorf("expected ( after %s", method)
+		}
+		args, err := p.arguments()
+		if err != nil {
+			return "", nil, true, err
+		}
+		calls = append(calls, call{Method: method, Args: args})
+
+		p.skipSpace()
+		if p.done() {
+			return entity, calls, true, nil
+		}
+		if !p.consume('.') {
+			return "", nil, true, p.errorf("expected . between methods")
+		}
+	}
+}
+
+type parser struct {
+	input string
+	pos   int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) skipSpace() {
+	for !p.done() && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *parser) consume(ch byte) bool {
+	p.skipSpace()
+	if !p.done() && p.input[p.pos] == ch {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("syntax error at column %d: %s", p.pos+1, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) identifier() string {
+	p.skipSpace()
+	start := p.pos
+	for !p.done() {
+		ch := rune(p.input[p.pos])
+		if ch != '_' && !unicode.IsLetter(ch) && !(p.pos > start && unicode.IsDigit(ch)) {
+			break
+		}
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+// arguments parses a comma separated literal list up to the closing parenthesis
+func (p *parser) arguments() ([]interface{}, error) {
+	var args []interface{}
+	if p.consume(')') {
+		return args, nil
+	}
+	for {
+		arg, err := p.literal()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if p.consume(')') {
+			return args, nil
+		}
+		if !p.consume(',') {
+			return nil, p.errorf("expected , or )")
+		}
+	}
+}
+
+// literal parses "go string", 'sql string', `raw string`, numbers, true/false and nil/null
+func (p *parser) literal() (interface{}, error) {
+	p.skipSpace()
+	if p.done() {
+		return nil, p.errorf("unexpected end of input")
+	}
+
+	switch quote := p.input[p.pos]; quote {
+	case '"', '`':
+		end := p.pos + 1
+		for end < len(p.input) && p.input[end] != quote {
+			if quote == '"' && p.input[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(p.input) {
+			return nil, p.errorf("unterminated string")
+		}
+		value, err := strconv.Unquote(p.input[p.pos : end+1])
+		if err != nil {
+			return nil, p.errorf("invalid string: %v", err)
+		}
+		p.pos = end + 1
+		return value, nil
+	case '\'':
+		// SQL style: '' escapes a quote
+		var b strings.Builder
+		for end := p.pos + 1; end < len(p.input); end++ {
+			if p.input[end] == '\'' {
+				if end+1 < len(p.input) && p.input[end+1] == '\'' {
+					b.WriteByte('\'')
+					end++
+					continue
+				}
+				p.pos = end + 1
+				return b.String(), nil
+			}
+			b.WriteByte(p.input[end])
+		}
+		return nil, p.errorf("unterminated string")
+	}
+
+	start := p.pos
+	for !p.done() && !strings.ContainsRune(",) \t", rune(p.input[p.pos])) {
+		p.pos++
+	}
+	word := p.input[start:p.pos]
+	switch strings.ToLower(word) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "nil", "null":
+		return nil, nil
+	}
+	if n, err := strconv.ParseInt(word, 10, 64); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(word, 64); err == nil {
+		return f, nil
+	}
+	p.pos = start
+	return nil, p.errorf("invalid argument %q (quote strings)", word)
+}
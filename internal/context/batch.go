@@ -0,0 +1,358 @@
+package context
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"github.com/shepherrrd/gontext/internal/dberrors"
+)
+
+// DefaultMaxBatchSize caps rows per INSERT statement, keeping wide tables under driver parameter limits
+const DefaultMaxBatchSize = 500
+
+// changeBatch is a run of pending changes with the same state and entity type
+// that can be sent as one statement
+type changeBatch struct {
+	state   EntityState
+	entries []*EntityEntry
+}
+
+// SetMaxBatchSize sets how many rows SaveChanges sends per INSERT/UPDATE/DELETE statement - EF Core: MaxBatchSize
+// A size of 1 disables batching and writes every entity with its own statement
+func (ctx *DbContext) SetMaxBatchSize(size int) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
+	ctx.maxBatchSize = size
+}
+
+// MaxBatchSize returns the configured batch size, or DefaultMaxBatchSize when unset
+func (ctx *DbContext) MaxBatchSize() int {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	if ctx.maxBatchSize <= 0 {
+		return DefaultMaxBatchSize
+	}
+	return ctx.maxBatchSize
+}
+
+// batchChanges groups consecutive ordered changes sharing a state and entity type
+// Ordering is preserved, so dependency order between batches still holds
+func batchChanges(ordered []*EntityEntry, maxSize int) []changeBatch {
+	var batches []changeBatch
+	var lastType reflect.Type
+
+	for _, entry := range ordered {
+		entityType := reflect.TypeOf(entry.Entity)
+		if entityType.Kind() == reflect.Ptr {
+			entityType = entityType.Elem()
+		}
+
+		if n := len(batches); n > 0 && batches[n-1].state == entry.State && lastType == entityType &&
+			len(batches[n-1].entries) < maxSize {
+			batches[n-1].entries = append(batches[n-1].entries, entry)
+			continue
+		}
+		batches = append(batches, changeBatch{state: entry.State, entries: []*EntityEntry{entry}})
+		lastType = entityType
+	}
+
+	return batches
+}
+
+// saveBatch writes one batch: inserts, updates and deletes each become a single multi-row statement
+func (ctx *DbContext) saveBatch(tx *gorm.DB, batch changeBatch) error {
+	entities := make([]interface{}, len(batch.entries))
+	for i, entry := range batch.entries {
+		entities[i] = entityPointer(entry.Entity)
+	}
+
+	switch batch.state {
+	case EntityAdded:
+		for _, entity := range entities {
+			if err := ctx.generateValues(tx, entity); err != nil {
+				return err
+			}
+		}
+		// Without RETURNING a multi-row insert cannot read defaults back, so insert one by one
+		if len(entities) == 1 || !ctx.driver.SupportsReturning() {
+			for _, entity := range entities {
+				if err := ctx.insertEntity(tx, entity); err != nil {
+					return err
+				}
+			}
+		} else {
+			returning, err := returningColumns(tx, entities[0])
+			if err != nil {
+				return err
+			}
+			if err := tx.Clauses(returning).Create(pointerSlice(entities)).Error; err != nil {
+				return err
+			}
+		}
+		// Keep the tracked instances in sync with the generated values
+		for i, entry := range batch.entries {
+			entry.Entity = entities[i]
+		}
+
+	case EntityModified:
+		return updateBatch(tx, batch.entries, entities)
+
+	case EntityDeleted:
+		target := entities[0]
+		if len(entities) > 1 {
+			target = pointerSlice(entities) // DELETE ... WHERE key IN (...)
+		}
+		result := tx.Delete(target)
+		if result.Error != nil {
+			return result.Error
+		}
+		// A row was already removed (or never existed) - EF Core: DbUpdateConcurrencyException
+		if result.RowsAffected < int64(len(entities)) {
+			return &dberrors.DbError{Kind: dberrors.ErrConcurrency}
+		}
+	}
+
+	return nil
+}
+
+// updateEntity writes one modified entity with its own UPDATE
+func updateEntity(tx *gorm.DB, entity interface{}, properties []string) error {
+	// Explicitly marked properties update only their columns
+	if len(properties) > 0 {
+		return tx.Model(entity).Select(properties).Updates(entity).Error
+	}
+	return tx.Save(entity).Error
+}
+
+// maxUpdateParameters keeps a batched UPDATE under the parameter limits of every driver (SQLite: 32766)
+const maxUpdateParameters = 30000
+
+// updateRow is the key and assigned values of one entity in a batched UPDATE
+type updateRow struct {
+	entity interface{}
+	key    interface{}
+	values map[string]interface{} // Column -> value, as the entity's own UPDATE would set it
+	upsert bool                   // Saved whole: a missing row is inserted, like Save does
+}
+
+// updateBatch writes modified entities of one type with a single UPDATE that sets each column with a
+// CASE over the primary key. Each row's values come from a dry run of the entity's own UPDATE, so
+// hooks, serializers and the encryption and time zone plugins apply as before. Entities with loaded
+// navigations, which Save writes too, and types with AfterSave or AfterUpdate hooks, which must follow
+// the write, are updated one by one
+func updateBatch(tx *gorm.DB, entries []*EntityEntry, entities []interface{}) error {
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(entities[0]); err != nil {
+		return err
+	}
+	entitySchema := stmt.Schema
+	primaryKey := entitySchema.PrioritizedPrimaryField
+	batchable := len(entities) > 1 && len(entitySchema.PrimaryFields) == 1 && primaryKey != nil &&
+		!entitySchema.AfterSave && !entitySchema.AfterUpdate
+
+	var rows []updateRow
+	var table string
+	var columns []string
+	for i, entity := range entities {
+		properties := entries[i].ModifiedProperties
+		if !batchable || hasLoadedNavigations(tx, entitySchema, entity) {
+			if err := updateEntity(tx, entity, properties); err != nil {
+				return err
+			}
+			continue
+		}
+
+		dryRun := tx.Session(&gorm.Session{DryRun: true}).Set(keepUpdateSetKey, true)
+		if len(properties) > 0 {
+			dryRun = dryRun.Model(entity).Select(properties).Updates(entity)
+		} else {
+			dryRun = dryRun.Save(entity)
+		}
+		if dryRun.Error != nil {
+			return dryRun.Error
+		}
+		set, _ := dryRun.Statement.Clauses["SET"].Expression.(clause.Set)
+		key, _ := primaryKey.ValueOf(tx.Statement.Context, reflect.ValueOf(entity).Elem())
+		row := updateRow{entity: entity, key: key, values: make(map[string]interface{}, len(set)), upsert: len(properties) == 0}
+		for _, assignment := range set {
+			if !containsString(columns, assignment.Column.Name) {
+				columns = append(columns, assignment.Column.Name)
+			}
+			row.values[assignment.Column.Name] = assignment.Value
+		}
+		table = dryRun.Statement.Table
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 || len(columns) == 0 {
+		return nil
+	}
+
+	// Every row costs a key and a value per column plus its key in the IN list
+	chunk := maxUpdateParameters / (2*len(columns) + 1)
+	if chunk < 1 {
+		chunk = 1
+	}
+	for start := 0; start < len(rows); start += chunk {
+		end := start + chunk
+		if end > len(rows) {
+			end = len(rows)
+		}
+		if err := updateRows(tx, table, primaryKey.DBName, columns, rows[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keepUpdateSetKey marks the dry runs of updateBatch, whose SET clause must outlive gorm:update
+const keepUpdateSetKey = "gontext:keep_update_set"
+
+// keepUpdateSet builds the SET clause of a marked dry run ahead of gorm:update, which then uses it and
+// leaves it on the statement instead of removing it. It is registered after every other plugin, so
+// encrypted and normalized values are what it captures
+func keepUpdateSet(db *gorm.DB) {
+	if _, marked := db.Get(keepUpdateSetKey); !marked || db.Error != nil {
+		return
+	}
+	if _, exists := db.Statement.Clauses["SET"]; !exists {
+		if set := callbacks.ConvertToAssignments(db.Statement); len(set) != 0 {
+			db.Statement.AddClause(set)
+		}
+	}
+}
+
+// updateRows runs UPDATE table SET column = CASE key WHEN ? THEN ? ... ELSE column END WHERE key IN (...);
+// the ELSE keeps columns a row does not set and gives PostgreSQL the column's type for the parameters
+func updateRows(tx *gorm.DB, table, keyColumn string, columns []string, rows []updateRow) error {
+	quotedKey := tx.Statement.Quote(keyColumn)
+	var sql strings.Builder
+	var vars []interface{}
+	sql.WriteString("UPDATE " + tx.Statement.Quote(table) + " SET ")
+	for i, column := range columns {
+		if i > 0 {
+			sql.WriteString(", ")
+		}
+		quoted := tx.Statement.Quote(column)
+		sql.WriteString(quoted + " = CASE " + quotedKey)
+		for _, row := range rows {
+			if value, exists := row.values[column]; exists {
+				sql.WriteString(" WHEN ? THEN ?")
+				vars = append(vars, row.key, value)
+			}
+		}
+		sql.WriteString(" ELSE " + quoted + " END")
+	}
+
+	keys := make([]interface{}, len(rows))
+	for i, row := range rows {
+		keys[i] = row.key
+	}
+	sql.WriteString(" WHERE " + quotedKey + " IN ?")
+	vars = append(vars, keys)
+
+	result := tx.Exec(sql.String(), vars...)
+	if result.Error != nil {
+		return result.Error
+	}
+	// MySQL counts only rows whose values changed, so a shortfall is checked against the rows that exist
+	if result.RowsAffected < int64(len(rows)) {
+		return upsertMissing(tx, table, keyColumn, rows)
+	}
+	return nil
+}
+
+// upsertMissing inserts the entities saved whole whose rows no longer exist, as Save does
+func upsertMissing(tx *gorm.DB, table, keyColumn string, rows []updateRow) error {
+	keys := make([]interface{}, len(rows))
+	for i, row := range rows {
+		keys[i] = row.key
+	}
+	var existing []interface{}
+	if err := tx.Table(table).Where(clause.IN{Column: clause.Column{Name: keyColumn}, Values: keys}).
+		Pluck(keyColumn, &existing).Error; err != nil {
+		return err
+	}
+	found := make(map[string]bool, len(existing))
+	for _, key := range existing {
+		found[fmt.Sprint(normalizeKey(key))] = true
+	}
+
+	for _, row := range rows {
+		if row.upsert && !found[fmt.Sprint(normalizeKey(row.key))] {
+			if err := tx.Session(&gorm.Session{SkipHooks: true}).Clauses(clause.OnConflict{UpdateAll: true}).
+				Create(row.entity).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// normalizeKey lets keys read back as text or bytes match the entity's key values
+func normalizeKey(key interface{}) interface{} {
+	if bytes, ok := key.([]byte); ok {
+		return string(bytes)
+	}
+	return key
+}
+
+// hasLoadedNavigations reports whether an entity carries navigations that Save would write as well
+func hasLoadedNavigations(tx *gorm.DB, entitySchema *schema.Schema, entity interface{}) bool {
+	value := reflect.ValueOf(entity).Elem()
+	for _, relationship := range entitySchema.Relationships.Relations {
+		navigation := relationship.Field.ReflectValueOf(tx.Statement.Context, value)
+		switch navigation.Kind() {
+		case reflect.Slice, reflect.Map:
+			if navigation.Len() > 0 {
+				return true
+			}
+		default:
+			if !navigation.IsZero() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// entityPointer returns the entity as a pointer, copying value entities so GORM can write to them
+func entityPointer(entity interface{}) interface{} {
+	entityValue := reflect.ValueOf(entity)
+	if entityValue.Kind() == reflect.Ptr {
+		return entity
+	}
+	entityPtr := reflect.New(entityValue.Type())
+	entityPtr.Elem().Set(entityValue)
+	return entityPtr.Interface()
+}
+
+// returningColumns names every column in RETURNING: GORM scans an empty RETURNING clause into new slice
+// elements, while listed columns are written back into the inserted entities in place
+func returningColumns(tx *gorm.DB, entity interface{}) (clause.Returning, error) {
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(entity); err != nil {
+		return clause.Returning{}, err
+	}
+	returning := clause.Returning{Columns: make([]clause.Column, 0, len(stmt.Schema.DBNames))}
+	for _, name := range stmt.Schema.DBNames {
+		returning.Columns = append(returning.Columns, clause.Column{Name: name})
+	}
+	return returning, nil
+}
+
+// pointerSlice builds a typed []*T from same-typed entity pointers so GORM treats them as one batch
+func pointerSlice(entities []interface{}) interface{} {
+	slice := reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(entities[0])), 0, len(entities))
+	for _, entity := range entities {
+		slice = reflect.Append(slice, reflect.ValueOf(entity))
+	}
+	return slice.Interface()
+}
@@ -0,0 +1,17 @@
+package context
+
+import (
+	"fmt"
+
+	"github.com/shepherrrd/gontext/internal/changefeed"
+)
+
+// ChangeFeed creates a feed of the row changes committed to the database, read from a logical
+// replication slot (PostgreSQL with wal_level=logical and the wal2json plugin). Changes made by any
+// client are reported, not only this context's SaveChanges
+func (ctx *DbContext) ChangeFeed(options changefeed.Options) (*changefeed.Feed, error) {
+	if ctx.driver.Name() != "postgres" {
+		return nil, fmt.Errorf("change feeds require PostgreSQL, not %s", ctx.driver.Name())
+	}
+	return changefeed.New(ctx.db, options), nil
+}
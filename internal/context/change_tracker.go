@@ -4,7 +4,11 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"reflect"
+	"sort"
 	"sync"
+	"time"
+
+	"github.com/shepherrrd/gontext/internal/types"
 )
 
 type EntityState int
@@ -19,12 +23,21 @@ const (
 type EntityEntry struct {
 	Entity         interface{}
 	State          EntityState
-	OriginalEntity interface{} // Store original state for change detection
+	OriginalEntity interface{} // Store original state for change detection (nil with ExplicitTracking)
+	// ModifiedProperties limits the UPDATE to these fields; empty updates every column
+	ModifiedProperties []string
+	sequence           uint64 // Tracking order, keeps SaveChanges deterministic
+	snapshotHash       uint64 // Hash of OriginalEntity, valid when hashed
+	hashed             bool
 }
 
 type ChangeTracker struct {
-	entries map[string]*EntityEntry  // Use string keys instead of interface{} keys
-	mu      sync.RWMutex
+	entries  map[string]*EntityEntry  // Use string keys instead of interface{} keys
+	sequence uint64
+	strategy ChangeTrackingStrategy
+	maxTracked int // Evict unchanged entries once this many are tracked, 0 = unlimited
+	tableOf  func(reflect.Type) string // Table of a registered entity type, "" when unknown
+	mu       sync.RWMutex
 }
 
 func NewChangeTracker() *ChangeTracker {
@@ -41,6 +54,7 @@ func (ct *ChangeTracker) entityKey(entity interface{}) string {
 	}
 	
 	entityType := value.Type()
+	name := ct.keyName(entityType)
 	
 	// Try to find the primary key field (typically "Id" or "ID")
 	var pkValue interface{} = ""
@@ -56,16 +70,32 @@ func (ct *ChangeTracker) entityKey(entity interface{}) string {
 		}
 	}
 	
-	// If no primary key value found or it's a zero value, create a unique hash based on field values
+	// Without a key value, a new entity is its instance: identical entities added twice are two rows,
+	// each needing its own generated key written back. Entities passed by value fall back to a hash of
+	// their field values
 	if pkValue == "" || pkValue == nil || isZeroValue(reflect.ValueOf(pkValue)) {
+		if pointer := reflect.ValueOf(entity); pointer.Kind() == reflect.Ptr {
+			return fmt.Sprintf("%s:%#x", name, pointer.Pointer())
+		}
 		if value.Kind() == reflect.Struct {
 			// Create a hash based on hashable field values only
 			hash := ct.hashStructFields(value, entityType)
-			return fmt.Sprintf("%s:%s", entityType.Name(), hash)
+			return fmt.Sprintf("%s:%s", name, hash)
 		}
 	}
 	
-	return fmt.Sprintf("%s:%v", entityType.Name(), pkValue)
+	return fmt.Sprintf("%s:%v", name, pkValue)
+}
+
+// keyName names an entity type in tracking keys by its table, so entities of the same row share an entry
+// while same-named types of different packages do not; unregistered types use their struct name
+func (ct *ChangeTracker) keyName(entityType reflect.Type) string {
+	if ct.tableOf != nil {
+		if table := ct.tableOf(entityType); table != "" {
+			return table
+		}
+	}
+	return entityType.Name()
 }
 
 // hashStructFields creates a hash based on hashable field values
@@ -145,11 +175,13 @@ func (ct *ChangeTracker) Add(entity interface{}, state EntityState) {
 	defer ct.mu.Unlock()
 
 	key := ct.entityKey(entity)
-	ct.entries[key] = &EntityEntry{
+	ct.sequence++
+	ct.entries[key] = (&EntityEntry{
 		Entity:         entity,
 		State:          state,
-		OriginalEntity: ct.deepCopy(entity), // Store original state
-	}
+		OriginalEntity: ct.snapshot(entity), // Store original state
+		sequence:       ct.sequence,
+	}).fingerprint()
 }
 
 // TrackLoaded tracks an entity that was loaded from the database
@@ -161,12 +193,17 @@ func (ct *ChangeTracker) TrackLoaded(entity interface{}) {
 	key := ct.entityKey(entity)
 	// Only track if not already tracked
 	if _, exists := ct.entries[key]; !exists {
+		if ct.maxTracked > 0 && len(ct.entries) >= ct.maxTracked {
+			ct.evictUnchanged()
+		}
 		fmt.Printf("[GONTEXT DEBUG] Tracking loaded entity: %s\n", key)
-		ct.entries[key] = &EntityEntry{
+		ct.sequence++
+		ct.entries[key] = (&EntityEntry{
 			Entity:         entity,
 			State:          EntityUnchanged,
-			OriginalEntity: ct.deepCopy(entity),
-		}
+			OriginalEntity: ct.snapshot(entity),
+			sequence:       ct.sequence,
+		}).fingerprint()
 	}
 }
 
@@ -191,6 +228,25 @@ func (ct *ChangeTracker) GetChanges() []*EntityEntry {
 			result = append(result, v)
 		}
 	}
+	// Return changes in the order they were tracked rather than map order
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].sequence < result[j].sequence
+	})
+	return result
+}
+
+// Entries returns every tracked entry, unchanged ones included, in tracking order
+func (ct *ChangeTracker) Entries() []*EntityEntry {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+
+	result := make([]*EntityEntry, 0, len(ct.entries))
+	for _, entry := range ct.entries {
+		result = append(result, entry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].sequence < result[j].sequence
+	})
 	return result
 }
 
@@ -201,6 +257,23 @@ func (ct *ChangeTracker) Clear() {
 	ct.entries = make(map[string]*EntityEntry)
 }
 
+// forget stops tracking entries, e.g. changes already saved while others are still pending
+func (ct *ChangeTracker) forget(entries []*EntityEntry) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	// Keys of added entries were taken before their keys were generated, so entries are matched as such
+	forgotten := make(map[*EntityEntry]bool, len(entries))
+	for _, entry := range entries {
+		forgotten[entry] = true
+	}
+	for key, entry := range ct.entries {
+		if forgotten[entry] {
+			delete(ct.entries, key)
+		}
+	}
+}
+
 func (ct *ChangeTracker) HasChanges() bool {
 	ct.mu.RLock()
 	defer ct.mu.RUnlock()
@@ -225,6 +298,28 @@ func (ct *ChangeTracker) DetectChanges() {
 			continue
 		}
 
+		// Entities with dirty-tracking setters report their own changes
+		if dirty, ok := entry.Entity.(DirtyTracker); ok {
+			if properties := dirty.DirtyProperties(); len(properties) > 0 {
+				entry.State = EntityModified
+				entry.ModifiedProperties = properties
+				changeCount++
+			}
+			continue
+		}
+
+		// Without a snapshot only MarkModified/SetProperty can flag changes
+		if entry.OriginalEntity == nil {
+			continue
+		}
+
+		// An unchanged hash means an unchanged entity; a different one is confirmed field by field
+		if entry.hashed {
+			if hash, ok := snapshotHash(entry.Entity); ok && hash == entry.snapshotHash {
+				continue
+			}
+		}
+
 		// Compare current entity with original
 		if !ct.entitiesEqual(entry.Entity, entry.OriginalEntity) {
 			fmt.Printf("[GONTEXT DEBUG] Change detected for entity %s\n", key)
@@ -253,20 +348,39 @@ func (ct *ChangeTracker) deepCopy(entity interface{}) interface{} {
 		}
 		originalElem := original.Elem()
 		copyPtr := reflect.New(originalElem.Type())
-		ct.copyRecursive(originalElem, copyPtr.Elem())
+		copies := map[pointerVisit]reflect.Value{visitOf(original): copyPtr}
+		ct.copyRecursive(originalElem, copyPtr.Elem(), copies)
 		return copyPtr.Interface()
 	}
 
 	// Handle non-pointer
 	copy := reflect.New(original.Type()).Elem()
-	ct.copyRecursive(original, copy)
+	ct.copyRecursive(original, copy, make(map[pointerVisit]reflect.Value))
 	return copy.Interface()
 }
 
-// copyRecursive recursively copies values
-func (ct *ChangeTracker) copyRecursive(original, copy reflect.Value) {
+// pointerVisit identifies a pointer already followed; the type tells a struct from its first field
+type pointerVisit struct {
+	pointer uintptr
+	typ     reflect.Type
+}
+
+func visitOf(pointer reflect.Value) pointerVisit {
+	return pointerVisit{pointer: pointer.Pointer(), typ: pointer.Type()}
+}
+
+// copyRecursive recursively copies values; copies maps the pointers already copied to their copy, so
+// shared instances stay shared in the copy and cycles (author.Posts[0].Author == author) end
+func (ct *ChangeTracker) copyRecursive(original, copy reflect.Value, copies map[pointerVisit]reflect.Value) {
 	switch original.Kind() {
 	case reflect.Struct:
+		// Value types such as time.Time keep their state in unexported fields; copy them whole
+		if isOpaqueStruct(original.Type()) || isRegisteredType(original.Type()) {
+			if copy.CanSet() && original.CanInterface() {
+				copy.Set(original)
+			}
+			return
+		}
 		originalType := original.Type()
 		for i := 0; i < original.NumField(); i++ {
 			field := originalType.Field(i)
@@ -280,14 +394,14 @@ func (ct *ChangeTracker) copyRecursive(original, copy reflect.Value) {
 			
 			// Only copy if both are accessible and the copy field can be set
 			if originalField.CanInterface() && copyField.CanSet() {
-				ct.copyRecursive(originalField, copyField)
+				ct.copyRecursive(originalField, copyField, copies)
 			}
 		}
 	case reflect.Slice:
 		if !original.IsNil() {
 			copy.Set(reflect.MakeSlice(original.Type(), original.Len(), original.Cap()))
 			for i := 0; i < original.Len(); i++ {
-				ct.copyRecursive(original.Index(i), copy.Index(i))
+				ct.copyRecursive(original.Index(i), copy.Index(i), copies)
 			}
 		}
 	case reflect.Map:
@@ -295,16 +409,21 @@ func (ct *ChangeTracker) copyRecursive(original, copy reflect.Value) {
 			copy.Set(reflect.MakeMap(original.Type()))
 			for _, key := range original.MapKeys() {
 				copyKey := reflect.New(key.Type()).Elem()
-				ct.copyRecursive(key, copyKey)
+				ct.copyRecursive(key, copyKey, copies)
 				copyValue := reflect.New(original.MapIndex(key).Type()).Elem()
-				ct.copyRecursive(original.MapIndex(key), copyValue)
+				ct.copyRecursive(original.MapIndex(key), copyValue, copies)
 				copy.SetMapIndex(copyKey, copyValue)
 			}
 		}
 	case reflect.Ptr:
 		if !original.IsNil() {
+			if copied, exists := copies[visitOf(original)]; exists {
+				copy.Set(copied)
+				return
+			}
 			copy.Set(reflect.New(original.Elem().Type()))
-			ct.copyRecursive(original.Elem(), copy.Elem())
+			copies[visitOf(original)] = copy
+			ct.copyRecursive(original.Elem(), copy.Elem(), copies)
 		}
 	default:
 		if copy.CanSet() && original.CanInterface() {
@@ -313,6 +432,28 @@ func (ct *ChangeTracker) copyRecursive(original, copy reflect.Value) {
 	}
 }
 
+var timeType = reflect.TypeOf(time.Time{})
+
+// isOpaqueStruct reports whether a struct keeps all its state in unexported fields (time.Time, big.Int, ...),
+// which field-by-field copying and comparison would skip
+func isOpaqueStruct(t reflect.Type) bool {
+	if t.NumField() == 0 {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).PkgPath == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// isRegisteredType reports whether t was registered with types.Register, so it is copied as a value
+func isRegisteredType(t reflect.Type) bool {
+	_, ok := types.Lookup(t)
+	return ok
+}
+
 // entitiesEqual compares two entities for equality
 func (ct *ChangeTracker) entitiesEqual(entity1, entity2 interface{}) bool {
 	if entity1 == nil && entity2 == nil {
@@ -324,8 +465,12 @@ func (ct *ChangeTracker) entitiesEqual(entity1, entity2 interface{}) bool {
 
 	value1 := reflect.ValueOf(entity1)
 	value2 := reflect.ValueOf(entity2)
+	visited := make(map[[2]pointerVisit]bool)
 
 	// Dereference pointers
+	if value1.Kind() == reflect.Ptr && value2.Kind() == reflect.Ptr && !value1.IsNil() && !value2.IsNil() {
+		visited[[2]pointerVisit{visitOf(value1), visitOf(value2)}] = true
+	}
 	if value1.Kind() == reflect.Ptr {
 		if value1.IsNil() && value2.IsNil() {
 			return true
@@ -347,17 +492,30 @@ func (ct *ChangeTracker) entitiesEqual(entity1, entity2 interface{}) bool {
 		return false
 	}
 
-	return ct.valuesEqual(value1, value2)
+	return ct.valuesEqual(value1, value2, visited)
 }
 
-// valuesEqual recursively compares reflect.Values
-func (ct *ChangeTracker) valuesEqual(value1, value2 reflect.Value) bool {
+// valuesEqual recursively compares reflect.Values; visited holds the pointer pairs being compared, which
+// are taken as equal when a cycle leads back to them, like reflect.DeepEqual does
+func (ct *ChangeTracker) valuesEqual(value1, value2 reflect.Value, visited map[[2]pointerVisit]bool) bool {
 	if value1.Type() != value2.Type() {
 		return false
 	}
 
+	if value1.Kind() != reflect.Ptr && value1.CanInterface() && value2.CanInterface() {
+		if mapping, ok := types.Lookup(value1.Type()); ok {
+			return mapping.Equals(value1.Interface(), value2.Interface())
+		}
+	}
+
 	switch value1.Kind() {
 	case reflect.Struct:
+		if value1.Type() == timeType && value1.CanInterface() && value2.CanInterface() {
+			return value1.Interface().(time.Time).Equal(value2.Interface().(time.Time))
+		}
+		if isOpaqueStruct(value1.Type()) && value1.CanInterface() && value2.CanInterface() {
+			return reflect.DeepEqual(value1.Interface(), value2.Interface())
+		}
 		structType := value1.Type()
 		for i := 0; i < value1.NumField(); i++ {
 			field := structType.Field(i)
@@ -371,7 +529,7 @@ func (ct *ChangeTracker) valuesEqual(value1, value2 reflect.Value) bool {
 			
 			// Only compare if both fields can be accessed
 			if field1.CanInterface() && field2.CanInterface() {
-				if !ct.valuesEqual(field1, field2) {
+				if !ct.valuesEqual(field1, field2, visited) {
 					return false
 				}
 			}
@@ -382,7 +540,7 @@ func (ct *ChangeTracker) valuesEqual(value1, value2 reflect.Value) bool {
 			return false
 		}
 		for i := 0; i < value1.Len(); i++ {
-			if !ct.valuesEqual(value1.Index(i), value2.Index(i)) {
+			if !ct.valuesEqual(value1.Index(i), value2.Index(i), visited) {
 				return false
 			}
 		}
@@ -394,7 +552,7 @@ func (ct *ChangeTracker) valuesEqual(value1, value2 reflect.Value) bool {
 		for _, key := range value1.MapKeys() {
 			val1 := value1.MapIndex(key)
 			val2 := value2.MapIndex(key)
-			if !val2.IsValid() || !ct.valuesEqual(val1, val2) {
+			if !val2.IsValid() || !ct.valuesEqual(val1, val2, visited) {
 				return false
 			}
 		}
@@ -406,7 +564,12 @@ func (ct *ChangeTracker) valuesEqual(value1, value2 reflect.Value) bool {
 		if value1.IsNil() || value2.IsNil() {
 			return false
 		}
-		return ct.valuesEqual(value1.Elem(), value2.Elem())
+		pair := [2]pointerVisit{visitOf(value1), visitOf(value2)}
+		if visited[pair] {
+			return true
+		}
+		visited[pair] = true
+		return ct.valuesEqual(value1.Elem(), value2.Elem(), visited)
 	default:
 		// Use safe comparison for basic types
 		if value1.CanInterface() && value2.CanInterface() {
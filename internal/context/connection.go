@@ -0,0 +1,88 @@
+package context
+
+import (
+	stdcontext "context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxConnectRetryDelay caps the exponential backoff between connection attempts
+const maxConnectRetryDelay = 30 * time.Second
+
+// connectWithRetry retries the initial connection with exponential backoff, for applications
+// that start together with their database (docker compose, Kubernetes)
+func connectWithRetry(options DbContextOptions) (*gorm.DB, error) {
+	delay := options.ConnectRetryDelay
+	if delay <= 0 {
+		delay = time.Second
+	}
+
+	for attempt := 0; ; attempt++ {
+		db, err := connect(options)
+		if err == nil {
+			return db, nil
+		}
+		if attempt >= options.ConnectRetries {
+			if attempt > 0 {
+				return nil, fmt.Errorf("giving up after %d attempts: %w", attempt+1, err)
+			}
+			return nil, err
+		}
+
+		log.Printf("Database not ready (attempt %d/%d): %v - retrying in %s", attempt+1, options.ConnectRetries+1, err, delay)
+		time.Sleep(delay)
+		if delay *= 2; delay > maxConnectRetryDelay {
+			delay = maxConnectRetryDelay
+		}
+	}
+}
+
+// warmup opens connections eagerly and returns them to the pool, so the first requests
+// do not pay for TCP, TLS and authentication round trips
+func warmup(db *gorm.DB, connections int) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	// database/sql keeps 2 idle connections by default and would close the rest on release
+	if connections > 2 {
+		sqlDB.SetMaxIdleConns(connections)
+	}
+
+	ctx, cancel := stdcontext.WithTimeout(stdcontext.Background(), 30*time.Second)
+	defer cancel()
+
+	conns := make([]*sql.Conn, connections)
+	errs := make([]error, connections)
+	var wg sync.WaitGroup
+	for i := range conns {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if conns[i], errs[i] = sqlDB.Conn(ctx); errs[i] == nil {
+				errs[i] = conns[i].PingContext(ctx)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Release only after all are open, otherwise the pool would hand the same connection out again
+	var firstErr error
+	for i, conn := range conns {
+		if conn != nil {
+			conn.Close()
+		}
+		if errs[i] != nil && firstErr == nil {
+			firstErr = errs[i]
+		}
+	}
+	if firstErr != nil {
+		return fmt.Errorf("failed to warm up connection pool: %w", firstErr)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package context
+
+import (
+	stdcontext "context"
+	"reflect"
+
+	"github.com/shepherrrd/gontext/internal/dberrors"
+)
+
+// Database runs raw SQL on the context's connection, or on its transaction while one is active
+// - EF Core: context.Database. Arguments are always bound as parameters: positional with ?, or
+// named with @name and sql.Named / a map[string]interface{}
+type Database struct {
+	ctx *DbContext
+}
+
+// Database returns the raw SQL API of the context, the supported replacement for GetDB
+func (ctx *DbContext) Database() *Database {
+	return &Database{ctx: ctx}
+}
+
+// Exec runs a statement and returns the number of affected rows
+// Usage: ctx.Database().Exec(`UPDATE "User" SET "IsActive" = false WHERE "LastLogin" < ?`, cutoff)
+func (d *Database) Exec(sql string, args ...interface{}) (int64, error) {
+	return d.ExecContext(stdcontext.Background(), sql, args...)
+}
+
+// ExecContext runs a statement until c is done and returns the number of affected rows
+func (d *Database) ExecContext(c stdcontext.Context, sql string, args ...interface{}) (int64, error) {
+	result := d.ctx.db.WithContext(c).Exec(sql, args...)
+	if result.Error != nil {
+		return 0, dberrors.Translate(result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// QueryContext runs a query and scans its rows into dest, a pointer to a slice of structs or scalars
+// Columns are matched to fields like LINQ queries match them (column tags, the naming convention)
+// Loaded entities run their AfterLoad hook; with track, those of registered entity types are tracked
+// so changes to them are saved by SaveChanges
+func (d *Database) QueryContext(c stdcontext.Context, dest interface{}, track bool, sql string, args ...interface{}) error {
+	if err := d.ctx.db.WithContext(c).Raw(sql, args...).Scan(dest).Error; err != nil {
+		return dberrors.Translate(err)
+	}
+
+	rows := reflect.ValueOf(dest).Elem()
+	if rows.Kind() != reflect.Slice || rows.Type().Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	track = track && d.ctx.GetEntityModel(rows.Type().Elem()) != nil
+	for i := 0; i < rows.Len(); i++ {
+		entity := rows.Index(i).Addr().Interface()
+		if track {
+			d.ctx.TrackLoaded(entity)
+		} else {
+			d.ctx.afterLoad(entity)
+		}
+	}
+	return nil
+}
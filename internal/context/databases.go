@@ -0,0 +1,191 @@
+package context
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"gorm.io/gorm"
+
+	"github.com/shepherrrd/gontext/internal/drivers"
+	"github.com/shepherrrd/gontext/internal/models"
+)
+
+// AddDatabase attaches another database to the context under name, so entity types routed to it with
+// RouteEntity are queried and saved there while the context stays the single place the application
+// works with. database is a context of its own connection (NewDbContext); its migrations are generated
+// from it, with its own migrations directory, and closing this context closes it
+// Panics when name is empty or taken, since databases are configured once at startup
+func (ctx *DbContext) AddDatabase(name string, database *DbContext) {
+	if name == "" {
+		panic("Database name must not be empty")
+	}
+	if database == nil || database == ctx || database.parent != nil {
+		panic(fmt.Sprintf("Database '%s' must be a context of its own connection", name))
+	}
+
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
+	if _, exists := ctx.databases[name]; exists {
+		panic(fmt.Sprintf("Database '%s' is already added", name))
+	}
+	if ctx.databases == nil {
+		ctx.databases = make(map[string]*DbContext)
+	}
+	ctx.databases[name] = database
+}
+
+// DatabaseContext returns the database added under name, e.g. for its migrations:
+// gontext.NewMigrationManager(ctx.DatabaseContext("analytics"), "migrations/analytics", "analytics")
+// Returns nil for unknown names
+func (ctx *DbContext) DatabaseContext(name string) *DbContext {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	return ctx.databases[name]
+}
+
+// RouteEntity registers the entity type, if needed, and stores it in the named database: LINQ sets
+// created afterwards query that database and SaveChanges writes its changes there. The entity model is
+// shared, so configuration through the model builder applies in both places; the entity leaves this
+// context's migrations and EnsureCreated and joins those of the database
+// Each database saves in its own transaction: SaveChanges writes one database after the other, and
+// WithTransaction and UseTransaction cover only this context's own database. Navigations between
+// entities of different databases cannot be included
+// Panics when the database was not added
+func (ctx *DbContext) RouteEntity(entity interface{}, name string) *DbSet {
+	dbSet := ctx.RegisterEntity(entity)
+	database := ctx.DatabaseContext(name)
+	if database == nil {
+		panic(fmt.Sprintf("Database '%s' not found, add it with AddDatabase", name))
+	}
+	database.adoptEntity(dbSet.entityType, dbSet.entityModel)
+
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
+	if ctx.routes == nil {
+		ctx.routes = make(map[string]*DbContext)
+	}
+	ctx.routes[typeKey(dbSet.entityType)] = database
+	return dbSet
+}
+
+// adoptEntity registers an entity model of the context the database was added to
+func (ctx *DbContext) adoptEntity(entityType reflect.Type, entityModel *models.EntityModel) {
+	key := typeKey(entityType)
+
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
+	if _, exists := ctx.entities[key]; exists {
+		return
+	}
+	ctx.applySchema(entityModel)
+	ctx.entities[key] = entityModel
+	ctx.entityTypes[key] = entityType
+
+	registered := []*models.EntityModel{entityModel}
+	if current := ctx.registered.Load(); current != nil {
+		registered = append(registered, (*current)...)
+	}
+	ctx.registered.Store(&registered)
+	ctx.dbSets[key] = NewDbSet(ctx, entityType, entityModel)
+}
+
+// databaseOf returns the database an entity type is routed to, nil for the context's own database
+func (ctx *DbContext) databaseOf(entityType reflect.Type) *DbContext {
+	if entityType.Kind() == reflect.Ptr {
+		entityType = entityType.Elem()
+	}
+
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	return ctx.routes[typeKey(entityType)]
+}
+
+// DBFor returns the connection storing an entity type: that of the database it is routed to, or the
+// context's own (GetDB)
+func (ctx *DbContext) DBFor(entityType reflect.Type) *gorm.DB {
+	if database := ctx.databaseOf(entityType); database != nil {
+		return database.db
+	}
+	return ctx.db
+}
+
+// DriverFor returns the driver of the database storing an entity type
+func (ctx *DbContext) DriverFor(entityType reflect.Type) drivers.DatabaseDriver {
+	if database := ctx.databaseOf(entityType); database != nil {
+		return database.driver
+	}
+	return ctx.driver
+}
+
+// databaseChanges are the pending changes stored in one database
+type databaseChanges struct {
+	name     string     // "" for the context's own database
+	database *DbContext // Context whose connection, driver and value generators write the changes
+	entries  []*EntityEntry
+}
+
+// changesByDatabase splits pending changes by the database storing them, the shard of its key for
+// sharded entities: the context's own database first, always present so a save without changes still
+// runs as before, then the others by name
+func (ctx *DbContext) changesByDatabase(pending []*EntityEntry) ([]databaseChanges, error) {
+	ctx.mu.RLock()
+	routes := len(ctx.routes)
+	names := make(map[*DbContext]string, len(ctx.databases))
+	for name, database := range ctx.databases {
+		names[database] = name
+	}
+	ctx.mu.RUnlock()
+
+	own := databaseChanges{database: ctx}
+	if routes == 0 {
+		own.entries = pending
+		return []databaseChanges{own}, nil
+	}
+
+	byDatabase := make(map[*DbContext]*databaseChanges)
+	for _, entry := range pending {
+		database := ctx.databaseOf(reflect.TypeOf(entry.Entity))
+		if sharded := ctx.shardedEntityOf(reflect.TypeOf(entry.Entity)); sharded != nil {
+			var err error
+			if database, _, err = sharded.databaseOfEntity(entry.Entity); err != nil {
+				return nil, err
+			}
+		}
+		if database == nil {
+			own.entries = append(own.entries, entry)
+			continue
+		}
+		changes, exists := byDatabase[database]
+		if !exists {
+			changes = &databaseChanges{name: names[database], database: database}
+			byDatabase[database] = changes
+		}
+		changes.entries = append(changes.entries, entry)
+	}
+
+	others := make([]databaseChanges, 0, len(byDatabase))
+	for _, changes := range byDatabase {
+		others = append(others, *changes)
+	}
+	sort.Slice(others, func(i, j int) bool { return others[i].name < others[j].name })
+	return append([]databaseChanges{own}, others...), nil
+}
+
+// databaseNames lists the names of the added databases in order
+func (ctx *DbContext) databaseNames() []string {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	names := make([]string, 0, len(ctx.databases))
+	for name := range ctx.databases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -1,15 +1,31 @@
 package context
 
 import (
+	stdcontext "context"
+	"crypto/tls"
+	"database/sql"
 	"fmt"
 	"log"
 	"reflect"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"github.com/shepherrrd/gontext/internal/cache"
+	"github.com/shepherrrd/gontext/internal/credentials"
+	"github.com/shepherrrd/gontext/internal/dberrors"
 	"github.com/shepherrrd/gontext/internal/drivers"
+	"github.com/shepherrrd/gontext/internal/encryption"
+	"github.com/shepherrrd/gontext/internal/locks"
 	"github.com/shepherrrd/gontext/internal/models"
 	"github.com/shepherrrd/gontext/internal/query"
+	"github.com/shepherrrd/gontext/internal/search"
+	"github.com/shepherrrd/gontext/internal/types"
 )
 
 // typeKey converts a reflect.Type to a string key for map storage
@@ -20,25 +36,142 @@ func typeKey(t reflect.Type) string {
 type DbContext struct {
 	db            *gorm.DB
 	driver        drivers.DatabaseDriver
+	parent        *DbContext // Context a scoped context was created from, nil for root contexts
 	entities      map[string]*models.EntityModel  // Use string keys instead of reflect.Type
 	entityTypes   map[string]reflect.Type         // Map to store the actual reflect.Type for each key
 	dbSets        map[string]interface{}          // Use string keys instead of reflect.Type  
 	mu            sync.RWMutex
 	changeTracker *ChangeTracker
 	pgPlugin      *query.PostgreSQLPlugin
+	hiLo          map[string]*hiLoAllocator // sequence name -> allocator
+	validator     StructValidator
+	ignoreCase    bool // Case-insensitive string matching for every query
+	literalStrings bool // Disable ">40"-style operator parsing in WhereField/WhereEntity string values
+	location      *time.Location // Time zone for calendar-day query helpers
+	timestampType drivers.TimestampType // Column type of time.Time fields, applied to GORM's schema too
+	maxBatchSize  int            // Rows per SaveChanges statement, 0 = DefaultMaxBatchSize
+	naming        models.NamingConvention // Table/column naming shared by queries and migrations
+	tableNaming   models.TableNaming      // Table names, nil to follow naming
+	constraintNaming models.ConstraintNaming // Index and constraint names, nil for the default
+	ownDB         *gorm.DB       // The context's own connection while db is a shared transaction (UseTransaction)
+	strictSQL     *query.StrictSQLPlugin  // Rejects raw conditions with inline literals when enabled
+	stmtCache     *query.StatementCache   // Prepared statement cache, nil unless PrepareStmt is set
+	plans         *query.PlanCache        // Cached LINQ translations, nil when disabled
+	futures       *query.FutureBatch      // Future queries waiting to be sent together
+	registered    atomic.Pointer[[]*models.EntityModel] // Copy-on-write list for lookups that must not take mu
+	searchEngine  search.Engine // Indexes Searchable entities, nil when unset
+	searchFromFeed bool         // Searchable entities are indexed from a change feed, not after SaveChanges
+	queryCache    *cache.Cache  // Results of Cached() queries, nil unless QueryCache is set
+	locks         *locks.Manager // Named locks shared through the GontextLocks table
+	sequenceModels map[string]*models.SequenceModel // Declared with HasSequence, created by migrations
+	sequences     *Sequences                       // Block allocator of sequence values
+	defaultScopes map[string][]interface{}         // Scopes applied to every LINQ set of an entity type
+	strictQueries bool // LINQ methods record an error for arguments they cannot use
+	queryTypes    sync.Map // typeKey -> SQL of types registered with RegisterQueryType, read without mu
+	databases     map[string]*DbContext // Databases added with AddDatabase, by name
+	routes        map[string]*DbContext // typeKey -> database of entity types routed with RouteEntity
+	shards        map[string]*shardedEntity // typeKey -> shards of entity types spread with ShardEntity
 }
 
 type DbContextOptions struct {
 	ConnectionString string
 	Driver          drivers.DatabaseDriver
 	LogLevel        string
+	// NamingConvention maps Go names to table/column names; DefaultNaming keeps the driver's behaviour
+	NamingConvention models.NamingConvention
+	// TableNaming names tables instead of the convention, e.g. models.PluralTableNames{Convention: SnakeCase}
+	// for "blog_posts"; a TableName() method and EntityTypeBuilder.ToTable still win
+	TableNaming models.TableNaming
+	// ConstraintNaming names generated indexes and constraints (default models.DefaultConstraintNaming,
+	// which keeps names within PostgreSQL's 63 characters). Changing it renames indexes in the next migration
+	ConstraintNaming models.ConstraintNaming
+	// Credentials supplies the user and password (and optionally host/database) when connections open,
+	// e.g. from a secrets manager; requires a postgres:// connection string
+	Credentials credentials.Provider
+	// CredentialRefresh re-fetches credentials after this interval and recycles pooled connections
+	// at the same pace so they reconnect with rotated secrets; 0 fetches once
+	CredentialRefresh time.Duration
+	// TokenProvider supplies the password of every new connection, e.g. RDS IAM or Azure AD tokens
+	TokenProvider credentials.TokenProvider
+	// TLS replaces sslmode with a custom CA and client certificates; see credentials.TLSOptions
+	TLS *tls.Config
+	// ConnectRetries retries the initial connection this many times while the database starts up
+	ConnectRetries int
+	// ConnectRetryDelay is the first wait between attempts, doubled each time up to 30s (default 1s)
+	ConnectRetryDelay time.Duration
+	// WarmupConnections opens this many pooled connections at startup to avoid first-request latency
+	WarmupConnections int
+	// StatementTimeout cancels any statement running longer than this (0 = no limit);
+	// LinqDbSet.WithTimeout overrides it per query
+	StatementTimeout time.Duration
+	// StrictSQL rejects raw WHERE/HAVING conditions containing inline literals, ; or comments
+	StrictSQL bool
+	// StrictQueries makes LINQ methods fail the query on arguments they cannot use instead of ignoring
+	// them; the default enables it unless GONTEXT_ENV is production
+	StrictQueries StrictQueryMode
+	// PrepareStmt prepares parameterized statements once and reuses them to skip parse/plan work
+	PrepareStmt bool
+	// PrepareStmtMaxSize caps the cached statements; the least recently used is closed beyond it
+	// (default query.DefaultStatementCacheSize)
+	PrepareStmtMaxSize int
+	// QueryPlanCacheSize caps the cached condition and field translations (default
+	// query.DefaultPlanCacheSize); negative disables the cache
+	QueryPlanCacheSize int
+	// QueryCache stores the results of LinqDbSet.Cached queries, e.g. cache.NewMemory(0) or a shared
+	// cache.NewRedis(...); entries are invalidated per table whenever gontext writes to it
+	QueryCache cache.Provider
+	// QueryCacheJitter varies each entry's TTL by up to this fraction so entries cached together do not
+	// expire together (default cache.DefaultJitter); negative disables it
+	QueryCacheJitter float64
+	// TimestampType maps time.Time fields to timestamptz or timestamp (PostgreSQL) in generated
+	// migrations and AutoMigrate/EnsureCreated alike; the default keeps each path's historical type
+	TimestampType drivers.TimestampType
+	// StoreTimesInUTC converts time.Time values to UTC before they are written: entity fields, update
+	// maps and query parameters. Without it, timestamp and DATETIME columns store the local wall clock
+	StoreTimesInUTC bool
+	// ReadTimesIn converts time.Time fields of loaded entities to this location (default as scanned:
+	// the session time zone for timestamptz, UTC for timestamp on PostgreSQL)
+	ReadTimesIn *time.Location
 }
 
 func NewDbContext(options DbContextOptions) (*DbContext, error) {
-	db, err := options.Driver.ConnectWithLogger(options.ConnectionString, options.LogLevel)
+	db, err := connectWithRetry(options)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
+	var stmtCache *query.StatementCache
+	if options.PrepareStmt {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, fmt.Errorf("failed to enable prepared statements: %w", err)
+		}
+		stmtCache = query.NewStatementCache(sqlDB, options.PrepareStmtMaxSize)
+		stmtCache.Install(db)
+	}
+	if err := db.Use(query.NewMaterializerPlugin()); err != nil {
+		return nil, fmt.Errorf("failed to register result materializer: %w", err)
+	}
+	if err := db.Use(query.NewStatementTimeoutPlugin(options.StatementTimeout)); err != nil {
+		return nil, fmt.Errorf("failed to register statement timeout: %w", err)
+	}
+	if err := db.Use(query.NewTimeZonePlugin(options.StoreTimesInUTC, options.ReadTimesIn)); err != nil {
+		return nil, fmt.Errorf("failed to register time zone handling: %w", err)
+	}
+	if typer, ok := options.Driver.(drivers.TimestampTyper); ok {
+		typer.SetTimestampType(options.TimestampType)
+	}
+	strictSQL := query.NewStrictSQLPlugin(options.StrictSQL)
+	if err := db.Use(strictSQL); err != nil {
+		return nil, fmt.Errorf("failed to register strict SQL mode: %w", err)
+	}
+	if options.WarmupConnections > 0 {
+		if err := warmup(db, options.WarmupConnections); err != nil {
+			if sqlDB, dbErr := db.DB(); dbErr == nil {
+				sqlDB.Close()
+			}
+			return nil, err
+		}
+	}
 
 	ctx := &DbContext{
 		db:            db,
@@ -47,6 +180,46 @@ func NewDbContext(options DbContextOptions) (*DbContext, error) {
 		entityTypes:   make(map[string]reflect.Type),
 		dbSets:        make(map[string]interface{}),
 		changeTracker: NewChangeTracker(),
+		hiLo:          make(map[string]*hiLoAllocator),
+		naming:        options.NamingConvention,
+		tableNaming:   options.TableNaming,
+		constraintNaming: options.ConstraintNaming,
+		timestampType: options.TimestampType,
+		strictSQL:     strictSQL,
+		stmtCache:     stmtCache,
+		plans:         query.NewPlanCache(options.QueryPlanCacheSize),
+		futures:       query.NewFutureBatch(),
+		locks:         locks.NewManager(db),
+		sequenceModels: make(map[string]*models.SequenceModel),
+		strictQueries: options.StrictQueries.enabled(),
+	}
+	ctx.sequences = newSequences(ctx)
+	ctx.changeTracker.tableOf = ctx.registeredTable
+	if options.QueryCache != nil {
+		ctx.queryCache = cache.New(options.QueryCache, options.QueryCacheJitter)
+		if err := db.Use(cache.NewInvalidationPlugin(ctx.queryCache)); err != nil {
+			return nil, fmt.Errorf("failed to register query cache invalidation: %w", err)
+		}
+	}
+	if err := db.Use(encryption.NewPlugin(ctx.EncryptedColumns)); err != nil {
+		return nil, fmt.Errorf("failed to register column encryption: %w", err)
+	}
+	// Registered last so it runs after the plugins that rewrite values before gorm:update
+	if err := db.Callback().Update().Before("gorm:update").Register("gontext:keep_update_set", keepUpdateSet); err != nil {
+		return nil, fmt.Errorf("failed to register batched updates: %w", err)
+	}
+	// Sessions share the config, so every statement's log goes through the masking logger
+	db.Config.Logger = query.NewMaskingLogger(db.Config.Logger, ctx.isSensitiveColumn)
+	
+	// Apply the convention before any model is parsed so GORM and migrations agree on names
+	if options.NamingConvention != models.DefaultNaming {
+		db.Config.NamingStrategy = query.NewConventionNamingStrategy(options.NamingConvention.Convert)
+	}
+	if namer, ok := db.Config.NamingStrategy.(interface{ SetConstraintNaming(models.ConstraintNaming) }); ok {
+		namer.SetConstraintNaming(ctx.ConstraintNaming())
+	}
+	if options.TableNaming != nil {
+		db.Config.NamingStrategy = query.NewTableNamingStrategy(db.Config.NamingStrategy, options.TableNaming.TableName)
 	}
 	
 	// Check if this is PostgreSQL - we'll get the plugin differently
@@ -58,6 +231,74 @@ func NewDbContext(options DbContextOptions) (*DbContext, error) {
 	return ctx, nil
 }
 
+// connect opens the database, fetching credentials per connection and applying custom TLS when configured
+func connect(options DbContextOptions) (*gorm.DB, error) {
+	if options.Credentials == nil && options.TokenProvider == nil && options.TLS == nil {
+		return options.Driver.ConnectWithLogger(options.ConnectionString, options.LogLevel)
+	}
+
+	connector, ok := options.Driver.(drivers.OptionsConnector)
+	if !ok {
+		return nil, fmt.Errorf("%s driver does not support credential providers or custom TLS", options.Driver.Name())
+	}
+
+	connectionString := options.ConnectionString
+	connectOptions := drivers.ConnectOptions{TLS: options.TLS}
+
+	var cache *credentials.Cache
+	if options.Credentials != nil {
+		// Resolve once up front so secret errors surface here and host/database from the secret apply
+		cache = credentials.NewCache(options.Credentials, options.CredentialRefresh)
+		initial, err := cache.Get(stdcontext.Background())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get database credentials: %w", err)
+		}
+		if connectionString, err = initial.Apply(connectionString); err != nil {
+			return nil, err
+		}
+	}
+	if options.TokenProvider != nil {
+		if _, err := options.TokenProvider.Token(stdcontext.Background()); err != nil {
+			return nil, fmt.Errorf("failed to get database token: %w", err)
+		}
+	}
+
+	if cache != nil || options.TokenProvider != nil {
+		connectOptions.Credentials = func(ctx stdcontext.Context) (string, string, error) {
+			var user, password string
+			if cache != nil {
+				current, err := cache.Get(ctx)
+				if err != nil {
+					return "", "", err
+				}
+				user, password = current.User, current.Password
+			}
+			if options.TokenProvider != nil {
+				token, err := options.TokenProvider.Token(ctx)
+				if err != nil {
+					return "", "", err
+				}
+				password = token
+			}
+			return user, password, nil
+		}
+	}
+
+	db, err := connector.ConnectWithOptions(connectionString, options.LogLevel, connectOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.CredentialRefresh > 0 {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		sqlDB.SetConnMaxLifetime(options.CredentialRefresh)
+	}
+	return db, nil
+}
+
 func (ctx *DbContext) RegisterEntity(entity interface{}) *DbSet {
 	entityType := reflect.TypeOf(entity)
 	if entityType.Kind() == reflect.Ptr {
@@ -73,16 +314,143 @@ func (ctx *DbContext) RegisterEntity(entity interface{}) *DbSet {
 		return ctx.dbSets[key].(*DbSet)
 	}
 
-	entityModel := models.NewEntityModel(entityType)
+	entityModel := models.NewEntityModelWithNaming(entityType, ctx.naming, ctx.tableNaming)
+	entityModel.ConstraintNaming = ctx.constraintNaming
+	ctx.applySchema(entityModel)
 	ctx.entities[key] = entityModel
 	ctx.entityTypes[key] = entityType  // Store the reflect.Type for later retrieval
 
+	registered := []*models.EntityModel{entityModel}
+	if current := ctx.registered.Load(); current != nil {
+		registered = append(registered, (*current)...)
+	}
+	ctx.registered.Store(&registered)
+
 	dbSet := NewDbSet(ctx, entityType, entityModel)
 	ctx.dbSets[key] = dbSet
 
 	return dbSet
 }
 
+// ApplyEntityModel applies changes made to an entity model through the model builder (table, ignored
+// fields, computed columns) to GORM's parsed schema of the entity; translations cached under the old
+// mapping are dropped
+func (ctx *DbContext) ApplyEntityModel(entityModel *models.EntityModel) {
+	ctx.applySchema(entityModel)
+	if sharded := ctx.shardedEntityOf(entityModel.Type); sharded != nil {
+		for _, database := range sharded.databases {
+			database.ApplyEntityModel(entityModel)
+		}
+	} else if database := ctx.databaseOf(entityModel.Type); database != nil {
+		database.ApplyEntityModel(entityModel)
+	}
+	ctx.plans.Clear()
+}
+
+// registeredTable returns the table of a registered entity type, or "" for other types
+// It reads the registered list, so the change tracker can call it without taking mu
+func (ctx *DbContext) registeredTable(entityType reflect.Type) string {
+	registered := ctx.registered.Load()
+	if registered == nil {
+		return ""
+	}
+	for _, entityModel := range *registered {
+		if entityModel.Type == entityType {
+			return entityModel.TableName
+		}
+	}
+	return ""
+}
+
+// applySchema makes GORM's parsed schema of the entity match its model, so AutoMigrate creates the same
+// table and columns as generated migrations and queries, inserts and updates use the same ones: a table
+// set with ToTable replaces GORM's, time fields get the
+// configured timestamp type and fields of types registered with types.Register their column type
+// (explicit type tags win), strings get their max length and collation, ignored fields are removed, and
+// computed columns are generated by the database and never written
+func (ctx *DbContext) applySchema(entityModel *models.EntityModel) {
+	stmt := &gorm.Statement{DB: ctx.db}
+	if err := stmt.Parse(reflect.New(entityModel.Type).Interface()); err != nil {
+		return // Not a GORM model; AutoMigrate would fail on it anyway
+	}
+	if entityModel.TableConfigured {
+		stmt.Schema.Table = entityModel.TableName
+	}
+
+	ignored := make(map[string]bool, len(entityModel.IgnoredFields))
+	for _, path := range entityModel.IgnoredFields {
+		ignored[path] = true
+	}
+	fields := make(map[string]models.FieldModel, len(entityModel.Fields))
+	for _, field := range entityModel.Fields {
+		fields[field.Path()] = field
+	}
+
+	_, timestampTyper := ctx.driver.(drivers.TimestampTyper)
+	for _, field := range stmt.Schema.Fields {
+		path := strings.Join(field.BindNames, ".")
+		if ignored[path] {
+			removeSchemaField(stmt.Schema, field)
+			continue
+		}
+
+		if field.TagSettings["TYPE"] == "" {
+			if mapping, ok := types.Lookup(field.FieldType); ok {
+				if columnType := mapping.ColumnTypeFor(ctx.driver.Name()); columnType != "" {
+					field.DataType = schema.DataType(columnType)
+				}
+			} else if field.GORMDataType == schema.Time && timestampTyper && ctx.timestampType != drivers.TimestampDefault {
+				field.DataType = schema.DataType(strings.ToLower(ctx.driver.MapGoTypeToSQL("time.Time")))
+			}
+		}
+
+		model, exists := fields[path]
+		if !exists {
+			continue
+		}
+		if field.TagSettings["TYPE"] == "" && field.GORMDataType == schema.String {
+			if model.MaxLength > 0 {
+				field.Size = model.MaxLength
+			}
+			if model.Collation != "" {
+				// Rebuilt from the abstract type each time, so a later length change is kept
+				base := *field
+				base.DataType = base.GORMDataType
+				var collation strings.Builder
+				ctx.db.Dialector.QuoteTo(&collation, model.Collation)
+				field.DataType = schema.DataType(ctx.db.Dialector.DataTypeOf(&base) + " COLLATE " + collation.String())
+			}
+		}
+		if model.IsComputed() && !strings.Contains(string(field.DataType), "GENERATED ALWAYS") {
+			field.DataType = schema.DataType(ctx.db.Dialector.DataTypeOf(field) + " " +
+				models.ComputedColumnClause(model.ComputedColumnSql, model.IsComputedStored))
+			field.Creatable = false
+			field.Updatable = false
+			field.HasDefaultValue = false
+			field.DefaultValue = ""
+		}
+	}
+}
+
+// removeSchemaField leaves an ignored field out of GORM's columns, as if it were tagged gorm:"-"
+func removeSchemaField(sch *schema.Schema, field *schema.Field) {
+	if field.DBName == "" {
+		return
+	}
+	delete(sch.FieldsByDBName, field.DBName)
+	for i, name := range sch.DBNames {
+		if name == field.DBName {
+			sch.DBNames = append(sch.DBNames[:i:i], sch.DBNames[i+1:]...)
+			break
+		}
+	}
+	field.DBName = ""
+	field.Creatable = false
+	field.Updatable = false
+	field.Readable = false
+	field.IgnoreMigration = true
+}
+
 func (ctx *DbContext) GetDbSet(entityType reflect.Type) *DbSet {
 	ctx.mu.RLock()
 	defer ctx.mu.RUnlock()
@@ -96,43 +464,205 @@ func (ctx *DbContext) GetDbSet(entityType reflect.Type) *DbSet {
 }
 
 func (ctx *DbContext) SaveChanges() error {
-	// Automatically detect changes before saving
-	ctx.changeTracker.DetectChanges()
-	
-	return ctx.db.Transaction(func(tx *gorm.DB) error {
-		for _, changes := range ctx.changeTracker.GetChanges() {
-			entity := changes.Entity
-			
-			// Ensure we have a pointer for GORM operations
-			entityValue := reflect.ValueOf(entity)
-			if entityValue.Kind() != reflect.Ptr {
-				// Create a pointer to the entity
-				entityPtr := reflect.New(entityValue.Type())
-				entityPtr.Elem().Set(entityValue)
-				entity = entityPtr.Interface()
-			}
-			
-			switch changes.State {
-			case EntityAdded:
-				if err := tx.Create(entity).Error; err != nil {
-					return err
-				}
-			case EntityModified:
-				if err := tx.Save(entity).Error; err != nil {
-					return err
-				}
-			case EntityDeleted:
-				if err := tx.Delete(entity).Error; err != nil {
-					return err
-				}
+	return ctx.SaveChangesWithOptions(SaveOptions{})
+}
+
+// saveChanges writes the pending changes of one database in one transaction of its connection
+func (ctx *DbContext) saveChanges(database *DbContext, pending []*EntityEntry, opts ...*sql.TxOptions) error {
+	maxBatchSize := ctx.MaxBatchSize()
+	err := database.db.Transaction(func(tx *gorm.DB) error {
+		// Same-type runs share a statement so mixed changes need few round trips
+		for _, batch := range batchChanges(ctx.orderChanges(pending), maxBatchSize) {
+			if err := database.saveBatch(tx, batch); err != nil {
+				return err
 			}
 		}
-		ctx.changeTracker.Clear()
 		return nil
+	}, opts...)
+	return dberrors.Translate(err)
+}
+
+// acceptChanges forgets saved changes once their commit succeeded, so a failed commit can be retried;
+// the last database of a save clears the whole tracker
+func (ctx *DbContext) acceptChanges(saved []*EntityEntry, clear bool) {
+	// Writes inside the transaction invalidated before the commit; readers may have cached the old rows since
+	ctx.invalidateSaved(saved)
+
+	for _, entry := range saved {
+		if dirty, ok := entry.Entity.(DirtyTracker); ok {
+			dirty.ClearDirty()
+		}
+	}
+	if clear {
+		ctx.changeTracker.Clear()
+	} else {
+		ctx.changeTracker.forget(saved)
+	}
+}
+
+// orderChanges orders pending changes by entity dependencies:
+// inserts and updates run parent-first, then deletes run child-first so foreign keys are never violated
+func (ctx *DbContext) orderChanges(changes []*EntityEntry) []*EntityEntry {
+	ctx.mu.RLock()
+	sortedEntities, _ := models.SortEntitiesByDependencies(ctx.entities) // On cycles keep the fallback order
+	ctx.mu.RUnlock()
+
+	rank := make(map[reflect.Type]int, len(sortedEntities))
+	for i, entityModel := range sortedEntities {
+		rank[entityModel.Type] = i
+	}
+	rankOf := func(entry *EntityEntry) int {
+		entityType := reflect.TypeOf(entry.Entity)
+		if entityType.Kind() == reflect.Ptr {
+			entityType = entityType.Elem()
+		}
+		if r, exists := rank[entityType]; exists {
+			return r
+		}
+		return len(sortedEntities) // Unregistered entities have no known dependents
+	}
+
+	var upserts, deletes []*EntityEntry
+	for _, entry := range changes {
+		if entry.State == EntityDeleted {
+			deletes = append(deletes, entry)
+		} else {
+			upserts = append(upserts, entry)
+		}
+	}
+
+	// Stable sorts keep tracking order within the same entity type
+	sort.SliceStable(upserts, func(i, j int) bool {
+		return rankOf(upserts[i]) < rankOf(upserts[j])
+	})
+	sort.SliceStable(deletes, func(i, j int) bool {
+		return rankOf(deletes[i]) > rankOf(deletes[j])
 	})
+
+	return append(upserts, deletes...)
+}
+
+// generateValues fills zero-valued fields that are generated in Go (key generators, HiLo),
+// then applies Go-side defaults so they can use the generated keys
+func (ctx *DbContext) generateValues(tx *gorm.DB, entity interface{}) error {
+	entityValue := reflect.ValueOf(entity).Elem()
+	entityModel := ctx.GetEntityModel(entityValue.Type())
+	if entityModel == nil {
+		return nil
+	}
+
+	for _, field := range entityModel.Fields {
+		if field.ValueGeneration != models.ValueGeneratedByGenerator && field.ValueGeneration != models.ValueGeneratedByHiLo {
+			continue
+		}
+
+		fieldValue := field.ValueOf(entityValue)
+		if !fieldValue.IsValid() || !fieldValue.CanSet() || !fieldValue.IsZero() {
+			continue
+		}
+
+		var value interface{}
+		var err error
+		if field.ValueGeneration == models.ValueGeneratedByHiLo {
+			value, err = ctx.hiLoAllocator(field).Next(tx)
+		} else if field.KeyGenerator != nil {
+			value, err = field.KeyGenerator()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to generate value for %s.%s: %w", entityModel.Name, field.Name, err)
+		}
+		if value == nil {
+			continue
+		}
+
+		generated := reflect.ValueOf(value)
+		if !generated.Type().ConvertibleTo(fieldValue.Type()) {
+			return fmt.Errorf("generated value of type %s cannot be assigned to %s.%s (%s)",
+				generated.Type(), entityModel.Name, field.Name, fieldValue.Type())
+		}
+		fieldValue.Set(generated.Convert(fieldValue.Type()))
+	}
+
+	return ctx.applyDefaultFuncs(entityModel, entity)
+}
+
+// applyDefaultFuncs sets zero-valued fields configured with HasDefaultFunc
+func (ctx *DbContext) applyDefaultFuncs(entityModel *models.EntityModel, entity interface{}) error {
+	entityValue := reflect.ValueOf(entity).Elem()
+	for _, field := range entityModel.Fields {
+		if field.DefaultFunc == nil {
+			continue
+		}
+
+		fieldValue := field.ValueOf(entityValue)
+		if !fieldValue.IsValid() || !fieldValue.CanSet() || !fieldValue.IsZero() {
+			continue
+		}
+
+		value := field.DefaultFunc(entity)
+		if value == nil {
+			continue
+		}
+
+		defaultValue := reflect.ValueOf(value)
+		if !defaultValue.Type().ConvertibleTo(fieldValue.Type()) {
+			return fmt.Errorf("default value of type %s cannot be assigned to %s.%s (%s)",
+				defaultValue.Type(), entityModel.Name, field.Name, fieldValue.Type())
+		}
+		fieldValue.Set(defaultValue.Convert(fieldValue.Type()))
+	}
+
+	return nil
+}
+
+// hiLoAllocator returns the shared allocator for a field's HiLo sequence
+func (ctx *DbContext) hiLoAllocator(field models.FieldModel) *hiLoAllocator {
+	if ctx.parent != nil {
+		return ctx.parent.hiLoAllocator(field) // Scoped contexts draw from their root's blocks
+	}
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
+	allocator, exists := ctx.hiLo[field.HiLoSequence]
+	if !exists {
+		allocator = newHiLoAllocator(field.HiLoSequence, field.HiLoBlockSize)
+		ctx.hiLo[field.HiLoSequence] = allocator
+	}
+	return allocator
+}
+
+// insertEntity inserts an added entity and writes database-generated values
+// (keys, defaults, timestamps) back into the entity instance
+func (ctx *DbContext) insertEntity(tx *gorm.DB, entity interface{}) error {
+	if ctx.driver.SupportsReturning() {
+		// An empty RETURNING clause renders RETURNING * so GORM scans every column back
+		return tx.Clauses(clause.Returning{}).Create(entity).Error
+	}
+
+	if err := tx.Create(entity).Error; err != nil {
+		return err
+	}
+
+	// No RETURNING support - re-read the row by primary key to pick up defaults
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(entity); err != nil {
+		return err
+	}
+	pkField := stmt.Schema.PrioritizedPrimaryField
+	if pkField == nil {
+		return nil
+	}
+	if _, isZero := pkField.ValueOf(tx.Statement.Context, reflect.ValueOf(entity).Elem()); isZero {
+		return nil
+	}
+	return tx.Take(entity).Error
 }
 
-func (ctx *DbContext) BeginTransaction() *gorm.DB {
+// BeginTransaction starts a transaction, optionally at a specific isolation level
+func (ctx *DbContext) BeginTransaction(level ...IsolationLevel) *gorm.DB {
+	if len(level) > 0 {
+		return ctx.db.Begin(ctx.txOptions(level[0])...)
+	}
 	return ctx.db.Begin()
 }
 
@@ -140,7 +670,7 @@ func (ctx *DbContext) BeginTransaction() *gorm.DB {
 // DEPRECATED: Use LINQ methods instead of GetDB().Model() patterns
 // OLD DEPRECATED PATTERN: ctx.GetDB().Model(&Entity{}).Select("SUM(field)").Scan(&result)
 // NEW CORRECT PATTERN: result, err := ctx.EntitySet.SumField("field")
-// Only use GetDB() for operations not yet supported by LINQ methods
+// For raw SQL use Database().Exec and gontext.SqlQuery instead
 func (ctx *DbContext) GetDB() *gorm.DB {
 	return ctx.db
 }
@@ -149,18 +679,250 @@ func (ctx *DbContext) GetDriver() drivers.DatabaseDriver {
 	return ctx.driver
 }
 
+// ConstraintNaming returns the naming of generated indexes and constraints
+func (ctx *DbContext) ConstraintNaming() models.ConstraintNaming {
+	if ctx.constraintNaming != nil {
+		return ctx.constraintNaming
+	}
+	return models.DefaultConstraintNaming{}
+}
+
+// NamingConvention returns the table/column naming convention configured for the context
+func (ctx *DbContext) NamingConvention() models.NamingConvention {
+	return ctx.naming
+}
+
+// SetCaseInsensitiveStrings makes Like/StartsWith/EndsWith helpers case-insensitive for every query
+// (ILIKE on PostgreSQL, LOWER() on other databases)
+func (ctx *DbContext) SetCaseInsensitiveStrings(enabled bool) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
+	ctx.ignoreCase = enabled
+}
+
+// CaseInsensitiveStrings reports whether string matching is case-insensitive by default
+func (ctx *DbContext) CaseInsensitiveStrings() bool {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	return ctx.ignoreCase
+}
+
+// SetStringOperatorParsing controls whether WhereField/WhereEntity read string values like ">40" as comparisons
+// Enabled by default; disable it when string values may legitimately start with '>', '<', '=' or '!'
+// and use explicit values such as GreaterThan(40) instead
+func (ctx *DbContext) SetStringOperatorParsing(enabled bool) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
+	ctx.literalStrings = !enabled
+}
+
+// StringOperatorParsing reports whether string values are parsed for comparison operators
+func (ctx *DbContext) StringOperatorParsing() bool {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	return !ctx.literalStrings
+}
+
+// SetStrictSQL rejects raw Where/Or/Having conditions containing inline string or numeric literals,
+// statement separators or comments with ErrUnsafeSQL - a guard for security reviews and CI runs
+// Conditions gontext builds itself are always parameterized and pass
+func (ctx *DbContext) SetStrictSQL(enabled bool) {
+	ctx.strictSQL.SetEnabled(enabled)
+}
+
+// StrictSQL reports whether strict SQL mode is enabled
+func (ctx *DbContext) StrictSQL() bool {
+	return ctx.strictSQL.Enabled()
+}
+
+// PreparedStatementStats reports prepared statement cache hits, misses and evictions
+// All counters are zero unless DbContextOptions.PrepareStmt is set
+func (ctx *DbContext) PreparedStatementStats() query.StatementCacheStats {
+	if ctx.stmtCache == nil {
+		return query.StatementCacheStats{}
+	}
+	return ctx.stmtCache.Stats()
+}
+
+// ResetPreparedStatements closes every cached prepared statement, e.g. after a migration changed
+// the tables they read; they are prepared again on next use
+func (ctx *DbContext) ResetPreparedStatements() {
+	if ctx.stmtCache != nil {
+		ctx.stmtCache.Reset()
+	}
+}
+
+// QueryPlanCache returns the cache LINQ sets use to reuse translated conditions and field references
+func (ctx *DbContext) QueryPlanCache() *query.PlanCache {
+	return ctx.plans
+}
+
+// QueryPlanStats reports query plan cache hits, misses and size
+func (ctx *DbContext) QueryPlanStats() query.PlanCacheStats {
+	return ctx.plans.Stats()
+}
+
+// QueryCache returns the cache of Cached() queries, or nil when DbContextOptions.QueryCache is unset
+func (ctx *DbContext) QueryCache() *cache.Cache {
+	return ctx.queryCache
+}
+
+// QueryCacheStats reports query cache hits, misses, shared loads and provider errors
+func (ctx *DbContext) QueryCacheStats() cache.Stats {
+	if ctx.queryCache == nil {
+		return cache.Stats{}
+	}
+	return ctx.queryCache.Stats()
+}
+
+// InvalidateQueryCache drops the cached results that read any of tables, e.g. after raw SQL writes
+// gontext does not see; writes through gontext invalidate their table automatically
+func (ctx *DbContext) InvalidateQueryCache(tables ...string) error {
+	if ctx.queryCache == nil {
+		return nil
+	}
+	return ctx.queryCache.Invalidate(stdcontext.Background(), tables...)
+}
+
+// invalidateSaved drops the cached results of the tables written by saved entries
+func (ctx *DbContext) invalidateSaved(entries []*EntityEntry) {
+	if ctx.queryCache == nil {
+		return
+	}
+	seen := make(map[reflect.Type]bool)
+	var tables []string
+	for _, entry := range entries {
+		entityType := reflect.Indirect(reflect.ValueOf(entry.Entity)).Type()
+		if seen[entityType] {
+			continue
+		}
+		seen[entityType] = true
+		stmt := &gorm.Statement{DB: ctx.db}
+		if err := stmt.Parse(reflect.New(entityType).Interface()); err == nil {
+			tables = append(tables, stmt.Schema.Table)
+		}
+	}
+	ctx.queryCache.Invalidate(stdcontext.Background(), tables...)
+}
+
+// Locks returns the distributed locks of the context's database, for critical sections that must run
+// in one process at a time across every instance of the application
+func (ctx *DbContext) Locks() *locks.Manager {
+	return ctx.locks
+}
+
+// FutureBatch returns the future queries LINQ sets have deferred and not yet sent
+func (ctx *DbContext) FutureBatch() *query.FutureBatch {
+	return ctx.futures
+}
+
+// ExecuteFutures sends every pending future query now instead of when the first result is read
+func (ctx *DbContext) ExecuteFutures() {
+	ctx.futures.Flush()
+}
+
+// isSensitiveColumn reports whether a column of any registered entity is marked sensitive
+// Names are compared without case and underscores, so PasswordHash matches password_hash
+func (ctx *DbContext) isSensitiveColumn(column string) bool {
+	normalize := func(name string) string {
+		return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+	}
+	column = normalize(column)
+
+	// Logging happens while mu is held (e.g. EnsureCreated), so read the registered list instead
+	registered := ctx.registered.Load()
+	if registered == nil {
+		return false
+	}
+	for _, entityModel := range *registered {
+		for _, field := range entityModel.Fields {
+			if field.IsSensitive && (normalize(field.ColumnName) == column || normalize(field.Name) == column) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// EncryptedColumns returns the columns of an entity type configured with IsEncrypted, by field name
+func (ctx *DbContext) EncryptedColumns(entityType reflect.Type) map[string]*encryption.Column {
+	entityModel := ctx.GetEntityModel(entityType)
+	if entityModel == nil {
+		return nil
+	}
+
+	var columns map[string]*encryption.Column
+	for name, field := range entityModel.Fields {
+		if field.Encryption == nil {
+			continue
+		}
+		if columns == nil {
+			columns = make(map[string]*encryption.Column)
+		}
+		columns[name] = field.Encryption
+	}
+	return columns
+}
+
+// SetTimeZone sets the time zone used by date helpers such as WhereDateBetween and WhereInLastDays
+// Defaults to the local time zone
+func (ctx *DbContext) SetTimeZone(location *time.Location) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
+	ctx.location = location
+}
+
+// TimeZone returns the configured time zone for date helpers, or nil when unset
+func (ctx *DbContext) TimeZone() *time.Location {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	return ctx.location
+}
+
+// GetEntityModel returns the registered model for an entity type, or nil if it is not registered
+func (ctx *DbContext) GetEntityModel(entityType reflect.Type) *models.EntityModel {
+	if entityType.Kind() == reflect.Ptr {
+		entityType = entityType.Elem()
+	}
+
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	return ctx.entities[typeKey(entityType)]
+}
+
+// GetEntityModels returns the models of the entities stored in the context's database, which its
+// migrations create; entities routed to another database are listed by that database's context
 func (ctx *DbContext) GetEntityModels() map[string]*models.EntityModel {
 	ctx.mu.RLock()
 	defer ctx.mu.RUnlock()
 
 	result := make(map[string]*models.EntityModel)
 	for key, entityModel := range ctx.entities {
+		if _, routed := ctx.routes[key]; routed {
+			continue
+		}
 		result[key] = entityModel
 	}
 	return result
 }
 
 func (ctx *DbContext) Close() error {
+	ctx.detachTransaction()
+	if ctx.parent != nil {
+		return nil // The pool belongs to the root context
+	}
+	for _, name := range ctx.databaseNames() {
+		if err := ctx.DatabaseContext(name).Close(); err != nil {
+			return fmt.Errorf("failed to close database %s: %w", name, err)
+		}
+	}
 	sqlDB, err := ctx.driver.GetSQLDB(ctx.db)
 	if err != nil {
 		return err
@@ -169,14 +931,81 @@ func (ctx *DbContext) Close() error {
 }
 
 func (ctx *DbContext) EnsureCreated() error {
+	// Added databases create the entities routed to them
+	for _, name := range ctx.databaseNames() {
+		if err := ctx.DatabaseContext(name).EnsureCreated(); err != nil {
+			return err
+		}
+	}
+
 	ctx.mu.RLock()
 	defer ctx.mu.RUnlock()
 
-	for _, entity := range ctx.entities {
+	for key, entity := range ctx.entities {
+		if _, routed := ctx.routes[key]; routed {
+			continue
+		}
+		if entity.View != nil {
+			continue // Created after the tables they read, below
+		}
+		if entity.Partition != nil {
+			log.Printf("Warning: %s is created unpartitioned; partitioned tables are created by migrations", entity.Name)
+		}
 		if err := ctx.db.AutoMigrate(reflect.New(entity.Type).Interface()); err != nil {
 			log.Printf("Warning: AutoMigrate failed for %s: %v", entity.Name, err)
+			continue
+		}
+		// AutoMigrate knows nothing about builder-declared indexes
+		for _, declared := range entity.Indexes {
+			index := declared.Definition(entity)
+			if ctx.driver.Name() == "mysql" {
+				log.Printf("Warning: index %s is not created on mysql; add it with a migration", index.Name)
+				continue
+			}
+			if err := ctx.db.Exec(models.CreateIndexSQL(entity.TableName, index)).Error; err != nil {
+				log.Printf("Warning: failed to create index %s: %v", index.Name, err)
+			}
+		}
+		if ctx.driver.Name() != "postgres" {
+			continue
+		}
+		// nor about full-text indexes
+		for _, field := range entity.Fields {
+			if field.TextSearchConfig == "" {
+				continue
+			}
+			index := models.TextSearchIndex(entity.Naming(), entity.TableName, field.ColumnName, field.TextSearchConfig)
+			if err := ctx.db.Exec(models.CreateIndexSQL(entity.TableName, index)).Error; err != nil {
+				log.Printf("Warning: failed to create index %s: %v", index.Name, err)
+			}
+		}
+		// nor about trigram indexes
+		for _, field := range entity.Fields {
+			if !field.Trigram {
+				continue
+			}
+			index := models.TrigramIndex(entity.Naming(), entity.TableName, field.ColumnName)
+			if err := ctx.db.Exec("CREATE EXTENSION IF NOT EXISTS " + models.TrigramExtension).Error; err != nil {
+				log.Printf("Warning: failed to create index %s: %v", index.Name, err)
+				continue
+			}
+			if err := ctx.db.Exec(models.CreateIndexSQL(entity.TableName, index)).Error; err != nil {
+				log.Printf("Warning: failed to create index %s: %v", index.Name, err)
+			}
+		}
+		// nor about triggers, which are replaced so a changed body takes effect
+		for _, trigger := range entity.Triggers {
+			for _, statement := range append(models.DropTriggerSQL(entity.TableName, *trigger), models.CreateTriggerSQL(entity.TableName, *trigger)...) {
+				if err := ctx.db.Exec(statement).Error; err != nil {
+					log.Printf("Warning: failed to create trigger %s: %v", trigger.Name, err)
+					break
+				}
+			}
 		}
 	}
+	ctx.ensureViews()
+	// Plans prepared against the old table shapes would fail with "cached plan must not change result type"
+	ctx.ResetPreparedStatements()
 	return nil
 }
 
@@ -195,7 +1024,11 @@ func (ctx *DbContext) RemoveEntity(entity interface{}) {
 	ctx.changeTracker.Add(entity, EntityDeleted)
 }
 
-// TrackLoaded tracks an entity that was loaded from the database
+// TrackLoaded runs the entity's AfterLoad hook and tracks an entity that was loaded from the database
 func (ctx *DbContext) TrackLoaded(entity interface{}) {
+	ctx.afterLoad(entity)
+	if ctx.isView(reflect.TypeOf(entity)) || ctx.isQueryType(reflect.TypeOf(entity)) {
+		return // Keyless rows of views and query types are never tracked
+	}
 	ctx.changeTracker.TrackLoaded(entity)
 }
\ No newline at end of file
@@ -24,6 +24,11 @@ func NewDbSet(ctx *DbContext, entityType reflect.Type, entityModel *models.Entit
 	}
 }
 
+// db returns the connection of the database storing the entity type
+func (ds *DbSet) db() *gorm.DB {
+	return ds.context.DBFor(ds.entityType)
+}
+
 func (ds *DbSet) Add(entity interface{}) {
 	ds.context.changeTracker.Add(entity, EntityAdded)
 }
@@ -43,35 +48,35 @@ func (ds *DbSet) Remove(entity interface{}) {
 }
 
 func (ds *DbSet) Find(dest interface{}, conditions ...interface{}) error {
-	return ds.context.db.Find(dest, conditions...).Error
+	return ds.db().Find(dest, conditions...).Error
 }
 
 func (ds *DbSet) FirstEntity(dest interface{}, conditions ...interface{}) error {
-	return ds.context.db.First(dest, conditions...).Error
+	return ds.db().First(dest, conditions...).Error
 }
 
 func (ds *DbSet) Where(query interface{}, args ...interface{}) *gorm.DB {
-	return ds.context.db.Model(reflect.New(ds.entityType).Interface()).Where(query, args...)
+	return ds.db().Model(reflect.New(ds.entityType).Interface()).Where(query, args...)
 }
 
 func (ds *DbSet) Create(value interface{}) error {
-	return ds.context.db.Create(value).Error
+	return ds.db().Create(value).Error
 }
 
 func (ds *DbSet) Delete(value interface{}, conditions ...interface{}) error {
-	return ds.context.db.Delete(value, conditions...).Error
+	return ds.db().Delete(value, conditions...).Error
 }
 
 func (ds *DbSet) Count(count *int64) error {
-	return ds.context.db.Model(reflect.New(ds.entityType).Interface()).Count(count).Error
+	return ds.db().Model(reflect.New(ds.entityType).Interface()).Count(count).Error
 }
 
 func (ds *DbSet) Preload(column string, conditions ...interface{}) *gorm.DB {
-	return ds.context.db.Model(reflect.New(ds.entityType).Interface()).Preload(column, conditions...)
+	return ds.db().Model(reflect.New(ds.entityType).Interface()).Preload(column, conditions...)
 }
 
 func (ds *DbSet) Raw(sql string, values ...interface{}) *gorm.DB {
-	return ds.context.db.Raw(sql, values...)
+	return ds.db().Raw(sql, values...)
 }
 
 func (ds *DbSet) GetEntityType() reflect.Type {
@@ -86,7 +91,7 @@ func (ds *DbSet) GetEntityModel() *models.EntityModel {
 func (ds *DbSet) LINQ() interface{} {
 	// This needs to be called with proper type information
 	// The actual implementation will be in the public API
-	return linq.NewLinqQuery[interface{}](ds.context.db)
+	return linq.NewLinqQuery[interface{}](ds.db())
 }
 
 // FirstOrDefault - EF Core style method with predicate support
@@ -94,7 +99,7 @@ func (ds *DbSet) FirstOrDefault(conditions ...interface{}) (interface{}, error)
 	log.Printf("[GONTEXT DEBUG] DbSet.FirstOrDefault called for entity type: %s", ds.entityType.Name())
 	
 	var result interface{}
-	query := ds.context.db.Model(reflect.New(ds.entityType).Interface())
+	query := ds.db().Model(reflect.New(ds.entityType).Interface())
 	
 	if len(conditions) > 0 {
 		log.Printf("[GONTEXT DEBUG] Adding conditions: %+v", conditions)
@@ -130,7 +135,7 @@ func (ds *DbSet) FirstOrDefault(conditions ...interface{}) (interface{}, error)
 	log.Printf("[GONTEXT DEBUG] Record found: %+v", result)
 	
 	// Automatically track the loaded entity for change detection
-	ds.context.changeTracker.TrackLoaded(result)
+	ds.context.TrackLoaded(result)
 	
 	return result, nil
 }
@@ -138,7 +143,7 @@ func (ds *DbSet) FirstOrDefault(conditions ...interface{}) (interface{}, error)
 // First - EF Core style method
 func (ds *DbSet) First(conditions ...interface{}) (interface{}, error) {
 	var result interface{}
-	query := ds.context.db.Model(reflect.New(ds.entityType).Interface())
+	query := ds.db().Model(reflect.New(ds.entityType).Interface())
 	
 	if len(conditions) > 0 {
 		query = query.Where(conditions[0], conditions[1:]...)
@@ -147,14 +152,14 @@ func (ds *DbSet) First(conditions ...interface{}) (interface{}, error) {
 	err := query.First(&result).Error
 	if err == nil {
 		// Automatically track the loaded entity for change detection
-		ds.context.changeTracker.TrackLoaded(result)
+		ds.context.TrackLoaded(result)
 	}
 	return result, err
 }
 
 // Single - EF Core style method
 func (ds *DbSet) Single(conditions ...interface{}) (interface{}, error) {
-	query := ds.context.db.Model(reflect.New(ds.entityType).Interface())
+	query := ds.db().Model(reflect.New(ds.entityType).Interface())
 	
 	if len(conditions) > 0 {
 		query = query.Where(conditions[0], conditions[1:]...)
@@ -175,14 +180,14 @@ func (ds *DbSet) Single(conditions ...interface{}) (interface{}, error) {
 	
 	result := results[0]
 	// Automatically track the loaded entity for change detection
-	ds.context.changeTracker.TrackLoaded(result)
+	ds.context.TrackLoaded(result)
 	
 	return result, nil
 }
 
 // Any - EF Core style method
 func (ds *DbSet) Any(conditions ...interface{}) (bool, error) {
-	query := ds.context.db.Model(reflect.New(ds.entityType).Interface())
+	query := ds.db().Model(reflect.New(ds.entityType).Interface())
 	
 	if len(conditions) > 0 {
 		query = query.Where(conditions[0], conditions[1:]...)
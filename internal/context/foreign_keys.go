@@ -0,0 +1,241 @@
+package context
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"github.com/shepherrrd/gontext/internal/models"
+)
+
+// ForeignKeys resolves the foreign keys between registered entities: those declared with HasForeignKey,
+// then those of navigations as GORM parses them (belongs-to navigations on the dependent, has-one/has-many
+// on the principal). Fields, tables and columns come from the entity models, so naming conventions and
+// column tags are honoured. A navigation's constraint tag sets its delete behavior:
+// gorm:"foreignKey:AuthorID;constraint:OnDelete:SET NULL"
+func (ctx *DbContext) ForeignKeys() []models.ForeignKeyModel {
+	entityModels := ctx.GetEntityModels()
+	sorted := make([]*models.EntityModel, 0, len(entityModels))
+	byName := make(map[string]*models.EntityModel, len(entityModels))
+	byType := make(map[reflect.Type]*models.EntityModel, len(entityModels))
+	for _, entity := range entityModels {
+		sorted = append(sorted, entity)
+		byName[entity.Name] = entity
+		byType[entity.Type] = entity
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	var foreignKeys []models.ForeignKeyModel
+	declared := make(map[string]bool)   // Dependent name + "." + field name
+	navigations := make(map[string]int) // Same key -> index of the navigation's foreign key
+	for _, dependent := range sorted {
+		for _, relationship := range dependent.Relationships {
+			field, exists := dependent.Fields[relationship.ForeignKey]
+			principal := byName[relationship.Principal]
+			if !exists || principal == nil {
+				continue
+			}
+			foreignKey := models.ForeignKeyModel{
+				Dependent:      dependent,
+				Field:          field,
+				Principal:      principal,
+				ReferencesKey:  relationship.PrincipalKey == "",
+				ConstraintName: relationship.ConstraintName,
+				OnDelete:       relationship.OnDelete,
+				OnUpdate:       relationship.OnUpdate,
+			}
+			if relationship.PrincipalKey != "" {
+				if foreignKey.PrincipalField, exists = principal.Fields[relationship.PrincipalKey]; !exists {
+					continue
+				}
+			} else if foreignKey.PrincipalField, exists = keyField(principal); !exists {
+				continue
+			}
+			foreignKeys = append(foreignKeys, foreignKey)
+			declared[dependent.Name+"."+field.Name] = true
+		}
+	}
+
+	for _, owner := range sorted {
+		stmt := &gorm.Statement{DB: ctx.db}
+		if err := stmt.Parse(reflect.New(owner.Type).Interface()); err != nil {
+			continue
+		}
+		names := make([]string, 0, len(stmt.Schema.Relationships.Relations))
+		for name := range stmt.Schema.Relationships.Relations {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			relationship := stmt.Schema.Relationships.Relations[name]
+			if relationship.Type == schema.Many2Many || relationship.Polymorphic != nil {
+				continue
+			}
+			var onDelete, onUpdate models.DeleteBehavior
+			if constraint := relationship.ParseConstraint(); constraint != nil {
+				onDelete = models.ParseDeleteBehavior(constraint.OnDelete)
+				onUpdate = models.ParseDeleteBehavior(constraint.OnUpdate)
+			}
+			for _, reference := range relationship.References {
+				if reference.PrimaryKey == nil {
+					continue
+				}
+				dependent := byType[reference.ForeignKey.Schema.ModelType]
+				principal := byType[reference.PrimaryKey.Schema.ModelType]
+				if dependent == nil || principal == nil {
+					continue
+				}
+				field, found := modelField(dependent, reference.ForeignKey)
+				if !found || declared[dependent.Name+"."+field.Name] {
+					continue
+				}
+				principalField, found := modelField(principal, reference.PrimaryKey)
+				if !found {
+					continue
+				}
+				// A belongs-to and the principal's has-one/has-many describe the same key; GORM reads the
+				// constraint tag from whichever declares it
+				if i, seen := navigations[dependent.Name+"."+field.Name]; seen {
+					if foreignKeys[i].OnDelete == models.DeleteDefault {
+						foreignKeys[i].OnDelete = onDelete
+					}
+					if foreignKeys[i].OnUpdate == models.DeleteDefault {
+						foreignKeys[i].OnUpdate = onUpdate
+					}
+					continue
+				}
+				navigations[dependent.Name+"."+field.Name] = len(foreignKeys)
+				foreignKeys = append(foreignKeys, models.ForeignKeyModel{
+					Dependent:      dependent,
+					Field:          field,
+					Principal:      principal,
+					PrincipalField: principalField,
+					ReferencesKey:  reference.PrimaryKey.PrimaryKey || principalField.IsPrimary,
+					OnDelete:       onDelete,
+					OnUpdate:       onUpdate,
+				})
+			}
+		}
+	}
+	return foreignKeys
+}
+
+// keyField returns the entity's single key field, falling back to its Id/ID field
+func keyField(entity *models.EntityModel) (models.FieldModel, bool) {
+	if len(entity.PrimaryKey) > 0 {
+		for _, field := range entity.Fields {
+			if field.ColumnName == entity.PrimaryKey[0] {
+				return field, true
+			}
+		}
+	}
+	for _, name := range []string{"Id", "ID"} {
+		if field, exists := entity.Fields[name]; exists {
+			return field, true
+		}
+	}
+	return models.FieldModel{}, false
+}
+
+// modelField finds the entity model field of a GORM field, by name or, for fields keyed by their embedded
+// path, by column
+func modelField(entity *models.EntityModel, field *schema.Field) (models.FieldModel, bool) {
+	if modelField, exists := entity.Fields[field.Name]; exists {
+		return modelField, true
+	}
+	for _, modelField := range entity.Fields {
+		if modelField.ColumnName == field.DBName {
+			return modelField, true
+		}
+	}
+	return models.FieldModel{}, false
+}
+
+// applyDeleteBehaviors applies the delete behaviors of foreign keys to tracked dependents of deleted
+// entities, like EF Core does before saving: Cascade deletes them, SetNull clears their foreign key and
+// Restrict fails the save. Dependents the context doesn't track are left to the database
+func (ctx *DbContext) applyDeleteBehaviors() error {
+	entries := ctx.changeTracker.Entries()
+	var deleted []*EntityEntry
+	for _, entry := range entries {
+		if entry.State == EntityDeleted {
+			deleted = append(deleted, entry)
+		}
+	}
+	if len(deleted) == 0 {
+		return nil
+	}
+
+	foreignKeys := ctx.ForeignKeys()
+	for len(deleted) > 0 {
+		principalEntry := deleted[0]
+		deleted = deleted[1:]
+		principalValue := reflect.Indirect(reflect.ValueOf(principalEntry.Entity))
+
+		for _, foreignKey := range foreignKeys {
+			if foreignKey.Principal.Type != principalValue.Type() || foreignKey.OnDelete == models.DeleteDefault ||
+				foreignKey.OnDelete == models.DeleteNoAction {
+				continue
+			}
+			key := indirectValue(foreignKey.PrincipalField.ValueOf(principalValue))
+			if !key.IsValid() {
+				continue
+			}
+
+			for _, entry := range entries {
+				if entry.State == EntityDeleted || entry == principalEntry {
+					continue
+				}
+				dependentValue := reflect.Indirect(reflect.ValueOf(entry.Entity))
+				if dependentValue.Type() != foreignKey.Dependent.Type {
+					continue
+				}
+				reference := indirectValue(foreignKey.Field.ValueOf(dependentValue))
+				if !reference.IsValid() || fmt.Sprint(reference.Interface()) != fmt.Sprint(key.Interface()) {
+					continue
+				}
+
+				switch foreignKey.OnDelete {
+				case models.DeleteCascade:
+					entry.State = EntityDeleted
+					deleted = append(deleted, entry)
+				case models.DeleteSetNull:
+					if reflect.ValueOf(entry.Entity).Kind() != reflect.Ptr {
+						entry.Entity = entityPointer(entry.Entity)
+						dependentValue = reflect.ValueOf(entry.Entity).Elem()
+					}
+					field := foreignKey.Field.ValueOf(dependentValue)
+					if !field.CanSet() {
+						continue
+					}
+					field.Set(reflect.Zero(field.Type()))
+					if entry.State == EntityUnchanged || len(entry.ModifiedProperties) > 0 {
+						entry.ModifiedProperties = append(entry.ModifiedProperties, foreignKey.Field.Name)
+					}
+					if entry.State == EntityUnchanged {
+						entry.State = EntityModified
+					}
+				case models.DeleteRestrict:
+					return fmt.Errorf("cannot delete %s: tracked %s references it through %s, whose delete behavior is Restrict",
+						foreignKey.Principal.Name, foreignKey.Dependent.Name, foreignKey.Field.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// indirectValue dereferences pointers, returning the zero Value for nil
+func indirectValue(value reflect.Value) reflect.Value {
+	for value.IsValid() && value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		value = value.Elem()
+	}
+	return value
+}
@@ -0,0 +1,46 @@
+package context
+
+import (
+	"fmt"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// hiLoAllocator hands out key values from blocks reserved with a single sequence round trip
+// Each nextval() call reserves the range [hi*blockSize, hi*blockSize+blockSize)
+type hiLoAllocator struct {
+	mu        sync.Mutex
+	sequence  string
+	blockSize int64
+	next      int64
+	max       int64
+}
+
+func newHiLoAllocator(sequence string, blockSize int) *hiLoAllocator {
+	return &hiLoAllocator{
+		sequence:  sequence,
+		blockSize: int64(blockSize),
+	}
+}
+
+// Next returns the next value, reserving a new block from the sequence when the current one is used up
+func (a *hiLoAllocator) Next(db *gorm.DB) (int64, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.next >= a.max {
+		var hi int64
+		// Quote the sequence name so case-sensitive names resolve like table names
+		err := db.Raw("SELECT nextval(?)", fmt.Sprintf(`"%s"`, a.sequence)).Scan(&hi).Error
+		if err != nil {
+			return 0, fmt.Errorf("failed to reserve HiLo block from sequence %s: %w", a.sequence, err)
+		}
+		a.next = hi * a.blockSize
+		a.max = a.next + a.blockSize
+	}
+
+	value := a.next
+	a.next++
+	return value, nil
+}
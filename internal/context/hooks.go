@@ -0,0 +1,59 @@
+package context
+
+// BeforeCreateHook is implemented by entities that prepare themselves before being inserted
+// SaveChanges calls BeforeCreate for every added entity before validation; an error aborts the save
+type BeforeCreateHook interface {
+	BeforeCreate(ctx *DbContext) error
+}
+
+// BeforeUpdateHook is implemented by entities that prepare themselves before being updated
+// e.g. keeping a denormalized column in sync with the fields it is derived from
+type BeforeUpdateHook interface {
+	BeforeUpdate(ctx *DbContext) error
+}
+
+// BeforeDeleteHook is implemented by entities that check invariants before being deleted
+type BeforeDeleteHook interface {
+	BeforeDelete(ctx *DbContext) error
+}
+
+// AfterLoadHook is implemented by entities that initialize computed state once loaded from the database
+// It runs before the entity is tracked, so fields set here are not saved as modifications
+type AfterLoadHook interface {
+	AfterLoad(ctx *DbContext)
+}
+
+// runSaveHooks calls the Before* hooks of every pending entity in tracking order
+func (ctx *DbContext) runSaveHooks(changes []*EntityEntry) error {
+	for _, entry := range changes {
+		entity := entityPointer(entry.Entity)
+
+		var err error
+		switch entry.State {
+		case EntityAdded:
+			if hook, ok := entity.(BeforeCreateHook); ok {
+				err = hook.BeforeCreate(ctx)
+			}
+		case EntityModified:
+			if hook, ok := entity.(BeforeUpdateHook); ok {
+				err = hook.BeforeUpdate(ctx)
+			}
+		case EntityDeleted:
+			if hook, ok := entity.(BeforeDeleteHook); ok {
+				err = hook.BeforeDelete(ctx)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		entry.Entity = entity
+	}
+	return nil
+}
+
+// afterLoad calls the AfterLoad hook of an entity materialized by a query
+func (ctx *DbContext) afterLoad(entity interface{}) {
+	if hook, ok := entity.(AfterLoadHook); ok {
+		hook.AfterLoad(ctx)
+	}
+}
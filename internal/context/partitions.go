@@ -0,0 +1,108 @@
+package context
+
+import (
+	stdcontext "context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shepherrrd/gontext/internal/dberrors"
+	"github.com/shepherrrd/gontext/internal/models"
+)
+
+// Partitions keeps the range partitions of partitioned tables (PartitionByRange) in step with time:
+// partitions for the coming intervals are created ahead, and those past the retention are detached
+// Hash partitions are all created by migrations and need no maintenance
+type Partitions struct {
+	ctx *DbContext
+}
+
+// PartitionMaintenance reports what a maintenance run changed, by partition table name
+type PartitionMaintenance struct {
+	Created  []string
+	Detached []string // Left in the database as standalone tables, to archive or drop
+}
+
+// Partitions returns the partition maintenance of the context's partitioned tables
+//
+//	result, err := ctx.Partitions().Maintain(c)
+func (ctx *DbContext) Partitions() *Partitions {
+	return &Partitions{ctx: ctx}
+}
+
+// Maintain creates the range partitions from the current interval to Premake intervals ahead and
+// detaches those more than Retention intervals old, for every range-partitioned entity. Run it at least
+// once per interval, e.g. with gontext.SchedulePartitionMaintenance, so rows never fall into the DEFAULT
+// partition: a partition cannot be created over rows the DEFAULT partition already holds
+// Requires PostgreSQL and tables created by migrations
+func (p *Partitions) Maintain(c stdcontext.Context) (PartitionMaintenance, error) {
+	var result PartitionMaintenance
+	if p.ctx.driver.Name() != "postgres" {
+		return result, fmt.Errorf("table partitioning requires PostgreSQL, not %s", p.ctx.driver.Name())
+	}
+
+	entities := p.ctx.GetEntityModels()
+	names := make([]string, 0, len(entities))
+	for name, entity := range entities {
+		if entity.Partition != nil && entity.Partition.Strategy == models.PartitionByRange {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	now := time.Now()
+	for _, name := range names {
+		if err := p.maintain(c, entities[name], now, &result); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// maintain creates and detaches the range partitions of one entity's table
+func (p *Partitions) maintain(c stdcontext.Context, entity *models.EntityModel, now time.Time, result *PartitionMaintenance) error {
+	partition, table := entity.Partition, entity.TableName
+	db := p.ctx.db.WithContext(c)
+
+	var attached []string
+	err := db.Raw(`SELECT child.relname FROM pg_inherits
+		JOIN pg_class parent ON pg_inherits.inhparent = parent.oid
+		JOIN pg_class child ON pg_inherits.inhrelid = child.oid
+		WHERE parent.relname = ?`, table).Scan(&attached).Error
+	if err != nil {
+		return dberrors.Translate(err)
+	}
+	existing := make(map[string]bool, len(attached))
+	for _, name := range attached {
+		existing[name] = true
+	}
+
+	current := partition.PartitionStart(now)
+	for start, i := current, 0; i <= partition.Premake; start, i = partition.NextPartitionStart(start), i+1 {
+		name := partition.RangePartitionName(table, start)
+		if existing[name] {
+			continue
+		}
+		if err := db.Exec(models.CreatePartitionSQL(table, name, partition.RangePartitionBound(start))).Error; err != nil {
+			return fmt.Errorf("failed to create partition %s: %w", name, dberrors.Translate(err))
+		}
+		result.Created = append(result.Created, name)
+	}
+
+	if partition.Retention <= 0 {
+		return nil
+	}
+	cutoff := partition.ShiftPartitionStart(current, -partition.Retention)
+	sort.Strings(attached)
+	for _, name := range attached {
+		start, ok := partition.ParseRangePartitionName(table, name)
+		if !ok || !start.Before(cutoff) {
+			continue
+		}
+		if err := db.Exec(fmt.Sprintf(`ALTER TABLE "%s" DETACH PARTITION "%s"`, table, name)).Error; err != nil {
+			return fmt.Errorf("failed to detach partition %s: %w", name, dberrors.Translate(err))
+		}
+		result.Detached = append(result.Detached, name)
+	}
+	return nil
+}
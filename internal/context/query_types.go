@@ -0,0 +1,41 @@
+package context
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// RegisterQueryType maps entityType to the rows of a SELECT instead of a table - EF Core's keyless
+// entity types mapped with ToSqlQuery. The SQL takes named @parameters bound per query; rows of the
+// type are never tracked and SaveChanges rejects them. Registering a type again replaces its SQL
+func (ctx *DbContext) RegisterQueryType(entityType reflect.Type, sql string) {
+	if entityType.Kind() == reflect.Ptr {
+		entityType = entityType.Elem()
+	}
+	key := typeKey(entityType)
+	ctx.mu.RLock()
+	_, isEntity := ctx.entities[key]
+	ctx.mu.RUnlock()
+	if isEntity {
+		panic(fmt.Sprintf("%s is registered as an entity and cannot also be a query type", entityType.Name()))
+	}
+	ctx.queryTypes.Store(key, sql)
+}
+
+// QueryTypeSQL returns the SQL entityType was registered with by RegisterQueryType
+func (ctx *DbContext) QueryTypeSQL(entityType reflect.Type) (string, bool) {
+	if entityType.Kind() == reflect.Ptr {
+		entityType = entityType.Elem()
+	}
+	sql, ok := ctx.queryTypes.Load(typeKey(entityType))
+	if !ok {
+		return "", false
+	}
+	return sql.(string), true
+}
+
+// isQueryType reports whether entityType was registered with RegisterQueryType; it does not take mu
+func (ctx *DbContext) isQueryType(entityType reflect.Type) bool {
+	_, ok := ctx.QueryTypeSQL(entityType)
+	return ok
+}
@@ -0,0 +1,78 @@
+package context
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/shepherrrd/gontext/internal/query"
+)
+
+// NewScopedContext returns a context for one unit of work, such as an HTTP request, that shares this
+// context's connection pool, model, configuration and caches but tracks entities on its own - EF Core's
+// scoped DbContext. Scoped contexts are cheap to create and independent of each other, so each request
+// can query, change and SaveChanges without seeing the tracked entities of concurrent ones
+// Entities, sequences and scopes registered later on this context are not seen by existing scopes;
+// closing a scoped context leaves the shared pool open
+func (ctx *DbContext) NewScopedContext() *DbContext {
+	root := ctx
+	if ctx.parent != nil {
+		root = ctx.parent
+	}
+
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	db := ctx.db
+	if ctx.ownDB != nil {
+		db = ctx.ownDB // Scopes never join the transaction this context is enlisted in
+	}
+	scoped := &DbContext{
+		db:               db,
+		driver:           ctx.driver,
+		parent:           root,
+		entities:         maps.Clone(ctx.entities),
+		entityTypes:      maps.Clone(ctx.entityTypes),
+		dbSets:           make(map[string]interface{}, len(ctx.dbSets)),
+		changeTracker:    NewChangeTracker(),
+		pgPlugin:         ctx.pgPlugin,
+		validator:        ctx.validator,
+		ignoreCase:       ctx.ignoreCase,
+		literalStrings:   ctx.literalStrings,
+		location:         ctx.location,
+		timestampType:    ctx.timestampType,
+		maxBatchSize:     ctx.maxBatchSize,
+		naming:           ctx.naming,
+		tableNaming:      ctx.tableNaming,
+		constraintNaming: ctx.constraintNaming,
+		strictSQL:        ctx.strictSQL,
+		stmtCache:        ctx.stmtCache,
+		plans:            ctx.plans,
+		futures:          query.NewFutureBatch(),
+		searchEngine:     ctx.searchEngine,
+		searchFromFeed:   ctx.searchFromFeed,
+		queryCache:       ctx.queryCache,
+		locks:            ctx.locks,
+		sequenceModels:   maps.Clone(ctx.sequenceModels),
+		sequences:        ctx.sequences,
+		defaultScopes:    make(map[string][]interface{}, len(ctx.defaultScopes)),
+		strictQueries:    ctx.strictQueries,
+		databases:        maps.Clone(ctx.databases),
+		routes:           maps.Clone(ctx.routes),
+		shards:           maps.Clone(ctx.shards),
+	}
+	scoped.changeTracker.tableOf = scoped.registeredTable
+	scoped.registered.Store(ctx.registered.Load())
+	for key, set := range ctx.dbSets {
+		if dbSet, ok := set.(*DbSet); ok {
+			scoped.dbSets[key] = NewDbSet(scoped, dbSet.entityType, dbSet.entityModel)
+		}
+	}
+	for key, scopes := range ctx.defaultScopes {
+		scoped.defaultScopes[key] = slices.Clone(scopes)
+	}
+	ctx.queryTypes.Range(func(key, sql interface{}) bool {
+		scoped.queryTypes.Store(key, sql)
+		return true
+	})
+	return scoped
+}
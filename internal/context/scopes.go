@@ -0,0 +1,26 @@
+package context
+
+import "reflect"
+
+// AddDefaultScope registers a scope applied to every LINQ set of entityType created afterwards, so
+// filters such as soft deletion or tenancy are written once; scope is a linq.QueryScope of that type
+// Register default scopes before the sets are created (before RegisterEntity for the type)
+func (ctx *DbContext) AddDefaultScope(entityType reflect.Type, scope interface{}) {
+	if entityType.Kind() == reflect.Ptr {
+		entityType = entityType.Elem()
+	}
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	if ctx.defaultScopes == nil {
+		ctx.defaultScopes = make(map[string][]interface{})
+	}
+	key := typeKey(entityType)
+	ctx.defaultScopes[key] = append(ctx.defaultScopes[key], scope)
+}
+
+// DefaultScopes returns the default scopes of entityType in registration order
+func (ctx *DbContext) DefaultScopes(entityType reflect.Type) []interface{} {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+	return append([]interface{}(nil), ctx.defaultScopes[typeKey(entityType)]...)
+}
@@ -0,0 +1,208 @@
+package context
+
+import (
+	stdcontext "context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"github.com/shepherrrd/gontext/internal/changefeed"
+	"github.com/shepherrrd/gontext/internal/models"
+	"github.com/shepherrrd/gontext/internal/search"
+)
+
+// searchIndex is the resolved search mapping of a Searchable entity
+type searchIndex struct {
+	model   *models.EntityModel
+	schema  *schema.Schema
+	key     *schema.Field
+	columns map[string]*schema.Field // Indexed column -> field
+}
+
+// SetSearchEngine indexes Searchable entities in engine after every successful SaveChanges
+// The changes are committed before they are indexed, so an indexing error wraps search.ErrSyncFailed
+// and the data stays saved; BackfillSearch repairs the index
+func (ctx *DbContext) SetSearchEngine(engine search.Engine) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
+	ctx.searchEngine = engine
+}
+
+// IndexChangeFeed indexes Searchable entities from a change feed instead of after SaveChanges, so writes
+// made outside this context are indexed too and SaveChanges no longer waits for the search engine
+// Call it after the entities are configured Searchable and before the feed runs
+func (ctx *DbContext) IndexChangeFeed(feed *changefeed.Feed) error {
+	engine := ctx.currentSearchEngine()
+	if engine == nil {
+		return errors.New("IndexChangeFeed requires a search engine; call SetSearchEngine first")
+	}
+
+	for _, entityModel := range ctx.GetEntityModels() {
+		if entityModel.Search == nil {
+			continue
+		}
+		index, err := ctx.searchIndex(entityModel)
+		if err != nil {
+			return err
+		}
+		feed.Subscribe(index.schema.Table, func(c stdcontext.Context, change changefeed.Change) error {
+			id := search.DocumentID(change.Columns[index.key.DBName])
+			if change.Action == changefeed.Deleted {
+				return engine.Delete(c, index.model.Search.Index, []string{id})
+			}
+
+			document := search.Document{ID: id, Fields: make(map[string]interface{}, len(index.columns))}
+			for column := range index.columns {
+				if value, exists := change.Columns[column]; exists {
+					document.Fields[column] = value
+				}
+			}
+			return engine.Upsert(c, index.model.Search.Index, []search.Document{document})
+		})
+	}
+
+	ctx.mu.Lock()
+	ctx.searchFromFeed = true
+	ctx.mu.Unlock()
+	return nil
+}
+
+// BackfillSearch indexes every row of a Searchable entity, e.g. after configuring it or to repair the index
+// Rows are read page by page in key order; c cancels it between pages
+func (ctx *DbContext) BackfillSearch(c stdcontext.Context, entityType reflect.Type, batchSize int) (int, error) {
+	entityModel := ctx.GetEntityModel(entityType)
+	if entityModel == nil || entityModel.Search == nil {
+		return 0, fmt.Errorf("%s is not configured Searchable", entityType)
+	}
+	engine := ctx.currentSearchEngine()
+	if engine == nil {
+		return 0, errors.New("BackfillSearch requires a search engine; call SetSearchEngine first")
+	}
+
+	index, err := ctx.searchIndex(entityModel)
+	if err != nil {
+		return 0, err
+	}
+	columns := make([]string, 0, len(index.columns))
+	for column := range index.columns {
+		columns = append(columns, column)
+	}
+	return search.Backfill(ctx.db.WithContext(c), engine, search.BackfillOptions{
+		Table:     index.schema.Table,
+		KeyColumn: index.key.DBName,
+		Columns:   columns,
+		Index:     entityModel.Search.Index,
+		BatchSize: batchSize,
+	})
+}
+
+func (ctx *DbContext) currentSearchEngine() search.Engine {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	return ctx.searchEngine
+}
+
+// syncSearch indexes the saved entries of Searchable entities, one request per index and operation
+func (ctx *DbContext) syncSearch(entries []*EntityEntry) error {
+	ctx.mu.RLock()
+	engine, fromFeed := ctx.searchEngine, ctx.searchFromFeed
+	ctx.mu.RUnlock()
+	if engine == nil || fromFeed {
+		return nil
+	}
+
+	type pending struct {
+		upserts []search.Document
+		deletes []string
+	}
+	var order []string
+	byIndex := make(map[string]*pending)
+	indexes := make(map[reflect.Type]*searchIndex)
+
+	for _, entry := range entries {
+		entityValue := reflect.Indirect(reflect.ValueOf(entry.Entity))
+		index, cached := indexes[entityValue.Type()]
+		if !cached {
+			if entityModel := ctx.GetEntityModel(entityValue.Type()); entityModel != nil && entityModel.Search != nil {
+				var err error
+				if index, err = ctx.searchIndex(entityModel); err != nil {
+					return fmt.Errorf("%w: changes were saved: %w", search.ErrSyncFailed, err)
+				}
+			}
+			indexes[entityValue.Type()] = index
+		}
+		if index == nil {
+			continue
+		}
+
+		name := index.model.Search.Index
+		if byIndex[name] == nil {
+			byIndex[name] = &pending{}
+			order = append(order, name)
+		}
+		key, _ := index.key.ValueOf(stdcontext.Background(), entityValue)
+		id := search.DocumentID(key)
+
+		if entry.State == EntityDeleted {
+			byIndex[name].deletes = append(byIndex[name].deletes, id)
+			continue
+		}
+		document := search.Document{ID: id, Fields: make(map[string]interface{}, len(index.columns))}
+		for column, field := range index.columns {
+			document.Fields[column], _ = field.ValueOf(stdcontext.Background(), entityValue)
+		}
+		byIndex[name].upserts = append(byIndex[name].upserts, document)
+	}
+
+	for _, name := range order {
+		if err := engine.Upsert(stdcontext.Background(), name, byIndex[name].upserts); err != nil {
+			return fmt.Errorf("%w: changes were saved: %w", search.ErrSyncFailed, err)
+		}
+		if err := engine.Delete(stdcontext.Background(), name, byIndex[name].deletes); err != nil {
+			return fmt.Errorf("%w: changes were saved: %w", search.ErrSyncFailed, err)
+		}
+	}
+	return nil
+}
+
+// searchIndex resolves the indexed columns and key of a Searchable entity with GORM's naming, so they
+// match the columns the change feed and backfill read
+func (ctx *DbContext) searchIndex(entityModel *models.EntityModel) (*searchIndex, error) {
+	stmt := &gorm.Statement{DB: ctx.db}
+	if err := stmt.Parse(reflect.New(entityModel.Type).Interface()); err != nil {
+		return nil, err
+	}
+	if stmt.Schema.PrioritizedPrimaryField == nil {
+		return nil, fmt.Errorf("searchable entity %s has no primary key", entityModel.Name)
+	}
+
+	index := &searchIndex{
+		model:   entityModel,
+		schema:  stmt.Schema,
+		key:     stmt.Schema.PrioritizedPrimaryField,
+		columns: make(map[string]*schema.Field),
+	}
+	if len(entityModel.Search.Fields) > 0 {
+		for _, name := range entityModel.Search.Fields {
+			field := stmt.Schema.LookUpField(name)
+			if field == nil || field.DBName == "" {
+				return nil, fmt.Errorf("searchable field %s.%s is not a column", entityModel.Name, name)
+			}
+			index.columns[field.DBName] = field
+		}
+		return index, nil
+	}
+
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName == "" || entityModel.Fields[field.Name].Encryption != nil {
+			continue
+		}
+		index.columns[field.DBName] = field
+	}
+	return index, nil
+}
@@ -0,0 +1,123 @@
+package context
+
+import (
+	stdcontext "context"
+	"fmt"
+	"sync"
+
+	"github.com/shepherrrd/gontext/internal/models"
+)
+
+// Sequences hands out values of database sequences for human-readable sequential identifiers such as
+// invoice numbers, independent of primary keys. Each process reserves a block of values per round trip
+// (the sequence's increment) and hands them out from memory, so values are unique across processes but
+// only ordered within one, and a block left unused by a stopped process is a gap
+type Sequences struct {
+	ctx    *DbContext
+	mu     sync.Mutex
+	blocks map[string]*sequenceBlock // sequence name -> values reserved by this process
+}
+
+// sequenceBlock is the reserved range [next, max) of a sequence
+type sequenceBlock struct {
+	increment int64
+	next      int64
+	max       int64
+}
+
+func newSequences(ctx *DbContext) *Sequences {
+	return &Sequences{ctx: ctx, blocks: make(map[string]*sequenceBlock)}
+}
+
+// HasSequence declares a sequence so migrations create it - EF Core: modelBuilder.HasSequence(name)
+// Usage: ctx.HasSequence("invoice_number").StartsAt(1000).BlockSize(20)
+func (ctx *DbContext) HasSequence(name string) *models.SequenceBuilder {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
+	sequence, exists := ctx.sequenceModels[name]
+	if !exists {
+		sequence = &models.SequenceModel{Name: name, StartWith: 1, BlockSize: 1}
+		ctx.sequenceModels[name] = sequence
+	}
+	return models.NewSequenceBuilder(sequence)
+}
+
+// GetSequenceModels returns the declared sequences by name
+func (ctx *DbContext) GetSequenceModels() map[string]*models.SequenceModel {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	sequences := make(map[string]*models.SequenceModel, len(ctx.sequenceModels))
+	for name, sequence := range ctx.sequenceModels {
+		sequences[name] = sequence
+	}
+	return sequences
+}
+
+// Sequences returns the allocator of the context's sequences
+//
+//	number, err := ctx.Sequences().Next("invoice_number")
+func (ctx *DbContext) Sequences() *Sequences {
+	return ctx.sequences
+}
+
+// Next returns the next value of the sequence name, reserving a new block when the current one is used up
+// Requires PostgreSQL; the sequence is created by migrations when declared with HasSequence
+func (s *Sequences) Next(name string) (int64, error) {
+	return s.NextContext(stdcontext.Background(), name)
+}
+
+// NextContext is Next with a context for the round trip that reserves a block
+func (s *Sequences) NextContext(ctx stdcontext.Context, name string) (int64, error) {
+	if s.ctx.driver.Name() != "postgres" {
+		return 0, fmt.Errorf("sequences require PostgreSQL, not %s", s.ctx.driver.Name())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	block, exists := s.blocks[name]
+	if !exists {
+		increment, err := s.increment(ctx, name)
+		if err != nil {
+			return 0, err
+		}
+		block = &sequenceBlock{increment: increment}
+		s.blocks[name] = block
+	}
+
+	if block.next >= block.max {
+		var first int64
+		// Quote the sequence name so case-sensitive names resolve like table names
+		err := s.ctx.db.WithContext(ctx).Raw("SELECT nextval(?)", fmt.Sprintf(`"%s"`, name)).Scan(&first).Error
+		if err != nil {
+			return 0, fmt.Errorf("failed to reserve values from sequence %s: %w", name, err)
+		}
+		block.next = first
+		block.max = first + block.increment
+	}
+
+	value := block.next
+	block.next++
+	return value, nil
+}
+
+// increment reads the sequence's increment from the database rather than the declaration, so blocks
+// never overlap values other processes take even when the declaration is ahead of the migrations
+func (s *Sequences) increment(ctx stdcontext.Context, name string) (int64, error) {
+	var increments []int64
+	err := s.ctx.db.WithContext(ctx).
+		Raw("SELECT increment_by FROM pg_sequences WHERE schemaname = current_schema() AND sequencename = ?", name).
+		Scan(&increments).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to read sequence %s: %w", name, err)
+	}
+	if len(increments) == 0 {
+		return 0, fmt.Errorf("sequence %s does not exist; declare it with HasSequence and run migrations", name)
+	}
+	if increments[0] <= 0 {
+		return 0, fmt.Errorf("sequence %s must increment upwards, got increment %d", name, increments[0])
+	}
+	return increments[0], nil
+}
@@ -0,0 +1,173 @@
+package context
+
+import (
+	"fmt"
+	"hash/fnv"
+	"reflect"
+
+	"gorm.io/gorm"
+
+	"github.com/shepherrrd/gontext/internal/models"
+)
+
+// ShardResolver picks the shard storing the rows of an entity type with a shard key value: an index
+// below shards into Sharding.Databases
+type ShardResolver func(entityType reflect.Type, key interface{}, shards int) int
+
+// HashShards spreads keys evenly over the shards by an FNV-1a hash of their text, the default resolver
+// Pointer keys hash like the value they point to. Changing the number of shards moves most keys, so
+// plan resharding with a resolver of your own
+func HashShards(entityType reflect.Type, key interface{}, shards int) int {
+	hash := fnv.New32a()
+	fmt.Fprint(hash, shardKeyValue(key))
+	return int(hash.Sum32() % uint32(shards))
+}
+
+// shardKeyValue dereferences pointer keys, so a *string key field selects the shard of its text rather
+// than of its address; a nil pointer becomes nil
+func shardKeyValue(key interface{}) interface{} {
+	value := reflect.ValueOf(key)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if !value.IsValid() {
+		return nil
+	}
+	return value.Interface()
+}
+
+// Sharding spreads an entity type over several databases with the same schema, for ShardEntity
+type Sharding struct {
+	// Key is the field whose value selects the shard, e.g. TenantId; rows of one key share a shard
+	Key string
+	// Databases are the names of the shard databases added with AddDatabase, in shard order
+	Databases []string
+	// Resolver maps a key to its shard (default HashShards)
+	Resolver ShardResolver
+}
+
+// shardedEntity is the resolved sharding of an entity type
+type shardedEntity struct {
+	key       models.FieldModel
+	names     []string
+	databases []*DbContext
+	resolver  ShardResolver
+}
+
+// ShardEntity registers the entity type, if needed, and spreads its rows over the shard databases by
+// the value of its shard key: SaveChanges writes each entity to the shard of its key, and the model
+// joins the migrations and EnsureCreated of every shard. Query the shards with a ShardedSet
+// Keys generated by one database (auto-increment) repeat across shards; generate them with a key
+// generator or HiLo on a single database, or make the shard key part of the primary key
+// Panics when the key field or a database is unknown, or the shards use different drivers
+func (ctx *DbContext) ShardEntity(entity interface{}, sharding Sharding) *DbSet {
+	dbSet := ctx.RegisterEntity(entity)
+	key, exists := dbSet.entityModel.Fields[sharding.Key]
+	if !exists {
+		panic(fmt.Sprintf("Field '%s' not found on %s", sharding.Key, dbSet.entityModel.Name))
+	}
+	if len(sharding.Databases) == 0 {
+		panic(fmt.Sprintf("Sharding of %s needs at least one database", dbSet.entityModel.Name))
+	}
+
+	sharded := &shardedEntity{key: key, names: sharding.Databases, resolver: sharding.Resolver}
+	if sharded.resolver == nil {
+		sharded.resolver = HashShards
+	}
+	for _, name := range sharding.Databases {
+		database := ctx.DatabaseContext(name)
+		if database == nil {
+			panic(fmt.Sprintf("Database '%s' not found, add it with AddDatabase", name))
+		}
+		if first := sharded.databases; len(first) > 0 && first[0].driver.Name() != database.driver.Name() {
+			panic(fmt.Sprintf("Shards of %s must use one driver, not %s and %s",
+				dbSet.entityModel.Name, first[0].driver.Name(), database.driver.Name()))
+		}
+		database.adoptEntity(dbSet.entityType, dbSet.entityModel)
+		sharded.databases = append(sharded.databases, database)
+	}
+
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
+	if ctx.routes == nil {
+		ctx.routes = make(map[string]*DbContext)
+	}
+	if ctx.shards == nil {
+		ctx.shards = make(map[string]*shardedEntity)
+	}
+	typeKey := typeKey(dbSet.entityType)
+	ctx.routes[typeKey] = sharded.databases[0] // Connection and driver of unsharded access (DBFor, DriverFor)
+	ctx.shards[typeKey] = sharded
+	return dbSet
+}
+
+// shardedEntityOf returns the sharding of an entity type, nil for types stored in one database
+func (ctx *DbContext) shardedEntityOf(entityType reflect.Type) *shardedEntity {
+	if entityType.Kind() == reflect.Ptr {
+		entityType = entityType.Elem()
+	}
+
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	return ctx.shards[typeKey(entityType)]
+}
+
+// ShardKey returns the shard key field of a sharded entity type, "" for types stored in one database
+func (ctx *DbContext) ShardKey(entityType reflect.Type) string {
+	if sharded := ctx.shardedEntityOf(entityType); sharded != nil {
+		return sharded.key.Name
+	}
+	return ""
+}
+
+// ShardConnections returns the connections of the shards of an entity type, in shard order
+func (ctx *DbContext) ShardConnections(entityType reflect.Type) []*gorm.DB {
+	sharded := ctx.shardedEntityOf(entityType)
+	if sharded == nil {
+		return []*gorm.DB{ctx.DBFor(entityType)}
+	}
+	connections := make([]*gorm.DB, len(sharded.databases))
+	for i, database := range sharded.databases {
+		connections[i] = database.db
+	}
+	return connections
+}
+
+// ShardIndex returns the shard storing the rows of an entity type with a shard key value
+func (ctx *DbContext) ShardIndex(entityType reflect.Type, key interface{}) (int, error) {
+	sharded := ctx.shardedEntityOf(entityType)
+	if sharded == nil {
+		return 0, nil
+	}
+	return sharded.shard(entityType, key)
+}
+
+// shard resolves a key, rejecting indexes the resolver got wrong
+func (s *shardedEntity) shard(entityType reflect.Type, key interface{}) (int, error) {
+	key = shardKeyValue(key) // Resolvers of their own see values, not pointers, as well
+	shard := s.resolver(entityType, key, len(s.databases))
+	if shard < 0 || shard >= len(s.databases) {
+		return 0, fmt.Errorf("shard resolver returned %d for %s key %v, expected 0 to %d",
+			shard, entityType.Name(), key, len(s.databases)-1)
+	}
+	return shard, nil
+}
+
+// databaseOfEntity returns the shard database of a tracked entity from its shard key value
+func (s *shardedEntity) databaseOfEntity(entity interface{}) (*DbContext, string, error) {
+	entityValue := reflect.Indirect(reflect.ValueOf(entity))
+	key := s.key.ValueOf(entityValue)
+	if !key.IsValid() {
+		return nil, "", fmt.Errorf("%s has no shard key %s", entityValue.Type().Name(), s.key.Name)
+	}
+	shard, err := s.shard(entityValue.Type(), key.Interface())
+	if err != nil {
+		return nil, "", err
+	}
+	return s.databases[shard], s.names[shard], nil
+}
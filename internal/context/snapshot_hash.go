@@ -0,0 +1,222 @@
+package context
+
+import (
+	"hash/maphash"
+	"math"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// snapshotSeed seeds the fingerprints of tracked entities; they are only compared within the process
+var snapshotSeed = maphash.MakeSeed()
+
+// fingerprint records the hash of the entry's snapshot so DetectChanges can skip the deep comparison
+// of entities that still hash the same; entries without a snapshot or with values the hash cannot
+// represent are always compared
+func (entry *EntityEntry) fingerprint() *EntityEntry {
+	if entry.OriginalEntity != nil {
+		entry.snapshotHash, entry.hashed = snapshotHash(entry.OriginalEntity)
+	}
+	return entry
+}
+
+// snapshotHasher holds the state of one hash; hashers are pooled, so hashing allocates nothing once warm
+type snapshotHasher struct {
+	hash    maphash.Hash
+	root    pointerVisit
+	seen    map[pointerVisit]uint64     // Order pointers other than the root were first seen in
+	entries []*maphash.Hash             // Hash of the current map entry, per map nesting depth
+	holders map[holderKey]reflect.Value // Settable copies of the map keys and values being hashed
+	depth   int
+}
+
+// holderKey identifies the holder of a map key or value by type and map nesting depth
+type holderKey struct {
+	typ   reflect.Type
+	depth int
+	elem  bool // Value rather than key, for maps whose keys and values share a type
+}
+
+var snapshotHashers = sync.Pool{New: func() interface{} {
+	hasher := &snapshotHasher{
+		seen:    make(map[pointerVisit]uint64),
+		holders: make(map[holderKey]reflect.Value),
+	}
+	hasher.hash.SetSeed(snapshotSeed)
+	return hasher
+}}
+
+// snapshotHash hashes the state entitiesEqual compares: exported fields, followed through pointers,
+// slices and maps, with times by instant. A pointer seen before hashes as the order it was first seen
+// in, so shared instances and cycles hash by their shape. It reports false for values only a deep
+// comparison can judge - types registered with types.Register, which define their own equality, opaque
+// structs and functions or channels. A changed entity never keeps its hash; an unchanged one can get a
+// different hash (+0 and -0, pointers first seen inside maps), which the deep comparison then settles
+func snapshotHash(entity interface{}) (uint64, bool) {
+	value := reflect.ValueOf(entity)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return 0, false
+	}
+
+	hasher := snapshotHashers.Get().(*snapshotHasher)
+	defer hasher.release()
+
+	hasher.root = visitOf(value)
+	if !hasher.write(&hasher.hash, value.Elem()) {
+		return 0, false
+	}
+	return hasher.hash.Sum64(), true
+}
+
+// release resets the hasher and returns it to the pool
+func (hasher *snapshotHasher) release() {
+	hasher.hash.Reset()
+	hasher.root = pointerVisit{}
+	hasher.depth = 0
+	clear(hasher.seen)
+	for _, holder := range hasher.holders {
+		holder.SetZero() // Do not keep the last entity's values alive
+	}
+	snapshotHashers.Put(hasher)
+}
+
+// holder returns a settable value of type t for the current map nesting depth
+func (hasher *snapshotHasher) holder(t reflect.Type, elem bool) reflect.Value {
+	key := holderKey{typ: t, depth: hasher.depth, elem: elem}
+	holder, exists := hasher.holders[key]
+	if !exists {
+		holder = reflect.New(t).Elem()
+		hasher.holders[key] = holder
+	}
+	return holder
+}
+
+// write writes value into hash, reporting false when it cannot be hashed faithfully
+func (hasher *snapshotHasher) write(hash *maphash.Hash, value reflect.Value) bool {
+	if value.Kind() != reflect.Ptr && isRegisteredType(value.Type()) {
+		return false
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		structType := value.Type()
+		if structType == timeType {
+			var instant time.Time
+			switch {
+			case value.CanAddr() && value.CanInterface():
+				instant = *value.Addr().Interface().(*time.Time) // Boxing the pointer does not allocate
+			case value.CanInterface():
+				instant = value.Interface().(time.Time)
+			default:
+				return false
+			}
+			writeUint64(hash, uint64(instant.Unix()))
+			writeUint64(hash, uint64(instant.Nanosecond()))
+			return true
+		}
+		if isOpaqueStruct(structType) {
+			return false
+		}
+		for i := 0; i < value.NumField(); i++ {
+			if structType.Field(i).PkgPath != "" {
+				continue // entitiesEqual skips unexported fields too
+			}
+			if !hasher.write(hash, value.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice, reflect.Array:
+		writeUint64(hash, uint64(value.Len()))
+		for i := 0; i < value.Len(); i++ {
+			if !hasher.write(hash, value.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		// Map order is random, so entries are hashed on their own and combined order-independently
+		writeUint64(hash, uint64(value.Len()))
+		if hasher.depth == len(hasher.entries) {
+			entry := new(maphash.Hash)
+			entry.SetSeed(snapshotSeed)
+			hasher.entries = append(hasher.entries, entry)
+		}
+		entry := hasher.entries[hasher.depth]
+		key, elem := hasher.holder(value.Type().Key(), false), hasher.holder(value.Type().Elem(), true)
+		hasher.depth++
+		defer func() { hasher.depth-- }()
+
+		var entries uint64
+		iter := value.MapRange()
+		for iter.Next() {
+			key.SetIterKey(iter)
+			elem.SetIterValue(iter)
+			entry.Reset()
+			if !hasher.write(entry, key) || !hasher.write(entry, elem) {
+				return false
+			}
+			entries += entry.Sum64()
+		}
+		writeUint64(hash, entries)
+		return true
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			hash.WriteByte(0)
+			return true
+		}
+		if value.Kind() == reflect.Interface {
+			hash.WriteByte(1)
+			hash.WriteString(value.Elem().Type().String())
+			return hasher.write(hash, value.Elem())
+		}
+		visit := visitOf(value)
+		order, exists := hasher.seen[visit]
+		if visit == hasher.root {
+			order, exists = 0, true
+		}
+		if exists {
+			hash.WriteByte(2)
+			writeUint64(hash, order)
+			return true
+		}
+		hasher.seen[visit] = uint64(len(hasher.seen) + 1)
+		hash.WriteByte(1)
+		return hasher.write(hash, value.Elem())
+	case reflect.Bool:
+		if value.Bool() {
+			hash.WriteByte(1)
+		} else {
+			hash.WriteByte(0)
+		}
+		return true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint64(hash, uint64(value.Int()))
+		return true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint64(hash, value.Uint())
+		return true
+	case reflect.Float32, reflect.Float64:
+		writeUint64(hash, math.Float64bits(value.Float()))
+		return true
+	case reflect.Complex64, reflect.Complex128:
+		writeUint64(hash, math.Float64bits(real(value.Complex())))
+		writeUint64(hash, math.Float64bits(imag(value.Complex())))
+		return true
+	case reflect.String:
+		writeUint64(hash, uint64(value.Len())) // "ab","c" and "a","bc" differ
+		hash.WriteString(value.String())
+		return true
+	}
+	return false
+}
+
+// writeUint64 writes v into hash without allocating
+func writeUint64(hash *maphash.Hash, v uint64) {
+	var buf [8]byte
+	for i := range buf {
+		buf[i] = byte(v >> (8 * i))
+	}
+	hash.Write(buf[:])
+}
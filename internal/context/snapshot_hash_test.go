@@ -0,0 +1,184 @@
+package context
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/shepherrrd/gontext/internal/types"
+)
+
+type hashedTag struct {
+	Name   string
+	Labels map[string][]string
+}
+
+type hashedPost struct {
+	Id        int
+	Title     string
+	Score     float64
+	Draft     bool
+	CreatedAt time.Time
+	DeletedAt *time.Time
+	Tags      []hashedTag
+	Meta      map[string]string
+	Extra     interface{}
+	Parent    *hashedPost
+}
+
+// alwaysChanged is registered with an Equal that never matches, so entities holding it always save
+type alwaysChanged struct {
+	Value int
+}
+
+func init() {
+	types.Register(reflect.TypeOf(alwaysChanged{}), types.Mapping{
+		Equal: func(a, b interface{}) bool { return false },
+	})
+}
+
+type registeredPost struct {
+	Id     int
+	Status alwaysChanged
+}
+
+func newHashedPost(id int) *hashedPost {
+	deleted := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	return &hashedPost{
+		Id:        id,
+		Title:     fmt.Sprintf("post %d", id),
+		Score:     1.5,
+		CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		DeletedAt: &deleted,
+		Tags: []hashedTag{
+			{Name: "go", Labels: map[string][]string{"level": {"easy", "fun"}}},
+			{Name: "sql"},
+		},
+		Meta:  map[string]string{"a": "1", "b": "2"},
+		Extra: 7,
+	}
+}
+
+// detect tracks post, applies change and reports whether DetectChanges marks it modified, also checking
+// that a hash equal to the snapshot's never comes with a changed entity
+func detect(t *testing.T, post *hashedPost, change func(*hashedPost)) bool {
+	t.Helper()
+	ct := NewChangeTracker()
+	ct.TrackLoaded(post)
+	entry := ct.entries[ct.entityKey(post)]
+	if !entry.hashed {
+		t.Fatal("hashedPost is not hashed at track time")
+	}
+
+	change(post)
+	equal := ct.entitiesEqual(post, entry.OriginalEntity)
+	if hash, ok := snapshotHash(post); ok && hash == entry.snapshotHash && !equal {
+		t.Fatal("hash equal to the snapshot's for a changed entity")
+	}
+	ct.DetectChanges()
+	if modified := entry.State == EntityModified; modified == equal {
+		t.Fatalf("DetectChanges modified = %v, deep comparison equal = %v", modified, equal)
+	}
+	return entry.State == EntityModified
+}
+
+func TestDetectChangesHashNeverHidesChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(*hashedPost)
+		changed bool
+	}{
+		{"nothing", func(p *hashedPost) {}, false},
+		{"title", func(p *hashedPost) { p.Title += "!" }, true},
+		{"float", func(p *hashedPost) { p.Score = 1.25 }, true},
+		{"bool", func(p *hashedPost) { p.Draft = true }, true},
+		{"time instant", func(p *hashedPost) { p.CreatedAt = p.CreatedAt.Add(time.Nanosecond) }, true},
+		{"time zone only", func(p *hashedPost) { p.CreatedAt = p.CreatedAt.In(time.FixedZone("X", 3600)) }, false},
+		{"pointed time", func(p *hashedPost) { *p.DeletedAt = p.DeletedAt.Add(time.Second) }, true},
+		{"nil pointer", func(p *hashedPost) { p.DeletedAt = nil }, true},
+		{"nested slice element", func(p *hashedPost) { p.Tags[1].Name = "pg" }, true},
+		{"slice in map in slice", func(p *hashedPost) { p.Tags[0].Labels["level"][1] = "hard" }, true},
+		{"map key moved", func(p *hashedPost) {
+			p.Tags[0].Labels["rank"] = p.Tags[0].Labels["level"]
+			delete(p.Tags[0].Labels, "level")
+		}, true},
+		{"slice appended", func(p *hashedPost) { p.Tags = append(p.Tags, hashedTag{}) }, true},
+		{"map values swapped", func(p *hashedPost) { p.Meta["a"], p.Meta["b"] = p.Meta["b"], p.Meta["a"] }, true},
+		{"map rebuilt equal", func(p *hashedPost) { p.Meta = map[string]string{"b": "2", "a": "1"} }, false},
+		{"interface type", func(p *hashedPost) { p.Extra = int64(7) }, true},
+		{"interface value", func(p *hashedPost) { p.Extra = 8 }, true},
+		{"strings re-split", func(p *hashedPost) { p.Tags[0].Labels["level"] = []string{"easyf", "un"} }, true},
+		{"cycle", func(p *hashedPost) { p.Parent = p }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if changed := detect(t, newHashedPost(1), tt.change); changed != tt.changed {
+				t.Errorf("modified = %v, want %v", changed, tt.changed)
+			}
+		})
+	}
+}
+
+func TestDetectChangesCyclicGraph(t *testing.T) {
+	post := newHashedPost(1)
+	child := newHashedPost(2)
+	post.Parent, child.Parent = child, post
+
+	if detect(t, post, func(p *hashedPost) {}) {
+		t.Fatal("unchanged cyclic graph reported as modified")
+	}
+	if !detect(t, post, func(p *hashedPost) { p.Parent.Parent.Parent.Title = "changed" }) {
+		t.Fatal("change reached through a cycle was not detected")
+	}
+}
+
+func TestDetectChangesRegisteredTypesAlwaysCompared(t *testing.T) {
+	ct := NewChangeTracker()
+	post := &registeredPost{Id: 1, Status: alwaysChanged{Value: 1}}
+	ct.TrackLoaded(post)
+	entry := ct.entries[ct.entityKey(post)]
+	if entry.hashed {
+		t.Fatal("entity with a registered type was hashed; its Equal would be skipped")
+	}
+
+	ct.DetectChanges()
+	if entry.State != EntityModified {
+		t.Fatal("registered Equal returning false did not mark the entity modified")
+	}
+}
+
+func BenchmarkDetectChanges(b *testing.B) {
+	const tracked = 10000
+	for _, modified := range []int{0, 100} {
+		b.Run(fmt.Sprintf("%d of %d modified", modified, tracked), func(b *testing.B) {
+			ct := NewChangeTracker()
+			posts := make([]*hashedPost, tracked)
+			for i := range posts {
+				posts[i] = newHashedPost(i + 1)
+				ct.TrackLoaded(posts[i])
+			}
+			changed := make([]*EntityEntry, modified)
+			for i := range changed {
+				posts[i*(tracked/modified)].Title = "changed"
+				changed[i] = ct.entries[ct.entityKey(posts[i*(tracked/modified)])]
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				ct.DetectChanges()
+
+				b.StopTimer()
+				for _, entry := range changed {
+					if entry.State != EntityModified {
+						b.Fatal("modified entity not detected")
+					}
+					entry.State = EntityUnchanged
+				}
+				b.StartTimer()
+			}
+		})
+	}
+}
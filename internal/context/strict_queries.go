@@ -0,0 +1,61 @@
+package context
+
+import (
+	"os"
+	"strings"
+)
+
+// EnvironmentVariable names the environment gontext runs in; "production" (or "prod") turns off
+// the development defaults such as strict queries
+const EnvironmentVariable = "GONTEXT_ENV"
+
+// StrictQueryMode controls whether LINQ methods fail on arguments they cannot use
+type StrictQueryMode int
+
+const (
+	// StrictQueriesInDevelopment enables strict queries unless GONTEXT_ENV is production (the default)
+	StrictQueriesInDevelopment StrictQueryMode = iota
+	// StrictQueriesOn always enables strict queries
+	StrictQueriesOn
+	// StrictQueriesOff keeps the lenient behaviour, where unusable arguments leave the query unchanged
+	StrictQueriesOff
+)
+
+// enabled resolves the mode against the environment
+func (m StrictQueryMode) enabled() bool {
+	switch m {
+	case StrictQueriesOn:
+		return true
+	case StrictQueriesOff:
+		return false
+	default:
+		return !IsProduction()
+	}
+}
+
+// IsProduction reports whether GONTEXT_ENV names a production environment
+func IsProduction() bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(EnvironmentVariable))) {
+	case "production", "prod":
+		return true
+	}
+	return false
+}
+
+// SetStrictQueries makes LINQ methods record an error, returned by the query's terminal method, when
+// they get arguments they cannot use - unrecognized argument types, unknown field names, field selector
+// functions or Expression predicates that cannot be translated - instead of leaving the query unchanged
+func (ctx *DbContext) SetStrictQueries(enabled bool) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
+	ctx.strictQueries = enabled
+}
+
+// StrictQueries reports whether strict query mode is enabled
+func (ctx *DbContext) StrictQueries() bool {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	return ctx.strictQueries
+}
@@ -0,0 +1,197 @@
+package context
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ChangeTrackingStrategy selects how modified entities are found - EF Core: ChangeTrackingStrategy
+type ChangeTrackingStrategy int
+
+const (
+	// SnapshotTracking deep-copies every tracked entity and compares it during SaveChanges
+	SnapshotTracking ChangeTrackingStrategy = iota
+	// ExplicitTracking keeps no snapshots; only MarkModified, SetProperty and DirtyTracker entities are saved as modified
+	ExplicitTracking
+)
+
+// DirtyTracker is implemented by entities whose setters record their own changes
+// They are never compared against a snapshot, so tracking them costs no copy
+type DirtyTracker interface {
+	// DirtyProperties returns the field names changed since loading or the last save
+	DirtyProperties() []string
+	// ClearDirty resets the recorded changes once SaveChanges has committed
+	ClearDirty()
+}
+
+// SetStrategy changes the strategy used for entities tracked from now on
+func (ct *ChangeTracker) SetStrategy(strategy ChangeTrackingStrategy) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	ct.strategy = strategy
+}
+
+// snapshot copies an entity for later comparison, or returns nil when no copy is needed
+// Callers hold ct.mu
+func (ct *ChangeTracker) snapshot(entity interface{}) interface{} {
+	if ct.strategy == ExplicitTracking {
+		return nil
+	}
+	if _, ok := entity.(DirtyTracker); ok {
+		return nil
+	}
+	return ct.deepCopy(entity)
+}
+
+// MarkModified marks a tracked (or new) entity as modified - EF Core: Entry(e).Property(p).IsModified = true
+// With properties only those fields are updated; without, every column is
+func (ct *ChangeTracker) MarkModified(entity interface{}, properties ...string) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	key := ct.entityKey(entity)
+	entry, exists := ct.entries[key]
+	if !exists {
+		ct.sequence++
+		entry = &EntityEntry{State: EntityUnchanged, sequence: ct.sequence}
+		ct.entries[key] = entry
+	}
+	entry.Entity = entity
+
+	wholeEntity := entry.State == EntityModified && len(entry.ModifiedProperties) == 0
+	switch entry.State {
+	case EntityUnchanged:
+		entry.State = EntityModified
+	case EntityModified:
+	default:
+		return // Added and deleted entities already write the whole row
+	}
+
+	if len(properties) == 0 || wholeEntity {
+		entry.ModifiedProperties = nil
+		return
+	}
+	for _, property := range properties {
+		if !containsString(entry.ModifiedProperties, property) {
+			entry.ModifiedProperties = append(entry.ModifiedProperties, property)
+		}
+	}
+}
+
+// SetChangeTrackingStrategy selects snapshot or explicit change tracking for entities tracked from now on
+// ExplicitTracking avoids copying every loaded entity, halving memory for large result sets
+func (ctx *DbContext) SetChangeTrackingStrategy(strategy ChangeTrackingStrategy) {
+	ctx.changeTracker.SetStrategy(strategy)
+}
+
+// MarkModified marks an entity as modified, optionally limiting the UPDATE to the given fields
+func (ctx *DbContext) MarkModified(entity interface{}, properties ...string) {
+	ctx.changeTracker.MarkModified(entity, properties...)
+}
+
+// SetProperty assigns a field on an entity pointer and marks just that field as modified
+func (ctx *DbContext) SetProperty(entity interface{}, property string, value interface{}) error {
+	entityValue := reflect.ValueOf(entity)
+	if entityValue.Kind() != reflect.Ptr || entityValue.IsNil() || entityValue.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("SetProperty requires a non-nil pointer to a struct, got %T", entity)
+	}
+
+	field := entityValue.Elem().FieldByName(property)
+	if !field.IsValid() || !field.CanSet() {
+		return fmt.Errorf("%s has no settable field %s", entityValue.Elem().Type().Name(), property)
+	}
+
+	if value == nil {
+		field.Set(reflect.Zero(field.Type()))
+	} else {
+		newValue := reflect.ValueOf(value)
+		if !newValue.Type().ConvertibleTo(field.Type()) {
+			return fmt.Errorf("cannot assign %T to %s.%s (%s)", value, entityValue.Elem().Type().Name(), property, field.Type())
+		}
+		field.Set(newValue.Convert(field.Type()))
+	}
+
+	ctx.changeTracker.MarkModified(entity, property)
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ChangeTrackerStats reports how many entities are tracked, by state
+type ChangeTrackerStats struct {
+	Tracked   int
+	Unchanged int
+	Added     int
+	Modified  int
+	Deleted   int
+}
+
+// Stats counts the tracked entities by state
+func (ct *ChangeTracker) Stats() ChangeTrackerStats {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+
+	stats := ChangeTrackerStats{Tracked: len(ct.entries)}
+	for _, entry := range ct.entries {
+		switch entry.State {
+		case EntityUnchanged:
+			stats.Unchanged++
+		case EntityAdded:
+			stats.Added++
+		case EntityModified:
+			stats.Modified++
+		case EntityDeleted:
+			stats.Deleted++
+		}
+	}
+	return stats
+}
+
+// SetMaxTracked caps the number of tracked entities; 0 removes the cap
+func (ct *ChangeTracker) SetMaxTracked(limit int) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	ct.maxTracked = limit
+}
+
+// evictUnchanged stops tracking entities without pending changes
+// Changes made to them afterwards are only saved through Update or MarkModified. Callers hold ct.mu
+func (ct *ChangeTracker) evictUnchanged() {
+	for key, entry := range ct.entries {
+		if entry.State == EntityUnchanged {
+			delete(ct.entries, key)
+		}
+	}
+}
+
+// ClearChangeTracker stops tracking every entity, discarding unsaved changes - EF Core: ChangeTracker.Clear()
+// SaveChanges already clears the tracker after a successful commit
+func (ctx *DbContext) ClearChangeTracker() {
+	ctx.changeTracker.Clear()
+}
+
+// HasChanges reports whether any tracked entity has unsaved changes - EF Core: ChangeTracker.HasChanges()
+func (ctx *DbContext) HasChanges() bool {
+	ctx.changeTracker.DetectChanges()
+	return ctx.changeTracker.HasChanges()
+}
+
+// ChangeTrackerStats returns tracked-entity counts, useful as a memory metric in long-running workers
+func (ctx *DbContext) ChangeTrackerStats() ChangeTrackerStats {
+	return ctx.changeTracker.Stats()
+}
+
+// SetAutoClearThreshold stops tracking unchanged entities once limit entities are tracked
+// so workers that only read do not grow the tracker without bound; 0 disables the limit
+func (ctx *DbContext) SetAutoClearThreshold(limit int) {
+	ctx.changeTracker.SetMaxTracked(limit)
+}
@@ -0,0 +1,263 @@
+package context
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/shepherrrd/gontext/internal/dberrors"
+)
+
+// IsolationLevel selects the transaction isolation level - EF Core: BeginTransaction(IsolationLevel)
+// The level is passed through database/sql, so each driver issues its own syntax
+// (SET TRANSACTION ISOLATION LEVEL ... on PostgreSQL and MySQL)
+type IsolationLevel = sql.IsolationLevel
+
+const (
+	IsolationDefault        IsolationLevel = sql.LevelDefault
+	IsolationReadCommitted  IsolationLevel = sql.LevelReadCommitted
+	IsolationRepeatableRead IsolationLevel = sql.LevelRepeatableRead
+	IsolationSerializable   IsolationLevel = sql.LevelSerializable
+)
+
+// SaveOptions configures a single SaveChangesWithOptions call
+type SaveOptions struct {
+	// IsolationLevel for the SaveChanges transaction; IsolationDefault keeps the database default
+	IsolationLevel IsolationLevel
+	// MaxRetries re-runs the whole unit of work when the database reports a serialization failure or deadlock
+	MaxRetries int
+}
+
+// txOptions maps an isolation level to database/sql options for the current driver
+// SQLite transactions are always serializable and its driver rejects explicit levels, so none are passed
+func (ctx *DbContext) txOptions(level IsolationLevel) []*sql.TxOptions {
+	if level == IsolationDefault || ctx.driver.Name() == "sqlite" {
+		return nil
+	}
+	return []*sql.TxOptions{{Isolation: level}}
+}
+
+// SaveChangesWithOptions saves all tracked changes using the given isolation level,
+// retrying the transaction up to MaxRetries times on serialization failures
+func (ctx *DbContext) SaveChangesWithOptions(options SaveOptions) error {
+	ctx.changeTracker.DetectChanges()
+	// Tracked dependents of deleted entities follow their foreign keys' delete behavior
+	if err := ctx.applyDeleteBehaviors(); err != nil {
+		return err
+	}
+	pending := ctx.changeTracker.GetChanges()
+	if err := ctx.rejectViewChanges(pending); err != nil {
+		return err
+	}
+
+	// Entity hooks run first so validation sees the values they set
+	if err := ctx.runSaveHooks(pending); err != nil {
+		return err
+	}
+
+	// Validate everything up front so no SQL runs for an invalid unit of work
+	if err := ctx.validateChanges(pending); err != nil {
+		return err
+	}
+
+	// Each database commits on its own; one that fails leaves the changes of those before it saved
+	groups, err := ctx.changesByDatabase(pending)
+	if err != nil {
+		return err
+	}
+	var saved []*EntityEntry
+	for i, group := range groups {
+		if err := ctx.saveWithRetries(group, options); err != nil {
+			if len(saved) == 0 {
+				return err
+			}
+			return errors.Join(fmt.Errorf("saving to database %s failed after the changes to %s were saved: %w",
+				group.name, savedDatabases(groups[:i]), err), ctx.syncSearch(saved))
+		}
+		ctx.acceptChanges(group.entries, i == len(groups)-1)
+		saved = append(saved, group.entries...)
+	}
+	return ctx.syncSearch(saved)
+}
+
+// saveWithRetries saves the changes of one database, retrying its transaction up to MaxRetries times
+// on serialization failures
+func (ctx *DbContext) saveWithRetries(group databaseChanges, options SaveOptions) error {
+	for attempt := 0; ; attempt++ {
+		restore := snapshotAddedEntities(group.entries)
+		err := ctx.saveChanges(group.database, group.entries, group.database.txOptions(options.IsolationLevel)...)
+		if err == nil {
+			return nil
+		}
+		// The transaction rolled back - undo keys written into added entities before trying again
+		restore()
+		// A failure inside a shared transaction aborts the owner's transaction too, so it can't be retried here
+		if attempt >= options.MaxRetries || group.database.ownDB != nil || !errors.Is(err, dberrors.ErrSerializationFailure) {
+			return err
+		}
+	}
+}
+
+// savedDatabases names the databases whose changes were committed, for the error of a partial save
+func savedDatabases(groups []databaseChanges) string {
+	names := make([]string, 0, len(groups))
+	for _, group := range groups {
+		switch {
+		case len(group.entries) == 0:
+		case group.name == "":
+			names = append(names, "the context's database")
+		default:
+			names = append(names, group.name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// UseTransaction enlists the context in a transaction it didn't start, e.g. one begun by another context
+// on the same database, so both commit or roll back together - EF Core: Database.UseTransaction
+// Queries, DbSets obtained afterwards and SaveChanges run in the transaction; SaveChanges wraps its writes
+// in a savepoint so a failed save leaves the transaction usable. Committing and rolling back stay with
+// whoever began it. UseTransaction(nil) detaches the context again, which must happen before it is used
+// after the commit
+//
+//	tx := orders.BeginTransaction()
+//	billing.UseTransaction(tx)
+//	defer billing.UseTransaction(nil)
+func (ctx *DbContext) UseTransaction(tx *gorm.DB) error {
+	if tx == nil {
+		ctx.detachTransaction()
+		return nil
+	}
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return ctx.enlist(tx.Statement.ConnPool)
+}
+
+// UseSQLTransaction enlists the context in a database/sql transaction, e.g. one shared with code that
+// doesn't use gontext; see UseTransaction. The transaction must be on the context's database
+func (ctx *DbContext) UseSQLTransaction(tx *sql.Tx) error {
+	if tx == nil {
+		ctx.detachTransaction()
+		return nil
+	}
+	return ctx.enlist(tx)
+}
+
+// CurrentTransaction returns the transaction the context is enlisted in, or nil
+func (ctx *DbContext) CurrentTransaction() *gorm.DB {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+	if ctx.ownDB == nil {
+		return nil
+	}
+	return ctx.db
+}
+
+func (ctx *DbContext) enlist(pool gorm.ConnPool) error {
+	if committer, ok := pool.(gorm.TxCommitter); !ok || committer == nil {
+		return errors.New("gontext: UseTransaction needs an open transaction")
+	}
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	if ctx.ownDB == nil {
+		ctx.ownDB = ctx.db
+	}
+	// Passing the context makes GORM clone the statement, as Begin does, so the connection pool of the
+	// context's own handle is left alone
+	enlisted := ctx.ownDB.Session(&gorm.Session{NewDB: true, Context: ctx.ownDB.Statement.Context})
+	enlisted.Statement.ConnPool = pool
+	ctx.db = enlisted
+	return nil
+}
+
+func (ctx *DbContext) detachTransaction() {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	if ctx.ownDB != nil {
+		ctx.db = ctx.ownDB
+		ctx.ownDB = nil
+	}
+}
+
+// TransactionScope is the transaction of a WithTransaction call
+type TransactionScope struct {
+	ctx *DbContext
+	tx  *gorm.DB
+}
+
+// Context returns the scope's context, a scoped context enlisted in the transaction: its queries, the
+// DbSets obtained from it and SaveChanges run in the transaction. It tracks entities on its own and
+// must not be used after the scope ends
+func (s *TransactionScope) Context() *DbContext {
+	return s.ctx
+}
+
+// Tx returns the scope's transaction, for raw GORM calls
+func (s *TransactionScope) Tx() *gorm.DB {
+	return s.tx
+}
+
+// SaveChanges saves the changes tracked by the scope's context in the scope's transaction
+func (s *TransactionScope) SaveChanges() error {
+	return s.ctx.SaveChanges()
+}
+
+// WithTransaction runs fn in a transaction, committing when fn returns nil and rolling back when it
+// returns an error or panics (the panic is re-raised). fn works through the scope's context, a scoped
+// context enlisted in the transaction; the receiver is left alone, so other goroutines using it stay
+// outside the transaction and never see its uncommitted rows
+// Scopes nest: WithTransaction on a scope's context runs in a savepoint of the outer transaction
+func (ctx *DbContext) WithTransaction(fn func(scope *TransactionScope) error, level ...IsolationLevel) error {
+	var opts []*sql.TxOptions
+	if len(level) > 0 {
+		opts = ctx.txOptions(level[0])
+	}
+
+	ctx.mu.RLock()
+	db := ctx.db
+	ctx.mu.RUnlock()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		scoped := ctx.NewScopedContext()
+		if err := scoped.enlist(tx.Statement.ConnPool); err != nil {
+			return err
+		}
+		return fn(&TransactionScope{ctx: scoped, tx: tx})
+	}, opts...)
+	return dberrors.Translate(err)
+}
+
+// snapshotAddedEntities copies added entities so generated keys can be reset after a rollback
+func snapshotAddedEntities(entries []*EntityEntry) func() {
+	type saved struct {
+		entry  *EntityEntry
+		entity interface{}
+		value  reflect.Value
+	}
+
+	var snapshots []saved
+	for _, entry := range entries {
+		if entry.State != EntityAdded {
+			continue
+		}
+		snapshot := saved{entry: entry, entity: entry.Entity}
+		if entityValue := reflect.ValueOf(entry.Entity); entityValue.Kind() == reflect.Ptr && !entityValue.IsNil() {
+			snapshot.value = reflect.New(entityValue.Elem().Type()).Elem()
+			snapshot.value.Set(entityValue.Elem())
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return func() {
+		for _, snapshot := range snapshots {
+			snapshot.entry.Entity = snapshot.entity
+			if snapshot.value.IsValid() {
+				reflect.ValueOf(snapshot.entity).Elem().Set(snapshot.value)
+			}
+		}
+	}
+}
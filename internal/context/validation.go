@@ -0,0 +1,109 @@
+package context
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Validatable is implemented by entities that validate themselves before being persisted
+type Validatable interface {
+	Validate() error
+}
+
+// StructValidator validates entities with struct-tag rules (e.g. an adapter over go-playground/validator)
+// Returning a *ValidationError reports individual field violations
+type StructValidator interface {
+	ValidateStruct(entity interface{}) error
+}
+
+// ValidationViolation describes a single failed rule
+type ValidationViolation struct {
+	Entity  string
+	Field   string
+	Message string
+}
+
+// ValidationError aggregates every violation found during SaveChanges
+// Use errors.As(err, &validationErr) to inspect the violations
+type ValidationError struct {
+	Violations []ValidationViolation
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		if v.Field != "" {
+			messages = append(messages, fmt.Sprintf("%s.%s: %s", v.Entity, v.Field, v.Message))
+		} else {
+			messages = append(messages, fmt.Sprintf("%s: %s", v.Entity, v.Message))
+		}
+	}
+	return fmt.Sprintf("validation failed: %s", strings.Join(messages, "; "))
+}
+
+// SetValidator registers a struct-tag validator that runs for every added or modified entity
+func (ctx *DbContext) SetValidator(validator StructValidator) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
+	ctx.validator = validator
+}
+
+// validateChanges checks all added and modified entities and aggregates their violations
+// Returns nil when every entity is valid
+func (ctx *DbContext) validateChanges(changes []*EntityEntry) error {
+	ctx.mu.RLock()
+	validator := ctx.validator
+	ctx.mu.RUnlock()
+
+	result := &ValidationError{}
+	for _, entry := range changes {
+		if entry.State != EntityAdded && entry.State != EntityModified {
+			continue
+		}
+
+		// Use a pointer so Validate methods with pointer receivers are found
+		entity := entry.Entity
+		entityValue := reflect.ValueOf(entity)
+		if entityValue.Kind() != reflect.Ptr {
+			entityPtr := reflect.New(entityValue.Type())
+			entityPtr.Elem().Set(entityValue)
+			entity = entityPtr.Interface()
+		}
+		entityName := reflect.TypeOf(entity).Elem().Name()
+
+		if validatable, ok := entity.(Validatable); ok {
+			result.add(entityName, validatable.Validate())
+		}
+		if validator != nil {
+			result.add(entityName, validator.ValidateStruct(entity))
+		}
+	}
+
+	if len(result.Violations) == 0 {
+		return nil
+	}
+	return result
+}
+
+// add records the violations carried by err, if any
+func (e *ValidationError) add(entityName string, err error) {
+	if err == nil {
+		return
+	}
+
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		for _, v := range validationErr.Violations {
+			if v.Entity == "" {
+				v.Entity = entityName
+			}
+			e.Violations = append(e.Violations, v)
+		}
+		return
+	}
+
+	e.Violations = append(e.Violations, ValidationViolation{Entity: entityName, Message: err.Error()})
+}
@@ -0,0 +1,140 @@
+package context
+
+import (
+	stdcontext "context"
+	"fmt"
+	"log"
+	"reflect"
+
+	"github.com/shepherrrd/gontext/internal/dberrors"
+	"github.com/shepherrrd/gontext/internal/models"
+)
+
+// RefreshOption changes how Views.Refresh recomputes a materialized view
+type RefreshOption int
+
+const (
+	// Concurrently refreshes without blocking readers of the view; it needs a unique index on the view,
+	// declared with HasIndex(...).IsUnique() on the view entity
+	Concurrently RefreshOption = iota + 1
+)
+
+// Views refreshes the materialized views mapped with ToMaterializedView
+type Views struct {
+	ctx *DbContext
+}
+
+// Views returns the materialized views of the context
+//
+//	err := ctx.Views().Refresh("daily_stats", gontext.Concurrently)
+func (ctx *DbContext) Views() *Views {
+	return &Views{ctx: ctx}
+}
+
+// Refresh recomputes the rows of a materialized view, named by view or entity name
+// - PostgreSQL: REFRESH MATERIALIZED VIEW
+func (v *Views) Refresh(name string, options ...RefreshOption) error {
+	return v.RefreshContext(stdcontext.Background(), name, options...)
+}
+
+// RefreshContext is Refresh canceled when c is done
+func (v *Views) RefreshContext(c stdcontext.Context, name string, options ...RefreshOption) error {
+	for _, entity := range v.ctx.GetEntityModels() {
+		if entity.View != nil && (entity.View.Name == name || entity.Name == name) {
+			return v.refresh(c, entity.View, options)
+		}
+	}
+	return fmt.Errorf("no materialized view named %s is registered", name)
+}
+
+// RefreshAll refreshes every materialized view, each after the views it reads so they see fresh rows
+func (v *Views) RefreshAll(c stdcontext.Context, options ...RefreshOption) error {
+	sorted, _ := models.SortEntitiesByDependencies(v.ctx.GetEntityModels()) // On cycles keep the fallback order
+	for _, entity := range sorted {
+		if entity.View == nil {
+			continue
+		}
+		if err := v.refresh(c, entity.View, options); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refresh runs REFRESH MATERIALIZED VIEW for one view
+func (v *Views) refresh(c stdcontext.Context, view *models.ViewModel, options []RefreshOption) error {
+	if v.ctx.driver.Name() != "postgres" {
+		return fmt.Errorf("materialized views require PostgreSQL, not %s", v.ctx.driver.Name())
+	}
+	concurrently := ""
+	for _, option := range options {
+		if option == Concurrently {
+			concurrently = "CONCURRENTLY "
+		}
+	}
+	sql := fmt.Sprintf(`REFRESH MATERIALIZED VIEW %s"%s"`, concurrently, view.Name)
+	if err := v.ctx.db.WithContext(c).Exec(sql).Error; err != nil {
+		return fmt.Errorf("failed to refresh materialized view %s: %w", view.Name, dberrors.Translate(err))
+	}
+	return nil
+}
+
+// isView reports whether an entity type is mapped to a materialized view
+// It reads the registered list, so it can be called without taking mu
+func (ctx *DbContext) isView(entityType reflect.Type) bool {
+	if entityType.Kind() == reflect.Ptr {
+		entityType = entityType.Elem()
+	}
+	registered := ctx.registered.Load()
+	if registered == nil {
+		return false
+	}
+	for _, entityModel := range *registered {
+		if entityModel.Type == entityType {
+			return entityModel.View != nil
+		}
+	}
+	return false
+}
+
+// rejectViewChanges fails a save that would write rows of materialized views or query types, which are read-only
+func (ctx *DbContext) rejectViewChanges(pending []*EntityEntry) error {
+	for _, entry := range pending {
+		entityType := reflect.TypeOf(entry.Entity)
+		if entityType.Kind() == reflect.Ptr {
+			entityType = entityType.Elem()
+		}
+		if ctx.isView(entityType) {
+			return fmt.Errorf("%s is mapped to a materialized view and cannot be saved", entityType.Name())
+		}
+		if ctx.isQueryType(entityType) {
+			return fmt.Errorf("%s is a query type and cannot be saved", entityType.Name())
+		}
+	}
+	return nil
+}
+
+// ensureViews creates the materialized views for EnsureCreated, after the tables they read
+// The caller holds mu
+func (ctx *DbContext) ensureViews() {
+	sorted, _ := models.SortEntitiesByDependencies(ctx.entities) // On cycles keep the fallback order
+	for _, entity := range sorted {
+		if _, routed := ctx.routes[typeKey(entity.Type)]; entity.View == nil || routed {
+			continue
+		}
+		if ctx.driver.Name() != "postgres" {
+			log.Printf("Warning: materialized view %s is not created on %s", entity.View.Name, ctx.driver.Name())
+			continue
+		}
+		if err := ctx.db.Exec(models.CreateViewSQL(*entity.View)).Error; err != nil {
+			log.Printf("Warning: failed to create materialized view %s: %v", entity.View.Name, err)
+			continue
+		}
+		for _, declared := range entity.Indexes {
+			index := declared.Definition(entity)
+			if err := ctx.db.Exec(models.CreateIndexSQL(entity.TableName, index)).Error; err != nil {
+				log.Printf("Warning: failed to create index %s: %v", index.Name, err)
+			}
+		}
+	}
+}
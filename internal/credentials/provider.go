@@ -0,0 +1,228 @@
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/shepherrrd/gontext/internal/config"
+)
+
+// Credentials are database login details from a secret store
+// Empty fields keep the values of the connection string
+type Credentials struct {
+	User     string
+	Password string
+	Host     string
+	Port     int
+	Database string
+}
+
+// String masks the password so credentials can be logged safely
+func (c Credentials) String() string {
+	return fmt.Sprintf("{User:%s Password:***** Host:%s Port:%d Database:%s}", c.User, c.Host, c.Port, c.Database)
+}
+
+// Provider resolves database credentials when connections are opened, so production
+// passwords can live in a secrets manager instead of .env files
+type Provider interface {
+	Credentials(ctx context.Context) (Credentials, error)
+}
+
+// ProviderFunc adapts a function to Provider
+type ProviderFunc func(ctx context.Context) (Credentials, error)
+
+func (f ProviderFunc) Credentials(ctx context.Context) (Credentials, error) {
+	return f(ctx)
+}
+
+// SecretFetcher reads the string value of a secret; it plugs a cloud SDK client into a provider
+type SecretFetcher func(ctx context.Context, name string) (string, error)
+
+// ParseSecret reads a JSON secret in the AWS RDS layout ({"username", "password", "host", "port",
+// "dbname"}), a postgres:// URL, or libpq-style key=value pairs ("user=app password='p w' host=db");
+// any other value is taken as the password itself
+func ParseSecret(secret string) (Credentials, error) {
+	secret = strings.TrimSpace(secret)
+	switch {
+	case strings.HasPrefix(secret, "{"):
+		var values map[string]interface{}
+		if err := json.Unmarshal([]byte(secret), &values); err != nil {
+			return Credentials{}, fmt.Errorf("invalid JSON secret: %w", err)
+		}
+		return fromMap(values)
+	case strings.HasPrefix(secret, "postgres://"), strings.HasPrefix(secret, "postgresql://"):
+		builder, err := config.ParseConnectionString(secret)
+		if err != nil {
+			return Credentials{}, err
+		}
+		if builder.Password == "" {
+			return Credentials{}, fmt.Errorf("secret has no password")
+		}
+		return Credentials{User: builder.User, Password: builder.Password, Host: builder.Host,
+			Port: builder.Port, Database: builder.Database}, nil
+	}
+	if values, ok := parseKeyValues(secret); ok {
+		return fromMap(values)
+	}
+	return Credentials{Password: secret}, nil
+}
+
+// parseKeyValues reads whitespace-separated key=value pairs whose values may be single or double
+// quoted with backslash escapes. It reports false unless every field is a pair and one of them is the
+// password, so a bare password containing "=" (e.g. base64) is not mistaken for pairs
+func parseKeyValues(secret string) (map[string]interface{}, bool) {
+	values := make(map[string]interface{})
+	isSpace := func(c byte) bool { return c == ' ' || c == '\t' || c == '\n' || c == '\r' }
+	for i := 0; i < len(secret); {
+		for i < len(secret) && isSpace(secret[i]) {
+			i++
+		}
+		if i == len(secret) {
+			break
+		}
+
+		start := i
+		for i < len(secret) && (secret[i] == '_' || unicode.IsLetter(rune(secret[i])) || unicode.IsDigit(rune(secret[i]))) {
+			i++
+		}
+		key := strings.ToLower(secret[start:i])
+		for i < len(secret) && (secret[i] == ' ' || secret[i] == '\t') {
+			i++
+		}
+		if key == "" || i == len(secret) || secret[i] != '=' {
+			return nil, false
+		}
+		i++
+		for i < len(secret) && (secret[i] == ' ' || secret[i] == '\t') {
+			i++
+		}
+
+		var value strings.Builder
+		if i < len(secret) && (secret[i] == '\'' || secret[i] == '"') {
+			quote := secret[i]
+			for i++; i < len(secret) && secret[i] != quote; i++ {
+				if secret[i] == '\\' && i+1 < len(secret) {
+					i++
+				}
+				value.WriteByte(secret[i])
+			}
+			if i == len(secret) {
+				return nil, false // Unterminated quote
+			}
+			i++
+		} else {
+			for ; i < len(secret) && !isSpace(secret[i]); i++ {
+				if secret[i] == '\\' && i+1 < len(secret) {
+					i++
+				}
+				value.WriteByte(secret[i])
+			}
+		}
+		values[key] = value.String()
+	}
+	_, ok := values["password"]
+	return values, ok
+}
+
+// fromMap reads credentials from secret key/value data, accepting common key spellings
+func fromMap(values map[string]interface{}) (Credentials, error) {
+	text := func(keys ...string) string {
+		for _, key := range keys {
+			if value, exists := values[key]; exists && value != nil {
+				return fmt.Sprint(value)
+			}
+		}
+		return ""
+	}
+
+	credentials := Credentials{
+		User:     text("username", "user"),
+		Password: text("password"),
+		Host:     text("host"),
+		Database: text("dbname", "database"),
+	}
+	if port := text("port"); port != "" {
+		number, err := strconv.ParseFloat(port, 64)
+		if err != nil {
+			return Credentials{}, fmt.Errorf("invalid port %q in secret", port)
+		}
+		credentials.Port = int(number)
+	}
+	if credentials.Password == "" {
+		return Credentials{}, fmt.Errorf("secret has no password")
+	}
+	return credentials, nil
+}
+
+// Apply overrides the parts of a postgres:// connection string with the non-empty credentials
+func (c Credentials) Apply(connectionString string) (string, error) {
+	builder, err := config.ParseConnectionString(connectionString)
+	if err != nil {
+		return "", err
+	}
+	if c.User != "" {
+		builder.User = c.User
+	}
+	if c.Password != "" {
+		builder.Password = c.Password
+	}
+	if c.Host != "" {
+		builder.Host = c.Host
+	}
+	if c.Port != 0 {
+		builder.Port = c.Port
+	}
+	if c.Database != "" {
+		builder.Database = c.Database
+	}
+	return builder.String(), nil
+}
+
+// Cache keeps the latest credentials of a provider and fetches them again once they are older
+// than the refresh interval (0 = fetch once)
+// When a refresh fails, the previous credentials stay in use so a secrets-manager outage
+// does not take the database down with it
+type Cache struct {
+	provider Provider
+	refresh  time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	current Credentials
+	fetched time.Time
+}
+
+// NewCache wraps a provider
+func NewCache(provider Provider, refresh time.Duration) *Cache {
+	return &Cache{provider: provider, refresh: refresh, now: time.Now}
+}
+
+// Get returns cached credentials, fetching them when missing or stale
+func (c *Cache) Get(ctx context.Context) (Credentials, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stale := c.fetched.IsZero() || (c.refresh > 0 && c.now().Sub(c.fetched) >= c.refresh)
+	if !stale {
+		return c.current, nil
+	}
+
+	credentials, err := c.provider.Credentials(ctx)
+	if err != nil {
+		if !c.fetched.IsZero() {
+			log.Printf("Warning: credential refresh failed, using previous credentials: %v", err)
+			return c.current, nil
+		}
+		return Credentials{}, err
+	}
+	c.current = credentials
+	c.fetched = c.now()
+	return credentials, nil
+}
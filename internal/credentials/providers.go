@@ -0,0 +1,191 @@
+package credentials
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// EnvProvider reads the user and password from environment variables (default PGUSER / PGPASSWORD)
+type EnvProvider struct {
+	UserVar     string
+	PasswordVar string
+}
+
+func (p EnvProvider) Credentials(ctx context.Context) (Credentials, error) {
+	userVar, passwordVar := p.UserVar, p.PasswordVar
+	if userVar == "" {
+		userVar = "PGUSER"
+	}
+	if passwordVar == "" {
+		passwordVar = "PGPASSWORD"
+	}
+
+	password, exists := os.LookupEnv(passwordVar)
+	if !exists {
+		return Credentials{}, fmt.Errorf("environment variable %s is not set", passwordVar)
+	}
+	return Credentials{User: os.Getenv(userVar), Password: password}, nil
+}
+
+// FileProvider reads a mounted secret file (Docker / Kubernetes secrets) holding a password or a JSON secret
+// The file is read again on every refresh, so rotated mounts are picked up
+type FileProvider struct {
+	Path string
+}
+
+func (p FileProvider) Credentials(ctx context.Context) (Credentials, error) {
+	data, err := os.ReadFile(p.Path)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to read secret file: %w", err)
+	}
+	return ParseSecret(string(data))
+}
+
+// VaultProvider reads a HashiCorp Vault secret over its HTTP API: KV v1/v2 paths such as
+// "secret/data/app/db" or dynamic database credentials such as "database/creds/app"
+// Address and Token default to VAULT_ADDR and VAULT_TOKEN
+type VaultProvider struct {
+	Address string
+	Token   string
+	Path    string
+	Client  *http.Client
+}
+
+func (p VaultProvider) Credentials(ctx context.Context) (Credentials, error) {
+	address, token := p.Address, p.Token
+	if address == "" {
+		address = os.Getenv("VAULT_ADDR")
+	}
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+	if address == "" || token == "" {
+		return Credentials{}, fmt.Errorf("vault address and token are required (VAULT_ADDR, VAULT_TOKEN)")
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(address, "/")+"/v1/"+strings.TrimLeft(p.Path, "/"), nil)
+	if err != nil {
+		return Credentials{}, err
+	}
+	request.Header.Set("X-Vault-Token", token)
+
+	var response struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := doJSON(p.Client, request, &response); err != nil {
+		return Credentials{}, fmt.Errorf("failed to read vault secret %s: %w", p.Path, err)
+	}
+
+	// KV v2 nests the secret in data.data
+	data := response.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		data = nested
+	}
+	return fromMap(data)
+}
+
+// AWSSecretsManagerProvider reads an AWS Secrets Manager secret, typically the JSON secret RDS manages
+// Fetch plugs in the AWS SDK so the module does not depend on it:
+//
+//	Fetch: func(ctx context.Context, id string) (string, error) {
+//	    out, err := client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: &id})
+//	    if err != nil { return "", err }
+//	    return *out.SecretString, nil
+//	}
+type AWSSecretsManagerProvider struct {
+	SecretID string
+	Fetch    SecretFetcher
+}
+
+func (p AWSSecretsManagerProvider) Credentials(ctx context.Context) (Credentials, error) {
+	if p.Fetch == nil {
+		return Credentials{}, fmt.Errorf("AWSSecretsManagerProvider requires Fetch (e.g. secretsmanager.Client.GetSecretValue)")
+	}
+	secret, err := p.Fetch(ctx, p.SecretID)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to read AWS secret %s: %w", p.SecretID, err)
+	}
+	return ParseSecret(secret)
+}
+
+// GCPSecretManagerProvider reads a Google Secret Manager version such as
+// "projects/my-project/secrets/db-password/versions/latest"
+// Without Fetch it calls the REST API with the service account token of the GCE/GKE/Cloud Run metadata server
+type GCPSecretManagerProvider struct {
+	Name   string
+	Fetch  SecretFetcher
+	Client *http.Client
+}
+
+func (p GCPSecretManagerProvider) Credentials(ctx context.Context) (Credentials, error) {
+	fetch := p.Fetch
+	if fetch == nil {
+		fetch = p.fetchWithMetadataToken
+	}
+	secret, err := fetch(ctx, p.Name)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to read GCP secret %s: %w", p.Name, err)
+	}
+	return ParseSecret(secret)
+}
+
+func (p GCPSecretManagerProvider) fetchWithMetadataToken(ctx context.Context, name string) (string, error) {
+	tokenRequest, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		"http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token", nil)
+	if err != nil {
+		return "", err
+	}
+	tokenRequest.Header.Set("Metadata-Flavor", "Google")
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := doJSON(p.Client, tokenRequest, &token); err != nil {
+		return "", fmt.Errorf("failed to get metadata server token: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://secretmanager.googleapis.com/v1/"+name+":access", nil)
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	var response struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	if err := doJSON(p.Client, request, &response); err != nil {
+		return "", err
+	}
+	secret, err := base64.StdEncoding.DecodeString(response.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("invalid secret payload: %w", err)
+	}
+	return string(secret), nil
+}
+
+// doJSON sends a request and decodes a successful JSON response
+func doJSON(client *http.Client, request *http.Request, target interface{}) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("%s: %s", response.Status, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, target)
+}
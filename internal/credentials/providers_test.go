@@ -0,0 +1,171 @@
+package credentials
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVaultProviderUnwrapsSecrets(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		body string
+		want Credentials
+	}{
+		{
+			name: "kv v1",
+			path: "secret/app/db",
+			body: `{"lease_duration": 2764800, "data": {"username": "app", "password": "v1-secret", "port": "5433"}}`,
+			want: Credentials{User: "app", Password: "v1-secret", Port: 5433},
+		},
+		{
+			name: "kv v2",
+			path: "secret/data/app/db",
+			body: `{"data": {"data": {"user": "app", "password": "v2-secret", "host": "db.internal", "dbname": "shop"},
+				"metadata": {"created_time": "2024-01-02T03:04:05Z", "version": 3}}}`,
+			want: Credentials{User: "app", Password: "v2-secret", Host: "db.internal", Database: "shop"},
+		},
+		{
+			name: "dynamic database credentials",
+			path: "/database/creds/app",
+			body: `{"lease_id": "database/creds/app/abc", "renewable": true, "lease_duration": 3600,
+				"data": {"username": "v-token-app-x1", "password": "A1a-generated"}}`,
+			want: Credentials{User: "v-token-app-x1", Password: "A1a-generated"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if want := "/v1/" + strings.TrimLeft(tt.path, "/"); r.URL.Path != want {
+					t.Errorf("requested %s, want %s", r.URL.Path, want)
+				}
+				if token := r.Header.Get("X-Vault-Token"); token != "s.root" {
+					t.Errorf("sent token %q, want s.root", token)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			got, err := VaultProvider{Address: server.URL + "/", Token: "s.root", Path: tt.path, Client: server.Client()}.Credentials(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Credentials() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVaultProviderErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/secret/data/missing" {
+			http.Error(w, `{"errors":[]}`, http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"data": {"data": {"username": "app"}}}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		provider VaultProvider
+		err      string
+	}{
+		{"missing secret", VaultProvider{Address: server.URL, Token: "t", Path: "secret/data/missing"}, "404 Not Found"},
+		{"no password", VaultProvider{Address: server.URL, Token: "t", Path: "secret/data/app"}, "secret has no password"},
+		{"no token", VaultProvider{Address: server.URL, Path: "secret/data/app"}, "vault address and token are required"},
+	}
+	t.Setenv("VAULT_TOKEN", "")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.provider.Credentials(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Credentials() error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestVaultProviderReadsEnvironment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "s.env" {
+			http.Error(w, "permission denied", http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"data": {"password": "from-env"}}`))
+	}))
+	defer server.Close()
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_TOKEN", "s.env")
+
+	got, err := VaultProvider{Path: "secret/app"}.Credentials(context.Background())
+	if err != nil || got.Password != "from-env" {
+		t.Errorf("Credentials() = %+v, %v; want the secret read with VAULT_ADDR and VAULT_TOKEN", got, err)
+	}
+}
+
+// redirect sends every request of the client to server, whatever its host
+type redirect struct{ server *httptest.Server }
+
+func (r redirect) RoundTrip(request *http.Request) (*http.Response, error) {
+	target, _ := url.Parse(r.server.URL)
+	request = request.Clone(request.Context())
+	request.URL.Scheme, request.URL.Host = target.Scheme, target.Host
+	return http.DefaultTransport.RoundTrip(request)
+}
+
+func TestGCPSecretManagerProviderUsesMetadataToken(t *testing.T) {
+	name := "projects/shop/secrets/db/versions/latest"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/computeMetadata/v1/instance/service-accounts/default/token":
+			if r.Header.Get("Metadata-Flavor") != "Google" {
+				http.Error(w, "missing Metadata-Flavor", http.StatusForbidden)
+				return
+			}
+			w.Write([]byte(`{"access_token": "ya29.token", "expires_in": 3599, "token_type": "Bearer"}`))
+		case "/v1/" + name + ":access":
+			if r.Header.Get("Authorization") != "Bearer ya29.token" {
+				http.Error(w, "unauthenticated", http.StatusUnauthorized)
+				return
+			}
+			payload := base64.StdEncoding.EncodeToString([]byte(`{"username": "app", "password": "gcp-secret"}`))
+			w.Write([]byte(`{"name": "` + name + `", "payload": {"data": "` + payload + `"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	provider := GCPSecretManagerProvider{Name: name, Client: &http.Client{Transport: redirect{server}}}
+	got, err := provider.Credentials(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Credentials{User: "app", Password: "gcp-secret"}); got != want {
+		t.Errorf("Credentials() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFileProviderReadsRotatedSecret(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db-password")
+	provider := FileProvider{Path: path}
+	for _, password := range []string{"first", "rotated"} {
+		if err := os.WriteFile(path, []byte(password+"\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := provider.Credentials(context.Background()); err != nil || got.Password != password {
+			t.Errorf("Credentials() = %+v, %v; want password %q", got, err, password)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package credentials
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenProvider returns a short-lived token used as the password of every new connection,
+// e.g. AWS RDS IAM authentication or Azure AD tokens
+type TokenProvider interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// TokenProviderFunc adapts a function to TokenProvider
+type TokenProviderFunc func(ctx context.Context) (string, error)
+
+func (f TokenProviderFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// AWSCredentials are the keys used to sign RDS IAM tokens
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// RDSIAMTokenProvider generates RDS IAM authentication tokens (valid for 15 minutes) - the equivalent of
+// the AWS SDK's auth.BuildAuthToken, signed locally so every new connection gets a fresh token
+// Credentials defaults to AWS_ACCESS_KEY_ID / AWS_SECRET_ACCESS_KEY / AWS_SESSION_TOKEN; plug in the SDK's
+// credential chain for instance or task roles. RDS requires TLS for IAM authentication
+type RDSIAMTokenProvider struct {
+	Endpoint    string // host:port of the instance
+	Region      string // Defaults to AWS_REGION
+	User        string // Database user granted rds_iam
+	Credentials func(ctx context.Context) (AWSCredentials, error)
+	Now         func() time.Time // Signing time, defaults to time.Now
+}
+
+func (p RDSIAMTokenProvider) Token(ctx context.Context) (string, error) {
+	region := p.Region
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if p.Endpoint == "" || region == "" || p.User == "" {
+		return "", fmt.Errorf("RDS IAM token requires Endpoint (host:port), Region and User")
+	}
+	if !strings.Contains(p.Endpoint, ":") {
+		return "", fmt.Errorf("RDS IAM endpoint %q must include the port", p.Endpoint)
+	}
+
+	credentials, err := p.awsCredentials(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now
+	if p.Now != nil {
+		now = p.Now
+	}
+	timestamp := now().UTC()
+	amzDate := timestamp.Format("20060102T150405Z")
+	date := timestamp.Format("20060102")
+	scope := fmt.Sprintf("%s/%s/rds-db/aws4_request", date, region)
+
+	query := map[string]string{
+		"Action":              "connect",
+		"DBUser":              p.User,
+		"X-Amz-Algorithm":     "AWS4-HMAC-SHA256",
+		"X-Amz-Credential":    credentials.AccessKeyID + "/" + scope,
+		"X-Amz-Date":          amzDate,
+		"X-Amz-Expires":       "900",
+		"X-Amz-SignedHeaders": "host",
+	}
+	if credentials.SessionToken != "" {
+		query["X-Amz-Security-Token"] = credentials.SessionToken
+	}
+	canonicalQuery := canonicalQueryString(query)
+
+	emptyHash := sha256.Sum256(nil)
+	canonicalRequest := strings.Join([]string{
+		"GET", "/", canonicalQuery, "host:" + p.Endpoint + "\n", "host", hex.EncodeToString(emptyHash[:]),
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hex.EncodeToString(requestHash[:])}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+credentials.SecretAccessKey), date)
+	for _, part := range []string{region, "rds-db", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	// Like BuildAuthToken, the token is the presigned URL without its scheme or path
+	return p.Endpoint + "?" + canonicalQuery + "&X-Amz-Signature=" + signature, nil
+}
+
+func (p RDSIAMTokenProvider) awsCredentials(ctx context.Context) (AWSCredentials, error) {
+	if p.Credentials != nil {
+		return p.Credentials(ctx)
+	}
+	credentials := AWSCredentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if credentials.AccessKeyID == "" || credentials.SecretAccessKey == "" {
+		return AWSCredentials{}, fmt.Errorf("AWS credentials not found (set AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY or Credentials)")
+	}
+	return credentials, nil
+}
+
+// canonicalQueryString sorts and RFC 3986 encodes query parameters as SigV4 requires
+func canonicalQueryString(values map[string]string) string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = sigV4Escape(key) + "=" + sigV4Escape(values[key])
+	}
+	return strings.Join(parts, "&")
+}
+
+func sigV4Escape(value string) string {
+	// QueryEscape encodes spaces as + and leaves ~ alone; SigV4 wants %20 and the unreserved set only
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// AzureADTokenProvider gets Azure AD access tokens for Azure Database for PostgreSQL from the managed
+// identity endpoint (IMDS) of the VM, App Service or AKS pod, caching each token until shortly before expiry
+// Use a pointer so the cache is shared: TokenProvider: &gontext.AzureADTokenProvider{}
+type AzureADTokenProvider struct {
+	ClientID string // User-assigned identity; empty uses the system-assigned identity
+	Resource string // Defaults to https://ossrdbms-aad.database.windows.net
+	Client   *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func (p *AzureADTokenProvider) Token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token != "" && time.Until(p.expires) > 5*time.Minute {
+		return p.token, nil
+	}
+
+	resource := p.Resource
+	if resource == "" {
+		resource = "https://ossrdbms-aad.database.windows.net"
+	}
+	query := url.Values{"api-version": {"2018-02-01"}, "resource": {resource}}
+	if p.ClientID != "" {
+		query.Set("client_id", p.ClientID)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		"http://169.254.169.254/metadata/identity/oauth2/token?"+query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Metadata", "true")
+
+	var response struct {
+		AccessToken string      `json:"access_token"`
+		ExpiresOn   json.Number `json:"expires_on"`
+	}
+	if err := doJSON(p.Client, request, &response); err != nil {
+		return "", fmt.Errorf("failed to get Azure AD token: %w", err)
+	}
+
+	p.token = response.AccessToken
+	p.expires = time.Now().Add(time.Hour)
+	if seconds, err := strconv.ParseInt(response.ExpiresOn.String(), 10, 64); err == nil {
+		p.expires = time.Unix(seconds, 0)
+	}
+	return p.token, nil
+}
@@ -0,0 +1,181 @@
+package dberrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Sentinel error kinds - match with errors.Is(err, dberrors.ErrUniqueViolation)
+var (
+	ErrUniqueViolation     = errors.New("unique constraint violation")
+	ErrForeignKeyViolation = errors.New("foreign key constraint violation")
+	ErrNotFound            = errors.New("record not found")
+	ErrConcurrency         = errors.New("concurrency conflict: the entity was modified or deleted by another operation")
+	// ErrSerializationFailure marks transactions the database aborted to keep isolation (serialization failures, deadlocks)
+	// The whole transaction can safely be retried
+	ErrSerializationFailure = errors.New("serialization failure: the transaction conflicted with a concurrent transaction")
+	// ErrTimeout marks statements cancelled by a statement timeout or context deadline
+	ErrTimeout = errors.New("statement timeout: the query was cancelled")
+	// ErrUnsafeSQL marks raw conditions rejected by strict SQL mode before reaching the database
+	ErrUnsafeSQL = errors.New("unsafe SQL rejected by strict mode")
+)
+
+// DbError carries the error kind plus whatever constraint details the driver reported
+// Use errors.As(err, &dbErr) to read Constraint/Table/Column; the driver error stays reachable via Unwrap
+type DbError struct {
+	Kind       error
+	Constraint string
+	Table      string
+	Column     string
+	Err        error
+}
+
+func (e *DbError) Error() string {
+	switch {
+	case e.Constraint != "":
+		return fmt.Sprintf("%v (constraint %s): %v", e.Kind, e.Constraint, e.Err)
+	case e.Err != nil:
+		return fmt.Sprintf("%v: %v", e.Kind, e.Err)
+	default:
+		return e.Kind.Error()
+	}
+}
+
+// Is reports whether target is this error's kind
+func (e *DbError) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *DbError) Unwrap() error {
+	return e.Err
+}
+
+// PostgreSQL SQLSTATE codes
+const (
+	pgUniqueViolation      = "23505"
+	pgForeignKeyViolation  = "23503"
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+	pgQueryCanceled        = "57014"
+)
+
+var (
+	pgConstraintPattern      = regexp.MustCompile(`constraint "([^"]+)"`)
+	pgKeyColumnPattern       = regexp.MustCompile(`Key \(([^)]+)\)`)
+	sqliteUniquePattern      = regexp.MustCompile(`UNIQUE constraint failed: ([^\s,]+)`)
+	mysqlDuplicateKeyPattern = regexp.MustCompile(`for key '([^']+)'`)
+)
+
+// Translate converts a driver or GORM error into a *DbError when its kind is recognised
+// Unrecognised errors are returned unchanged
+func Translate(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var dbErr *DbError
+	if errors.As(err, &dbErr) {
+		return err
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &DbError{Kind: ErrNotFound, Err: err}
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &DbError{Kind: ErrTimeout, Err: err}
+	}
+
+	// PostgreSQL (pgconn.PgError) exposes SQLState() plus constraint details
+	var sqlStateErr interface{ SQLState() string }
+	if errors.As(err, &sqlStateErr) {
+		switch sqlStateErr.SQLState() {
+		case pgUniqueViolation:
+			return withPgDetails(&DbError{Kind: ErrUniqueViolation, Err: err}, sqlStateErr)
+		case pgForeignKeyViolation:
+			return withPgDetails(&DbError{Kind: ErrForeignKeyViolation, Err: err}, sqlStateErr)
+		case pgSerializationFailure, pgDeadlockDetected:
+			return &DbError{Kind: ErrSerializationFailure, Err: err}
+		case pgQueryCanceled:
+			return &DbError{Kind: ErrTimeout, Err: err}
+		}
+	}
+
+	return translateMessage(err)
+}
+
+// withPgDetails copies ConstraintName/TableName/ColumnName from the driver error
+// Read via reflection so the pgx driver is not a dependency of this package
+func withPgDetails(dbErr *DbError, driverErr interface{}) *DbError {
+	value := reflect.Indirect(reflect.ValueOf(driverErr))
+	if value.Kind() != reflect.Struct {
+		return dbErr
+	}
+
+	stringField := func(name string) string {
+		if field := value.FieldByName(name); field.IsValid() && field.Kind() == reflect.String {
+			return field.String()
+		}
+		return ""
+	}
+
+	dbErr.Constraint = stringField("ConstraintName")
+	dbErr.Table = stringField("TableName")
+	dbErr.Column = stringField("ColumnName")
+	if dbErr.Column == "" {
+		// Unique violations only report the column in the detail: Key ("Email")=(a@b.com) already exists.
+		if match := pgKeyColumnPattern.FindStringSubmatch(stringField("Detail")); match != nil {
+			dbErr.Column = strings.Trim(match[1], `"`)
+		}
+	}
+	return dbErr
+}
+
+// translateMessage recognises errors by message for drivers without structured errors (MySQL, SQLite)
+// and for GORM's own translated errors
+func translateMessage(err error) error {
+	message := err.Error()
+
+	switch {
+	case errors.Is(err, gorm.ErrDuplicatedKey),
+		strings.Contains(message, "duplicate key value violates unique constraint"),
+		strings.Contains(message, "Error 1062"),
+		strings.Contains(message, "UNIQUE constraint failed"):
+		dbErr := &DbError{Kind: ErrUniqueViolation, Err: err}
+		if match := pgConstraintPattern.FindStringSubmatch(message); match != nil {
+			dbErr.Constraint = match[1]
+		} else if match := mysqlDuplicateKeyPattern.FindStringSubmatch(message); match != nil {
+			dbErr.Constraint = match[1]
+		} else if match := sqliteUniquePattern.FindStringSubmatch(message); match != nil {
+			// SQLite reports table.column
+			if parts := strings.SplitN(match[1], ".", 2); len(parts) == 2 {
+				dbErr.Table, dbErr.Column = parts[0], parts[1]
+			}
+		}
+		return dbErr
+
+	case errors.Is(err, gorm.ErrForeignKeyViolated),
+		strings.Contains(message, "violates foreign key constraint"),
+		strings.Contains(message, "Error 1451"),
+		strings.Contains(message, "Error 1452"),
+		strings.Contains(message, "FOREIGN KEY constraint failed"):
+		dbErr := &DbError{Kind: ErrForeignKeyViolation, Err: err}
+		if match := pgConstraintPattern.FindStringSubmatch(message); match != nil {
+			dbErr.Constraint = match[1]
+		}
+		return dbErr
+
+	case strings.Contains(message, "could not serialize access"),
+		strings.Contains(message, "deadlock detected"),
+		strings.Contains(message, "Error 1213"), // MySQL: deadlock found when trying to get lock
+		strings.Contains(message, "database is locked"):
+		return &DbError{Kind: ErrSerializationFailure, Err: err}
+	}
+
+	return err
+}
@@ -0,0 +1,124 @@
+package dberrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// pgError mirrors the fields of pgconn.PgError that Translate reads
+type pgError struct {
+	Code           string
+	Message        string
+	Detail         string
+	TableName      string
+	ColumnName     string
+	ConstraintName string
+}
+
+func (e *pgError) Error() string    { return "ERROR: " + e.Message + " (SQLSTATE " + e.Code + ")" }
+func (e *pgError) SQLState() string { return e.Code }
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want DbError // Err is not compared
+	}{
+		{
+			name: "postgres unique violation",
+			err: &pgError{Code: "23505", Message: `duplicate key value violates unique constraint "ix_users_email"`,
+				Detail: `Key ("Email")=(ada@example.com) already exists.`, TableName: "users", ConstraintName: "ix_users_email"},
+			want: DbError{Kind: ErrUniqueViolation, Constraint: "ix_users_email", Table: "users", Column: "Email"},
+		},
+		{
+			name: "postgres foreign key violation",
+			err: fmt.Errorf("failed to save: %w", &pgError{Code: "23503", Message: `insert or update on table "posts" violates foreign key constraint "fk_posts_author"`,
+				TableName: "posts", ConstraintName: "fk_posts_author"}),
+			want: DbError{Kind: ErrForeignKeyViolation, Constraint: "fk_posts_author", Table: "posts"},
+		},
+		{"postgres serialization failure", &pgError{Code: "40001", Message: "could not serialize access"}, DbError{Kind: ErrSerializationFailure}},
+		{"postgres deadlock", &pgError{Code: "40P01", Message: "deadlock detected"}, DbError{Kind: ErrSerializationFailure}},
+		{"postgres statement timeout", &pgError{Code: "57014", Message: "canceling statement due to statement timeout"}, DbError{Kind: ErrTimeout}},
+		{
+			name: "mysql duplicate entry",
+			err:  errors.New("Error 1062 (23000): Duplicate entry 'ada@example.com' for key 'users.ix_users_email'"),
+			want: DbError{Kind: ErrUniqueViolation, Constraint: "users.ix_users_email"},
+		},
+		{
+			name: "mysql foreign key violation",
+			err:  errors.New("Error 1452 (23000): Cannot add or update a child row: a foreign key constraint fails"),
+			want: DbError{Kind: ErrForeignKeyViolation},
+		},
+		{"mysql deadlock", errors.New("Error 1213 (40001): Deadlock found when trying to get lock"), DbError{Kind: ErrSerializationFailure}},
+		{
+			name: "sqlite unique violation",
+			err:  errors.New("UNIQUE constraint failed: users.email"),
+			want: DbError{Kind: ErrUniqueViolation, Table: "users", Column: "email"},
+		},
+		{"sqlite foreign key violation", errors.New("FOREIGN KEY constraint failed"), DbError{Kind: ErrForeignKeyViolation}},
+		{"sqlite busy", errors.New("database is locked"), DbError{Kind: ErrSerializationFailure}},
+		{"gorm not found", fmt.Errorf("load user: %w", gorm.ErrRecordNotFound), DbError{Kind: ErrNotFound}},
+		{"gorm duplicated key", gorm.ErrDuplicatedKey, DbError{Kind: ErrUniqueViolation}},
+		{"gorm foreign key", gorm.ErrForeignKeyViolated, DbError{Kind: ErrForeignKeyViolation}},
+		{"deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), DbError{Kind: ErrTimeout}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Translate(tt.err)
+			var dbErr *DbError
+			if !errors.As(err, &dbErr) {
+				t.Fatalf("Translate() = %#v, want a *DbError", err)
+			}
+			if !errors.Is(err, tt.want.Kind) {
+				t.Errorf("errors.Is(err, %v) = false", tt.want.Kind)
+			}
+			if dbErr.Constraint != tt.want.Constraint || dbErr.Table != tt.want.Table || dbErr.Column != tt.want.Column {
+				t.Errorf("details constraint=%q table=%q column=%q, want %q %q %q", dbErr.Constraint, dbErr.Table, dbErr.Column,
+					tt.want.Constraint, tt.want.Table, tt.want.Column)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Error("the driver error is not reachable through Unwrap")
+			}
+		})
+	}
+}
+
+func TestTranslateLeavesOtherErrors(t *testing.T) {
+	if err := Translate(nil); err != nil {
+		t.Errorf("Translate(nil) = %v", err)
+	}
+	syntax := &pgError{Code: "42601", Message: `syntax error at or near "SELEC"`}
+	if err := Translate(syntax); err != syntax {
+		t.Errorf("Translate() of a syntax error = %#v, want it unchanged", err)
+	}
+	translated := &DbError{Kind: ErrConcurrency}
+	wrapped := fmt.Errorf("save: %w", translated)
+	if err := Translate(wrapped); err != wrapped {
+		t.Errorf("Translate() of a translated error = %#v, want it unchanged", err)
+	}
+}
+
+func TestDbErrorMessage(t *testing.T) {
+	cause := errors.New("UNIQUE constraint failed: users.email")
+	tests := []struct {
+		err  *DbError
+		want string
+	}{
+		{&DbError{Kind: ErrUniqueViolation, Constraint: "ix_users_email", Err: cause}, "unique constraint violation (constraint ix_users_email): UNIQUE constraint failed: users.email"},
+		{&DbError{Kind: ErrUniqueViolation, Err: cause}, "unique constraint violation: UNIQUE constraint failed: users.email"},
+		{&DbError{Kind: ErrConcurrency}, ErrConcurrency.Error()},
+	}
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Error() = %q, want %q", got, tt.want)
+		}
+	}
+	if errors.Is(&DbError{Kind: ErrNotFound}, ErrUniqueViolation) {
+		t.Error("a not-found error matched ErrUniqueViolation")
+	}
+}
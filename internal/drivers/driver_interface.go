@@ -1,8 +1,15 @@
 package drivers
 
 import (
+	"context"
+	"crypto/tls"
 	"database/sql"
+	"strings"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/shepherrrd/gontext/internal/types"
 )
 
 type DatabaseDriver interface {
@@ -12,9 +19,85 @@ type DatabaseDriver interface {
 	GetSQLDB(db *gorm.DB) (*sql.DB, error)
 	MapGoTypeToSQL(goType string) string
 	SupportsTransactions() bool
+	SupportsReturning() bool
 	GetSchemaInformationQuery() string
 }
 
+// TimestampType selects the column type of time.Time fields on databases that have both a zoned and
+// an unzoned timestamp type (PostgreSQL)
+type TimestampType int
+
+const (
+	// TimestampDefault keeps each path's historical type: timestamp in generated migrations and
+	// timestamptz in AutoMigrate/EnsureCreated
+	TimestampDefault TimestampType = iota
+	// TimestampWithTimeZone stores instants (timestamptz); values are read back in the session time zone
+	TimestampWithTimeZone
+	// TimestampWithoutTimeZone stores the wall clock alone (timestamp); pair it with UTC normalization
+	TimestampWithoutTimeZone
+)
+
+// TimestampTyper is implemented by drivers whose time.Time column type can be chosen
+type TimestampTyper interface {
+	SetTimestampType(timestampType TimestampType)
+}
+
+// CredentialsFunc returns the user and password for a new connection; an empty user keeps the
+// connection string's user
+type CredentialsFunc func(ctx context.Context) (user string, password string, err error)
+
+// ConnectOptions customises how physical connections are opened
+type ConnectOptions struct {
+	Credentials CredentialsFunc // Called before every new connection, nil keeps the connection string's login
+	TLS         *tls.Config     // Custom CA / client certificates, nil uses sslmode
+}
+
+// OptionsConnector is implemented by drivers that support ConnectOptions
+type OptionsConnector interface {
+	ConnectWithOptions(connectionString string, logLevel string, options ConnectOptions) (*gorm.DB, error)
+}
+
+// StatementDialect is implemented by drivers to generate the statements whose SQL differs between
+// databases - upserts, UPDATE/DELETE limited by Take/Skip and pagination - so query code never
+// branches on the database name
+type StatementDialect interface {
+	// UpsertClause makes an INSERT update updateColumns of the row it conflicts with on conflictColumns,
+	// or skip the row when updateColumns is empty
+	UpsertClause(conflictColumns []string, updateColumns []string) clause.Expression
+	// SupportsMutationLimit reports whether UPDATE and DELETE take ORDER BY and LIMIT themselves
+	SupportsMutationLimit() bool
+	// KeySubquery returns what a limited UPDATE or DELETE matches keyColumn against, given the limited
+	// SELECT of the keys of the rows to change
+	KeySubquery(keys *gorm.DB, keyColumn string) interface{}
+	// Pagination returns the LIMIT clause the database accepts for limit
+	Pagination(limit clause.Limit) clause.Limit
+}
+
+// DialectFor returns the statement dialect of a GORM dialector name, for queries built without a
+// context; unknown databases get PostgreSQL's standard SQL
+func DialectFor(name string) StatementDialect {
+	switch name {
+	case "mysql":
+		return &MySQLDriver{}
+	case "sqlite":
+		return &SQLiteDriver{}
+	default:
+		return &PostgreSQLDriver{}
+	}
+}
+
+// onConflict is the standard ON CONFLICT (...) DO UPDATE / DO NOTHING upsert of PostgreSQL and SQLite
+func onConflict(conflictColumns []string, updateColumns []string) clause.OnConflict {
+	columns := make([]clause.Column, len(conflictColumns))
+	for i, name := range conflictColumns {
+		columns[i] = clause.Column{Name: name}
+	}
+	if len(updateColumns) == 0 {
+		return clause.OnConflict{Columns: columns, DoNothing: true}
+	}
+	return clause.OnConflict{Columns: columns, DoUpdates: clause.AssignmentColumns(updateColumns)}
+}
+
 type ColumnInfo struct {
 	Name         string
 	DataType     string
@@ -22,4 +105,38 @@ type ColumnInfo struct {
 	IsPrimary    bool
 	DefaultValue *string
 	MaxLength    *int
-}
\ No newline at end of file
+}
+// nullableBaseTypes maps database/sql null wrappers to the Go type they hold
+var nullableBaseTypes = map[string]string{
+	"sql.NullString":  "string",
+	"sql.NullInt64":   "int64",
+	"sql.NullInt32":   "int32",
+	"sql.NullInt16":   "int32",
+	"sql.NullByte":    "int32",
+	"sql.NullBool":    "bool",
+	"sql.NullFloat64": "float64",
+	"sql.NullTime":    "time.Time",
+}
+
+// customColumnType returns the column type registered for goType with types.Register, if any
+func customColumnType(driverName, goType string) (string, bool) {
+	mapping, ok := types.LookupName(baseGoType(goType))
+	if !ok {
+		return "", false
+	}
+	columnType := mapping.ColumnTypeFor(driverName)
+	return columnType, columnType != ""
+}
+
+// baseGoType strips nullability from a Go type name so *int64, sql.NullInt64 and sql.Null[int64]
+// map to the same column type as int64; the column's NULL constraint comes from IsNullable
+func baseGoType(goType string) string {
+	goType = strings.TrimLeft(goType, "*")
+	if base, ok := nullableBaseTypes[goType]; ok {
+		return base
+	}
+	if strings.HasPrefix(goType, "sql.Null[") && strings.HasSuffix(goType, "]") {
+		return baseGoType(goType[len("sql.Null[") : len(goType)-1])
+	}
+	return goType
+}
@@ -3,12 +3,14 @@ package drivers
 import (
 	"database/sql"
 	"log"
+	"math"
 	"os"
 	"strings"
 	"time"
 
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
 )
 
@@ -77,7 +79,49 @@ func (m *MySQLDriver) SupportsTransactions() bool {
 	return true
 }
 
+// SupportsReturning is false for MySQL; generated values are read back with a follow-up SELECT
+func (m *MySQLDriver) SupportsReturning() bool {
+	return false
+}
+
+// UpsertClause generates INSERT ... ON DUPLICATE KEY UPDATE column = VALUES(column), which MySQL and
+// MariaDB run on a conflict with any unique key: conflictColumns cannot narrow it. With nothing to
+// update the key is assigned to itself, the usual way to skip duplicates without INSERT IGNORE
+func (m *MySQLDriver) UpsertClause(conflictColumns []string, updateColumns []string) clause.Expression {
+	if len(updateColumns) == 0 {
+		return clause.OnConflict{DoNothing: true}
+	}
+	return clause.OnConflict{DoUpdates: clause.AssignmentColumns(updateColumns)}
+}
+
+// SupportsMutationLimit is true: UPDATE and DELETE accept ORDER BY and LIMIT, though not OFFSET
+func (m *MySQLDriver) SupportsMutationLimit() bool {
+	return true
+}
+
+// KeySubquery wraps the keys in a derived table: MySQL rejects LIMIT in an IN subquery and a subquery
+// reading the table being changed, but accepts both once the keys are materialized
+func (m *MySQLDriver) KeySubquery(keys *gorm.DB, keyColumn string) interface{} {
+	return clause.Expr{
+		SQL:  "SELECT ? FROM (?) AS ?",
+		Vars: []interface{}{clause.Column{Name: keyColumn}, keys, clause.Table{Name: "gontext_keys"}},
+	}
+}
+
+// Pagination adds the largest LIMIT to an OFFSET without one, which MySQL's grammar requires
+func (m *MySQLDriver) Pagination(limit clause.Limit) clause.Limit {
+	if limit.Limit == nil && limit.Offset > 0 {
+		unbounded := math.MaxInt
+		limit.Limit = &unbounded
+	}
+	return limit
+}
+
 func (m *MySQLDriver) MapGoTypeToSQL(goType string) string {
+	if columnType, ok := customColumnType(m.Name(), goType); ok {
+		return columnType
+	}
+	goType = baseGoType(goType)
 	switch {
 	case strings.Contains(goType, "uuid.UUID"):
 		return "CHAR(36)"
@@ -89,6 +133,8 @@ func (m *MySQLDriver) MapGoTypeToSQL(goType string) string {
 		return "INT"
 	case goType == "int64":
 		return "BIGINT"
+	case goType == "uint", goType == "uint32", goType == "uint64":
+		return "BIGINT UNSIGNED"
 	case goType == "bool":
 		return "TINYINT(1)"
 	case goType == "float64":
@@ -1,20 +1,26 @@
 package drivers
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"log"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
 	"github.com/shepherrrd/gontext/internal/query"
 )
 
 type PostgreSQLDriver struct{
-	plugin *query.PostgreSQLPlugin
+	plugin     *query.PostgreSQLPlugin
+	timestamps TimestampType
 }
 
 func NewPostgreSQLDriver() *PostgreSQLDriver {
@@ -32,6 +38,52 @@ func (p *PostgreSQLDriver) Connect(connectionString string) (*gorm.DB, error) {
 }
 
 func (p *PostgreSQLDriver) ConnectWithLogger(connectionString string, logLevel string) (*gorm.DB, error) {
+	return p.open(postgres.Open(connectionString), logLevel)
+}
+
+// ConnectWithOptions opens a pool with custom TLS and credentials fetched before every new physical
+// connection, so rotated secrets and short-lived tokens apply without restarting the application
+func (p *PostgreSQLDriver) ConnectWithOptions(connectionString string, logLevel string, options ConnectOptions) (*gorm.DB, error) {
+	connConfig, err := pgx.ParseConfig(connectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.TLS != nil {
+		tlsConfig := options.TLS.Clone()
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName = connConfig.Host
+		}
+		// Explicit TLS replaces sslmode's plaintext fallbacks
+		connConfig.TLSConfig = tlsConfig
+		connConfig.Fallbacks = nil
+	}
+
+	var openOptions []stdlib.OptionOpenDB
+	if options.Credentials != nil {
+		openOptions = append(openOptions, stdlib.OptionBeforeConnect(func(ctx context.Context, config *pgx.ConnConfig) error {
+			user, password, err := options.Credentials(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get database credentials: %w", err)
+			}
+			if user != "" {
+				config.User = user
+			}
+			config.Password = password
+			return nil
+		}))
+	}
+	sqlDB := stdlib.OpenDB(*connConfig, openOptions...)
+
+	db, err := p.open(postgres.New(postgres.Config{Conn: sqlDB}), logLevel)
+	if err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+func (p *PostgreSQLDriver) open(dialector gorm.Dialector, logLevel string) (*gorm.DB, error) {
 	// Create PostgreSQL naming strategy for Pascal case
 	namingStrategy := query.NewPostgreSQLNamingStrategy()
 	
@@ -72,7 +124,7 @@ func (p *PostgreSQLDriver) ConnectWithLogger(connectionString string, logLevel s
 		gormLogger = logger.Default.LogMode(logger.Silent)
 	}
 	
-	db, err := gorm.Open(postgres.Open(connectionString), &gorm.Config{
+	db, err := gorm.Open(dialector, &gorm.Config{
 		NamingStrategy: namingStrategy,
 		Logger:         gormLogger,
 	})
@@ -103,17 +155,54 @@ func (p *PostgreSQLDriver) SupportsTransactions() bool {
 	return true
 }
 
+// SupportsReturning reports whether INSERT ... RETURNING can be used to read back generated values
+func (p *PostgreSQLDriver) SupportsReturning() bool {
+	return true
+}
+
+// UpsertClause generates INSERT ... ON CONFLICT (...) DO UPDATE SET column = excluded.column
+func (p *PostgreSQLDriver) UpsertClause(conflictColumns []string, updateColumns []string) clause.Expression {
+	return onConflict(conflictColumns, updateColumns)
+}
+
+// SupportsMutationLimit is false for PostgreSQL; limited changes filter on a subquery of the keys
+func (p *PostgreSQLDriver) SupportsMutationLimit() bool {
+	return false
+}
+
+// KeySubquery uses the limited SELECT of the keys as is
+func (p *PostgreSQLDriver) KeySubquery(keys *gorm.DB, keyColumn string) interface{} {
+	return keys
+}
+
+// Pagination keeps the limit; PostgreSQL accepts OFFSET without LIMIT
+func (p *PostgreSQLDriver) Pagination(limit clause.Limit) clause.Limit {
+	return limit
+}
+
+// SetTimestampType chooses between TIMESTAMPTZ and TIMESTAMP for time.Time fields
+func (p *PostgreSQLDriver) SetTimestampType(timestampType TimestampType) {
+	p.timestamps = timestampType
+}
+
 func (p *PostgreSQLDriver) MapGoTypeToSQL(goType string) string {
+	if columnType, ok := customColumnType(p.Name(), goType); ok {
+		return columnType
+	}
+	goType = baseGoType(goType)
 	switch {
 	case strings.Contains(goType, "uuid.UUID"):
 		return "UUID"
 	case strings.Contains(goType, "time.Time"):
+		if p.timestamps == TimestampWithTimeZone {
+			return "TIMESTAMPTZ"
+		}
 		return "TIMESTAMP"
 	case goType == "string":
 		return "TEXT"
 	case goType == "int", goType == "int32":
 		return "INTEGER"
-	case goType == "int64":
+	case goType == "int64", goType == "uint", goType == "uint32", goType == "uint64":
 		return "BIGINT"
 	case goType == "bool":
 		return "BOOLEAN"
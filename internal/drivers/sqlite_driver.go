@@ -9,6 +9,7 @@ import (
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
 )
 
@@ -77,7 +78,36 @@ func (s *SQLiteDriver) SupportsTransactions() bool {
 	return true
 }
 
+// SupportsReturning reports whether INSERT ... RETURNING can be used (SQLite 3.35+)
+func (s *SQLiteDriver) SupportsReturning() bool {
+	return true
+}
+
+// UpsertClause generates INSERT ... ON CONFLICT (...) DO UPDATE SET column = excluded.column (SQLite 3.24+)
+func (s *SQLiteDriver) UpsertClause(conflictColumns []string, updateColumns []string) clause.Expression {
+	return onConflict(conflictColumns, updateColumns)
+}
+
+// SupportsMutationLimit is false: UPDATE/DELETE ... LIMIT is a compile-time option SQLite builds leave out
+func (s *SQLiteDriver) SupportsMutationLimit() bool {
+	return false
+}
+
+// KeySubquery uses the limited SELECT of the keys as is
+func (s *SQLiteDriver) KeySubquery(keys *gorm.DB, keyColumn string) interface{} {
+	return keys
+}
+
+// Pagination keeps the limit; the SQLite dialector writes OFFSET without LIMIT as LIMIT -1 OFFSET
+func (s *SQLiteDriver) Pagination(limit clause.Limit) clause.Limit {
+	return limit
+}
+
 func (s *SQLiteDriver) MapGoTypeToSQL(goType string) string {
+	if columnType, ok := customColumnType(s.Name(), goType); ok {
+		return columnType
+	}
+	goType = baseGoType(goType)
 	switch {
 	case strings.Contains(goType, "uuid.UUID"):
 		return "TEXT"
@@ -85,7 +115,8 @@ func (s *SQLiteDriver) MapGoTypeToSQL(goType string) string {
 		return "DATETIME"
 	case goType == "string":
 		return "TEXT"
-	case goType == "int", goType == "int32", goType == "int64":
+	case goType == "int", goType == "int32", goType == "int64",
+		goType == "uint", goType == "uint32", goType == "uint64":
 		return "INTEGER"
 	case goType == "bool":
 		return "BOOLEAN"
@@ -0,0 +1,135 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// prefix marks encrypted values, so plaintext written before a column was encrypted still loads
+const prefix = "gx1:"
+
+// ErrUnknownKey is returned when a value was encrypted under a key ID the keyring does not hold
+var ErrUnknownKey = errors.New("encryption key not found")
+
+// Keyring holds the AES keys of encrypted columns by key ID. New values are encrypted under the primary
+// key; values are stored as "gx1:<key id>:<base64 nonce+ciphertext>", so after a rotation rows written
+// under older keys still decrypt as long as those keys stay in the keyring
+type Keyring struct {
+	primary string
+	keys    map[string]*key
+	ids     []string // Sorted, so equality values come out in a stable order
+}
+
+type key struct {
+	aead   cipher.AEAD
+	ivSeed []byte // HMAC key deriving nonces for deterministic encryption
+}
+
+// NewKeyring creates a keyring encrypting under primaryID; keys are 16, 24 or 32 bytes (AES-128/192/256)
+func NewKeyring(primaryID string, keys map[string][]byte) (*Keyring, error) {
+	if _, exists := keys[primaryID]; !exists {
+		return nil, fmt.Errorf("primary key %q is not in the keyring", primaryID)
+	}
+
+	keyring := &Keyring{primary: primaryID, keys: make(map[string]*key, len(keys))}
+	for id, secret := range keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("invalid key ID %q: must be non-empty and contain no ':'", id)
+		}
+		block, err := aes.NewCipher(secret)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte("gontext deterministic iv"))
+		keyring.keys[id] = &key{aead: aead, ivSeed: mac.Sum(nil)}
+		keyring.ids = append(keyring.ids, id)
+	}
+	sort.Strings(keyring.ids)
+	return keyring, nil
+}
+
+// Encrypt encrypts plaintext under the primary key. Deterministic encryption derives the nonce from the
+// plaintext (a synthetic IV), so equal values give equal ciphertexts and can be matched in queries, at the
+// cost of revealing which rows share a value
+func (k *Keyring) Encrypt(plaintext []byte, deterministic bool) (string, error) {
+	return k.encryptWith(k.primary, plaintext, deterministic)
+}
+
+// EqualityValues returns the deterministic ciphertexts of plaintext under every key, for matching rows
+// written before and after a key rotation
+func (k *Keyring) EqualityValues(plaintext []byte) []string {
+	values := make([]string, 0, len(k.ids))
+	for _, id := range k.ids {
+		value, err := k.encryptWith(id, plaintext, true)
+		if err == nil {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func (k *Keyring) encryptWith(id string, plaintext []byte, deterministic bool) (string, error) {
+	key := k.keys[id]
+	nonce := make([]byte, key.aead.NonceSize())
+	if deterministic {
+		mac := hmac.New(sha256.New, key.ivSeed)
+		mac.Write(plaintext)
+		copy(nonce, mac.Sum(nil))
+	} else if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := key.aead.Seal(nonce, nonce, plaintext, []byte(id))
+	return prefix + id + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt returns the plaintext of an encrypted value; values without the encryption prefix are returned
+// as they are, so a column can be encrypted while it still holds plaintext rows
+func (k *Keyring) Decrypt(value string) ([]byte, error) {
+	if !IsEncrypted(value) {
+		return []byte(value), nil
+	}
+
+	id, encoded, ok := strings.Cut(strings.TrimPrefix(value, prefix), ":")
+	if !ok {
+		return nil, errors.New("malformed encrypted value")
+	}
+	key, exists := k.keys[id]
+	if !exists {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < key.aead.NonceSize() {
+		return nil, errors.New("malformed encrypted value")
+	}
+
+	nonce, ciphertext := sealed[:key.aead.NonceSize()], sealed[key.aead.NonceSize():]
+	plaintext, err := key.aead.Open(nil, nonce, ciphertext, []byte(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt value under key %q: %w", id, err)
+	}
+	return plaintext, nil
+}
+
+// PrimaryKeyID returns the ID new values are encrypted under
+func (k *Keyring) PrimaryKeyID() string {
+	return k.primary
+}
+
+// IsEncrypted reports whether a stored value carries the encryption prefix
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
@@ -0,0 +1,138 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newTestKeyring(t *testing.T, primary string, ids ...string) *Keyring {
+	t.Helper()
+	keys := make(map[string][]byte, len(ids))
+	for i, id := range ids {
+		keys[id] = bytes.Repeat([]byte{byte(i + 1)}, 32)
+	}
+	keyring, err := NewKeyring(primary, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyring
+}
+
+func TestKeyringRoundTrip(t *testing.T) {
+	keyring := newTestKeyring(t, "k1", "k1")
+	for _, deterministic := range []bool{false, true} {
+		for _, plaintext := range []string{"", "123-45-6789", "gx1:looks encrypted", "ünïcode"} {
+			value, err := keyring.Encrypt([]byte(plaintext), deterministic)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(value, "gx1:k1:") || strings.Contains(value, plaintext) && plaintext != "" {
+				t.Errorf("Encrypt(%q) = %q, want an opaque value under k1", plaintext, value)
+			}
+			decrypted, err := keyring.Decrypt(value)
+			if err != nil || string(decrypted) != plaintext {
+				t.Errorf("Decrypt(Encrypt(%q, %v)) = %q, %v", plaintext, deterministic, decrypted, err)
+			}
+		}
+	}
+}
+
+func TestKeyringDeterministicEncryption(t *testing.T) {
+	keyring := newTestKeyring(t, "k1", "k1")
+	encrypt := func(plaintext string, deterministic bool) string {
+		value, err := keyring.Encrypt([]byte(plaintext), deterministic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return value
+	}
+
+	if encrypt("a@example.com", true) != encrypt("a@example.com", true) {
+		t.Error("deterministic encryption gave different ciphertexts for equal values")
+	}
+	if encrypt("a@example.com", true) == encrypt("b@example.com", true) {
+		t.Error("deterministic encryption gave one ciphertext for different values")
+	}
+	if encrypt("a@example.com", false) == encrypt("a@example.com", false) {
+		t.Error("random encryption gave equal ciphertexts for equal values")
+	}
+}
+
+func TestKeyringRotation(t *testing.T) {
+	old := newTestKeyring(t, "2024-01", "2024-01")
+	written, err := old.Encrypt([]byte("secret"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated := newTestKeyring(t, "2024-06", "2024-01", "2024-06")
+	if plaintext, err := rotated.Decrypt(written); err != nil || string(plaintext) != "secret" {
+		t.Errorf("Decrypt after rotation = %q, %v; want the value written under the retired key", plaintext, err)
+	}
+	value, err := rotated.Encrypt([]byte("secret"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(value, "gx1:2024-06:") {
+		t.Errorf("Encrypt after rotation = %q, want it under the new primary key", value)
+	}
+
+	equality := rotated.EqualityValues([]byte("secret"))
+	if len(equality) != 2 || equality[0] != written || equality[1] != value {
+		t.Errorf("EqualityValues = %q, want the ciphertexts under the old and the new key", equality)
+	}
+
+	retired := newTestKeyring(t, "2024-06", "2024-06")
+	if _, err := retired.Decrypt(written); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Decrypt without the old key error = %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestKeyringDecryptRejectsTamperedValues(t *testing.T) {
+	keyring := newTestKeyring(t, "k1", "k1", "k2")
+	value, err := keyring.Encrypt([]byte("secret"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(value, "gx1:k1:"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed[len(sealed)-1] ^= 1
+
+	for name, tampered := range map[string]string{
+		"ciphertext": "gx1:k1:" + base64.RawStdEncoding.EncodeToString(sealed),
+		"key ID":     strings.Replace(value, "gx1:k1:", "gx1:k2:", 1), // The key ID is authenticated data
+		"no key ID":  "gx1:k1",
+		"encoding":   "gx1:k1:!!!",
+		"short":      "gx1:k1:AAAA",
+	} {
+		if plaintext, err := keyring.Decrypt(tampered); err == nil {
+			t.Errorf("%s: Decrypt(%q) = %q, want an error", name, tampered, plaintext)
+		}
+	}
+	if plaintext, err := keyring.Decrypt("written before encryption"); err != nil || string(plaintext) != "written before encryption" {
+		t.Errorf("Decrypt(plaintext) = %q, %v; want the value as it is", plaintext, err)
+	}
+}
+
+func TestNewKeyringValidatesKeys(t *testing.T) {
+	valid := bytes.Repeat([]byte{1}, 16)
+	tests := map[string]struct {
+		primary string
+		keys    map[string][]byte
+	}{
+		"missing primary": {"k2", map[string][]byte{"k1": valid}},
+		"key size":        {"k1", map[string][]byte{"k1": valid[:10]}},
+		"colon in ID":     {"k:1", map[string][]byte{"k:1": valid}},
+		"empty ID":        {"k1", map[string][]byte{"k1": valid, "": valid}},
+	}
+	for name, tt := range tests {
+		if _, err := NewKeyring(tt.primary, tt.keys); err == nil {
+			t.Errorf("%s: NewKeyring succeeded, want an error", name)
+		}
+	}
+}
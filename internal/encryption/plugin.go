@@ -0,0 +1,268 @@
+package encryption
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Column is the encryption configured for one property
+type Column struct {
+	Keyring *Keyring
+	// Deterministic encrypts equal values to equal ciphertexts so Where("Field", value) can match them
+	Deterministic bool
+}
+
+// EqualityValues returns the stored forms value may have, one per key, for an equality condition
+func (c *Column) EqualityValues(value interface{}) ([]string, error) {
+	if !c.Deterministic {
+		return nil, errors.New("equality queries require deterministic encryption")
+	}
+	plaintext, ok := plaintextOf(reflect.ValueOf(value))
+	if !ok {
+		return nil, fmt.Errorf("cannot compare an encrypted column with %T", value)
+	}
+	return c.Keyring.EqualityValues(plaintext), nil
+}
+
+// Lookup returns the encrypted columns of a model type by Go field name, or nil when it has none
+type Lookup func(model reflect.Type) map[string]*Column
+
+// Plugin encrypts configured columns while GORM writes an entity and decrypts them once a query has
+// scanned its rows, so entities only ever hold plaintext
+type Plugin struct {
+	lookup Lookup
+}
+
+const (
+	restoreKey      = "gontext:encryption_restore"
+	decryptModelKey = "gontext:encryption_decrypt_model"
+)
+
+// restore is a field value replaced by its ciphertext for the duration of a write
+type restore struct {
+	field    reflect.Value
+	original reflect.Value
+}
+
+// NewPlugin creates the plugin; lookup is consulted per statement, so columns configured later apply
+func NewPlugin(lookup Lookup) *Plugin {
+	return &Plugin{lookup: lookup}
+}
+
+// Name returns the plugin name
+func (p *Plugin) Name() string {
+	return "gontext:encryption"
+}
+
+// Initialize encrypts around creates and updates and decrypts after queries, before AfterFind hooks
+func (p *Plugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register("gontext:encrypt_create", p.encrypt),
+		callbacks.Create().After("gorm:create").Register("gontext:restore_create", p.restore),
+		callbacks.Update().Before("gorm:update").Register("gontext:encrypt_update", p.encrypt),
+		callbacks.Update().After("gorm:update").Register("gontext:restore_update", p.restore),
+		callbacks.Query().After("gorm:query").Before("gorm:preload").Register("gontext:decrypt_query", p.decrypt),
+	)
+}
+
+// columns returns the encrypted columns of the statement's model
+func (p *Plugin) columns(db *gorm.DB) map[string]*Column {
+	if db.Statement.Schema == nil {
+		return nil
+	}
+	return p.lookup(db.Statement.Schema.ModelType)
+}
+
+func (p *Plugin) encrypt(db *gorm.DB) {
+	columns := p.columns(db)
+	if db.Error != nil || len(columns) == 0 {
+		return
+	}
+
+	// Update("Email", v) and Updates(map) carry values in a map; encrypt a copy of it
+	if values, ok := db.Statement.Dest.(map[string]interface{}); ok {
+		encrypted := make(map[string]interface{}, len(values))
+		for name, value := range values {
+			if column, field := columnFor(db.Statement.Schema, columns, name); column != nil {
+				var err error
+				if value, err = encryptValue(column, reflect.ValueOf(value)); err != nil {
+					db.AddError(fmt.Errorf("failed to encrypt %s.%s: %w", db.Statement.Schema.Name, field.Name, err))
+					return
+				}
+			}
+			encrypted[name] = value
+		}
+		db.Statement.Dest = encrypted
+		// GORM copies the written values into the model, which gets its plaintext back in restore
+		db.InstanceSet(decryptModelKey, true)
+		return
+	}
+
+	var restores []restore
+	err := eachField(db, columns, func(field reflect.Value, column *Column) error {
+		ciphertext, err := encryptValue(column, field)
+		if err != nil || ciphertext == nil {
+			return err
+		}
+		original := reflect.New(field.Type()).Elem()
+		original.Set(field)
+		restores = append(restores, restore{field: field, original: original})
+		field.Set(reflect.ValueOf(ciphertext).Convert(field.Type()))
+		return nil
+	})
+	db.InstanceSet(restoreKey, restores)
+	if err != nil {
+		db.AddError(err)
+	}
+}
+
+// restore puts the plaintext back into written entities, whether or not the write succeeded
+func (p *Plugin) restore(db *gorm.DB) {
+	if value, ok := db.InstanceGet(restoreKey); ok {
+		for _, r := range value.([]restore) {
+			r.field.Set(r.original)
+		}
+	}
+	if _, ok := db.InstanceGet(decryptModelKey); ok {
+		err := eachField(db, p.columns(db), func(field reflect.Value, column *Column) error {
+			return decryptValue(column, field)
+		})
+		if err != nil {
+			db.AddError(err)
+		}
+	}
+}
+
+func (p *Plugin) decrypt(db *gorm.DB) {
+	columns := p.columns(db)
+	if db.Error != nil || len(columns) == 0 {
+		return
+	}
+
+	err := eachField(db, columns, func(field reflect.Value, column *Column) error {
+		return decryptValue(column, field)
+	})
+	if err != nil {
+		db.AddError(err)
+	}
+}
+
+// eachField calls fn with every encrypted field of the statement's entities (a struct or a slice of them)
+func eachField(db *gorm.DB, columns map[string]*Column, fn func(field reflect.Value, column *Column) error) error {
+	sch := db.Statement.Schema
+	value := reflect.Indirect(db.Statement.ReflectValue)
+
+	visit := func(entity reflect.Value) error {
+		entity = reflect.Indirect(entity)
+		if !entity.IsValid() || entity.Type() != sch.ModelType {
+			return nil // Scans into other types (DTOs, maps) are left as stored
+		}
+		for name, column := range columns {
+			field := sch.LookUpField(name)
+			if field == nil {
+				continue
+			}
+			if err := fn(field.ReflectValueOf(db.Statement.Context, entity), column); err != nil {
+				return fmt.Errorf("%s.%s: %w", sch.Name, name, err)
+			}
+		}
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := visit(value.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+		return visit(value)
+	default:
+		return nil
+	}
+}
+
+// columnFor matches a map key (field or column name) to an encrypted column
+func columnFor(sch *schema.Schema, columns map[string]*Column, name string) (*Column, *schema.Field) {
+	field := sch.LookUpField(name)
+	if field == nil {
+		return nil, nil
+	}
+	return columns[field.Name], field
+}
+
+// encryptValue returns the ciphertext for a string, *string or []byte value; nil for nil values
+func encryptValue(column *Column, value reflect.Value) (interface{}, error) {
+	if !value.IsValid() || (value.Kind() == reflect.Ptr && value.IsNil()) {
+		return nil, nil
+	}
+	plaintext, ok := plaintextOf(value)
+	if !ok {
+		return nil, fmt.Errorf("encrypted columns must be string or []byte, got %s", value.Type())
+	}
+	ciphertext, err := column.Keyring.Encrypt(plaintext, column.Deterministic)
+	if err != nil {
+		return nil, err
+	}
+	switch reflect.Indirect(value).Kind() {
+	case reflect.Slice:
+		return []byte(ciphertext), nil
+	default:
+		if value.Kind() == reflect.Ptr {
+			return &ciphertext, nil
+		}
+		return ciphertext, nil
+	}
+}
+
+// decryptValue replaces a scanned ciphertext in field with its plaintext
+func decryptValue(column *Column, field reflect.Value) error {
+	target := field
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil
+		}
+		target = field.Elem()
+	}
+
+	switch target.Kind() {
+	case reflect.String:
+		plaintext, err := column.Keyring.Decrypt(target.String())
+		if err != nil {
+			return err
+		}
+		target.SetString(string(plaintext))
+	case reflect.Slice:
+		if target.Type().Elem().Kind() != reflect.Uint8 || target.IsNil() {
+			return nil
+		}
+		plaintext, err := column.Keyring.Decrypt(string(target.Bytes()))
+		if err != nil {
+			return err
+		}
+		target.SetBytes(plaintext)
+	}
+	return nil
+}
+
+// plaintextOf returns the bytes of a string, *string or []byte value
+func plaintextOf(value reflect.Value) ([]byte, bool) {
+	value = reflect.Indirect(value)
+	switch {
+	case !value.IsValid():
+		return nil, false
+	case value.Kind() == reflect.String:
+		return []byte(value.String()), true
+	case value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.Uint8:
+		return value.Bytes(), true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,45 @@
+package jobs
+
+import (
+	"time"
+)
+
+// Status is the lifecycle state of a job
+type Status string
+
+const (
+	Pending   Status = "pending"   // Waiting for RunAt, or for a retry
+	Running   Status = "running"   // Claimed by a worker until LockedUntil
+	Succeeded Status = "succeeded" // Only kept with WorkerOptions.KeepSucceeded
+	Failed    Status = "failed"    // Gave up after MaxAttempts; LastError holds the last failure
+)
+
+const (
+	// DefaultQueue is the queue jobs go to and workers read when none is named
+	DefaultQueue = "default"
+	// DefaultMaxAttempts is the number of runs before a failing job is marked Failed
+	DefaultMaxAttempts = 5
+)
+
+// Job is a unit of background work stored in the GontextJobs table
+// Register it like any entity so migrations create the table: gontext.RegisterEntity[gontext.Job](ctx)
+type Job struct {
+	Id          int64      `gorm:"primaryKey"`
+	Queue       string     `gorm:"not null"`
+	Type        string     `gorm:"not null"` // Selects the worker handler
+	Payload     string     // JSON-encoded arguments
+	Status      Status     `gorm:"not null"`
+	Attempts    int        `gorm:"not null"` // Runs started, including the current one
+	MaxAttempts int        `gorm:"not null"`
+	RunAt       time.Time  `gorm:"not null;index"` // Not claimed before this time
+	LockedBy    *string    // Worker holding the job while Running
+	LockedUntil *time.Time // Visibility timeout: another worker may claim the job after it
+	LastError   *string
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+}
+
+// TableName keeps the queue apart from application tables
+func (Job) TableName() string {
+	return "GontextJobs"
+}
@@ -0,0 +1,63 @@
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/shepherrrd/gontext/internal/context"
+)
+
+// EnqueueOptions configures a single Enqueue call
+type EnqueueOptions struct {
+	Queue       string        // Default DefaultQueue
+	RunAt       time.Time     // Earliest time the job runs; zero runs it as soon as a worker is free
+	Delay       time.Duration // Added to the current time when RunAt is zero
+	MaxAttempts int           // Default DefaultMaxAttempts
+}
+
+// Enqueue stages a job in the context's change tracker, so the next SaveChanges inserts it in the same
+// transaction as the changes it belongs to: the job exists exactly when they were committed
+// payload is stored as JSON and decoded by the worker handler registered for jobType
+func Enqueue(ctx *context.DbContext, jobType string, payload interface{}, options ...EnqueueOptions) (*Job, error) {
+	var opts EnqueueOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if opts.Queue == "" {
+		opts.Queue = DefaultQueue
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode payload of %s job: %w", jobType, err)
+	}
+
+	now := time.Now()
+	runAt := opts.RunAt
+	if runAt.IsZero() {
+		runAt = now.Add(opts.Delay)
+	}
+
+	job := &Job{
+		Queue:       opts.Queue,
+		Type:        jobType,
+		Payload:     string(encoded),
+		Status:      Pending,
+		MaxAttempts: opts.MaxAttempts,
+		RunAt:       runAt,
+		CreatedAt:   now,
+	}
+	ctx.RegisterEntity(Job{})
+	ctx.AddEntity(job)
+	return job, nil
+}
+
+// EnsureTable creates the jobs table if needed, for applications that do not manage it with migrations
+func EnsureTable(ctx *context.DbContext) error {
+	ctx.RegisterEntity(Job{})
+	return ctx.GetDB().AutoMigrate(&Job{})
+}
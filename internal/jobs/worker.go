@@ -0,0 +1,338 @@
+package jobs
+
+import (
+	stdcontext "context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/shepherrrd/gontext/internal/context"
+)
+
+const (
+	// DefaultVisibilityTimeout is how long a claimed job stays hidden from other workers when
+	// WorkerOptions.VisibilityTimeout is 0
+	DefaultVisibilityTimeout = 5 * time.Minute
+	// DefaultPollInterval is the wait after finding no job when WorkerOptions.PollInterval is 0
+	DefaultPollInterval = time.Second
+)
+
+// WorkerOptions configures a worker
+type WorkerOptions struct {
+	Queue       string // Queue to read (default DefaultQueue)
+	Concurrency int    // Jobs run at the same time by Run (default 1)
+	// VisibilityTimeout is how long a job is leased to the worker running it; a job still Running after it
+	// (the worker crashed or hung) is claimed again by any worker. Handlers get it as their deadline
+	VisibilityTimeout time.Duration
+	PollInterval      time.Duration // Wait after finding no job (default DefaultPollInterval)
+	// Backoff returns the delay before retrying a job that failed its attempt'th run
+	// (default 10s doubling per attempt, capped at an hour)
+	Backoff func(attempt int) time.Duration
+	// KeepSucceeded marks finished jobs Succeeded instead of deleting them
+	KeepSucceeded bool
+	WorkerID      string // Recorded in LockedBy (default host:pid:random)
+}
+
+// Handler runs one job; an error (or panic) fails the attempt and the job is retried after a backoff
+// until it has run MaxAttempts times
+type Handler func(ctx stdcontext.Context, job *Job) error
+
+// Worker claims jobs of its queue with SELECT ... FOR UPDATE SKIP LOCKED and runs the handler registered
+// for their type, so any number of workers across instances share a queue without running a job twice
+// at once. Delivery is at least once: make handlers idempotent
+type Worker struct {
+	db        *gorm.DB
+	driver    string
+	returning bool // The claim is a single UPDATE ... RETURNING
+	options   WorkerOptions
+	mu        sync.RWMutex
+	handlers  map[string]Handler // Job type -> handler
+	columns   map[string]clause.Column
+}
+
+// NewWorker creates a worker reading through ctx's connection
+func NewWorker(ctx *context.DbContext, options WorkerOptions) *Worker {
+	if options.Queue == "" {
+		options.Queue = DefaultQueue
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = 1
+	}
+	if options.VisibilityTimeout <= 0 {
+		options.VisibilityTimeout = DefaultVisibilityTimeout
+	}
+	if options.PollInterval <= 0 {
+		options.PollInterval = DefaultPollInterval
+	}
+	if options.Backoff == nil {
+		options.Backoff = defaultBackoff
+	}
+	if options.WorkerID == "" {
+		options.WorkerID = workerID()
+	}
+	ctx.RegisterEntity(Job{})
+	return &Worker{
+		db:        ctx.GetDB(),
+		driver:    ctx.GetDriver().Name(),
+		returning: ctx.GetDriver().SupportsReturning(),
+		options:   options,
+		handlers:  make(map[string]Handler),
+	}
+}
+
+// Handle runs handler for the jobs of jobType; the worker only claims types it has a handler for
+func (w *Worker) Handle(jobType string, handler Handler) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.handlers[jobType] = handler
+}
+
+// HandleTyped runs handler for the jobs of jobType with their payload decoded into a T
+func HandleTyped[T any](w *Worker, jobType string, handler func(ctx stdcontext.Context, payload T, job *Job) error) {
+	w.Handle(jobType, func(ctx stdcontext.Context, job *Job) error {
+		var payload T
+		if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+			return fmt.Errorf("failed to decode payload: %w", err)
+		}
+		return handler(ctx, payload, job)
+	})
+}
+
+// Run works Concurrency jobs at a time until ctx is cancelled; it returns ctx's error on cancellation or
+// the first database error. Job failures are recorded on the job and do not stop the worker
+func (w *Worker) Run(ctx stdcontext.Context) error {
+	group, ctx := errgroup.WithContext(ctx)
+	for i := 0; i < w.options.Concurrency; i++ {
+		group.Go(func() error {
+			for {
+				worked, err := w.Work(ctx)
+				if err != nil {
+					if ctx.Err() != nil {
+						return ctx.Err()
+					}
+					return err
+				}
+				if worked {
+					continue // More jobs may be waiting
+				}
+
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(w.options.PollInterval):
+				}
+			}
+		})
+	}
+	return group.Wait()
+}
+
+// Work claims and runs one due job, reporting whether there was one
+func (w *Worker) Work(ctx stdcontext.Context) (bool, error) {
+	job, err := w.claim(ctx)
+	if err != nil || job == nil {
+		return false, err
+	}
+
+	w.mu.RLock()
+	handler := w.handlers[job.Type]
+	w.mu.RUnlock()
+
+	runErr := w.run(ctx, handler, job)
+	// Record the outcome even when ctx was cancelled during the run
+	return true, w.finish(stdcontext.WithoutCancel(ctx), job, runErr)
+}
+
+// run calls handler with the lease as deadline, turning a panic into an error
+func (w *Worker) run(ctx stdcontext.Context, handler Handler, job *Job) (err error) {
+	ctx, cancel := stdcontext.WithDeadline(ctx, *job.LockedUntil)
+	defer cancel()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("job panicked: %v", recovered)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// claim leases the next due job of a handled type: pending jobs whose RunAt has passed and running jobs
+// whose lease expired. With RETURNING (PostgreSQL, SQLite) the job is picked and leased by one UPDATE;
+// MySQL locks the row in a transaction first. Rows locked by other workers are skipped
+func (w *Worker) claim(ctx stdcontext.Context) (*Job, error) {
+	types := w.types()
+	if len(types) == 0 {
+		return nil, errors.New("worker has no handlers; call Handle before Run")
+	}
+	columns, err := w.jobColumns()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	lockedUntil := now.Add(w.options.VisibilityTimeout)
+	lease := map[string]interface{}{
+		columns["Status"].Name:      Running,
+		columns["Attempts"].Name:    gorm.Expr("? + 1", columns["Attempts"]),
+		columns["LockedBy"].Name:    w.options.WorkerID,
+		columns["LockedUntil"].Name: lockedUntil,
+	}
+	due := func(tx *gorm.DB) *gorm.DB {
+		query := tx.Model(&Job{}).
+			Where(clause.Eq{Column: columns["Queue"], Value: w.options.Queue}).
+			Where(clause.IN{Column: columns["Type"], Values: types}).
+			Where(clause.Or(
+				clause.And(clause.Eq{Column: columns["Status"], Value: Pending}, clause.Lte{Column: columns["RunAt"], Value: now}),
+				clause.And(clause.Eq{Column: columns["Status"], Value: Running}, clause.Lte{Column: columns["LockedUntil"], Value: now}),
+			)).
+			Order(clause.OrderBy{Columns: []clause.OrderByColumn{{Column: columns["RunAt"]}, {Column: columns["Id"]}}}).
+			Limit(1)
+		if w.driver != "sqlite" { // SQLite has no row locks; its writes are serialized anyway
+			query = query.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate, Options: clause.LockingOptionsSkipLocked})
+		}
+		return query
+	}
+
+	var job Job
+	if w.returning {
+		// UPDATE ... WHERE Id = (SELECT Id ... FOR UPDATE SKIP LOCKED LIMIT 1) RETURNING *
+		next := due(w.db.Session(&gorm.Session{NewDB: true}).WithContext(ctx)).Select(columns["Id"].Name)
+		result := w.db.WithContext(ctx).Model(&job).Clauses(clause.Returning{}).
+			Where(clause.Expr{SQL: "? = (?)", Vars: []interface{}{columns["Id"], next}}).
+			Updates(lease)
+		if result.Error != nil {
+			return nil, fmt.Errorf("failed to claim job from queue %s: %w", w.options.Queue, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil, nil
+		}
+		return &job, nil
+	}
+
+	claimed := false
+	err = w.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := due(tx).Find(&job)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		if err := tx.Model(&job).Updates(lease).Error; err != nil {
+			return err
+		}
+		claimed = true
+		return tx.Take(&job, job.Id).Error // Read back the leased values
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job from queue %s: %w", w.options.Queue, err)
+	}
+	if !claimed {
+		return nil, nil
+	}
+	return &job, nil
+}
+
+// finish records the outcome of a run, unless the lease expired and another worker claimed the job
+func (w *Worker) finish(ctx stdcontext.Context, job *Job, runErr error) error {
+	columns, err := w.jobColumns()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	// The lease guards every write: after a visibility timeout the job belongs to the next run
+	lease := w.db.WithContext(ctx).Model(job).
+		Where(clause.Eq{Column: columns["LockedBy"], Value: w.options.WorkerID}).
+		Where(clause.Eq{Column: columns["Attempts"], Value: job.Attempts})
+
+	var result *gorm.DB
+	switch {
+	case runErr == nil && !w.options.KeepSucceeded:
+		result = lease.Delete(job)
+	case runErr == nil:
+		job.Status = Succeeded
+		job.CompletedAt = &now
+		job.LockedBy, job.LockedUntil = nil, nil
+		result = lease.Select("Status", "CompletedAt", "LockedBy", "LockedUntil").Updates(job)
+	default:
+		message := runErr.Error()
+		job.LastError = &message
+		job.LockedBy, job.LockedUntil = nil, nil
+		if job.Attempts >= job.MaxAttempts {
+			job.Status = Failed
+			job.CompletedAt = &now
+		} else {
+			job.Status = Pending
+			job.RunAt = now.Add(w.options.Backoff(job.Attempts))
+		}
+		result = lease.Select("Status", "CompletedAt", "RunAt", "LastError", "LockedBy", "LockedUntil").Updates(job)
+	}
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to record outcome of job %d: %w", job.Id, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		log.Printf("gontext: job %d ran past its visibility timeout and was claimed again; its outcome was dropped", job.Id)
+	}
+	return nil
+}
+
+func (w *Worker) types() []interface{} {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	types := make([]string, 0, len(w.handlers))
+	for jobType := range w.handlers {
+		types = append(types, jobType)
+	}
+	sort.Strings(types)
+
+	values := make([]interface{}, len(types))
+	for i, jobType := range types {
+		values[i] = jobType
+	}
+	return values
+}
+
+// jobColumns resolves the Job columns with the context's naming strategy
+func (w *Worker) jobColumns() (map[string]clause.Column, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.columns != nil {
+		return w.columns, nil
+	}
+	stmt := &gorm.Statement{DB: w.db}
+	if err := stmt.Parse(&Job{}); err != nil {
+		return nil, fmt.Errorf("failed to parse job schema: %w", err)
+	}
+	w.columns = make(map[string]clause.Column, len(stmt.Schema.Fields))
+	for _, field := range stmt.Schema.Fields {
+		w.columns[field.Name] = clause.Column{Name: field.DBName}
+	}
+	return w.columns, nil
+}
+
+func defaultBackoff(attempt int) time.Duration {
+	delay := 10 * time.Second
+	for i := 1; i < attempt && delay < time.Hour; i++ {
+		delay *= 2
+	}
+	return min(delay, time.Hour)
+}
+
+func workerID() string {
+	host, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return fmt.Sprintf("%s:%d:%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
@@ -0,0 +1,217 @@
+package linq
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/schema"
+
+	"github.com/shepherrrd/gontext/internal/dberrors"
+)
+
+// AggregateExpr is one aggregate column of a grouped query, built with Count/Sum/Average/Min/Max
+type AggregateExpr struct {
+	function  string
+	fieldName string
+	alias     string
+	distinct  bool
+}
+
+// Count - COUNT(field), use "*" to count rows: Count("*").As("PostCount")
+func Count(fieldName string) AggregateExpr {
+	return AggregateExpr{function: "COUNT", fieldName: fieldName}
+}
+
+// CountDistinctOf - COUNT(DISTINCT field)
+func CountDistinctOf(fieldName string) AggregateExpr {
+	return AggregateExpr{function: "COUNT", fieldName: fieldName, distinct: true}
+}
+
+// Sum - SUM(field)
+func Sum(fieldName string) AggregateExpr {
+	return AggregateExpr{function: "SUM", fieldName: fieldName}
+}
+
+// Average - AVG(field)
+func Average(fieldName string) AggregateExpr {
+	return AggregateExpr{function: "AVG", fieldName: fieldName}
+}
+
+// Min - MIN(field)
+func Min(fieldName string) AggregateExpr {
+	return AggregateExpr{function: "MIN", fieldName: fieldName}
+}
+
+// Max - MAX(field)
+func Max(fieldName string) AggregateExpr {
+	return AggregateExpr{function: "MAX", fieldName: fieldName}
+}
+
+// As names the result column - it must match a field of the struct passed to ScanInto
+func (a AggregateExpr) As(alias string) AggregateExpr {
+	a.alias = alias
+	return a
+}
+
+// GroupBy - EF Core: GroupBy(x => x.AuthorId) - groups rows for Aggregate
+// Usage: ctx.Posts.GroupBy("AuthorId").Aggregate(Count("*").As("PostCount")).ScanInto(&stats)
+func (ds *LinqDbSet[T]) GroupBy(fieldNames ...string) *LinqDbSet[T] {
+	db := ds.db
+	for _, fieldName := range fieldNames {
+		db = db.Group(ds.quoteField(fieldName))
+	}
+	newDbSet := ds.clone(db)
+	newDbSet.groupBy = append(append([]string(nil), ds.groupBy...), fieldNames...)
+	return newDbSet
+}
+
+// Aggregate selects the grouping fields plus the given aggregates
+// Grouping columns keep their field names so they scan into same-named struct fields
+func (ds *LinqDbSet[T]) Aggregate(aggregates ...AggregateExpr) *LinqDbSet[T] {
+	var columns []string
+	for _, fieldName := range ds.groupBy {
+		columns = append(columns, fmt.Sprintf("%s AS %s", ds.quoteField(fieldName), ds.db.Statement.Quote(fieldName)))
+	}
+
+	for i, aggregate := range aggregates {
+		argument := "*"
+		if aggregate.fieldName != "*" {
+			argument = ds.quoteField(aggregate.fieldName)
+		}
+		if aggregate.distinct {
+			argument = "DISTINCT " + argument
+		}
+
+		alias := aggregate.alias
+		if alias == "" {
+			alias = fmt.Sprintf("%s%d", strings.ToLower(aggregate.function), i)
+		}
+		columns = append(columns, fmt.Sprintf("%s(%s) AS %s", aggregate.function, argument, ds.db.Statement.Quote(alias)))
+	}
+
+	return ds.clone(ds.db.Select(strings.Join(columns, ", ")))
+}
+
+// ScanInto runs the query and scans the rows into a slice of user structs: var stats []AuthorStats; ScanInto(&stats)
+// Columns are matched to struct fields by name (the aliases given with As)
+func (ds *LinqDbSet[T]) ScanInto(dest interface{}) error {
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("ScanInto requires a pointer to a slice, got %T", dest)
+	}
+
+	return dberrors.Translate(ds.query().Model(new(T)).Scan(dest).Error)
+}
+
+// MinOf returns the smallest value of a field scanned into its Go type: MinOf[Post, time.Time](ctx.Posts, "CreatedAt")
+// Returns ErrNotFound when no rows match, like EF Core's Min on an empty sequence
+func MinOf[T any, R any](ds *LinqDbSet[T], fieldName string) (R, error) {
+	return aggregateOf[T, R](ds, "MIN", fieldName)
+}
+
+// MaxOf returns the largest value of a field scanned into its Go type: MaxOf[Order, float64](ctx.Orders, "Total")
+// Returns ErrNotFound when no rows match, like EF Core's Max on an empty sequence
+func MaxOf[T any, R any](ds *LinqDbSet[T], fieldName string) (R, error) {
+	return aggregateOf[T, R](ds, "MAX", fieldName)
+}
+
+func aggregateOf[T any, R any](ds *LinqDbSet[T], function string, fieldName string) (R, error) {
+	var result R
+	var raw interface{}
+	row := ds.paginated().Select(fmt.Sprintf("%s(%s)", function, ds.quoteField(fieldName))).Row()
+	if err := row.Scan(&raw); err != nil {
+		return result, dberrors.Translate(err)
+	}
+	if raw == nil {
+		return result, &dberrors.DbError{Kind: dberrors.ErrNotFound}
+	}
+	result, err := convertAggregate[R](ds.db.Statement.Context, ds.lookupField(fieldName), raw)
+	if err != nil {
+		return result, fmt.Errorf("%s of %s: %w", function, fieldName, err)
+	}
+	return result, nil
+}
+
+// aggregateTimeLayouts are the text forms of times returned by drivers that do not parse them, SQLite
+// and MySQL without parseTime; times without a zone are read as UTC
+var aggregateTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02T15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// convertAggregate converts an aggregate's raw value to R: times are parsed from their text form,
+// values of the field's own type are set through the field so its serializer or Scanner applies, and
+// other types use database/sql's conversions (MaxOf[Order, int64] of an int field)
+func convertAggregate[R any](ctx context.Context, field *schema.Field, raw interface{}) (R, error) {
+	var result R
+	target := reflect.ValueOf(&result).Elem()
+	targetType := target.Type()
+	if targetType.Kind() == reflect.Ptr {
+		targetType = targetType.Elem()
+	}
+	set := func(value reflect.Value) {
+		if target.Kind() == reflect.Ptr {
+			target.Set(reflect.New(targetType))
+			target = target.Elem()
+		}
+		target.Set(value)
+	}
+
+	switch {
+	case targetType == reflect.TypeOf(time.Time{}):
+		instant, err := aggregateTime(raw)
+		if err != nil {
+			return result, err
+		}
+		set(reflect.ValueOf(instant))
+	case field != nil && field.IndirectFieldType == targetType:
+		holder := reflect.New(field.Schema.ModelType).Elem()
+		if err := field.Set(ctx, holder, raw); err != nil {
+			return result, err
+		}
+		value := reflect.Indirect(field.ReflectValueOf(ctx, holder))
+		if !value.IsValid() {
+			return result, fmt.Errorf("cannot read %v as %s", raw, targetType)
+		}
+		set(value)
+	default:
+		var converted sql.Null[R]
+		if err := converted.Scan(raw); err != nil {
+			return result, err
+		}
+		result = converted.V
+	}
+	return result, nil
+}
+
+// aggregateTime reads a time the driver returned as a time or as text
+func aggregateTime(raw interface{}) (time.Time, error) {
+	var text string
+	switch value := raw.(type) {
+	case time.Time:
+		return value, nil
+	case string:
+		text = value
+	case []byte:
+		text = string(value)
+	default:
+		return time.Time{}, fmt.Errorf("cannot read %T as time.Time", raw)
+	}
+
+	text = strings.TrimSpace(text)
+	for _, layout := range aggregateTimeLayouts {
+		if instant, err := time.Parse(layout, text); err == nil {
+			return instant, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q as time.Time", text)
+}
@@ -0,0 +1,39 @@
+package linq
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAggregateTime(t *testing.T) {
+	want := time.Date(2024, 3, 1, 12, 30, 15, 500000000, time.UTC)
+	tests := []struct {
+		raw  interface{}
+		want time.Time
+	}{
+		{want, want},
+		{"2024-03-01 12:30:15.5+00:00", want},                       // SQLite
+		{"2024-03-01 14:30:15.5+02:00", want},                       // SQLite, other zone
+		{"2024-03-01T12:30:15.5Z", want},                            // RFC 3339
+		{[]byte("2024-03-01 12:30:15.500000"), want},                // MySQL without parseTime
+		{"2024-03-01 12:30:15", want.Truncate(time.Second)},         // No fraction
+		{"2024-03-01", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}, // DATE column
+	}
+
+	for _, tt := range tests {
+		got, err := aggregateTime(tt.raw)
+		if err != nil {
+			t.Errorf("aggregateTime(%v): %v", tt.raw, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("aggregateTime(%v) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+
+	for _, raw := range []interface{}{"yesterday", 42} {
+		if _, err := aggregateTime(raw); err == nil {
+			t.Errorf("aggregateTime(%v) did not fail", raw)
+		}
+	}
+}
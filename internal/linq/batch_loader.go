@@ -0,0 +1,220 @@
+package linq
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/shepherrrd/gontext/internal/dberrors"
+)
+
+const (
+	// DefaultBatchLoaderWait is how long a loader collects keys before querying them
+	DefaultBatchLoaderWait = 2 * time.Millisecond
+	// MaxBatchLoaderKeys dispatches a batch early so the IN list stays within parameter limits
+	MaxBatchLoaderKeys = 1000
+)
+
+// BatchLoader coalesces ById lookups issued within a short window into one WHERE pk IN (...) query and
+// hands each caller its own entity, the DataLoader pattern GraphQL resolvers need to avoid N+1 queries
+// Results are cached per key for the loader's lifetime, so create one loader per request
+type BatchLoader[T any] struct {
+	set  *LinqDbSet[T]
+	wait time.Duration
+
+	mu      sync.Mutex
+	loads   map[string]*batchLoad[T] // Key -> load, pending or finished
+	pending []*batchLoad[T]          // Loads waiting for the next dispatch
+	timer   *time.Timer
+}
+
+type batchLoad[T any] struct {
+	key    string
+	id     interface{}
+	done   chan struct{} // Closed once result / err are set
+	result *T
+	err    error
+}
+
+// BatchLoader - DataLoader: new DataLoader(batchFn) - creates a per-request loader for ById lookups
+// wait is how long keys are collected before one query loads them; 0 uses DefaultBatchLoaderWait
+//
+//	loader := ctx.Users.BatchLoader(0)
+//	author, err := loader.Load(post.AuthorId) // Concurrent resolvers share one query
+func (ds *LinqDbSet[T]) BatchLoader(wait time.Duration) *BatchLoader[T] {
+	if wait <= 0 {
+		wait = DefaultBatchLoaderWait
+	}
+	return &BatchLoader[T]{set: ds, wait: wait, loads: make(map[string]*batchLoad[T])}
+}
+
+// Load returns the entity with the given primary key, or nil when there is none (like ById)
+func (l *BatchLoader[T]) Load(id interface{}) (*T, error) {
+	load := l.enqueue(id)
+	<-load.done
+	return load.result, load.err
+}
+
+// LoadMany returns the entities for ids in the same order, nil where there is none
+// All keys go into the current batch, so the call costs at most one query
+func (l *BatchLoader[T]) LoadMany(ids ...interface{}) ([]*T, error) {
+	loads := make([]*batchLoad[T], len(ids))
+	for i, id := range ids {
+		loads[i] = l.enqueue(id)
+	}
+
+	results := make([]*T, len(ids))
+	for i, load := range loads {
+		<-load.done
+		if load.err != nil {
+			return nil, load.err
+		}
+		results[i] = load.result
+	}
+	return results, nil
+}
+
+// Clear forgets the cached result for id so the next Load queries it again, e.g. after an update
+func (l *BatchLoader[T]) Clear(id interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := loaderKey(id)
+	if load, exists := l.loads[key]; exists && isClosed(load.done) {
+		delete(l.loads, key)
+	}
+}
+
+// ClearAll forgets every cached result
+func (l *BatchLoader[T]) ClearAll() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, load := range l.loads {
+		if isClosed(load.done) {
+			delete(l.loads, key)
+		}
+	}
+}
+
+// enqueue returns the load for id, adding it to the pending batch when it is not cached
+func (l *BatchLoader[T]) enqueue(id interface{}) *batchLoad[T] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := loaderKey(id)
+	if load, exists := l.loads[key]; exists {
+		return load
+	}
+
+	load := &batchLoad[T]{key: key, id: id, done: make(chan struct{})}
+	l.loads[key] = load
+	l.pending = append(l.pending, load)
+
+	switch {
+	case len(l.pending) >= MaxBatchLoaderKeys:
+		if l.timer != nil {
+			l.timer.Stop()
+			l.timer = nil
+		}
+		batch := l.pending
+		l.pending = nil
+		go l.dispatch(batch)
+	case len(l.pending) == 1:
+		l.timer = time.AfterFunc(l.wait, l.flush)
+	}
+	return load
+}
+
+// flush dispatches the pending batch when its wait has elapsed
+func (l *BatchLoader[T]) flush() {
+	l.mu.Lock()
+	batch := l.pending
+	l.pending = nil
+	l.timer = nil
+	l.mu.Unlock()
+
+	if len(batch) > 0 {
+		l.dispatch(batch)
+	}
+}
+
+// dispatch loads a batch with one query and distributes the rows to their loads by primary key
+func (l *BatchLoader[T]) dispatch(batch []*batchLoad[T]) {
+	results, err := l.query(batch)
+	if err != nil {
+		// Failures are not cached: the next Load of these keys queries again
+		l.mu.Lock()
+		for _, load := range batch {
+			delete(l.loads, load.key)
+		}
+		l.mu.Unlock()
+	}
+
+	for _, load := range batch {
+		load.result, load.err = results[load.key], err
+		close(load.done)
+	}
+}
+
+func (l *BatchLoader[T]) query(batch []*batchLoad[T]) (map[string]*T, error) {
+	ds := l.set
+	stmt := &gorm.Statement{DB: ds.db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, err
+	}
+	primaryKey := stmt.Schema.PrioritizedPrimaryField
+	if primaryKey == nil {
+		return nil, fmt.Errorf("BatchLoader requires a primary key on %s", ds.entityType.Name())
+	}
+
+	ids := make([]interface{}, len(batch))
+	for i, load := range batch {
+		ids[i] = load.id
+	}
+
+	var rows []T
+	err := ds.paginated().
+		Where(clause.IN{Column: clause.Column{Table: clause.CurrentTable, Name: primaryKey.DBName}, Values: ids}).
+		Find(&rows).Error
+	if err != nil {
+		return nil, dberrors.Translate(err)
+	}
+	ds.resolveIdentities(rows)
+
+	results := make(map[string]*T, len(rows))
+	for i := range rows {
+		entity := &rows[i]
+		key, _ := primaryKey.ValueOf(ds.db.Statement.Context, reflect.ValueOf(entity).Elem())
+		results[loaderKey(key)] = entity
+
+		// Automatically track the loaded entity for change detection
+		ds.trackEntity(entity)
+	}
+	return results, nil
+}
+
+// loaderKey normalizes a key so that 7, int64(7) and "7" share one load
+func loaderKey(id interface{}) string {
+	value := reflect.ValueOf(id)
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	if !value.IsValid() {
+		return "<nil>"
+	}
+	return fmt.Sprint(value.Interface())
+}
+
+func isClosed(done chan struct{}) bool {
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,189 @@
+package linq
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/shepherrrd/gontext/internal/dberrors"
+)
+
+// Setter assigns a value to a field in UpdateWhereIds and ExecuteUpdate; create it with Set
+type Setter struct {
+	Field string
+	Value interface{}
+}
+
+// Set assigns value to a field (Go field name, column name or json tag) - EF Core: SetProperty
+// The value can be a gorm.Expr for updates computed in SQL: Set("Views", gorm.Expr(`"Views" + 1`))
+func Set(fieldName string, value interface{}) Setter {
+	return Setter{Field: fieldName, Value: value}
+}
+
+// RemoveByIds deletes the rows with the given primary keys in one DELETE ... WHERE pk IN (...) statement
+// and returns how many were deleted. Unlike RemoveRange it runs immediately instead of at SaveChanges and
+// loads nothing; filters already on the set narrow the delete further. Tracked instances of the deleted
+// rows are left in the change tracker, like EF Core's ExecuteDelete
+// Usage: deleted, err := ctx.Sessions.RemoveByIds(expired...)
+func (ds *LinqDbSet[T]) RemoveByIds(ids ...interface{}) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	query, err := ds.whereIds(ids)
+	if err != nil {
+		return 0, err
+	}
+	result := query.Delete(new(T))
+	if result.Error != nil {
+		return 0, dberrors.Translate(result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// UpdateWhereIds sets fields of the rows with the given primary keys in one UPDATE ... WHERE pk IN (...)
+// statement and returns how many were updated; like RemoveByIds it runs immediately and leaves tracked
+// instances unchanged
+// Usage: ctx.Posts.UpdateWhereIds(ids, gontext.Set("IsPublished", true), gontext.Set("PublishedAt", now))
+func (ds *LinqDbSet[T]) UpdateWhereIds(ids []interface{}, setters ...Setter) (int64, error) {
+	if len(ids) == 0 || len(setters) == 0 {
+		return 0, nil
+	}
+
+	values, err := ds.assignments(setters)
+	if err != nil {
+		return 0, err
+	}
+
+	query, err := ds.whereIds(ids)
+	if err != nil {
+		return 0, err
+	}
+	result := query.Updates(values)
+	if result.Error != nil {
+		return 0, dberrors.Translate(result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// ExecuteUpdate sets fields of every row the query matches in one UPDATE statement and returns how many
+// were updated - EF Core's ExecuteUpdate. Take/Skip and the ordering choose the rows to change: MySQL and
+// MariaDB run UPDATE ... ORDER BY ... LIMIT, other databases filter on a subquery of the chosen keys
+// Like UpdateWhereIds it runs immediately and leaves tracked instances unchanged; a set without filters
+// is refused so a forgotten Where cannot update the whole table
+// Usage: ctx.Jobs.Where("Status", "pending").OrderBy("CreatedAt").Take(100).ExecuteUpdate(gontext.Set("Status", "claimed"))
+func (ds *LinqDbSet[T]) ExecuteUpdate(setters ...Setter) (int64, error) {
+	if len(setters) == 0 {
+		return 0, nil
+	}
+	values, err := ds.assignments(setters)
+	if err != nil {
+		return 0, err
+	}
+	query, err := ds.mutation()
+	if err != nil {
+		return 0, err
+	}
+	result := query.Updates(values)
+	if result.Error != nil {
+		return 0, dberrors.Translate(result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// ExecuteDelete deletes every row the query matches in one DELETE statement and returns how many were
+// deleted - EF Core's ExecuteDelete. Take/Skip limit it like ExecuteUpdate, soft-deleting entities are
+// soft deleted, and a set without filters is refused
+// Usage: deleted, err := ctx.Logs.Where("CreatedAt < ?", cutoff).OrderBy("CreatedAt").Take(10000).ExecuteDelete()
+func (ds *LinqDbSet[T]) ExecuteDelete() (int64, error) {
+	query, err := ds.mutation()
+	if err != nil {
+		return 0, err
+	}
+	result := query.Delete(new(T))
+	if result.Error != nil {
+		return 0, dberrors.Translate(result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// Upsert inserts entities in one statement, updating the existing row instead of failing when one with
+// the same conflictFields exists, and returns the number of rows affected. conflictFields name the unique
+// key to match (the primary key when omitted); every other column except creation timestamps is updated
+// PostgreSQL and SQLite generate ON CONFLICT (...) DO UPDATE and need a unique index on conflictFields;
+// MySQL and MariaDB generate ON DUPLICATE KEY UPDATE, which matches any unique key of the table and
+// counts an updated row twice. Generated keys are written back into entities; the change tracker is bypassed
+// Usage: ctx.Products.Upsert(products, "Sku")
+func (ds *LinqDbSet[T]) Upsert(entities []T, conflictFields ...string) (int64, error) {
+	if len(entities) == 0 {
+		return 0, nil
+	}
+	if ds.db.Error != nil {
+		return 0, ds.db.Error
+	}
+
+	stmt := &gorm.Statement{DB: ds.db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return 0, err
+	}
+
+	conflict := make(map[string]bool)
+	var conflictColumns []string
+	if len(conflictFields) == 0 {
+		for _, field := range stmt.Schema.PrimaryFields {
+			conflictFields = append(conflictFields, field.Name)
+		}
+	}
+	for _, name := range conflictFields {
+		field := ds.lookupField(name)
+		if field == nil || field.DBName == "" {
+			return 0, fmt.Errorf("field '%s' not found on %s", name, ds.entityType.Name())
+		}
+		conflict[field.DBName] = true
+		conflictColumns = append(conflictColumns, field.DBName)
+	}
+
+	var updateColumns []string
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName == "" || field.PrimaryKey || conflict[field.DBName] || !field.Creatable || !field.Updatable || field.AutoCreateTime > 0 {
+			continue
+		}
+		updateColumns = append(updateColumns, field.DBName)
+	}
+
+	result := ds.db.Session(&gorm.Session{}).Clauses(ds.dialect().UpsertClause(conflictColumns, updateColumns)).Create(&entities)
+	if result.Error != nil {
+		return 0, dberrors.Translate(result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// assignments resolves setters to the columns they update, refusing unknown fields and the key
+func (ds *LinqDbSet[T]) assignments(setters []Setter) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(setters))
+	for _, setter := range setters {
+		field := ds.lookupField(setter.Field)
+		if field == nil || field.DBName == "" {
+			return nil, fmt.Errorf("field '%s' not found on %s", setter.Field, ds.entityType.Name())
+		}
+		if field.PrimaryKey {
+			return nil, fmt.Errorf("key field %s.%s cannot be updated", ds.entityType.Name(), field.Name)
+		}
+		values[field.DBName] = setter.Value
+	}
+	return values, nil
+}
+
+// whereIds filters the set's query to the rows with the given values of its single-column primary key
+func (ds *LinqDbSet[T]) whereIds(ids []interface{}) (*gorm.DB, error) {
+	stmt := &gorm.Statement{DB: ds.db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, err
+	}
+	if len(stmt.Schema.PrimaryFields) != 1 {
+		return nil, fmt.Errorf("%s must have a single-column primary key to be changed by key", ds.entityType.Name())
+	}
+
+	column := clause.Column{Table: clause.CurrentTable, Name: stmt.Schema.PrioritizedPrimaryField.DBName}
+	return ds.paginated().Where(clause.IN{Column: column, Values: ids}), nil
+}
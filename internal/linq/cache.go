@@ -0,0 +1,139 @@
+package linq
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/shepherrrd/gontext/internal/cache"
+)
+
+// errNotCacheable reports a result gob cannot encode (e.g. cyclic navigations); the query result is still used
+var errNotCacheable = errors.New("query result cannot be cached")
+
+// Cached - EF Plus: FromCache() - serves ToList, First, FirstOrDefault and Count from the context's query
+// cache (DbContextOptions.QueryCache) for up to ttl. Entries are dropped when gontext writes to the tables
+// the query read: the entity's table and those of its Include()d navigations
+//
+//	products, err := ctx.Products.Where("IsActive", true).OrderBy("Name").Cached(5 * time.Minute).ToList()
+//
+// Without a configured cache the query runs as usual
+func (ds *LinqDbSet[T]) Cached(ttl time.Duration) *LinqDbSet[T] {
+	newDbSet := ds.clone(ds.db)
+	newDbSet.cacheTTL = ttl
+	return newDbSet
+}
+
+// queryCacheOf returns the context's query cache, or nil when none is configured
+func queryCacheOf(ctx interface{}) *cache.Cache {
+	if provider, ok := ctx.(interface{ QueryCache() *cache.Cache }); ok {
+		return provider.QueryCache()
+	}
+	return nil
+}
+
+// cachedRun runs run(query, dest), or fills dest from the query cache when the set is Cached
+// Concurrent misses of the same query share one database round trip
+func (ds *LinqDbSet[T]) cachedRun(query *gorm.DB, kind string, dest interface{}, run func(tx *gorm.DB, dest interface{}) *gorm.DB) error {
+	queryCache := queryCacheOf(ds.context)
+	if ds.cacheTTL <= 0 || queryCache == nil {
+		return run(query, dest).Error
+	}
+
+	// Build the SQL without running it to key the entry on the statement and its parameters
+	probe := run(query.Session(&gorm.Session{DryRun: true}), reflect.New(reflect.TypeOf(dest).Elem()).Interface())
+	if probe.Error != nil {
+		return probe.Error
+	}
+	key := cacheKey(kind, probe.Statement)
+	tags := cacheTags(probe.Statement)
+
+	loadedHere := false
+	data, err := queryCache.Load(query.Statement.Context, key, tags, ds.cacheTTL, func() ([]byte, error) {
+		loadedHere = true
+		if err := run(query, dest).Error; err != nil {
+			return nil, err
+		}
+		var buffer bytes.Buffer
+		if err := gob.NewEncoder(&buffer).Encode(dest); err != nil {
+			return nil, fmt.Errorf("%w: %v", errNotCacheable, err)
+		}
+		return buffer.Bytes(), nil
+	})
+	switch {
+	case loadedHere && errors.Is(err, errNotCacheable):
+		return nil // dest holds the result
+	case errors.Is(err, errNotCacheable):
+		return run(query, dest).Error
+	case err != nil || loadedHere:
+		return err
+	}
+
+	// gob leaves zero values out, so decode into a zero destination
+	target := reflect.ValueOf(dest).Elem()
+	target.Set(reflect.Zero(target.Type()))
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(dest)
+}
+
+// cacheKey identifies a query by its kind, SQL, parameters and preloads
+func cacheKey(kind string, stmt *gorm.Statement) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%s\x00", kind, stmt.SQL.String())
+	for _, value := range stmt.Vars {
+		fmt.Fprintf(hash, "%T=%v\x00", value, value)
+	}
+	for _, name := range sortedPreloads(stmt) {
+		fmt.Fprintf(hash, "preload:%s=%v\x00", name, stmt.Preloads[name])
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// cacheTags returns the tables a query reads: its own and those of preloaded and joined navigations
+func cacheTags(stmt *gorm.Statement) []string {
+	if stmt.Schema == nil {
+		return []string{stmt.Table}
+	}
+
+	seen := map[string]bool{stmt.Schema.Table: true}
+	tags := []string{stmt.Schema.Table}
+	paths := sortedPreloads(stmt)
+	for _, join := range stmt.Joins {
+		paths = append(paths, join.Name)
+	}
+	for _, path := range paths {
+		current := stmt.Schema
+		for _, name := range strings.Split(path, ".") {
+			relation, ok := current.Relationships.Relations[name]
+			if !ok {
+				break
+			}
+			current = relation.FieldSchema
+			tables := []string{current.Table}
+			if relation.JoinTable != nil {
+				tables = append(tables, relation.JoinTable.Table)
+			}
+			for _, table := range tables {
+				if !seen[table] {
+					seen[table] = true
+					tags = append(tags, table)
+				}
+			}
+		}
+	}
+	return tags
+}
+
+func sortedPreloads(stmt *gorm.Statement) []string {
+	names := preloadNames(stmt.Preloads)
+	sort.Strings(names)
+	return names
+}
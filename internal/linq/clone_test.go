@@ -0,0 +1,74 @@
+package linq
+
+import (
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newSQLiteDB returns an in-memory SQLite database with tables for models
+func newSQLiteDB(t *testing.T, models ...interface{}) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1) // Every connection to :memory: is a database of its own
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// newSQLiteOrders returns a set of 3 orders in an in-memory SQLite database: totals 10, 20 and 30
+func newSQLiteOrders(t *testing.T) *LinqDbSet[pagedOrder] {
+	t.Helper()
+	db := newSQLiteDB(t, &pagedOrder{})
+	for i, email := range []string{"ada@example.com", "bob@example.com", "cy@example.com"} {
+		if err := db.Create(&pagedOrder{Id: i + 1, Email: email, Total: float64(10 * (i + 1))}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewLinqDbSet[pagedOrder](db)
+}
+
+func TestChainedSetsDoNotShareConditions(t *testing.T) {
+	orders := newSQLiteOrders(t)
+	q := orders.Where("Total", GreaterThanOrEqual(10))
+
+	branches := map[string]*LinqDbSet[pagedOrder]{
+		"Where":   q.Where("Email", "ada@example.com"),
+		"Or":      q.Where("Total", GreaterThan(20)).Or("Id", 2),
+		"Take":    q.Take(1),
+		"Skip":    q.Skip(2),
+		"OrderBy": q.OrderByDescending("Total"),
+		"Select":  q.Select("Id"),
+	}
+	for name, branch := range branches {
+		if _, err := branch.ToList(); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if count, err := q.Count(); err != nil || count != 3 {
+			t.Errorf("after running the %s branch, the set it came from counts %d, %v; want 3", name, count, err)
+		}
+	}
+
+	first := q.Where("Email", "ada@example.com")
+	second := q.Where("Email", "bob@example.com")
+	if count, err := first.Count(); err != nil || count != 1 {
+		t.Errorf("first branch counts %d, %v; want 1", count, err)
+	}
+	if rows, err := second.ToList(); err != nil || len(rows) != 1 || rows[0].Id != 2 {
+		t.Errorf("second branch returned %+v, %v; want only order 2", rows, err)
+	}
+	if count, err := orders.Count(); err != nil || count != 3 {
+		t.Errorf("the unfiltered set counts %d, %v; want 3", count, err)
+	}
+}
@@ -0,0 +1,162 @@
+package linq
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/shepherrrd/gontext/internal/types"
+)
+
+// Comparison is an explicit comparison value for WhereField, OrField and WhereEntity
+// Usage: ctx.Users.WhereField("Age", GreaterThan(40)) instead of WhereField("Age", ">40")
+// The short names Eq, Ne, Gt, Gte, Lt and Lte build typed predicates from a TypedField, so the
+// comparison values use the long names
+type Comparison struct {
+	operator string
+	value    interface{}
+}
+
+// Equal - field = value, even when value is a string starting with an operator
+func Equal(value interface{}) Comparison { return Comparison{operator: "=", value: value} }
+
+// NotEqual - field <> value
+func NotEqual(value interface{}) Comparison { return Comparison{operator: "<>", value: value} }
+
+// GreaterThan - field > value
+func GreaterThan(value interface{}) Comparison { return Comparison{operator: ">", value: value} }
+
+// GreaterThanOrEqual - field >= value
+func GreaterThanOrEqual(value interface{}) Comparison {
+	return Comparison{operator: ">=", value: value}
+}
+
+// LessThan - field < value
+func LessThan(value interface{}) Comparison { return Comparison{operator: "<", value: value} }
+
+// LessThanOrEqual - field <= value
+func LessThanOrEqual(value interface{}) Comparison { return Comparison{operator: "<=", value: value} }
+
+// LiteralStrings turns off operator parsing for this query: WhereField("Name", ">x") matches the text ">x"
+func (ds *LinqDbSet[T]) LiteralStrings() *LinqDbSet[T] {
+	newDbSet := ds.clone(ds.db)
+	newDbSet.literalStrings = true
+	return newDbSet
+}
+
+// parsesOperators reports whether string values like ">40" are read as comparisons
+func (ds *LinqDbSet[T]) parsesOperators() bool {
+	if ds.literalStrings {
+		return false
+	}
+	if ctx, ok := ds.context.(interface{ StringOperatorParsing() bool }); ok {
+		return ctx.StringOperatorParsing()
+	}
+	return true
+}
+
+// comparisonCondition builds "column op ?" for a WhereField/WhereEntity value; nil becomes IS NULL
+func (ds *LinqDbSet[T]) comparisonCondition(quotedFieldName string, value interface{}) (string, []interface{}) {
+	if isNilValue(value) {
+		return fmt.Sprintf("%s IS NULL", quotedFieldName), nil
+	}
+	switch v := value.(type) {
+	case Comparison:
+		return ds.operatorCondition(quotedFieldName, v.operator, v.value)
+	case *Comparison:
+		return ds.operatorCondition(quotedFieldName, v.operator, v.value)
+	case string:
+		if ds.parsesOperators() {
+			operator, actualValue := ds.parseOperator(v)
+			return fmt.Sprintf("%s %s ?", quotedFieldName, operator), []interface{}{actualValue}
+		}
+	}
+	return fmt.Sprintf("%s = ?", quotedFieldName), []interface{}{types.QueryValue(value)}
+}
+
+// operatorCondition builds "column op ?", comparing against NULL with IS [NOT] NULL
+func (ds *LinqDbSet[T]) operatorCondition(quotedFieldName, operator string, value interface{}) (string, []interface{}) {
+	if isNilValue(value) {
+		switch operator {
+		case "=":
+			return fmt.Sprintf("%s IS NULL", quotedFieldName), nil
+		case "<>":
+			return fmt.Sprintf("%s IS NOT NULL", quotedFieldName), nil
+		}
+	}
+	return fmt.Sprintf("%s %s ?", quotedFieldName, operator), []interface{}{types.QueryValue(value)}
+}
+
+// IncludeZero makes WhereEntity/OrEntity filter on the named fields even when they hold zero values
+// Usage: ctx.Users.IncludeZero("IsActive").Where(&User{IsActive: false, Role: "admin"})
+// A nil pointer or invalid sql.Null* field included this way matches NULL. It also applies to the
+// entities of earlier Where calls, so Where(&User{IsActive: false}).IncludeZero("IsActive") filters on
+// false too; an earlier Or can't gain a term afterwards, so the query fails instead
+func (ds *LinqDbSet[T]) IncludeZero(fieldNames ...string) *LinqDbSet[T] {
+	newDbSet := ds.clone(ds.db)
+	newDbSet.includeZero = append(append([]string(nil), ds.includeZero...), fieldNames...)
+
+	db := ds.db
+	var skipped []skippedZero
+	for _, zero := range ds.skippedZero {
+		if !containsFold(fieldNames, zero.fieldName) {
+			skipped = append(skipped, zero)
+			continue
+		}
+		if zero.or {
+			return ds.fail("IncludeZero", fmt.Errorf("%s was skipped as zero by an earlier Or, call IncludeZero before it", zero.fieldName))
+		}
+		value, _ := newDbSet.entityFilterValue(zero.fieldName, zero.value)
+		condition, args := ds.comparisonCondition(ds.quoteField(zero.fieldName), value)
+		db = db.Where(condition, args...)
+	}
+	if len(skipped) == len(ds.skippedZero) {
+		return newDbSet
+	}
+	applied := newDbSet.clone(db)
+	applied.skippedZero = skipped
+	return applied
+}
+
+// skippedZero is a zero field a WhereEntity (or, for OrEntity) call did not filter on
+type skippedZero struct {
+	fieldName string
+	value     reflect.Value
+	or        bool
+}
+
+// entityFilterValue returns the value WhereEntity/OrEntity filter a field on, and whether to filter on it at all
+// Unset (zero) fields are skipped unless named in IncludeZero. Pointer fields are dereferenced, so
+// Where(&User{IsActive: &no}) filters on false; nil pointers and invalid sql.Null* values become NULL
+func (ds *LinqDbSet[T]) entityFilterValue(fieldName string, fieldValue reflect.Value) (interface{}, bool) {
+	if fieldValue.IsZero() && !ds.includesZero(fieldName) {
+		return nil, false
+	}
+	if fieldValue.Kind() == reflect.Ptr {
+		if fieldValue.IsNil() {
+			return nil, true
+		}
+		fieldValue = fieldValue.Elem()
+	}
+	value := fieldValue.Interface()
+	if valuer, ok := value.(driver.Valuer); ok {
+		if v, err := valuer.Value(); err == nil && v == nil {
+			return nil, true
+		}
+	}
+	return value, true
+}
+
+func (ds *LinqDbSet[T]) includesZero(fieldName string) bool {
+	return containsFold(ds.includeZero, fieldName)
+}
+
+func containsFold(names []string, fieldName string) bool {
+	for _, name := range names {
+		if strings.EqualFold(name, fieldName) {
+			return true
+		}
+	}
+	return false
+}
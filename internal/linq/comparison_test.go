@@ -0,0 +1,141 @@
+package linq
+
+import (
+	"errors"
+	"testing"
+)
+
+type zeroMember struct {
+	Id       int
+	Role     string
+	IsActive bool
+	Visits   int
+}
+
+// newMembers returns a set of 4 members: an active and an inactive admin, an active and an inactive guest
+func newMembers(t *testing.T) *LinqDbSet[zeroMember] {
+	t.Helper()
+	db := newSQLiteDB(t, &zeroMember{})
+	members := []zeroMember{
+		{Id: 1, Role: "admin", IsActive: true, Visits: 3},
+		{Id: 2, Role: "admin", IsActive: false},
+		{Id: 3, Role: "guest", IsActive: true, Visits: 1},
+		{Id: 4, Role: "guest", IsActive: false},
+	}
+	if err := db.Create(&members).Error; err != nil {
+		t.Fatal(err)
+	}
+	return NewLinqDbSet[zeroMember](db)
+}
+
+func memberIds(t *testing.T, set *LinqDbSet[zeroMember]) []int {
+	t.Helper()
+	members, err := set.OrderBy("Id").ToList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]int, len(members))
+	for i, member := range members {
+		ids[i] = member.Id
+	}
+	return ids
+}
+
+func TestIncludeZeroAppliesInEitherOrder(t *testing.T) {
+	members := newMembers(t)
+	inactiveAdmin := &zeroMember{Role: "admin", IsActive: false}
+
+	tests := []struct {
+		name string
+		set  *LinqDbSet[zeroMember]
+		want []int
+	}{
+		{"zero skipped", members.Where(inactiveAdmin), []int{1, 2}},
+		{"before Where", members.IncludeZero("IsActive").Where(inactiveAdmin), []int{2}},
+		{"after Where", members.Where(inactiveAdmin).IncludeZero("IsActive"), []int{2}},
+		{"case-insensitive", members.Where(inactiveAdmin).IncludeZero("isactive"), []int{2}},
+		{"between two Wheres", members.Where(&zeroMember{Role: "guest"}).IncludeZero("Visits").Where(&zeroMember{IsActive: true}), []int{}},
+		{"twice", members.Where(inactiveAdmin).IncludeZero("IsActive").IncludeZero("IsActive", "Visits"), []int{2}},
+		{"other field", members.Where(inactiveAdmin).IncludeZero("Visits"), []int{2}},
+		{"Or without it", members.Where("Visits", 3).OrEntity(zeroMember{}), []int{1}},
+		{"before OrEntity", members.Where("Visits", 3).IncludeZero("IsActive").OrEntity(zeroMember{}), []int{1, 2, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := memberIds(t, tt.set); !equalInts(got, tt.want) {
+				t.Errorf("got members %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIncludeZeroAfterOrEntityFails(t *testing.T) {
+	members := newMembers(t)
+	set := members.Where("Role", "guest").OrEntity(zeroMember{Role: "admin"}).IncludeZero("IsActive")
+
+	var queryErr *QueryError
+	if err := set.Err(); !errors.As(err, &queryErr) || queryErr.Method != "IncludeZero" {
+		t.Fatalf("Err() = %v, want a QueryError of IncludeZero", err)
+	}
+	if _, err := set.ToList(); err == nil {
+		t.Error("ToList ran a query whose Or skipped the included field")
+	}
+	if ids := memberIds(t, members.Where("Role", "guest").OrEntity(zeroMember{Role: "admin"}).IncludeZero("Role")); len(ids) != 4 {
+		t.Errorf("IncludeZero of a field the Or filtered on got members %v, want all 4", ids)
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestComparisonsMatchTypedPredicates(t *testing.T) {
+	members := newMembers(t)
+	visits := FieldOf[int]("Visits")
+	tests := []struct {
+		name       string
+		comparison Comparison
+		predicate  Predicate
+		want       []int
+	}{
+		{"equal", Equal(3), Eq(visits, 3), []int{1}},
+		{"not equal", NotEqual(0), Ne(visits, 0), []int{1, 3}},
+		{"greater", GreaterThan(1), Gt(visits, 1), []int{1}},
+		{"greater or equal", GreaterThanOrEqual(1), Gte(visits, 1), []int{1, 3}},
+		{"less", LessThan(1), Lt(visits, 1), []int{2, 4}},
+		{"less or equal", LessThanOrEqual(1), Lte(visits, 1), []int{2, 3, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := memberIds(t, members.Where("Visits", tt.comparison)); !equalInts(got, tt.want) {
+				t.Errorf("comparison matched %v, want %v", got, tt.want)
+			}
+			if got := memberIds(t, members.Where(tt.predicate)); !equalInts(got, tt.want) {
+				t.Errorf("typed predicate matched %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEqualMatchesOperatorTextLiterally(t *testing.T) {
+	members := newMembers(t)
+	if got := memberIds(t, members.Where("Role", ">admin")); !equalInts(got, []int{3, 4}) {
+		t.Errorf(`Where("Role", ">admin") matched %v, want the roles after admin`, got)
+	}
+	if got := memberIds(t, members.Where("Role", Equal(">admin"))); len(got) != 0 {
+		t.Errorf(`Where("Role", Equal(">admin")) matched %v, want no role spelled ">admin"`, got)
+	}
+	if got := memberIds(t, members.LiteralStrings().Where("Role", ">admin")); len(got) != 0 {
+		t.Errorf(`LiteralStrings().Where("Role", ">admin") matched %v, want none`, got)
+	}
+}
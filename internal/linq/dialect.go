@@ -0,0 +1,79 @@
+package linq
+
+import (
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/shepherrrd/gontext/internal/drivers"
+)
+
+// dialect returns the statement generation of the set's database: the driver of the database the
+// context stores T in, or the driver matching the connection for sets created without a context
+func (ds *LinqDbSet[T]) dialect() drivers.StatementDialect {
+	if ctx, ok := ds.context.(interface {
+		DriverFor(reflect.Type) drivers.DatabaseDriver
+	}); ok {
+		if dialect, ok := ctx.DriverFor(ds.entityType).(drivers.StatementDialect); ok {
+			return dialect
+		}
+	}
+	return drivers.DialectFor(ds.db.Dialector.Name())
+}
+
+// paginate writes Take/Skip in the form the database accepts; MySQL has no OFFSET without LIMIT
+func (ds *LinqDbSet[T]) paginate(db *gorm.DB) *gorm.DB {
+	limit, ok := db.Statement.Clauses["LIMIT"].Expression.(clause.Limit)
+	if !ok {
+		return db
+	}
+	paginated := ds.dialect().Pagination(limit)
+	if paginated.Limit == limit.Limit && paginated.Offset == limit.Offset {
+		return db
+	}
+	return db.Session(&gorm.Session{}).Clauses(paginated)
+}
+
+// paginated returns the filtered query on T with Take/Skip in the database's form but without the set's
+// ordering, for counts, aggregates and other terminals that read the LIMIT clause but not ORDER BY
+func (ds *LinqDbSet[T]) paginated() *gorm.DB {
+	return ds.paginate(ds.db.Session(&gorm.Session{})).Model(new(T))
+}
+
+// mutation returns the statement an UPDATE or DELETE of the set runs on. Without Take/Skip it is the
+// filtered query; with them the change is limited to the rows the ordered, paginated query returns,
+// through ORDER BY ... LIMIT where the database allows it and a subquery of their keys elsewhere
+func (ds *LinqDbSet[T]) mutation() (*gorm.DB, error) {
+	if ds.db.Error != nil {
+		return nil, ds.db.Error
+	}
+	limit, limited := ds.db.Statement.Clauses["LIMIT"].Expression.(clause.Limit)
+	if !limited || (limit.Limit == nil && limit.Offset == 0) {
+		return ds.db.Session(&gorm.Session{}).Model(new(T)), nil
+	}
+
+	dialect := ds.dialect()
+	if dialect.SupportsMutationLimit() && limit.Offset == 0 {
+		return ds.query().Session(&gorm.Session{}).Model(new(T)), nil
+	}
+
+	stmt := &gorm.Statement{DB: ds.db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, err
+	}
+	if len(stmt.Schema.PrimaryFields) != 1 {
+		return nil, fmt.Errorf("%s must have a single-column primary key to be changed with Take or Skip", ds.entityType.Name())
+	}
+	key := stmt.Schema.PrioritizedPrimaryField.DBName
+
+	keys := ds.query().Session(&gorm.Session{}).Model(new(T)).
+		Select(ds.db.Statement.Quote(clause.Column{Table: stmt.Schema.Table, Name: key}))
+
+	// The outer statement keeps the filters but not the pagination, which the subquery applies
+	unlimited := ds.db.Session(&gorm.Session{}).Clauses()
+	delete(unlimited.Statement.Clauses, "LIMIT")
+	column := clause.Column{Table: clause.CurrentTable, Name: key}
+	return unlimited.Model(new(T)).Where(clause.Expr{SQL: "? IN (?)", Vars: []interface{}{column, dialect.KeySubquery(keys, key)}}), nil
+}
@@ -0,0 +1,86 @@
+package linq
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type pagedOrder struct {
+	Id    int
+	Email string
+	Total float64
+}
+
+// sqlRecorder keeps the SQL of every statement, including dry runs
+type sqlRecorder struct {
+	statements []string
+}
+
+func (r *sqlRecorder) LogMode(logger.LogLevel) logger.Interface      { return r }
+func (r *sqlRecorder) Info(context.Context, string, ...interface{})  {}
+func (r *sqlRecorder) Warn(context.Context, string, ...interface{})  {}
+func (r *sqlRecorder) Error(context.Context, string, ...interface{}) {}
+func (r *sqlRecorder) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	sql, _ := fc()
+	r.statements = append(r.statements, sql)
+}
+
+// newMySQLDryRun returns a set on a MySQL connection that builds statements without running them
+func newMySQLDryRun(t *testing.T) (*LinqDbSet[pagedOrder], *sqlRecorder) {
+	t.Helper()
+	recorder := &sqlRecorder{}
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "user:pass@tcp(127.0.0.1:3306)/shop", SkipInitializeWithVersion: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true, Logger: recorder})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewLinqDbSet[pagedOrder](db), recorder
+}
+
+func TestTerminalsPaginateForMySQL(t *testing.T) {
+	terminals := map[string]func(*LinqDbSet[pagedOrder]){
+		"Count":         func(ds *LinqDbSet[pagedOrder]) { ds.Count() },
+		"Any":           func(ds *LinqDbSet[pagedOrder]) { ds.Any() },
+		"All":           func(ds *LinqDbSet[pagedOrder]) { ds.All("Total > ?", 0) },
+		"None":          func(ds *LinqDbSet[pagedOrder]) { ds.None("Total > ?", 0) },
+		"CountDistinct": func(ds *LinqDbSet[pagedOrder]) { ds.CountDistinct("Email") },
+		"SumField":      func(ds *LinqDbSet[pagedOrder]) { ds.SumField("Total") },
+		"AverageField":  func(ds *LinqDbSet[pagedOrder]) { ds.AverageField("Total") },
+		"MinField":      func(ds *LinqDbSet[pagedOrder]) { ds.MinField("Total") },
+		"MaxField":      func(ds *LinqDbSet[pagedOrder]) { ds.MaxField("Total") },
+	}
+
+	for name, terminal := range terminals {
+		t.Run(name, func(t *testing.T) {
+			ds, recorder := newMySQLDryRun(t)
+			terminal(ds.Where("Total > ?", 10).Skip(20))
+
+			if len(recorder.statements) == 0 {
+				t.Fatal("no statement built")
+			}
+			for _, sql := range recorder.statements {
+				if strings.Contains(sql, "OFFSET") && !strings.Contains(sql, "LIMIT") {
+					t.Errorf("OFFSET without LIMIT, which MySQL rejects: %s", sql)
+				}
+			}
+		})
+	}
+}
+
+func TestCountPagedQueryCountsPage(t *testing.T) {
+	ds, recorder := newMySQLDryRun(t)
+	ds.Skip(20).Take(10).Count()
+	ds.Skip(20).Take(10).Any()
+
+	for _, sql := range recorder.statements {
+		if !strings.Contains(sql, "SELECT COUNT(*) FROM (") || !strings.Contains(sql, "LIMIT 10 OFFSET 20) AS counted") {
+			t.Errorf("paged count does not count the page's rows: %s", sql)
+		}
+	}
+}
@@ -0,0 +1,421 @@
+package linq
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// WhereDynamic - System.Linq.Dynamic: Where("Age > @0 && Email.Contains(@1)", 30, "gmail")
+// Parses a C#-style expression at runtime, checks every member against the entity metadata and compiles it
+// to a parameterized predicate, so user-defined filters from admin UIs never reach SQL as text.
+// Supported: == != > >= < <=, && || ! (or and/or/not), parentheses, navigation paths (Author.Username),
+// string methods Contains/StartsWith/EndsWith, @0.Contains(Id) for IN, and literals "text", 42, true, null
+// A parse error is reported by the query's terminal method
+func (ds *LinqDbSet[T]) WhereDynamic(expression string, args ...interface{}) *LinqDbSet[T] {
+	predicate, err := ds.parseDynamic(expression, args)
+	if err != nil {
+		return ds.fail(call("WhereDynamic", expression), err)
+	}
+	return ds.wherePredicate(predicate, false)
+}
+
+func (ds *LinqDbSet[T]) parseDynamic(expression string, args []interface{}) (Predicate, error) {
+	stmt := &gorm.Statement{DB: ds.db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, err
+	}
+	tokens, err := tokenizeDynamic(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := &dynamicParser{tokens: tokens, args: args, schema: stmt.Schema}
+	predicate, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if token := parser.peek(); token.kind != dynamicEnd {
+		return nil, parser.unexpected(token)
+	}
+	return predicate, nil
+}
+
+type dynamicTokenKind int
+
+const (
+	dynamicIdentifier  dynamicTokenKind = iota
+	dynamicLiteral                      // String or number
+	dynamicPlaceholder                  // @0, @1, ...
+	dynamicOperator                     // == != > >= < <= && || ! ( ) . ,
+	dynamicEnd
+)
+
+type dynamicToken struct {
+	kind     dynamicTokenKind
+	text     string
+	value    interface{} // Literal value, or the argument index of a placeholder
+	position int
+}
+
+func tokenizeDynamic(expression string) ([]dynamicToken, error) {
+	var tokens []dynamicToken
+	runes := []rune(expression)
+	at := func(i int) rune {
+		if i < len(runes) {
+			return runes[i]
+		}
+		return 0
+	}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '_' || unicode.IsLetter(r):
+			for i < len(runes) && (runes[i] == '_' || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+				i++
+			}
+			tokens = append(tokens, dynamicToken{kind: dynamicIdentifier, text: string(runes[start:i]), position: start})
+		case unicode.IsDigit(r) || (r == '-' && unicode.IsDigit(at(i+1))):
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || (runes[i] == '.' && unicode.IsDigit(at(i+1)))) {
+				i++
+			}
+			text := string(runes[start:i])
+			var value interface{}
+			var err error
+			if strings.Contains(text, ".") {
+				value, err = strconv.ParseFloat(text, 64)
+			} else {
+				value, err = strconv.ParseInt(text, 10, 64)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q at position %d", text, start)
+			}
+			tokens = append(tokens, dynamicToken{kind: dynamicLiteral, text: text, value: value, position: start})
+		case r == '"' || r == '\'':
+			var text strings.Builder
+			closed := false
+			for i++; i < len(runes); i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+					text.WriteRune(runes[i])
+					continue
+				}
+				if runes[i] == r {
+					closed = true
+					i++
+					break
+				}
+				text.WriteRune(runes[i])
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated string at position %d", start)
+			}
+			tokens = append(tokens, dynamicToken{kind: dynamicLiteral, text: string(runes[start:i]), value: text.String(), position: start})
+		case r == '@' && unicode.IsDigit(at(i+1)):
+			i++
+			for i < len(runes) && unicode.IsDigit(runes[i]) {
+				i++
+			}
+			index, _ := strconv.Atoi(string(runes[start+1 : i]))
+			tokens = append(tokens, dynamicToken{kind: dynamicPlaceholder, text: string(runes[start:i]), value: index, position: start})
+		default:
+			operator := string(r)
+			if two := string(runes[i:min(i+2, len(runes))]); len([]rune(two)) == 2 {
+				switch two {
+				case "==", "!=", ">=", "<=", "&&", "||", "<>":
+					operator = two
+				}
+			}
+			switch operator {
+			case "==", "!=", ">=", "<=", "&&", "||", "<>", ">", "<", "!", "(", ")", ".", ",", "=":
+			default:
+				return nil, fmt.Errorf("unexpected %q at position %d", r, start)
+			}
+			i += len([]rune(operator))
+			tokens = append(tokens, dynamicToken{kind: dynamicOperator, text: operator, position: start})
+		}
+	}
+	return append(tokens, dynamicToken{kind: dynamicEnd, position: len(runes)}), nil
+}
+
+// dynamicComparisons maps expression operators to SQL
+var dynamicComparisons = map[string]string{
+	"==": "=", "=": "=", "!=": "<>", "<>": "<>", ">": ">", ">=": ">=", "<": "<", "<=": "<=",
+}
+
+// dynamicParser is a recursive descent parser producing a Predicate:
+//
+//	or         = and { ("||" | "or") and }
+//	and        = unary { ("&&" | "and") unary }
+//	unary      = ("!" | "not") unary | "(" or ")" | "@n" ".Contains(" member ")" | condition
+//	condition  = member [ "." method "(" value ")" | comparison value ]
+//	member     = identifier { "." identifier }
+type dynamicParser struct {
+	tokens   []dynamicToken
+	position int
+	args     []interface{}
+	schema   *schema.Schema
+}
+
+func (p *dynamicParser) peek() dynamicToken {
+	return p.tokens[p.position]
+}
+
+func (p *dynamicParser) next() dynamicToken {
+	token := p.tokens[p.position]
+	if token.kind != dynamicEnd {
+		p.position++
+	}
+	return token
+}
+
+func (p *dynamicParser) isOperator(operators ...string) bool {
+	token := p.peek()
+	for _, operator := range operators {
+		if (token.kind == dynamicOperator && token.text == operator) ||
+			(token.kind == dynamicIdentifier && strings.EqualFold(token.text, operator) && unicode.IsLetter(rune(operator[0]))) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *dynamicParser) expect(operator string) error {
+	if !p.isOperator(operator) {
+		return p.unexpected(p.peek())
+	}
+	p.next()
+	return nil
+}
+
+func (p *dynamicParser) unexpected(token dynamicToken) error {
+	if token.kind == dynamicEnd {
+		return fmt.Errorf("unexpected end of expression")
+	}
+	return fmt.Errorf("unexpected %q at position %d", token.text, token.position)
+}
+
+func (p *dynamicParser) parseOr() (Predicate, error) {
+	return p.parseJunction(p.parseAnd, Or, "||", "or")
+}
+
+func (p *dynamicParser) parseAnd() (Predicate, error) {
+	return p.parseJunction(p.parseUnary, And, "&&", "and")
+}
+
+func (p *dynamicParser) parseJunction(operand func() (Predicate, error), join func(...Predicate) Predicate, operators ...string) (Predicate, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	predicates := []Predicate{first}
+	for p.isOperator(operators...) {
+		p.next()
+		predicate, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		predicates = append(predicates, predicate)
+	}
+	if len(predicates) == 1 {
+		return first, nil
+	}
+	return join(predicates...), nil
+}
+
+func (p *dynamicParser) parseUnary() (Predicate, error) {
+	switch {
+	case p.isOperator("!", "not"):
+		p.next()
+		predicate, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Not(predicate), nil
+	case p.isOperator("("):
+		p.next()
+		predicate, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return predicate, p.expect(")")
+	case p.peek().kind == dynamicPlaceholder:
+		return p.parseMembership()
+	}
+	return p.parseCondition()
+}
+
+// parseMembership parses @0.Contains(Id), the Dynamic LINQ form of IN
+func (p *dynamicParser) parseMembership() (Predicate, error) {
+	values, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("."); err != nil {
+		return nil, err
+	}
+	if method := p.next(); method.kind != dynamicIdentifier || method.text != "Contains" {
+		return nil, p.unexpected(method)
+	}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	fieldName, _, err := p.parseMember()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+
+	list := reflect.ValueOf(values)
+	if list.Kind() != reflect.Slice && list.Kind() != reflect.Array {
+		return nil, fmt.Errorf("Contains on a placeholder needs a slice argument, got %T", values)
+	}
+	if list.Len() == 0 {
+		// IN () is invalid SQL; an empty list matches no row
+		return And(comparison{fieldName: fieldName, operator: "IS NULL"}, comparison{fieldName: fieldName, operator: "IS NOT NULL"}), nil
+	}
+	return comparison{fieldName: fieldName, operator: "IN", value: values, hasValue: true}, nil
+}
+
+func (p *dynamicParser) parseCondition() (Predicate, error) {
+	fieldName, field, err := p.parseMember()
+	if err != nil {
+		return nil, err
+	}
+
+	// String method: Email.Contains(@1)
+	if p.isOperator(".") {
+		p.next()
+		method := p.next()
+		if method.kind != dynamicIdentifier {
+			return nil, p.unexpected(method)
+		}
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s.%s expects a string, got %T", fieldName, method.text, value)
+		}
+		switch method.text {
+		case "Contains":
+			return likePredicate{fieldName: fieldName, pattern: "%" + escapeLike(text) + "%"}, nil
+		case "StartsWith":
+			return likePredicate{fieldName: fieldName, pattern: escapeLike(text) + "%"}, nil
+		case "EndsWith":
+			return likePredicate{fieldName: fieldName, pattern: "%" + escapeLike(text)}, nil
+		}
+		return nil, fmt.Errorf("unsupported method %q at position %d", method.text, method.position)
+	}
+
+	token := p.peek()
+	operator, ok := dynamicComparisons[token.text]
+	if token.kind != dynamicOperator || !ok {
+		// A bare boolean member: IsActive
+		if field.FieldType.Kind() == reflect.Bool || (field.FieldType.Kind() == reflect.Ptr && field.FieldType.Elem().Kind() == reflect.Bool) {
+			return comparison{fieldName: fieldName, operator: "=", value: true, hasValue: true}, nil
+		}
+		return nil, p.unexpected(token)
+	}
+	p.next()
+
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	if value == nil && operator != "=" && operator != "<>" {
+		return nil, fmt.Errorf("null can only be compared with == or !=")
+	}
+	return comparison{fieldName: fieldName, operator: operator, value: value, hasValue: true}, nil
+}
+
+// parseMember reads a field or navigation path and resolves it against the entity metadata
+func (p *dynamicParser) parseMember() (string, *schema.Field, error) {
+	token := p.next()
+	if token.kind != dynamicIdentifier {
+		return "", nil, p.unexpected(token)
+	}
+
+	current := p.schema
+	var path []string
+	name := token.text
+	for {
+		relationship := lookupRelationship(current, name)
+		if relationship == nil || !p.isOperator(".") || p.tokens[p.position+1].kind != dynamicIdentifier ||
+			(relationship.Type != schema.BelongsTo && relationship.Type != schema.HasOne) {
+			break
+		}
+		path = append(path, relationship.Name)
+		current = relationship.FieldSchema
+		p.next()
+		name = p.next().text
+	}
+
+	field := lookupSchemaField(current, name)
+	if field == nil {
+		return "", nil, fmt.Errorf("unknown member %q on %s at position %d", name, current.Name, token.position)
+	}
+	return strings.Join(append(path, field.Name), "."), field, nil
+}
+
+// parseValue reads a literal, null, true/false or a placeholder argument
+func (p *dynamicParser) parseValue() (interface{}, error) {
+	token := p.next()
+	switch token.kind {
+	case dynamicLiteral:
+		return token.value, nil
+	case dynamicPlaceholder:
+		index := token.value.(int)
+		if index >= len(p.args) {
+			return nil, fmt.Errorf("%s has no argument (%d given)", token.text, len(p.args))
+		}
+		return p.args[index], nil
+	case dynamicIdentifier:
+		switch strings.ToLower(token.text) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null", "nil":
+			return nil, nil
+		}
+	}
+	return nil, p.unexpected(token)
+}
+
+// lookupSchemaField finds a column field by Go name, column name or json tag, ignoring case
+func lookupSchemaField(s *schema.Schema, name string) *schema.Field {
+	if field := s.LookUpField(name); field != nil && field.DBName != "" {
+		return field
+	}
+	for _, field := range s.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		jsonName := strings.Split(field.Tag.Get("json"), ",")[0]
+		if jsonName == name || strings.EqualFold(field.Name, name) || strings.EqualFold(field.DBName, name) {
+			return field
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package linq
+
+import (
+	"strings"
+	"testing"
+)
+
+type dynamicAuthor struct {
+	Id       int
+	Username string `json:"user_name"`
+}
+
+type dynamicPost struct {
+	Id        int
+	Title     string
+	Views     int
+	Published bool
+	Rating    *float64
+	AuthorId  int
+	Author    *dynamicAuthor
+}
+
+// newDynamicPosts returns 4 posts: by ada "Go 100%" (published, 10 views, rated 4.5) and "Draft" (2 views),
+// by bob "Go generics" (published, 30 views) and "Rust" (published, 20 views, rated 3)
+func newDynamicPosts(t *testing.T) *LinqDbSet[dynamicPost] {
+	t.Helper()
+	db := newSQLiteDB(t, &dynamicAuthor{}, &dynamicPost{})
+	high, low := 4.5, 3.0
+	authors := []dynamicAuthor{{Id: 1, Username: "ada"}, {Id: 2, Username: "bob"}}
+	posts := []dynamicPost{
+		{Id: 1, Title: "Go 100%", Views: 10, Published: true, Rating: &high, AuthorId: 1},
+		{Id: 2, Title: "Draft", Views: 2, AuthorId: 1},
+		{Id: 3, Title: "Go generics", Views: 30, Published: true, AuthorId: 2},
+		{Id: 4, Title: "Rust", Views: 20, Published: true, Rating: &low, AuthorId: 2},
+	}
+	if err := db.Create(&authors).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Omit("Author").Create(&posts).Error; err != nil {
+		t.Fatal(err)
+	}
+	return NewLinqDbSet[dynamicPost](db)
+}
+
+func postIds(t *testing.T, set *LinqDbSet[dynamicPost]) []int {
+	t.Helper()
+	posts, err := set.OrderBy("Id").ToList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]int, len(posts))
+	for i, post := range posts {
+		ids[i] = post.Id
+	}
+	return ids
+}
+
+func TestWhereDynamic(t *testing.T) {
+	posts := newDynamicPosts(t)
+	tests := []struct {
+		expression string
+		args       []interface{}
+		want       []int
+	}{
+		{"Views > 10", nil, []int{3, 4}},
+		{"Views >= @0 && Views <= @1", []interface{}{10, 20}, []int{1, 4}},
+		{"Views == 2 || Views = 30", nil, []int{2, 3}},
+		{"Views != 10 and Views <> 2", nil, []int{3, 4}},
+		{"Title == \"Go 100%\"", nil, []int{1}},
+		{`Title == 'Rust'`, nil, []int{4}},
+		{`Title == 'Go \'generics\''`, nil, []int{}},
+		{`Title == "Go\ generics"`, nil, []int{3}},
+		{"Title.StartsWith(\"Go\")", nil, []int{1, 3}},
+		{"Title.EndsWith(@0)", []interface{}{"ics"}, []int{3}},
+		{"Title.Contains(@0)", []interface{}{"%"}, []int{1}},
+		{"Published", nil, []int{1, 3, 4}},
+		{"!Published", nil, []int{2}},
+		{"not Published or Views > 25", nil, []int{2, 3}},
+		{"Published == false", nil, []int{2}},
+		{"Rating == null", nil, []int{2, 3}},
+		{"Rating != nil && Rating < 4.5", nil, []int{4}},
+		{"Views > -1 && !(Views < 10 || Views > 20)", nil, []int{1, 4}},
+		{"Published && (Views < 15 || Views > 25)", nil, []int{1, 3}},
+		{"@0.Contains(Id)", []interface{}{[]int{2, 4, 9}}, []int{2, 4}},
+		{"@0.Contains(Id)", []interface{}{[]int{}}, []int{}},
+		{"Author.Username == @0", []interface{}{"bob"}, []int{3, 4}},
+		{"Author.user_name.StartsWith(\"a\") && Views > 5", nil, []int{1}},
+		{"views > 25 || TITLE == 'Draft'", nil, []int{2, 3}},
+		{"author_id == 1", nil, []int{1, 2}},
+	}
+
+	for _, tt := range tests {
+		got := postIds(t, posts.WhereDynamic(tt.expression, tt.args...))
+		if !equalInts(got, tt.want) {
+			t.Errorf("WhereDynamic(%q, %v) matched %v, want %v", tt.expression, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestWhereDynamicReportsErrors(t *testing.T) {
+	posts := newDynamicPosts(t)
+	tests := []struct {
+		expression string
+		args       []interface{}
+		err        string
+	}{
+		{"Views >", nil, "unexpected end of expression"},
+		{"Views > 1 Views", nil, `unexpected "Views" at position 10`},
+		{"(Views > 1", nil, "unexpected end of expression"},
+		{"Views > 1)", nil, `unexpected ")" at position 9`},
+		{"Missing == 1", nil, `unknown member "Missing" on dynamicPost at position 0`},
+		{"Author.Missing == 1", nil, `unknown member "Missing" on dynamicAuthor at position 0`},
+		{"Views > @1", []interface{}{1}, "@1 has no argument (1 given)"},
+		{`Title == "open`, nil, "unterminated string at position 9"},
+		{"Views ~ 1", nil, `unexpected '~' at position 6`},
+		{"Views", nil, "unexpected end of expression"},
+		{"Views > null", nil, "null can only be compared with == or !="},
+		{"Title.Trim(\"x\")", nil, `unsupported method "Trim" at position 6`},
+		{"Title.Contains(1)", nil, "Title.Contains expects a string, got int64"},
+		{"@0.Contains(Id)", []interface{}{3}, "Contains on a placeholder needs a slice argument, got int"},
+		{"@0.Any(Id)", []interface{}{[]int{1}}, `unexpected "Any" at position 3`},
+		{"Views > 1; DROP TABLE dynamic_posts", nil, `unexpected ';' at position 9`},
+	}
+
+	for _, tt := range tests {
+		_, err := posts.WhereDynamic(tt.expression, tt.args...).ToList()
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("WhereDynamic(%q) error = %v, want %q", tt.expression, err, tt.err)
+		}
+	}
+	if count, err := posts.Count(); err != nil || count != 4 {
+		t.Errorf("%d posts after the rejected expressions, %v; want 4", count, err)
+	}
+}
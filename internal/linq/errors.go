@@ -0,0 +1,67 @@
+package linq
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// QueryError is a mistake found while a query was chained - an unknown field or navigation, an argument
+// no overload accepts, an invalid raw fragment. Chained methods cannot return errors, so it is recorded
+// on the set and returned by the terminal method (ToList, First, Count, ...); Err reports it earlier
+type QueryError struct {
+	Entity string // Entity type of the set
+	Method string // Chained call that failed, with its arguments: Include("Autor")
+	Err    error
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("%s on %s: %v", e.Method, e.Entity, e.Err)
+}
+
+func (e *QueryError) Unwrap() error {
+	return e.Err
+}
+
+// Err returns the errors recorded while the query was chained, nil when it is valid so far; the
+// terminal method returns the same errors. QueryError describes the failed call:
+//
+//	query := ctx.Posts.Include("Autor").Where("IsPublished", true)
+//	if err := query.Err(); err != nil { ... } // Include("Autor") on Post: navigation not found
+func (ds *LinqDbSet[T]) Err() error {
+	return ds.db.Error
+}
+
+// fail returns a copy of the set carrying a QueryError for method, which the query reports when it
+// runs; method names the call with its arguments
+func (ds *LinqDbSet[T]) fail(method string, err error) *LinqDbSet[T] {
+	newDb := ds.db.Session(&gorm.Session{})
+	newDb.AddError(ds.queryError(method, err))
+	return ds.clone(newDb)
+}
+
+// queryError wraps err in a QueryError for method on the set's entity, unless it already is one
+func (ds *LinqDbSet[T]) queryError(method string, err error) error {
+	var queryErr *QueryError
+	if errors.As(err, &queryErr) {
+		return err
+	}
+	return &QueryError{Entity: ds.entityType.Name(), Method: method, Err: err}
+}
+
+// call formats a chained call for QueryError.Method: call("Include", "Autor") is Include("Autor")
+func call(method string, args ...interface{}) string {
+	formatted := ""
+	for i, arg := range args {
+		if i > 0 {
+			formatted += ", "
+		}
+		if s, ok := arg.(string); ok {
+			formatted += fmt.Sprintf("%q", s)
+		} else {
+			formatted += fmt.Sprintf("%v", arg)
+		}
+	}
+	return method + "(" + formatted + ")"
+}
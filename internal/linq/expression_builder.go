@@ -4,9 +4,17 @@ import (
 	"fmt"
 	"reflect"
 	"log"
+	"slices"
 	"strings"
+	"time"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"github.com/shepherrrd/gontext/internal/dberrors"
+	"github.com/shepherrrd/gontext/internal/encryption"
+	"github.com/shepherrrd/gontext/internal/models"
 	"github.com/shepherrrd/gontext/internal/query"
+	"github.com/shepherrrd/gontext/internal/types"
 )
 
 // Expression represents a LINQ expression
@@ -27,6 +35,16 @@ type LinqDbSet[T any] struct {
 	context    interface{} // Will hold the DbContext
 	translator *query.PostgreSQLQueryTranslator // For automatic PostgreSQL translation
 	tableName  string // Entity table name
+	ignoreCase bool   // Case-insensitive Like/StartsWith/EndsWith for this query
+	literalStrings bool // Compare string values as-is instead of parsing ">40"-style operators
+	includeZero []string // Fields WhereEntity/OrEntity filter on even when zero
+	skippedZero []skippedZero // Zero fields earlier WhereEntity/OrEntity calls skipped, for a later IncludeZero
+	orderings  []ordering // ORDER BY terms, applied when the query executes
+	groupBy    []string   // GROUP BY field names, selected by Aggregate
+	location   *time.Location // Time zone for calendar-day helpers, overrides the context's
+	cacheTTL   time.Duration  // Serve results from the query cache for this long, 0 = uncached
+	unscoped   *LinqDbSet[T]  // The set before the context's default scopes, for IgnoreDefaultScopes
+	identityResolution bool   // Share one instance per key across included navigations
 }
 
 func NewLinqDbSet[T any](db *gorm.DB) *LinqDbSet[T] {
@@ -54,35 +72,82 @@ func NewLinqDbSetWithContext[T any](db *gorm.DB, ctx interface{}) *LinqDbSet[T]
 
 	// Check if this is a PostgreSQL database and set up automatic translation
 	var translator *query.PostgreSQLQueryTranslator
-	tableName := entityType.Name()
-	
-	// Get table name (check for TableName method)
-	if tabler, ok := interface{}(zero).(interface{ TableName() string }); ok {
-		tableName = tabler.TableName()
-	}
+	tableName := tableNameOf(ctx, entityType)
 	
 	// Detect PostgreSQL by checking the driver name
 	if db.Dialector.Name() == "postgres" {
-		translator = query.NewPostgreSQLQueryTranslator()
-		
-		// Register field names
-		var fieldNames []string
-		for i := 0; i < entityType.NumField(); i++ {
-			field := entityType.Field(i)
-			if field.PkgPath == "" { // exported field
-				fieldNames = append(fieldNames, field.Name)
+		translator = planCacheOf(ctx).Translator(entityType, func() *query.PostgreSQLQueryTranslator {
+			translator := query.NewPostgreSQLQueryTranslator()
+
+			// Register field names
+			translator.RegisterEntityFields(tableName, models.FieldNames(entityType))
+
+			// Map fields to their columns when a naming convention or column tag renames them
+			stmt := &gorm.Statement{DB: db}
+			if err := stmt.Parse(new(T)); err == nil {
+				columns := make(map[string]string)
+				for _, field := range stmt.Schema.Fields {
+					if field.DBName != "" && field.DBName != field.Name {
+						columns[field.Name] = field.DBName
+					}
+				}
+				translator.RegisterEntityColumns(tableName, columns)
 			}
-		}
-		translator.RegisterEntityFields(tableName, fieldNames)
+			return translator
+		})
 	}
 
-	return &LinqDbSet[T]{
+	dbSet := &LinqDbSet[T]{
 		db:         db,
 		entityType: entityType,
 		context:    ctx,
 		translator: translator,
 		tableName:  tableName,
 	}
+	return dbSet.withDefaultScopes()
+}
+
+// clone returns a copy of the set with a new query - chained methods never mutate the receiver
+// The query is a new GORM session: calls chained on it copy its statement instead of adding their
+// clauses to it, so branches of one set (q.Where(a), q.Where(b)) don't see each other's conditions
+func (ds *LinqDbSet[T]) clone(db *gorm.DB) *LinqDbSet[T] {
+	newDbSet := *ds
+	newDbSet.db = db.Session(&gorm.Session{})
+	return &newDbSet
+}
+
+// planCacheOf returns the context's translation cache, or nil (no caching) outside a DbContext
+func planCacheOf(ctx interface{}) *query.PlanCache {
+	if provider, ok := ctx.(interface{ QueryPlanCache() *query.PlanCache }); ok {
+		return provider.QueryPlanCache()
+	}
+	return nil
+}
+
+// tableNameOf returns the table of an entity type: the one of the context's entity model when it is
+// registered (ToTable, table naming), otherwise its TableName() method or struct name
+func tableNameOf(ctx interface{}, entityType reflect.Type) string {
+	if provider, ok := ctx.(interface {
+		GetEntityModel(reflect.Type) *models.EntityModel
+	}); ok {
+		if entityModel := provider.GetEntityModel(entityType); entityModel != nil {
+			return entityModel.TableName
+		}
+	}
+	if tabler, ok := reflect.New(entityType).Interface().(interface{ TableName() string }); ok {
+		return tabler.TableName()
+	}
+	return entityType.Name()
+}
+
+// translateCondition quotes the field names of a raw condition, reusing earlier translations of the same text
+func (ds *LinqDbSet[T]) translateCondition(condition string) string {
+	if ds.translator == nil {
+		return condition
+	}
+	return planCacheOf(ds.context).Lookup(ds.entityType, query.PlanCondition, condition, func() string {
+		return ds.translator.TranslateQuery(ds.tableName, condition)
+	})
 }
 
 // trackEntity tracks an entity for change detection if context is available
@@ -99,6 +164,7 @@ func (ds *LinqDbSet[T]) trackEntity(entity *T) {
 // 1. Where("Id = ?", value) - SQL with parameters
 // 2. Where("Id", value) - field name with value
 // 3. Where(&User{Id: 1}) - struct pointer like GORM
+// 4. Where(RawExpr("ts_rank(fts, ?) > ?", q, 0.1)) - raw SQL fragment with parameters
 func (ds *LinqDbSet[T]) Where(args ...interface{}) *LinqDbSet[T] {
 	if len(args) == 0 {
 		return ds
@@ -107,6 +173,18 @@ func (ds *LinqDbSet[T]) Where(args ...interface{}) *LinqDbSet[T] {
 	// Pattern 1: Struct pointer like GORM Where(&User{Id: 1})
 	if len(args) == 1 {
 		arg := args[0]
+		// Typed predicate: Where(And(Eq(UserF.IsActive, true), Gt(UserF.Age, 30)))
+		if predicate, ok := arg.(Predicate); ok {
+			return ds.wherePredicate(predicate, false)
+		}
+		// Raw fragment with bound parameters: Where(RawExpr("ts_rank(fts, ?) > ?", q, 0.1))
+		if raw, ok := arg.(RawExpression); ok {
+			return ds.whereRaw("Where", raw, false)
+		}
+		// Raw condition without parameters, e.g. a correlated subquery: Where("AuthorId = Users.Id")
+		if condition, ok := arg.(string); ok {
+			return ds.clone(ds.db.Where(ds.translateCondition(condition)))
+		}
 		// Check if it's a pointer to our entity type
 		if entityPtr, ok := arg.(*T); ok {
 			return ds.WhereEntity(*entityPtr)
@@ -119,9 +197,9 @@ func (ds *LinqDbSet[T]) Where(args ...interface{}) *LinqDbSet[T] {
 		return ds.WhereStruct(arg)
 	}
 	
-	// Pattern 2: Where("Id", value) - field name with value
+	// Pattern 2: Where("Id", value) - field name with value (conditions with placeholders fall through to pattern 3)
 	if len(args) == 2 {
-		if fieldName, ok := args[0].(string); ok {
+		if fieldName, ok := args[0].(string); ok && !strings.Contains(fieldName, "?") {
 			return ds.WhereField(fieldName, args[1])
 		}
 	}
@@ -129,23 +207,13 @@ func (ds *LinqDbSet[T]) Where(args ...interface{}) *LinqDbSet[T] {
 	// Pattern 3: Where("Id = ?", value) - SQL with parameters
 	if len(args) >= 2 {
 		if condition, ok := args[0].(string); ok {
-			quotedFieldName := condition
-			if ds.translator != nil {
-				quotedFieldName = ds.translator.TranslateQuery(ds.tableName, condition)
-			}
 			// Create a new LinqDbSet to avoid mutating the original
-			newDbSet := &LinqDbSet[T]{
-				db:         ds.db.Where(quotedFieldName, args[1:]...),
-				entityType: ds.entityType,
-				context:    ds.context,
-				translator: ds.translator,
-				tableName:  ds.tableName,
-			}
+			newDbSet := ds.clone(ds.db.Where(ds.translateCondition(condition), args[1:]...))
 			return newDbSet
 		}
 	}
 	
-	return ds
+	return ds.unsupportedArguments("Where", args)
 }
 
 // FirstOrDefault - gets first element matching predicate or zero value
@@ -153,18 +221,12 @@ func (ds *LinqDbSet[T]) Where(args ...interface{}) *LinqDbSet[T] {
 // DEPRECATED OLD PATTERN: user := h.dbContext.Files.FirstOrDefault() - WRONG! Missing error handling
 // CORRECT NEW PATTERN: user, err := h.dbContext.Files.FirstOrDefault(); if err != nil { ... }
 func (ds *LinqDbSet[T]) FirstOrDefault(predicate ...Expression[T]) (*T, error) {
-	query := ds.db.Model(new(T))
-	
-	if len(predicate) > 0 {
-		// Convert lambda to SQL - simplified approach
-		condition := ds.parseExpression(predicate[0])
-		if condition != "" {
-			query = query.Where(condition)
-		}
-	}
+	query := ds.withPredicate(ds.query().Model(new(T)), "FirstOrDefault", predicate)
 	
 	var result T
-	err := query.First(&result).Error
+	err := ds.cachedRun(query, "first", &result, func(tx *gorm.DB, dest interface{}) *gorm.DB {
+		return tx.First(dest)
+	})
 	
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -175,6 +237,7 @@ func (ds *LinqDbSet[T]) FirstOrDefault(predicate ...Expression[T]) (*T, error) {
 	
 	// Automatically track the loaded entity for change detection
 	resultPtr := &result
+	ds.resolveIdentities(resultPtr)
 	ds.trackEntity(resultPtr)
 	
 	return resultPtr, nil
@@ -184,7 +247,7 @@ func (ds *LinqDbSet[T]) FirstOrDefault(predicate ...Expression[T]) (*T, error) {
 // 1. First() - get first element
 // 2. First(&Entity{Field: value}) - find by entity pattern (like GORM)
 func (ds *LinqDbSet[T]) First(args ...interface{}) (*T, error) {
-	query := ds.db.Model(new(T))
+	query := ds.query().Model(new(T))
 	
 	// If entity pattern provided, use it as WHERE condition
 	if len(args) == 1 {
@@ -201,10 +264,7 @@ func (ds *LinqDbSet[T]) First(args ...interface{}) (*T, error) {
 					continue
 				}
 				
-				fieldName := field.Name
-				if ds.translator != nil {
-					fieldName = ds.translator.GetQuotedFieldName(fieldName)
-				}
+				fieldName := ds.quoteField(field.Name)
 				
 				query = query.Where(fmt.Sprintf("%s = ?", fieldName), fieldValue.Interface())
 			}
@@ -212,94 +272,341 @@ func (ds *LinqDbSet[T]) First(args ...interface{}) (*T, error) {
 	}
 	
 	var result T
-	err := query.First(&result).Error
+	err := ds.cachedRun(query, "first", &result, func(tx *gorm.DB, dest interface{}) *gorm.DB {
+		return tx.First(dest)
+	})
 	if err != nil {
-		return nil, err
+		return nil, dberrors.Translate(err)
 	}
 	
 	// Automatically track the loaded entity for change detection
 	resultPtr := &result
+	ds.resolveIdentities(resultPtr)
 	ds.trackEntity(resultPtr)
 	
 	return resultPtr, nil
 }
 
-// Single - gets exactly one element matching predicate
+// Single - EF Core: Single() - gets exactly one element matching the accumulated query
+// Returns ErrNotFound when there are no matches and an error when there is more than one
 func (ds *LinqDbSet[T]) Single(predicate ...Expression[T]) (*T, error) {
-	query := ds.db.Model(new(T))
-	
-	if len(predicate) > 0 {
-		condition := ds.parseExpression(predicate[0])
-		if condition != "" {
-			query = query.Where(condition)
-		}
+	result, err := ds.SingleOrDefault(predicate...)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, dberrors.Translate(gorm.ErrRecordNotFound)
 	}
+	return result, nil
+}
+
+// SingleOrDefault - EF Core: SingleOrDefault() - gets the only matching element or nil
+// Returns an error when more than one element matches
+func (ds *LinqDbSet[T]) SingleOrDefault(predicate ...Expression[T]) (*T, error) {
+	query := ds.withPredicate(ds.query().Model(new(T)), "SingleOrDefault", predicate)
 	
+	// Fetch at most two rows - enough to detect a second match
 	var results []T
 	err := query.Limit(2).Find(&results).Error
 	if err != nil {
-		return nil, err
+		return nil, dberrors.Translate(err)
 	}
 	
 	if len(results) == 0 {
-		return nil, gorm.ErrRecordNotFound
+		return nil, nil
 	}
 	if len(results) > 1 {
 		return nil, fmt.Errorf("sequence contains more than one element")
 	}
 	
-	return &results[0], nil
+	// Automatically track the loaded entity for change detection
+	resultPtr := &results[0]
+	ds.resolveIdentities(resultPtr)
+	ds.trackEntity(resultPtr)
+	
+	return resultPtr, nil
 }
 
-// Any - checks if any element matches predicate
-func (ds *LinqDbSet[T]) Any(predicate ...Expression[T]) (bool, error) {
-	query := ds.db.Model(new(T))
+// Last - EF Core: Last() - gets the last element of the accumulated query
+// Reverses the query's ORDER BY, or orders by primary key descending when there is none
+func (ds *LinqDbSet[T]) Last(predicate ...Expression[T]) (*T, error) {
+	result, err := ds.LastOrDefault(predicate...)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, dberrors.Translate(gorm.ErrRecordNotFound)
+	}
+	return result, nil
+}
+
+// LastOrDefault - EF Core: LastOrDefault() - gets the last element or nil when there are no matches
+func (ds *LinqDbSet[T]) LastOrDefault(predicate ...Expression[T]) (*T, error) {
+	// Work on a copy of the statement so reversing the order doesn't leak into ds
+	query := ds.withPredicate(ds.db.Session(&gorm.Session{}).Model(new(T)), "LastOrDefault", predicate)
 	
-	if len(predicate) > 0 {
-		condition := ds.parseExpression(predicate[0])
-		if condition != "" {
-			query = query.Where(condition)
+	// Ordering added directly on the gorm query comes first, then the LINQ orderings
+	orderBy, hasOrderBy := query.Statement.Clauses["ORDER BY"].Expression.(clause.OrderBy)
+	if hasOrderBy {
+		delete(query.Statement.Clauses, "ORDER BY")
+	}
+	linqOrderBy := ds.orderByClause()
+	orderBy.Columns = append(orderBy.Columns, linqOrderBy.Columns...)
+	
+	var result T
+	var err error
+	if linqOrderBy.Expression != nil {
+		var terms []clause.Expression
+		if len(orderBy.Columns) > 0 {
+			terms = append(terms, reverseOrderBy(orderBy))
+		}
+		terms = append(terms, ds.orderingExpression(true))
+		err = query.Order(clause.OrderBy{Expression: clause.CommaExpression{Exprs: terms}}).Take(&result).Error
+	} else if len(orderBy.Columns) > 0 {
+		err = query.Order(reverseOrderBy(orderBy)).Take(&result).Error
+	} else {
+		err = query.Last(&result).Error
+	}
+	
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, dberrors.Translate(err)
+	}
+	
+	// Automatically track the loaded entity for change detection
+	resultPtr := &result
+	ds.resolveIdentities(resultPtr)
+	ds.trackEntity(resultPtr)
+	
+	return resultPtr, nil
+}
+
+// reverseOrderBy flips the direction of every ORDER BY column
+// Raw columns such as "\"CreatedAt\" DESC, \"Id\"" are rewritten term by term
+func reverseOrderBy(orderBy clause.OrderBy) clause.OrderBy {
+	reversed := clause.OrderBy{}
+	for _, column := range orderBy.Columns {
+		if !column.Column.Raw {
+			column.Desc = !column.Desc
+			reversed.Columns = append(reversed.Columns, column)
+			continue
 		}
+		
+		terms := strings.Split(column.Column.Name, ",")
+		for i, term := range terms {
+			term = strings.TrimSpace(term)
+			upper := strings.ToUpper(term)
+			switch {
+			case strings.HasSuffix(upper, " DESC"):
+				terms[i] = strings.TrimSpace(term[:len(term)-len(" DESC")]) + " ASC"
+			case strings.HasSuffix(upper, " ASC"):
+				terms[i] = strings.TrimSpace(term[:len(term)-len(" ASC")]) + " DESC"
+			default:
+				terms[i] = term + " DESC"
+			}
+		}
+		column.Column.Name = strings.Join(terms, ", ")
+		reversed.Columns = append(reversed.Columns, column)
 	}
+	return reversed
+}
+
+// Any - checks if any element matches predicate
+func (ds *LinqDbSet[T]) Any(predicate ...Expression[T]) (bool, error) {
+	query := ds.withPredicate(ds.paginated(), "Any", predicate)
 	
+	// Counted like Count so Skip/Take limit the rows looked at, not the single count row
 	var count int64
-	err := query.Count(&count).Error
+	err := countQuery(query, &count).Error
 	return count > 0, err
 }
 
+// All - EF Core: All(x => condition) - true when every element of the query satisfies the condition
+// Runs server-side as NOT EXISTS over the inverted condition: ctx.Users.Where("Active", true).All("Age >= ?", 18)
+func (ds *LinqDbSet[T]) All(condition string, args ...interface{}) (bool, error) {
+	condition = ds.translateCondition(condition)
+	
+	// NULL comparisons count as not satisfied, matching EF Core's semantics
+	violations := ds.paginated().Select("1").
+		Where(fmt.Sprintf("NOT COALESCE((%s), FALSE)", condition), args...)
+	return ds.notExists(violations)
+}
+
+// None - true when no element of the query satisfies the condition
+// Runs server-side as NOT EXISTS: ctx.Orders.Where("UserId", id).None("Status = ?", "overdue")
+func (ds *LinqDbSet[T]) None(condition string, args ...interface{}) (bool, error) {
+	condition = ds.translateCondition(condition)
+	
+	matches := ds.paginated().Select("1").Where(condition, args...)
+	return ds.notExists(matches)
+}
+
+// notExists evaluates SELECT NOT EXISTS (subquery) in a single round trip
+func (ds *LinqDbSet[T]) notExists(subquery *gorm.DB) (bool, error) {
+	var result bool
+	err := ds.db.Session(&gorm.Session{NewDB: true}).Raw("SELECT NOT EXISTS (?)", subquery).Scan(&result).Error
+	if err != nil {
+		return false, dberrors.Translate(err)
+	}
+	return result, nil
+}
+
+// Subquery is implemented by every LinqDbSet so sets of different entity types can be composed
+type Subquery interface {
+	subqueryDB() *gorm.DB
+}
+
+// subqueryDB returns an independent copy of the accumulated query for embedding in another query
+func (ds *LinqDbSet[T]) subqueryDB() *gorm.DB {
+	return ds.query().Session(&gorm.Session{}).Model(new(T))
+}
+
+// WhereExists - keeps rows for which the correlated subquery returns any row
+// Usage: ctx.Users.WhereExists(ctx.Posts.Where("AuthorId = Users.Id").Where("Published", true))
+func (ds *LinqDbSet[T]) WhereExists(subquery Subquery) *LinqDbSet[T] {
+	return ds.whereSubquery("EXISTS (?)", subquery.subqueryDB().Select("1"))
+}
+
+// WhereNotExists - keeps rows for which the correlated subquery returns no rows
+func (ds *LinqDbSet[T]) WhereNotExists(subquery Subquery) *LinqDbSet[T] {
+	return ds.whereSubquery("NOT EXISTS (?)", subquery.subqueryDB().Select("1"))
+}
+
+// WhereInQuery - keeps rows whose field value is returned by the subquery
+// The subquery must select a single column: ctx.Users.WhereInQuery("Id", ctx.Posts.Select("AuthorId"))
+func (ds *LinqDbSet[T]) WhereInQuery(fieldName string, subquery Subquery) *LinqDbSet[T] {
+	return ds.whereSubquery(ds.quoteField(fieldName)+" IN (?)", subquery.subqueryDB())
+}
+
+// WhereNotInQuery - keeps rows whose field value is not returned by the subquery
+func (ds *LinqDbSet[T]) WhereNotInQuery(fieldName string, subquery Subquery) *LinqDbSet[T] {
+	return ds.whereSubquery(ds.quoteField(fieldName)+" NOT IN (?)", subquery.subqueryDB())
+}
+
+func (ds *LinqDbSet[T]) whereSubquery(condition string, subquery *gorm.DB) *LinqDbSet[T] {
+	// Create a new LinqDbSet to avoid mutating the original
+	return ds.clone(ds.db.Where(condition, subquery))
+}
+
+// quoteField resolves a Go field name, column name or json tag to its column and quotes it
+// PostgreSQL uses the translator's quoted column; other drivers quote the schema column with the dialect.
+// Names that match no field (expressions, qualified references) keep the previous behaviour
+func (ds *LinqDbSet[T]) quoteField(fieldName string) string {
+	if len(ds.db.Statement.Joins) > 0 {
+		if field := ds.lookupField(fieldName); field != nil {
+			// Qualify with the entity's table so columns stay unambiguous next to joined navigations
+			return ds.db.Statement.Quote(clause.Column{Table: field.Schema.Table, Name: field.DBName})
+		}
+	}
+	return planCacheOf(ds.context).Lookup(ds.entityType, query.PlanField, fieldName, func() string {
+		return ds.quoteUnjoinedField(fieldName)
+	})
+}
+
+// quoteUnjoinedField resolves and quotes a field of a query without joins
+func (ds *LinqDbSet[T]) quoteUnjoinedField(fieldName string) string {
+	field := ds.lookupField(fieldName)
+	if ds.translator != nil {
+		if field != nil {
+			return ds.translator.GetQuotedFieldName(field.DBName)
+		}
+		return ds.translator.GetQuotedFieldName(ds.translator.ColumnName(ds.tableName, fieldName))
+	}
+	if field != nil {
+		return ds.db.Statement.Quote(field.DBName)
+	}
+	return fieldName
+}
+
+// lookupField finds the schema field for a Go field name, column name or json tag (exact match first,
+// then case-insensitive) so WhereField("username") and WhereField("Username") behave the same on every driver
+func (ds *LinqDbSet[T]) lookupField(name string) *schema.Field {
+	stmt := &gorm.Statement{DB: ds.db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil
+	}
+	return lookupSchemaField(stmt.Schema, name)
+}
+
 // Count - counts elements matching predicate
+// Grouped, distinct and paged queries are counted through a subquery so the result is the number of rows
+// the query returns: ctx.Posts.Select("AuthorId").Distinct().Count() counts distinct authors
 func (ds *LinqDbSet[T]) Count(predicate ...Expression[T]) (int64, error) {
-	query := ds.db.Model(new(T))
+	query := ds.withPredicate(ds.paginated(), "Count", predicate)
+	
+	var count int64
+	err := ds.cachedRun(query, "count", &count, func(tx *gorm.DB, dest interface{}) *gorm.DB {
+		return countQuery(tx, dest.(*int64))
+	})
+	return count, dberrors.Translate(err)
+}
+
+// countQuery runs COUNT(*) for query into count, wrapping it in a subquery when COUNT(*) on the query
+// itself would not count the rows it returns
+func countQuery(query *gorm.DB, count *int64) *gorm.DB {
+	if !countNeedsSubquery(query) {
+		return query.Count(count)
+	}
 	
-	if len(predicate) > 0 {
-		condition := ds.parseExpression(predicate[0])
-		if condition != "" {
-			query = query.Where(condition)
+	subquery := query.Session(&gorm.Session{})
+	if groupBy, ok := subquery.Statement.Clauses["GROUP BY"].Expression.(clause.GroupBy); ok && len(subquery.Statement.Selects) == 0 {
+		// SELECT * is invalid with GROUP BY - project the grouping columns instead
+		columns := make([]string, len(groupBy.Columns))
+		for i, column := range groupBy.Columns {
+			columns[i] = subquery.Statement.Quote(column)
 		}
+		subquery = subquery.Select(strings.Join(columns, ", "))
 	}
 	
+	return query.Session(&gorm.Session{NewDB: true}).
+		Raw("SELECT COUNT(*) FROM (?) AS counted", subquery).Find(count)
+}
+
+// LongCount - EF Core: LongCount() - same as Count, which already returns int64
+func (ds *LinqDbSet[T]) LongCount(predicate ...Expression[T]) (int64, error) {
+	return ds.Count(predicate...)
+}
+
+// CountDistinct - counts distinct non-null values of a field: ctx.Users.CountDistinct("Email")
+func (ds *LinqDbSet[T]) CountDistinct(fieldName string) (int64, error) {
 	var count int64
-	err := query.Count(&count).Error
-	return count, err
+	err := ds.paginated().Select(fmt.Sprintf("COUNT(DISTINCT %s)", ds.quoteField(fieldName))).Scan(&count).Error
+	return count, dberrors.Translate(err)
 }
 
-// ToList - gets all elements matching predicate
-func (ds *LinqDbSet[T]) ToList(predicate ...Expression[T]) ([]T, error) {
-	query := ds.db.Model(new(T))
-	
-	if len(predicate) > 0 {
-		condition := ds.parseExpression(predicate[0])
-		if condition != "" {
-			query = query.Where(condition)
+// countNeedsSubquery reports whether COUNT(*) on the query would not count the rows it returns
+func countNeedsSubquery(query *gorm.DB) bool {
+	if query.Statement.Distinct {
+		return true
+	}
+	for _, name := range []string{"GROUP BY", "LIMIT"} {
+		if _, ok := query.Statement.Clauses[name]; ok {
+			return true
 		}
 	}
+	for _, selected := range query.Statement.Selects {
+		if strings.Contains(strings.ToUpper(selected), "DISTINCT") {
+			return true
+		}
+	}
+	return false
+}
+
+// ToList - gets all elements matching predicate
+func (ds *LinqDbSet[T]) ToList(predicate ...Expression[T]) ([]T, error) {
+	query := ds.withPredicate(ds.query().Model(new(T)), "ToList", predicate)
 	
 	var results []T
-	err := query.Find(&results).Error
+	err := ds.cachedRun(query, "list", &results, func(tx *gorm.DB, dest interface{}) *gorm.DB {
+		return tx.Find(dest)
+	})
 	if err != nil {
 		return results, err
 	}
 	
+	ds.resolveIdentities(results)
 	// Automatically track all loaded entities for change detection
 	for i := range results {
 		ds.trackEntity(&results[i])
@@ -312,189 +619,192 @@ func (ds *LinqDbSet[T]) ToList(predicate ...Expression[T]) ([]T, error) {
 // 1. OrderBy(func(T) interface{}) - field selector function
 // 2. OrderBy("fieldName") - field name string
 // 3. OrderBy(&Entity.Field) - pointer-based field selector
+// 4. OrderBy(RawExpr("similarity(\"Title\", ?)", q)) - raw SQL fragment with parameters
 func (ds *LinqDbSet[T]) OrderBy(args ...interface{}) *LinqDbSet[T] {
-	if len(args) == 0 {
-		return ds
+	if raw, ok := rawOrdering(args); ok {
+		return ds.orderByRaw("OrderBy", raw, false)
 	}
-	
-	// Pattern 1: Function selector OrderBy(func(T) interface{})
-	if len(args) == 1 {
-		if selector, ok := args[0].(func(T) interface{}); ok {
-			fieldName := ds.parseFieldSelector(selector)
-			if fieldName != "" {
-				quotedFieldName := fieldName
-				if ds.translator != nil {
-					quotedFieldName = ds.translator.GetQuotedFieldName(fieldName)
-				}
-				// Create a new LinqDbSet to avoid mutating the original
-				newDbSet := &LinqDbSet[T]{
-					db:         ds.db.Order(quotedFieldName + " ASC"),
-					entityType: ds.entityType,
-					context:    ds.context,
-					translator: ds.translator,
-					tableName:  ds.tableName,
-				}
-				return newDbSet
-			}
-			return ds
-		}
-		
-		// Pattern 2: String field name OrderBy("fieldName")
-		if fieldName, ok := args[0].(string); ok {
-			log.Printf("[GONTEXT DEBUG] LinqDbSet[%T].OrderBy called with field name: %s", *new(T), fieldName)
-			
-			quotedFieldName := fieldName
-			if ds.translator != nil {
-				quotedFieldName = ds.translator.GetQuotedFieldName(fieldName)
-				log.Printf("[GONTEXT DEBUG] Field name translated: %s -> %s", fieldName, quotedFieldName)
-			}
-			
-			orderClause := quotedFieldName + " ASC"
-			log.Printf("[GONTEXT DEBUG] Adding ORDER BY: %s", orderClause)
-			// Create a new LinqDbSet to avoid mutating the original
-			newDbSet := &LinqDbSet[T]{
-				db:         ds.db.Order(orderClause),
-				entityType: ds.entityType,
-				context:    ds.context,
-				translator: ds.translator,
-				tableName:  ds.tableName,
-			}
-			return newDbSet
-		}
-		
-		// Pattern 3: Pointer-based field selector OrderBy(&Entity.Field)
-		fieldName := ds.extractFieldNameFromPointer(args[0])
-		if fieldName != "" {
-			log.Printf("[GONTEXT DEBUG] LinqDbSet[%T].OrderBy called with pointer field: %s", *new(T), fieldName)
-			
-			quotedFieldName := fieldName
-			if ds.translator != nil {
-				quotedFieldName = ds.translator.GetQuotedFieldName(fieldName)
-				log.Printf("[GONTEXT DEBUG] Field name translated: %s -> %s", fieldName, quotedFieldName)
-			}
-			
-			orderClause := quotedFieldName + " ASC"
-			log.Printf("[GONTEXT DEBUG] Adding ORDER BY: %s", orderClause)
-			// Create a new LinqDbSet to avoid mutating the original
-			newDbSet := &LinqDbSet[T]{
-				db:         ds.db.Order(orderClause),
-				entityType: ds.entityType,
-				context:    ds.context,
-				translator: ds.translator,
-				tableName:  ds.tableName,
-			}
-			return newDbSet
+	if fieldName := ds.orderingFieldName(args...); fieldName != "" {
+		if invalid := ds.unknownField("OrderBy", fieldName); invalid != nil {
+			return invalid
 		}
+		return ds.withOrdering(fieldName, false)
 	}
-	
-	return ds
+	return ds.unsupportedArguments("OrderBy", args)
 }
-
 // OrderByDescending - overloaded method that supports multiple patterns:
 // 1. OrderByDescending(func(T) interface{}) - field selector function
 // 2. OrderByDescending("fieldName") - field name string
 // 3. OrderByDescending(&Entity.Field) - pointer-based field selector
+// 4. OrderByDescending(RawExpr("ts_rank(\"Search\", plainto_tsquery(?))", q)) - raw SQL fragment with parameters
 func (ds *LinqDbSet[T]) OrderByDescending(args ...interface{}) *LinqDbSet[T] {
-	if len(args) == 0 {
-		return ds
+	if raw, ok := rawOrdering(args); ok {
+		return ds.orderByRaw("OrderByDescending", raw, true)
 	}
-	
-	// Pattern 1: Function selector OrderByDescending(func(T) interface{})
-	if len(args) == 1 {
-		if selector, ok := args[0].(func(T) interface{}); ok {
-			fieldName := ds.parseFieldSelector(selector)
-			if fieldName != "" {
-				quotedFieldName := fieldName
-				if ds.translator != nil {
-					quotedFieldName = ds.translator.GetQuotedFieldName(fieldName)
-				}
-				// Create a new LinqDbSet to avoid mutating the original
-				newDbSet := &LinqDbSet[T]{
-					db:         ds.db.Order(quotedFieldName + " DESC"),
-					entityType: ds.entityType,
-					context:    ds.context,
-					translator: ds.translator,
-					tableName:  ds.tableName,
-				}
-				return newDbSet
-			}
-			return ds
+	if fieldName := ds.orderingFieldName(args...); fieldName != "" {
+		if invalid := ds.unknownField("OrderByDescending", fieldName); invalid != nil {
+			return invalid
 		}
-		
-		// Pattern 2: String field name OrderByDescending("fieldName")
-		if fieldName, ok := args[0].(string); ok {
-			log.Printf("[GONTEXT DEBUG] LinqDbSet[%T].OrderByDescending called with field name: %s", *new(T), fieldName)
-			
-			quotedFieldName := fieldName
-			if ds.translator != nil {
-				quotedFieldName = ds.translator.GetQuotedFieldName(fieldName)
-				log.Printf("[GONTEXT DEBUG] Field name translated: %s -> %s", fieldName, quotedFieldName)
-			}
-			
-			orderClause := quotedFieldName + " DESC"
-			log.Printf("[GONTEXT DEBUG] Adding ORDER BY: %s", orderClause)
-			// Create a new LinqDbSet to avoid mutating the original
-			newDbSet := &LinqDbSet[T]{
-				db:         ds.db.Order(orderClause),
-				entityType: ds.entityType,
-				context:    ds.context,
-				translator: ds.translator,
-				tableName:  ds.tableName,
-			}
-			return newDbSet
+		return ds.withOrdering(fieldName, true)
+	}
+	return ds.unsupportedArguments("OrderByDescending", args)
+}
+
+// ThenBy - EF Core: OrderBy(x => x.Field1).ThenBy(x => x.Field2) - adds a secondary ascending ordering
+// Accepts the same patterns as OrderBy: ThenBy("fieldName"), ThenBy(func(T) interface{}), ThenBy(&Entity.Field)
+func (ds *LinqDbSet[T]) ThenBy(args ...interface{}) *LinqDbSet[T] {
+	if raw, ok := rawOrdering(args); ok {
+		return ds.orderByRaw("ThenBy", raw, false)
+	}
+	if fieldName := ds.orderingFieldName(args...); fieldName != "" {
+		if invalid := ds.unknownField("ThenBy", fieldName); invalid != nil {
+			return invalid
 		}
-		
-		// Pattern 3: Pointer-based field selector OrderByDescending(&Entity.Field)
-		fieldName := ds.extractFieldNameFromPointer(args[0])
-		if fieldName != "" {
-			log.Printf("[GONTEXT DEBUG] LinqDbSet[%T].OrderByDescending called with pointer field: %s", *new(T), fieldName)
-			
-			quotedFieldName := fieldName
-			if ds.translator != nil {
-				quotedFieldName = ds.translator.GetQuotedFieldName(fieldName)
-				log.Printf("[GONTEXT DEBUG] Field name translated: %s -> %s", fieldName, quotedFieldName)
-			}
-			
-			orderClause := quotedFieldName + " DESC"
-			log.Printf("[GONTEXT DEBUG] Adding ORDER BY: %s", orderClause)
-			// Create a new LinqDbSet to avoid mutating the original
-			newDbSet := &LinqDbSet[T]{
-				db:         ds.db.Order(orderClause),
-				entityType: ds.entityType,
-				context:    ds.context,
-				translator: ds.translator,
-				tableName:  ds.tableName,
-			}
-			return newDbSet
+		return ds.withOrdering(fieldName, false)
+	}
+	return ds.unsupportedArguments("ThenBy", args)
+}
+
+// ThenByDescending - EF Core: OrderBy(x => x.Field1).ThenByDescending(x => x.Field2)
+func (ds *LinqDbSet[T]) ThenByDescending(args ...interface{}) *LinqDbSet[T] {
+	if raw, ok := rawOrdering(args); ok {
+		return ds.orderByRaw("ThenByDescending", raw, true)
+	}
+	if fieldName := ds.orderingFieldName(args...); fieldName != "" {
+		if invalid := ds.unknownField("ThenByDescending", fieldName); invalid != nil {
+			return invalid
 		}
+		return ds.withOrdering(fieldName, true)
+	}
+	return ds.unsupportedArguments("ThenByDescending", args)
+}
+
+// ordering is a single ORDER BY term, kept as a field name until the query executes
+type ordering struct {
+	fieldName  string
+	desc       bool
+	column     string      // Resolved column for navigation paths such as "Author.Username"
+	expression *clause.Expr // SQL expression with parameters ordered by instead of a field
+}
+
+// orderingFieldName resolves the field named by an OrderBy/ThenBy argument:
+// a field selector function, a field name string or a pointer to a field
+func (ds *LinqDbSet[T]) orderingFieldName(args ...interface{}) string {
+	if len(args) != 1 {
+		return ""
 	}
 	
-	return ds
+	if selector, ok := args[0].(func(T) interface{}); ok {
+		return ds.parseFieldSelector(selector)
+	}
+	
+	if fieldName, ok := args[0].(string); ok {
+		return fieldName
+	}
+	
+	return ds.extractFieldNameFromPointer(args[0])
+}
+
+// rawOrdering returns the raw fragment an OrderBy/ThenBy call orders by, if that is its argument
+func rawOrdering(args []interface{}) (RawExpression, bool) {
+	if len(args) != 1 {
+		return RawExpression{}, false
+	}
+	raw, ok := args[0].(RawExpression)
+	return raw, ok
 }
 
+// withOrdering returns a copy of the set with another ORDER BY term appended
+// Terms are applied in call order when the query executes, so ThenBy always follows OrderBy
+func (ds *LinqDbSet[T]) withOrdering(fieldName string, desc bool) *LinqDbSet[T] {
+	newDbSet := ds.clone(ds.db)
+	term := ordering{fieldName: fieldName, desc: desc}
+	if strings.Contains(fieldName, ".") {
+		// Sorting by a related entity's column joins the navigation now so the column is in scope
+		joined, column := ds.navigate("OrderBy", fieldName)
+		newDbSet = joined.clone(joined.db)
+		term.column = column
+	}
+	newDbSet.orderings = append(append([]ordering(nil), ds.orderings...), term)
+	return newDbSet
+}
+
+// orderByClause builds the ORDER BY clause for the accumulated orderings
+// PostgreSQL gets quoted PascalCase identifiers; other drivers get the schema's column names quoted by the dialect
+func (ds *LinqDbSet[T]) orderByClause() clause.OrderBy {
+	orderBy := clause.OrderBy{}
+	if len(ds.orderings) == 0 {
+		return orderBy
+	}
+	
+	for _, term := range ds.orderings {
+		if term.expression != nil {
+			// Expressions carry parameters, which only an ORDER BY expression can hold
+			return clause.OrderBy{Expression: ds.orderingExpression(false)}
+		}
+		orderBy.Columns = append(orderBy.Columns, clause.OrderByColumn{Column: ds.orderingColumn(term), Desc: term.desc})
+	}
+	return orderBy
+}
+
+// orderingColumn resolves the column of a field ordering term
+func (ds *LinqDbSet[T]) orderingColumn(term ordering) clause.Column {
+	column := clause.Column{Name: term.fieldName, Raw: true}
+	if term.column != "" {
+		column.Name = term.column
+	} else if ds.translator != nil {
+		column.Name = ds.quoteField(term.fieldName)
+	} else if field := ds.lookupField(term.fieldName); field != nil {
+		column = clause.Column{Name: field.DBName}
+		if len(ds.db.Statement.Joins) > 0 {
+			column.Table = clause.CurrentTable
+		}
+	}
+	return column
+}
+
+// orderingExpression builds the accumulated orderings as one ORDER BY expression, in reverse for Last
+func (ds *LinqDbSet[T]) orderingExpression(reverse bool) clause.Expression {
+	terms := make([]clause.Expression, 0, len(ds.orderings))
+	for _, term := range ds.orderings {
+		direction := ""
+		if term.desc != reverse {
+			direction = " DESC"
+		}
+		if term.expression != nil {
+			terms = append(terms, clause.Expr{SQL: term.expression.SQL + direction, Vars: term.expression.Vars})
+		} else {
+			terms = append(terms, clause.Expr{SQL: "?" + direction, Vars: []interface{}{ds.orderingColumn(term)}})
+		}
+	}
+	return clause.CommaExpression{Exprs: terms}
+}
+
+// withOrderingExpression returns a copy of the set with an ORDER BY term on an SQL expression appended
+func (ds *LinqDbSet[T]) withOrderingExpression(expression clause.Expr, desc bool) *LinqDbSet[T] {
+	newDbSet := ds.clone(ds.db)
+	newDbSet.orderings = append(append([]ordering(nil), ds.orderings...), ordering{expression: &expression, desc: desc})
+	return newDbSet
+}
+
+// query returns the accumulated query with the ordering and pagination applied, for row-returning operations
+func (ds *LinqDbSet[T]) query() *gorm.DB {
+	if len(ds.orderings) == 0 {
+		return ds.paginate(ds.db)
+	}
+	return ds.paginate(ds.db.Session(&gorm.Session{}).Order(ds.orderByClause()))
+}
 // Take - takes specified number of elements
 func (ds *LinqDbSet[T]) Take(count int) *LinqDbSet[T] {
 	// Create a new LinqDbSet to avoid mutating the original
-	newDbSet := &LinqDbSet[T]{
-		db:         ds.db.Limit(count),
-		entityType: ds.entityType,
-		context:    ds.context,
-		translator: ds.translator,
-		tableName:  ds.tableName,
-	}
+	newDbSet := ds.clone(ds.db.Limit(count))
 	return newDbSet
 }
 
 // Skip - skips specified number of elements
 func (ds *LinqDbSet[T]) Skip(count int) *LinqDbSet[T] {
 	// Create a new LinqDbSet to avoid mutating the original
-	newDbSet := &LinqDbSet[T]{
-		db:         ds.db.Offset(count),
-		entityType: ds.entityType,
-		context:    ds.context,
-		translator: ds.translator,
-		tableName:  ds.tableName,
-	}
+	newDbSet := ds.clone(ds.db.Offset(count))
 	return newDbSet
 }
 
@@ -531,6 +841,7 @@ func (ds *LinqDbSet[T]) ById(id interface{}) (*T, error) {
 	
 	// Automatically track the loaded entity for change detection
 	resultPtr := &result
+	ds.resolveIdentities(resultPtr)
 	ds.trackEntity(resultPtr)
 	
 	return resultPtr, nil
@@ -552,14 +863,8 @@ func (ds *LinqDbSet[T]) WhereEntity(entity T) *LinqDbSet[T] {
 		entityType = entityType.Elem()
 	}
 	
-	// Create a new LinqDbSet to avoid mutating the original
-	newDbSet := &LinqDbSet[T]{
-		db:         ds.db,
-		entityType: ds.entityType,
-		context:    ds.context,
-		translator: ds.translator,
-		tableName:  ds.tableName,
-	}
+	db := ds.db
+	var skipped []skippedZero
 	
 	// Iterate through fields and build WHERE conditions
 	for i := 0; i < entityType.NumField(); i++ {
@@ -571,31 +876,22 @@ func (ds *LinqDbSet[T]) WhereEntity(entity T) *LinqDbSet[T] {
 			continue
 		}
 		
-		// Skip zero values (unset fields)
-		if fieldValue.IsZero() {
-			continue
-		}
-		
+		// Skip zero values (unset fields) unless requested with IncludeZero
 		fieldName := field.Name
-		quotedFieldName := fieldName
-		if ds.translator != nil {
-			quotedFieldName = ds.translator.GetQuotedFieldName(fieldName)
+		value, ok := ds.entityFilterValue(fieldName, fieldValue)
+		if !ok {
+			skipped = append(skipped, skippedZero{fieldName: fieldName, value: fieldValue, or: false})
+			continue
 		}
+		quotedFieldName := ds.quoteField(fieldName)
 		
-		// Check if the value is a string with comparison operators
-		value := fieldValue.Interface()
-		if strValue, ok := value.(string); ok {
-			// Parse operator from string value
-			operator, actualValue := ds.parseOperator(strValue)
-			condition := fmt.Sprintf("%s %s ?", quotedFieldName, operator)
-			newDbSet.db = newDbSet.db.Where(condition, actualValue)
-		} else {
-			// Default equality comparison
-			condition := fmt.Sprintf("%s = ?", quotedFieldName)
-			newDbSet.db = newDbSet.db.Where(condition, value)
-		}
+		// Comparison values and (unless disabled) strings like ">18" choose the operator
+		condition, args := ds.comparisonCondition(quotedFieldName, value)
+		db = db.Where(condition, args...)
 	}
 	
+	newDbSet := ds.clone(db)
+	newDbSet.skippedZero = append(append([]skippedZero(nil), ds.skippedZero...), skipped...)
 	return newDbSet
 }
 
@@ -614,77 +910,59 @@ func (ds *LinqDbSet[T]) WhereStruct(entity interface{}) *LinqDbSet[T] {
 		}
 	}
 	
-	return ds
+	return ds.unsupportedArguments("Where", []interface{}{entity})
 }
 
 // WhereField - helper for field-based filtering with comparison operators
 // DEPRECATED: Use the overloaded Where method instead: Where("fieldName", value) or Where(&Entity{Field: value})
-// Supports: WhereField("Age", 25), WhereField("Age", ">25"), WhereField("Age", ">=18"), etc.
+// Supports: WhereField("Age", 25), WhereField("Age", GreaterThan(25)), WhereField("Age", ">=18"), etc.
+// String operator parsing can be turned off with LiteralStrings() or ctx.SetStringOperatorParsing(false)
 func (ds *LinqDbSet[T]) WhereField(fieldName string, value interface{}) *LinqDbSet[T] {
-	// Apply PostgreSQL translation if available
-	quotedFieldName := fieldName
-	if ds.translator != nil {
-		quotedFieldName = ds.translator.GetQuotedFieldName(fieldName)
-	}
-	
-	// Create a new LinqDbSet instance to avoid mutating the original
-	newDbSet := &LinqDbSet[T]{
-		db:         ds.db,
-		entityType: ds.entityType,
-		context:    ds.context,
-		translator: ds.translator,
-		tableName:  ds.tableName,
+	// Deterministically encrypted columns match the value's ciphertext under every key
+	if column := ds.encryptedColumn(fieldName); column != nil {
+		values, err := column.EqualityValues(value)
+		if err != nil {
+			return ds.fail(call("Where", fieldName), err)
+		}
+		return ds.clone(ds.db.Where(fmt.Sprintf("%s IN ?", ds.quoteField(fieldName)), values))
 	}
+
+	// Resolve the column, joining navigations for paths like "Author.Username"
+	newDbSet, quotedFieldName := ds.navigate("WhereField", fieldName)
 	
 	return newDbSet.addComparisonCondition(quotedFieldName, value, "WHERE")
 }
 
+// encryptedColumn returns the encryption of a column of T configured with IsEncrypted, or nil
+func (ds *LinqDbSet[T]) encryptedColumn(fieldName string) *encryption.Column {
+	provider, ok := ds.context.(interface {
+		EncryptedColumns(reflect.Type) map[string]*encryption.Column
+	})
+	if !ok {
+		return nil
+	}
+	columns := provider.EncryptedColumns(ds.entityType)
+	if len(columns) == 0 {
+		return nil
+	}
+	if field := ds.lookupField(fieldName); field != nil {
+		return columns[field.Name]
+	}
+	return nil
+}
+
 // addComparisonCondition - helper to add comparison conditions with operator support
 func (ds *LinqDbSet[T]) addComparisonCondition(quotedFieldName string, value interface{}, conditionType string) *LinqDbSet[T] {
-	// Create a new LinqDbSet to avoid mutating the original
-	newDbSet := &LinqDbSet[T]{
-		db:         ds.db,
-		entityType: ds.entityType,
-		context:    ds.context,
-		translator: ds.translator,
-		tableName:  ds.tableName,
-	}
-	
-	// Handle comparison operators for numeric and string types
-	switch v := value.(type) {
-	case string:
-		// Check for operator prefixes in string values
-		operator, actualValue := ds.parseOperator(v)
-		condition := fmt.Sprintf("%s %s ?", quotedFieldName, operator)
-		
-		if conditionType == "WHERE" {
-			newDbSet.db = newDbSet.db.Where(condition, actualValue)
-		} else {
-			newDbSet.db = newDbSet.db.Or(condition, actualValue)
-		}
-		
-	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
-		// For numeric types, support direct comparison
-		condition := fmt.Sprintf("%s = ?", quotedFieldName)
-		
-		if conditionType == "WHERE" {
-			newDbSet.db = newDbSet.db.Where(condition, value)
-		} else {
-			newDbSet.db = newDbSet.db.Or(condition, value)
-		}
-		
-	default:
-		// Default equality comparison
-		condition := fmt.Sprintf("%s = ?", quotedFieldName)
-		
-		if conditionType == "WHERE" {
-			newDbSet.db = newDbSet.db.Where(condition, value)
-		} else {
-			newDbSet.db = newDbSet.db.Or(condition, value)
-		}
+	db := ds.db
+	
+	condition, args := ds.comparisonCondition(quotedFieldName, value)
+	if conditionType == "WHERE" {
+		db = db.Where(condition, args...)
+	} else {
+		db = db.Or(condition, args...)
 	}
 	
-	return newDbSet
+	return ds.clone(db)
 }
 
 // parseOperator - parses operator from string value
@@ -721,66 +999,101 @@ func (ds *LinqDbSet[T]) parseOperator(strValue string) (operator string, actualV
 // WhereFieldIn - helper for IN queries - EF Core: context.Users.Where(x => values.Contains(x.Field))
 func (ds *LinqDbSet[T]) WhereFieldIn(fieldName string, values []interface{}) *LinqDbSet[T] {
 	// Create a new LinqDbSet to avoid mutating the original
-	newDbSet := &LinqDbSet[T]{
-		db:         ds.db.Where(fmt.Sprintf("%s IN ?", fieldName), values),
-		entityType: ds.entityType,
-		context:    ds.context,
-		translator: ds.translator,
-		tableName:  ds.tableName,
+	newDbSet, column := ds.navigate("WhereFieldIn", fieldName)
+	encoded := make([]interface{}, len(values))
+	for i, value := range values {
+		encoded[i] = types.QueryValue(value)
 	}
-	return newDbSet
+	return newDbSet.clone(newDbSet.db.Where(fmt.Sprintf("%s IN ?", column), encoded))
 }
 
 // WhereFieldLike - helper for LIKE queries - EF Core: context.Users.Where(x => x.Field.Contains(pattern))
+// The pattern is matched literally: % and _ in user input are escaped (use WhereFieldLikeRaw for wildcards)
 func (ds *LinqDbSet[T]) WhereFieldLike(fieldName string, pattern string) *LinqDbSet[T] {
 	// Create a new LinqDbSet to avoid mutating the original
-	newDbSet := &LinqDbSet[T]{
-		db:         ds.db.Where(fmt.Sprintf("%s LIKE ?", fieldName), "%"+pattern+"%"),
-		entityType: ds.entityType,
-		context:    ds.context,
-		translator: ds.translator,
-		tableName:  ds.tableName,
-	}
-	return newDbSet
+	newDbSet, column := ds.navigate("WhereFieldLike", fieldName)
+	return newDbSet.clone(newDbSet.db.Where(ds.likeCondition(column, true), "%"+escapeLike(pattern)+"%"))
 }
 
 // WhereFieldStartsWith - EF Core: context.Users.Where(x => x.Field.StartsWith(prefix))
 func (ds *LinqDbSet[T]) WhereFieldStartsWith(fieldName string, prefix string) *LinqDbSet[T] {
 	// Create a new LinqDbSet to avoid mutating the original
-	newDbSet := &LinqDbSet[T]{
-		db:         ds.db.Where(fmt.Sprintf("%s LIKE ?", fieldName), prefix+"%"),
-		entityType: ds.entityType,
-		context:    ds.context,
-		translator: ds.translator,
-		tableName:  ds.tableName,
-	}
-	return newDbSet
+	newDbSet, column := ds.navigate("WhereFieldStartsWith", fieldName)
+	return newDbSet.clone(newDbSet.db.Where(ds.likeCondition(column, true), escapeLike(prefix)+"%"))
 }
 
 // WhereFieldEndsWith - EF Core: context.Users.Where(x => x.Field.EndsWith(suffix))
 func (ds *LinqDbSet[T]) WhereFieldEndsWith(fieldName string, suffix string) *LinqDbSet[T] {
 	// Create a new LinqDbSet to avoid mutating the original
-	newDbSet := &LinqDbSet[T]{
-		db:         ds.db.Where(fmt.Sprintf("%s LIKE ?", fieldName), "%"+suffix),
-		entityType: ds.entityType,
-		context:    ds.context,
-		translator: ds.translator,
-		tableName:  ds.tableName,
-	}
+	newDbSet, column := ds.navigate("WhereFieldEndsWith", fieldName)
+	return newDbSet.clone(newDbSet.db.Where(ds.likeCondition(column, true), "%"+escapeLike(suffix)))
+}
+
+// WhereFieldEqualsCI - case-insensitive equality - EF Core: Where(x => x.Email.ToLower() == email.ToLower())
+func (ds *LinqDbSet[T]) WhereFieldEqualsCI(fieldName string, value string) *LinqDbSet[T] {
+	newDbSet, column := ds.navigate("WhereFieldEqualsCI", fieldName)
+	return newDbSet.clone(newDbSet.db.Where(fmt.Sprintf("LOWER(%s) = LOWER(?)", column), value))
+}
+
+// IgnoreCase makes WhereFieldLike/StartsWith/EndsWith on this query case-insensitive
+// Usage: ctx.Users.IgnoreCase().WhereFieldStartsWith("Username", term)
+func (ds *LinqDbSet[T]) IgnoreCase() *LinqDbSet[T] {
+	newDbSet := ds.clone(ds.db)
+	newDbSet.ignoreCase = true
 	return newDbSet
 }
 
+// WhereFieldLikeRaw - LIKE with a caller-supplied pattern; % and _ keep their wildcard meaning
+// Usage: ctx.Files.WhereFieldLikeRaw("Name", "report_202_-%.pdf")
+func (ds *LinqDbSet[T]) WhereFieldLikeRaw(fieldName string, pattern string) *LinqDbSet[T] {
+	newDbSet, column := ds.navigate("WhereFieldLikeRaw", fieldName)
+	return newDbSet.clone(newDbSet.db.Where(ds.likeCondition(column, false), pattern))
+}
+
+// likeEscapeChar escapes wildcards in LIKE patterns
+// '!' avoids backslash handling that differs between PostgreSQL, MySQL and SQLite string literals
+const likeEscapeChar = "!"
+
+// escapeLike escapes LIKE wildcards so user input is matched literally
+func escapeLike(value string) string {
+	replacer := strings.NewReplacer(
+		likeEscapeChar, likeEscapeChar+likeEscapeChar,
+		"%", likeEscapeChar+"%",
+		"_", likeEscapeChar+"_",
+	)
+	return replacer.Replace(value)
+}
+
+// likeCondition builds the LIKE condition for a resolved column, honouring the query and context case settings
+// PostgreSQL uses ILIKE; other databases compare LOWER() of both sides
+// escaped adds the ESCAPE clause for patterns built with escapeLike
+func (ds *LinqDbSet[T]) likeCondition(quotedFieldName string, escaped bool) string {
+
+	ignoreCase := ds.ignoreCase
+	if ctx, ok := ds.context.(interface{ CaseInsensitiveStrings() bool }); ok && ctx.CaseInsensitiveStrings() {
+		ignoreCase = true
+	}
+	
+	escape := ""
+	if escaped {
+		escape = fmt.Sprintf(" ESCAPE '%s'", likeEscapeChar)
+	}
+	
+	switch {
+	case !ignoreCase:
+		return fmt.Sprintf("%s LIKE ?%s", quotedFieldName, escape)
+	case ds.translator != nil:
+		return fmt.Sprintf("%s ILIKE ?%s", quotedFieldName, escape)
+	default:
+		return fmt.Sprintf("LOWER(%s) LIKE LOWER(?)%s", quotedFieldName, escape)
+	}
+}
+
 // WhereFieldBetween - EF Core: context.Users.Where(x => x.Field >= min && x.Field <= max)
 func (ds *LinqDbSet[T]) WhereFieldBetween(fieldName string, min, max interface{}) *LinqDbSet[T] {
 	// Create a new LinqDbSet to avoid mutating the original
-	newDbSet := &LinqDbSet[T]{
-		db:         ds.db.Where(fmt.Sprintf("%s BETWEEN ? AND ?", fieldName), min, max),
-		entityType: ds.entityType,
-		context:    ds.context,
-		translator: ds.translator,
-		tableName:  ds.tableName,
-	}
-	return newDbSet
+	newDbSet, column := ds.navigate("WhereFieldBetween", fieldName)
+	return newDbSet.clone(newDbSet.db.Where(fmt.Sprintf("%s BETWEEN ? AND ?", column), types.QueryValue(min), types.QueryValue(max)))
 }
 
 // Or - overloaded method that supports multiple patterns like Where:
@@ -795,6 +1108,12 @@ func (ds *LinqDbSet[T]) Or(args ...interface{}) *LinqDbSet[T] {
 	// Pattern 1: Entity struct like GORM Or(&User{Email: "test"})
 	if len(args) == 1 {
 		arg := args[0]
+		if predicate, ok := arg.(Predicate); ok {
+			return ds.wherePredicate(predicate, true)
+		}
+		if raw, ok := arg.(RawExpression); ok {
+			return ds.whereRaw("Or", raw, true)
+		}
 		// Check if it's a pointer to our entity type
 		if entityPtr, ok := arg.(*T); ok {
 			return ds.OrEntity(*entityPtr)
@@ -817,23 +1136,13 @@ func (ds *LinqDbSet[T]) Or(args ...interface{}) *LinqDbSet[T] {
 	// Pattern 3: Or("email = ?", value) - SQL with parameters
 	if len(args) >= 2 {
 		if condition, ok := args[0].(string); ok {
-			quotedCondition := condition
-			if ds.translator != nil {
-				quotedCondition = ds.translator.TranslateQuery(ds.tableName, condition)
-			}
 			// Create a new LinqDbSet to avoid mutating the original
-			newDbSet := &LinqDbSet[T]{
-				db:         ds.db.Or(quotedCondition, args[1:]...),
-				entityType: ds.entityType,
-				context:    ds.context,
-				translator: ds.translator,
-				tableName:  ds.tableName,
-			}
+			newDbSet := ds.clone(ds.db.Or(ds.translateCondition(condition), args[1:]...))
 			return newDbSet
 		}
 	}
 	
-	return ds
+	return ds.unsupportedArguments("Or", args)
 }
 
 // OrStruct - helper method to handle Or with any struct type
@@ -851,20 +1160,17 @@ func (ds *LinqDbSet[T]) OrStruct(entity interface{}) *LinqDbSet[T] {
 		}
 	}
 	
-	return ds
+	return ds.unsupportedArguments("Or", []interface{}{entity})
 }
 
 // OrField - adds OR condition for field comparison with operator support
 // DEPRECATED: Use the overloaded Or method instead: Or("fieldName", value) or Or(&Entity{Field: value})
-// Supports: OrField("Age", 25), OrField("Age", ">25"), OrField("Age", ">=18"), etc.
+// Supports: OrField("Age", 25), OrField("Age", GreaterThan(25)), OrField("Age", ">=18"), etc.
 func (ds *LinqDbSet[T]) OrField(fieldName string, value interface{}) *LinqDbSet[T] {
 	// Apply PostgreSQL translation if available
-	quotedFieldName := fieldName
-	if ds.translator != nil {
-		quotedFieldName = ds.translator.GetQuotedFieldName(fieldName)
-	}
+	newDbSet, quotedFieldName := ds.navigate("OrField", fieldName)
 	
-	return ds.addComparisonCondition(quotedFieldName, value, "OR")
+	return newDbSet.addComparisonCondition(quotedFieldName, value, "OR")
 }
 
 // OrEntity - adds OR condition with entity struct with comparison operator support
@@ -883,14 +1189,8 @@ func (ds *LinqDbSet[T]) OrEntity(entity T) *LinqDbSet[T] {
 		entityType = entityType.Elem()
 	}
 	
-	// Create a new LinqDbSet to avoid mutating the original
-	newDbSet := &LinqDbSet[T]{
-		db:         ds.db,
-		entityType: ds.entityType,
-		context:    ds.context,
-		translator: ds.translator,
-		tableName:  ds.tableName,
-	}
+	db := ds.db
+	var skipped []skippedZero
 	
 	// Build OR conditions for non-zero fields
 	for i := 0; i < entityType.NumField(); i++ {
@@ -902,100 +1202,49 @@ func (ds *LinqDbSet[T]) OrEntity(entity T) *LinqDbSet[T] {
 			continue
 		}
 		
-		// Skip zero values (unset fields)
-		if fieldValue.IsZero() {
-			continue
-		}
-		
+		// Skip zero values (unset fields) unless requested with IncludeZero
 		fieldName := field.Name
-		quotedFieldName := fieldName
-		if ds.translator != nil {
-			quotedFieldName = ds.translator.GetQuotedFieldName(fieldName)
+		value, ok := ds.entityFilterValue(fieldName, fieldValue)
+		if !ok {
+			skipped = append(skipped, skippedZero{fieldName: fieldName, value: fieldValue, or: true})
+			continue
 		}
+		quotedFieldName := ds.quoteField(fieldName)
 		
-		// Check if the value is a string with comparison operators
-		value := fieldValue.Interface()
-		if strValue, ok := value.(string); ok {
-			// Parse operator from string value
-			operator, actualValue := ds.parseOperator(strValue)
-			condition := fmt.Sprintf("%s %s ?", quotedFieldName, operator)
-			newDbSet.db = newDbSet.db.Or(condition, actualValue)
-		} else {
-			// Default equality comparison
-			condition := fmt.Sprintf("%s = ?", quotedFieldName)
-			newDbSet.db = newDbSet.db.Or(condition, value)
-		}
+		// Comparison values and (unless disabled) strings like ">18" choose the operator
+		condition, args := ds.comparisonCondition(quotedFieldName, value)
+		db = db.Or(condition, args...)
 	}
 	
+	newDbSet := ds.clone(db)
+	newDbSet.skippedZero = append(append([]skippedZero(nil), ds.skippedZero...), skipped...)
 	return newDbSet
 }
 
 // WhereFieldNull - EF Core: context.Users.Where(x => x.Field == null)
 func (ds *LinqDbSet[T]) WhereFieldNull(fieldName string) *LinqDbSet[T] {
 	// Create a new LinqDbSet to avoid mutating the original
-	newDbSet := &LinqDbSet[T]{
-		db:         ds.db.Where(fmt.Sprintf("%s IS NULL", fieldName)),
-		entityType: ds.entityType,
-		context:    ds.context,
-		translator: ds.translator,
-		tableName:  ds.tableName,
-	}
-	return newDbSet
+	newDbSet, column := ds.navigate("WhereFieldNull", fieldName)
+	return newDbSet.clone(newDbSet.db.Where(fmt.Sprintf("%s IS NULL", column)))
 }
 
 // WhereFieldNotNull - EF Core: context.Users.Where(x => x.Field != null)
 func (ds *LinqDbSet[T]) WhereFieldNotNull(fieldName string) *LinqDbSet[T] {
 	// Create a new LinqDbSet to avoid mutating the original
-	newDbSet := &LinqDbSet[T]{
-		db:         ds.db.Where(fmt.Sprintf("%s IS NOT NULL", fieldName)),
-		entityType: ds.entityType,
-		context:    ds.context,
-		translator: ds.translator,
-		tableName:  ds.tableName,
-	}
-	return newDbSet
+	newDbSet, column := ds.navigate("WhereFieldNotNull", fieldName)
+	return newDbSet.clone(newDbSet.db.Where(fmt.Sprintf("%s IS NOT NULL", column)))
 }
 
 // OrderByField - EF Core: context.Users.OrderBy("Field")
 // DEPRECATED: Use the overloaded OrderBy method instead: OrderBy("fieldName") or OrderBy(func(T) interface{})
 func (ds *LinqDbSet[T]) OrderByField(fieldName string) *LinqDbSet[T] {
-	quotedFieldName := fieldName
-	if ds.translator != nil {
-		quotedFieldName = ds.translator.GetQuotedFieldName(fieldName)
-	}
-	
-	orderClause := quotedFieldName + " ASC"
-	// Create a new LinqDbSet to avoid mutating the original
-	newDbSet := &LinqDbSet[T]{
-		db:         ds.db.Order(orderClause),
-		entityType: ds.entityType,
-		context:    ds.context,
-		translator: ds.translator,
-		tableName:  ds.tableName,
-	}
-	return newDbSet
+	return ds.withOrdering(fieldName, false)
 }
-
 // OrderByFieldDescending - EF Core: context.Users.OrderByDescending("Field")
 // DEPRECATED: Use the overloaded OrderByDescending method instead: OrderByDescending("fieldName") or OrderByDescending(func(T) interface{})
 func (ds *LinqDbSet[T]) OrderByFieldDescending(fieldName string) *LinqDbSet[T] {
-	quotedFieldName := fieldName
-	if ds.translator != nil {
-		quotedFieldName = ds.translator.GetQuotedFieldName(fieldName)
-	}
-	
-	orderClause := quotedFieldName + " DESC"
-	// Create a new LinqDbSet to avoid mutating the original
-	newDbSet := &LinqDbSet[T]{
-		db:         ds.db.Order(orderClause),
-		entityType: ds.entityType,
-		context:    ds.context,
-		translator: ds.translator,
-		tableName:  ds.tableName,
-	}
-	return newDbSet
+	return ds.withOrdering(fieldName, true)
 }
-
 // OrderByAscending - Entity-based ordering: context.Users.OrderByAscending(&User{CreatedAt: time.Now()})
 // Only works with fields that have values set in the entity (non-zero values)
 func (ds *LinqDbSet[T]) OrderByAscending(entity T) *LinqDbSet[T] {
@@ -1099,30 +1348,12 @@ func (ds *LinqDbSet[T]) getFirstSetFieldNameForAggregation(entity T) string {
 
 // ThenByField - EF Core: context.Users.OrderBy(x => x.Field1).ThenBy(x => x.Field2)
 func (ds *LinqDbSet[T]) ThenByField(fieldName string) *LinqDbSet[T] {
-	// Create a new LinqDbSet to avoid mutating the original
-	newDbSet := &LinqDbSet[T]{
-		db:         ds.db.Order(fieldName + " ASC"),
-		entityType: ds.entityType,
-		context:    ds.context,
-		translator: ds.translator,
-		tableName:  ds.tableName,
-	}
-	return newDbSet
+	return ds.withOrdering(fieldName, false)
 }
-
 // ThenByFieldDescending - EF Core: context.Users.OrderBy(x => x.Field1).ThenByDescending(x => x.Field2)
 func (ds *LinqDbSet[T]) ThenByFieldDescending(fieldName string) *LinqDbSet[T] {
-	// Create a new LinqDbSet to avoid mutating the original
-	newDbSet := &LinqDbSet[T]{
-		db:         ds.db.Order(fieldName + " DESC"),
-		entityType: ds.entityType,
-		context:    ds.context,
-		translator: ds.translator,
-		tableName:  ds.tableName,
-	}
-	return newDbSet
+	return ds.withOrdering(fieldName, true)
 }
-
 // EF Core-style CRUD Operations
 
 // Add - EF Core style: context.Users.Add(user) - Creates entity in database immediately
@@ -1145,28 +1376,31 @@ func (ds *LinqDbSet[T]) Add(entity T) (*T, error) {
 	}
 	
 	// Track entity for insertion in change tracker (EF Core style)
+	// The pointer is tracked so SaveChanges can write generated values back into it
+	entityPtr := &entity
 	if ds.context != nil {
 		ctxValue := reflect.ValueOf(ds.context)
 		if ctxValue.Kind() == reflect.Ptr {
 			addEntityMethod := ctxValue.MethodByName("AddEntity")
 			if addEntityMethod.IsValid() {
 				addEntityMethod.Call([]reflect.Value{
-					reflect.ValueOf(entity),
+					reflect.ValueOf(entityPtr),
 				})
 			}
 		}
 	} else {
 		// If no context available, create immediately (fallback behavior)
-		err := db.Create(&entity).Error
+		err := db.Create(entityPtr).Error
 		if err != nil {
-			return nil, err
+			return nil, dberrors.Translate(err)
 		}
 	}
 	
-	return &entity, nil
+	return entityPtr, nil
 }
 
 // getAutoGeneratedPrimaryKeyFields returns field names that should be omitted for auto-generation
+// Covers uuid DEFAULT gen_random_uuid(), serial/bigserial/IDENTITY and auto-increment keys
 func (ds *LinqDbSet[T]) getAutoGeneratedPrimaryKeyFields(entity interface{}) []string {
 	var omitFields []string
 	
@@ -1179,29 +1413,13 @@ func (ds *LinqDbSet[T]) getAutoGeneratedPrimaryKeyFields(entity interface{}) []s
 		return omitFields
 	}
 	
-	entityType := entityValue.Type()
-	
-	// Look for fields with primary_key and default:gen_random_uuid() tags
-	for i := 0; i < entityType.NumField(); i++ {
-		field := entityType.Field(i)
-		
-		// Skip unexported fields
-		if field.PkgPath != "" {
+	for _, field := range ds.entityModel().Fields {
+		if !field.IsPrimary || !field.IsDatabaseGenerated() {
 			continue
 		}
 		
-		// Get GORM tag
-		gormTag := field.Tag.Get("gorm")
-		if gormTag == "" {
-			continue
-		}
-		
-		// Check if it's a UUID primary key with auto-generation
-		if field.Type.String() == "uuid.UUID" && 
-		   strings.Contains(gormTag, "primary_key") && 
-		   strings.Contains(gormTag, "default:gen_random_uuid()") {
-			
-			// Add field name to omit list for auto-generation
+		// Only omit keys the caller left unset so explicit values are still inserted
+		if fieldValue := field.ValueOf(entityValue); fieldValue.IsValid() && fieldValue.IsZero() {
 			omitFields = append(omitFields, field.Name)
 		}
 	}
@@ -1209,6 +1427,19 @@ func (ds *LinqDbSet[T]) getAutoGeneratedPrimaryKeyFields(entity interface{}) []s
 	return omitFields
 }
 
+// entityModel returns the context's entity model (including model builder configuration)
+// or a model built from the struct tags when no context is available
+func (ds *LinqDbSet[T]) entityModel() *models.EntityModel {
+	if ctx, ok := ds.context.(interface {
+		GetEntityModel(reflect.Type) *models.EntityModel
+	}); ok {
+		if entityModel := ctx.GetEntityModel(ds.entityType); entityModel != nil {
+			return entityModel
+		}
+	}
+	return models.NewEntityModel(ds.entityType)
+}
+
 // getNonAutoGeneratedFields returns all field names except the auto-generated ones
 func (ds *LinqDbSet[T]) getNonAutoGeneratedFields(entity interface{}, omitFields []string) []string {
 	var selectFields []string
@@ -1375,25 +1606,26 @@ func (ds *LinqDbSet[T]) Save(entity interface{}) error {
 			}
 		}
 	}
-	return ds.db.Save(entity).Error
+	return dberrors.Translate(ds.db.Save(entity).Error)
 }
 
 // Create - GORM-style create - DEPRECATED: Use Add instead for EF Core consistency
 func (ds *LinqDbSet[T]) Create(entity interface{}) error {
 	log.Printf("[GONTEXT DEBUG] Create method called (DEPRECATED - use Add instead)")
-	return ds.db.Create(entity).Error
+	return dberrors.Translate(ds.db.Create(entity).Error)
 }
 
 
-// Delete deletes records matching the current query filters
+// Delete deletes records matching the current query filters, like ExecuteDelete without the count
 func (ds *LinqDbSet[T]) Delete() error {
-	return ds.db.Delete(new(T)).Error
+	_, err := ds.ExecuteDelete()
+	return err
 }
 
 // Scan - Execute query and scan results into destination
 // Example: var total int64; err := ctx.Files.Select("COALESCE(SUM(size), 0)").Scan(&total)
 func (ds *LinqDbSet[T]) Scan(dest interface{}) error {
-	return ds.db.Scan(dest).Error
+	return ds.query().Scan(dest).Error
 }
 
 // Sum - overloaded method that supports multiple patterns:
@@ -1413,12 +1645,9 @@ func (ds *LinqDbSet[T]) Sum(args ...interface{}) (float64, error) {
 			}
 			
 			var result float64
-			quotedFieldName := fieldName
-			if ds.translator != nil {
-				quotedFieldName = ds.translator.GetQuotedFieldName(fieldName)
-			}
+			quotedFieldName := ds.quoteField(fieldName)
 			
-			err := ds.db.Model(new(T)).Select(fmt.Sprintf("COALESCE(SUM(%s), 0)", quotedFieldName)).Scan(&result).Error
+			err := ds.paginated().Select(fmt.Sprintf("COALESCE(SUM(%s), 0)", quotedFieldName)).Scan(&result).Error
 			return result, err
 		}
 		
@@ -1448,12 +1677,9 @@ func (ds *LinqDbSet[T]) Sum(args ...interface{}) (float64, error) {
 // PREFER: Use the overloaded Sum method instead: Sum(&Entity{Field: 0}) or Sum(func(T) interface{})
 func (ds *LinqDbSet[T]) SumField(fieldName string) (float64, error) {
 	var result float64
-	quotedFieldName := fieldName
-	if ds.translator != nil {
-		quotedFieldName = ds.translator.GetQuotedFieldName(fieldName)
-	}
+	quotedFieldName := ds.quoteField(fieldName)
 	
-	err := ds.db.Model(new(T)).Select(fmt.Sprintf("COALESCE(SUM(%s), 0)", quotedFieldName)).Scan(&result).Error
+	err := ds.paginated().Select(fmt.Sprintf("COALESCE(SUM(%s), 0)", quotedFieldName)).Scan(&result).Error
 	return result, err
 }
 
@@ -1501,12 +1727,9 @@ func (ds *LinqDbSet[T]) Average(args ...interface{}) (float64, error) {
 // PREFER: Use the overloaded Average method instead: Average(&Entity{Field: 0}) or Average(func(T) interface{})
 func (ds *LinqDbSet[T]) AverageField(fieldName string) (float64, error) {
 	var result float64
-	quotedFieldName := fieldName
-	if ds.translator != nil {
-		quotedFieldName = ds.translator.GetQuotedFieldName(fieldName)
-	}
+	quotedFieldName := ds.quoteField(fieldName)
 	
-	err := ds.db.Model(new(T)).Select(fmt.Sprintf("COALESCE(AVG(%s), 0)", quotedFieldName)).Scan(&result).Error
+	err := ds.paginated().Select(fmt.Sprintf("COALESCE(AVG(%s), 0)", quotedFieldName)).Scan(&result).Error
 	return result, err
 }
 
@@ -1554,12 +1777,9 @@ func (ds *LinqDbSet[T]) Min(args ...interface{}) (interface{}, error) {
 // PREFER: Use the overloaded Min method instead: Min(&Entity{Field: 0}) or Min(func(T) interface{})
 func (ds *LinqDbSet[T]) MinField(fieldName string) (interface{}, error) {
 	var result interface{}
-	quotedFieldName := fieldName
-	if ds.translator != nil {
-		quotedFieldName = ds.translator.GetQuotedFieldName(fieldName)
-	}
+	quotedFieldName := ds.quoteField(fieldName)
 	
-	err := ds.db.Model(new(T)).Select(fmt.Sprintf("MIN(%s)", quotedFieldName)).Scan(&result).Error
+	err := ds.paginated().Select(fmt.Sprintf("MIN(%s)", quotedFieldName)).Scan(&result).Error
 	return result, err
 }
 
@@ -1607,18 +1827,16 @@ func (ds *LinqDbSet[T]) Max(args ...interface{}) (interface{}, error) {
 // PREFER: Use the overloaded Max method instead: Max(&Entity{Field: 0}) or Max(func(T) interface{})
 func (ds *LinqDbSet[T]) MaxField(fieldName string) (interface{}, error) {
 	var result interface{}
-	quotedFieldName := fieldName
-	if ds.translator != nil {
-		quotedFieldName = ds.translator.GetQuotedFieldName(fieldName)
-	}
+	quotedFieldName := ds.quoteField(fieldName)
 	
-	err := ds.db.Model(new(T)).Select(fmt.Sprintf("MAX(%s)", quotedFieldName)).Scan(&result).Error
+	err := ds.paginated().Select(fmt.Sprintf("MAX(%s)", quotedFieldName)).Scan(&result).Error
 	return result, err
 }
 
 // Include - Type-safe Include supporting both string names and pointer-based navigation properties
-// Supports: query.Include("User", "Buckets") or query.Include(&Entity.User, &Entity.Buckets)
-// Validates field names exist on the entity type and panics with clear error if not
+// Supports: query.Include("User", "Buckets"), nested paths query.Include("Author.Profile") or
+// query.Include(&Entity.User, &Entity.Buckets)
+// Names that are not navigations fail the query with a QueryError, returned by the terminal method and Err
 func (ds *LinqDbSet[T]) Include(args ...interface{}) *LinqDbSet[T] {
 	var fieldNames []string
 	
@@ -1636,34 +1854,35 @@ func (ds *LinqDbSet[T]) Include(args ...interface{}) *LinqDbSet[T] {
 		}
 	}
 	
-	// Validate all field names exist on the entity type
-	var zero T
-	entityType := reflect.TypeOf(zero)
-	if entityType.Kind() == reflect.Ptr {
-		entityType = entityType.Elem()
-	}
-	
-	for _, fieldName := range fieldNames {
-		if _, found := entityType.FieldByName(fieldName); !found {
-			panic(fmt.Sprintf("Field '%s' not found on %s", fieldName, entityType.Name()))
-		}
+	// Validate every name is a navigation path of the entity type
+	stmt := &gorm.Statement{DB: ds.db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return ds.fail("Include", err)
 	}
 	
 	// Apply GORM preloading
 	newDb := ds.db
-	for _, association := range fieldNames {
-		newDb = newDb.Preload(association)
+	for _, fieldName := range fieldNames {
+		path, err := navigationPath(stmt.Schema, fieldName)
+		if err != nil {
+			return ds.fail(call("Include", fieldName), err)
+		}
+		newDb = newDb.Preload(path)
 	}
 	
-	return &LinqDbSet[T]{
-		db:         newDb,
-		entityType: ds.entityType,
-		context:    ds.context,
-		translator: ds.translator,
-		tableName:  ds.tableName,
-	}
+	return ds.clone(newDb)
 }
 
+// IncludeFields - Include that loads only the given fields of a navigation; the path may be nested ("Author.Posts")
+// The fields must contain the keys GORM matches rows on: ctx.Posts.IncludeFields("Author", "Id", "Username")
+func (ds *LinqDbSet[T]) IncludeFields(navigation string, fields ...string) *LinqDbSet[T] {
+	if len(fields) == 0 {
+		return ds.clone(ds.db.Preload(navigation))
+	}
+	return ds.clone(ds.db.Preload(navigation, func(db *gorm.DB) *gorm.DB {
+		return db.Select(fields)
+	}))
+}
 
 // extractFieldNameFromPointer extracts field name from various pointer patterns
 // Supports multiple patterns for type-safe field selection
@@ -1976,13 +2195,7 @@ func (ds *LinqDbSet[T]) IncludeTyped(selectors ...func() interface{}) *LinqDbSet
 			newDb = newDb.Preload(fieldName)
 		}
 		
-		return &LinqDbSet[T]{
-			db:         newDb,
-			entityType: ds.entityType,
-			context:    ds.context,
-			translator: ds.translator,
-			tableName:  ds.tableName,
-		}
+		return ds.clone(newDb)
 	}
 	
 	return ds
@@ -1997,55 +2210,119 @@ func (ds *LinqDbSet[T]) extractFieldNameFromSelector(selector func() interface{}
 }
 
 
-// IncludeAll - Load all relationships automatically by detecting GORM foreign key tags
-func (ds *LinqDbSet[T]) IncludeAll() *LinqDbSet[T] {
-	var zero T
-	value := reflect.ValueOf(zero)
-	if value.Kind() == reflect.Ptr {
-		value = value.Elem()
+// IncludeAll - Load all relationships automatically from the entity's relationship metadata
+// maxDepth follows navigations of the loaded entities too (default 1, the entity's own navigations):
+// ctx.Users.IncludeAll(2) loads Posts and Posts.Comments. Back-references to the entity a navigation
+// was reached from (Posts.Author on a User) are skipped, since that entity is already loaded; other
+// structs such as time.Time or embedded value types are never navigations
+func (ds *LinqDbSet[T]) IncludeAll(maxDepth ...int) *LinqDbSet[T] {
+	depth := 1
+	if len(maxDepth) > 0 {
+		depth = maxDepth[0]
+	}
+	
+	stmt := &gorm.Statement{DB: ds.db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return ds.fail(call("IncludeAll", depth), err)
 	}
-	entityType := value.Type()
 	
 	newDb := ds.db
+	for _, path := range navigationPaths(stmt.Schema, nil, "", depth) {
+		newDb = newDb.Preload(path)
+	}
+	return ds.clone(newDb)
+}
+
+// navigationPaths lists the preload paths of a schema's navigations down to depth levels, in field order,
+// leaving out the back-reference of the relationship the schema was reached through
+func navigationPaths(sch *schema.Schema, via *schema.Relationship, prefix string, depth int) []string {
+	if depth <= 0 {
+		return nil
+	}
 	
-	// Find all relationship fields by looking for slices and struct references
-	for i := 0; i < entityType.NumField(); i++ {
-		field := entityType.Field(i)
-		fieldType := field.Type
-		
-		// Skip unexported fields
-		if field.PkgPath != "" {
+	var paths []string
+	for _, field := range sch.Fields {
+		rel, ok := sch.Relationships.Relations[field.Name]
+		if !ok || (via != nil && isBackReference(via, rel)) {
 			continue
 		}
-		
-		// Check for slice relationships (e.g., []Bucket)
-		if fieldType.Kind() == reflect.Slice {
-			elemType := fieldType.Elem()
-			if elemType.Kind() == reflect.Struct {
-				// This is likely a relationship - use field name for preload
-				newDb = newDb.Preload(field.Name)
-			}
+		path := prefix + field.Name
+		paths = append(paths, path)
+		paths = append(paths, navigationPaths(rel.FieldSchema, rel, path+".", depth-1)...)
+	}
+	return paths
+}
+
+// isBackReference reports whether rel leads back over the same foreign key to where via came from,
+// as Post.Author does for User.Posts
+func isBackReference(via, rel *schema.Relationship) bool {
+	if rel.FieldSchema != via.Schema {
+		return false
+	}
+	if via.JoinTable != nil || rel.JoinTable != nil {
+		return via.JoinTable != nil && rel.JoinTable != nil && via.JoinTable.Table == rel.JoinTable.Table
+	}
+	return slices.Equal(foreignKeyColumns(via), foreignKeyColumns(rel))
+}
+
+// foreignKeyColumns returns the sorted foreign key columns of a relationship
+func foreignKeyColumns(rel *schema.Relationship) []string {
+	var columns []string
+	for _, ref := range rel.References {
+		if ref.ForeignKey != nil {
+			columns = append(columns, ref.ForeignKey.Schema.Table+"."+ref.ForeignKey.DBName)
 		}
-		
-		// Check for single struct relationships (e.g., User in Bucket.Owner)
-		if fieldType.Kind() == reflect.Struct && fieldType.PkgPath() != "" {
-			// This might be a belongs-to relationship
-			newDb = newDb.Preload(field.Name)
+	}
+	slices.Sort(columns)
+	return columns
+}
+
+// Distinct - EF Core: Distinct() - removes duplicate rows, optionally over specific columns
+// Usage: ctx.Users.Select("Country").Distinct() or ctx.Users.Distinct("Country", "City")
+func (ds *LinqDbSet[T]) Distinct(columns ...string) *LinqDbSet[T] {
+	var newDb *gorm.DB
+	if len(columns) > 0 {
+		quoted := make([]interface{}, len(columns))
+		for i, column := range columns {
+			quoted[i] = ds.quoteField(column)
 		}
+		newDb = ds.db.Distinct(quoted...)
+	} else {
+		newDb = ds.db.Distinct()
+	}
+	
+	return ds.clone(newDb)
+}
+
+// DistinctBy - EF Core: DistinctBy(x => x.Email) - keeps one whole entity per distinct field value
+// PostgreSQL uses DISTINCT ON, so the ordering decides which row is kept:
+// ctx.Users.DistinctBy("Email").OrderByDescending("CreatedAt") keeps the newest user per email.
+// Other databases keep the row with the lowest primary key per value.
+func (ds *LinqDbSet[T]) DistinctBy(fieldName string) *LinqDbSet[T] {
+	if ds.translator != nil {
+		quotedFieldName := ds.quoteField(fieldName)
 		
-		// Check for pointer to struct relationships (e.g., *User)
-		if fieldType.Kind() == reflect.Ptr && fieldType.Elem().Kind() == reflect.Struct {
-			newDb = newDb.Preload(field.Name)
-		}
+		// DISTINCT ON requires its expression to lead the ORDER BY
+		newDbSet := ds.clone(ds.db.Select(fmt.Sprintf("DISTINCT ON (%s) *", quotedFieldName)))
+		newDbSet.orderings = append([]ordering{{fieldName: fieldName}}, ds.orderings...)
+		return newDbSet
 	}
 	
-	return &LinqDbSet[T]{
-		db:         newDb,
-		entityType: ds.entityType,
-		context:    ds.context,
-		translator: ds.translator,
-		tableName:  ds.tableName,
+	stmt := &gorm.Statement{DB: ds.db}
+	if err := stmt.Parse(new(T)); err != nil || stmt.Schema.PrioritizedPrimaryField == nil {
+		return ds.fail(call("DistinctBy", fieldName), fmt.Errorf("a primary key is required"))
 	}
+	
+	column := fieldName
+	if field := stmt.Schema.LookUpField(fieldName); field != nil {
+		column = field.DBName
+	}
+	primaryKey := stmt.Schema.PrioritizedPrimaryField.DBName
+	
+	// Emulate DISTINCT ON with the first primary key in each group
+	firstPerGroup := ds.db.Session(&gorm.Session{}).Model(new(T)).
+		Select(fmt.Sprintf("MIN(%s)", primaryKey)).Group(column)
+	return ds.clone(ds.db.Where(fmt.Sprintf("%s IN (?)", primaryKey), firstPerGroup))
 }
 
 // Select - Choose specific fields to load: context.Users.Select("Id", "Username", "Email")
@@ -2054,24 +2331,31 @@ func (ds *LinqDbSet[T]) IncludeAll() *LinqDbSet[T] {
 func (ds *LinqDbSet[T]) Select(fields ...string) *LinqDbSet[T] {
 	newDb := ds.db.Select(fields)
 	
-	return &LinqDbSet[T]{
-		db:         newDb,
-		entityType: ds.entityType,
-		context:    ds.context,
-		translator: ds.translator,
-		tableName:  ds.tableName,
+	return ds.clone(newDb)
+}
+
+// SelectFields - Select that resolves field names, column names or json names to table-qualified columns,
+// so the projection stays unambiguous when navigation filters join other tables
+// Unknown names are skipped, or fail the query in strict mode: ctx.Users.SelectFields("id", "userName")
+func (ds *LinqDbSet[T]) SelectFields(fieldNames ...string) *LinqDbSet[T] {
+	var columns []string
+	for _, fieldName := range fieldNames {
+		if field := ds.lookupField(fieldName); field != nil {
+			columns = append(columns, ds.db.Statement.Quote(clause.Column{Table: field.Schema.Table, Name: field.DBName}))
+		} else if invalid := ds.unknownField("SelectFields", fieldName); invalid != nil {
+			return invalid
+		}
+	}
+	if len(columns) == 0 {
+		return ds
 	}
+	
+	return ds.clone(ds.db.Select(columns))
 }
 
 // Omit - Exclude specific fields from loading: context.Users.Omit("PasswordHash")
 func (ds *LinqDbSet[T]) Omit(fields ...string) *LinqDbSet[T] {
 	newDb := ds.db.Omit(fields...)
 	
-	return &LinqDbSet[T]{
-		db:         newDb,
-		entityType: ds.entityType,
-		context:    ds.context,
-		translator: ds.translator,
-		tableName:  ds.tableName,
-	}
+	return ds.clone(newDb)
 }
\ No newline at end of file
@@ -0,0 +1,56 @@
+package linq
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/shepherrrd/gontext/internal/dberrors"
+	"github.com/shepherrrd/gontext/internal/query"
+)
+
+// Future is a query result fetched on first use. Reading any future of a context runs every pending
+// future of that context in one round trip
+type Future[V any] struct {
+	query *query.FutureQuery
+	value V
+}
+
+// Value runs the pending futures unless this one has run already and returns its result
+func (f *Future[V]) Value() (V, error) {
+	err := f.query.Wait()
+	return f.value, dberrors.Translate(err)
+}
+
+// futureBatchOf returns the context's pending futures, or nil (each future runs alone) outside a DbContext
+func futureBatchOf(ctx interface{}) *query.FutureBatch {
+	if provider, ok := ctx.(interface{ FutureBatch() *query.FutureBatch }); ok {
+		return provider.FutureBatch()
+	}
+	return nil
+}
+
+// Future - NHibernate: Future() - defers ToList until a future of this context is read
+//
+//	users := ctx.Users.Where("IsActive = ?", true).Future()
+//	total := ctx.Orders.FutureCount()
+//	list, err := users.Value() // Both queries are sent here, in one round trip
+func (ds *LinqDbSet[T]) Future() *Future[[]T] {
+	future := &Future[[]T]{}
+	future.query = futureBatchOf(ds.context).Queue(ds.query().Model(new(T)), func(tx *gorm.DB) *gorm.DB {
+		return tx.Find(&future.value)
+	}, func() {
+		// Automatically track all loaded entities for change detection
+		for i := range future.value {
+			ds.trackEntity(&future.value[i])
+		}
+	})
+	return future
+}
+
+// FutureCount - NHibernate: FutureValue() - defers Count until a future of this context is read
+func (ds *LinqDbSet[T]) FutureCount() *Future[int64] {
+	future := &Future[int64]{}
+	future.query = futureBatchOf(ds.context).Queue(ds.paginated(), func(tx *gorm.DB) *gorm.DB {
+		return countQuery(tx, &future.value)
+	}, nil)
+	return future
+}
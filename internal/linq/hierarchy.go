@@ -0,0 +1,103 @@
+package linq
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/shepherrrd/gontext/internal/dberrors"
+)
+
+// defaultMaxDepth stops recursion on corrupted (cyclic) hierarchies
+const defaultMaxDepth = 100
+
+// HierarchyQuery runs recursive CTE queries over a self-referencing entity (e.g. Category.ParentID)
+type HierarchyQuery[T any] struct {
+	set         *LinqDbSet[T]
+	parentField string
+	maxDepth    int
+}
+
+// WithRecursive - queries a self-referencing hierarchy through WITH RECURSIVE
+// Usage: ctx.Categories.WithRecursive("ParentID").Descendants(rootID)
+// Results are ordered by depth; filters chained before WithRecursive are not applied.
+func (ds *LinqDbSet[T]) WithRecursive(parentField string) *HierarchyQuery[T] {
+	return &HierarchyQuery[T]{set: ds, parentField: parentField, maxDepth: defaultMaxDepth}
+}
+
+// MaxDepth limits how many levels are followed (default 100)
+func (h *HierarchyQuery[T]) MaxDepth(depth int) *HierarchyQuery[T] {
+	newQuery := *h
+	newQuery.maxDepth = depth
+	return &newQuery
+}
+
+// Tree returns every node reachable from the roots (nodes whose parent is NULL)
+func (h *HierarchyQuery[T]) Tree() ([]T, error) {
+	return h.run(func(table, key, parent string) (string, string, []interface{}) {
+		anchor := fmt.Sprintf("%s IS NULL", parent)
+		recursive := fmt.Sprintf("child.%s = tree.%s", parent, key)
+		return anchor, recursive, nil
+	}, 0)
+}
+
+// Descendants returns all nodes below the given node (children, grandchildren, ...)
+func (h *HierarchyQuery[T]) Descendants(id interface{}) ([]T, error) {
+	return h.run(func(table, key, parent string) (string, string, []interface{}) {
+		anchor := fmt.Sprintf("%s = ?", key)
+		recursive := fmt.Sprintf("child.%s = tree.%s", parent, key)
+		return anchor, recursive, []interface{}{id}
+	}, 1)
+}
+
+// Ancestors returns the parent chain of the given node, nearest parent first
+func (h *HierarchyQuery[T]) Ancestors(id interface{}) ([]T, error) {
+	return h.run(func(table, key, parent string) (string, string, []interface{}) {
+		anchor := fmt.Sprintf("%s = ?", key)
+		recursive := fmt.Sprintf("child.%s = tree.%s", key, parent)
+		return anchor, recursive, []interface{}{id}
+	}, 1)
+}
+
+// run builds and executes the recursive CTE
+// build returns the anchor condition, the join condition between child and tree, and the anchor args;
+// rows shallower than minDepth (the starting node itself) are excluded from the result
+func (h *HierarchyQuery[T]) run(build func(table, key, parent string) (string, string, []interface{}), minDepth int) ([]T, error) {
+	ds := h.set
+	stmt := &gorm.Statement{DB: ds.db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ds.entityType.Name(), err)
+	}
+	if stmt.Schema.PrioritizedPrimaryField == nil {
+		return nil, fmt.Errorf("WithRecursive requires a primary key on %s", ds.entityType.Name())
+	}
+	parentField := stmt.Schema.LookUpField(h.parentField)
+	if parentField == nil {
+		return nil, fmt.Errorf("field '%s' not found on %s", h.parentField, ds.entityType.Name())
+	}
+
+	table := stmt.Quote(stmt.Table)
+	key := stmt.Quote(stmt.Schema.PrioritizedPrimaryField.DBName)
+	parent := stmt.Quote(parentField.DBName)
+	anchor, recursive, args := build(table, key, parent)
+
+	sql := fmt.Sprintf(`WITH RECURSIVE tree AS (
+	SELECT %[1]s.*, 0 AS tree_depth FROM %[1]s WHERE %[2]s
+	UNION ALL
+	SELECT child.*, tree.tree_depth + 1 FROM %[1]s child JOIN tree ON %[3]s WHERE tree.tree_depth < ?
+)
+SELECT * FROM tree WHERE tree_depth >= ? ORDER BY tree_depth`, table, anchor, recursive)
+	args = append(args, h.maxDepth, minDepth)
+
+	var results []T
+	if err := ds.db.Session(&gorm.Session{NewDB: true}).Raw(sql, args...).Scan(&results).Error; err != nil {
+		return nil, dberrors.Translate(err)
+	}
+
+	// Automatically track all loaded entities for change detection
+	for i := range results {
+		ds.trackEntity(&results[i])
+	}
+
+	return results, nil
+}
@@ -0,0 +1,153 @@
+package linq
+
+import (
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// WithIdentityResolution makes included navigations share one instance per entity key - EF Core's identity
+// resolution. Without it every navigation loaded by Include or IncludeAll is its own copy: 500 posts by
+// 3 authors load 500 Author values, and changing one leaves the others stale. With it, pointer navigations
+// (*User, []*Post) of the whole result point at a single instance per key, including the query's own rows
+// Value navigations (User, []Post) cannot share an instance and stay copies. Shared instances can form
+// cycles (user.Posts[0].Author == user); the change tracker snapshots them as a graph, but encoders that
+// follow pointers must handle them. Lists, single lookups and BatchLoader batches are all resolved
+// Usage: posts, err := ctx.Posts.Include("Author", "Comments.Author").WithIdentityResolution().ToList()
+func (ds *LinqDbSet[T]) WithIdentityResolution() *LinqDbSet[T] {
+	newDbSet := ds.clone(ds.db)
+	newDbSet.identityResolution = true
+	return newDbSet
+}
+
+// identityMap holds the instance kept for each entity key while a loaded graph is resolved
+type identityMap struct {
+	db        *gorm.DB
+	instances map[string]reflect.Value // Entity type and key -> pointer to the shared instance
+	visited   map[uintptr]bool         // Instances whose navigations were already resolved
+}
+
+// resolveIdentities points the navigations of the loaded results, a pointer to an entity or a slice of
+// entities, at one shared instance per entity key when the query asked for it and included anything
+func (ds *LinqDbSet[T]) resolveIdentities(results interface{}) {
+	if !ds.identityResolution || len(ds.db.Statement.Preloads) == 0 {
+		return
+	}
+
+	ids := &identityMap{db: ds.db, instances: make(map[string]reflect.Value), visited: make(map[uintptr]bool)}
+	var roots []reflect.Value
+	value := reflect.ValueOf(results)
+	switch value.Kind() {
+	case reflect.Ptr:
+		roots = append(roots, value)
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			roots = append(roots, value.Index(i).Addr())
+		}
+	}
+
+	// The query's rows are registered first, so navigations back to them resolve to the returned rows
+	for _, root := range roots {
+		ids.register(root)
+	}
+	for _, root := range roots {
+		ids.visit(root)
+	}
+}
+
+// key identifies an entity instance by its type and primary key, "" for entities without a set key
+func (ids *identityMap) key(instance reflect.Value) (string, *schema.Schema) {
+	stmt := &gorm.Statement{DB: ids.db}
+	if err := stmt.Parse(instance.Interface()); err != nil || len(stmt.Schema.PrimaryFields) == 0 {
+		return "", nil
+	}
+
+	key := instance.Type().Elem().String()
+	for _, field := range stmt.Schema.PrimaryFields {
+		value, zero := field.ValueOf(ids.db.Statement.Context, instance.Elem())
+		if zero {
+			return "", stmt.Schema
+		}
+		key += fmt.Sprintf("|%v", value)
+	}
+	return key, stmt.Schema
+}
+
+// register keeps instance as the shared instance of its key unless one is kept already
+func (ids *identityMap) register(instance reflect.Value) {
+	if key, _ := ids.key(instance); key != "" {
+		if _, exists := ids.instances[key]; !exists {
+			ids.instances[key] = instance
+		}
+	}
+}
+
+// resolve returns the shared instance for instance's key, registering instance when it is the first;
+// navigations only the duplicate loaded are moved onto the shared instance so nothing loaded is lost
+func (ids *identityMap) resolve(instance reflect.Value) reflect.Value {
+	key, sch := ids.key(instance)
+	if key == "" {
+		return instance
+	}
+	shared, exists := ids.instances[key]
+	if !exists {
+		ids.instances[key] = instance
+		return instance
+	}
+	if shared.Pointer() != instance.Pointer() {
+		for _, rel := range sch.Relationships.Relations {
+			target := rel.Field.ReflectValueOf(ids.db.Statement.Context, shared.Elem())
+			loaded := rel.Field.ReflectValueOf(ids.db.Statement.Context, instance.Elem())
+			if target.IsZero() && !loaded.IsZero() {
+				target.Set(loaded)
+				delete(ids.visited, shared.Pointer()) // Resolve the moved navigations too
+			}
+		}
+	}
+	return shared
+}
+
+// visit replaces the pointer navigations of instance with shared instances, then resolves their own
+func (ids *identityMap) visit(instance reflect.Value) {
+	if instance.IsNil() || ids.visited[instance.Pointer()] {
+		return
+	}
+	ids.visited[instance.Pointer()] = true
+
+	_, sch := ids.key(instance)
+	if sch == nil {
+		return
+	}
+	for _, rel := range sch.Relationships.Relations {
+		navigation := rel.Field.ReflectValueOf(ids.db.Statement.Context, instance.Elem())
+		switch navigation.Kind() {
+		case reflect.Ptr:
+			if navigation.IsNil() {
+				continue
+			}
+			shared := ids.resolve(navigation)
+			navigation.Set(shared)
+			ids.visit(shared)
+		case reflect.Struct:
+			ids.register(navigation.Addr())
+			ids.visit(navigation.Addr())
+		case reflect.Slice:
+			for i := 0; i < navigation.Len(); i++ {
+				element := navigation.Index(i)
+				if element.Kind() == reflect.Ptr {
+					if element.IsNil() {
+						continue
+					}
+					shared := ids.resolve(element)
+					element.Set(shared)
+					ids.visit(shared)
+				} else {
+					ids.register(element.Addr())
+					ids.visit(element.Addr())
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package linq
+
+import (
+	stdcontext "context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// ErrInvalidListRequest is wrapped by the errors Listing returns for input the endpoint does not allow -
+// a field missing from an allowlist, an unknown operator, a value of the wrong type - so handlers can
+// answer 400 Bad Request with errors.Is
+var ErrInvalidListRequest = errors.New("invalid list request")
+
+// Pagination selects a page of a list endpoint; pages start at 1
+type Pagination struct {
+	Page     int `json:"page" form:"page" query:"page"`
+	PageSize int `json:"pageSize" form:"pageSize" query:"pageSize"`
+}
+
+// Sorting orders a list endpoint: comma-separated fields, descending with a - prefix ("-CreatedAt,Name")
+type Sorting struct {
+	Sort string `json:"sort" form:"sort" query:"sort"`
+}
+
+// Filter narrows a list endpoint to rows whose Field compares to Value with Operator: eq (the default),
+// ne, gt, gte, lt, lte, contains, startswith, endswith, or in with comma-separated values
+type Filter struct {
+	Field    string `json:"field"`
+	Operator string `json:"operator"`
+	Value    string `json:"value"`
+}
+
+// ListRequest is the input of a list endpoint, bound from the query string or a JSON body
+type ListRequest struct {
+	Pagination
+	Sorting
+	Filters []Filter `json:"filters"`
+}
+
+// Listing answers list endpoints over a query: it filters, counts, sorts, pages and maps the rows onto
+// the DTO D from a ListRequest, accepting only the fields named by SortableBy and FilterableBy
+type Listing[T any, D any] struct {
+	set             *LinqDbSet[T]
+	sortable        map[string]string // Lower-cased allowed name -> field
+	filterable      map[string]string
+	defaultSort     string
+	defaultPageSize int
+	maxPageSize     int
+}
+
+// ListEndpoint creates a Listing over ds, whose filters apply to every request (tenancy, soft deletion)
+// Nothing is sortable or filterable until allowed; pages default to 20 rows and at most 100
+//
+//	users := gontext.ListEndpoint[User, UserDto](ctx.Users.Where("TenantId", tenant)).
+//	    SortableBy("Name", "CreatedAt").FilterableBy("Role", "Name").DefaultSort("-CreatedAt")
+//	items, total, err := users.List(request)
+func ListEndpoint[T any, D any](ds *LinqDbSet[T]) *Listing[T, D] {
+	return &Listing[T, D]{
+		set:             ds,
+		sortable:        make(map[string]string),
+		filterable:      make(map[string]string),
+		defaultPageSize: 20,
+		maxPageSize:     100,
+	}
+}
+
+// SortableBy allows requests to sort by fields (Go field names, or navigation paths like "Author.Name");
+// requests name them case-insensitively, so "createdAt" selects CreatedAt
+func (l *Listing[T, D]) SortableBy(fields ...string) *Listing[T, D] {
+	newListing := l.copy()
+	for _, field := range fields {
+		newListing.sortable[strings.ToLower(l.checkField(field))] = field
+	}
+	return newListing
+}
+
+// FilterableBy allows requests to filter on fields, named like SortableBy
+func (l *Listing[T, D]) FilterableBy(fields ...string) *Listing[T, D] {
+	newListing := l.copy()
+	for _, field := range fields {
+		newListing.filterable[strings.ToLower(l.checkField(field))] = field
+	}
+	return newListing
+}
+
+// DefaultSort orders requests that do not choose a sort, in the form of Sorting.Sort; it may use any field
+func (l *Listing[T, D]) DefaultSort(sort string) *Listing[T, D] {
+	newListing := l.copy()
+	newListing.defaultSort = sort
+	return newListing
+}
+
+// PageSize sets the page size of requests without one and the largest a request may ask for
+func (l *Listing[T, D]) PageSize(defaultSize, maxSize int) *Listing[T, D] {
+	if defaultSize <= 0 || maxSize < defaultSize {
+		panic(fmt.Sprintf("Page sizes of %s must satisfy 0 < default (%d) <= max (%d)", l.set.entityType.Name(), defaultSize, maxSize))
+	}
+	newListing := l.copy()
+	newListing.defaultPageSize = defaultSize
+	newListing.maxPageSize = maxSize
+	return newListing
+}
+
+// List returns a page of rows mapped onto D and the number of rows matching the filters on all pages
+// Page sizes above the maximum are capped. Rows are sorted by the primary key last, so pages do not
+// overlap when the sorted fields have equal values
+func (l *Listing[T, D]) List(request ListRequest) ([]D, int64, error) {
+	return l.ListContext(stdcontext.Background(), request)
+}
+
+// ListContext is List canceled when c is done
+func (l *Listing[T, D]) ListContext(c stdcontext.Context, request ListRequest) ([]D, int64, error) {
+	filtered := l.set.WithContext(c)
+	for _, filter := range request.Filters {
+		var err error
+		if filtered, err = l.filter(filtered, filter); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	total, err := filtered.Count()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sort := request.Sort
+	if strings.TrimSpace(sort) == "" {
+		sort = l.defaultSort
+	}
+	sorted, err := l.sort(filtered, sort, sort != request.Sort)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	page, pageSize := request.Page, request.PageSize
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = l.defaultPageSize
+	}
+	if pageSize > l.maxPageSize {
+		pageSize = l.maxPageSize
+	}
+
+	items, err := MapTo[D](sorted.Skip((page - 1) * pageSize).Take(pageSize)).ToList()
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// filter adds one request filter, converting its value to the field's type
+func (l *Listing[T, D]) filter(ds *LinqDbSet[T], filter Filter) (*LinqDbSet[T], error) {
+	field, ok := l.filterable[strings.ToLower(filter.Field)]
+	if !ok {
+		return nil, fmt.Errorf("%w: filtering on %q is not allowed", ErrInvalidListRequest, filter.Field)
+	}
+
+	operator := strings.ToLower(filter.Operator)
+	switch operator {
+	case "contains":
+		return ds.WhereFieldLike(field, filter.Value), nil
+	case "startswith":
+		return ds.WhereFieldStartsWith(field, filter.Value), nil
+	case "endswith":
+		return ds.WhereFieldEndsWith(field, filter.Value), nil
+	case "in":
+		var values []interface{}
+		for _, text := range strings.Split(filter.Value, ",") {
+			value, err := l.value(field, strings.TrimSpace(text))
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return ds.WhereFieldIn(field, values), nil
+	}
+
+	value, err := l.value(field, filter.Value)
+	if err != nil {
+		return nil, err
+	}
+	comparisons := map[string]func(interface{}) Comparison{
+		"": Equal, "eq": Equal, "ne": NotEqual,
+		"gt": GreaterThan, "gte": GreaterThanOrEqual, "lt": LessThan, "lte": LessThanOrEqual,
+	}
+	comparison, ok := comparisons[operator]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown operator %q for %q", ErrInvalidListRequest, filter.Operator, filter.Field)
+	}
+	return ds.WhereField(field, comparison(value)), nil
+}
+
+// value converts a filter value to the Go type of field; values of navigation paths stay strings
+func (l *Listing[T, D]) value(fieldName, text string) (interface{}, error) {
+	field := l.set.lookupField(fieldName)
+	if field == nil || strings.Contains(fieldName, ".") {
+		return text, nil
+	}
+	if field.FieldType.Kind() == reflect.String {
+		return text, nil
+	}
+
+	entity := reflect.New(l.set.entityType).Elem()
+	ctx := l.set.db.Statement.Context
+	if err := field.Set(ctx, entity, text); err != nil {
+		return nil, fmt.Errorf("%w: %q is not a valid %s for %q", ErrInvalidListRequest, text, field.FieldType, fieldName)
+	}
+	value, _ := field.ValueOf(ctx, entity)
+	return value, nil
+}
+
+// sort orders the rows by a sort string, then by the primary key; trusted sorts (the default) may use
+// fields outside the allowlist
+func (l *Listing[T, D]) sort(ds *LinqDbSet[T], sort string, trusted bool) (*LinqDbSet[T], error) {
+	sorted := false
+	sortedByKey := false
+	key := l.primaryKey()
+	for _, term := range strings.Split(sort, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		descending := strings.HasPrefix(term, "-")
+		name := strings.TrimLeft(term, "+-")
+
+		field, ok := l.sortable[strings.ToLower(name)]
+		if !ok && !trusted {
+			return nil, fmt.Errorf("%w: sorting by %q is not allowed", ErrInvalidListRequest, name)
+		}
+		if !ok {
+			field = name
+		}
+		if key != nil && strings.EqualFold(field, key.Name) {
+			sortedByKey = true
+		}
+
+		switch {
+		case !sorted && descending:
+			ds = ds.OrderByDescending(field)
+		case !sorted:
+			ds = ds.OrderBy(field)
+		case descending:
+			ds = ds.ThenByDescending(field)
+		default:
+			ds = ds.ThenBy(field)
+		}
+		sorted = true
+	}
+
+	if key == nil || sortedByKey {
+		return ds, nil
+	}
+	if !sorted {
+		return ds.OrderBy(key.Name), nil
+	}
+	return ds.ThenBy(key.Name), nil
+}
+
+// primaryKey returns T's single primary key field, nil for composite or missing keys
+func (l *Listing[T, D]) primaryKey() *schema.Field {
+	stmt := &gorm.Statement{DB: l.set.db}
+	if err := stmt.Parse(new(T)); err != nil || len(stmt.Schema.PrimaryFields) != 1 {
+		return nil
+	}
+	return stmt.Schema.PrimaryFields[0]
+}
+
+// checkField panics for allowlist entries that are not fields of T; paths are checked when used
+func (l *Listing[T, D]) checkField(field string) string {
+	if !strings.Contains(field, ".") && l.set.lookupField(field) == nil {
+		panic(fmt.Sprintf("Field '%s' not found on %s", field, l.set.entityType.Name()))
+	}
+	return field
+}
+
+// copy returns a Listing with its own allowlists
+func (l *Listing[T, D]) copy() *Listing[T, D] {
+	newListing := *l
+	newListing.sortable = make(map[string]string, len(l.sortable))
+	for name, field := range l.sortable {
+		newListing.sortable[name] = field
+	}
+	newListing.filterable = make(map[string]string, len(l.filterable))
+	for name, field := range l.filterable {
+		newListing.filterable[name] = field
+	}
+	return &newListing
+}
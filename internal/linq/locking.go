@@ -0,0 +1,47 @@
+package linq
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LockMode selects the row lock taken by WithLock; combine a strength with an option: LockForUpdate | SkipLocked
+type LockMode int
+
+const (
+	LockForUpdate LockMode = 1 << iota // SELECT ... FOR UPDATE
+	LockForShare                       // SELECT ... FOR SHARE
+	SkipLocked                         // skip rows locked by other transactions (queue consumers)
+	NoWait                             // fail immediately instead of waiting for a lock
+)
+
+// WithLock - adds a row lock to the query; must run inside a transaction to hold the lock
+// Usage: ctx.Jobs.Where("Status", "pending").OrderBy("CreatedAt").Take(10).WithLock(LockForUpdate | SkipLocked).ToList()
+// SQLite has no row locks (transactions lock the database), so the lock is omitted there.
+func (ds *LinqDbSet[T]) WithLock(mode LockMode) *LinqDbSet[T] {
+	if ds.db.Dialector.Name() == "sqlite" {
+		return ds
+	}
+
+	locking := clause.Locking{Strength: "UPDATE"}
+	if mode&LockForShare != 0 && mode&LockForUpdate == 0 {
+		locking.Strength = "SHARE"
+	}
+
+	switch {
+	case mode&SkipLocked != 0:
+		locking.Options = "SKIP LOCKED"
+	case mode&NoWait != 0:
+		locking.Options = "NOWAIT"
+	}
+
+	return ds.clone(ds.db.Clauses(locking))
+}
+
+// InTransaction - runs the query on an open transaction, e.g. one from ctx.BeginTransaction()
+// Required for WithLock: the lock is held until the transaction commits or rolls back
+func (ds *LinqDbSet[T]) InTransaction(tx *gorm.DB) *LinqDbSet[T] {
+	newDb := ds.db.Session(&gorm.Session{}).Clauses() // copy the accumulated statement
+	newDb.Statement.ConnPool = tx.Statement.ConnPool
+	return ds.clone(newDb)
+}
@@ -0,0 +1,84 @@
+package linq
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/shepherrrd/gontext/internal/dberrors"
+)
+
+// Mapped is a query projected onto the DTO type D: only the entity columns D declares are selected
+type Mapped[T any, D any] struct {
+	set *LinqDbSet[T]
+}
+
+// MapTo - AutoMapper: ProjectTo<UserDto>() - selects only the entity columns matching D's fields and scans them into D
+// A DTO field maps to the entity field with the same Go name, column name or json tag; `gontext:"from:Email"` names the
+// source field and `gontext:"-"` (or `gorm:"-"`) leaves the field unmapped. A field matching no column is an error
+//
+//	dtos, err := gontext.MapTo[UserDto](ctx.Users.Where("IsActive", true).OrderBy("Name")).ToList()
+func MapTo[D any, T any](ds *LinqDbSet[T]) *Mapped[T, D] {
+	columns, err := ds.mappedColumns(new(D))
+	if err != nil {
+		return &Mapped[T, D]{set: ds.fail(fmt.Sprintf("MapTo[%T]", *new(D)), err)}
+	}
+	return &Mapped[T, D]{set: ds.clone(ds.db.Select(columns))}
+}
+
+// ToList runs the query and returns the mapped rows
+func (m *Mapped[T, D]) ToList() ([]D, error) {
+	var results []D
+	err := m.set.query().Model(new(T)).Scan(&results).Error
+	if err != nil {
+		return nil, dberrors.Translate(err)
+	}
+	return results, nil
+}
+
+// FirstOrDefault returns the first mapped row, or nil when there is none
+func (m *Mapped[T, D]) FirstOrDefault() (*D, error) {
+	first := &Mapped[T, D]{set: m.set.Take(1)}
+	results, err := first.ToList()
+	if err != nil || len(results) == 0 {
+		return nil, err
+	}
+	return &results[0], nil
+}
+
+// mappedColumns builds `"table"."column" AS "dto_column"` for each mapped field of dto
+func (ds *LinqDbSet[T]) mappedColumns(dto interface{}) ([]string, error) {
+	stmt := &gorm.Statement{DB: ds.db}
+	if err := stmt.Parse(dto); err != nil {
+		return nil, err
+	}
+
+	var columns []string
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName == "" || !field.Readable || field.Tag.Get("gontext") == "-" {
+			continue
+		}
+
+		source := field.Name
+		for _, option := range strings.Split(field.Tag.Get("gontext"), ";") {
+			if name, ok := strings.CutPrefix(strings.TrimSpace(option), "from:"); ok {
+				source = name
+			}
+		}
+
+		entityField := ds.lookupField(source)
+		if entityField == nil {
+			return nil, fmt.Errorf("field %s matches no column of %s (tag it `gontext:\"-\"` to leave it unmapped)", field.Name, ds.entityType.Name())
+		}
+		columns = append(columns, fmt.Sprintf("%s AS %s",
+			ds.db.Statement.Quote(clause.Column{Table: entityField.Schema.Table, Name: entityField.DBName}),
+			ds.db.Statement.Quote(field.DBName)))
+	}
+
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no fields of %s map to columns of %s", stmt.Schema.Name, ds.entityType.Name())
+	}
+	return columns, nil
+}
@@ -0,0 +1,102 @@
+package linq
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// navigate resolves a field name to its quoted column. Navigation paths such as "Author.Username"
+// or "Author.Profile.Bio" join each related entity once (LEFT JOIN aliased by the navigation name)
+// and return the joined column: "Author"."Username".
+// Only reference navigations (belongs-to, has-one) can be joined; other names resolve like quoteField.
+func (ds *LinqDbSet[T]) navigate(method, fieldName string) (*LinqDbSet[T], string) {
+	dot := strings.LastIndex(fieldName, ".")
+	if dot < 0 {
+		if ds.strict() && ds.lookupField(fieldName) == nil {
+			return ds.invalidQuery(call(method, fieldName), fmt.Errorf("field not found")), ds.quoteField(fieldName)
+		}
+		return ds, ds.quoteField(fieldName)
+	}
+
+	stmt := &gorm.Statement{DB: ds.db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return ds, ds.quoteField(fieldName)
+	}
+
+	var names []string
+	current := stmt.Schema
+	for _, name := range strings.Split(fieldName[:dot], ".") {
+		relationship := lookupRelationship(current, name)
+		if relationship == nil || (relationship.Type != schema.BelongsTo && relationship.Type != schema.HasOne) {
+			// Not a navigation - e.g. a qualified reference to another table
+			return ds, ds.quoteField(fieldName)
+		}
+		names = append(names, relationship.Name)
+		current = relationship.FieldSchema
+	}
+
+	field := current.LookUpField(fieldName[dot+1:])
+	if field == nil {
+		for _, candidate := range current.Fields {
+			if strings.EqualFold(candidate.Name, fieldName[dot+1:]) {
+				field = candidate
+				break
+			}
+		}
+	}
+	if field == nil || field.DBName == "" {
+		return ds.invalidQuery(call(method, fieldName), fmt.Errorf("field not found on %s", current.Name)), ds.quoteField(fieldName)
+	}
+
+	newDbSet := ds
+	if joinPath := strings.Join(names, "."); !hasJoin(ds.db, joinPath) {
+		newDbSet = ds.clone(ds.db.Joins(joinPath))
+	}
+
+	// GORM aliases nested joins as Author__Profile
+	alias := strings.Join(names, "__")
+	return newDbSet, ds.db.Statement.Quote(clause.Column{Table: alias, Name: field.DBName})
+}
+
+// navigationPath resolves a dotted path of navigations ("Author.Profile") from s, ignoring case, to
+// the path GORM preloads
+func navigationPath(s *schema.Schema, path string) (string, error) {
+	var names []string
+	current := s
+	for _, name := range strings.Split(path, ".") {
+		relationship := lookupRelationship(current, name)
+		if relationship == nil {
+			return "", fmt.Errorf("navigation '%s' not found on %s", name, current.Name)
+		}
+		names = append(names, relationship.Name)
+		current = relationship.FieldSchema
+	}
+	return strings.Join(names, "."), nil
+}
+
+// lookupRelationship finds a navigation by name, ignoring case
+func lookupRelationship(s *schema.Schema, name string) *schema.Relationship {
+	if relationship, ok := s.Relationships.Relations[name]; ok {
+		return relationship
+	}
+	for relationName, relationship := range s.Relationships.Relations {
+		if strings.EqualFold(relationName, name) {
+			return relationship
+		}
+	}
+	return nil
+}
+
+// hasJoin reports whether the query already joins the navigation path
+func hasJoin(db *gorm.DB, path string) bool {
+	for _, join := range db.Statement.Joins {
+		if join.Name == path {
+			return true
+		}
+	}
+	return false
+}
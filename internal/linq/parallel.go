@@ -0,0 +1,64 @@
+package linq
+
+import (
+	stdcontext "context"
+	"fmt"
+	"sync"
+)
+
+// ParallelQuery is a query Parallel can run; create one with Defer
+type ParallelQuery interface {
+	run(ctx stdcontext.Context) error
+}
+
+// Deferred is a query to run with Parallel; Result and Err hold its outcome once Parallel returns
+type Deferred[T any] struct {
+	query  func(ctx stdcontext.Context) (T, error)
+	Result T
+	Err    error
+}
+
+// Defer wraps a query returning any typed result - a list, a count, a single entity - for Parallel
+// The query should pass ctx on with WithContext so a failing sibling cancels it
+func Defer[T any](query func(ctx stdcontext.Context) (T, error)) *Deferred[T] {
+	return &Deferred[T]{query: query}
+}
+
+func (d *Deferred[T]) run(ctx stdcontext.Context) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("parallel query panicked: %v", recovered)
+			d.Err = err
+		}
+	}()
+	d.Result, d.Err = d.query(ctx)
+	return d.Err
+}
+
+// Parallel runs independent queries concurrently, each on its own pooled connection, and waits for all
+// of them. The first error cancels ctx for the others and is returned; each Deferred keeps its own Err
+// Queries must not share a transaction: a connection cannot run two statements at once
+func Parallel(ctx stdcontext.Context, queries ...ParallelQuery) error {
+	ctx, cancel := stdcontext.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg    sync.WaitGroup
+		once  sync.Once
+		first error
+	)
+	for _, query := range queries {
+		wg.Add(1)
+		go func(query ParallelQuery) {
+			defer wg.Done()
+			if err := query.run(ctx); err != nil {
+				once.Do(func() {
+					first = err
+					cancel()
+				})
+			}
+		}(query)
+	}
+	wg.Wait()
+	return first
+}
@@ -0,0 +1,49 @@
+package linq
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm/clause"
+
+	"github.com/shepherrrd/gontext/internal/models"
+)
+
+// WherePartitionRange filters a range-partitioned set to partition keys in [from, to), a condition
+// PostgreSQL prunes partitions with, so only the partitions overlapping the range are scanned
+// Usage: ctx.Events.WherePartitionRange(monthStart, monthStart.AddDate(0, 1, 0)).ToList()
+// Fails the query when the entity is not partitioned by range
+func (ds *LinqDbSet[T]) WherePartitionRange(from, to time.Time) *LinqDbSet[T] {
+	partition, failed := ds.partition("WherePartitionRange", models.PartitionByRange)
+	if failed != nil {
+		return failed
+	}
+	column := clause.Column{Table: clause.CurrentTable, Name: partition.Column}
+	return ds.clone(ds.db.Where(clause.Gte{Column: column, Value: from}).Where(clause.Lt{Column: column, Value: to}))
+}
+
+// WherePartitionKey filters a partitioned set to one partition key value, so only the partition holding
+// it is scanned; for hash partitioning this is the only condition PostgreSQL prunes with
+// Usage: ctx.Readings.WherePartitionKey(deviceID).Where("RecordedAt > ?", since).ToList()
+// Fails the query when the entity is not partitioned
+func (ds *LinqDbSet[T]) WherePartitionKey(value interface{}) *LinqDbSet[T] {
+	partition, failed := ds.partition("WherePartitionKey", "")
+	if failed != nil {
+		return failed
+	}
+	column := clause.Column{Table: clause.CurrentTable, Name: partition.Column}
+	return ds.clone(ds.db.Where(clause.Eq{Column: column, Value: value}))
+}
+
+// partition returns the entity's partitioning; when it has none or not of strategy (any when empty) it
+// returns a set carrying the error instead, which the query reports when it runs
+func (ds *LinqDbSet[T]) partition(method string, strategy models.PartitionStrategy) (*models.PartitionModel, *LinqDbSet[T]) {
+	partition := ds.entityModel().Partition
+	if partition == nil {
+		return nil, ds.fail(method, fmt.Errorf("not partitioned; configure it with PartitionByRange or PartitionByHash"))
+	}
+	if strategy != "" && partition.Strategy != strategy {
+		return nil, ds.fail(method, fmt.Errorf("partitioned by %s, not %s", partition.Strategy, strategy))
+	}
+	return partition, nil
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"gorm.io/gorm"
+	"github.com/shepherrrd/gontext/internal/models"
 	"github.com/shepherrrd/gontext/internal/query"
 )
 
@@ -27,23 +28,13 @@ func NewPostgreSQLLinqDbSet[T any](db *gorm.DB, ctx interface{}) *PostgreSQLLinq
 	}
 	
 	// Get table name
-	tableName := entityType.Name()
-	if tabler, ok := interface{}(zero).(interface{ TableName() string }); ok {
-		tableName = tabler.TableName()
-	}
+	tableName := tableNameOf(ctx, entityType)
 	
 	// Create translator
 	translator := query.NewPostgreSQLQueryTranslator()
 	
 	// Register field names
-	var fieldNames []string
-	for i := 0; i < entityType.NumField(); i++ {
-		field := entityType.Field(i)
-		if field.PkgPath == "" { // exported field
-			fieldNames = append(fieldNames, field.Name)
-		}
-	}
-	translator.RegisterEntityFields(tableName, fieldNames)
+	translator.RegisterEntityFields(tableName, models.FieldNames(entityType))
 	
 	return &PostgreSQLLinqDbSet[T]{
 		LinqDbSet:  baseDbSet,
@@ -402,9 +393,9 @@ func (ds *PostgreSQLLinqDbSet[T]) Include(args ...interface{}) *PostgreSQLLinqDb
 	}
 }
 
-// IncludeAll - Load all relationships automatically
-func (ds *PostgreSQLLinqDbSet[T]) IncludeAll() *PostgreSQLLinqDbSet[T] {
-	newLinqDbSet := ds.LinqDbSet.IncludeAll()
+// IncludeAll - Load all relationships automatically, following navigations down to maxDepth levels
+func (ds *PostgreSQLLinqDbSet[T]) IncludeAll(maxDepth ...int) *PostgreSQLLinqDbSet[T] {
+	newLinqDbSet := ds.LinqDbSet.IncludeAll(maxDepth...)
 	
 	return &PostgreSQLLinqDbSet[T]{
 		LinqDbSet:  newLinqDbSet,
@@ -431,7 +422,7 @@ func (ds *PostgreSQLLinqDbSet[T]) SumField(fieldName string) (float64, error) {
 	var result float64
 	quotedFieldName := ds.translator.GetQuotedFieldName(fieldName)
 	
-	err := ds.LinqDbSet.db.Model(new(T)).Select(fmt.Sprintf("COALESCE(SUM(%s), 0)", quotedFieldName)).Scan(&result).Error
+	err := ds.LinqDbSet.paginated().Select(fmt.Sprintf("COALESCE(SUM(%s), 0)", quotedFieldName)).Scan(&result).Error
 	return result, err
 }
 
@@ -440,7 +431,7 @@ func (ds *PostgreSQLLinqDbSet[T]) AverageField(fieldName string) (float64, error
 	var result float64
 	quotedFieldName := ds.translator.GetQuotedFieldName(fieldName)
 	
-	err := ds.LinqDbSet.db.Model(new(T)).Select(fmt.Sprintf("COALESCE(AVG(%s), 0)", quotedFieldName)).Scan(&result).Error
+	err := ds.LinqDbSet.paginated().Select(fmt.Sprintf("COALESCE(AVG(%s), 0)", quotedFieldName)).Scan(&result).Error
 	return result, err
 }
 
@@ -449,7 +440,7 @@ func (ds *PostgreSQLLinqDbSet[T]) MinField(fieldName string) (interface{}, error
 	var result interface{}
 	quotedFieldName := ds.translator.GetQuotedFieldName(fieldName)
 	
-	err := ds.LinqDbSet.db.Model(new(T)).Select(fmt.Sprintf("MIN(%s)", quotedFieldName)).Scan(&result).Error
+	err := ds.LinqDbSet.paginated().Select(fmt.Sprintf("MIN(%s)", quotedFieldName)).Scan(&result).Error
 	return result, err
 }
 
@@ -458,7 +449,7 @@ func (ds *PostgreSQLLinqDbSet[T]) MaxField(fieldName string) (interface{}, error
 	var result interface{}
 	quotedFieldName := ds.translator.GetQuotedFieldName(fieldName)
 	
-	err := ds.LinqDbSet.db.Model(new(T)).Select(fmt.Sprintf("MAX(%s)", quotedFieldName)).Scan(&result).Error
+	err := ds.LinqDbSet.paginated().Select(fmt.Sprintf("MAX(%s)", quotedFieldName)).Scan(&result).Error
 	return result, err
 }
 
@@ -467,7 +458,7 @@ func (ds *PostgreSQLLinqDbSet[T]) CountField(fieldName string) (int64, error) {
 	var result int64
 	quotedFieldName := ds.translator.GetQuotedFieldName(fieldName)
 	
-	err := ds.LinqDbSet.db.Model(new(T)).Select(fmt.Sprintf("COUNT(%s)", quotedFieldName)).Scan(&result).Error
+	err := ds.LinqDbSet.paginated().Select(fmt.Sprintf("COUNT(%s)", quotedFieldName)).Scan(&result).Error
 	return result, err
 }
 
@@ -476,6 +467,6 @@ func (ds *PostgreSQLLinqDbSet[T]) CountDistinctField(fieldName string) (int64, e
 	var result int64
 	quotedFieldName := ds.translator.GetQuotedFieldName(fieldName)
 	
-	err := ds.LinqDbSet.db.Model(new(T)).Select(fmt.Sprintf("COUNT(DISTINCT %s)", quotedFieldName)).Scan(&result).Error
+	err := ds.LinqDbSet.paginated().Select(fmt.Sprintf("COUNT(DISTINCT %s)", quotedFieldName)).Scan(&result).Error
 	return result, err
 }
\ No newline at end of file
@@ -0,0 +1,222 @@
+package linq
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/shepherrrd/gontext/internal/types"
+)
+
+// TypedField names an entity field with its Go value type so predicates are checked at compile time
+// Declare once per entity: var UserF = struct{ Age TypedField[int] }{ Age: FieldOf[int]("Age") }
+type TypedField[V any] struct {
+	name string
+}
+
+// FieldOf creates a typed field reference; the name may be a navigation path like "Author.Username"
+func FieldOf[V any](name string) TypedField[V] {
+	return TypedField[V]{name: name}
+}
+
+// Name returns the field name
+func (f TypedField[V]) Name() string {
+	return f.name
+}
+
+// Predicate is a composable, parameterized condition built with Eq, Gt, And, Or, ...
+// Pass it to Where or Or: ctx.Users.Where(And(Eq(UserF.IsActive, true), Gt(UserF.Age, 30)))
+type Predicate interface {
+	// build renders the condition; column resolves a field name to its quoted column
+	build(column func(fieldName string) string) (string, []interface{})
+}
+
+// comparison is a single field condition such as "Age" > ?
+type comparison struct {
+	fieldName string
+	operator  string
+	value     interface{}
+	hasValue  bool
+}
+
+func (c comparison) build(column func(string) string) (string, []interface{}) {
+	if !c.hasValue {
+		return fmt.Sprintf("%s %s", column(c.fieldName), c.operator), nil
+	}
+	// Eq(f, nil) / Ne(f, nil) on pointer fields compare with NULL
+	if isNilValue(c.value) {
+		switch c.operator {
+		case "=":
+			return fmt.Sprintf("%s IS NULL", column(c.fieldName)), nil
+		case "<>":
+			return fmt.Sprintf("%s IS NOT NULL", column(c.fieldName)), nil
+		}
+	}
+	return fmt.Sprintf("%s %s ?", column(c.fieldName), c.operator), []interface{}{types.QueryValue(c.value)}
+}
+
+// isNilValue reports whether value is nil or a nil pointer, map, slice or interface
+func isNilValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// junction joins predicates with AND or OR
+type junction struct {
+	operator   string
+	predicates []Predicate
+}
+
+func (j junction) build(column func(string) string) (string, []interface{}) {
+	var parts []string
+	var args []interface{}
+	for _, predicate := range j.predicates {
+		if predicate == nil {
+			continue
+		}
+		sql, predicateArgs := predicate.build(column)
+		if sql == "" {
+			continue
+		}
+		parts = append(parts, sql)
+		args = append(args, predicateArgs...)
+	}
+	switch len(parts) {
+	case 0:
+		return "", nil
+	case 1:
+		return parts[0], args
+	default:
+		return "(" + strings.Join(parts, " "+j.operator+" ") + ")", args
+	}
+}
+
+// negation wraps a predicate in NOT
+type negation struct {
+	predicate Predicate
+}
+
+func (n negation) build(column func(string) string) (string, []interface{}) {
+	sql, args := n.predicate.build(column)
+	if sql == "" {
+		return "", nil
+	}
+	return "NOT (" + sql + ")", args
+}
+
+// And matches when every predicate matches
+func And(predicates ...Predicate) Predicate {
+	return junction{operator: "AND", predicates: predicates}
+}
+
+// Or matches when any predicate matches
+func Or(predicates ...Predicate) Predicate {
+	return junction{operator: "OR", predicates: predicates}
+}
+
+// Not negates a predicate
+func Not(predicate Predicate) Predicate {
+	return negation{predicate: predicate}
+}
+
+// Eq - field = value
+func Eq[V any](field TypedField[V], value V) Predicate {
+	return comparison{fieldName: field.name, operator: "=", value: value, hasValue: true}
+}
+
+// Ne - field <> value
+func Ne[V any](field TypedField[V], value V) Predicate {
+	return comparison{fieldName: field.name, operator: "<>", value: value, hasValue: true}
+}
+
+// Gt - field > value
+func Gt[V any](field TypedField[V], value V) Predicate {
+	return comparison{fieldName: field.name, operator: ">", value: value, hasValue: true}
+}
+
+// Gte - field >= value
+func Gte[V any](field TypedField[V], value V) Predicate {
+	return comparison{fieldName: field.name, operator: ">=", value: value, hasValue: true}
+}
+
+// Lt - field < value
+func Lt[V any](field TypedField[V], value V) Predicate {
+	return comparison{fieldName: field.name, operator: "<", value: value, hasValue: true}
+}
+
+// Lte - field <= value
+func Lte[V any](field TypedField[V], value V) Predicate {
+	return comparison{fieldName: field.name, operator: "<=", value: value, hasValue: true}
+}
+
+// Like - field LIKE pattern; % and _ are wildcards
+func Like(field TypedField[string], pattern string) Predicate {
+	return comparison{fieldName: field.name, operator: "LIKE", value: pattern, hasValue: true}
+}
+
+// Contains - field LIKE %value%, matching value literally
+func Contains(field TypedField[string], value string) Predicate {
+	return likePredicate{fieldName: field.name, pattern: "%" + escapeLike(value) + "%"}
+}
+
+// StartsWith - field LIKE value%, matching value literally
+func StartsWith(field TypedField[string], prefix string) Predicate {
+	return likePredicate{fieldName: field.name, pattern: escapeLike(prefix) + "%"}
+}
+
+// EndsWith - field LIKE %value, matching value literally
+func EndsWith(field TypedField[string], suffix string) Predicate {
+	return likePredicate{fieldName: field.name, pattern: "%" + escapeLike(suffix)}
+}
+
+// likePredicate is a LIKE condition whose pattern was escaped with escapeLike
+type likePredicate struct {
+	fieldName string
+	pattern   string
+}
+
+func (l likePredicate) build(column func(string) string) (string, []interface{}) {
+	return fmt.Sprintf("%s LIKE ? ESCAPE '%s'", column(l.fieldName), likeEscapeChar), []interface{}{l.pattern}
+}
+
+// In - field IN (values...)
+func In[V any](field TypedField[V], values ...V) Predicate {
+	return comparison{fieldName: field.name, operator: "IN", value: values, hasValue: true}
+}
+
+// IsNull - field IS NULL
+func IsNull[V any](field TypedField[V]) Predicate {
+	return comparison{fieldName: field.name, operator: "IS NULL"}
+}
+
+// IsNotNull - field IS NOT NULL
+func IsNotNull[V any](field TypedField[V]) Predicate {
+	return comparison{fieldName: field.name, operator: "IS NOT NULL"}
+}
+
+// wherePredicate renders a predicate against this set, joining any navigations its fields use
+func (ds *LinqDbSet[T]) wherePredicate(predicate Predicate, or bool) *LinqDbSet[T] {
+	newDbSet := ds
+	resolve := func(fieldName string) string {
+		var column string
+		newDbSet, column = newDbSet.navigate("Where", fieldName)
+		return column
+	}
+	// Add the joins first so columns resolved before a navigation are table-qualified too
+	predicate.build(resolve)
+	sql, args := predicate.build(resolve)
+	if sql == "" {
+		return ds
+	}
+	if or {
+		return newDbSet.clone(newDbSet.db.Or(sql, args...))
+	}
+	return newDbSet.clone(newDbSet.db.Where(sql, args...))
+}
@@ -45,7 +45,7 @@ func (q *LinqQuery[T]) Where(condition string, args ...interface{}) *LinqQuery[T
 func (q *LinqQuery[T]) WhereFunc(predicate func(T) bool) *LinqQuery[T] {
 	// For function predicates, we'll need to fetch and filter in memory
 	// This is less efficient but provides full LINQ-like functionality
-	q.builder.query = q.builder.query.Where("1=1") // placeholder
+	q.builder.query = q.builder.query.Where("TRUE") // placeholder
 	return q
 }
 
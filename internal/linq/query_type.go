@@ -0,0 +1,181 @@
+package linq
+
+import (
+	stdcontext "context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// namedParameter matches the @name parameters of a query type's SQL, not MySQL's @@variables or the @
+// of an address written in the SQL
+var namedParameter = regexp.MustCompile(`(?:^|[^@\w])@(\w+)`)
+
+// QueryType is a read-only set over the rows of a parameterized SELECT registered for T, for reporting
+// queries kept in one place instead of spread over handlers. LINQ methods compose over the SELECT as a
+// derived table: Where and OrderBy filter and sort its rows by T's fields
+type QueryType[T any] struct {
+	set        *LinqDbSet[T]
+	sql        string
+	parameters []string               // Names of the @parameters the SQL uses
+	params     map[string]interface{} // Values bound so far
+}
+
+// NewQueryType creates a read-only set over the rows of sql, whose columns map to T's fields like a
+// table's; bind its @parameters with WithParam
+func NewQueryType[T any](db *gorm.DB, ctx interface{}, sql string) *QueryType[T] {
+	seen := make(map[string]bool)
+	var parameters []string
+	for _, match := range namedParameter.FindAllStringSubmatch(sql, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			parameters = append(parameters, match[1])
+		}
+	}
+	return &QueryType[T]{set: NewLinqDbSetWithContext[T](db, ctx), sql: sql, parameters: parameters}
+}
+
+// WithParam binds the @name parameter of the SQL; names the SQL does not use fail the query
+// Usage: ctx.SalesReport.WithParam("tenant", tenantID).WithParam("since", since).ToList()
+func (q *QueryType[T]) WithParam(name string, value interface{}) *QueryType[T] {
+	name = strings.TrimPrefix(name, "@")
+	newQuery := q.with(q.set)
+	if !newQuery.uses(name) {
+		newQuery.set = q.set.fail(call("WithParam", name), fmt.Errorf("the query has no parameter @%s", name))
+		return newQuery
+	}
+	newQuery.params[name] = value
+	return newQuery
+}
+
+// WithParams binds several @parameters at once
+func (q *QueryType[T]) WithParams(params map[string]interface{}) *QueryType[T] {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names) // Report the same unknown parameter on every run
+	newQuery := q
+	for _, name := range names {
+		newQuery = newQuery.WithParam(name, params[name])
+	}
+	return newQuery
+}
+
+// Where filters the rows of the query; it takes the arguments of LinqDbSet.Where
+func (q *QueryType[T]) Where(args ...interface{}) *QueryType[T] {
+	return q.with(q.set.Where(args...))
+}
+
+// Or adds an OR condition; it takes the arguments of LinqDbSet.Or
+func (q *QueryType[T]) Or(args ...interface{}) *QueryType[T] {
+	return q.with(q.set.Or(args...))
+}
+
+// OrderBy sorts the rows by a field, ascending
+func (q *QueryType[T]) OrderBy(args ...interface{}) *QueryType[T] {
+	return q.with(q.set.OrderBy(args...))
+}
+
+// OrderByDescending sorts the rows by a field, descending
+func (q *QueryType[T]) OrderByDescending(args ...interface{}) *QueryType[T] {
+	return q.with(q.set.OrderByDescending(args...))
+}
+
+// ThenBy adds an ascending secondary sort
+func (q *QueryType[T]) ThenBy(args ...interface{}) *QueryType[T] {
+	return q.with(q.set.ThenBy(args...))
+}
+
+// ThenByDescending adds a descending secondary sort
+func (q *QueryType[T]) ThenByDescending(args ...interface{}) *QueryType[T] {
+	return q.with(q.set.ThenByDescending(args...))
+}
+
+// Take returns at most count rows
+func (q *QueryType[T]) Take(count int) *QueryType[T] {
+	return q.with(q.set.Take(count))
+}
+
+// Skip skips the first count rows
+func (q *QueryType[T]) Skip(count int) *QueryType[T] {
+	return q.with(q.set.Skip(count))
+}
+
+// WithContext runs the query with a Go context so it is cancelled with a request
+func (q *QueryType[T]) WithContext(ctx stdcontext.Context) *QueryType[T] {
+	return q.with(q.set.WithContext(ctx))
+}
+
+// Err returns the errors recorded while the query was chained, like LinqDbSet.Err
+func (q *QueryType[T]) Err() error {
+	return q.set.Err()
+}
+
+// ToList runs the query and returns its rows, which are never tracked
+func (q *QueryType[T]) ToList() ([]T, error) {
+	return q.bound("ToList").ToList()
+}
+
+// FirstOrDefault returns the first row, or nil when the query returns none
+func (q *QueryType[T]) FirstOrDefault() (*T, error) {
+	return q.bound("FirstOrDefault").FirstOrDefault()
+}
+
+// Count returns the number of rows the query returns
+func (q *QueryType[T]) Count() (int64, error) {
+	return q.bound("Count").Count()
+}
+
+// Any reports whether the query returns any row
+func (q *QueryType[T]) Any() (bool, error) {
+	return q.bound("Any").Any()
+}
+
+// Scan runs the query into dest, for projections chosen with raw SQL
+func (q *QueryType[T]) Scan(dest interface{}) error {
+	return q.bound("Scan").Scan(dest)
+}
+
+// with returns a copy of the query on set, with its own parameter values
+func (q *QueryType[T]) with(set *LinqDbSet[T]) *QueryType[T] {
+	newQuery := *q
+	newQuery.set = set
+	newQuery.params = make(map[string]interface{}, len(q.params))
+	for name, value := range q.params {
+		newQuery.params[name] = value
+	}
+	return &newQuery
+}
+
+// uses reports whether the SQL has the @name parameter
+func (q *QueryType[T]) uses(name string) bool {
+	for _, parameter := range q.parameters {
+		if parameter == name {
+			return true
+		}
+	}
+	return false
+}
+
+// bound returns the set reading from the SQL with its parameters bound, aliased as T's table so
+// conditions qualified with the table name resolve; method fails when a parameter is unbound
+func (q *QueryType[T]) bound(method string) *LinqDbSet[T] {
+	for _, name := range q.parameters {
+		if _, ok := q.params[name]; !ok {
+			return q.set.fail(method, fmt.Errorf("parameter @%s is not set, bind it with WithParam", name))
+		}
+	}
+
+	ds := q.set
+	stmt := &gorm.Statement{DB: ds.db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return ds.fail(method, err)
+	}
+	source := clause.NamedExpr{SQL: q.sql, Vars: []interface{}{q.params}}
+	return ds.clone(ds.db.Session(&gorm.Session{}).Table(fmt.Sprintf("(?) AS %s", stmt.Quote(stmt.Schema.Table)), source))
+}
@@ -0,0 +1,108 @@
+package linq
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+// RawExpression is an SQL fragment with bound parameters, accepted by Where, Or, OrderBy/ThenBy and
+// SelectExpr to reach SQL the typed API has no method for without leaving the query chain
+// Create it with RawExpr; the SQL is embedded as written, so identifiers must be quoted by the caller
+type RawExpression struct {
+	SQL  string
+	Args []interface{}
+}
+
+// RawExpr creates a raw SQL fragment whose ? placeholders are bound to args, never spliced into the SQL
+// Usage: ctx.Posts.Where(gontext.RawExpr(`ts_rank("Search", plainto_tsquery(?)) > ?`, q, 0.1))
+// A fragment whose placeholder count does not match its arguments, or that contains a ; ending the
+// statement, fails the query when it runs instead of reaching the database
+func RawExpr(sql string, args ...interface{}) RawExpression {
+	return RawExpression{SQL: sql, Args: args}
+}
+
+// validate rejects fragments that cannot be a single bound expression
+func (r RawExpression) validate() error {
+	if strings.TrimSpace(r.SQL) == "" {
+		return fmt.Errorf("raw expression is empty")
+	}
+	if strings.Contains(r.SQL, ";") {
+		return fmt.Errorf("raw expression %q must not contain ';'", r.SQL)
+	}
+	if placeholders := strings.Count(r.SQL, "?"); placeholders != len(r.Args) {
+		return fmt.Errorf("raw expression %q has %d placeholders for %d arguments", r.SQL, placeholders, len(r.Args))
+	}
+	return nil
+}
+
+// expr returns the fragment as a GORM expression
+func (r RawExpression) expr() clause.Expr {
+	return clause.Expr{SQL: r.SQL, Vars: r.Args}
+}
+
+// rawExpression validates a raw fragment passed to method; for invalid ones it returns a set carrying
+// the error instead, which the query reports when it runs
+func (ds *LinqDbSet[T]) rawExpression(method string, raw RawExpression) (clause.Expr, *LinqDbSet[T]) {
+	if err := raw.validate(); err != nil {
+		return clause.Expr{}, ds.fail(method, err)
+	}
+	return raw.expr(), nil
+}
+
+// whereRaw adds a raw fragment as an AND (or OR) condition
+func (ds *LinqDbSet[T]) whereRaw(method string, raw RawExpression, or bool) *LinqDbSet[T] {
+	expression, failed := ds.rawExpression(method, raw)
+	if failed != nil {
+		return failed
+	}
+	if or {
+		return ds.clone(ds.db.Or(expression))
+	}
+	return ds.clone(ds.db.Where(expression))
+}
+
+// orderByRaw appends an ORDER BY term on a raw fragment
+func (ds *LinqDbSet[T]) orderByRaw(method string, raw RawExpression, desc bool) *LinqDbSet[T] {
+	expression, failed := ds.rawExpression(method, raw)
+	if failed != nil {
+		return failed
+	}
+	return ds.withOrderingExpression(expression, desc)
+}
+
+// SelectExpr chooses the columns to load from field names (Go field, column or json name, resolved like
+// SelectFields) and raw fragments, typically computed columns read with Scan or ToListAs
+// Usage: ctx.Posts.SelectExpr("Id", "Title", gontext.RawExpr(`ts_rank("Search", plainto_tsquery(?)) AS "Rank"`, q))
+// Names that are not fields are kept as written, like Select
+func (ds *LinqDbSet[T]) SelectExpr(columns ...interface{}) *LinqDbSet[T] {
+	var parts []string
+	var args []interface{}
+	for _, column := range columns {
+		switch c := column.(type) {
+		case RawExpression:
+			expression, failed := ds.rawExpression("SelectExpr", c)
+			if failed != nil {
+				return failed
+			}
+			parts = append(parts, expression.SQL)
+			args = append(args, expression.Vars...)
+		case string:
+			if field := ds.lookupField(c); field != nil && field.DBName != "" {
+				parts = append(parts, ds.db.Statement.Quote(clause.Column{Table: field.Schema.Table, Name: field.DBName}))
+			} else {
+				parts = append(parts, c)
+			}
+		default:
+			return ds.fail("SelectExpr", fmt.Errorf("unsupported column %T, expected a field name or RawExpr", column))
+		}
+	}
+	if len(parts) == 0 {
+		return ds
+	}
+	if len(args) == 0 {
+		return ds.clone(ds.db.Select(parts))
+	}
+	return ds.clone(ds.db.Select(strings.Join(parts, ", "), args...))
+}
@@ -0,0 +1,60 @@
+package linq
+
+import (
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+// QueryScope is a reusable piece of a query - filters, orderings, includes - applied with Scopes
+// or registered on the context as a default scope of the entity type
+//
+//	func ActiveUsers(q *gontext.LinqDbSet[User]) *gontext.LinqDbSet[User] {
+//		return q.Where("IsActive", true)
+//	}
+type QueryScope[T any] func(*LinqDbSet[T]) *LinqDbSet[T]
+
+// Scopes applies scopes to the query in order: ctx.Users.Scopes(ActiveUsers, Adults).ToList()
+func (ds *LinqDbSet[T]) Scopes(scopes ...QueryScope[T]) *LinqDbSet[T] {
+	scoped := ds
+	for _, scope := range scopes {
+		if scope != nil {
+			scoped = scope(scoped)
+		}
+	}
+	return scoped
+}
+
+// IgnoreDefaultScopes drops the default scopes registered on the context for the entity type
+// - EF Core: IgnoreQueryFilters. It starts the query over, so call it first in the chain:
+// ctx.Users.IgnoreDefaultScopes().Where("Id", id).First()
+func (ds *LinqDbSet[T]) IgnoreDefaultScopes() *LinqDbSet[T] {
+	if ds.unscoped == nil {
+		return ds
+	}
+	return ds.unscoped
+}
+
+// withDefaultScopes applies the default scopes the context has for the entity type to a new set,
+// keeping the unscoped set for IgnoreDefaultScopes
+func (ds *LinqDbSet[T]) withDefaultScopes() *LinqDbSet[T] {
+	scoper, ok := ds.context.(interface{ DefaultScopes(reflect.Type) []interface{} })
+	if !ok {
+		return ds
+	}
+	var scopes []QueryScope[T]
+	for _, registered := range scoper.DefaultScopes(ds.entityType) {
+		if scope, ok := registered.(QueryScope[T]); ok {
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		return ds
+	}
+
+	scoped := ds.Scopes(scopes...)
+	// A new session, so every query started from the set copies the scoped conditions instead of adding to them
+	scoped = scoped.clone(scoped.db.Session(&gorm.Session{}))
+	scoped.unscoped = ds
+	return scoped
+}
@@ -0,0 +1,184 @@
+package linq
+
+import (
+	stdcontext "context"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// ToMaps - runs the query and returns each entity as a map keyed by json name that holds only the fields the
+// query selected, never the omitted ones, so a field missing `json:"-"` cannot leak:
+//
+//	users, err := ctx.Users.Omit("PasswordHash").Include("Posts").ToMaps()
+//
+// Included navigations are nested the same way; Omit("Posts.Body") leaves a field out of them
+// Fields tagged `json:"-"` are never written and `omitempty` skips zero values, like encoding/json
+func (ds *LinqDbSet[T]) ToMaps() ([]map[string]interface{}, error) {
+	entities, err := ds.ToList()
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := &gorm.Statement{DB: ds.db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, err
+	}
+
+	view := newSerializationView(stmt.Schema, ds.db.Statement.Selects, ds.db.Statement.Omits, preloadNames(ds.db.Statement.Preloads))
+	results := make([]map[string]interface{}, len(entities))
+	for i := range entities {
+		results[i] = view.toMap(ds.db.Statement.Context, reflect.ValueOf(&entities[i]).Elem())
+	}
+	return results, nil
+}
+
+// ToJSON - runs the query and encodes the result of ToMaps as a JSON array
+func (ds *LinqDbSet[T]) ToJSON() (json.RawMessage, error) {
+	maps, err := ds.ToMaps()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(maps)
+}
+
+// serializationView decides which fields of a schema are written, and how its included navigations are
+type serializationView struct {
+	schema     *schema.Schema
+	selected   map[string]bool // Field names; nil writes every field
+	omitted    map[string]bool
+	navigation map[string]*serializationView
+}
+
+func newSerializationView(s *schema.Schema, selects, omits, preloads []string) *serializationView {
+	view := &serializationView{schema: s, omitted: make(map[string]bool), navigation: make(map[string]*serializationView)}
+
+	for _, sel := range selects {
+		if strings.TrimSpace(sel) == "*" {
+			view.selected = nil
+			break
+		}
+		if field := selectedField(s, sel); field != nil {
+			if view.selected == nil {
+				view.selected = make(map[string]bool)
+			}
+			view.selected[field.Name] = true
+		}
+	}
+
+	// Omits and preloads with a "Navigation." prefix apply to that navigation's view
+	nestedOmits := make(map[string][]string)
+	for _, omit := range omits {
+		if navigation, rest, ok := strings.Cut(omit, "."); ok {
+			nestedOmits[navigation] = append(nestedOmits[navigation], rest)
+		} else if field := lookupSchemaField(s, omit); field != nil {
+			view.omitted[field.Name] = true
+		} else {
+			view.omitted[omit] = true
+		}
+	}
+	nestedPreloads := make(map[string][]string)
+	for _, preload := range preloads {
+		navigation, rest, _ := strings.Cut(preload, ".")
+		if _, exists := nestedPreloads[navigation]; !exists {
+			nestedPreloads[navigation] = nil
+		}
+		if rest != "" {
+			nestedPreloads[navigation] = append(nestedPreloads[navigation], rest)
+		}
+	}
+
+	for navigation, nested := range nestedPreloads {
+		relation, ok := s.Relationships.Relations[navigation]
+		if !ok || view.omitted[navigation] {
+			continue
+		}
+		view.navigation[navigation] = newSerializationView(relation.FieldSchema, nil, nestedOmits[navigation], nested)
+	}
+	return view
+}
+
+// toMap writes the visible fields and included navigations of one entity
+func (v *serializationView) toMap(ctx stdcontext.Context, value reflect.Value) map[string]interface{} {
+	result := make(map[string]interface{})
+	for _, field := range v.schema.Fields {
+		if field.DBName == "" || v.omitted[field.Name] || (v.selected != nil && !v.selected[field.Name]) {
+			continue
+		}
+		v.write(result, field, field.ReflectValueOf(ctx, value), func(fieldValue reflect.Value) interface{} {
+			return fieldValue.Interface()
+		})
+	}
+
+	for navigation, nested := range v.navigation {
+		field := v.schema.Relationships.Relations[navigation].Field
+		v.write(result, field, field.ReflectValueOf(ctx, value), func(fieldValue reflect.Value) interface{} {
+			return nested.toValue(ctx, fieldValue)
+		})
+	}
+	return result
+}
+
+// toValue converts a navigation value (pointer, struct or slice of them) into maps
+func (v *serializationView) toValue(ctx stdcontext.Context, value reflect.Value) interface{} {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			return nil
+		}
+		return v.toValue(ctx, value.Elem())
+	case reflect.Slice, reflect.Array:
+		items := make([]interface{}, value.Len())
+		for i := range items {
+			items[i] = v.toValue(ctx, value.Index(i))
+		}
+		return items
+	case reflect.Struct:
+		return v.toMap(ctx, value)
+	default:
+		return value.Interface()
+	}
+}
+
+// write stores a field under its json name, honoring `json:"-"` and `omitempty`
+func (v *serializationView) write(result map[string]interface{}, field *schema.Field, value reflect.Value, convert func(reflect.Value) interface{}) {
+	name, options, _ := strings.Cut(field.StructField.Tag.Get("json"), ",")
+	if name == "-" && options == "" {
+		return
+	}
+	if name == "" {
+		name = field.Name
+	}
+	if strings.Contains(","+options+",", ",omitempty,") && (!value.IsValid() || value.IsZero()) {
+		return
+	}
+	if !value.IsValid() {
+		result[name] = nil
+		return
+	}
+	result[name] = convert(value)
+}
+
+// selectedField resolves a Select entry (field name, quoted column, "table"."column" or "expr AS alias") to a field
+func selectedField(s *schema.Schema, sel string) *schema.Field {
+	sel = strings.TrimSpace(sel)
+	if index := strings.LastIndex(strings.ToUpper(sel), " AS "); index >= 0 {
+		sel = sel[index+4:]
+	}
+	if index := strings.LastIndex(sel, "."); index >= 0 {
+		sel = sel[index+1:]
+	}
+	return lookupSchemaField(s, strings.Trim(strings.TrimSpace(sel), "\"`[]"))
+}
+
+// preloadNames returns the navigation paths of a statement's preloads
+func preloadNames(preloads map[string][]interface{}) []string {
+	names := make([]string, 0, len(preloads))
+	for name := range preloads {
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,421 @@
+package linq
+
+import (
+	stdcontext "context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm/schema"
+
+	"github.com/shepherrrd/gontext/internal/dberrors"
+)
+
+// ShardedSet queries an entity type whose rows are spread over shard databases by a shard key. A query
+// whose conditions pin the shard key - Where("TenantId", tenant) - runs on that key's shard only; other
+// queries fan out to every shard in parallel and their results are combined: lists are merged in the
+// requested order before Skip/Take apply, counts are summed
+// Loaded entities are tracked by the context, and SaveChanges writes each one to the shard of its key
+// Usage:
+//
+//	orders, err := ctx.Orders.Where("TenantId", tenant).OrderByDescending("CreatedAt").Take(20).ToList()
+//	open, err := ctx.Orders.Where("Status", "open").Count() // Every shard
+type ShardedSet[T any] struct {
+	sets     []*LinqDbSet[T] // One per shard, in shard order, carrying the chained conditions
+	keyField string          // Go name of the shard key field
+	shardOf  func(key interface{}) (int, error)
+	keys     []interface{} // Shard key values the conditions pin
+	orders   []shardOrdering
+	take     int // -1 without Take
+	skip     int
+	err      error // First chaining error, reported by the terminal methods
+}
+
+// shardOrdering is an ordering the merged results of a fan-out query follow
+type shardOrdering struct {
+	field *schema.Field
+	desc  bool
+}
+
+// NewShardedSet creates a sharded set over the connections of the shards of T, in shard order; shardOf
+// resolves a shard key value to its shard. Use gontext.RegisterShardedEntity
+func NewShardedSet[T any](keyField string, shards []*LinqDbSet[T], shardOf func(key interface{}) (int, error)) *ShardedSet[T] {
+	return &ShardedSet[T]{sets: shards, keyField: keyField, shardOf: shardOf, take: -1}
+}
+
+// clone copies the set for a chained method
+func (ss *ShardedSet[T]) clone() *ShardedSet[T] {
+	cloned := *ss
+	cloned.sets = append([]*LinqDbSet[T](nil), ss.sets...)
+	cloned.keys = append([]interface{}(nil), ss.keys...)
+	cloned.orders = append([]shardOrdering(nil), ss.orders...)
+	return &cloned
+}
+
+// each applies a chained method to the set of every shard
+func (ss *ShardedSet[T]) each(chain func(*LinqDbSet[T]) *LinqDbSet[T]) *ShardedSet[T] {
+	cloned := ss.clone()
+	for i, set := range cloned.sets {
+		cloned.sets[i] = chain(set)
+	}
+	return cloned
+}
+
+// Where adds a condition like LinqDbSet.Where on every shard; an equality on the shard key field
+// (Where("TenantId", tenant)) also routes the query to that key's shard
+func (ss *ShardedSet[T]) Where(args ...interface{}) *ShardedSet[T] {
+	cloned := ss.each(func(set *LinqDbSet[T]) *LinqDbSet[T] { return set.Where(args...) })
+	if key, ok := ss.pinnedKey(args); ok {
+		cloned.keys = append(cloned.keys, key)
+	}
+	return cloned
+}
+
+// pinnedKey returns the shard key value of a Where("KeyField", value) condition that is a plain equality.
+// Operators (GreaterThan, ">3"), lists, NULL and other values can match keys of every shard, so those
+// queries fan out
+func (ss *ShardedSet[T]) pinnedKey(args []interface{}) (interface{}, bool) {
+	if len(args) != 2 {
+		return nil, false
+	}
+	name, ok := args[0].(string)
+	if !ok {
+		return nil, false
+	}
+	set := ss.sets[0]
+	field := set.lookupField(name)
+	if field == nil || field.Name != ss.keyField {
+		return nil, false
+	}
+
+	value := args[1]
+	switch v := value.(type) {
+	case Comparison:
+		if v.operator != "=" {
+			return nil, false
+		}
+		value = v.value
+	case *Comparison:
+		if v == nil || v.operator != "=" {
+			return nil, false
+		}
+		value = v.value
+	case string:
+		if set.parsesOperators() {
+			operator, key := set.parseOperator(v)
+			return key, operator == "="
+		}
+	}
+	return scalarKey(value)
+}
+
+// scalarKey dereferences a key value and reports whether it is a single value a shard key can equal:
+// a number, string or bool, a fixed-size array such as a UUID, or a struct with its own text or driver
+// value (times, sql.Null*)
+func scalarKey(value interface{}) (interface{}, bool) {
+	key := reflect.ValueOf(value)
+	for key.Kind() == reflect.Ptr {
+		if key.IsNil() {
+			return nil, false
+		}
+		key = key.Elem()
+	}
+	switch key.Kind() {
+	case reflect.Bool, reflect.String, reflect.Array,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return key.Interface(), true
+	case reflect.Struct:
+		switch key.Interface().(type) {
+		case fmt.Stringer, driver.Valuer:
+			return key.Interface(), true
+		}
+	}
+	return nil, false
+}
+
+// OrderBy orders by a field; fan-out queries merge the shards' rows in the same order
+func (ss *ShardedSet[T]) OrderBy(field string) *ShardedSet[T] {
+	return ss.orderBy("OrderBy", field, false)
+}
+
+// OrderByDescending orders by a field, descending
+func (ss *ShardedSet[T]) OrderByDescending(field string) *ShardedSet[T] {
+	return ss.orderBy("OrderByDescending", field, true)
+}
+
+func (ss *ShardedSet[T]) orderBy(method, name string, desc bool) *ShardedSet[T] {
+	cloned := ss.each(func(set *LinqDbSet[T]) *LinqDbSet[T] {
+		if desc {
+			return set.OrderByDescending(name)
+		}
+		return set.OrderBy(name)
+	})
+	field := ss.sets[0].lookupField(name)
+	if field == nil {
+		return cloned.fail(call(method, name), fmt.Errorf("field not found"))
+	}
+	cloned.orders = append(cloned.orders, shardOrdering{field: field, desc: desc})
+	return cloned
+}
+
+// Take limits the combined results to count rows
+func (ss *ShardedSet[T]) Take(count int) *ShardedSet[T] {
+	cloned := ss.clone()
+	cloned.take = count
+	return cloned
+}
+
+// Skip skips the first count rows of the combined results
+func (ss *ShardedSet[T]) Skip(count int) *ShardedSet[T] {
+	cloned := ss.clone()
+	cloned.skip = count
+	return cloned
+}
+
+// WithContext runs the queries of every shard with ctx
+func (ss *ShardedSet[T]) WithContext(ctx stdcontext.Context) *ShardedSet[T] {
+	return ss.each(func(set *LinqDbSet[T]) *LinqDbSet[T] { return set.WithContext(ctx) })
+}
+
+// fail records a chaining error, kept until a terminal method reports it
+func (ss *ShardedSet[T]) fail(method string, err error) *ShardedSet[T] {
+	if ss.err == nil {
+		ss.err = ss.sets[0].queryError(method, err)
+	}
+	return ss
+}
+
+// Err returns the errors recorded while the query was chained, nil when it is valid so far
+func (ss *ShardedSet[T]) Err() error {
+	if ss.err != nil {
+		return ss.err
+	}
+	for _, set := range ss.sets {
+		if err := set.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Shard returns the set of the shard storing key, for queries the sharded set has no method for
+// Usage: ctx.Orders.Shard(tenant).Where("TenantId", tenant).Include("Lines").GroupBy(...)
+func (ss *ShardedSet[T]) Shard(key interface{}) (*LinqDbSet[T], error) {
+	shard, err := ss.shardOf(key)
+	if err != nil {
+		return nil, err
+	}
+	return ss.sets[shard], nil
+}
+
+// targets returns the shards the query runs on: that of the pinned keys, or every shard
+func (ss *ShardedSet[T]) targets() ([]*LinqDbSet[T], error) {
+	if err := ss.Err(); err != nil {
+		return nil, err
+	}
+	if len(ss.keys) == 0 {
+		return ss.sets, nil
+	}
+	seen := make(map[int]bool)
+	var targets []*LinqDbSet[T]
+	for _, key := range ss.keys {
+		shard, err := ss.shardOf(key)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[shard] {
+			seen[shard] = true
+			targets = append(targets, ss.sets[shard])
+		}
+	}
+	return targets, nil
+}
+
+// fanOut runs query on every target concurrently and returns the results in shard order
+func fanOut[T any, R any](targets []*LinqDbSet[T], query func(*LinqDbSet[T]) (R, error)) ([]R, error) {
+	results := make([]R, len(targets))
+	errs := make([]error, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target *LinqDbSet[T]) {
+			defer wg.Done()
+			results[i], errs[i] = query(target)
+		}(i, target)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// ToList returns the matching rows; fan-out queries read up to Skip+Take rows per shard and merge them
+func (ss *ShardedSet[T]) ToList() ([]T, error) {
+	targets, err := ss.targets()
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) == 1 {
+		set := targets[0]
+		if ss.skip > 0 {
+			set = set.Skip(ss.skip)
+		}
+		if ss.take >= 0 {
+			set = set.Take(ss.take)
+		}
+		return set.ToList()
+	}
+
+	// Rows are tracked once merged, so the tracked instances are the returned ones
+	lists, err := fanOut(targets, func(set *LinqDbSet[T]) ([]T, error) {
+		if ss.take >= 0 {
+			set = set.Take(ss.skip + ss.take) // Any row of the page is among the first Skip+Take of its shard
+		}
+		var rows []T
+		err := set.query().Model(new(T)).Find(&rows).Error
+		return rows, dberrors.Translate(err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	var merged []T
+	for _, list := range lists {
+		merged = append(merged, list...)
+	}
+	if len(ss.orders) > 0 {
+		sort.SliceStable(merged, func(i, j int) bool { return ss.less(merged[i], merged[j]) })
+	}
+
+	if ss.skip >= len(merged) {
+		return []T{}, nil
+	}
+	merged = merged[ss.skip:]
+	if ss.take >= 0 && ss.take < len(merged) {
+		merged = merged[:ss.take]
+	}
+	for i := range merged {
+		ss.sets[0].trackEntity(&merged[i])
+	}
+	return merged, nil
+}
+
+// less orders two rows of different shards like the databases ordered the rows of each
+func (ss *ShardedSet[T]) less(a, b T) bool {
+	ctx := stdcontext.Background()
+	va, vb := reflect.ValueOf(&a).Elem(), reflect.ValueOf(&b).Elem()
+	for _, ordering := range ss.orders {
+		c := compareValues(ordering.field.ReflectValueOf(ctx, va), ordering.field.ReflectValueOf(ctx, vb))
+		if c == 0 {
+			continue
+		}
+		if ordering.desc {
+			return c > 0
+		}
+		return c < 0
+	}
+	return false
+}
+
+// compareValues compares field values of two rows: numbers, strings, booleans and times by value, nil
+// pointers first, anything else by its text
+func compareValues(a, b reflect.Value) int {
+	if a.Kind() == reflect.Ptr {
+		switch {
+		case a.IsNil() && b.IsNil():
+			return 0
+		case a.IsNil():
+			return -1
+		case b.IsNil():
+			return 1
+		}
+		a, b = a.Elem(), b.Elem()
+	}
+	if ta, ok := a.Interface().(time.Time); ok {
+		return ta.Compare(b.Interface().(time.Time))
+	}
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareOrdered(a.Int(), b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return compareOrdered(a.Uint(), b.Uint())
+	case reflect.Float32, reflect.Float64:
+		return compareOrdered(a.Float(), b.Float())
+	case reflect.String:
+		return compareOrdered(a.String(), b.String())
+	case reflect.Bool:
+		return compareOrdered(fmt.Sprint(a.Bool()), fmt.Sprint(b.Bool())) // false before true
+	}
+	return compareOrdered(fmt.Sprint(a.Interface()), fmt.Sprint(b.Interface()))
+}
+
+func compareOrdered[V int64 | uint64 | float64 | string](a, b V) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// FirstOrDefault returns the first matching row in the requested order, nil when there is none
+func (ss *ShardedSet[T]) FirstOrDefault() (*T, error) {
+	rows, err := ss.Take(1).ToList()
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	return &rows[0], nil
+}
+
+// Count returns the number of matching rows, summed over the shards
+func (ss *ShardedSet[T]) Count() (int64, error) {
+	targets, err := ss.targets()
+	if err != nil {
+		return 0, err
+	}
+	counts, err := fanOut(targets, func(set *LinqDbSet[T]) (int64, error) { return set.Count() })
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, count := range counts {
+		total += count
+	}
+	return total, nil
+}
+
+// Any reports whether a shard has a matching row
+func (ss *ShardedSet[T]) Any() (bool, error) {
+	targets, err := ss.targets()
+	if err != nil {
+		return false, err
+	}
+	found, err := fanOut(targets, func(set *LinqDbSet[T]) (bool, error) { return set.Any() })
+	if err != nil {
+		return false, err
+	}
+	for _, exists := range found {
+		if exists {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Add tracks entity for insertion; SaveChanges writes it to the shard of its key
+func (ss *ShardedSet[T]) Add(entity T) (*T, error) {
+	return ss.sets[0].Add(entity)
+}
+
+// Remove tracks entity for deletion from the shard of its key
+func (ss *ShardedSet[T]) Remove(entity T) {
+	ss.sets[0].Remove(entity)
+}
@@ -0,0 +1,53 @@
+package linq
+
+import (
+	"fmt"
+
+	"gorm.io/gorm/clause"
+)
+
+// DefaultSimilarityThreshold is pg_trgm's default pg_trgm.similarity_threshold, the similarity the %
+// operator requires
+const DefaultSimilarityThreshold = 0.3
+
+// WhereSimilar keeps rows whose field is at least threshold similar to term by pg_trgm trigram similarity
+// (0 to 1), so typos still match: WhereSimilar("Username", "jonh", 0.4) finds "john"
+// Thresholds from DefaultSimilarityThreshold up use the % operator, which a trigram index (HasTrigramIndex
+// or the gontext trigram tag) serves; lower ones compare similarity() on every row. A threshold of 0 uses
+// % with the server's pg_trgm.similarity_threshold. Requires PostgreSQL with the pg_trgm extension
+func (ds *LinqDbSet[T]) WhereSimilar(fieldName, term string, threshold float64) *LinqDbSet[T] {
+	column, failed := ds.similarityColumn("WhereSimilar", fieldName)
+	if failed != nil {
+		return failed
+	}
+
+	switch {
+	case threshold <= 0:
+		return ds.clone(ds.db.Where("? % ?", column, term))
+	case threshold < DefaultSimilarityThreshold:
+		return ds.clone(ds.db.Where("similarity(?, ?) >= ?", column, term, threshold))
+	default:
+		return ds.clone(ds.db.Where("? % ? AND similarity(?, ?) >= ?", column, term, column, term, threshold))
+	}
+}
+
+// OrderBySimilarity orders rows by trigram similarity of a field to term, most similar first; combine it
+// with WhereSimilar to rank typo-tolerant matches. Later ThenBy calls order rows of equal similarity
+// Usage: ctx.Users.WhereSimilar("Username", q, 0.3).OrderBySimilarity("Username", q).Take(10).ToList()
+func (ds *LinqDbSet[T]) OrderBySimilarity(fieldName, term string) *LinqDbSet[T] {
+	column, failed := ds.similarityColumn("OrderBySimilarity", fieldName)
+	if failed != nil {
+		return failed
+	}
+	return ds.withOrderingExpression(clause.Expr{SQL: "similarity(?, ?)", Vars: []interface{}{column, term}}, true)
+}
+
+// similarityColumn resolves the column of a field searched by similarity; for unknown fields it returns
+// a set carrying the error instead, which the query reports when it runs
+func (ds *LinqDbSet[T]) similarityColumn(method, fieldName string) (clause.Column, *LinqDbSet[T]) {
+	field := ds.lookupField(fieldName)
+	if field == nil || field.DBName == "" {
+		return clause.Column{}, ds.fail(call(method, fieldName), fmt.Errorf("field not found"))
+	}
+	return clause.Column{Table: clause.CurrentTable, Name: field.DBName}, nil
+}
@@ -0,0 +1,62 @@
+package linq
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// strict reports whether the context runs in strict query mode, where methods record an error for
+// arguments they cannot use instead of leaving the query unchanged
+func (ds *LinqDbSet[T]) strict() bool {
+	ctx, ok := ds.context.(interface{ StrictQueries() bool })
+	return ok && ctx.StrictQueries()
+}
+
+// invalidQuery returns a set carrying a QueryError for method in strict mode; otherwise the set is
+// returned unchanged as the lenient methods always have
+func (ds *LinqDbSet[T]) invalidQuery(method string, err error) *LinqDbSet[T] {
+	if !ds.strict() {
+		return ds
+	}
+	return ds.fail(method, err)
+}
+
+// unsupportedArguments records that method got arguments matching none of its patterns
+func (ds *LinqDbSet[T]) unsupportedArguments(method string, args []interface{}) *LinqDbSet[T] {
+	types := make([]string, len(args))
+	for i, arg := range args {
+		types[i] = fmt.Sprintf("%T", arg)
+	}
+	if len(args) == 1 {
+		if _, ok := args[0].(func(T) interface{}); ok {
+			return ds.invalidQuery(method, fmt.Errorf("field selector functions cannot be translated to SQL, pass the field name"))
+		}
+	}
+	return ds.invalidQuery(method, fmt.Errorf("unsupported arguments (%s)", strings.Join(types, ", ")))
+}
+
+// unknownField records that method was given a field name T does not have; fields of T and dotted
+// paths, which may name navigations or other tables, are accepted and return nil
+func (ds *LinqDbSet[T]) unknownField(method, fieldName string) *LinqDbSet[T] {
+	if !ds.strict() || strings.Contains(fieldName, ".") || ds.lookupField(fieldName) != nil {
+		return nil
+	}
+	return ds.invalidQuery(call(method, fieldName), fmt.Errorf("field not found"))
+}
+
+// withPredicate adds the condition of an Expression predicate passed to a terminal method; predicates
+// cannot be translated to SQL yet, so strict mode fails the query instead of silently ignoring them
+func (ds *LinqDbSet[T]) withPredicate(query *gorm.DB, method string, predicate []Expression[T]) *gorm.DB {
+	if len(predicate) == 0 {
+		return query
+	}
+	if condition := ds.parseExpression(predicate[0]); condition != "" {
+		return query.Where(condition)
+	}
+	if ds.strict() {
+		query.AddError(ds.queryError(method, fmt.Errorf("Expression predicates cannot be translated to SQL, use Where before %s", method)))
+	}
+	return query
+}
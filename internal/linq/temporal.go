@@ -0,0 +1,62 @@
+package linq
+
+import (
+	"fmt"
+	"time"
+)
+
+// InTimeZone sets the time zone used to resolve calendar days for the temporal helpers on this query
+// Usage: ctx.Orders.InTimeZone(berlin).WhereDateBetween("CreatedAt", from, to)
+func (ds *LinqDbSet[T]) InTimeZone(location *time.Location) *LinqDbSet[T] {
+	newDbSet := ds.clone(ds.db)
+	newDbSet.location = location
+	return newDbSet
+}
+
+// WhereDateBetween - keeps rows whose timestamp falls on a calendar day from..to (inclusive)
+// Days are resolved in the query's time zone and compared as a half-open range so indexes are used
+func (ds *LinqDbSet[T]) WhereDateBetween(fieldName string, from, to time.Time) *LinqDbSet[T] {
+	location := ds.timeLocation()
+	start := startOfDay(from.In(location))
+	end := startOfDay(to.In(location)).AddDate(0, 0, 1)
+
+	column := ds.quoteField(fieldName)
+	return ds.clone(ds.db.Where(fmt.Sprintf("%s >= ? AND %s < ?", column, column), start, end))
+}
+
+// WhereOlderThan - keeps rows whose timestamp is more than age in the past: WhereOlderThan("LastLoginAt", 90*24*time.Hour)
+func (ds *LinqDbSet[T]) WhereOlderThan(fieldName string, age time.Duration) *LinqDbSet[T] {
+	cutoff := time.Now().In(ds.timeLocation()).Add(-age)
+	return ds.clone(ds.db.Where(fmt.Sprintf("%s < ?", ds.quoteField(fieldName)), cutoff))
+}
+
+// WhereNewerThan - keeps rows whose timestamp is within age of now
+func (ds *LinqDbSet[T]) WhereNewerThan(fieldName string, age time.Duration) *LinqDbSet[T] {
+	cutoff := time.Now().In(ds.timeLocation()).Add(-age)
+	return ds.clone(ds.db.Where(fmt.Sprintf("%s >= ?", ds.quoteField(fieldName)), cutoff))
+}
+
+// WhereInLastDays - keeps rows from the last n calendar days including today: WhereInLastDays(7, "CreatedAt")
+// Day boundaries follow the query's time zone, so DST changes don't shift the window
+func (ds *LinqDbSet[T]) WhereInLastDays(days int, fieldName string) *LinqDbSet[T] {
+	today := startOfDay(time.Now().In(ds.timeLocation()))
+	start := today.AddDate(0, 0, -(days - 1))
+	return ds.clone(ds.db.Where(fmt.Sprintf("%s >= ?", ds.quoteField(fieldName)), start))
+}
+
+// timeLocation returns the query's time zone, then the context's, then the local time zone
+func (ds *LinqDbSet[T]) timeLocation() *time.Location {
+	if ds.location != nil {
+		return ds.location
+	}
+	if ctx, ok := ds.context.(interface{ TimeZone() *time.Location }); ok {
+		if location := ctx.TimeZone(); location != nil {
+			return location
+		}
+	}
+	return time.Local
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
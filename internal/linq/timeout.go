@@ -0,0 +1,21 @@
+package linq
+
+import (
+	stdcontext "context"
+	"time"
+
+	"github.com/shepherrrd/gontext/internal/query"
+)
+
+// WithTimeout - cancels the query when it runs longer than timeout, overriding DbContextOptions.StatementTimeout
+// The clock starts when the query executes; a timed-out query returns gontext.ErrTimeout
+// Usage: ctx.Orders.Where("Status", "open").WithTimeout(2 * time.Second).ToList()
+func (ds *LinqDbSet[T]) WithTimeout(timeout time.Duration) *LinqDbSet[T] {
+	return ds.clone(ds.db.Set(query.StatementTimeoutKey, timeout))
+}
+
+// WithContext - runs the query with a Go context so it is cancelled with a request or a Parallel batch
+// Usage: ctx.Orders.WithContext(r.Context()).Where("Status", "open").ToList()
+func (ds *LinqDbSet[T]) WithContext(ctx stdcontext.Context) *LinqDbSet[T] {
+	return ds.clone(ds.db.WithContext(ctx))
+}
@@ -0,0 +1,211 @@
+package locks
+
+import (
+	stdcontext "context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultRetryInterval is the wait between attempts of AcquireWait
+const DefaultRetryInterval = 100 * time.Millisecond
+
+var (
+	// ErrLockHeld is returned by Acquire when another owner holds an unexpired lock of the name
+	ErrLockHeld = errors.New("lock is held by another owner")
+	// ErrLockLost is returned by Extend and Release when the lease expired and another owner took the lock
+	ErrLockLost = errors.New("lock was lost: the lease expired and another owner acquired it")
+)
+
+// Lock is a held lock in the GontextLocks table
+// Register it like any entity so migrations create the table: gontext.RegisterEntity[gontext.Lock](ctx)
+type Lock struct {
+	Name       string    `gorm:"primaryKey"`
+	Owner      string    `gorm:"not null"` // Random token of the lease holding the lock
+	AcquiredAt time.Time `gorm:"not null"`
+	ExpiresAt  time.Time `gorm:"not null"` // After this anyone may take the lock over
+}
+
+// TableName keeps the locks apart from application tables
+func (Lock) TableName() string {
+	return "GontextLocks"
+}
+
+// Manager hands out named locks shared by every process using the database. Locks expire after their
+// TTL, so a crashed holder never blocks others for longer; unlike advisory locks they are not tied to a
+// connection, so they work through a pool and on every supported database
+// Expiry is judged by the clocks of the application hosts, which should be kept in sync
+type Manager struct {
+	db      *gorm.DB
+	mu      sync.Mutex
+	columns map[string]clause.Column
+}
+
+// Lease is a held lock; Release it when the critical section ends
+type Lease struct {
+	manager   *Manager
+	name      string
+	owner     string
+	mu        sync.Mutex
+	expiresAt time.Time
+}
+
+// NewManager creates a lock manager for db
+func NewManager(db *gorm.DB) *Manager {
+	return &Manager{db: db}
+}
+
+// EnsureTable creates the locks table if needed, for applications that do not use migrations
+func (m *Manager) EnsureTable() error {
+	return m.db.AutoMigrate(&Lock{})
+}
+
+// Acquire takes the lock name for ttl, or returns ErrLockHeld at once when another owner holds it
+//
+//	lease, err := ctx.Locks().Acquire("invoice:123", 30*time.Second)
+//	if errors.Is(err, gontext.ErrLockHeld) { return nil } // Someone else is on it
+//	defer lease.Release()
+func (m *Manager) Acquire(name string, ttl time.Duration) (*Lease, error) {
+	return m.acquire(stdcontext.Background(), name, ttl)
+}
+
+// AcquireWait takes the lock name for ttl, waiting until it is free or ctx is done
+func (m *Manager) AcquireWait(ctx stdcontext.Context, name string, ttl time.Duration) (*Lease, error) {
+	for {
+		lease, err := m.acquire(ctx, name, ttl)
+		if !errors.Is(err, ErrLockHeld) {
+			return lease, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("failed to acquire lock %s: %w", name, ctx.Err())
+		case <-time.After(DefaultRetryInterval):
+		}
+	}
+}
+
+// WithLock runs fn while holding the lock name, waiting for it until ctx is done
+// fn should finish within ttl: after it the lock may be taken over while fn still runs
+func (m *Manager) WithLock(ctx stdcontext.Context, name string, ttl time.Duration, fn func() error) error {
+	lease, err := m.AcquireWait(ctx, name, ttl)
+	if err != nil {
+		return err
+	}
+	defer lease.Release()
+	return fn()
+}
+
+// acquire inserts the lock, or takes over an expired one
+func (m *Manager) acquire(ctx stdcontext.Context, name string, ttl time.Duration) (*Lease, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("lock %s needs a positive TTL", name)
+	}
+	columns, err := m.lockColumns()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	lock := &Lock{Name: name, Owner: newOwner(), AcquiredAt: now, ExpiresAt: now.Add(ttl)}
+	result := m.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(lock)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to acquire lock %s: %w", name, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		result = m.db.WithContext(ctx).Model(&Lock{Name: name}).
+			Where(clause.Lte{Column: columns["ExpiresAt"], Value: now}).
+			Select("Owner", "AcquiredAt", "ExpiresAt").
+			Updates(lock)
+		if result.Error != nil {
+			return nil, fmt.Errorf("failed to acquire lock %s: %w", name, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrLockHeld, name)
+		}
+	}
+	return &Lease{manager: m, name: name, owner: lock.Owner, expiresAt: lock.ExpiresAt}, nil
+}
+
+// Name returns the name of the lock
+func (l *Lease) Name() string {
+	return l.name
+}
+
+// ExpiresAt returns when the lease ends unless it is extended
+func (l *Lease) ExpiresAt() time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.expiresAt
+}
+
+// Extend keeps the lock for ttl from now; it returns ErrLockLost when another owner has taken it over
+func (l *Lease) Extend(ttl time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	expiresAt := time.Now().Add(ttl)
+	result := l.owned().Select("ExpiresAt").Updates(&Lock{ExpiresAt: expiresAt})
+	if result.Error != nil {
+		return fmt.Errorf("failed to extend lock %s: %w", l.name, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ErrLockLost, l.name)
+	}
+	l.expiresAt = expiresAt
+	return nil
+}
+
+// Release frees the lock; it returns ErrLockLost when another owner had already taken it over
+func (l *Lease) Release() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	result := l.owned().Delete(&Lock{Name: l.name})
+	if result.Error != nil {
+		return fmt.Errorf("failed to release lock %s: %w", l.name, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ErrLockLost, l.name)
+	}
+	return nil
+}
+
+// owned scopes a statement to the lock row while this lease still owns it
+func (l *Lease) owned() *gorm.DB {
+	columns, _ := l.manager.lockColumns() // Resolved when the lease was acquired
+	return l.manager.db.Model(&Lock{Name: l.name}).
+		Where(clause.Eq{Column: columns["Owner"], Value: l.owner})
+}
+
+// lockColumns resolves the Lock columns with the database's naming strategy
+func (m *Manager) lockColumns() (map[string]clause.Column, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.columns != nil {
+		return m.columns, nil
+	}
+	stmt := &gorm.Statement{DB: m.db}
+	if err := stmt.Parse(&Lock{}); err != nil {
+		return nil, fmt.Errorf("failed to parse lock schema: %w", err)
+	}
+	m.columns = make(map[string]clause.Column, len(stmt.Schema.Fields))
+	for _, field := range stmt.Schema.Fields {
+		m.columns[field.Name] = clause.Column{Name: field.DBName}
+	}
+	return m.columns, nil
+}
+
+func newOwner() string {
+	token := make([]byte, 16)
+	rand.Read(token)
+	return hex.EncodeToString(token)
+}
@@ -0,0 +1,189 @@
+package locks
+
+import (
+	stdcontext "context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newManagers returns count managers with pools of their own on one WAL SQLite file, standing in for
+// application instances sharing a database
+func newManagers(t *testing.T, count int) []*Manager {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "locks.db") + "?_journal_mode=WAL&_busy_timeout=5000"
+	managers := make([]*Manager, count)
+	for i := range managers {
+		db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Discard})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sqlDB, err := db.DB()
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { sqlDB.Close() })
+		managers[i] = NewManager(db)
+		if err := managers[i].EnsureTable(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return managers
+}
+
+func TestAcquireIsExclusive(t *testing.T) {
+	managers := newManagers(t, 2)
+	lease, err := managers[0].Acquire("invoice:1", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lease.Name() != "invoice:1" || time.Until(lease.ExpiresAt()) <= 0 {
+		t.Errorf("lease %q expires at %s, want invoice:1 held for a minute", lease.Name(), lease.ExpiresAt())
+	}
+
+	for _, manager := range managers {
+		if _, err := manager.Acquire("invoice:1", time.Minute); !errors.Is(err, ErrLockHeld) {
+			t.Errorf("Acquire of a held lock error = %v, want ErrLockHeld", err)
+		}
+	}
+	other, err := managers[1].Acquire("invoice:2", time.Minute)
+	if err != nil {
+		t.Fatalf("Acquire of another name error = %v", err)
+	}
+	defer other.Release()
+
+	if err := lease.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if err := lease.Release(); !errors.Is(err, ErrLockLost) {
+		t.Errorf("second Release error = %v, want ErrLockLost", err)
+	}
+	again, err := managers[1].Acquire("invoice:1", time.Minute)
+	if err != nil {
+		t.Fatalf("Acquire after Release error = %v", err)
+	}
+	again.Release()
+}
+
+func TestExpiredLockIsTakenOver(t *testing.T) {
+	managers := newManagers(t, 2)
+	stale, err := managers[0].Acquire("report", 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	lease, err := managers[1].Acquire("report", time.Minute)
+	if err != nil {
+		t.Fatalf("Acquire of an expired lock error = %v", err)
+	}
+	if err := stale.Extend(time.Minute); !errors.Is(err, ErrLockLost) {
+		t.Errorf("Extend of a taken over lease error = %v, want ErrLockLost", err)
+	}
+	if err := stale.Release(); !errors.Is(err, ErrLockLost) {
+		t.Errorf("Release of a taken over lease error = %v, want ErrLockLost", err)
+	}
+	if _, err := managers[0].Acquire("report", time.Minute); !errors.Is(err, ErrLockHeld) {
+		t.Errorf("the stale lease's Release freed the new owner's lock: Acquire error = %v", err)
+	}
+	if err := lease.Release(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestExtendKeepsTheLock(t *testing.T) {
+	managers := newManagers(t, 2)
+	lease, err := managers[0].Acquire("sync", 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expiresAt := lease.ExpiresAt()
+	if err := lease.Extend(time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if !lease.ExpiresAt().After(expiresAt.Add(50 * time.Second)) {
+		t.Errorf("ExpiresAt = %s after Extend, want about a minute from now", lease.ExpiresAt())
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	if _, err := managers[1].Acquire("sync", time.Minute); !errors.Is(err, ErrLockHeld) {
+		t.Errorf("Acquire of an extended lock error = %v, want ErrLockHeld", err)
+	}
+	if err := lease.Release(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAcquireNeedsPositiveTTL(t *testing.T) {
+	managers := newManagers(t, 1)
+	if _, err := managers[0].Acquire("job", 0); err == nil {
+		t.Error("Acquire with a zero TTL succeeded, want an error")
+	}
+}
+
+func TestAcquireWait(t *testing.T) {
+	managers := newManagers(t, 2)
+	held, err := managers[0].Acquire("queue", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := stdcontext.WithTimeout(stdcontext.Background(), 3*DefaultRetryInterval)
+	defer cancel()
+	if _, err := managers[1].AcquireWait(ctx, "queue", time.Minute); !errors.Is(err, stdcontext.DeadlineExceeded) {
+		t.Errorf("AcquireWait on a held lock error = %v, want the context's deadline", err)
+	}
+
+	time.AfterFunc(2*DefaultRetryInterval, func() { held.Release() })
+	lease, err := managers[1].AcquireWait(stdcontext.Background(), "queue", time.Minute)
+	if err != nil {
+		t.Fatalf("AcquireWait after the Release error = %v", err)
+	}
+	lease.Release()
+}
+
+func TestWithLockSerializesInstances(t *testing.T) {
+	managers := newManagers(t, 3)
+	var running, overlaps, runs atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 9; i++ {
+		wg.Add(1)
+		go func(manager *Manager) {
+			defer wg.Done()
+			err := manager.WithLock(stdcontext.Background(), "migration", time.Minute, func() error {
+				if running.Add(1) > 1 {
+					overlaps.Add(1)
+				}
+				time.Sleep(5 * time.Millisecond)
+				running.Add(-1)
+				runs.Add(1)
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}(managers[i%len(managers)])
+	}
+	wg.Wait()
+
+	if runs.Load() != 9 || overlaps.Load() != 0 {
+		t.Errorf("%d runs with %d overlapping, want 9 runs one at a time", runs.Load(), overlaps.Load())
+	}
+
+	failed := errors.New("step failed")
+	if err := managers[0].WithLock(stdcontext.Background(), "migration", time.Minute, func() error { return failed }); !errors.Is(err, failed) {
+		t.Errorf("WithLock error = %v, want fn's error", err)
+	}
+	if lease, err := managers[1].Acquire("migration", time.Minute); err != nil {
+		t.Errorf("the lock was not released after fn failed: %v", err)
+	} else {
+		lease.Release()
+	}
+}
@@ -8,11 +8,14 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 	"github.com/shepherrrd/gontext/internal/context"
 	"github.com/shepherrrd/gontext/internal/drivers"
 	"github.com/shepherrrd/gontext/internal/models"
@@ -111,7 +114,7 @@ func (mm *MigrationManager) AddMigration(name string) error {
 	}
 
 	// Create current snapshot
-	currentSnapshot := models.NewModelSnapshot(mm.context.GetEntityModels())
+	currentSnapshot := models.NewModelSnapshot(mm.context.GetEntityModels()).WithSequences(mm.context.GetSequenceModels())
 
 	var operations []models.MigrationOperation
 
@@ -319,6 +322,9 @@ func (mm *MigrationManager) generateOperations() ([]models.MigrationOperation, e
 	driver := mm.context.GetDriver()
 
 	for _, entityModel := range entityModels {
+		if entityModel.View != nil {
+			continue // Created after the tables they read, below
+		}
 		exists, err := mm.tableExists(entityModel.TableName)
 		if err != nil {
 			return nil, err
@@ -327,6 +333,8 @@ func (mm *MigrationManager) generateOperations() ([]models.MigrationOperation, e
 		if !exists {
 			operation := mm.createTableOperation(entityModel, driver)
 			operations = append(operations, operation)
+			operations = append(operations, partitionOperations(entityModel.Name, entityModel.TableName, entityModel.Partition)...)
+			operations = append(operations, triggerOperations(entityModel)...)
 		} else {
 			schemaOps, err := mm.generateSchemaChangeOperations(entityModel, driver)
 			if err != nil {
@@ -336,54 +344,43 @@ func (mm *MigrationManager) generateOperations() ([]models.MigrationOperation, e
 		}
 	}
 
-	return operations, nil
+	for _, entityModel := range mm.sortEntitiesByDependencies(entityModels) {
+		if entityModel.View != nil {
+			operations = append(operations, viewOperations(entityModel)...)
+		}
+	}
+
+	return withExtensions(operations), nil
 }
 
 func (mm *MigrationManager) createTableOperation(entity *models.EntityModel, driver drivers.DatabaseDriver) models.MigrationOperation {
 	var columns []models.ColumnDefinition
-	var indexes []models.IndexDefinition
 	entityModels := mm.context.GetEntityModels() // Get entity models for foreign key resolution
+	foreignKeys := mm.foreignKeyReferences(entity, entityModels)
 
 	for _, field := range entity.Fields {
+		if mm.isNavigation(entity.Name, field.Name, entityModels) {
+			continue
+		}
 		column := models.ColumnDefinition{
 			Name:         field.ColumnName,
-			Type:         driver.MapGoTypeToSQL(field.Type),
+			Type:         columnType(driver, field.Type, field.MaxLength, field.Collation),
 			IsNullable:   field.IsNullable,
 			IsPrimary:    field.IsPrimary,
 			IsUnique:     field.IsUnique,
+			IsIdentity:   field.ValueGeneration == models.ValueGeneratedByIdentity,
 			DefaultValue: field.DefaultValue,
 		}
-
-		// Parse GORM tags for additional constraints
-		if len(field.Tags) > 0 {
-			// Parse foreign key relationships from tags
-			if foreignKey := mm.parseForeignKeyFromTags(field.Tags, entity.Name); foreignKey != nil {
-				column.References = foreignKey
-			}
-
-			// Parse unique indexes
-			if _, hasUniqueIndex := field.Tags["uniqueIndex"]; hasUniqueIndex {
-				column.IsUnique = true
-				indexes = append(indexes, models.IndexDefinition{
-					Name:     fmt.Sprintf("idx_%s_%s", entity.TableName, field.ColumnName),
-					Columns:  []string{field.ColumnName},
-					IsUnique: true,
-				})
-			}
-
-			// Parse regular indexes  
-			if _, hasIndex := field.Tags["index"]; hasIndex {
-				indexes = append(indexes, models.IndexDefinition{
-					Name:     fmt.Sprintf("idx_%s_%s", entity.TableName, field.ColumnName),
-					Columns:  []string{field.ColumnName},
-					IsUnique: false,
-				})
-			}
+		if field.IsComputed() {
+			column.Computed = models.ComputedColumnClause(field.ComputedColumnSql, field.IsComputedStored)
 		}
 
+		// Foreign keys of navigations, then the <Entity>Id naming convention
+		column.References = foreignKeys[field.ColumnName]
+
 		// Also check field names for common foreign key patterns (only for UUID fields)
 		if column.References == nil && strings.Contains(field.Type, "uuid.UUID") {
-			if foreignKey := mm.parseForeignKeyFromFieldName(field.ColumnName, entityModels); foreignKey != nil {
+			if foreignKey := mm.parseForeignKeyFromFieldName(field.Name, entityModels); foreignKey != nil {
 				column.References = foreignKey
 			}
 		}
@@ -397,7 +394,7 @@ func (mm *MigrationManager) createTableOperation(entity *models.EntityModel, dri
 		Details: models.CreateTableOperation{
 			TableName: entity.TableName,
 			Columns:   columns,
-			Indexes:   indexes,
+			Partition: entity.Partition,
 		},
 	}
 }
@@ -428,19 +425,24 @@ func (mm *MigrationManager) generateSchemaChangeOperations(entity *models.Entity
 		}
 
 		if _, exists := dbSchema[field.ColumnName]; !exists {
+			column := models.ColumnDefinition{
+				Name:         field.ColumnName,
+				Type:         columnType(driver, field.Type, field.MaxLength, field.Collation),
+				IsNullable:   field.IsNullable,
+				IsPrimary:    field.IsPrimary,
+				IsUnique:     field.IsUnique,
+				IsIdentity:   field.ValueGeneration == models.ValueGeneratedByIdentity,
+				DefaultValue: field.DefaultValue,
+			}
+			if field.IsComputed() {
+				column.Computed = models.ComputedColumnClause(field.ComputedColumnSql, field.IsComputedStored)
+			}
 			operations = append(operations, models.MigrationOperation{
 				Type:       models.AddColumn,
 				EntityName: entity.Name,
 				Details: models.AddColumnOperation{
 					TableName: entity.TableName,
-					Column: models.ColumnDefinition{
-						Name:         field.ColumnName,
-						Type:         driver.MapGoTypeToSQL(field.Type),
-						IsNullable:   field.IsNullable,
-						IsPrimary:    field.IsPrimary,
-						IsUnique:     field.IsUnique,
-						DefaultValue: field.DefaultValue,
-					},
+					Column:    column,
 				},
 			})
 		}
@@ -543,16 +545,94 @@ func (mm *MigrationManager) generateOperationSQL(op models.MigrationOperation, i
 			}
 		} else {
 			if addOp, ok := op.Details.(models.AddColumnOperation); ok {
-				nullable := ""
+				suffix := ""
+				if addOp.Column.Computed != "" {
+					suffix = " " + escapeGoString(addOp.Column.Computed)
+				}
 				if !addOp.Column.IsNullable {
-					nullable = " NOT NULL"
+					suffix += " NOT NULL"
 				}
 				return fmt.Sprintf(`	// Add column %s to %s
 	if err := db.Exec("ALTER TABLE \\\"%s\\\" ADD COLUMN \\\"%s\\\" %s%s").Error; err != nil {
 		return err
 	}
-`, addOp.Column.Name, addOp.TableName, addOp.TableName, addOp.Column.Name, addOp.Column.Type, nullable)
+`, addOp.Column.Name, addOp.TableName, addOp.TableName, addOp.Column.Name, addOp.Column.Type, suffix)
+			}
+		}
+	case models.RenameTable:
+		if renameOp, ok := op.Details.(models.RenameTableOperation); ok {
+			from, to := renameOp.OldName, renameOp.NewName
+			if isRollback {
+				from, to = to, from
+			}
+			return fmt.Sprintf(`	// Rename table %s to %s
+	if err := db.Exec("ALTER TABLE \"%s\" RENAME TO \"%s\"").Error; err != nil {
+		return err
+	}
+`, from, to, from, to)
+		}
+	case models.CreateExtension:
+		if extensionOp, ok := op.Details.(models.CreateExtensionOperation); ok && !isRollback {
+			return fmt.Sprintf(`	// Create extension %s
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS %s").Error; err != nil {
+		return err
+	}
+`, extensionOp.Name, extensionOp.Name)
+		}
+	case models.CreateTrigger, models.DropTrigger:
+		var tableName string
+		var trigger models.TriggerModel
+		if triggerOp, ok := op.Details.(models.CreateTriggerOperation); ok {
+			tableName, trigger = triggerOp.TableName, triggerOp.Trigger
+		} else if triggerOp, ok := op.Details.(models.DropTriggerOperation); ok {
+			tableName, trigger = triggerOp.TableName, triggerOp.Trigger
+		}
+		comment, statements := "Create trigger", models.CreateTriggerSQL(tableName, trigger)
+		if (op.Type == models.CreateTrigger) == isRollback {
+			comment, statements = "Drop trigger", models.DropTriggerSQL(tableName, trigger)
+		}
+		code := fmt.Sprintf("\t// %s %s on %s\n", comment, trigger.Name, tableName)
+		for _, statement := range statements {
+			code += fmt.Sprintf(`	if err := db.Exec("%s").Error; err != nil {
+		return err
+	}
+`, escapeGoString(statement))
+		}
+		return code
+	case models.CreateView, models.DropView:
+		var view models.ViewModel
+		create := op.Type == models.CreateView
+		if viewOp, ok := op.Details.(models.CreateViewOperation); ok {
+			view = viewOp.View
+		} else if viewOp, ok := op.Details.(models.DropViewOperation); ok {
+			view = viewOp.View
+		}
+		if create == isRollback {
+			return fmt.Sprintf(`	// Drop materialized view %s
+	if err := db.Exec("%s").Error; err != nil {
+		return err
+	}
+`, view.Name, escapeGoString(models.DropViewSQL(view.Name)))
+		}
+		return fmt.Sprintf(`	// Create materialized view %s
+	if err := db.Exec("%s").Error; err != nil {
+		return err
+	}
+`, view.Name, escapeGoString(models.CreateViewSQL(view)))
+	case models.CreatePartition:
+		if partitionOp, ok := op.Details.(models.CreatePartitionOperation); ok {
+			if isRollback {
+				return fmt.Sprintf(`	// Drop partition %s
+	if err := db.Exec("DROP TABLE IF EXISTS \"%s\"").Error; err != nil {
+		return err
+	}
+`, partitionOp.PartitionName, partitionOp.PartitionName)
 			}
+			return fmt.Sprintf(`	// Create partition %s of %s
+	if err := db.Exec("%s").Error; err != nil {
+		return err
+	}
+`, partitionOp.PartitionName, partitionOp.TableName, escapeGoString(models.CreatePartitionSQL(partitionOp.TableName, partitionOp.PartitionName, partitionOp.Bound)))
 		}
 	case models.RenameColumn:
 		if renameOp, ok := op.Details.(models.RenameColumnOperation); ok {
@@ -570,6 +650,91 @@ func (mm *MigrationManager) generateOperationSQL(op models.MigrationOperation, i
 `, renameOp.OldName, renameOp.NewName, renameOp.TableName, renameOp.TableName, renameOp.OldName, renameOp.NewName)
 			}
 		}
+	case models.ModifyColumn:
+		if modifyOp, ok := op.Details.(models.ModifyColumnOperation); ok {
+			column := modifyOp.Column
+			if isRollback {
+				column = modifyOp.OldColumn
+			}
+			return fmt.Sprintf(`	// Change the type of column %s in %s
+	if err := db.Exec("%s").Error; err != nil {
+		return err
+	}
+`, column.Name, modifyOp.TableName, escapeGoString(alterColumnTypeSQL(modifyOp.TableName, column)))
+		}
+	case models.AddIndex:
+		if indexOp, ok := op.Details.(models.AddIndexOperation); ok {
+			if isRollback {
+				return fmt.Sprintf(`	// Drop index %s
+	if err := db.Exec("%s").Error; err != nil {
+		return err
+	}
+`, indexOp.Index.Name, escapeGoString(models.DropIndexSQL(indexOp.Index)))
+			}
+			return fmt.Sprintf(`	// Create index %s on %s
+	if err := db.Exec("%s").Error; err != nil {
+		return err
+	}
+`, indexOp.Index.Name, indexOp.TableName, escapeGoString(models.CreateIndexSQL(indexOp.TableName, indexOp.Index)))
+		}
+	case models.DropIndex:
+		if indexOp, ok := op.Details.(models.DropIndexOperation); ok {
+			if isRollback {
+				return fmt.Sprintf(`	// Recreate index %s on %s
+	if err := db.Exec("%s").Error; err != nil {
+		return err
+	}
+`, indexOp.Index.Name, indexOp.TableName, escapeGoString(models.CreateIndexSQL(indexOp.TableName, indexOp.Index)))
+			}
+			return fmt.Sprintf(`	// Drop index %s
+	if err := db.Exec("%s").Error; err != nil {
+		return err
+	}
+`, indexOp.Index.Name, escapeGoString(models.DropIndexSQL(indexOp.Index)))
+		}
+	case models.CreateSequence:
+		if seqOp, ok := op.Details.(models.CreateSequenceOperation); ok {
+			if isRollback {
+				return fmt.Sprintf(`	// Drop sequence %s
+	if err := db.Exec("DROP SEQUENCE IF EXISTS \"%s\"").Error; err != nil {
+		return err
+	}
+`, seqOp.SequenceName, seqOp.SequenceName)
+			}
+			return fmt.Sprintf(`	// Create sequence %s
+	if err := db.Exec("%s").Error; err != nil {
+		return err
+	}
+`, seqOp.SequenceName, escapeGoString(createSequenceSQL(seqOp)))
+		}
+	case models.AlterSequence:
+		if seqOp, ok := op.Details.(models.AlterSequenceOperation); ok {
+			increment := seqOp.IncrementBy
+			if isRollback {
+				increment = seqOp.OldIncrementBy
+			}
+			return fmt.Sprintf(`	// Change the increment of sequence %s
+	if err := db.Exec("ALTER SEQUENCE \"%s\" INCREMENT BY %d").Error; err != nil {
+		return err
+	}
+`, seqOp.SequenceName, seqOp.SequenceName, increment)
+		}
+	case models.DropSequence:
+		if seqOp, ok := op.Details.(models.DropSequenceOperation); ok {
+			if isRollback {
+				create := models.CreateSequenceOperation{SequenceName: seqOp.SequenceName, IncrementBy: seqOp.IncrementBy, StartWith: seqOp.StartWith}
+				return fmt.Sprintf(`	// Recreate sequence %s
+	if err := db.Exec("%s").Error; err != nil {
+		return err
+	}
+`, seqOp.SequenceName, escapeGoString(createSequenceSQL(create)))
+			}
+			return fmt.Sprintf(`	// Drop sequence %s
+	if err := db.Exec("DROP SEQUENCE IF EXISTS \"%s\"").Error; err != nil {
+		return err
+	}
+`, seqOp.SequenceName, seqOp.SequenceName)
+		}
 	}
 	return ""
 }
@@ -585,16 +750,26 @@ func (mm *MigrationManager) generateCreateTableSQL(createOp models.CreateTableOp
 	
 	for _, col := range createOp.Columns {
 		columnDef := fmt.Sprintf("\"%s\" %s", col.Name, col.Type)
+		if col.Computed != "" {
+			columnDef += " " + col.Computed
+		} else if col.IsIdentity && !strings.Contains(strings.ToUpper(col.Type), "SERIAL") {
+			columnDef += " GENERATED BY DEFAULT AS IDENTITY"
+		}
 		if !col.IsNullable {
 			columnDef += " NOT NULL"
 		}
 		if col.IsUnique && !col.IsPrimary {
 			// Use named unique constraints for better error messages
-			uniqueConstraintName := fmt.Sprintf("uni_%s_%s", createOp.TableName, col.Name)
+			uniqueConstraintName := mm.context.ConstraintNaming().UniqueName(createOp.TableName, col.Name)
+			uniqueColumns := fmt.Sprintf("\"%s\"", col.Name)
+			if createOp.Partition != nil && col.Name != createOp.Partition.Column {
+				// Unique constraints of a partitioned table must include the partition key
+				uniqueColumns += fmt.Sprintf(", \"%s\"", createOp.Partition.Column)
+			}
 			uniqueConstraints = append(uniqueConstraints, 
-				fmt.Sprintf("CONSTRAINT \"%s\" UNIQUE (\"%s\")", uniqueConstraintName, col.Name))
+				fmt.Sprintf("CONSTRAINT \"%s\" UNIQUE (%s)", uniqueConstraintName, uniqueColumns))
 		}
-		if col.DefaultValue != nil {
+		if col.DefaultValue != nil && col.Computed == "" {
 			columnDef += fmt.Sprintf(" DEFAULT %s", *col.DefaultValue)
 		}
 		columns = append(columns, columnDef)
@@ -605,15 +780,26 @@ func (mm *MigrationManager) generateCreateTableSQL(createOp models.CreateTableOp
 		
 		// Add foreign key constraints
 		if col.References != nil {
-			fkConstraintName := fmt.Sprintf("fk_%s_%s", createOp.TableName, col.Name)
+			fkConstraintName := col.References.ConstraintName
+			if fkConstraintName == "" {
+				fkConstraintName = mm.context.ConstraintNaming().ForeignKeyName(createOp.TableName, col.Name)
+			}
 			foreignKeys = append(foreignKeys, 
-				fmt.Sprintf("CONSTRAINT \"%s\" FOREIGN KEY (\"%s\") REFERENCES \"%s\" (\"%s\")", 
-					fkConstraintName, col.Name, col.References.ReferencedTable, col.References.ReferencedColumn))
+				fmt.Sprintf("CONSTRAINT \"%s\" FOREIGN KEY (\"%s\") REFERENCES \"%s\" (\"%s\")%s", 
+					fkConstraintName, col.Name, col.References.ReferencedTable, col.References.ReferencedColumn,
+					referentialActions(col.References)))
 		}
 	}
 	
 	sql.WriteString(strings.Join(columns, ", "))
 	
+	if len(primaryKeys) > 0 && createOp.Partition != nil {
+		// The primary key of a partitioned table must include the partition key
+		partitionKey := fmt.Sprintf("\"%s\"", createOp.Partition.Column)
+		if !slices.Contains(primaryKeys, partitionKey) {
+			primaryKeys = append(primaryKeys, partitionKey)
+		}
+	}
 	if len(primaryKeys) > 0 {
 		sql.WriteString(fmt.Sprintf(", PRIMARY KEY (%s)", strings.Join(primaryKeys, ", ")))
 	}
@@ -631,6 +817,9 @@ func (mm *MigrationManager) generateCreateTableSQL(createOp models.CreateTableOp
 	}
 	
 	sql.WriteString(")")
+	if createOp.Partition != nil {
+		sql.WriteString(" " + createOp.Partition.PartitionClause())
+	}
 	return sql.String()
 }
 
@@ -744,7 +933,7 @@ func (mm *MigrationManager) executeMigrationSQL(migrationID string, tx *gorm.DB)
 		return fmt.Errorf("failed to load previous snapshot: %w", err)
 	}
 
-	currentSnapshot := models.NewModelSnapshot(mm.context.GetEntityModels())
+	currentSnapshot := models.NewModelSnapshot(mm.context.GetEntityModels()).WithSequences(mm.context.GetSequenceModels())
 	var operations []models.MigrationOperation
 
 	if previousSnapshot == nil {
@@ -781,8 +970,32 @@ func (mm *MigrationManager) executeMigrationSQL(migrationID string, tx *gorm.DB)
 func (mm *MigrationManager) executeMigrationOperations(tx *gorm.DB) error {
 	// For initial migrations, use GORM's AutoMigrate to create tables
 	entityModelsMap := mm.context.GetEntityModels()
+
+	for _, op := range mm.declaredSequenceOperations() {
+		seqOp := op.Details.(models.CreateSequenceOperation)
+		if err := tx.Exec(mm.generateOperationExecutionSQL(op)).Error; err != nil {
+			return fmt.Errorf("failed to create sequence %s: %w", seqOp.SequenceName, err)
+		}
+	}
+
+	// Extensions the indexes need come first
+	var searchIndexes []models.MigrationOperation
+	for _, entityModel := range entityModelsMap {
+		searchIndexes = append(searchIndexes, textSearchIndexOperations(entityModel)...)
+	}
+	for _, op := range withExtensions(searchIndexes) {
+		if op.Type != models.CreateExtension {
+			break
+		}
+		if err := tx.Exec(mm.generateOperationExecutionSQL(op)).Error; err != nil {
+			return fmt.Errorf("failed to create extension %s: %w", op.Details.(models.CreateExtensionOperation).Name, err)
+		}
+	}
 	
 	for _, entityModel := range entityModelsMap {
+		if entityModel.View != nil {
+			continue // Created after the tables they read, below
+		}
 		// Get a pointer to a new instance of the entity type
 		entityPtr := reflect.New(entityModel.Type).Interface()
 		
@@ -790,6 +1003,42 @@ func (mm *MigrationManager) executeMigrationOperations(tx *gorm.DB) error {
 		if err := tx.AutoMigrate(entityPtr); err != nil {
 			return fmt.Errorf("failed to auto-migrate entity %s: %w", entityModel.Name, err)
 		}
+
+		// AutoMigrate knows nothing about builder-declared or full-text indexes or HiLo keys, so create them explicitly
+		for _, op := range append(indexOperations(entityModel), textSearchIndexOperations(entityModel)...) {
+			if err := tx.Exec(mm.generateOperationExecutionSQL(op)).Error; err != nil {
+				return fmt.Errorf("failed to create index %s: %w", op.Details.(models.AddIndexOperation).Index.Name, err)
+			}
+		}
+
+		// nor about triggers
+		for _, op := range triggerOperations(entityModel) {
+			if err := tx.Exec(mm.generateOperationExecutionSQL(op)).Error; err != nil {
+				return fmt.Errorf("failed to create trigger %s: %w", op.Details.(models.CreateTriggerOperation).Trigger.Name, err)
+			}
+		}
+
+		for _, field := range entityModel.Fields {
+			if field.ValueGeneration != models.ValueGeneratedByHiLo {
+				continue
+			}
+			sql := mm.generateOperationExecutionSQL(createSequenceOperation(entityModel.Name, field.HiLoSequence))
+			if err := tx.Exec(sql).Error; err != nil {
+				return fmt.Errorf("failed to create sequence %s: %w", field.HiLoSequence, err)
+			}
+		}
+	}
+
+	for _, entityModel := range mm.sortEntitiesByDependencies(entityModelsMap) {
+		if entityModel.View == nil {
+			continue
+		}
+		fmt.Printf("Creating materialized view for entity: %s (view: %s)\n", entityModel.Name, entityModel.View.Name)
+		for _, op := range viewOperations(entityModel) {
+			if err := tx.Exec(mm.generateOperationExecutionSQL(op)).Error; err != nil {
+				return fmt.Errorf("failed to create materialized view %s: %w", entityModel.View.Name, err)
+			}
+		}
 	}
 	
 	return nil
@@ -816,6 +1065,9 @@ func (mm *MigrationManager) executeRollbackOperations(migrationId string, tx *go
 		
 		// Use quoted table name for PostgreSQL case sensitivity
 		dropSQL := fmt.Sprintf("DROP TABLE IF EXISTS \"%s\" CASCADE", tableName)
+		if entityModel.View != nil {
+			dropSQL = models.DropViewSQL(tableName) + " CASCADE"
+		}
 		if err := tx.Exec(dropSQL).Error; err != nil {
 			return fmt.Errorf("failed to drop table %s: %w", tableName, err)
 		}
@@ -837,16 +1089,47 @@ func (mm *MigrationManager) generateOperationExecutionSQL(op models.MigrationOpe
 		}
 	case models.AddColumn:
 		if addOp, ok := op.Details.(models.AddColumnOperation); ok {
-			nullable := ""
+			suffix := ""
+			if addOp.Column.Computed != "" {
+				suffix = " " + addOp.Column.Computed
+			}
 			if !addOp.Column.IsNullable {
-				nullable = " NOT NULL"
+				suffix += " NOT NULL"
 			}
 			defaultVal := ""
-			if addOp.Column.DefaultValue != nil {
+			if addOp.Column.DefaultValue != nil && addOp.Column.Computed == "" {
 				defaultVal = fmt.Sprintf(" DEFAULT %s", *addOp.Column.DefaultValue)
 			}
 			return fmt.Sprintf("ALTER TABLE \"%s\" ADD COLUMN \"%s\" %s%s%s", 
-				addOp.TableName, addOp.Column.Name, addOp.Column.Type, nullable, defaultVal)
+				addOp.TableName, addOp.Column.Name, addOp.Column.Type, suffix, defaultVal)
+		}
+	case models.RenameTable:
+		if renameOp, ok := op.Details.(models.RenameTableOperation); ok {
+			return fmt.Sprintf("ALTER TABLE \"%s\" RENAME TO \"%s\"", renameOp.OldName, renameOp.NewName)
+		}
+	case models.CreateExtension:
+		if extensionOp, ok := op.Details.(models.CreateExtensionOperation); ok {
+			return "CREATE EXTENSION IF NOT EXISTS " + extensionOp.Name
+		}
+	case models.CreateTrigger:
+		if triggerOp, ok := op.Details.(models.CreateTriggerOperation); ok {
+			return strings.Join(models.CreateTriggerSQL(triggerOp.TableName, triggerOp.Trigger), ";\n")
+		}
+	case models.DropTrigger:
+		if triggerOp, ok := op.Details.(models.DropTriggerOperation); ok {
+			return strings.Join(models.DropTriggerSQL(triggerOp.TableName, triggerOp.Trigger), ";\n")
+		}
+	case models.CreateView:
+		if viewOp, ok := op.Details.(models.CreateViewOperation); ok {
+			return models.CreateViewSQL(viewOp.View)
+		}
+	case models.DropView:
+		if viewOp, ok := op.Details.(models.DropViewOperation); ok {
+			return models.DropViewSQL(viewOp.View.Name)
+		}
+	case models.CreatePartition:
+		if partitionOp, ok := op.Details.(models.CreatePartitionOperation); ok {
+			return models.CreatePartitionSQL(partitionOp.TableName, partitionOp.PartitionName, partitionOp.Bound)
 		}
 	case models.RenameColumn:
 		if renameOp, ok := op.Details.(models.RenameColumnOperation); ok {
@@ -858,6 +1141,30 @@ func (mm *MigrationManager) generateOperationExecutionSQL(op models.MigrationOpe
 			return fmt.Sprintf("ALTER TABLE \"%s\" DROP COLUMN \"%s\"", 
 				dropOp.TableName, dropOp.ColumnName)
 		}
+	case models.ModifyColumn:
+		if modifyOp, ok := op.Details.(models.ModifyColumnOperation); ok {
+			return alterColumnTypeSQL(modifyOp.TableName, modifyOp.Column)
+		}
+	case models.AddIndex:
+		if indexOp, ok := op.Details.(models.AddIndexOperation); ok {
+			return models.CreateIndexSQL(indexOp.TableName, indexOp.Index)
+		}
+	case models.DropIndex:
+		if indexOp, ok := op.Details.(models.DropIndexOperation); ok {
+			return models.DropIndexSQL(indexOp.Index)
+		}
+	case models.CreateSequence:
+		if seqOp, ok := op.Details.(models.CreateSequenceOperation); ok {
+			return createSequenceSQL(seqOp)
+		}
+	case models.AlterSequence:
+		if seqOp, ok := op.Details.(models.AlterSequenceOperation); ok {
+			return fmt.Sprintf("ALTER SEQUENCE \"%s\" INCREMENT BY %d", seqOp.SequenceName, seqOp.IncrementBy)
+		}
+	case models.DropSequence:
+		if seqOp, ok := op.Details.(models.DropSequenceOperation); ok {
+			return fmt.Sprintf("DROP SEQUENCE IF EXISTS \"%s\"", seqOp.SequenceName)
+		}
 	}
 	return ""
 }
@@ -1016,125 +1323,211 @@ func (mm *MigrationManager) generateInitialOperations() ([]models.MigrationOpera
 	entityModels := mm.context.GetEntityModels()
 	driver := mm.context.GetDriver()
 
+	operations = append(operations, mm.declaredSequenceOperations()...)
+
 	// Sort entities by dependencies (parent tables first)
 	sortedEntities := mm.sortEntitiesByDependencies(entityModels)
 
 	for _, entityModel := range sortedEntities {
+		if entityModel.View != nil {
+			operations = append(operations, viewOperations(entityModel)...)
+			continue
+		}
+		for _, field := range entityModel.Fields {
+			if field.ValueGeneration == models.ValueGeneratedByHiLo {
+				operations = append(operations, createSequenceOperation(entityModel.Name, field.HiLoSequence))
+			}
+		}
 		operation := mm.createTableOperation(entityModel, driver)
 		operations = append(operations, operation)
+		operations = append(operations, partitionOperations(entityModel.Name, entityModel.TableName, entityModel.Partition)...)
+		operations = append(operations, indexOperations(entityModel)...)
+		operations = append(operations, textSearchIndexOperations(entityModel)...)
+		operations = append(operations, triggerOperations(entityModel)...)
 	}
 
-	return operations, nil
+	return withExtensions(operations), nil
 }
 
-// sortEntitiesByDependencies sorts entities so parent tables are created before child tables
-// Uses dynamic topological sorting based on foreign key relationships detected from GORM tags
-func (mm *MigrationManager) sortEntitiesByDependencies(entityModels map[string]*models.EntityModel) []*models.EntityModel {
-	// Build dependency graph from foreign key relationships
-	dependencies := make(map[string][]string) // entity -> list of entities it depends on
-	allEntities := make(map[string]*models.EntityModel)
-	
-	// Initialize maps
-	for _, entity := range entityModels {
-		allEntities[entity.Name] = entity
-		dependencies[entity.Name] = []string{}
+// triggerOperations creates the entity's declared triggers
+func triggerOperations(entity *models.EntityModel) []models.MigrationOperation {
+	var operations []models.MigrationOperation
+	for _, trigger := range entity.Triggers {
+		operations = append(operations, models.MigrationOperation{
+			Type:       models.CreateTrigger,
+			EntityName: entity.Name,
+			Details:    models.CreateTriggerOperation{TableName: entity.TableName, Trigger: *trigger},
+		})
 	}
-	
-	// Analyze each entity for foreign key dependencies
-	for _, entity := range entityModels {
-		for _, field := range entity.Fields {
-			// Check if field has foreign key relationship via GORM tags
-			if gormTag, exists := field.Tags["gorm"]; exists {
-				if strings.Contains(gormTag, "foreignKey:") {
-					// Parse foreignKey tag to find referenced entity
-					parts := strings.Split(gormTag, ";")
-					for _, part := range parts {
-						part = strings.TrimSpace(part)
-						if strings.HasPrefix(part, "references:") {
-							// This indicates a relationship - find the referenced entity
-							// The field type should indicate the referenced entity
-							fieldType := strings.TrimPrefix(field.Type, "[]") // Handle slices
-							fieldType = strings.TrimPrefix(fieldType, "*")    // Handle pointers
-							
-							// Check if this type corresponds to another entity
-							for _, otherEntity := range entityModels {
-								if otherEntity.Name == fieldType {
-									dependencies[entity.Name] = append(dependencies[entity.Name], fieldType)
-								}
-							}
-						}
-					}
-				}
-			}
-			
-			// Also check for UUID fields that follow naming conventions (e.g., UserId, BucketId)
-			if strings.Contains(field.Type, "uuid.UUID") && strings.HasSuffix(field.Name, "Id") {
-				// Extract potential entity name (e.g., UserId -> User, BucketId -> Bucket)
-				potentialEntityName := strings.TrimSuffix(field.Name, "Id")
-				if referencedEntity, exists := allEntities[potentialEntityName]; exists && referencedEntity.Name != entity.Name {
-					// Avoid duplicates
-					found := false
-					for _, dep := range dependencies[entity.Name] {
-						if dep == potentialEntityName {
-							found = true
-							break
-						}
-					}
-					if !found {
-						dependencies[entity.Name] = append(dependencies[entity.Name], potentialEntityName)
-					}
-				}
-			}
-		}
+	return operations
+}
+
+// viewOperations creates a view entity's materialized view and its declared indexes, such as the unique
+// index REFRESH MATERIALIZED VIEW CONCURRENTLY needs
+func viewOperations(entity *models.EntityModel) []models.MigrationOperation {
+	operations := []models.MigrationOperation{{
+		Type:       models.CreateView,
+		EntityName: entity.Name,
+		Details:    models.CreateViewOperation{View: *entity.View},
+	}}
+	return append(operations, indexOperations(entity)...)
+}
+
+// partitionOperations creates the partitions a partitioned table starts with: all of its hash partitions,
+// or the DEFAULT partition of range partitioning, whose ranged partitions are created by maintenance
+func partitionOperations(entityName, tableName string, partition *models.PartitionModel) []models.MigrationOperation {
+	if partition == nil {
+		return nil
 	}
-	
-	// Perform topological sort
-	result := []*models.EntityModel{}
-	visited := make(map[string]bool)
-	visiting := make(map[string]bool)
-	
-	var visit func(string) error
-	visit = func(entityName string) error {
-		if visiting[entityName] {
-			return fmt.Errorf("circular dependency detected involving entity: %s", entityName)
-		}
-		if visited[entityName] {
-			return nil
+	var operations []models.MigrationOperation
+	for _, initial := range partition.InitialPartitions(tableName) {
+		operations = append(operations, models.MigrationOperation{
+			Type:       models.CreatePartition,
+			EntityName: entityName,
+			Details: models.CreatePartitionOperation{
+				TableName:     tableName,
+				PartitionName: initial[0],
+				Bound:         initial[1],
+			},
+		})
+	}
+	return operations
+}
+
+// indexOperations creates the entity's declared and tag indexes, ordered by name
+func indexOperations(entity *models.EntityModel) []models.MigrationOperation {
+	var operations []models.MigrationOperation
+	for _, index := range models.EntityIndexes(entity) {
+		operations = append(operations, addIndexOperation(entity.Name, entity.TableName, index))
+	}
+	return operations
+}
+
+// textSearchIndexOperations creates the full-text indexes of the entity's columns searched with a
+// text search configuration, and the trigram indexes of those searched by similarity
+func textSearchIndexOperations(entity *models.EntityModel) []models.MigrationOperation {
+	var operations []models.MigrationOperation
+	for _, field := range entity.Fields {
+		if field.TextSearchConfig != "" {
+			operations = append(operations, addIndexOperation(entity.Name, entity.TableName,
+				models.TextSearchIndex(entity.Naming(), entity.TableName, field.ColumnName, field.TextSearchConfig)))
 		}
-		
-		visiting[entityName] = true
-		
-		// Visit all dependencies first
-		for _, dep := range dependencies[entityName] {
-			if _, exists := allEntities[dep]; exists {
-				if err := visit(dep); err != nil {
-					return err
-				}
-			}
+		if field.Trigram {
+			operations = append(operations, addIndexOperation(entity.Name, entity.TableName,
+				models.TrigramIndex(entity.Naming(), entity.TableName, field.ColumnName)))
 		}
-		
-		visiting[entityName] = false
-		visited[entityName] = true
-		result = append(result, allEntities[entityName])
-		
-		return nil
 	}
-	
-	// Visit all entities
-	for entityName := range allEntities {
-		if !visited[entityName] {
-			if err := visit(entityName); err != nil {
-				// If topological sort fails due to cycles, fall back to simple ordering
-				fmt.Printf("Warning: %v. Using simple entity ordering.\n", err)
-				result = []*models.EntityModel{}
-				for _, entity := range entityModels {
-					result = append(result, entity)
-				}
-				break
-			}
-		}
+	sort.Slice(operations, func(i, j int) bool {
+		return operations[i].Details.(models.AddIndexOperation).Index.Name < operations[j].Details.(models.AddIndexOperation).Index.Name
+	})
+	return operations
+}
+
+func snapshotIndexDefinition(index models.IndexSnapshot) models.IndexDefinition {
+	return models.IndexDefinition{
+		Name:       index.Name,
+		Columns:    index.Columns,
+		IsUnique:   index.IsUnique,
+		Expression: index.Expression,
+		Filter:     index.Filter,
+	}
+}
+
+func addIndexOperation(entityName, tableName string, index models.IndexDefinition) models.MigrationOperation {
+	return models.MigrationOperation{
+		Type:       models.AddIndex,
+		EntityName: entityName,
+		Details:    models.AddIndexOperation{TableName: tableName, Index: index},
+	}
+}
+
+func dropIndexOperation(entityName, tableName string, index models.IndexDefinition) models.MigrationOperation {
+	return models.MigrationOperation{
+		Type:       models.DropIndex,
+		EntityName: entityName,
+		Details:    models.DropIndexOperation{TableName: tableName, Index: index},
+	}
+}
+
+// columnType returns a column's SQL type: VARCHAR(maxLength) instead of the driver's text type for strings
+// with a max length, followed by the column's collation
+func columnType(driver drivers.DatabaseDriver, goType string, maxLength int, collation string) string {
+	sqlType := driver.MapGoTypeToSQL(goType)
+	upper := strings.ToUpper(sqlType)
+	if maxLength > 0 && (upper == "TEXT" || strings.HasPrefix(upper, "VARCHAR")) {
+		sqlType = fmt.Sprintf("VARCHAR(%d)", maxLength)
+	}
+	if collation != "" {
+		sqlType += fmt.Sprintf(` COLLATE "%s"`, collation)
+	}
+	return sqlType
+}
+
+// createSequenceOperation builds the operation for a HiLo key sequence
+// The sequence increments by 1 - each value reserves a whole block of keys on the client
+func createSequenceOperation(entityName, sequenceName string) models.MigrationOperation {
+	return models.MigrationOperation{
+		Type:       models.CreateSequence,
+		EntityName: entityName,
+		Details: models.CreateSequenceOperation{
+			SequenceName: sequenceName,
+			IncrementBy:  1,
+		},
+	}
+}
+
+// declaredSequenceOperations creates the sequences declared with HasSequence, ordered by name
+func (mm *MigrationManager) declaredSequenceOperations() []models.MigrationOperation {
+	sequences := mm.context.GetSequenceModels()
+	names := make([]string, 0, len(sequences))
+	for name := range sequences {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	operations := make([]models.MigrationOperation, 0, len(names))
+	for _, name := range names {
+		operations = append(operations, models.MigrationOperation{
+			Type: models.CreateSequence,
+			Details: models.CreateSequenceOperation{
+				SequenceName: name,
+				IncrementBy:  sequences[name].BlockSize,
+				StartWith:    sequences[name].StartWith,
+			},
+		})
+	}
+	return operations
+}
+
+// createSequenceSQL renders the CREATE SEQUENCE statement of an operation
+func createSequenceSQL(seqOp models.CreateSequenceOperation) string {
+	sql := fmt.Sprintf("CREATE SEQUENCE IF NOT EXISTS \"%s\" INCREMENT BY %d", seqOp.SequenceName, seqOp.IncrementBy)
+	if seqOp.StartWith != 0 {
+		sql += fmt.Sprintf(" START WITH %d", seqOp.StartWith)
+	}
+	return sql
+}
+
+// alterColumnTypeSQL changes a column's type, including its collation
+func alterColumnTypeSQL(tableName string, column models.ColumnDefinition) string {
+	return fmt.Sprintf(`ALTER TABLE "%s" ALTER COLUMN "%s" TYPE %s`, tableName, column.Name, column.Type)
+}
+
+// escapeGoString escapes SQL for a double-quoted string literal in a generated migration file, including
+// the backslashes and line breaks of multi-line view queries
+func escapeGoString(sql string) string {
+	quoted := strconv.Quote(sql)
+	return quoted[1 : len(quoted)-1]
+}
+
+// sortEntitiesByDependencies sorts entities so parent tables are created before child tables
+// Uses dynamic topological sorting based on foreign key relationships detected from GORM tags
+func (mm *MigrationManager) sortEntitiesByDependencies(entityModels map[string]*models.EntityModel) []*models.EntityModel {
+	result, err := models.SortEntitiesByDependencies(entityModels)
+	if err != nil {
+		// If topological sort fails due to cycles, fall back to simple ordering
+		fmt.Printf("Warning: %v. Using simple entity ordering.\n", err)
 	}
-	
 	return result
 }
 
@@ -1143,31 +1536,129 @@ func (mm *MigrationManager) generateOperationsFromComparison(comparison *models.
 	driver := mm.context.GetDriver()
 	entityModels := mm.context.GetEntityModels()
 
+	// Materialized views are dropped before and created after the table changes, so they never block
+	// changes to the columns they read and always see the new ones; creations are ordered so views come
+	// after the entities they read
+	var viewDrops []models.MigrationOperation
+	viewCreates := make(map[string][]models.MigrationOperation)
+
 	for _, change := range comparison.Changes {
 		switch change.Type {
 		case models.EntityAdded:
 			entitySnapshot := change.Details.(models.EntitySnapshot)
+			if entitySnapshot.View != nil {
+				viewCreates[entitySnapshot.Name] = append(viewCreates[entitySnapshot.Name], models.MigrationOperation{
+					Type:       models.CreateView,
+					EntityName: entitySnapshot.Name,
+					Details:    models.CreateViewOperation{View: *entitySnapshot.View},
+				})
+				for _, index := range entitySnapshot.Indexes {
+					viewCreates[entitySnapshot.Name] = append(viewCreates[entitySnapshot.Name],
+						addIndexOperation(entitySnapshot.Name, entitySnapshot.TableName, snapshotIndexDefinition(index)))
+				}
+				continue
+			}
+			for _, field := range entitySnapshot.Fields {
+				if field.HiLoSequence != "" {
+					operations = append(operations, createSequenceOperation(entitySnapshot.Name, field.HiLoSequence))
+				}
+			}
 			operation := mm.createTableOperationFromSnapshot(entitySnapshot, driver, entityModels)
 			operations = append(operations, operation)
+			operations = append(operations, partitionOperations(entitySnapshot.Name, entitySnapshot.TableName, entitySnapshot.Partition)...)
+			for _, index := range entitySnapshot.Indexes {
+				operations = append(operations, addIndexOperation(entitySnapshot.Name, entitySnapshot.TableName, snapshotIndexDefinition(index)))
+			}
+			for _, field := range sortedFieldSnapshots(entitySnapshot.Fields) {
+				if field.TextSearch != "" {
+					operations = append(operations, addIndexOperation(entitySnapshot.Name, entitySnapshot.TableName,
+						models.TextSearchIndex(mm.context.ConstraintNaming(), entitySnapshot.TableName, field.ColumnName, field.TextSearch)))
+				}
+				if field.Trigram {
+					operations = append(operations, addIndexOperation(entitySnapshot.Name, entitySnapshot.TableName,
+						models.TrigramIndex(mm.context.ConstraintNaming(), entitySnapshot.TableName, field.ColumnName)))
+				}
+			}
+			for _, trigger := range entitySnapshot.Triggers {
+				operations = append(operations, models.MigrationOperation{
+					Type:       models.CreateTrigger,
+					EntityName: entitySnapshot.Name,
+					Details:    models.CreateTriggerOperation{TableName: entitySnapshot.TableName, Trigger: trigger},
+				})
+			}
+
+		case models.EntityRemoved:
+			// Tables of removed entities are kept, but a view holds no data of its own
+			if entitySnapshot := change.Details.(models.EntitySnapshot); entitySnapshot.View != nil {
+				viewDrops = append(viewDrops, models.MigrationOperation{
+					Type:       models.DropView,
+					EntityName: entitySnapshot.Name,
+					Details:    models.DropViewOperation{View: *entitySnapshot.View},
+				})
+			}
+
+		case models.ViewReplaced:
+			views := change.Details.(models.ViewComparison)
+			if views.Old != nil {
+				viewDrops = append(viewDrops, models.MigrationOperation{
+					Type:       models.DropView,
+					EntityName: change.EntityName,
+					Details:    models.DropViewOperation{View: *views.Old},
+				})
+			}
+			if views.New != nil {
+				viewCreates[change.EntityName] = append(viewCreates[change.EntityName], models.MigrationOperation{
+					Type:       models.CreateView,
+					EntityName: change.EntityName,
+					Details:    models.CreateViewOperation{View: *views.New},
+				})
+			}
+
+		case models.TableRenamed:
+			rename := change.Details.(models.TableRename)
+			operations = append(operations, models.MigrationOperation{
+				Type:       models.RenameTable,
+				EntityName: change.EntityName,
+				Details:    models.RenameTableOperation{OldName: rename.OldName, NewName: rename.NewName},
+			})
 
 		case models.FieldAdded:
 			fieldSnapshot := change.Details.(models.FieldSnapshot)
+			if mm.isNavigation(change.EntityName, fieldSnapshot.Name, entityModels) {
+				continue
+			}
 			operation := models.MigrationOperation{
 				Type:       models.AddColumn,
 				EntityName: change.EntityName,
 				Details: models.AddColumnOperation{
-					TableName: change.EntityName, 
+					TableName: tableNameFor(change.EntityName, entityModels),
 					Column: models.ColumnDefinition{
 						Name:         fieldSnapshot.ColumnName,
-						Type:         driver.MapGoTypeToSQL(fieldSnapshot.Type),
+						Type:         columnType(driver, fieldSnapshot.Type, fieldSnapshot.MaxLength, fieldSnapshot.Collation),
 						IsNullable:   fieldSnapshot.IsNullable,
 						IsPrimary:    fieldSnapshot.IsPrimary,
 						IsUnique:     fieldSnapshot.IsUnique,
+						IsIdentity:   fieldSnapshot.IsIdentity,
 						DefaultValue: fieldSnapshot.DefaultValue,
+						Computed:     snapshotComputedClause(fieldSnapshot),
 					},
 				},
 			}
 			operations = append(operations, operation)
+			if fieldSnapshot.TextSearch != "" {
+				tableName := tableNameFor(change.EntityName, entityModels)
+				operations = append(operations, addIndexOperation(change.EntityName, tableName,
+					models.TextSearchIndex(mm.context.ConstraintNaming(), tableName, fieldSnapshot.ColumnName, fieldSnapshot.TextSearch)))
+			}
+			if fieldSnapshot.Trigram {
+				tableName := tableNameFor(change.EntityName, entityModels)
+				operations = append(operations, addIndexOperation(change.EntityName, tableName,
+					models.TrigramIndex(mm.context.ConstraintNaming(), tableName, fieldSnapshot.ColumnName)))
+			}
+
+		case models.FieldModified:
+			fields := change.Details.(models.FieldComparison)
+			operations = append(operations, fieldModificationOperations(change.EntityName, tableNameFor(change.EntityName, entityModels), fields, driver, mm.context.ConstraintNaming())...)
 
 		case models.FieldRenamed:
 			fieldRename := change.Details.(models.FieldRename)
@@ -1175,9 +1666,9 @@ func (mm *MigrationManager) generateOperationsFromComparison(comparison *models.
 				Type:       models.RenameColumn,
 				EntityName: change.EntityName,
 				Details: models.RenameColumnOperation{
-					TableName: change.EntityName, 
-					OldName:   fieldRename.OldName,
-					NewName:   fieldRename.NewName,
+					TableName: tableNameFor(change.EntityName, entityModels),
+					OldName:   mm.context.NamingConvention().Convert(fieldRename.OldName),
+					NewName:   fieldRename.Field.ColumnName,
 				},
 			}
 			operations = append(operations, operation)
@@ -1188,28 +1679,116 @@ func (mm *MigrationManager) generateOperationsFromComparison(comparison *models.
 				Type:       models.DropColumn,
 				EntityName: change.EntityName,
 				Details: models.DropColumnOperation{
-					TableName:  change.EntityName, // Use Pascal case
+					TableName:  tableNameFor(change.EntityName, entityModels),
 					ColumnName: fieldSnapshot.ColumnName,
 				},
 			}
 			operations = append(operations, operation)
+
+		case models.IndexAdded:
+			index := change.Details.(models.IndexSnapshot)
+			operation := addIndexOperation(change.EntityName, tableNameFor(change.EntityName, entityModels), snapshotIndexDefinition(index))
+			if _, replaced := viewCreates[change.EntityName]; replaced {
+				viewCreates[change.EntityName] = append(viewCreates[change.EntityName], operation)
+				continue
+			}
+			operations = append(operations, operation)
+
+		case models.IndexRemoved:
+			index := change.Details.(models.IndexSnapshot)
+			operations = append(operations, dropIndexOperation(change.EntityName, tableNameFor(change.EntityName, entityModels), snapshotIndexDefinition(index)))
+
+		case models.TriggerAdded:
+			operations = append(operations, models.MigrationOperation{
+				Type:       models.CreateTrigger,
+				EntityName: change.EntityName,
+				Details: models.CreateTriggerOperation{
+					TableName: tableNameFor(change.EntityName, entityModels),
+					Trigger:   change.Details.(models.TriggerModel),
+				},
+			})
+
+		case models.TriggerRemoved:
+			operations = append(operations, models.MigrationOperation{
+				Type:       models.DropTrigger,
+				EntityName: change.EntityName,
+				Details: models.DropTriggerOperation{
+					TableName: tableNameFor(change.EntityName, entityModels),
+					Trigger:   change.Details.(models.TriggerModel),
+				},
+			})
+
+		case models.SequenceAdded:
+			sequence := change.Details.(models.SequenceSnapshot)
+			operations = append(operations, models.MigrationOperation{
+				Type: models.CreateSequence,
+				Details: models.CreateSequenceOperation{
+					SequenceName: sequence.Name,
+					IncrementBy:  sequence.IncrementBy,
+					StartWith:    sequence.StartWith,
+				},
+			})
+
+		case models.SequenceModified:
+			sequences := change.Details.(models.SequenceComparison)
+			operations = append(operations, models.MigrationOperation{
+				Type: models.AlterSequence,
+				Details: models.AlterSequenceOperation{
+					SequenceName:   sequences.New.Name,
+					IncrementBy:    sequences.New.IncrementBy,
+					OldIncrementBy: sequences.Old.IncrementBy,
+				},
+			})
+
+		case models.SequenceRemoved:
+			sequence := change.Details.(models.SequenceSnapshot)
+			operations = append(operations, models.MigrationOperation{
+				Type: models.DropSequence,
+				Details: models.DropSequenceOperation{
+					SequenceName: sequence.Name,
+					IncrementBy:  sequence.IncrementBy,
+					StartWith:    sequence.StartWith,
+				},
+			})
 		}
 	}
 
-	return operations, nil
+	if len(viewDrops) > 0 {
+		operations = append(viewDrops, operations...)
+	}
+	for _, entityModel := range mm.sortEntitiesByDependencies(entityModels) {
+		operations = append(operations, viewCreates[entityModel.Name]...)
+	}
+
+	return withExtensions(operations), nil
 }
 
 func (mm *MigrationManager) createTableOperationFromSnapshot(entitySnapshot models.EntitySnapshot, driver drivers.DatabaseDriver, entityModels map[string]*models.EntityModel) models.MigrationOperation {
 	var columns []models.ColumnDefinition
+	foreignKeys := make(map[string]*models.ForeignKeyReference)
+	for _, entity := range entityModels {
+		if entity.Name == entitySnapshot.Name {
+			foreignKeys = mm.foreignKeyReferences(entity, entityModels)
+		}
+	}
 
 	for _, field := range entitySnapshot.Fields {
+		if mm.isNavigation(entitySnapshot.Name, field.Name, entityModels) {
+			continue
+		}
 		column := models.ColumnDefinition{
 			Name:         field.ColumnName,
-			Type:         driver.MapGoTypeToSQL(field.Type),
+			Type:         columnType(driver, field.Type, field.MaxLength, field.Collation),
 			IsNullable:   field.IsNullable,
 			IsPrimary:    field.IsPrimary,
 			IsUnique:     field.IsUnique,
+			IsIdentity:   field.IsIdentity,
 			DefaultValue: field.DefaultValue,
+			Computed:     snapshotComputedClause(field),
+			References:   foreignKeys[field.ColumnName],
+		}
+		if column.References == nil && strings.Contains(field.Type, "uuid.UUID") {
+			column.References = mm.parseForeignKeyFromFieldName(field.Name, entityModels)
 		}
 		columns = append(columns, column)
 	}
@@ -1220,10 +1799,112 @@ func (mm *MigrationManager) createTableOperationFromSnapshot(entitySnapshot mode
 		Details: models.CreateTableOperation{
 			TableName: entitySnapshot.TableName,
 			Columns:   columns,
+			Partition: entitySnapshot.Partition,
 		},
 	}
 }
 
+// fieldModificationOperations alters a column whose length or collation changed and replaces its
+// full-text index when its text search configuration changed; other changes need hand-written migrations
+func fieldModificationOperations(entityName, tableName string, fields models.FieldComparison, driver drivers.DatabaseDriver, naming models.ConstraintNaming) []models.MigrationOperation {
+	var operations []models.MigrationOperation
+	oldField, newField := fields.Old, fields.New
+
+	if oldField.MaxLength != newField.MaxLength || oldField.Collation != newField.Collation {
+		operations = append(operations, models.MigrationOperation{
+			Type:       models.ModifyColumn,
+			EntityName: entityName,
+			Details: models.ModifyColumnOperation{
+				TableName: tableName,
+				Column: models.ColumnDefinition{
+					Name: newField.ColumnName,
+					Type: columnType(driver, newField.Type, newField.MaxLength, newField.Collation),
+				},
+				OldColumn: models.ColumnDefinition{
+					Name: oldField.ColumnName,
+					Type: columnType(driver, oldField.Type, oldField.MaxLength, oldField.Collation),
+				},
+			},
+		})
+	}
+
+	if oldField.TextSearch != newField.TextSearch {
+		if oldField.TextSearch != "" {
+			operations = append(operations, dropIndexOperation(entityName, tableName,
+				models.TextSearchIndex(naming, tableName, oldField.ColumnName, oldField.TextSearch)))
+		}
+		if newField.TextSearch != "" {
+			operations = append(operations, addIndexOperation(entityName, tableName,
+				models.TextSearchIndex(naming, tableName, newField.ColumnName, newField.TextSearch)))
+		}
+	}
+	if oldField.Trigram != newField.Trigram {
+		if oldField.Trigram {
+			operations = append(operations, dropIndexOperation(entityName, tableName,
+				models.TrigramIndex(naming, tableName, oldField.ColumnName)))
+		} else {
+			operations = append(operations, addIndexOperation(entityName, tableName,
+				models.TrigramIndex(naming, tableName, newField.ColumnName)))
+		}
+	}
+	return operations
+}
+
+// withExtensions puts the creation of the extensions the operations' indexes need in front of them
+func withExtensions(operations []models.MigrationOperation) []models.MigrationOperation {
+	for _, op := range operations {
+		if indexOp, ok := op.Details.(models.AddIndexOperation); ok && strings.HasSuffix(indexOp.Index.Expression, " gin_trgm_ops") {
+			extension := models.MigrationOperation{
+				Type:    models.CreateExtension,
+				Details: models.CreateExtensionOperation{Name: models.TrigramExtension},
+			}
+			return append([]models.MigrationOperation{extension}, operations...)
+		}
+	}
+	return operations
+}
+
+// sortedFieldSnapshots returns the fields ordered by name, so generated migrations are stable
+func sortedFieldSnapshots(fields map[string]models.FieldSnapshot) []models.FieldSnapshot {
+	sorted := make([]models.FieldSnapshot, 0, len(fields))
+	for _, field := range fields {
+		sorted = append(sorted, field)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return sorted
+}
+
+// snapshotComputedClause returns the GENERATED ALWAYS AS clause of a computed column, or ""
+func snapshotComputedClause(field models.FieldSnapshot) string {
+	if field.ComputedSql == "" {
+		return ""
+	}
+	return models.ComputedColumnClause(field.ComputedSql, field.IsStored)
+}
+
+// primaryKeyColumn returns the entity's key column, falling back to the Id/ID field's column
+func primaryKeyColumn(entity *models.EntityModel) string {
+	if len(entity.PrimaryKey) > 0 {
+		return entity.PrimaryKey[0]
+	}
+	for _, name := range []string{"Id", "ID"} {
+		if field, exists := entity.Fields[name]; exists {
+			return field.ColumnName
+		}
+	}
+	return "Id"
+}
+
+// tableNameFor returns the table an entity maps to under the context's naming convention
+func tableNameFor(entityName string, entityModels map[string]*models.EntityModel) string {
+	for _, entity := range entityModels {
+		if entity.Name == entityName {
+			return entity.TableName
+		}
+	}
+	return entityName
+}
+
 func toSnakeCase(str string) string {
 	var result strings.Builder
 	for i, r := range str {
@@ -1235,40 +1916,88 @@ func toSnakeCase(str string) string {
 	return strings.ToLower(result.String())
 }
 
-// parseForeignKeyFromTags extracts foreign key information from GORM tags
-func (mm *MigrationManager) parseForeignKeyFromTags(tags map[string]string, entityName string) *models.ForeignKeyReference {
-	// Look for navigation properties in related entities that reference this field
-	// This is a simplified approach - in practice we'd need to analyze all entities to find relationships
-	
-	entityModels := mm.context.GetEntityModels()
-	for _, relatedEntity := range entityModels {
-		for _, field := range relatedEntity.Fields {
-			if len(field.Tags) > 0 {
-				// Check if this field has a foreignKey tag pointing to our entity
-				if foreignKeyValue, hasForeignKey := field.Tags["foreignKey"]; hasForeignKey {
-					if foreignKeyValue != "" {
-						// Check if this foreign key refers to a field in our current entity
-						for _, ourEntity := range entityModels {
-							if ourEntity.Name == entityName {
-								for _, ourField := range ourEntity.Fields {
-									if ourField.ColumnName == foreignKeyValue {
-										return &models.ForeignKeyReference{
-											ReferencedTable:  relatedEntity.TableName,
-											ReferencedColumn: "Id", // Most foreign keys reference the Id field
-											OnDelete:         "CASCADE",
-											OnUpdate:         "CASCADE",
-										}
-									}
-								}
-							}
-						}
-					}
-				}
+// foreignKeyReferences resolves the foreign keys held by the entity's columns, keyed by column name, from
+// the context's foreign keys: those declared with HasForeignKey and those of navigations
+func (mm *MigrationManager) foreignKeyReferences(entity *models.EntityModel, entityModels map[string]*models.EntityModel) map[string]*models.ForeignKeyReference {
+	references := make(map[string]*models.ForeignKeyReference)
+	for _, foreignKey := range mm.context.ForeignKeys() {
+		if foreignKey.Dependent.Name != entity.Name {
+			continue
+		}
+		column, target := foreignKey.Field.ColumnName, foreignKey.PrincipalField.ColumnName
+		if !foreignKey.ReferencesKey && !isUniqueColumn(foreignKey.Principal, target) {
+			fmt.Printf("Warning: foreign key %s.%s references %s.%s, which is neither the key nor unique\n",
+				entity.TableName, column, foreignKey.Principal.TableName, target)
+		}
+		references[column] = &models.ForeignKeyReference{
+			ReferencedTable:  foreignKey.Principal.TableName,
+			ReferencedColumn: target,
+			OnDelete:         string(foreignKey.OnDelete),
+			OnUpdate:         string(foreignKey.OnUpdate),
+			ConstraintName:   foreignKey.ConstraintName,
+		}
+	}
+
+	for _, relationship := range entity.Relationships {
+		if field, exists := entity.Fields[relationship.ForeignKey]; exists && references[field.ColumnName] == nil {
+			target := relationship.Principal
+			if relationship.PrincipalKey != "" {
+				target += "." + relationship.PrincipalKey
 			}
+			fmt.Printf("Warning: foreign key %s.%s references %s, which is not registered\n", entity.Name, relationship.ForeignKey, target)
 		}
 	}
-	
-	return nil
+	return references
+}
+
+// entitySchema returns the schema GORM parses for the entity, or nil when it can't be parsed
+func (mm *MigrationManager) entitySchema(entity *models.EntityModel) *schema.Schema {
+	stmt := &gorm.Statement{DB: mm.context.GetDB()}
+	if err := stmt.Parse(reflect.New(entity.Type).Interface()); err != nil {
+		return nil
+	}
+	return stmt.Schema
+}
+
+// isNavigation reports whether the field is a navigation to related entities rather than a column
+func (mm *MigrationManager) isNavigation(entityName, fieldName string, entityModels map[string]*models.EntityModel) bool {
+	for _, entity := range entityModels {
+		if entity.Name != entityName {
+			continue
+		}
+		if entitySchema := mm.entitySchema(entity); entitySchema != nil {
+			_, isRelationship := entitySchema.Relationships.Relations[fieldName]
+			return isRelationship
+		}
+	}
+	return false
+}
+
+// referentialActions returns the ON DELETE/ON UPDATE clauses of a foreign key, empty for the database default
+func referentialActions(reference *models.ForeignKeyReference) string {
+	var actions string
+	if reference.OnDelete != "" {
+		actions += " ON DELETE " + reference.OnDelete
+	}
+	if reference.OnUpdate != "" {
+		actions += " ON UPDATE " + reference.OnUpdate
+	}
+	return actions
+}
+
+// isUniqueColumn reports whether the column is unique on its own, by tag or by a single-column unique index
+func isUniqueColumn(entity *models.EntityModel, column string) bool {
+	for _, field := range entity.Fields {
+		if field.ColumnName == column && field.IsUnique {
+			return true
+		}
+	}
+	for _, index := range models.EntityIndexes(entity) {
+		if index.IsUnique && index.Filter == "" && len(index.Columns) == 1 && index.Columns[0] == column {
+			return true
+		}
+	}
+	return false
 }
 
 // parseForeignKeyFromFieldName checks field names for common foreign key patterns dynamically
@@ -1301,10 +2030,8 @@ func (mm *MigrationManager) parseForeignKeyFromFieldName(fieldName string, entit
 			expectedFieldName := referencedEntity.Name + "Id"
 			if strings.EqualFold(fieldName, expectedFieldName) {
 				return &models.ForeignKeyReference{
-					ReferencedTable:  referencedEntity.Name, 
-					ReferencedColumn: "Id",
-					OnDelete:         "CASCADE",
-					OnUpdate:         "CASCADE",
+					ReferencedTable:  referencedEntity.TableName,
+					ReferencedColumn: primaryKeyColumn(referencedEntity),
 				}
 			}
 		}
@@ -1329,10 +2056,8 @@ func (mm *MigrationManager) parseForeignKeyFromFieldName(fieldName string, entit
 		for _, specialCase := range specialCases {
 			if fieldNameLower == specialCase {
 				return &models.ForeignKeyReference{
-					ReferencedTable:  userLikeEntity.Name,
-					ReferencedColumn: "Id",
-					OnDelete:         "CASCADE", 
-					OnUpdate:         "CASCADE",
+					ReferencedTable:  userLikeEntity.TableName,
+					ReferencedColumn: primaryKeyColumn(userLikeEntity),
 				}
 			}
 		}
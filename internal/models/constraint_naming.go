@@ -0,0 +1,66 @@
+package models
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxIdentifierLength is PostgreSQL's identifier limit; the server silently truncates longer names, so
+// two long names sharing a prefix would collide
+const MaxIdentifierLength = 63
+
+// ConstraintNaming names the indexes and constraints that migrations and AutoMigrate generate
+// Names must be stable: a changed name makes the next migration drop and recreate the index
+type ConstraintNaming interface {
+	IndexName(table string, columns []string) string
+	UniqueName(table, column string) string
+	ForeignKeyName(table, column string) string
+	CheckName(table, column string) string
+}
+
+// DefaultConstraintNaming names indexes idx_<table>_<columns>, unique constraints uni_<table>_<column>,
+// foreign keys fk_<table>_<column> and checks chk_<table>_<column>, shortened to MaxLength
+type DefaultConstraintNaming struct {
+	MaxLength int // 0 for MaxIdentifierLength
+}
+
+func (n DefaultConstraintNaming) IndexName(table string, columns []string) string {
+	return ShortenIdentifier("idx_"+table+"_"+strings.Join(columns, "_"), n.MaxLength)
+}
+
+func (n DefaultConstraintNaming) UniqueName(table, column string) string {
+	return ShortenIdentifier("uni_"+table+"_"+column, n.MaxLength)
+}
+
+func (n DefaultConstraintNaming) ForeignKeyName(table, column string) string {
+	return ShortenIdentifier("fk_"+table+"_"+column, n.MaxLength)
+}
+
+func (n DefaultConstraintNaming) CheckName(table, column string) string {
+	return ShortenIdentifier("chk_"+table+"_"+column, n.MaxLength)
+}
+
+// ShortenIdentifier keeps a name within maxLength characters (0 for MaxIdentifierLength) by replacing its
+// tail with 8 hex characters of the whole name's SHA-1, as GORM does, so shortened names stay unique and
+// the same between runs
+func ShortenIdentifier(name string, maxLength int) string {
+	if maxLength <= 0 {
+		maxLength = MaxIdentifierLength
+	}
+	if utf8.RuneCountInString(name) <= maxLength {
+		return name
+	}
+	sum := sha1.Sum([]byte(name))
+	runes := []rune(name)
+	return string(runes[:maxLength-8]) + hex.EncodeToString(sum[:])[:8]
+}
+
+// Naming returns the entity's constraint naming, DefaultConstraintNaming unless the context set one
+func (e *EntityModel) Naming() ConstraintNaming {
+	if e.ConstraintNaming != nil {
+		return e.ConstraintNaming
+	}
+	return DefaultConstraintNaming{}
+}
@@ -0,0 +1,137 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SortEntitiesByDependencies orders entities so referenced (parent) entities come before their dependents
+// Dependencies are detected from HasForeignKey declarations, GORM foreignKey tags on navigations and <Entity>Id
+// UUID naming conventions.
+// On a dependency cycle the entities are returned in name order together with the cycle error.
+func SortEntitiesByDependencies(entityModels map[string]*EntityModel) ([]*EntityModel, error) {
+	// Build dependency graph from foreign key relationships
+	dependencies := make(map[string][]string) // entity -> list of entities it depends on
+	allEntities := make(map[string]*EntityModel)
+	
+	// Initialize maps
+	for _, entity := range entityModels {
+		allEntities[entity.Name] = entity
+		dependencies[entity.Name] = []string{}
+	}
+	
+	// Visit entities in name order so the result is stable between runs
+	var entityNames []string
+	for name := range allEntities {
+		entityNames = append(entityNames, name)
+	}
+	sort.Strings(entityNames)
+	
+	// Analyze each entity for foreign key dependencies
+	for _, entity := range entityModels {
+		// Foreign keys declared with HasForeignKey; a self reference needs no ordering
+		for _, relationship := range entity.Relationships {
+			if _, exists := allEntities[relationship.Principal]; exists && relationship.Principal != entity.Name {
+				dependencies[entity.Name] = appendDependency(dependencies[entity.Name], relationship.Principal)
+			}
+		}
+
+		// Materialized views come after the entities they read
+		for _, dependency := range ViewDependencies(entity, allEntities) {
+			dependencies[entity.Name] = appendDependency(dependencies[entity.Name], dependency)
+		}
+
+		for _, field := range entity.Fields {
+			// Check if field is a navigation with a GORM foreignKey tag; like GORM, it's a belongs-to when the
+			// foreign key field is on this entity, otherwise the referenced entity holds it and depends on us
+			if foreignKey, exists := lookupTag(field.Tags, "foreignKey"); exists && foreignKey != "" {
+				// The field type names the referenced entity: []*pkg.Entity -> Entity
+				fieldType := strings.TrimLeft(field.Type, "[]*")
+				fieldType = fieldType[strings.LastIndex(fieldType, ".")+1:]
+				if otherEntity, exists := allEntities[fieldType]; exists && otherEntity.Name != entity.Name {
+					if _, belongsTo := entity.Fields[foreignKey]; belongsTo {
+						dependencies[entity.Name] = appendDependency(dependencies[entity.Name], otherEntity.Name)
+					} else if _, hasOne := otherEntity.Fields[foreignKey]; hasOne {
+						dependencies[otherEntity.Name] = appendDependency(dependencies[otherEntity.Name], entity.Name)
+					}
+				}
+			}
+			
+			// Also check for UUID fields that follow naming conventions (e.g., UserId, BucketId)
+			if strings.Contains(field.Type, "uuid.UUID") && strings.HasSuffix(field.Name, "Id") {
+				// Extract potential entity name (e.g., UserId -> User, BucketId -> Bucket)
+				potentialEntityName := strings.TrimSuffix(field.Name, "Id")
+				if referencedEntity, exists := allEntities[potentialEntityName]; exists && referencedEntity.Name != entity.Name {
+					// Avoid duplicates
+					found := false
+					for _, dep := range dependencies[entity.Name] {
+						if dep == potentialEntityName {
+							found = true
+							break
+						}
+					}
+					if !found {
+						dependencies[entity.Name] = append(dependencies[entity.Name], potentialEntityName)
+					}
+				}
+			}
+		}
+	}
+	
+	// Perform topological sort
+	result := []*EntityModel{}
+	visited := make(map[string]bool)
+	visiting := make(map[string]bool)
+	
+	var visit func(string) error
+	visit = func(entityName string) error {
+		if visiting[entityName] {
+			return fmt.Errorf("circular dependency detected involving entity: %s", entityName)
+		}
+		if visited[entityName] {
+			return nil
+		}
+		
+		visiting[entityName] = true
+		
+		// Visit all dependencies first
+		for _, dep := range dependencies[entityName] {
+			if _, exists := allEntities[dep]; exists {
+				if err := visit(dep); err != nil {
+					return err
+				}
+			}
+		}
+		
+		visiting[entityName] = false
+		visited[entityName] = true
+		result = append(result, allEntities[entityName])
+		
+		return nil
+	}
+	
+	// Visit all entities
+	for _, entityName := range entityNames {
+		if !visited[entityName] {
+			if err := visit(entityName); err != nil {
+				result = []*EntityModel{}
+				for _, name := range entityNames {
+					result = append(result, allEntities[name])
+				}
+				return result, err
+			}
+		}
+	}
+	
+	return result, nil
+}
+
+func appendDependency(dependencies []string, name string) []string {
+	for _, dependency := range dependencies {
+		if dependency == name {
+			return dependencies
+		}
+	}
+	return append(dependencies, name)
+}
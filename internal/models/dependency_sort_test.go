@@ -0,0 +1,120 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+// entity builds a model with fields given as name -> type, each with an optional gorm tag after a "|"
+func entity(name string, fields map[string]string, relationships ...*RelationshipModel) *EntityModel {
+	model := &EntityModel{Name: name, TableName: strings.ToLower(name) + "s", Fields: map[string]FieldModel{}, Relationships: relationships}
+	for fieldName, definition := range fields {
+		fieldType, tag, _ := strings.Cut(definition, "|")
+		tags := map[string]string{}
+		parseTags(tag, tags)
+		model.Fields[fieldName] = FieldModel{Name: fieldName, Type: fieldType, Tags: tags}
+	}
+	return model
+}
+
+func sortedNames(t *testing.T, entities ...*EntityModel) ([]string, error) {
+	t.Helper()
+	byName := make(map[string]*EntityModel, len(entities))
+	for _, model := range entities {
+		byName[model.Name] = model
+	}
+	sorted, err := SortEntitiesByDependencies(byName)
+	names := make([]string, len(sorted))
+	for i, model := range sorted {
+		names[i] = model.Name
+	}
+	return names, err
+}
+
+func TestSortEntitiesByDependencies(t *testing.T) {
+	tests := []struct {
+		name     string
+		entities []*EntityModel
+		want     string
+	}{
+		{
+			name: "declared foreign keys",
+			entities: []*EntityModel{
+				entity("Account", map[string]string{"Id": "int"}),
+				entity("Invoice", map[string]string{"Id": "int", "AccountID": "int", "ParentID": "int"},
+					&RelationshipModel{ForeignKey: "AccountID", Principal: "Zone"},
+					&RelationshipModel{ForeignKey: "ParentID", Principal: "Invoice"}), // Self reference
+				entity("Zone", map[string]string{"Id": "int"}),
+			},
+			want: "Account Zone Invoice",
+		},
+		{
+			name: "belongs-to navigation",
+			entities: []*EntityModel{
+				entity("Apple", map[string]string{"Id": "int", "TreeId": "int", "Tree": "*models.Tree|foreignKey:TreeId"}),
+				entity("Tree", map[string]string{"Id": "int"}),
+			},
+			want: "Tree Apple",
+		},
+		{
+			name: "has-many navigation on the principal",
+			entities: []*EntityModel{
+				entity("Author", map[string]string{"Id": "int", "Posts": "[]*models.Post|foreignKey:AuthorId"}),
+				entity("Post", map[string]string{"Id": "int", "AuthorId": "int"}),
+				entity("Comment", map[string]string{"Id": "int", "PostId": "int", "Post": "models.Post|foreignKey:PostId"}),
+			},
+			want: "Author Post Comment",
+		},
+		{
+			name: "uuid naming convention",
+			entities: []*EntityModel{
+				entity("Bucket", map[string]string{"Id": "uuid.UUID", "OwnerId": "uuid.UUID"}),
+				entity("File", map[string]string{"Id": "uuid.UUID", "BucketId": "uuid.UUID"}),
+				entity("Owner", map[string]string{"Id": "uuid.UUID"}),
+			},
+			want: "Owner Bucket File",
+		},
+		{
+			name: "materialized view",
+			entities: []*EntityModel{
+				func() *EntityModel {
+					model := entity("AccountTotal", map[string]string{"Total": "int"})
+					model.View = &ViewModel{Query: `SELECT SUM("Amount") AS "Total" FROM "zpayments"`}
+					return model
+				}(),
+				func() *EntityModel {
+					model := entity("ZPayment", map[string]string{"Id": "int", "Amount": "int"})
+					model.TableName = "zpayments"
+					return model
+				}(),
+			},
+			want: "ZPayment AccountTotal",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names, err := sortedNames(t, tt.entities...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(names, " "); got != tt.want {
+				t.Errorf("sorted %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortEntitiesByDependenciesReportsCycles(t *testing.T) {
+	names, err := sortedNames(t,
+		entity("Egg", map[string]string{"Id": "int", "HenID": "int"}, &RelationshipModel{ForeignKey: "HenID", Principal: "Hen"}),
+		entity("Hen", map[string]string{"Id": "int", "EggID": "int"}, &RelationshipModel{ForeignKey: "EggID", Principal: "Egg"}),
+		entity("Barn", map[string]string{"Id": "int"}),
+	)
+	if err == nil || !strings.Contains(err.Error(), "circular dependency") {
+		t.Errorf("error = %v, want the cycle reported", err)
+	}
+	if got := strings.Join(names, " "); got != "Barn Egg Hen" {
+		t.Errorf("sorted %s on a cycle, want name order", got)
+	}
+}
@@ -0,0 +1,289 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DiagramFormat selects the output of ModelSnapshot.Diagram
+type DiagramFormat string
+
+const (
+	DiagramMermaid DiagramFormat = "mermaid" // Mermaid erDiagram, renders on GitHub
+	DiagramDot     DiagramFormat = "dot"     // Graphviz
+	DiagramDBML    DiagramFormat = "dbml"    // dbdiagram.io
+)
+
+// diagramRelation is a foreign key from one entity to another
+type diagramRelation struct {
+	From       EntitySnapshot
+	FromColumn string
+	To         EntitySnapshot
+	ToColumn   string
+	Nullable   bool
+}
+
+// Diagram renders the model as an entity-relationship diagram with keys, foreign keys and indexes
+// Foreign keys follow the <Entity>Id convention also used by migrations
+func (s *ModelSnapshot) Diagram(format DiagramFormat) (string, error) {
+	entities := s.sortedEntities()
+	relations := s.relations(entities)
+
+	switch format {
+	case DiagramMermaid:
+		return mermaidDiagram(entities, relations), nil
+	case DiagramDot:
+		return dotDiagram(entities, relations), nil
+	case DiagramDBML:
+		return dbmlDiagram(entities, relations), nil
+	default:
+		return "", fmt.Errorf("unknown diagram format %q (use mermaid, dot or dbml)", format)
+	}
+}
+
+func (s *ModelSnapshot) sortedEntities() []EntitySnapshot {
+	entities := make([]EntitySnapshot, 0, len(s.Entities))
+	for _, entity := range s.Entities {
+		entities = append(entities, entity)
+	}
+	sort.Slice(entities, func(i, j int) bool { return entities[i].Name < entities[j].Name })
+	return entities
+}
+
+// sortedFields lists primary keys first, then fields by name
+func sortedFields(entity EntitySnapshot) []FieldSnapshot {
+	fields := make([]FieldSnapshot, 0, len(entity.Fields))
+	for _, field := range entity.Fields {
+		fields = append(fields, field)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		if fields[i].IsPrimary != fields[j].IsPrimary {
+			return fields[i].IsPrimary
+		}
+		return fields[i].Name < fields[j].Name
+	})
+	return fields
+}
+
+func primaryKeyField(entity EntitySnapshot) (FieldSnapshot, bool) {
+	for _, field := range sortedFields(entity) {
+		if field.IsPrimary {
+			return field, true
+		}
+	}
+	return FieldSnapshot{}, false
+}
+
+func (s *ModelSnapshot) relations(entities []EntitySnapshot) []diagramRelation {
+	byName := make(map[string]EntitySnapshot, len(entities))
+	for _, entity := range entities {
+		byName[strings.ToLower(entity.Name)] = entity
+	}
+
+	var relations []diagramRelation
+	for _, entity := range entities {
+		for _, field := range sortedFields(entity) {
+			if field.IsPrimary {
+				continue
+			}
+			name := strings.ToLower(field.Name)
+			if !strings.HasSuffix(name, "id") || len(name) <= 2 {
+				continue
+			}
+			target, exists := byName[strings.TrimSuffix(name, "id")]
+			if !exists {
+				continue
+			}
+			key, hasKey := primaryKeyField(target)
+			if !hasKey {
+				continue
+			}
+			relations = append(relations, diagramRelation{
+				From:       entity,
+				FromColumn: field.ColumnName,
+				To:         target,
+				ToColumn:   key.ColumnName,
+				Nullable:   field.IsNullable,
+			})
+		}
+	}
+	return relations
+}
+
+// diagramIndexes returns the snapshot's indexes; snapshots saved before indexes were recorded fall back
+// to single-column indexes declared with index/uniqueIndex tags
+func diagramIndexes(entity EntitySnapshot) []IndexSnapshot {
+	if len(entity.Indexes) > 0 {
+		return entity.Indexes
+	}
+	var indexes []IndexSnapshot
+	for _, field := range sortedFields(entity) {
+		if _, unique := lookupTag(field.Tags, "uniqueIndex"); unique {
+			indexes = append(indexes, IndexSnapshot{Name: DefaultConstraintNaming{}.IndexName(entity.TableName, []string{field.ColumnName}), Columns: []string{field.ColumnName}, IsUnique: true})
+		} else if _, index := lookupTag(field.Tags, "index"); index {
+			indexes = append(indexes, IndexSnapshot{Name: DefaultConstraintNaming{}.IndexName(entity.TableName, []string{field.ColumnName}), Columns: []string{field.ColumnName}})
+		}
+	}
+	return indexes
+}
+
+// diagramType shortens a Go type for diagram attributes: *uuid.UUID -> uuid.UUID
+func diagramType(goType string) string {
+	goType = strings.TrimLeft(goType, "*")
+	if goType == "" {
+		return "unknown"
+	}
+	return goType
+}
+
+func mermaidDiagram(entities []EntitySnapshot, relations []diagramRelation) string {
+	// Mermaid attribute types must be single identifiers
+	typeName := strings.NewReplacer(".", "_", "[]", "array_", "*", "", " ", "_", "[", "_", "]", "_")
+
+	var b strings.Builder
+	b.WriteString("erDiagram\n")
+	for _, entity := range entities {
+		fmt.Fprintf(&b, "    %s {\n", entity.Name)
+		for _, field := range sortedFields(entity) {
+			var keys []string
+			if field.IsPrimary {
+				keys = append(keys, "PK")
+			}
+			for _, relation := range relations {
+				if relation.From.Name == entity.Name && relation.FromColumn == field.ColumnName {
+					keys = append(keys, "FK")
+					break
+				}
+			}
+			if field.IsUnique {
+				keys = append(keys, "UK")
+			}
+			line := fmt.Sprintf("        %s %s", typeName.Replace(diagramType(field.Type)), field.ColumnName)
+			if len(keys) > 0 {
+				line += " " + strings.Join(keys, ",")
+			}
+			b.WriteString(line + "\n")
+		}
+		b.WriteString("    }\n")
+	}
+	for _, relation := range relations {
+		// Many children to one parent; a nullable key makes the parent optional
+		parent := "||"
+		if relation.Nullable {
+			parent = "o|"
+		}
+		fmt.Fprintf(&b, "    %s }o--%s %s : %q\n", relation.From.Name, parent, relation.To.Name, relation.FromColumn)
+	}
+	return b.String()
+}
+
+func dotDiagram(entities []EntitySnapshot, relations []diagramRelation) string {
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "{", `\{`, "}", `\}`, "|", `\|`, "<", `\<`, ">", `\>`)
+
+	var b strings.Builder
+	b.WriteString("digraph model {\n")
+	b.WriteString("    rankdir=LR;\n")
+	b.WriteString("    node [shape=record, fontname=\"Helvetica\"];\n")
+	for _, entity := range entities {
+		var rows []string
+		for _, field := range sortedFields(entity) {
+			row := fmt.Sprintf("%s : %s", field.ColumnName, diagramType(field.Type))
+			if field.IsPrimary {
+				row += " (PK)"
+			} else if field.IsUnique {
+				row += " (UK)"
+			}
+			rows = append(rows, escape.Replace(row)+`\l`)
+		}
+		for _, index := range diagramIndexes(entity) {
+			kind := "index"
+			if index.IsUnique {
+				kind = "unique"
+			}
+			target := index.Expression
+			if target == "" {
+				target = strings.Join(index.Columns, ", ")
+			}
+			row := fmt.Sprintf("%s %s(%s)", kind, index.Name, target)
+			if index.Filter != "" {
+				row += " where " + index.Filter
+			}
+			rows = append(rows, escape.Replace(row)+`\l`)
+		}
+		label := escape.Replace(entity.Name)
+		if entity.TableName != "" && entity.TableName != entity.Name {
+			label += escape.Replace(fmt.Sprintf(" (%s)", entity.TableName))
+		}
+		fmt.Fprintf(&b, "    %q [label=\"{%s|%s}\"];\n", entity.Name, label, strings.Join(rows, ""))
+	}
+	for _, relation := range relations {
+		fmt.Fprintf(&b, "    %q -> %q [label=%q];\n", relation.From.Name, relation.To.Name, relation.FromColumn)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func dbmlDiagram(entities []EntitySnapshot, relations []diagramRelation) string {
+	var b strings.Builder
+	for i, entity := range entities {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "Table %q {\n", entity.TableName)
+		for _, field := range sortedFields(entity) {
+			var settings []string
+			if field.IsPrimary {
+				settings = append(settings, "pk")
+			}
+			if field.IsUnique {
+				settings = append(settings, "unique")
+			}
+			if !field.IsNullable && !field.IsPrimary {
+				settings = append(settings, "not null")
+			}
+			if field.IsIdentity {
+				settings = append(settings, "increment")
+			}
+			if field.DefaultValue != nil {
+				settings = append(settings, fmt.Sprintf("default: `%s`", *field.DefaultValue))
+			}
+			line := fmt.Sprintf("  %q %q", field.ColumnName, diagramType(field.Type))
+			if len(settings) > 0 {
+				line += " [" + strings.Join(settings, ", ") + "]"
+			}
+			b.WriteString(line + "\n")
+		}
+
+		if indexes := diagramIndexes(entity); len(indexes) > 0 {
+			b.WriteString("\n  Indexes {\n")
+			for _, index := range indexes {
+				columns := make([]string, len(index.Columns))
+				for c, column := range index.Columns {
+					columns[c] = fmt.Sprintf("%q", column)
+				}
+				if index.Expression != "" {
+					columns = []string{"`" + index.Expression + "`"}
+				}
+				settings := []string{fmt.Sprintf("name: %q", index.Name)}
+				if index.IsUnique {
+					settings = append([]string{"unique"}, settings...)
+				}
+				if index.Filter != "" {
+					settings = append(settings, fmt.Sprintf("note: %q", "where "+index.Filter))
+				}
+				fmt.Fprintf(&b, "    (%s) [%s]\n", strings.Join(columns, ", "), strings.Join(settings, ", "))
+			}
+			b.WriteString("  }\n")
+		}
+		b.WriteString("}\n")
+	}
+
+	if len(relations) > 0 {
+		b.WriteString("\n")
+	}
+	for _, relation := range relations {
+		fmt.Fprintf(&b, "Ref: %q.%q > %q.%q\n", relation.From.TableName, relation.FromColumn, relation.To.TableName, relation.ToColumn)
+	}
+	return b.String()
+}
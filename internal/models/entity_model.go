@@ -1,8 +1,20 @@
 package models
 
 import (
+	"database/sql/driver"
+	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/shepherrrd/gontext/internal/encryption"
+	"github.com/shepherrrd/gontext/internal/search"
+)
+
+var (
+	timeType   = reflect.TypeOf(time.Time{})
+	valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
 )
 
 type EntityModel struct {
@@ -11,6 +23,34 @@ type EntityModel struct {
 	Type       reflect.Type
 	Fields     map[string]FieldModel
 	PrimaryKey []string
+
+	// TableConfigured reports that TableName was set with ToTable, overriding TableName() and the naming
+	TableConfigured bool
+
+	// Search indexes the entity in a search engine, nil unless configured with Searchable
+	Search *search.Mapping
+
+	// Indexes are the indexes declared with HasIndex; see EntityIndexes for those of tags too
+	Indexes []*IndexModel
+
+	// Relationships are the foreign keys declared with HasForeignKey
+	Relationships []*RelationshipModel
+
+	// ConstraintNaming names the entity's indexes and constraints; see Naming
+	ConstraintNaming ConstraintNaming
+
+	// Partition splits the table into partitions, nil unless configured with PartitionByRange or PartitionByHash
+	Partition *PartitionModel
+
+	// Triggers are the triggers declared with HasTrigger, created by migrations
+	Triggers []*TriggerModel
+
+	// View maps the entity to a materialized view, nil unless configured with ToMaterializedView
+	View *ViewModel
+
+	// IgnoredFields lists the fields that map to no column (gontext:"-" or Ignore), by path for fields
+	// of embedded structs, e.g. "Audit.CreatedBy"
+	IgnoredFields []string
 }
 
 type FieldModel struct {
@@ -24,15 +64,130 @@ type FieldModel struct {
 	IsUnique     bool
 	DefaultValue *string
 	OldName      *string // For column renames
+
+	// Value generation for keys and other generated columns
+	ValueGeneration ValueGenerationStrategy
+	KeyGenerator    KeyGenerator // Go-side generator (ULID, snowflake, ...)
+	HiLoSequence    string       // Sequence backing HiLo allocation
+	HiLoBlockSize   int          // Number of values reserved per sequence round trip
+
+	// DefaultFunc computes a value in Go for added entities that leave the field at its zero value
+	DefaultFunc DefaultValueFunc
+
+	// Encryption encrypts the column at rest, nil for plaintext columns
+	Encryption *encryption.Column
+
+	// IsSensitive masks the values of the column in logged SQL (gontext:"sensitive" or IsSensitive)
+	IsSensitive bool
+
+	// MaxLength makes a string column VARCHAR(MaxLength) instead of TEXT (gorm size tag or HasMaxLength)
+	MaxLength int
+	// Collation is the column's collation (gontext collation tag or UseCollation)
+	Collation string
+	// TextSearchConfig is the PostgreSQL text search configuration the column is searched with; migrations
+	// index to_tsvector(TextSearchConfig, column) with GIN (gontext text_search tag or HasTextSearchConfig)
+	TextSearchConfig string
+	// Trigram indexes the column with GIN gin_trgm_ops for pg_trgm similarity search with WhereSimilar
+	// (gontext trigram tag or HasTrigramIndex)
+	Trigram bool
+
+	// ComputedColumnSql is the expression of a column the database computes from others; computed
+	// columns are never inserted or updated. IsComputedStored stores the value instead of computing it on read
+	ComputedColumnSql string
+	IsComputedStored  bool
+
+	// EmbeddedPath names the embedded struct fields the field is declared in, outermost first; empty
+	// for fields declared on the entity itself
+	EmbeddedPath []string
+}
+
+// ValueGenerationStrategy describes how a column value is produced when an entity is inserted
+type ValueGenerationStrategy int
+
+const (
+	ValueGeneratedNever             ValueGenerationStrategy = iota
+	ValueGeneratedByDatabaseDefault // e.g. uuid DEFAULT gen_random_uuid()
+	ValueGeneratedByIdentity        // serial/bigserial/IDENTITY/AUTO_INCREMENT
+	ValueGeneratedByGenerator       // Go function supplied through the model builder
+	ValueGeneratedByHiLo            // HiLo blocks allocated from a database sequence
+)
+
+// KeyGenerator produces a key value in Go (e.g. a ULID or snowflake ID)
+type KeyGenerator func() (interface{}, error)
+
+// DefaultValueFunc computes a field's default from the entity being inserted (a pointer to the entity)
+type DefaultValueFunc func(entity interface{}) interface{}
+
+// IsDatabaseGenerated reports whether the database produces the value on insert
+func (f FieldModel) IsDatabaseGenerated() bool {
+	return f.ValueGeneration == ValueGeneratedByDatabaseDefault || f.ValueGeneration == ValueGeneratedByIdentity
+}
+
+// IsComputed reports whether the database computes the column from other columns
+func (f FieldModel) IsComputed() bool {
+	return f.ComputedColumnSql != ""
+}
+
+// Path returns the field's name qualified by the embedded structs it is declared in, e.g. "Audit.CreatedAt"
+func (f FieldModel) Path() string {
+	name := f.Name[strings.LastIndex(f.Name, ".")+1:]
+	return strings.Join(append(append([]string(nil), f.EmbeddedPath...), name), ".")
+}
+
+// TextSearchIndex returns the GIN index that serves full-text queries on a column searched with
+// to_tsvector(config, column), named idx_<table>_<column>_fts by the default naming
+func TextSearchIndex(naming ConstraintNaming, table, column, config string) IndexDefinition {
+	return IndexDefinition{
+		Name:       naming.IndexName(table, []string{column, "fts"}),
+		Method:     "GIN",
+		Expression: fmt.Sprintf(`to_tsvector('%s', "%s")`, strings.ReplaceAll(config, "'", "''"), column),
+	}
+}
+
+// TrigramExtension is the PostgreSQL extension trigram indexes and similarity search need
+const TrigramExtension = "pg_trgm"
+
+// TrigramIndex returns the GIN index that serves pg_trgm similarity and LIKE queries on a column, named
+// idx_<table>_<column>_trgm by the default naming
+func TrigramIndex(naming ConstraintNaming, table, column string) IndexDefinition {
+	return IndexDefinition{
+		Name:       naming.IndexName(table, []string{column, "trgm"}),
+		Method:     "GIN",
+		Expression: fmt.Sprintf(`"%s" gin_trgm_ops`, column),
+	}
+}
+
+// ComputedColumnClause returns the column definition suffix of a computed column; PostgreSQL before
+// version 18 supports stored computed columns only
+func ComputedColumnClause(sql string, stored bool) string {
+	if stored {
+		return fmt.Sprintf("GENERATED ALWAYS AS (%s) STORED", sql)
+	}
+	return fmt.Sprintf("GENERATED ALWAYS AS (%s) VIRTUAL", sql)
 }
 
 func NewEntityModel(entityType reflect.Type) *EntityModel {
+	return NewEntityModelWithConvention(entityType, DefaultNaming)
+}
+
+// NewEntityModelWithConvention builds an entity model whose table and column names follow the convention
+// A TableName() method and gorm column tags override the convention
+func NewEntityModelWithConvention(entityType reflect.Type, convention NamingConvention) *EntityModel {
+	return NewEntityModelWithNaming(entityType, convention, nil)
+}
+
+// NewEntityModelWithNaming builds an entity model whose table is named by tables (the convention when nil)
+// and whose columns follow the convention. A TableName() method and gorm column tags override both
+func NewEntityModelWithNaming(entityType reflect.Type, convention NamingConvention, tables TableNaming) *EntityModel {
 	if entityType.Kind() == reflect.Ptr {
 		entityType = entityType.Elem()
 	}
 
 	// Get table name (check for custom TableName method first)
-	tableName := entityType.Name() // Default to struct name
+	tableName := convention.Convert(entityType.Name()) // Default to struct name
+	if tables != nil {
+		tableName = tables.TableName(entityType.Name())
+	}
 	
 	// Create a zero value instance to check for TableName method
 	zeroValue := reflect.New(entityType).Interface()
@@ -47,21 +202,160 @@ func NewEntityModel(entityType reflect.Type) *EntityModel {
 		Fields:    make(map[string]FieldModel),
 	}
 
-	for i := 0; i < entityType.NumField(); i++ {
-		field := entityType.Field(i)
+	addFields(entity, entityType, nil, "", nil, convention)
+
+	return entity
+}
+
+// addFields adds the columns of structType to the entity, flattening embedded structs (anonymous fields
+// and fields tagged gorm:"embedded") the way GORM does: their fields become columns of the entity, named
+// with the embeddedPrefix tag in front. Direct fields are added before embedded ones so, as with Go's field
+// promotion, they keep the plain name when an embedded struct has a field of the same name; the embedded
+// field is then keyed by its path, e.g. "Author.Name"
+func addFields(entity *EntityModel, structType reflect.Type, path []string, prefix string, inherited map[string]string, convention NamingConvention) {
+	var embedded []reflect.StructField
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		if IsEmbeddedField(field) {
+			embedded = append(embedded, field)
+			continue
+		}
 		if field.PkgPath != "" {
 			continue
 		}
+		if isIgnoredField(field) {
+			if field.Tag.Get("gontext") == "-" {
+				entity.IgnoredFields = append(entity.IgnoredFields, strings.Join(append(append([]string(nil), path...), field.Name), "."))
+			}
+			continue
+		}
 
 		fieldModel := parseFieldModel(field)
-		entity.Fields[field.Name] = fieldModel
+		for key, value := range inherited {
+			if _, exists := fieldModel.Tags[key]; !exists {
+				fieldModel.Tags[key] = value
+			}
+		}
+		if column, exists := lookupTag(fieldModel.Tags, "column"); exists && column != "" {
+			fieldModel.ColumnName = prefix + column
+		} else {
+			fieldModel.ColumnName = prefix + convention.Convert(field.Name)
+		}
+
+		key := field.Name
+		if _, taken := entity.Fields[key]; taken && len(path) > 0 {
+			key = strings.Join(append(append([]string(nil), path...), field.Name), ".")
+		}
+		fieldModel.Name = key
+		fieldModel.EmbeddedPath = path
+		entity.Fields[key] = fieldModel
 
 		if fieldModel.IsPrimary {
 			entity.PrimaryKey = append(entity.PrimaryKey, fieldModel.ColumnName)
 		}
 	}
 
-	return entity
+	for _, field := range embedded {
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		tags := make(map[string]string)
+		parseTags(field.Tag.Get("gontext"), tags)
+		parseTags(field.Tag.Get("gorm"), tags)
+		embeddedPrefix, _ := lookupTag(tags, "embeddedPrefix")
+		// Like GORM, the embedding field's other tags (e.g. not null) apply to every embedded column
+		for key, value := range inherited {
+			if _, exists := tags[key]; !exists {
+				tags[key] = value
+			}
+		}
+		for key := range tags {
+			if strings.EqualFold(key, "embedded") || strings.EqualFold(key, "embeddedPrefix") {
+				delete(tags, key)
+			}
+		}
+
+		fieldPath := append(append([]string(nil), path...), field.Name)
+		addFields(entity, fieldType, fieldPath, prefix+embeddedPrefix, tags, convention)
+	}
+}
+
+// isIgnoredField reports whether the field is tagged gontext:"-", or gorm:"-" which GORM already ignores
+func isIgnoredField(field reflect.StructField) bool {
+	if field.Tag.Get("gontext") == "-" {
+		return true
+	}
+	gormTag := field.Tag.Get("gorm")
+	return gormTag == "-" || gormTag == "-:all"
+}
+
+// IsEmbeddedField reports whether GORM flattens the struct field into its parent's columns: an anonymous
+// struct, or a struct tagged gorm:"embedded". Times, byte slices and driver.Valuer types are single columns
+func IsEmbeddedField(field reflect.StructField) bool {
+	fieldType := field.Type
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	if fieldType.Kind() != reflect.Struct {
+		return false
+	}
+
+	if isIgnoredField(field) {
+		return false
+	}
+
+	tags := make(map[string]string)
+	parseTags(field.Tag.Get("gorm"), tags)
+	if _, embedded := lookupTag(tags, "embedded"); embedded {
+		return true
+	}
+	if !field.Anonymous || fieldType == timeType || isSQLNullType(fieldType) {
+		return false
+	}
+	return !fieldType.Implements(valuerType) && !reflect.PointerTo(fieldType).Implements(valuerType)
+}
+
+// FieldNames returns the names of the entity's column fields in declaration order, with the fields of
+// embedded structs in place of the struct, as GORM names them
+func FieldNames(entityType reflect.Type) []string {
+	if entityType.Kind() == reflect.Ptr {
+		entityType = entityType.Elem()
+	}
+
+	var names []string
+	for i := 0; i < entityType.NumField(); i++ {
+		field := entityType.Field(i)
+		if IsEmbeddedField(field) {
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			names = append(names, FieldNames(fieldType)...)
+		} else if field.PkgPath == "" && !isIgnoredField(field) {
+			names = append(names, field.Name)
+		}
+	}
+	return names
+}
+
+// ValueOf returns the field's value in entity (a struct value), following embedded structs; the result
+// is invalid when an embedded struct pointer on the way is nil
+func (f FieldModel) ValueOf(entity reflect.Value) reflect.Value {
+	for _, name := range f.EmbeddedPath {
+		entity = entity.FieldByName(name)
+		if entity.Kind() == reflect.Ptr {
+			if entity.IsNil() {
+				return reflect.Value{}
+			}
+			entity = entity.Elem()
+		}
+	}
+	return entity.FieldByName(f.Name[strings.LastIndex(f.Name, ".")+1:])
 }
 
 func parseFieldModel(field reflect.StructField) FieldModel {
@@ -84,7 +378,8 @@ func parseFieldModel(field reflect.StructField) FieldModel {
 		parseTags(gormTag, fieldModel.Tags)
 	}
 
-	if _, exists := fieldModel.Tags["primary_key"]; exists || strings.Contains(gonTextTag, "primary_key") {
+	_, isPrimaryKey := lookupTag(fieldModel.Tags, "primaryKey")
+	if _, exists := fieldModel.Tags["primary_key"]; exists || isPrimaryKey || strings.Contains(gonTextTag, "primary_key") {
 		fieldModel.IsPrimary = true
 		fieldModel.IsNullable = false
 	}
@@ -93,7 +388,12 @@ func parseFieldModel(field reflect.StructField) FieldModel {
 		fieldModel.IsUnique = true
 	}
 
-	if _, exists := fieldModel.Tags["not_null"]; exists {
+	if _, exists := fieldModel.Tags["sensitive"]; exists {
+		fieldModel.IsSensitive = true
+	}
+
+	_, isNotNull := lookupTag(fieldModel.Tags, "not null")
+	if _, exists := fieldModel.Tags["not_null"]; exists || isNotNull {
 		fieldModel.IsNullable = false
 	}
 
@@ -105,9 +405,75 @@ func parseFieldModel(field reflect.StructField) FieldModel {
 		fieldModel.OldName = &oldName
 	}
 
+	if size, exists := lookupTag(fieldModel.Tags, "size"); exists {
+		fieldModel.MaxLength, _ = strconv.Atoi(size)
+	}
+
+	if collation, exists := fieldModel.Tags["collation"]; exists {
+		fieldModel.Collation = collation
+	}
+
+	if config, exists := fieldModel.Tags["text_search"]; exists {
+		fieldModel.TextSearchConfig = config
+	}
+
+	if _, exists := fieldModel.Tags["trigram"]; exists {
+		fieldModel.Trigram = true
+	}
+
+	fieldModel.ValueGeneration = detectValueGeneration(fieldModel)
+
 	return fieldModel
 }
 
+// detectValueGeneration infers how the database generates a column from its tags and type
+func detectValueGeneration(field FieldModel) ValueGenerationStrategy {
+	if autoIncrement, exists := lookupTag(field.Tags, "autoIncrement"); exists {
+		if strings.EqualFold(autoIncrement, "false") {
+			return ValueGeneratedNever
+		}
+		return ValueGeneratedByIdentity
+	}
+
+	if _, exists := lookupTag(field.Tags, "identity"); exists {
+		return ValueGeneratedByIdentity
+	}
+
+	if columnType, exists := lookupTag(field.Tags, "type"); exists {
+		switch strings.ToLower(columnType) {
+		case "serial", "bigserial", "smallserial":
+			return ValueGeneratedByIdentity
+		}
+	}
+
+	if field.DefaultValue != nil {
+		defaultValue := strings.ToLower(*field.DefaultValue)
+		if strings.Contains(defaultValue, "gen_random_uuid()") || strings.Contains(defaultValue, "uuid_generate_v4()") {
+			return ValueGeneratedByDatabaseDefault
+		}
+	}
+
+	// Integer primary keys are auto-incremented by default (matches GORM)
+	if field.IsPrimary && field.DefaultValue == nil {
+		switch field.GoType.Kind() {
+		case reflect.Int, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+			return ValueGeneratedByIdentity
+		}
+	}
+
+	return ValueGeneratedNever
+}
+
+// lookupTag finds a tag by name ignoring case, since GORM tag keys are case-insensitive
+func lookupTag(tags map[string]string, name string) (string, bool) {
+	for key, value := range tags {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
 func parseTags(tagStr string, tags map[string]string) {
 	parts := strings.Split(tagStr, ";")
 	for _, part := range parts {
@@ -129,7 +495,13 @@ func isNullableType(t reflect.Type) bool {
 	return t.Kind() == reflect.Ptr || 
 		   t.Kind() == reflect.Interface ||
 		   t.Kind() == reflect.Slice ||
-		   t.Kind() == reflect.Map
+		   t.Kind() == reflect.Map ||
+		   isSQLNullType(t)
+}
+
+// isSQLNullType reports whether t is a database/sql null wrapper: sql.NullString, sql.NullTime, sql.Null[T], ...
+func isSQLNullType(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.PkgPath() == "database/sql" && strings.HasPrefix(t.Name(), "Null")
 }
 
 func toSnakeCase(str string) string {
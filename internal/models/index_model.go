@@ -0,0 +1,218 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// IndexModel is an index declared through the model builder - EF Core: entity.HasIndex(x => new { ... })
+type IndexModel struct {
+	Name       string
+	Fields     []string // Go field names, in index order
+	IsUnique   bool
+	Expression string // SQL expression indexed instead of Fields, e.g. LOWER("Email")
+	Filter     string // WHERE predicate of a partial index, e.g. "DeletedAt" IS NULL
+}
+
+// IndexBuilder configures a declared index
+type IndexBuilder struct {
+	entity  *EntityModel
+	index   *IndexModel
+	changed func(*EntityModel)
+}
+
+// HasIndex declares an index over one or more fields; migrations create it
+// Usage: gontext.Entity[Post](ctx).HasIndex("AuthorID", "Slug").IsUnique()
+// Panics on unknown fields, since this is a configuration bug
+func (b *EntityTypeBuilder) HasIndex(fields ...string) *IndexBuilder {
+	if len(fields) == 0 {
+		panic(fmt.Sprintf("HasIndex on %s needs at least one field", b.entity.Name))
+	}
+	columns := make([]string, len(fields))
+	for i, name := range fields {
+		field, exists := b.entity.Fields[name]
+		if !exists {
+			panic(fmt.Sprintf("Field '%s' not found on %s", name, b.entity.Name))
+		}
+		columns[i] = field.ColumnName
+	}
+
+	index := &IndexModel{
+		Name:   b.entity.Naming().IndexName(b.entity.TableName, columns),
+		Fields: append([]string(nil), fields...),
+	}
+	b.entity.Indexes = append(b.entity.Indexes, index)
+	notify(b.changed, b.entity)
+	return &IndexBuilder{entity: b.entity, index: index, changed: b.changed}
+}
+
+// HasExpressionIndex declares an index over an SQL expression; migrations create it
+// Usage: gontext.Entity[User](ctx).HasExpressionIndex(`LOWER("Email")`).IsUnique()
+// The default name is built from the expression's words: idx_User_lower_email
+func (b *EntityTypeBuilder) HasExpressionIndex(expression string) *IndexBuilder {
+	if strings.TrimSpace(expression) == "" {
+		panic(fmt.Sprintf("HasExpressionIndex on %s needs an expression", b.entity.Name))
+	}
+	words := strings.FieldsFunc(strings.ToLower(expression), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	index := &IndexModel{
+		Name:       b.entity.Naming().IndexName(b.entity.TableName, words),
+		Expression: expression,
+	}
+	b.entity.Indexes = append(b.entity.Indexes, index)
+	notify(b.changed, b.entity)
+	return &IndexBuilder{entity: b.entity, index: index, changed: b.changed}
+}
+
+// Model returns the index model being configured
+func (b *IndexBuilder) Model() *IndexModel {
+	return b.index
+}
+
+// IsUnique makes the index reject rows whose indexed columns equal another row's
+func (b *IndexBuilder) IsUnique() *IndexBuilder {
+	b.index.IsUnique = true
+	notify(b.changed, b.entity)
+	return b
+}
+
+// HasFilter makes the index partial, covering only the rows that match the predicate
+// Usage: HasIndex("Email").IsUnique().HasFilter(`"DeletedAt" IS NULL`)
+func (b *IndexBuilder) HasFilter(predicate string) *IndexBuilder {
+	b.index.Filter = predicate
+	notify(b.changed, b.entity)
+	return b
+}
+
+// HasDatabaseName names the index instead of idx_<table>_<columns>
+func (b *IndexBuilder) HasDatabaseName(name string) *IndexBuilder {
+	b.index.Name = name
+	notify(b.changed, b.entity)
+	return b
+}
+
+// Definition resolves the index's fields to the entity's columns
+func (index *IndexModel) Definition(entity *EntityModel) IndexDefinition {
+	columns := make([]string, 0, len(index.Fields))
+	for _, name := range index.Fields {
+		if field, exists := entity.Fields[name]; exists {
+			columns = append(columns, field.ColumnName)
+		}
+	}
+	return IndexDefinition{
+		Name:       index.Name,
+		Columns:    columns,
+		IsUnique:   index.IsUnique,
+		Expression: index.Expression,
+		Filter:     index.Filter,
+	}
+}
+
+// EntityIndexes returns the entity's indexes ordered by name: those declared with HasIndex and those of
+// gorm index and uniqueIndex tags. Fields whose tags share an index name form one composite index, in
+// field declaration order unless a priority is given: gorm:"uniqueIndex:idx_author_slug,priority:2"
+func EntityIndexes(entity *EntityModel) []IndexDefinition {
+	type tagColumn struct {
+		column   string
+		priority int
+		order    []int
+	}
+	tagIndexes := make(map[string]*IndexDefinition)
+	tagColumns := make(map[string][]tagColumn)
+
+	for _, field := range entity.Fields {
+		for key, value := range field.Tags {
+			unique := strings.EqualFold(key, "uniqueIndex")
+			if !unique && !strings.EqualFold(key, "index") {
+				continue
+			}
+
+			settings := strings.Split(value, ",")
+			name := strings.TrimSpace(settings[0])
+			if name == "" {
+				name = entity.Naming().IndexName(entity.TableName, []string{field.ColumnName})
+			}
+			priority := 10 // GORM's default priority
+			for _, setting := range settings[1:] {
+				parts := strings.SplitN(strings.TrimSpace(setting), ":", 2)
+				switch strings.ToLower(parts[0]) {
+				case "unique":
+					unique = true
+				case "priority":
+					if len(parts) == 2 {
+						if value, err := strconv.Atoi(parts[1]); err == nil {
+							priority = value
+						}
+					}
+				}
+			}
+
+			index, exists := tagIndexes[name]
+			if !exists {
+				index = &IndexDefinition{Name: name}
+				tagIndexes[name] = index
+			}
+			index.IsUnique = index.IsUnique || unique
+			tagColumns[name] = append(tagColumns[name], tagColumn{
+				column:   field.ColumnName,
+				priority: priority,
+				order:    fieldOrder(entity, field),
+			})
+		}
+	}
+
+	var indexes []IndexDefinition
+	for name, index := range tagIndexes {
+		columns := tagColumns[name]
+		sort.SliceStable(columns, func(i, j int) bool {
+			if columns[i].priority != columns[j].priority {
+				return columns[i].priority < columns[j].priority
+			}
+			return lessOrder(columns[i].order, columns[j].order)
+		})
+		for _, column := range columns {
+			index.Columns = append(index.Columns, column.column)
+		}
+		indexes = append(indexes, *index)
+	}
+	for _, index := range entity.Indexes {
+		indexes = append(indexes, index.Definition(entity))
+	}
+
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i].Name < indexes[j].Name })
+	return indexes
+}
+
+// fieldOrder returns the struct field index path of a field, through the embedded structs it is
+// declared in, for ordering fields by declaration
+func fieldOrder(entity *EntityModel, field FieldModel) []int {
+	var order []int
+	structType := entity.Type
+	for _, name := range strings.Split(field.Path(), ".") {
+		if structType.Kind() == reflect.Ptr {
+			structType = structType.Elem()
+		}
+		structField, found := structType.FieldByName(name)
+		if !found {
+			break
+		}
+		order = append(order, structField.Index...)
+		structType = structField.Type
+	}
+	return order
+}
+
+func lessOrder(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
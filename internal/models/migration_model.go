@@ -1,6 +1,8 @@
 package models
 
 import (
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -13,6 +15,13 @@ type Migration struct {
 	DependsOn   *string   `gontext:"nullable"` // ID of the migration this depends on
 }
 
+// SeedHistory records a seeder that has been applied to the database
+type SeedHistory struct {
+	Id          string    `gontext:"primary_key"` // Seeder name
+	Environment string
+	AppliedAt   time.Time `gontext:"not_null"`
+}
+
 type MigrationOperation struct {
 	Type       MigrationOperationType
 	EntityName string
@@ -33,18 +42,70 @@ const (
 	AddForeignKey
 	DropForeignKey
 	RawSQL
+	CreateSequence
+	AlterSequence
+	DropSequence
+	RenameTable
+	CreatePartition
+	CreateView
+	DropView
+	CreateTrigger
+	DropTrigger
+	CreateExtension
 )
 
 type CreateTableOperation struct {
 	TableName string
 	Columns   []ColumnDefinition
 	Indexes   []IndexDefinition
+	// Partition makes the table partitioned; its key column joins the primary key and unique constraints
+	Partition *PartitionModel
 }
 
 type DropTableOperation struct {
 	TableName string
 }
 
+// RenameTableOperation renames an entity's table
+type RenameTableOperation struct {
+	OldName string
+	NewName string
+}
+
+// CreatePartitionOperation creates a partition of a partitioned table
+type CreatePartitionOperation struct {
+	TableName     string
+	PartitionName string
+	Bound         string // FOR VALUES clause, or DEFAULT
+}
+
+// CreateViewOperation creates a materialized view
+type CreateViewOperation struct {
+	View ViewModel
+}
+
+// DropViewOperation drops a materialized view
+type DropViewOperation struct {
+	View ViewModel // Recreated on rollback
+}
+
+// CreateTriggerOperation creates a trigger and its trigger function
+type CreateTriggerOperation struct {
+	TableName string
+	Trigger   TriggerModel
+}
+
+// DropTriggerOperation drops a trigger and its trigger function
+type DropTriggerOperation struct {
+	TableName string
+	Trigger   TriggerModel // Recreated on rollback
+}
+
+// CreateExtensionOperation creates a PostgreSQL extension; rollbacks keep it, since other objects may use it
+type CreateExtensionOperation struct {
+	Name string
+}
+
 type AddColumnOperation struct {
 	TableName string
 	Column    ColumnDefinition
@@ -61,9 +122,23 @@ type RenameColumnOperation struct {
 	NewName     string
 }
 
+// ModifyColumnOperation changes the type of a column (length, collation)
 type ModifyColumnOperation struct {
 	TableName string
 	Column    ColumnDefinition
+	OldColumn ColumnDefinition // Restored on rollback
+}
+
+// AddIndexOperation creates an index
+type AddIndexOperation struct {
+	TableName string
+	Index     IndexDefinition
+}
+
+// DropIndexOperation drops an index
+type DropIndexOperation struct {
+	TableName string
+	Index     IndexDefinition // Recreated on rollback
 }
 
 type ColumnDefinition struct {
@@ -72,14 +147,71 @@ type ColumnDefinition struct {
 	IsNullable   bool
 	IsPrimary    bool
 	IsUnique     bool
+	IsIdentity   bool
 	DefaultValue *string
 	References   *ForeignKeyReference
+	Computed     string // GENERATED ALWAYS AS (...) clause of a computed column
+}
+
+// CreateSequenceOperation creates the sequence backing a HiLo key or a declared sequence
+type CreateSequenceOperation struct {
+	SequenceName string
+	IncrementBy  int
+	StartWith    int64 // 0 keeps the database default of 1
+}
+
+// AlterSequenceOperation changes the increment of a declared sequence
+type AlterSequenceOperation struct {
+	SequenceName   string
+	IncrementBy    int
+	OldIncrementBy int // Restored on rollback
+}
+
+// DropSequenceOperation drops a sequence that is no longer declared
+type DropSequenceOperation struct {
+	SequenceName string
+	IncrementBy  int // Recreated with it on rollback
+	StartWith    int64
 }
 
 type IndexDefinition struct {
-	Name      string
-	Columns   []string
-	IsUnique  bool
+	Name       string
+	Columns    []string
+	IsUnique   bool
+	Method     string // Index access method, e.g. "GIN"; empty uses the database default
+	Expression string // Indexed expression, used instead of Columns
+	Filter     string // WHERE predicate of a partial index
+}
+
+// CreateIndexSQL returns the statement that creates index on table
+func CreateIndexSQL(table string, index IndexDefinition) string {
+	sql := "CREATE "
+	if index.IsUnique {
+		sql += "UNIQUE "
+	}
+	sql += fmt.Sprintf(`INDEX IF NOT EXISTS "%s" ON "%s"`, index.Name, table)
+	if index.Method != "" {
+		sql += " USING " + index.Method
+	}
+
+	target := index.Expression
+	if target == "" {
+		quoted := make([]string, len(index.Columns))
+		for i, column := range index.Columns {
+			quoted[i] = fmt.Sprintf(`"%s"`, column)
+		}
+		target = strings.Join(quoted, ", ")
+	}
+	sql += " (" + target + ")"
+	if index.Filter != "" {
+		sql += " WHERE " + index.Filter
+	}
+	return sql
+}
+
+// DropIndexSQL returns the statement that drops an index
+func DropIndexSQL(index IndexDefinition) string {
+	return fmt.Sprintf(`DROP INDEX IF EXISTS "%s"`, index.Name)
 }
 
 type ForeignKeyReference struct {
@@ -87,4 +219,5 @@ type ForeignKeyReference struct {
 	ReferencedColumn string
 	OnDelete         string
 	OnUpdate         string
+	ConstraintName   string // Empty for fk_<table>_<column>
 }
\ No newline at end of file
@@ -0,0 +1,290 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+
+	"github.com/shepherrrd/gontext/internal/encryption"
+	"github.com/shepherrrd/gontext/internal/search"
+)
+
+// EntityTypeBuilder configures an entity's mapping - EF Core: modelBuilder.Entity<T>()
+type EntityTypeBuilder struct {
+	entity  *EntityModel
+	changed func(*EntityModel)
+}
+
+// PropertyBuilder configures a single mapped field - EF Core: entity.Property(x => x.Field)
+type PropertyBuilder struct {
+	entity    *EntityModel
+	fieldName string
+	changed   func(*EntityModel)
+}
+
+// NewEntityTypeBuilder creates a builder that mutates the given entity model
+func NewEntityTypeBuilder(entity *EntityModel) *EntityTypeBuilder {
+	return &EntityTypeBuilder{entity: entity}
+}
+
+// Model returns the entity model being configured
+func (b *EntityTypeBuilder) Model() *EntityModel {
+	return b.entity
+}
+
+// OnChange calls fn after every change made through the builder, so the context can apply the
+// mapping to its ORM schema
+func (b *EntityTypeBuilder) OnChange(fn func(*EntityModel)) *EntityTypeBuilder {
+	b.changed = fn
+	return b
+}
+
+// notify reports a change of the entity model to the OnChange callback
+func notify(changed func(*EntityModel), entity *EntityModel) {
+	if changed != nil {
+		changed(entity)
+	}
+}
+
+// ToTable maps the entity to the named table, overriding its TableName() method and the context's table
+// naming - EF Core: entity.ToTable("posts"). The next migration renames the existing table
+// Panics on an empty name, since this is a configuration bug
+func (b *EntityTypeBuilder) ToTable(name string) *EntityTypeBuilder {
+	if name == "" {
+		panic(fmt.Sprintf("Table name of %s must not be empty", b.entity.Name))
+	}
+	b.entity.TableName = name
+	b.entity.TableConfigured = true
+	notify(b.changed, b.entity)
+	return b
+}
+
+// Property returns a builder for the named field
+// Panics with a clear error if the field does not exist, since this is a configuration bug
+func (b *EntityTypeBuilder) Property(fieldName string) *PropertyBuilder {
+	if _, exists := b.entity.Fields[fieldName]; !exists {
+		panic(fmt.Sprintf("Field '%s' not found on %s", fieldName, b.entity.Name))
+	}
+	return &PropertyBuilder{entity: b.entity, fieldName: fieldName, changed: b.changed}
+}
+
+// Ignore leaves a field out of the mapping, like the gontext:"-" tag: it gets no column and is never
+// read or written - EF Core: entity.Ignore(x => x.Field)
+// Panics with a clear error if the field does not exist, since this is a configuration bug
+func (b *EntityTypeBuilder) Ignore(fieldName string) *EntityTypeBuilder {
+	field, exists := b.entity.Fields[fieldName]
+	if !exists {
+		panic(fmt.Sprintf("Field '%s' not found on %s", fieldName, b.entity.Name))
+	}
+
+	delete(b.entity.Fields, fieldName)
+	for i, column := range b.entity.PrimaryKey {
+		if column == field.ColumnName {
+			b.entity.PrimaryKey = append(b.entity.PrimaryKey[:i:i], b.entity.PrimaryKey[i+1:]...)
+			break
+		}
+	}
+	b.entity.IgnoredFields = append(b.entity.IgnoredFields, field.Path())
+	notify(b.changed, b.entity)
+	return b
+}
+
+// Searchable indexes the entity in a search engine index: saved entities are upserted and deleted ones removed
+// fields limits the indexed Go fields; none indexes every column except encrypted ones
+// Panics on unknown fields or a composite key, since these are configuration bugs
+// Usage: gontext.Entity[Product](ctx).Searchable("products", "Name", "Description", "Price")
+func (b *EntityTypeBuilder) Searchable(index string, fields ...string) *EntityTypeBuilder {
+	if len(b.entity.PrimaryKey) > 1 {
+		panic(fmt.Sprintf("Searchable entity %s must have a single-column key", b.entity.Name))
+	}
+	for _, name := range fields {
+		if _, exists := b.entity.Fields[name]; !exists {
+			panic(fmt.Sprintf("Field '%s' not found on %s", name, b.entity.Name))
+		}
+	}
+	b.entity.Search = &search.Mapping{Index: index, Fields: fields}
+	return b
+}
+
+// update applies a change to the field model (fields are stored by value)
+func (p *PropertyBuilder) update(apply func(field *FieldModel)) *PropertyBuilder {
+	field := p.entity.Fields[p.fieldName]
+	apply(&field)
+	p.entity.Fields[p.fieldName] = field
+	notify(p.changed, p.entity)
+	return p
+}
+
+// UseIdentityColumn marks the field as database generated (serial/IDENTITY/AUTO_INCREMENT)
+func (p *PropertyBuilder) UseIdentityColumn() *PropertyBuilder {
+	return p.update(func(field *FieldModel) {
+		field.ValueGeneration = ValueGeneratedByIdentity
+	})
+}
+
+// HasKeyGenerator generates the value in Go when an added entity leaves the field at its zero value
+// Usage: Property("ID").HasKeyGenerator(func() (interface{}, error) { return ulid.Make().String(), nil })
+func (p *PropertyBuilder) HasKeyGenerator(generator KeyGenerator) *PropertyBuilder {
+	return p.update(func(field *FieldModel) {
+		field.ValueGeneration = ValueGeneratedByGenerator
+		field.KeyGenerator = generator
+	})
+}
+
+// UseHiLo allocates values client-side in blocks of blockSize reserved from a database sequence
+// Requires sequence support (PostgreSQL); migrations create the sequence
+// Usage: Property("ID").UseHiLo("Post_hilo", 100)
+func (p *PropertyBuilder) UseHiLo(sequenceName string, blockSize int) *PropertyBuilder {
+	if blockSize <= 0 {
+		blockSize = 10
+	}
+	return p.update(func(field *FieldModel) {
+		field.ValueGeneration = ValueGeneratedByHiLo
+		field.HiLoSequence = sequenceName
+		field.HiLoBlockSize = blockSize
+	})
+}
+
+// ValueGeneratedNever disables any value generation for the field
+func (p *PropertyBuilder) ValueGeneratedNever() *PropertyBuilder {
+	return p.update(func(field *FieldModel) {
+		field.ValueGeneration = ValueGeneratedNever
+		field.KeyGenerator = nil
+	})
+}
+
+// HasDefaultFunc computes the value in Go when an added entity leaves the field at its zero value
+// Complements SQL defaults (gorm default tags) for values that depend on the entity
+// fn is func(*Entity) V or func() V, e.g. Property("Slug").HasDefaultFunc(func(p *Post) any { return slugify(p.Title) })
+// Panics when fn has another shape, since this is a configuration bug
+func (p *PropertyBuilder) HasDefaultFunc(fn interface{}) *PropertyBuilder {
+	fnValue := reflect.ValueOf(fn)
+	fnType := fnValue.Type()
+	entityPtr := reflect.PointerTo(p.entity.Type)
+	if fnType.Kind() != reflect.Func || fnType.NumOut() != 1 || fnType.NumIn() > 1 ||
+		(fnType.NumIn() == 1 && !entityPtr.AssignableTo(fnType.In(0))) {
+		panic(fmt.Sprintf("HasDefaultFunc for %s.%s expects func(*%s) V or func() V, got %T",
+			p.entity.Name, p.fieldName, p.entity.Type.Name(), fn))
+	}
+
+	defaultFunc := func(entity interface{}) interface{} {
+		var in []reflect.Value
+		if fnType.NumIn() == 1 {
+			in = append(in, reflect.ValueOf(entity))
+		}
+		return fnValue.Call(in)[0].Interface()
+	}
+	return p.update(func(field *FieldModel) {
+		field.DefaultFunc = defaultFunc
+	})
+}
+
+// HasMaxLength makes a string column VARCHAR(length) instead of TEXT, like the gorm size tag
+// Panics when length is not positive or the field is not a string, since these are configuration bugs
+func (p *PropertyBuilder) HasMaxLength(length int) *PropertyBuilder {
+	p.requireString("HasMaxLength")
+	if length <= 0 {
+		panic(fmt.Sprintf("Max length of %s.%s must be positive, got %d", p.entity.Name, p.fieldName, length))
+	}
+	return p.update(func(field *FieldModel) {
+		field.MaxLength = length
+	})
+}
+
+// UseCollation sets the column's collation, e.g. "und-x-icu" or "C" on PostgreSQL, "utf8mb4_bin" on MySQL
+// or "NOCASE" on SQLite - EF Core: UseCollation
+func (p *PropertyBuilder) UseCollation(collation string) *PropertyBuilder {
+	return p.update(func(field *FieldModel) {
+		field.Collation = collation
+	})
+}
+
+// HasTextSearchConfig indexes the column for PostgreSQL full-text search with a text search configuration
+// such as "english"; queries use the index when they search to_tsvector with the same configuration:
+// Where(`to_tsvector('english', "Body") @@ websearch_to_tsquery('english', ?)`, terms)
+// Panics when the field is not a string, since this is a configuration bug
+func (p *PropertyBuilder) HasTextSearchConfig(config string) *PropertyBuilder {
+	p.requireString("HasTextSearchConfig")
+	return p.update(func(field *FieldModel) {
+		field.TextSearchConfig = config
+	})
+}
+
+// HasTrigramIndex indexes the column with a GIN trigram index for typo-tolerant search with WhereSimilar
+// and OrderBySimilarity; migrations create the pg_trgm extension with it
+// Panics when the field is not a string, since this is a configuration bug
+func (p *PropertyBuilder) HasTrigramIndex() *PropertyBuilder {
+	p.requireString("HasTrigramIndex")
+	return p.update(func(field *FieldModel) {
+		field.Trigram = true
+	})
+}
+
+// requireString panics when the field does not hold a string
+func (p *PropertyBuilder) requireString(method string) {
+	fieldType := p.entity.Fields[p.fieldName].GoType
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	if fieldType.Kind() != reflect.String && fieldType != reflect.TypeOf(sql.NullString{}) {
+		panic(fmt.Sprintf("%s requires a string field, %s.%s is %s", method, p.entity.Name, p.fieldName, fieldType))
+	}
+}
+
+// HasComputedColumnSql makes the field a column the database computes from others; migrations create it
+// with GENERATED ALWAYS AS (sql), and inserts and updates leave it out. stored keeps the computed value
+// on disk (required by PostgreSQL before version 18); otherwise it is computed when read
+// Usage: Property("FullName").HasComputedColumnSql(`"FirstName" || ' ' || "LastName"`, true)
+// Panics on a key field, since this is a configuration bug
+func (p *PropertyBuilder) HasComputedColumnSql(sql string, stored bool) *PropertyBuilder {
+	if p.entity.Fields[p.fieldName].IsPrimary {
+		panic(fmt.Sprintf("Key field %s.%s cannot be a computed column", p.entity.Name, p.fieldName))
+	}
+	return p.update(func(field *FieldModel) {
+		field.ComputedColumnSql = sql
+		field.IsComputedStored = stored
+		field.ValueGeneration = ValueGeneratedNever
+		field.DefaultValue = nil
+	})
+}
+
+// IsSensitive masks the field's values in logged SQL, like the gontext:"sensitive" tag
+// Usage: Property("Email").IsSensitive()
+func (p *PropertyBuilder) IsSensitive() *PropertyBuilder {
+	return p.update(func(field *FieldModel) {
+		field.IsSensitive = true
+	})
+}
+
+// IsEncrypted encrypts the column at rest with AES-GCM under the keyring's primary key; values are
+// encrypted on write and decrypted when loaded. Each write uses a random nonce, so the column cannot be
+// queried by value - use IsEncryptedDeterministic for that
+// Usage: Property("Ssn").IsEncrypted(keyring)
+func (p *PropertyBuilder) IsEncrypted(keyring *encryption.Keyring) *PropertyBuilder {
+	return p.encrypted(keyring, false)
+}
+
+// IsEncryptedDeterministic encrypts the column so equal values have equal ciphertexts, which keeps
+// Where("Email", value) working (across key rotations too) but reveals which rows share a value
+// Usage: Property("Email").IsEncryptedDeterministic(keyring)
+func (p *PropertyBuilder) IsEncryptedDeterministic(keyring *encryption.Keyring) *PropertyBuilder {
+	return p.encrypted(keyring, true)
+}
+
+// encrypted panics on a missing keyring or a field that is not string, *string or []byte,
+// since these are configuration bugs
+func (p *PropertyBuilder) encrypted(keyring *encryption.Keyring, deterministic bool) *PropertyBuilder {
+	if keyring == nil {
+		panic(fmt.Sprintf("Encryption of %s.%s requires a keyring", p.entity.Name, p.fieldName))
+	}
+	fieldType := p.entity.Fields[p.fieldName].GoType
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	if fieldType.Kind() != reflect.String && !(fieldType.Kind() == reflect.Slice && fieldType.Elem().Kind() == reflect.Uint8) {
+		panic(fmt.Sprintf("Encrypted field %s.%s must be a string or []byte, got %s", p.entity.Name, p.fieldName, fieldType))
+	}
+	return p.update(func(field *FieldModel) {
+		field.Encryption = &encryption.Column{Keyring: keyring, Deterministic: deterministic}
+	})
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/jinzhu/inflection"
+)
+
+// NamingConvention maps Go struct and field names to table and column names
+// TableName() methods and gorm column tags always take precedence over the convention
+type NamingConvention int
+
+const (
+	DefaultNaming NamingConvention = iota // Keep the driver's naming (Go names as-is for PostgreSQL)
+	PascalCase                            // BlogPost.AuthorId -> "BlogPost"."AuthorId"
+	SnakeCase                             // BlogPost.AuthorId -> "blog_post"."author_id"
+	CamelCase                             // BlogPost.AuthorId -> "blogPost"."authorId"
+)
+
+// Convert applies the convention to a Go identifier
+func (c NamingConvention) Convert(name string) string {
+	switch c {
+	case SnakeCase:
+		return strings.ToLower(strings.Join(splitWords(name), "_"))
+	case CamelCase:
+		words := splitWords(name)
+		for i, word := range words {
+			if i == 0 {
+				words[i] = strings.ToLower(word)
+			} else {
+				words[i] = strings.ToUpper(word[:1]) + strings.ToLower(word[1:])
+			}
+		}
+		return strings.Join(words, "")
+	default:
+		return name
+	}
+}
+
+func (c NamingConvention) String() string {
+	switch c {
+	case PascalCase:
+		return "PascalCase"
+	case SnakeCase:
+		return "snake_case"
+	case CamelCase:
+		return "camelCase"
+	default:
+		return "default"
+	}
+}
+
+// TableNaming derives an entity's table name from its struct name, replacing the naming convention for
+// tables; a TableName() method and EntityTypeBuilder.ToTable take precedence
+type TableNaming interface {
+	TableName(entityName string) string
+}
+
+// PluralTableNames names tables with the convention and pluralizes the last word, like GORM's default:
+// BlogPost -> "blog_posts" with SnakeCase, Category -> "Categories" with DefaultNaming
+type PluralTableNames struct {
+	Convention NamingConvention
+}
+
+// TableName converts the struct name and pluralizes it
+func (p PluralTableNames) TableName(entityName string) string {
+	return inflection.Plural(p.Convention.Convert(entityName))
+}
+
+// splitWords splits a Go identifier into words, keeping acronyms together: UserID -> [User ID], HTTPServer -> [HTTP Server]
+func splitWords(name string) []string {
+	runes := []rune(name)
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		boundary := false
+		switch {
+		case runes[i] == '_':
+			words = appendWord(words, runes[start:i])
+			start = i + 1
+			continue
+		case unicode.IsUpper(runes[i]) && unicode.IsLower(runes[i-1]),
+			unicode.IsUpper(runes[i]) && unicode.IsDigit(runes[i-1]):
+			boundary = true
+		case unicode.IsUpper(runes[i]) && unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1]):
+			boundary = true // End of an acronym: the "S" in HTTPServer
+		}
+		if boundary && i > start {
+			words = appendWord(words, runes[start:i])
+			start = i
+		}
+	}
+	return appendWord(words, runes[start:])
+}
+
+func appendWord(words []string, word []rune) []string {
+	if len(word) == 0 {
+		return words
+	}
+	return append(words, string(word))
+}
@@ -0,0 +1,239 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// PartitionStrategy is how a partitioned table splits its rows - PostgreSQL: PARTITION BY
+type PartitionStrategy string
+
+const (
+	PartitionByRange PartitionStrategy = "RANGE" // One partition per day, month or year of a time column
+	PartitionByHash  PartitionStrategy = "HASH"  // A fixed number of partitions by hash of a column
+)
+
+// PartitionInterval is the time span covered by each range partition
+type PartitionInterval string
+
+const (
+	PartitionDaily   PartitionInterval = "daily"
+	PartitionMonthly PartitionInterval = "monthly"
+	PartitionYearly  PartitionInterval = "yearly"
+)
+
+// DefaultPartitionPremake is how many range partitions ahead of the current one are kept created
+const DefaultPartitionPremake = 3
+
+// PartitionModel is the partitioning declared through the model builder; it is stored in model
+// snapshots so migrations can create the partitioned table
+type PartitionModel struct {
+	Strategy PartitionStrategy `json:"strategy"`
+	Field    string            `json:"field"` // Go field name of the partition key
+	Column   string            `json:"column"`
+	Interval PartitionInterval `json:"interval,omitempty"` // Range partitions only
+	Modulus  int               `json:"modulus,omitempty"`  // Number of hash partitions
+	// Premake is how many range partitions after the current one maintenance keeps created
+	Premake int `json:"premake,omitempty"`
+	// Retention is how many range partitions before the current one stay attached; older ones are
+	// detached into standalone tables for archival. 0 keeps every partition attached
+	Retention int `json:"retention,omitempty"`
+}
+
+// PartitionBuilder configures the partitioning of an entity
+type PartitionBuilder struct {
+	entity  *EntityModel
+	changed func(*EntityModel)
+}
+
+// PartitionByRange splits the table into one partition per interval of a time field; migrations create
+// the partitioned table with a DEFAULT partition and Partitions().Maintain creates the ranged ones
+// Usage: gontext.Entity[Event](ctx).PartitionByRange("OccurredAt", gontext.PartitionMonthly).Retain(12)
+// Panics on an unknown or non-time field, since this is a configuration bug
+func (b *EntityTypeBuilder) PartitionByRange(fieldName string, interval PartitionInterval) *PartitionBuilder {
+	field := b.partitionField(fieldName)
+	fieldType := field.GoType
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	if fieldType != timeType {
+		panic(fmt.Sprintf("Range partition key %s.%s must be a time.Time, got %s", b.entity.Name, fieldName, field.GoType))
+	}
+	switch interval {
+	case PartitionDaily, PartitionMonthly, PartitionYearly:
+	default:
+		panic(fmt.Sprintf("Unknown partition interval %q for %s", interval, b.entity.Name))
+	}
+
+	b.entity.Partition = &PartitionModel{
+		Strategy: PartitionByRange,
+		Field:    fieldName,
+		Column:   field.ColumnName,
+		Interval: interval,
+		Premake:  DefaultPartitionPremake,
+	}
+	notify(b.changed, b.entity)
+	return &PartitionBuilder{entity: b.entity, changed: b.changed}
+}
+
+// PartitionByHash spreads the rows over a fixed number of partitions by hash of a field; migrations
+// create all of them
+// Usage: gontext.Entity[Reading](ctx).PartitionByHash("DeviceID", 16)
+// Panics on an unknown field or fewer than two partitions, since these are configuration bugs
+func (b *EntityTypeBuilder) PartitionByHash(fieldName string, partitions int) *PartitionBuilder {
+	field := b.partitionField(fieldName)
+	if partitions < 2 {
+		panic(fmt.Sprintf("Hash partitioning of %s needs at least 2 partitions, got %d", b.entity.Name, partitions))
+	}
+
+	b.entity.Partition = &PartitionModel{
+		Strategy: PartitionByHash,
+		Field:    fieldName,
+		Column:   field.ColumnName,
+		Modulus:  partitions,
+	}
+	notify(b.changed, b.entity)
+	return &PartitionBuilder{entity: b.entity, changed: b.changed}
+}
+
+// partitionField returns the field a partition key is declared on, panicking when it does not exist
+func (b *EntityTypeBuilder) partitionField(fieldName string) FieldModel {
+	field, exists := b.entity.Fields[fieldName]
+	if !exists {
+		panic(fmt.Sprintf("Field '%s' not found on %s", fieldName, b.entity.Name))
+	}
+	return field
+}
+
+// Model returns the partitioning being configured
+func (b *PartitionBuilder) Model() *PartitionModel {
+	return b.entity.Partition
+}
+
+// Premake keeps count range partitions after the current one created, so rows never land in the
+// DEFAULT partition as long as maintenance runs more often than count intervals
+func (b *PartitionBuilder) Premake(count int) *PartitionBuilder {
+	if count < 0 {
+		count = 0
+	}
+	b.entity.Partition.Premake = count
+	notify(b.changed, b.entity)
+	return b
+}
+
+// Retain keeps count range partitions before the current one attached; maintenance detaches older
+// partitions, which stay in the database as standalone tables to archive or drop
+func (b *PartitionBuilder) Retain(count int) *PartitionBuilder {
+	if count < 0 {
+		count = 0
+	}
+	b.entity.Partition.Retention = count
+	notify(b.changed, b.entity)
+	return b
+}
+
+// PartitionClause returns the PARTITION BY clause that ends the table's CREATE TABLE statement
+func (p *PartitionModel) PartitionClause() string {
+	return fmt.Sprintf(`PARTITION BY %s ("%s")`, p.Strategy, p.Column)
+}
+
+// PartitionStart returns the start of the range partition containing t, in UTC
+func (p *PartitionModel) PartitionStart(t time.Time) time.Time {
+	t = t.UTC()
+	switch p.Interval {
+	case PartitionDaily:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	case PartitionYearly:
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// NextPartitionStart returns the start of the range partition after the one starting at start
+func (p *PartitionModel) NextPartitionStart(start time.Time) time.Time {
+	return p.ShiftPartitionStart(start, 1)
+}
+
+// ShiftPartitionStart returns the start of the range partition count intervals after (or, negative,
+// before) the one starting at start
+func (p *PartitionModel) ShiftPartitionStart(start time.Time, count int) time.Time {
+	switch p.Interval {
+	case PartitionDaily:
+		return start.AddDate(0, 0, count)
+	case PartitionYearly:
+		return start.AddDate(count, 0, 0)
+	default:
+		return start.AddDate(0, count, 0)
+	}
+}
+
+// nameLayout is the time layout of range partition name suffixes
+func (p *PartitionModel) nameLayout() string {
+	switch p.Interval {
+	case PartitionDaily:
+		return "20060102"
+	case PartitionYearly:
+		return "2006"
+	default:
+		return "200601"
+	}
+}
+
+// RangePartitionName names the range partition starting at start: Event_p202405 for May 2024 by month
+func (p *PartitionModel) RangePartitionName(table string, start time.Time) string {
+	return table + "_p" + start.Format(p.nameLayout())
+}
+
+// ParseRangePartitionName returns the start of a range partition of table from its name, false for
+// partitions gontext did not name (the DEFAULT partition, hand-made ones)
+func (p *PartitionModel) ParseRangePartitionName(table, name string) (time.Time, bool) {
+	suffix, found := strings.CutPrefix(name, table+"_p")
+	if !found || len(suffix) != len(p.nameLayout()) {
+		return time.Time{}, false
+	}
+	start, err := time.Parse(p.nameLayout(), suffix)
+	return start, err == nil
+}
+
+// RangePartitionBound returns the FOR VALUES clause of the range partition starting at start
+func (p *PartitionModel) RangePartitionBound(start time.Time) string {
+	const layout = "2006-01-02 15:04:05Z07:00"
+	return fmt.Sprintf("FOR VALUES FROM ('%s') TO ('%s')", start.Format(layout), p.NextPartitionStart(start).Format(layout))
+}
+
+// HashPartitionName names hash partition remainder of table: Reading_p0, Reading_p1, ...
+func HashPartitionName(table string, remainder int) string {
+	return fmt.Sprintf("%s_p%d", table, remainder)
+}
+
+// HashPartitionBound returns the FOR VALUES clause of a hash partition
+func HashPartitionBound(modulus, remainder int) string {
+	return fmt.Sprintf("FOR VALUES WITH (MODULUS %d, REMAINDER %d)", modulus, remainder)
+}
+
+// DefaultPartitionName names the DEFAULT partition of a range-partitioned table
+func DefaultPartitionName(table string) string {
+	return table + "_default"
+}
+
+// CreatePartitionSQL returns the statement that creates a partition of table; bound is a FOR VALUES
+// clause or DEFAULT
+func CreatePartitionSQL(table, partition, bound string) string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS "%s" PARTITION OF "%s" %s`, partition, table, bound)
+}
+
+// InitialPartitions returns the partitions migrations create with the table, by name with their bound:
+// every hash partition, or the DEFAULT partition of a range-partitioned table
+func (p *PartitionModel) InitialPartitions(table string) [][2]string {
+	if p.Strategy == PartitionByHash {
+		partitions := make([][2]string, p.Modulus)
+		for remainder := range partitions {
+			partitions[remainder] = [2]string{HashPartitionName(table, remainder), HashPartitionBound(p.Modulus, remainder)}
+		}
+		return partitions
+	}
+	return [][2]string{{DefaultPartitionName(table), "DEFAULT"}}
+}
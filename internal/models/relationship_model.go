@@ -0,0 +1,116 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DeleteBehavior is what happens to dependent rows when their principal is deleted (or its key updated)
+// - EF Core: DeleteBehavior. The zero value leaves it to the database, which means NO ACTION
+type DeleteBehavior string
+
+const (
+	DeleteDefault  DeleteBehavior = ""
+	DeleteCascade  DeleteBehavior = "CASCADE"   // Delete the dependents too
+	DeleteSetNull  DeleteBehavior = "SET NULL"  // Clear the dependents' foreign keys
+	DeleteRestrict DeleteBehavior = "RESTRICT"  // Refuse while dependents exist, checked immediately
+	DeleteNoAction DeleteBehavior = "NO ACTION" // Refuse while dependents exist, checked at the end of the statement
+)
+
+// ParseDeleteBehavior reads a referential action as written in SQL or GORM constraint tags, e.g. "SET NULL"
+func ParseDeleteBehavior(action string) DeleteBehavior {
+	return DeleteBehavior(strings.ToUpper(strings.Join(strings.Fields(action), " ")))
+}
+
+// RelationshipModel is a foreign key declared through the model builder - EF Core:
+// entity.HasOne(x => x.Sender).WithMany().HasForeignKey(x => x.SenderID)
+// It's needed when navigations can't describe the relationship: self references, several foreign keys
+// to the same entity, or foreign keys without a navigation
+type RelationshipModel struct {
+	ForeignKey     string // Go field name of the foreign key on the dependent entity
+	Principal      string // Name of the referenced entity, which may be the dependent entity itself
+	PrincipalKey   string // Go field name of the referenced column; empty for the principal's key
+	ConstraintName string // Empty for the default fk_<table>_<column>
+	OnDelete       DeleteBehavior
+	OnUpdate       DeleteBehavior
+}
+
+// ForeignKeyModel is a resolved foreign key, declared with HasForeignKey or taken from a navigation: a
+// dependent entity's field referencing a principal entity's field
+type ForeignKeyModel struct {
+	Dependent      *EntityModel
+	Field          FieldModel
+	Principal      *EntityModel
+	PrincipalField FieldModel
+	ReferencesKey  bool // PrincipalField is the principal's key rather than another (unique) field
+	ConstraintName string
+	OnDelete       DeleteBehavior
+	OnUpdate       DeleteBehavior
+}
+
+// RelationshipBuilder configures a declared foreign key
+type RelationshipBuilder struct {
+	entity       *EntityModel
+	relationship *RelationshipModel
+	changed      func(*EntityModel)
+}
+
+// HasForeignKey declares that a field references another entity's key; migrations create the constraint
+// Usage: gontext.Entity[Message](ctx).HasForeignKey("SenderID", "User"), or for a self reference
+// gontext.Entity[Comment](ctx).HasForeignKey("ParentID", "Comment")
+// Declaring the same field again replaces its relationship. Panics on unknown fields, since this is a
+// configuration bug
+func (b *EntityTypeBuilder) HasForeignKey(fieldName, principal string) *RelationshipBuilder {
+	if _, exists := b.entity.Fields[fieldName]; !exists {
+		panic(fmt.Sprintf("Field '%s' not found on %s", fieldName, b.entity.Name))
+	}
+
+	relationship := &RelationshipModel{ForeignKey: fieldName, Principal: principal}
+	replaced := false
+	for i, existing := range b.entity.Relationships {
+		if existing.ForeignKey == fieldName {
+			b.entity.Relationships[i] = relationship
+			replaced = true
+		}
+	}
+	if !replaced {
+		b.entity.Relationships = append(b.entity.Relationships, relationship)
+	}
+	notify(b.changed, b.entity)
+	return &RelationshipBuilder{entity: b.entity, relationship: relationship, changed: b.changed}
+}
+
+// Model returns the relationship model being configured
+func (b *RelationshipBuilder) Model() *RelationshipModel {
+	return b.relationship
+}
+
+// HasPrincipalKey references a unique field of the principal instead of its key - EF Core: HasPrincipalKey
+func (b *RelationshipBuilder) HasPrincipalKey(fieldName string) *RelationshipBuilder {
+	b.relationship.PrincipalKey = fieldName
+	notify(b.changed, b.entity)
+	return b
+}
+
+// OnDelete sets what happens to dependents when the principal is deleted - EF Core: OnDelete
+// Usage: HasForeignKey("ParentID", "Comment").OnDelete(gontext.DeleteSetNull)
+// SaveChanges applies it to tracked dependents too, and migrations emit it as ON DELETE
+func (b *RelationshipBuilder) OnDelete(behavior DeleteBehavior) *RelationshipBuilder {
+	b.relationship.OnDelete = behavior
+	notify(b.changed, b.entity)
+	return b
+}
+
+// OnUpdate sets what happens to dependents when the principal's key changes, emitted as ON UPDATE
+func (b *RelationshipBuilder) OnUpdate(behavior DeleteBehavior) *RelationshipBuilder {
+	b.relationship.OnUpdate = behavior
+	notify(b.changed, b.entity)
+	return b
+}
+
+// HasConstraintName names the foreign key constraint instead of fk_<table>_<column>
+func (b *RelationshipBuilder) HasConstraintName(name string) *RelationshipBuilder {
+	b.relationship.ConstraintName = name
+	notify(b.changed, b.entity)
+	return b
+}
@@ -0,0 +1,43 @@
+package models
+
+import "fmt"
+
+// SequenceModel is a database sequence declared on the context, independent of any entity's keys
+type SequenceModel struct {
+	Name      string
+	StartWith int64 // First value handed out
+	// BlockSize is the number of values reserved per round trip; the sequence increments by it so every
+	// nextval() reserves a whole block. Values are unique but, across processes and restarts, not gap-free
+	BlockSize int
+}
+
+// SequenceBuilder configures a declared sequence - EF Core: modelBuilder.HasSequence<long>(name)
+type SequenceBuilder struct {
+	sequence *SequenceModel
+}
+
+// NewSequenceBuilder creates a builder that mutates the given sequence model
+func NewSequenceBuilder(sequence *SequenceModel) *SequenceBuilder {
+	return &SequenceBuilder{sequence: sequence}
+}
+
+// Model returns the sequence model being configured
+func (b *SequenceBuilder) Model() *SequenceModel {
+	return b.sequence
+}
+
+// StartsAt sets the first value of the sequence
+func (b *SequenceBuilder) StartsAt(value int64) *SequenceBuilder {
+	b.sequence.StartWith = value
+	return b
+}
+
+// BlockSize reserves size values per database round trip and hands them out from memory
+// Panics when size is not positive, since this is a configuration bug
+func (b *SequenceBuilder) BlockSize(size int) *SequenceBuilder {
+	if size <= 0 {
+		panic(fmt.Sprintf("Block size of sequence %s must be positive, got %d", b.sequence.Name, size))
+	}
+	b.sequence.BlockSize = size
+	return b
+}
@@ -12,14 +12,25 @@ type ModelSnapshot struct {
 	Version   string                     `json:"version"`
 	Timestamp time.Time                  `json:"timestamp"`
 	Entities  map[string]EntitySnapshot  `json:"entities"`
+	Sequences map[string]SequenceSnapshot `json:"sequences,omitempty"`
 	Checksum  string                     `json:"checksum"`
 }
 
+// SequenceSnapshot records a sequence declared with DbContext.HasSequence
+type SequenceSnapshot struct {
+	Name        string `json:"name"`
+	StartWith   int64  `json:"start_with"`
+	IncrementBy int    `json:"increment_by"`
+}
+
 type EntitySnapshot struct {
 	Name      string                    `json:"name"`
 	TableName string                    `json:"table_name"`
 	Fields    map[string]FieldSnapshot  `json:"fields"`
 	Indexes   []IndexSnapshot           `json:"indexes"`
+	Partition *PartitionModel           `json:"partition,omitempty"`
+	View      *ViewModel                `json:"view,omitempty"`
+	Triggers  []TriggerModel            `json:"triggers,omitempty"`
 }
 
 type FieldSnapshot struct {
@@ -31,12 +42,22 @@ type FieldSnapshot struct {
 	IsUnique     bool                   `json:"is_unique"`
 	DefaultValue *string                `json:"default_value"`
 	Tags         map[string]string      `json:"tags"`
+	IsIdentity   bool                   `json:"is_identity,omitempty"`
+	HiLoSequence string                 `json:"hilo_sequence,omitempty"`
+	MaxLength    int                    `json:"max_length,omitempty"`
+	Collation    string                 `json:"collation,omitempty"`
+	TextSearch   string                 `json:"text_search,omitempty"`
+	Trigram      bool                   `json:"trigram,omitempty"`
+	ComputedSql  string                 `json:"computed_sql,omitempty"`
+	IsStored     bool                   `json:"is_stored,omitempty"`
 }
 
 type IndexSnapshot struct {
-	Name     string   `json:"name"`
-	Columns  []string `json:"columns"`
-	IsUnique bool     `json:"is_unique"`
+	Name       string   `json:"name"`
+	Columns    []string `json:"columns"`
+	IsUnique   bool     `json:"is_unique"`
+	Expression string   `json:"expression,omitempty"`
+	Filter     string   `json:"filter,omitempty"`
 }
 
 func NewModelSnapshot(entities map[string]*EntityModel) *ModelSnapshot {
@@ -52,6 +73,8 @@ func NewModelSnapshot(entities map[string]*EntityModel) *ModelSnapshot {
 			TableName: entity.TableName,
 			Fields:    make(map[string]FieldSnapshot),
 			Indexes:   []IndexSnapshot{},
+			Partition: entity.Partition,
+			View:      entity.View,
 		}
 
 		for fieldName, field := range entity.Fields {
@@ -64,10 +87,32 @@ func NewModelSnapshot(entities map[string]*EntityModel) *ModelSnapshot {
 				IsUnique:     field.IsUnique,
 				DefaultValue: field.DefaultValue,
 				Tags:         field.Tags,
+				IsIdentity:   field.ValueGeneration == ValueGeneratedByIdentity,
+				HiLoSequence: field.HiLoSequence,
+				MaxLength:    field.MaxLength,
+				Collation:    field.Collation,
+				TextSearch:   field.TextSearchConfig,
+				Trigram:      field.Trigram,
+				ComputedSql:  field.ComputedColumnSql,
+				IsStored:     field.IsComputedStored,
 			}
 			entitySnapshot.Fields[fieldName] = fieldSnapshot
 		}
 
+		for _, trigger := range entity.Triggers {
+			entitySnapshot.Triggers = append(entitySnapshot.Triggers, *trigger)
+		}
+
+		for _, index := range EntityIndexes(entity) {
+			entitySnapshot.Indexes = append(entitySnapshot.Indexes, IndexSnapshot{
+				Name:       index.Name,
+				Columns:    index.Columns,
+				IsUnique:   index.IsUnique,
+				Expression: index.Expression,
+				Filter:     index.Filter,
+			})
+		}
+
 		snapshot.Entities[entity.Name] = entitySnapshot
 	}
 
@@ -76,11 +121,31 @@ func NewModelSnapshot(entities map[string]*EntityModel) *ModelSnapshot {
 	return snapshot
 }
 
+// WithSequences adds the context's declared sequences to the snapshot
+func (s *ModelSnapshot) WithSequences(sequences map[string]*SequenceModel) *ModelSnapshot {
+	if len(sequences) == 0 {
+		return s
+	}
+	s.Sequences = make(map[string]SequenceSnapshot, len(sequences))
+	for name, sequence := range sequences {
+		s.Sequences[name] = SequenceSnapshot{
+			Name:        sequence.Name,
+			StartWith:   sequence.StartWith,
+			IncrementBy: sequence.BlockSize,
+		}
+	}
+	s.Checksum = s.calculateChecksum()
+	return s
+}
+
 func (s *ModelSnapshot) calculateChecksum() string {
 	// Create a stable representation for checksum
 	data := make(map[string]interface{})
 	data["version"] = s.Version
 	data["entities"] = s.Entities
+	if len(s.Sequences) > 0 { // Leave checksums of snapshots without sequences unchanged
+		data["sequences"] = s.Sequences
+	}
 
 	jsonData, _ := json.Marshal(data)
 	return fmt.Sprintf("%x", md5.Sum(jsonData))
@@ -118,12 +183,53 @@ func (s *ModelSnapshot) Compare(other *ModelSnapshot) *SnapshotComparison {
 		}
 	}
 
+	comparison.Changes = append(comparison.Changes, s.compareSequences(other)...)
+
 	comparison.HasChanges = len(comparison.Changes) > 0
 	return comparison
 }
 
+// compareSequences reports declared sequences that were added, removed or given another block size
+// A new start value only matters when the sequence is created, so it is not a change
+func (s *ModelSnapshot) compareSequences(other *ModelSnapshot) []SnapshotChange {
+	var changes []SnapshotChange
+	for name, current := range s.Sequences {
+		previous, exists := other.Sequences[name]
+		switch {
+		case !exists:
+			changes = append(changes, SnapshotChange{Type: SequenceAdded, EntityName: name, Details: current})
+		case previous.IncrementBy != current.IncrementBy:
+			changes = append(changes, SnapshotChange{
+				Type:       SequenceModified,
+				EntityName: name,
+				Details:    SequenceComparison{Old: previous, New: current},
+			})
+		}
+	}
+	for name, previous := range other.Sequences {
+		if _, exists := s.Sequences[name]; !exists {
+			changes = append(changes, SnapshotChange{Type: SequenceRemoved, EntityName: name, Details: previous})
+		}
+	}
+	return changes
+}
+
 func (s *ModelSnapshot) compareEntities(current, other EntitySnapshot) []SnapshotChange {
 	var changes []SnapshotChange
+
+	// The columns of a view follow from its query, so only the view itself and its indexes are compared
+	if current.View != nil || other.View != nil {
+		return s.compareViews(current, other)
+	}
+
+	// A renamed table comes first so the entity's other changes apply to the new name
+	if other.TableName != "" && other.TableName != current.TableName {
+		changes = append(changes, SnapshotChange{
+			Type:       TableRenamed,
+			EntityName: current.Name,
+			Details:    TableRename{OldName: other.TableName, NewName: current.TableName},
+		})
+	}
 	
 	// First pass: identify all renames to avoid double-processing
 	renamedFields := make(map[string]string) // oldName -> newName
@@ -202,9 +308,89 @@ func (s *ModelSnapshot) compareEntities(current, other EntitySnapshot) []Snapsho
 		}
 	}
 
+	changes = append(changes, s.compareIndexes(current, other)...)
+	changes = append(changes, s.compareTriggers(current, other)...)
+
 	return changes
 }
 
+// compareViews reports a materialized view whose name or query changed, which replaces it, and then
+// all of its indexes since they are dropped with it; otherwise only its index changes
+func (s *ModelSnapshot) compareViews(current, other EntitySnapshot) []SnapshotChange {
+	if current.View != nil && other.View != nil && viewsEqual(*current.View, *other.View) {
+		return s.compareIndexes(current, other)
+	}
+
+	changes := []SnapshotChange{{
+		Type:       ViewReplaced,
+		EntityName: current.Name,
+		Details:    ViewComparison{Old: other.View, New: current.View},
+	}}
+	for _, index := range current.Indexes {
+		changes = append(changes, SnapshotChange{Type: IndexAdded, EntityName: current.Name, Details: index})
+	}
+	return changes
+}
+
+func viewsEqual(a, b ViewModel) bool {
+	return a.Name == b.Name && a.Query == b.Query
+}
+
+// compareIndexes reports indexes that were added or removed; a changed index is removed and added again
+func (s *ModelSnapshot) compareIndexes(current, other EntitySnapshot) []SnapshotChange {
+	var changes []SnapshotChange
+	previous := make(map[string]IndexSnapshot, len(other.Indexes))
+	for _, index := range other.Indexes {
+		previous[index.Name] = index
+	}
+	existing := make(map[string]IndexSnapshot, len(current.Indexes))
+	for _, index := range current.Indexes {
+		existing[index.Name] = index
+	}
+
+	for _, index := range other.Indexes {
+		if currentIndex, exists := existing[index.Name]; !exists || !indexesEqual(currentIndex, index) {
+			changes = append(changes, SnapshotChange{Type: IndexRemoved, EntityName: current.Name, Details: index})
+		}
+	}
+	for _, index := range current.Indexes {
+		if previousIndex, exists := previous[index.Name]; !exists || !indexesEqual(index, previousIndex) {
+			changes = append(changes, SnapshotChange{Type: IndexAdded, EntityName: current.Name, Details: index})
+		}
+	}
+	return changes
+}
+
+// compareTriggers reports triggers that were added or removed; a changed trigger is removed and added again
+func (s *ModelSnapshot) compareTriggers(current, other EntitySnapshot) []SnapshotChange {
+	var changes []SnapshotChange
+	previous := make(map[string]TriggerModel, len(other.Triggers))
+	for _, trigger := range other.Triggers {
+		previous[trigger.Name] = trigger
+	}
+	existing := make(map[string]TriggerModel, len(current.Triggers))
+	for _, trigger := range current.Triggers {
+		existing[trigger.Name] = trigger
+	}
+
+	for _, trigger := range other.Triggers {
+		if currentTrigger, exists := existing[trigger.Name]; !exists || !TriggersEqual(currentTrigger, trigger) {
+			changes = append(changes, SnapshotChange{Type: TriggerRemoved, EntityName: current.Name, Details: trigger})
+		}
+	}
+	for _, trigger := range current.Triggers {
+		if previousTrigger, exists := previous[trigger.Name]; !exists || !TriggersEqual(trigger, previousTrigger) {
+			changes = append(changes, SnapshotChange{Type: TriggerAdded, EntityName: current.Name, Details: trigger})
+		}
+	}
+	return changes
+}
+
+func indexesEqual(a, b IndexSnapshot) bool {
+	return a.IsUnique == b.IsUnique && strings.Join(a.Columns, ",") == strings.Join(b.Columns, ",") &&
+		a.Expression == b.Expression && a.Filter == b.Filter
+}
+
 func (s *ModelSnapshot) findRenamedField(oldField FieldSnapshot, currentFields map[string]FieldSnapshot) *string {
 	// First check for explicit old_name tag
 	for fieldName, currentField := range currentFields {
@@ -299,6 +485,12 @@ func (s *ModelSnapshot) fieldsEqual(field1, field2 FieldSnapshot) bool {
 		field1.IsPrimary == field2.IsPrimary &&
 		field1.IsNullable == field2.IsNullable &&
 		field1.IsUnique == field2.IsUnique &&
+		field1.MaxLength == field2.MaxLength &&
+		field1.Collation == field2.Collation &&
+		field1.TextSearch == field2.TextSearch &&
+		field1.Trigram == field2.Trigram &&
+		field1.ComputedSql == field2.ComputedSql &&
+		field1.IsStored == field2.IsStored &&
 		((field1.DefaultValue == nil && field2.DefaultValue == nil) ||
 			(field1.DefaultValue != nil && field2.DefaultValue != nil && *field1.DefaultValue == *field2.DefaultValue))
 }
@@ -325,8 +517,35 @@ const (
 	FieldRemoved
 	FieldModified
 	FieldRenamed
+	SequenceAdded // EntityName holds the sequence name for sequence changes
+	SequenceRemoved
+	SequenceModified
+	IndexAdded // Details holds the IndexSnapshot
+	IndexRemoved
+	TableRenamed // Details holds the TableRename
+	ViewReplaced // Details holds the ViewComparison
+	TriggerAdded // Details holds the TriggerModel
+	TriggerRemoved
 )
 
+// TableRename records an entity whose table name changed (ToTable, TableName() or the table naming)
+type TableRename struct {
+	OldName string `json:"old_name"`
+	NewName string `json:"new_name"`
+}
+
+// ViewComparison records a materialized view that is dropped and created again; Old or New is nil when
+// an entity switched between a table and a view
+type ViewComparison struct {
+	Old *ViewModel `json:"old"`
+	New *ViewModel `json:"new"`
+}
+
+type SequenceComparison struct {
+	Old SequenceSnapshot `json:"old"`
+	New SequenceSnapshot `json:"new"`
+}
+
 type FieldComparison struct {
 	Old FieldSnapshot `json:"old"`
 	New FieldSnapshot `json:"new"`
@@ -0,0 +1,152 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TriggerTiming is when a trigger fires relative to the change that fires it
+type TriggerTiming string
+
+const (
+	TriggerBefore TriggerTiming = "BEFORE"
+	TriggerAfter  TriggerTiming = "AFTER"
+)
+
+// TriggerEvent is a change that fires a trigger
+type TriggerEvent string
+
+const (
+	TriggerOnInsert TriggerEvent = "INSERT"
+	TriggerOnUpdate TriggerEvent = "UPDATE"
+	TriggerOnDelete TriggerEvent = "DELETE"
+)
+
+// TriggerModel is a trigger and its trigger function declared through the model builder; it is stored
+// in model snapshots so migrations create, replace and drop it like other schema objects
+type TriggerModel struct {
+	Name         string         `json:"name"`
+	Timing       TriggerTiming  `json:"timing"`
+	Events       []TriggerEvent `json:"events"`
+	ForStatement bool           `json:"for_statement,omitempty"` // FOR EACH STATEMENT instead of FOR EACH ROW
+	When         string         `json:"when,omitempty"`          // Condition the trigger fires on, e.g. OLD.* IS DISTINCT FROM NEW.*
+	Body         string         `json:"body"`                    // PL/pgSQL statements between BEGIN and END
+}
+
+// TriggerBuilder configures a declared trigger
+type TriggerBuilder struct {
+	entity  *EntityModel
+	trigger *TriggerModel
+	changed func(*EntityModel)
+}
+
+// HasTrigger declares a row trigger whose PL/pgSQL body runs at timing for the given events; migrations
+// create it with its trigger function, replace it when it changes and drop it when it is removed
+// Usage:
+//
+//	gontext.Entity[Post](ctx).HasTrigger("Post_audit", gontext.TriggerAfter, gontext.TriggerOnDelete).
+//	    Executes(`INSERT INTO "PostAudit" ("PostId", "DeletedAt") VALUES (OLD."Id", now()); RETURN OLD;`)
+//
+// Declaring a name again replaces the earlier trigger. Panics without events, since this is a configuration bug
+func (b *EntityTypeBuilder) HasTrigger(name string, timing TriggerTiming, events ...TriggerEvent) *TriggerBuilder {
+	if name == "" || len(events) == 0 {
+		panic(fmt.Sprintf("Trigger on %s needs a name and at least one event", b.entity.Name))
+	}
+
+	trigger := &TriggerModel{Name: name, Timing: timing, Events: append([]TriggerEvent(nil), events...)}
+	replaced := false
+	for i, existing := range b.entity.Triggers {
+		if existing.Name == name {
+			b.entity.Triggers[i] = trigger
+			replaced = true
+		}
+	}
+	if !replaced {
+		b.entity.Triggers = append(b.entity.Triggers, trigger)
+	}
+	notify(b.changed, b.entity)
+	return &TriggerBuilder{entity: b.entity, trigger: trigger, changed: b.changed}
+}
+
+// HasUpdatedAtTrigger sets a time field to now() on every update in the database, so rows changed by raw
+// SQL or other applications are stamped too; the trigger is named <Table>_touch_<Column>
+// Usage: gontext.Entity[Post](ctx).HasUpdatedAtTrigger("UpdatedAt")
+// Panics on an unknown field, since this is a configuration bug
+func (b *EntityTypeBuilder) HasUpdatedAtTrigger(fieldName string) *TriggerBuilder {
+	field, exists := b.entity.Fields[fieldName]
+	if !exists {
+		panic(fmt.Sprintf("Field '%s' not found on %s", fieldName, b.entity.Name))
+	}
+	name := fmt.Sprintf("%s_touch_%s", b.entity.TableName, field.ColumnName)
+	return b.HasTrigger(name, TriggerBefore, TriggerOnUpdate).
+		Executes(fmt.Sprintf(`NEW."%s" = now(); RETURN NEW;`, field.ColumnName))
+}
+
+// Model returns the trigger being configured
+func (b *TriggerBuilder) Model() *TriggerModel {
+	return b.trigger
+}
+
+// Executes sets the PL/pgSQL statements the trigger function runs; row triggers return NEW (or OLD for
+// deletes), or NULL from a BEFORE trigger to skip the change
+func (b *TriggerBuilder) Executes(body string) *TriggerBuilder {
+	b.trigger.Body = strings.TrimSpace(body)
+	notify(b.changed, b.entity)
+	return b
+}
+
+// When fires the trigger only for changes matching condition, e.g. OLD."Status" IS DISTINCT FROM NEW."Status"
+func (b *TriggerBuilder) When(condition string) *TriggerBuilder {
+	b.trigger.When = condition
+	notify(b.changed, b.entity)
+	return b
+}
+
+// ForEachStatement fires the trigger once per statement instead of once per changed row
+func (b *TriggerBuilder) ForEachStatement() *TriggerBuilder {
+	b.trigger.ForStatement = true
+	notify(b.changed, b.entity)
+	return b
+}
+
+// FunctionName returns the name of the trigger's function
+func (t TriggerModel) FunctionName() string {
+	return t.Name + "_fn"
+}
+
+// CreateTriggerSQL returns the statements that create the trigger function and the trigger on table
+func CreateTriggerSQL(table string, trigger TriggerModel) []string {
+	events := make([]string, len(trigger.Events))
+	for i, event := range trigger.Events {
+		events[i] = string(event)
+	}
+	level := "ROW"
+	if trigger.ForStatement {
+		level = "STATEMENT"
+	}
+	when := ""
+	if trigger.When != "" {
+		when = fmt.Sprintf(" WHEN (%s)", trigger.When)
+	}
+
+	return []string{
+		fmt.Sprintf(`CREATE OR REPLACE FUNCTION "%s"() RETURNS trigger LANGUAGE plpgsql AS $gontext$ BEGIN %s END; $gontext$`,
+			trigger.FunctionName(), trigger.Body),
+		fmt.Sprintf(`CREATE TRIGGER "%s" %s %s ON "%s" FOR EACH %s%s EXECUTE FUNCTION "%s"()`,
+			trigger.Name, trigger.Timing, strings.Join(events, " OR "), table, level, when, trigger.FunctionName()),
+	}
+}
+
+// DropTriggerSQL returns the statements that drop the trigger on table and its function
+func DropTriggerSQL(table string, trigger TriggerModel) []string {
+	return []string{
+		fmt.Sprintf(`DROP TRIGGER IF EXISTS "%s" ON "%s"`, trigger.Name, table),
+		fmt.Sprintf(`DROP FUNCTION IF EXISTS "%s"()`, trigger.FunctionName()),
+	}
+}
+
+// TriggersEqual reports whether two declarations of a trigger create the same trigger
+func TriggersEqual(a, b TriggerModel) bool {
+	return a.Name == b.Name && a.Timing == b.Timing && a.ForStatement == b.ForStatement && a.When == b.When &&
+		a.Body == b.Body && fmt.Sprint(a.Events) == fmt.Sprint(b.Events)
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ViewModel maps a keyless entity to a materialized view instead of a table; it is stored in model
+// snapshots so migrations can create, replace and drop the view
+type ViewModel struct {
+	Name  string `json:"name"`
+	Query string `json:"query"` // SELECT the view is defined by
+	// DependsOn lists the entities or tables the query reads that migrations cannot find in it; quoted
+	// table names of registered entities in the query are found automatically
+	DependsOn []string `json:"depends_on,omitempty"`
+}
+
+// ViewBuilder configures a materialized view declared with ToMaterializedView
+type ViewBuilder struct {
+	entity  *EntityModel
+	changed func(*EntityModel)
+}
+
+// ToMaterializedView maps the entity to a materialized view defined by query; migrations create it after
+// the tables it reads, and ctx.Views().Refresh recomputes its rows. The entity is keyless: its rows are
+// never tracked and SaveChanges rejects changes to them - EF Core: entity.HasNoKey().ToView(name)
+// Usage:
+//
+//	gontext.Entity[DailyStats](ctx).ToMaterializedView("daily_stats",
+//	    `SELECT date_trunc('day', "CreatedAt") AS "Day", count(*) AS "Orders" FROM "Order" GROUP BY 1`)
+//
+// Panics on an empty name or query, since this is a configuration bug
+func (b *EntityTypeBuilder) ToMaterializedView(name, query string) *ViewBuilder {
+	if name == "" || strings.TrimSpace(query) == "" {
+		panic(fmt.Sprintf("Materialized view of %s needs a name and a query", b.entity.Name))
+	}
+	b.entity.View = &ViewModel{Name: name, Query: strings.TrimSpace(query)}
+	b.entity.TableName = name
+	b.entity.TableConfigured = true
+	b.entity.PrimaryKey = nil
+	notify(b.changed, b.entity)
+	return &ViewBuilder{entity: b.entity, changed: b.changed}
+}
+
+// Model returns the view being configured
+func (b *ViewBuilder) Model() *ViewModel {
+	return b.entity.View
+}
+
+// DependsOn declares entities (by name) or tables the view reads, for queries that name them in ways
+// migrations cannot see, e.g. unquoted or through a function
+func (b *ViewBuilder) DependsOn(names ...string) *ViewBuilder {
+	for _, name := range names {
+		b.entity.View.DependsOn = appendDependency(b.entity.View.DependsOn, name)
+	}
+	notify(b.changed, b.entity)
+	return b
+}
+
+// ViewDependencies returns the names of the entities a view entity reads: those declared with DependsOn,
+// by entity or table name, and those whose quoted table name appears in the view's query
+func ViewDependencies(entity *EntityModel, entities map[string]*EntityModel) []string {
+	if entity.View == nil {
+		return nil
+	}
+	var dependencies []string
+	for _, other := range entities {
+		if other.Name == entity.Name {
+			continue
+		}
+		if strings.Contains(entity.View.Query, `"`+other.TableName+`"`) {
+			dependencies = appendDependency(dependencies, other.Name)
+			continue
+		}
+		for _, name := range entity.View.DependsOn {
+			if name == other.Name || name == other.TableName {
+				dependencies = appendDependency(dependencies, other.Name)
+			}
+		}
+	}
+	sort.Strings(dependencies)
+	return dependencies
+}
+
+// CreateViewSQL returns the statement that creates a materialized view
+func CreateViewSQL(view ViewModel) string {
+	return fmt.Sprintf(`CREATE MATERIALIZED VIEW IF NOT EXISTS "%s" AS %s`, view.Name, view.Query)
+}
+
+// DropViewSQL returns the statement that drops a materialized view
+func DropViewSQL(name string) string {
+	return fmt.Sprintf(`DROP MATERIALIZED VIEW IF EXISTS "%s"`, name)
+}
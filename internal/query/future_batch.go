@@ -0,0 +1,207 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/logger"
+)
+
+var (
+	// errBatchUnsupported means the pending queries cannot share a pgx batch and run one by one
+	errBatchUnsupported = errors.New("connection does not support query batches")
+	errNoColumnTypes    = errors.New("column types are not available for batched queries")
+)
+
+// FutureBatch holds the deferred queries of one context. Awaiting any of them runs every pending one:
+// in a single pgx batch round trip on PostgreSQL, one after another on other drivers and in transactions
+// A nil *FutureBatch gives every query a batch of its own
+type FutureBatch struct {
+	mu      sync.Mutex
+	pending []*FutureQuery
+}
+
+// FutureQuery is a query whose execution is deferred until it, or another query of its batch, is awaited
+type FutureQuery struct {
+	batch  *FutureBatch
+	db     *gorm.DB                   // Session the query runs on
+	run    func(tx *gorm.DB) *gorm.DB // Runs the query into its destination
+	loaded func()                     // Called once the destination is filled, may be nil
+	stmt   *gorm.DB                   // Dry run of run holding SQL, Vars and destination; nil runs it on its own
+	err    error
+}
+
+// NewFutureBatch creates an empty batch
+func NewFutureBatch() *FutureBatch {
+	return &FutureBatch{}
+}
+
+// Queue defers run on db. The SQL is built now, so a later flush only sends it
+func (b *FutureBatch) Queue(db *gorm.DB, run func(tx *gorm.DB) *gorm.DB, loaded func()) *FutureQuery {
+	if b == nil {
+		b = NewFutureBatch()
+	}
+	query := &FutureQuery{batch: b, db: db.Session(&gorm.Session{}), run: run, loaded: loaded}
+
+	stmt := run(query.db.Session(&gorm.Session{DryRun: true, Logger: logger.Discard}))
+	// Preloads issue follow-up queries once the rows are read, so those queries run on their own
+	if stmt.Error == nil && len(stmt.Statement.Preloads) == 0 && stmt.Statement.SQL.Len() > 0 {
+		query.stmt = stmt
+	}
+
+	b.mu.Lock()
+	b.pending = append(b.pending, query)
+	b.mu.Unlock()
+	return query
+}
+
+// Wait runs the query's batch unless it has run already and returns the query's error
+func (q *FutureQuery) Wait() error {
+	q.batch.Flush()
+	return q.err
+}
+
+// Flush runs every pending query of the batch; queries that already ran keep their outcome
+func (b *FutureBatch) Flush() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	pending := b.pending
+	b.pending = nil
+
+	var batched []*FutureQuery
+	for _, query := range pending {
+		if query.stmt != nil {
+			batched = append(batched, query)
+		} else {
+			query.execute()
+		}
+	}
+	if len(batched) > 1 && sendBatch(batched) == nil {
+		return
+	}
+	for _, query := range batched {
+		query.execute()
+	}
+}
+
+// execute runs the query through GORM on its own
+func (q *FutureQuery) execute() {
+	q.err = q.run(q.db).Error
+	q.finish()
+}
+
+func (q *FutureQuery) finish() {
+	if q.err == nil && q.loaded != nil {
+		q.loaded()
+	}
+}
+
+// sendBatch sends the queries in one pgx batch and scans each result set into its destination
+// It returns errBatchUnsupported, having run nothing, when the queries do not share a pgx-backed *sql.DB
+func sendBatch(queries []*FutureQuery) error {
+	var sqlDB *sql.DB
+	switch pool := queries[0].stmt.Statement.ConnPool.(type) {
+	case *sql.DB:
+		sqlDB = pool
+	case *StatementCache:
+		sqlDB = pool.db
+	default:
+		return errBatchUnsupported // Transactions pin a connection database/sql does not hand out
+	}
+	for _, query := range queries[1:] {
+		if query.stmt.Statement.ConnPool != queries[0].stmt.Statement.ConnPool {
+			return errBatchUnsupported
+		}
+	}
+
+	ctx := queries[0].stmt.Statement.Context
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		for _, query := range queries {
+			query.err = err
+		}
+		return nil
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn interface{}) error {
+		pgxConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errBatchUnsupported
+		}
+
+		batch := &pgx.Batch{}
+		for _, query := range queries {
+			batch.Queue(query.stmt.Statement.SQL.String(), query.stmt.Statement.Vars...)
+		}
+		start := time.Now()
+		results := pgxConn.Conn().SendBatch(ctx, batch)
+		defer results.Close()
+
+		for _, query := range queries {
+			query.scan(ctx, results, start)
+		}
+		return nil
+	})
+}
+
+// scan reads the query's result set from the batch the way its query callbacks would
+func (q *FutureQuery) scan(ctx context.Context, results pgx.BatchResults, start time.Time) {
+	tx := q.stmt
+	rows, err := results.Query()
+	if err != nil {
+		tx.AddError(err)
+	} else {
+		scanRows(tx, batchRows{rows})
+		rows.Close()
+		if err := rows.Err(); err != nil && tx.Error == nil {
+			tx.AddError(err)
+		}
+		callbacks.AfterQuery(tx)
+	}
+
+	q.db.Logger.Trace(ctx, start, func() (string, int64) {
+		sql, vars := tx.Statement.SQL.String(), tx.Statement.Vars
+		if filter, ok := q.db.Logger.(gorm.ParamsFilter); ok {
+			sql, vars = filter.ParamsFilter(ctx, sql, vars...)
+		}
+		return tx.Dialector.Explain(sql, vars...), tx.RowsAffected
+	}, tx.Error)
+	q.err = tx.Error
+	q.finish()
+}
+
+// batchRows adapts pgx rows to the gorm.Rows interface GORM's scanner reads
+type batchRows struct {
+	pgx.Rows
+}
+
+func (r batchRows) Columns() ([]string, error) {
+	fields := r.FieldDescriptions()
+	columns := make([]string, len(fields))
+	for i, field := range fields {
+		columns[i] = field.Name
+	}
+	return columns, nil
+}
+
+// ColumnTypes is only read for map destinations, which futures do not use
+func (r batchRows) ColumnTypes() ([]*sql.ColumnType, error) {
+	return nil, errNoColumnTypes
+}
+
+func (r batchRows) Close() error {
+	r.Rows.Close()
+	return r.Rows.Err()
+}
@@ -0,0 +1,200 @@
+package query
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// MaskedValue replaces sensitive parameters in logged SQL
+const MaskedValue = "***"
+
+// MaskingLogger wraps a GORM logger so parameters bound to sensitive columns are logged as MaskedValue,
+// in query traces, slow query warnings and error logs alike
+type MaskingLogger struct {
+	logger.Interface
+	sensitive func(column string) bool
+}
+
+// NewMaskingLogger wraps inner; sensitive reports whether a column name holds sensitive values
+func NewMaskingLogger(inner logger.Interface, sensitive func(column string) bool) *MaskingLogger {
+	return &MaskingLogger{Interface: inner, sensitive: sensitive}
+}
+
+// LogMode keeps the masking when the level changes, e.g. through db.Debug()
+func (l *MaskingLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return &MaskingLogger{Interface: l.Interface.LogMode(level), sensitive: l.sensitive}
+}
+
+// ParamsFilter masks sensitive parameters before GORM interpolates them into the logged SQL
+func (l *MaskingLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	if filter, ok := l.Interface.(gorm.ParamsFilter); ok {
+		sql, params = filter.ParamsFilter(ctx, sql, params...)
+	}
+	return sql, MaskParams(sql, params, l.sensitive)
+}
+
+// MaskParams returns params with the values bound to sensitive columns replaced by MaskedValue
+// params is returned as is when nothing is masked
+func MaskParams(sql string, params []interface{}, sensitive func(column string) bool) []interface{} {
+	if len(params) == 0 || sensitive == nil {
+		return params
+	}
+
+	var masked []interface{}
+	for _, index := range SensitiveParams(sql, sensitive) {
+		if index < 0 || index >= len(params) {
+			continue
+		}
+		if masked == nil {
+			masked = append([]interface{}(nil), params...)
+		}
+		masked[index] = MaskedValue
+	}
+	if masked == nil {
+		return params
+	}
+	return masked
+}
+
+// comparisonKeywords sit between a column and its parameter without ending the comparison
+var comparisonKeywords = map[string]bool{
+	"LIKE": true, "ILIKE": true, "IN": true, "NOT": true, "IS": true, "BETWEEN": true,
+	"SIMILAR": true, "TO": true, "ANY": true, "ALL": true, "ESCAPE": true,
+}
+
+// SensitiveParams returns the indexes of the parameters of sql bound to sensitive columns: INSERT
+// values by their column list, and comparisons, IN lists and SET assignments by the column before them
+// Parameters that cannot be attributed to a column are not reported
+func SensitiveParams(sql string, sensitive func(column string) bool) []int {
+	var tokens []sqlToken
+	for _, token := range tokenizeSQL(sql) {
+		if token.Kind != tokenSpace && token.Kind != tokenComment {
+			tokens = append(tokens, token)
+		}
+	}
+
+	var indexes []int
+	next := 0 // Index of the next ? placeholder
+	paramIndex := func(token sqlToken) int {
+		if strings.HasPrefix(token.Text, "$") {
+			n, _ := strconv.Atoi(token.Text[1:])
+			return n - 1
+		}
+		next++
+		return next - 1
+	}
+	mark := func(token sqlToken, column string) {
+		index := paramIndex(token)
+		if column != "" && sensitive(column) {
+			indexes = append(indexes, index)
+		}
+	}
+
+	column := ""     // Column the next parameter compares with or is assigned to
+	between := false // The AND of BETWEEN ? AND ? continues the comparison
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		upper := strings.ToUpper(token.Text)
+
+		switch {
+		case token.Kind == tokenIdentifier && upper == "INSERT":
+			i = insertStatement(tokens, i, mark)
+			column = ""
+		case token.Kind == tokenPlaceholder:
+			mark(token, column)
+		case token.Kind == tokenQuotedIdentifier:
+			column = unquoteIdentifier(token.Text)
+		case token.Kind == tokenIdentifier:
+			switch {
+			case i+1 < len(tokens) && tokens[i+1].Text == "(":
+				// Function call such as LOWER(...): keeps the column it is applied to or compared with
+			case upper == "BETWEEN":
+				between = true
+			case upper == "AND" && between:
+				between = false
+			case comparisonKeywords[upper]:
+			case isKeyword(upper):
+				column = ""
+			default:
+				column = token.Text
+			}
+		}
+	}
+	return indexes
+}
+
+// insertStatement attributes the VALUES parameters of an INSERT starting at tokens[start] to its column
+// list and returns the index of the last token it consumed
+func insertStatement(tokens []sqlToken, start int, mark func(sqlToken, string)) int {
+	i := start + 1
+	// INSERT INTO table ( columns )
+	for i < len(tokens) && tokens[i].Text != "(" {
+		if strings.EqualFold(tokens[i].Text, "VALUES") || strings.EqualFold(tokens[i].Text, "SELECT") {
+			return i - 1
+		}
+		i++
+	}
+	var columns []string
+	for i++; i < len(tokens) && tokens[i].Text != ")"; i++ {
+		if tokens[i].Kind == tokenQuotedIdentifier || tokens[i].Kind == tokenIdentifier {
+			columns = append(columns, unquoteIdentifier(tokens[i].Text))
+		}
+	}
+
+	// VALUES (...), (...)
+	if i+1 >= len(tokens) || !strings.EqualFold(tokens[i+1].Text, "VALUES") {
+		return i
+	}
+	i += 2
+	for i < len(tokens) && tokens[i].Text == "(" {
+		position, depth := 0, 1
+		for i++; i < len(tokens) && depth > 0; i++ {
+			switch tokens[i].Text {
+			case "(":
+				depth++
+			case ")":
+				depth--
+			case ",":
+				if depth == 1 {
+					position++
+				}
+			}
+			if tokens[i].Kind == tokenPlaceholder {
+				column := ""
+				if position < len(columns) {
+					column = columns[position]
+				}
+				mark(tokens[i], column)
+			}
+		}
+		if i < len(tokens) && tokens[i].Text == "," {
+			i++
+		}
+	}
+	return i - 1
+}
+
+func unquoteIdentifier(text string) string {
+	if len(text) >= 2 && (text[0] == '"' || text[0] == '`') {
+		quote := string(text[0])
+		return strings.ReplaceAll(text[1:len(text)-1], quote+quote, quote)
+	}
+	return text
+}
+
+// isKeyword reports SQL keywords that start a new clause or condition, so the previous column no
+// longer applies (e.g. LIMIT ?, AND other = ?)
+func isKeyword(word string) bool {
+	switch word {
+	case "SELECT", "FROM", "WHERE", "AND", "OR", "SET", "VALUES", "LIMIT", "OFFSET", "ORDER", "GROUP",
+		"BY", "HAVING", "JOIN", "LEFT", "RIGHT", "INNER", "OUTER", "ON", "AS", "UPDATE", "DELETE",
+		"INTO", "RETURNING", "CASE", "WHEN", "THEN", "ELSE", "END", "NULL", "TRUE", "FALSE", "DISTINCT",
+		"UNION", "EXISTS", "ASC", "DESC", "FETCH", "FOR", "CONFLICT", "DO", "NOTHING", "WITH":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,334 @@
+package query
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/schema"
+)
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+	bytesType   = reflect.TypeOf([]byte(nil))
+)
+
+// MaterializerPlugin replaces GORM's query callback so slices of entities are scanned through a
+// column-ordinal mapping cached per entity type and column list: each column is scanned straight into
+// its field's memory instead of GORM's per-row field lookups, value pools and reflective Set calls
+// Queries it cannot map exactly - joins, serializer fields, embedded pointers, unusual field types,
+// single-row destinations - fall back to GORM's scanner unchanged
+type MaterializerPlugin struct {
+	mappers sync.Map // materializerKey -> *materializer, nil when the shape needs GORM's scanner
+}
+
+type materializerKey struct {
+	model   reflect.Type
+	columns string
+}
+
+// materializer maps result columns, by ordinal, to field offsets of one entity type
+type materializer struct {
+	model   reflect.Type
+	columns []columnMapping
+}
+
+type columnMapping struct {
+	offset uintptr
+	typ    reflect.Type                                   // Field type; nil discards the column
+	holder func() interface{}                             // Nullable scan target for non-pointer basic fields
+	assign func(field unsafe.Pointer, holder interface{}) // Copies the holder into the field
+}
+
+// NewMaterializerPlugin creates the plugin
+func NewMaterializerPlugin() *MaterializerPlugin {
+	return &MaterializerPlugin{}
+}
+
+// Name returns the plugin name
+func (p *MaterializerPlugin) Name() string {
+	return "gontext:materializer"
+}
+
+// Initialize swaps gorm:query for the mapped scanner
+func (p *MaterializerPlugin) Initialize(db *gorm.DB) error {
+	return db.Callback().Query().Replace("gorm:query", p.query)
+}
+
+// query is callbacks.Query with the mapped scanner in front of gorm.Scan
+func (p *MaterializerPlugin) query(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	callbacks.BuildQuerySQL(db)
+	if db.DryRun || db.Error != nil {
+		return
+	}
+
+	rows, err := db.Statement.ConnPool.QueryContext(db.Statement.Context, db.Statement.SQL.String(), db.Statement.Vars...)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	defer func() {
+		db.AddError(rows.Close())
+	}()
+
+	if !p.scan(db, rows) {
+		gorm.Scan(rows, db, 0)
+	}
+}
+
+// scanRows scans rows into db's destination the way its query callback would
+func scanRows(db *gorm.DB, rows gorm.Rows) {
+	if p, ok := db.Config.Plugins[(&MaterializerPlugin{}).Name()].(*MaterializerPlugin); ok && p.scan(db, rows) {
+		return
+	}
+	gorm.Scan(rows, db, 0)
+}
+
+// scan materializes rows into a slice destination, reporting false when GORM must scan instead
+func (p *MaterializerPlugin) scan(db *gorm.DB, rows gorm.Rows) bool {
+	stmt := db.Statement
+	if stmt.Schema == nil || len(stmt.Joins) > 0 {
+		return false
+	}
+
+	slice := stmt.ReflectValue
+	if slice.Kind() == reflect.Interface {
+		slice = slice.Elem()
+	}
+	if slice.Kind() != reflect.Slice || !slice.CanSet() {
+		return false
+	}
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+	if elemType != stmt.Schema.ModelType {
+		return false
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return false
+	}
+	mapper := p.mapper(stmt.Schema, columns)
+	if mapper == nil {
+		return false
+	}
+
+	if slice.Cap() == 0 {
+		slice = reflect.MakeSlice(slice.Type(), 0, 20)
+	} else {
+		slice.SetLen(0)
+	}
+
+	var discard interface{}
+	values := make([]interface{}, len(mapper.columns))
+	for i, column := range mapper.columns {
+		switch {
+		case column.typ == nil:
+			values[i] = &discard
+		case column.holder != nil:
+			values[i] = column.holder()
+		}
+	}
+
+	for rows.Next() {
+		db.RowsAffected++
+		elem := reflect.New(mapper.model)
+		base := elem.UnsafePointer()
+		for i, column := range mapper.columns {
+			if column.typ != nil && column.holder == nil {
+				values[i] = reflect.NewAt(column.typ, unsafe.Add(base, column.offset)).Interface()
+			}
+		}
+		if err := rows.Scan(values...); err != nil {
+			db.AddError(err)
+			break
+		}
+		for i, column := range mapper.columns {
+			if column.assign != nil {
+				column.assign(unsafe.Add(base, column.offset), values[i])
+			}
+		}
+
+		if isPtr {
+			slice = reflect.Append(slice, elem)
+		} else {
+			slice = reflect.Append(slice, elem.Elem())
+		}
+	}
+	stmt.ReflectValue.Set(slice)
+
+	if err := rows.Err(); err != nil && err != db.Error {
+		db.AddError(err)
+	}
+	return true
+}
+
+// mapper returns the cached mapping for an entity type and column list, building it on first use
+func (p *MaterializerPlugin) mapper(sch *schema.Schema, columns []string) *materializer {
+	key := materializerKey{model: sch.ModelType, columns: strings.Join(columns, "\x00")}
+	if cached, exists := p.mappers.Load(key); exists {
+		return cached.(*materializer)
+	}
+	mapper := buildMaterializer(sch, columns)
+	p.mappers.Store(key, mapper)
+	return mapper
+}
+
+func buildMaterializer(sch *schema.Schema, columns []string) *materializer {
+	mapper := &materializer{model: sch.ModelType, columns: make([]columnMapping, len(columns))}
+	seen := make(map[string]bool, len(columns))
+
+	for i, column := range columns {
+		if seen[column] {
+			return nil // GORM pairs duplicate columns with duplicate fields
+		}
+		seen[column] = true
+
+		field := sch.LookUpField(column)
+		if field == nil || !field.Readable {
+			if strings.Contains(column, "__") {
+				return nil // Nested relation column
+			}
+			continue
+		}
+		if field.Serializer != nil {
+			return nil
+		}
+
+		offset, ok := fieldOffset(sch.ModelType, field.StructField.Index)
+		if !ok {
+			return nil
+		}
+		mapping, ok := mapFieldType(field.FieldType)
+		if !ok {
+			return nil
+		}
+		mapping.offset = offset
+		mapper.columns[i] = mapping
+	}
+	return mapper
+}
+
+// fieldOffset sums the struct offsets along an index path; pointer-embedded structs are not addressable inline
+func fieldOffset(model reflect.Type, index []int) (uintptr, bool) {
+	var offset uintptr
+	current := model
+	for _, i := range index {
+		if i < 0 || current.Kind() != reflect.Struct {
+			return 0, false
+		}
+		structField := current.Field(i)
+		offset += structField.Offset
+		current = structField.Type
+	}
+	return offset, true
+}
+
+// mapFieldType picks how a column reaches a field: scanners, pointers and []byte are scanned in place
+// (database/sql handles NULL for them); basic values go through a nullable holder so NULL leaves
+// the zero value, as GORM does
+func mapFieldType(typ reflect.Type) (columnMapping, bool) {
+	mapping := columnMapping{typ: typ}
+	switch {
+	case reflect.PointerTo(typ).Implements(scannerType), typ == bytesType:
+		return mapping, true
+	case typ == timeType:
+		mapping.holder = func() interface{} { return new(sql.NullTime) }
+		mapping.assign = func(field unsafe.Pointer, holder interface{}) {
+			*(*time.Time)(field) = holder.(*sql.NullTime).Time
+		}
+		return mapping, true
+	case typ.Kind() == reflect.Ptr:
+		elem := typ.Elem()
+		if reflect.PointerTo(elem).Implements(scannerType) || elem == timeType || isBasicKind(elem.Kind()) {
+			return mapping, true
+		}
+		return mapping, false
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		mapping.holder = func() interface{} { return new(sql.NullString) }
+		mapping.assign = func(field unsafe.Pointer, holder interface{}) {
+			*(*string)(field) = holder.(*sql.NullString).String
+		}
+	case reflect.Bool:
+		mapping.holder = func() interface{} { return new(sql.NullBool) }
+		mapping.assign = func(field unsafe.Pointer, holder interface{}) {
+			*(*bool)(field) = holder.(*sql.NullBool).Bool
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		kind := typ.Kind()
+		mapping.holder = func() interface{} { return new(sql.Null[int64]) }
+		mapping.assign = func(field unsafe.Pointer, holder interface{}) {
+			value := holder.(*sql.Null[int64]).V
+			switch kind {
+			case reflect.Int:
+				*(*int)(field) = int(value)
+			case reflect.Int8:
+				*(*int8)(field) = int8(value)
+			case reflect.Int16:
+				*(*int16)(field) = int16(value)
+			case reflect.Int32:
+				*(*int32)(field) = int32(value)
+			default:
+				*(*int64)(field) = value
+			}
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		kind := typ.Kind()
+		mapping.holder = func() interface{} { return new(sql.Null[uint64]) }
+		mapping.assign = func(field unsafe.Pointer, holder interface{}) {
+			value := holder.(*sql.Null[uint64]).V
+			switch kind {
+			case reflect.Uint:
+				*(*uint)(field) = uint(value)
+			case reflect.Uint8:
+				*(*uint8)(field) = uint8(value)
+			case reflect.Uint16:
+				*(*uint16)(field) = uint16(value)
+			case reflect.Uint32:
+				*(*uint32)(field) = uint32(value)
+			default:
+				*(*uint64)(field) = value
+			}
+		}
+	case reflect.Float32, reflect.Float64:
+		kind := typ.Kind()
+		mapping.holder = func() interface{} { return new(sql.NullFloat64) }
+		mapping.assign = func(field unsafe.Pointer, holder interface{}) {
+			value := holder.(*sql.NullFloat64).Float64
+			if kind == reflect.Float32 {
+				*(*float32)(field) = float32(value)
+			} else {
+				*(*float64)(field) = value
+			}
+		}
+	default:
+		return mapping, false
+	}
+	return mapping, true
+}
+
+func isBasicKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
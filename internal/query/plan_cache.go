@@ -0,0 +1,155 @@
+package query
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultPlanCacheSize is the number of translated conditions and field references kept per context
+const DefaultPlanCacheSize = 2048
+
+// PlanKind separates the translation steps whose output is cached
+type PlanKind uint8
+
+const (
+	PlanCondition PlanKind = iota // Raw Where/Or/All/None condition with quoted identifiers
+	PlanField                     // Field, column or json name resolved to its quoted column
+)
+
+// PlanCacheStats reports query plan cache usage
+type PlanCacheStats struct {
+	Entries     int   // Cached translations
+	Translators int   // Entity types whose field/column maps are built
+	Capacity    int   // Maximum cached translations
+	Hits        int64 // Translations served from the cache
+	Misses      int64 // Translations computed
+	Evictions   int64 // Translations dropped to stay within Capacity
+}
+
+// HitRatio is Hits / (Hits + Misses), or 0 before the first lookup
+func (s PlanCacheStats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+type planKey struct {
+	entity reflect.Type
+	kind   PlanKind
+	input  string
+}
+
+// PlanCache keeps the output of the LINQ translation pipeline (per-entity translators, quoted
+// conditions and field references) so repeated chains skip reflection, schema lookups and tokenizing
+// Every cached step is a pure function of the entity type and its input for the lifetime of a context
+// A nil *PlanCache computes every translation
+type PlanCache struct {
+	capacity int
+
+	mu          sync.RWMutex
+	plans       map[planKey]string
+	translators map[reflect.Type]*PostgreSQLQueryTranslator
+
+	hits      atomic.Int64
+	misses    atomic.Int64
+	evictions atomic.Int64
+}
+
+// NewPlanCache creates a cache; capacity 0 uses DefaultPlanCacheSize and a negative capacity disables it
+func NewPlanCache(capacity int) *PlanCache {
+	if capacity < 0 {
+		return nil
+	}
+	if capacity == 0 {
+		capacity = DefaultPlanCacheSize
+	}
+	return &PlanCache{
+		capacity:    capacity,
+		plans:       make(map[planKey]string),
+		translators: make(map[reflect.Type]*PostgreSQLQueryTranslator),
+	}
+}
+
+// Translator returns the entity's translator, building it on first use
+func (c *PlanCache) Translator(entityType reflect.Type, build func() *PostgreSQLQueryTranslator) *PostgreSQLQueryTranslator {
+	if c == nil {
+		return build()
+	}
+
+	c.mu.RLock()
+	translator, exists := c.translators[entityType]
+	c.mu.RUnlock()
+	if exists {
+		return translator
+	}
+
+	translator = build()
+	c.mu.Lock()
+	c.translators[entityType] = translator
+	c.mu.Unlock()
+	return translator
+}
+
+// Lookup returns the cached translation of input, computing it with build on a miss
+// Beyond capacity an arbitrary entry is dropped: conditions built from runtime values are cold,
+// so a full LRU is not worth a write lock on every hit
+func (c *PlanCache) Lookup(entityType reflect.Type, kind PlanKind, input string, build func() string) string {
+	if c == nil {
+		return build()
+	}
+
+	key := planKey{entity: entityType, kind: kind, input: input}
+	c.mu.RLock()
+	output, exists := c.plans[key]
+	c.mu.RUnlock()
+	if exists {
+		c.hits.Add(1)
+		return output
+	}
+
+	c.misses.Add(1)
+	output = build()
+	c.mu.Lock()
+	if _, exists := c.plans[key]; !exists && len(c.plans) >= c.capacity {
+		for evicted := range c.plans {
+			delete(c.plans, evicted)
+			c.evictions.Add(1)
+			break
+		}
+	}
+	c.plans[key] = output
+	c.mu.Unlock()
+	return output
+}
+
+// Stats returns the current cache counters
+func (c *PlanCache) Stats() PlanCacheStats {
+	if c == nil {
+		return PlanCacheStats{}
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return PlanCacheStats{
+		Entries:     len(c.plans),
+		Translators: len(c.translators),
+		Capacity:    c.capacity,
+		Hits:        c.hits.Load(),
+		Misses:      c.misses.Load(),
+		Evictions:   c.evictions.Load(),
+	}
+}
+
+// Clear drops every cached translation and translator, e.g. after entity registrations changed
+func (c *PlanCache) Clear() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.plans = make(map[planKey]string)
+	c.translators = make(map[reflect.Type]*PostgreSQLQueryTranslator)
+}
@@ -4,18 +4,22 @@ import (
 	"reflect"
 
 	"gorm.io/gorm/schema"
+
+	"github.com/shepherrrd/gontext/internal/models"
 )
 
 // PostgreSQLNamingStrategy implements GORM's NamingStrategy interface for PostgreSQL Pascal case
 type PostgreSQLNamingStrategy struct {
 	schema.NamingStrategy
-	translator *PostgreSQLQueryTranslator
+	translator  *PostgreSQLQueryTranslator
+	constraints models.ConstraintNaming
 }
 
 // NewPostgreSQLNamingStrategy creates a new PostgreSQL naming strategy
 func NewPostgreSQLNamingStrategy() *PostgreSQLNamingStrategy {
 	return &PostgreSQLNamingStrategy{
-		translator: NewPostgreSQLQueryTranslator(),
+		translator:  NewPostgreSQLQueryTranslator(),
+		constraints: models.DefaultConstraintNaming{},
 	}
 }
 
@@ -39,28 +43,24 @@ func (ns *PostgreSQLNamingStrategy) RelationshipFKName(rel schema.Relationship)
 	return rel.Name + "ID"
 }
 
+// SetConstraintNaming names check constraints and indexes through naming
+func (ns *PostgreSQLNamingStrategy) SetConstraintNaming(naming models.ConstraintNaming) {
+	ns.constraints = naming
+}
+
 // CheckerName returns the checker name
 func (ns *PostgreSQLNamingStrategy) CheckerName(table, column string) string {
-	return "chk_" + table + "_" + column
+	return ns.constraints.CheckName(table, column)
 }
 
 // IndexName returns the index name
 func (ns *PostgreSQLNamingStrategy) IndexName(table, column string) string {
-	return "idx_" + table + "_" + column
+	return ns.constraints.IndexName(table, []string{column})
 }
 
 // RegisterEntityFields registers field names for query translation
 func (ns *PostgreSQLNamingStrategy) RegisterEntityFields(entityName string, entityType reflect.Type) {
-	var fieldNames []string
-	
-	for i := 0; i < entityType.NumField(); i++ {
-		field := entityType.Field(i)
-		if field.PkgPath == "" { // exported field
-			fieldNames = append(fieldNames, field.Name)
-		}
-	}
-	
-	ns.translator.RegisterEntityFields(entityName, fieldNames)
+	ns.translator.RegisterEntityFields(entityName, models.FieldNames(entityType))
 }
 
 // TranslateQuery translates WHERE conditions for PostgreSQL
@@ -71,4 +71,68 @@ func (ns *PostgreSQLNamingStrategy) TranslateQuery(entityName, condition string)
 // GetTranslator returns the query translator
 func (ns *PostgreSQLNamingStrategy) GetTranslator() *PostgreSQLQueryTranslator {
 	return ns.translator
-}
\ No newline at end of file
+}
+// ConventionNamingStrategy maps table and column names through a naming convention (snake_case, camelCase, ...)
+// so GORM resolves the same names the migrations create. Table names are never pluralized
+type ConventionNamingStrategy struct {
+	schema.NamingStrategy
+	convert     func(string) string
+	constraints models.ConstraintNaming
+}
+
+// NewConventionNamingStrategy creates a naming strategy that applies convert to Go names
+func NewConventionNamingStrategy(convert func(string) string) *ConventionNamingStrategy {
+	return &ConventionNamingStrategy{convert: convert, constraints: models.DefaultConstraintNaming{}}
+}
+
+// TableName converts the struct name
+func (ns *ConventionNamingStrategy) TableName(table string) string {
+	return ns.convert(table)
+}
+
+// ColumnName converts the field name
+func (ns *ConventionNamingStrategy) ColumnName(table, column string) string {
+	return ns.convert(column)
+}
+
+// JoinTableName converts the join table name
+func (ns *ConventionNamingStrategy) JoinTableName(joinTable string) string {
+	return ns.convert(joinTable)
+}
+
+// RelationshipFKName returns the foreign key name
+func (ns *ConventionNamingStrategy) RelationshipFKName(rel schema.Relationship) string {
+	return "fk_" + rel.Schema.Table + "_" + ns.convert(rel.Name)
+}
+
+// SetConstraintNaming names check constraints and indexes through naming
+func (ns *ConventionNamingStrategy) SetConstraintNaming(naming models.ConstraintNaming) {
+	ns.constraints = naming
+}
+
+// CheckerName returns the checker name
+func (ns *ConventionNamingStrategy) CheckerName(table, column string) string {
+	return ns.constraints.CheckName(table, column)
+}
+
+// IndexName returns the index name
+func (ns *ConventionNamingStrategy) IndexName(table, column string) string {
+	return ns.constraints.IndexName(table, []string{column})
+}
+
+// TableNamingStrategy replaces the table names of another naming strategy, so GORM names the tables of
+// the models it parses (including related entities it preloads) the way the entity models do
+type TableNamingStrategy struct {
+	schema.Namer
+	tableName func(string) string
+}
+
+// NewTableNamingStrategy wraps namer, naming tables with tableName
+func NewTableNamingStrategy(namer schema.Namer, tableName func(string) string) *TableNamingStrategy {
+	return &TableNamingStrategy{Namer: namer, tableName: tableName}
+}
+
+// TableName names the table of a struct
+func (ns *TableNamingStrategy) TableName(table string) string {
+	return ns.tableName(table)
+}
@@ -5,6 +5,8 @@ import (
 	"strings"
 
 	"gorm.io/gorm"
+
+	"github.com/shepherrrd/gontext/internal/models"
 )
 
 // PostgreSQLPlugin is a GORM plugin that automatically translates queries for PostgreSQL Pascal case
@@ -40,15 +42,7 @@ func (p *PostgreSQLPlugin) RegisterEntity(entityType reflect.Type, tableName str
 	p.entityMap[tableName] = entityType
 	
 	// Extract field names
-	var fieldNames []string
-	for i := 0; i < entityType.NumField(); i++ {
-		field := entityType.Field(i)
-		if field.PkgPath == "" { // exported field
-			fieldNames = append(fieldNames, field.Name)
-		}
-	}
-	
-	p.translator.RegisterEntityFields(tableName, fieldNames)
+	p.translator.RegisterEntityFields(tableName, models.FieldNames(entityType))
 }
 
 // translateWhereCallback translates WHERE conditions in GORM callbacks
@@ -1,19 +1,21 @@
 package query
 
 import (
-	"regexp"
 	"strings"
+	"unicode"
 )
 
 // PostgreSQLQueryTranslator handles automatic translation of field names to quoted PostgreSQL identifiers
 type PostgreSQLQueryTranslator struct {
-	entityFieldMap map[string][]string // entityType -> field names
+	entityFieldMap map[string][]string          // entityType -> field names
+	columnMap      map[string]map[string]string // entityType -> field name -> column name
 }
 
 // NewPostgreSQLQueryTranslator creates a new translator
 func NewPostgreSQLQueryTranslator() *PostgreSQLQueryTranslator {
 	return &PostgreSQLQueryTranslator{
 		entityFieldMap: make(map[string][]string),
+		columnMap:      make(map[string]map[string]string),
 	}
 }
 
@@ -22,129 +24,94 @@ func (t *PostgreSQLQueryTranslator) RegisterEntityFields(entityName string, fiel
 	t.entityFieldMap[entityName] = fieldNames
 }
 
+// RegisterEntityColumns registers the column each field maps to when it differs from the field name
+// (naming conventions, column tags)
+func (t *PostgreSQLQueryTranslator) RegisterEntityColumns(entityName string, columns map[string]string) {
+	t.columnMap[entityName] = columns
+}
+
+// ColumnName returns the column a field maps to, or the field name itself when none is registered
+func (t *PostgreSQLQueryTranslator) ColumnName(entityName, fieldName string) string {
+	if column, exists := t.columnMap[entityName][fieldName]; exists {
+		return column
+	}
+	return fieldName
+}
+
 // TranslateQuery translates a WHERE condition to use proper PostgreSQL quoted identifiers
 func (t *PostgreSQLQueryTranslator) TranslateQuery(entityName, condition string) string {
 	if fieldNames, exists := t.entityFieldMap[entityName]; exists {
-		return t.translateCondition(condition, fieldNames)
+		return t.translateCondition(condition, fieldNames, t.columnMap[entityName])
 	}
 	return condition
 }
 
-// translateCondition translates field names in a condition to quoted identifiers
-func (t *PostgreSQLQueryTranslator) translateCondition(condition string, fieldNames []string) string {
-	result := condition
-	
-	// Sort field names by length (descending) to match longer names first
-	// This prevents issues where "Name" might match part of "Username"
-	sortedFields := make([]string, len(fieldNames))
-	copy(sortedFields, fieldNames)
-	for i := 0; i < len(sortedFields)-1; i++ {
-		for j := i + 1; j < len(sortedFields); j++ {
-			if len(sortedFields[i]) < len(sortedFields[j]) {
-				sortedFields[i], sortedFields[j] = sortedFields[j], sortedFields[i]
-			}
-		}
+// translateCondition quotes the identifier tokens of a condition that name entity fields, mapping them to
+// their columns; string literals, quoted identifiers, function names and ::type casts are left alone
+func (t *PostgreSQLQueryTranslator) translateCondition(condition string, fieldNames []string, columns map[string]string) string {
+	tokens := tokenizeSQL(condition)
+	fields := make(map[string]bool, len(fieldNames))
+	for _, fieldName := range fieldNames {
+		fields[fieldName] = true
 	}
-	
-	for _, fieldName := range sortedFields {
-		// Skip if already quoted
-		if strings.Contains(result, "\""+fieldName+"\"") {
+
+	var result strings.Builder
+	result.Grow(len(condition) + 8)
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if token.Kind != tokenIdentifier {
+			result.WriteString(token.Text)
 			continue
 		}
-		
-		// Pattern to match field names in various SQL contexts
-		patterns := []string{
-			// Basic comparisons with flexible spacing: fieldName = ? or fieldName= ?
-			`\b` + regexp.QuoteMeta(fieldName) + `\s*(=|!=|<>|<|>|<=|>=)\s*`,
-			// LIKE/ILIKE with flexible spacing: fieldName LIKE ? or fieldName LIKE ?
-			`\b` + regexp.QuoteMeta(fieldName) + `\s+(LIKE|ILIKE)\s+`,
-			// IN/NOT IN with flexible spacing: fieldName IN ? or fieldName NOT IN ?
-			`\b` + regexp.QuoteMeta(fieldName) + `\s+(IN|NOT\s+IN)\s+`,
-			// IS NULL/IS NOT NULL: fieldName IS NULL
-			`\b` + regexp.QuoteMeta(fieldName) + `(\s+IS\s+(NOT\s+)?NULL)`,
-			// BETWEEN: fieldName BETWEEN
-			`\b` + regexp.QuoteMeta(fieldName) + `(\s+BETWEEN\s)`,
-			// ORDER BY: ORDER BY fieldName
-			`(ORDER\s+BY\s+)` + regexp.QuoteMeta(fieldName) + `(\s|$)`,
-			// GROUP BY: GROUP BY fieldName
-			`(GROUP\s+BY\s+)` + regexp.QuoteMeta(fieldName) + `(\s|$)`,
-			// SELECT: SELECT fieldName
-			`(SELECT\s+)` + regexp.QuoteMeta(fieldName) + `(\s|,|$)`,
-			// Functions: COUNT(fieldName), SUM(fieldName), etc.
-			`(COUNT\s*\(\s*)` + regexp.QuoteMeta(fieldName) + `(\s*\))`,
-			`(SUM\s*\(\s*)` + regexp.QuoteMeta(fieldName) + `(\s*\))`,
-			`(AVG\s*\(\s*)` + regexp.QuoteMeta(fieldName) + `(\s*\))`,
-			`(MIN\s*\(\s*)` + regexp.QuoteMeta(fieldName) + `(\s*\))`,
-			`(MAX\s*\(\s*)` + regexp.QuoteMeta(fieldName) + `(\s*\))`,
-		}
-		
-		for _, pattern := range patterns {
-			re := regexp.MustCompile(`(?i)` + pattern)
-			result = re.ReplaceAllStringFunc(result, func(match string) string {
-				return strings.ReplaceAll(match, fieldName, `"`+fieldName+`"`)
-			})
-		}
-	}
-	
-	return result
-}
 
-// TranslateComplexQuery handles complex WHERE queries with AND, OR, parentheses
-func (t *PostgreSQLQueryTranslator) TranslateComplexQuery(entityName, condition string) string {
-	if fieldNames, exists := t.entityFieldMap[entityName]; exists {
-		return t.translateComplexCondition(condition, fieldNames)
+		previous, next := significantToken(tokens, i, -1), significantToken(tokens, i, 1)
+		switch {
+		case previous == "::" || next == "(":
+			// Type names and function calls
+			result.WriteString(token.Text)
+		case isQualifiedReference(tokens, i):
+			// Qualified references to other tables, e.g. correlated subqueries: Users.Id -> "Users"."Id"
+			result.WriteString(`"` + token.Text + `"."` + tokens[i+2].Text + `"`)
+			i += 2
+		case fields[token.Text]:
+			column := token.Text
+			if mapped, exists := columns[token.Text]; exists {
+				column = mapped
+			}
+			result.WriteString(`"` + column + `"`)
+		default:
+			result.WriteString(token.Text)
+		}
 	}
-	return condition
+	return result.String()
 }
 
-// translateComplexCondition handles complex queries with logical operators
-func (t *PostgreSQLQueryTranslator) translateComplexCondition(condition string, fieldNames []string) string {
-	// First, handle simple field references
-	result := t.translateCondition(condition, fieldNames)
-	
-	// Handle complex cases with AND/OR/parentheses
-	// Split by logical operators while preserving them
-	parts := t.splitPreservingDelimiters(result, []string{" AND ", " OR ", "(", ")"})
-	
-	for i, part := range parts {
-		part = strings.TrimSpace(part)
-		if part != "" && part != "AND" && part != "OR" && part != "(" && part != ")" {
-			parts[i] = t.translateCondition(part, fieldNames)
+// significantToken returns the text of the nearest non-space token before (step -1) or after (step 1) index i
+func significantToken(tokens []sqlToken, i, step int) string {
+	for j := i + step; j >= 0 && j < len(tokens); j += step {
+		if tokens[j].Kind != tokenSpace && tokens[j].Kind != tokenComment {
+			return tokens[j].Text
 		}
 	}
-	
-	return strings.Join(parts, "")
+	return ""
 }
 
-// splitPreservingDelimiters splits a string by delimiters while keeping the delimiters
-func (t *PostgreSQLQueryTranslator) splitPreservingDelimiters(text string, delimiters []string) []string {
-	if len(delimiters) == 0 {
-		return []string{text}
+// isQualifiedReference matches an unqualified PascalCase Table.Column reference starting at index i
+func isQualifiedReference(tokens []sqlToken, i int) bool {
+	if i+2 >= len(tokens) || tokens[i+1].Text != "." || tokens[i+2].Kind != tokenIdentifier {
+		return false
 	}
-	
-	result := []string{text}
-	
-	for _, delimiter := range delimiters {
-		var newResult []string
-		for _, part := range result {
-			if strings.Contains(part, delimiter) {
-				split := strings.Split(part, delimiter)
-				for i, s := range split {
-					if i > 0 {
-						newResult = append(newResult, delimiter)
-					}
-					if s != "" {
-						newResult = append(newResult, s)
-					}
-				}
-			} else {
-				newResult = append(newResult, part)
-			}
-		}
-		result = newResult
+	if i > 0 && tokens[i-1].Text == "." {
+		return false
 	}
-	
-	return result
+	table, column := []rune(tokens[i].Text), []rune(tokens[i+2].Text)
+	return unicode.IsUpper(table[0]) && unicode.IsUpper(column[0])
+}
+
+// TranslateComplexQuery translates conditions with AND, OR and parentheses; the tokenizer handles
+// any nesting, so it is the same as TranslateQuery
+func (t *PostgreSQLQueryTranslator) TranslateComplexQuery(entityName, condition string) string {
+	return t.TranslateQuery(entityName, condition)
 }
 
 // GetQuotedFieldName returns a field name with PostgreSQL quotes
@@ -0,0 +1,43 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AuditSQL reports what makes a raw SQL fragment look injectable: inline string or numeric literals
+// instead of ? parameters, statement separators and comments
+// Quoted identifiers, ? / $1 / @name placeholders and single-character ESCAPE clauses are fine
+func AuditSQL(sql string) []string {
+	var issues []string
+	tokens := tokenizeSQL(sql)
+
+	for i, token := range tokens {
+		switch token.Kind {
+		case tokenQuotedIdentifier:
+			if token.Unterminated {
+				issues = append(issues, "unterminated quoted identifier")
+			}
+		case tokenString:
+			switch {
+			case token.Unterminated:
+				issues = append(issues, "unterminated string literal")
+			case strings.HasPrefix(token.Text, "$"):
+				issues = append(issues, "dollar-quoted string literal (use a ? parameter)")
+			case strings.EqualFold(significantToken(tokens, i, -1), "ESCAPE") && len([]rune(token.Text)) == 3:
+				// LIKE ? ESCAPE '\'
+			default:
+				issues = append(issues, fmt.Sprintf("string literal %s (use a ? parameter)", token.Text))
+			}
+		case tokenNumber:
+			issues = append(issues, fmt.Sprintf("numeric literal %s (use a ? parameter)", token.Text))
+		case tokenComment:
+			issues = append(issues, "comment "+token.Text[:2])
+		case tokenOperator:
+			if token.Text == ";" {
+				issues = append(issues, "statement separator ;")
+			}
+		}
+	}
+	return issues
+}
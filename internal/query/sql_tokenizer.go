@@ -0,0 +1,162 @@
+package query
+
+import (
+	"unicode"
+)
+
+// sqlTokenKind classifies a token of a SQL fragment
+type sqlTokenKind int
+
+const (
+	tokenSpace            sqlTokenKind = iota
+	tokenIdentifier                    // Unquoted word: field names, keywords, functions
+	tokenQuotedIdentifier              // "Name" or `name`
+	tokenString                        // 'text', E'text', $$text$$ or $tag$text$tag$
+	tokenNumber                        // 42, 3.14, 1e10
+	tokenPlaceholder                   // ?, $1, @name
+	tokenComment                       // -- line or /* block */
+	tokenOperator                      // Punctuation and operators, one rune at a time except ::
+)
+
+// sqlToken is a slice of the input; joining every token's Text gives the input back
+type sqlToken struct {
+	Kind         sqlTokenKind
+	Text         string
+	Unterminated bool // A quote or block comment ran to the end of the input
+}
+
+// tokenizeSQL splits a SQL fragment into tokens without interpreting it, so translation and auditing
+// never mistake the contents of string literals or quoted identifiers for SQL
+func tokenizeSQL(sql string) []sqlToken {
+	var tokens []sqlToken
+	runes := []rune(sql)
+
+	isWordStart := func(r rune) bool { return r == '_' || unicode.IsLetter(r) }
+	isWordRune := func(r rune) bool { return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r) }
+	at := func(i int) rune {
+		if i < len(runes) {
+			return runes[i]
+		}
+		return 0
+	}
+
+	for i := 0; i < len(runes); {
+		start := i
+		kind := tokenOperator
+		unterminated := false
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			kind = tokenSpace
+			for i < len(runes) && unicode.IsSpace(runes[i]) {
+				i++
+			}
+		case (r == 'E' || r == 'e') && at(i+1) == '\'':
+			// Escape string E'...'
+			kind = tokenString
+			i, unterminated = scanQuoted(runes, i+1, '\'', true)
+		case isWordStart(r):
+			kind = tokenIdentifier
+			for i < len(runes) && isWordRune(runes[i]) {
+				i++
+			}
+		case r == '"' || r == '`':
+			kind = tokenQuotedIdentifier
+			i, unterminated = scanQuoted(runes, i, r, false)
+		case r == '\'':
+			kind = tokenString
+			i, unterminated = scanQuoted(runes, i, '\'', false)
+		case r == '$' && unicode.IsDigit(at(i+1)):
+			kind = tokenPlaceholder
+			i++
+			for i < len(runes) && unicode.IsDigit(runes[i]) {
+				i++
+			}
+		case r == '$':
+			// Dollar-quoted string: $$...$$ or $tag$...$tag$
+			end := i + 1
+			for end < len(runes) && (runes[end] == '_' || unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end])) {
+				end++
+			}
+			if at(end) != '$' {
+				i++
+				break
+			}
+			kind = tokenString
+			tag := runes[i : end+1]
+			if close := indexRunes(runes, tag, end+1); close >= 0 {
+				i = close + len(tag)
+			} else {
+				i, unterminated = len(runes), true
+			}
+		case r == '?':
+			kind = tokenPlaceholder
+			i++
+		case r == '@' && isWordStart(at(i+1)):
+			kind = tokenPlaceholder
+			i++
+			for i < len(runes) && isWordRune(runes[i]) {
+				i++
+			}
+		case unicode.IsDigit(r) || (r == '.' && unicode.IsDigit(at(i+1))):
+			kind = tokenNumber
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			if (at(i) == 'e' || at(i) == 'E') && (unicode.IsDigit(at(i+1)) || ((at(i+1) == '+' || at(i+1) == '-') && unicode.IsDigit(at(i+2)))) {
+				i += 2
+				for i < len(runes) && unicode.IsDigit(runes[i]) {
+					i++
+				}
+			}
+		case r == '-' && at(i+1) == '-':
+			kind = tokenComment
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '/' && at(i+1) == '*':
+			kind = tokenComment
+			if end := indexRunes(runes, []rune("*/"), i+2); end >= 0 {
+				i = end + 2
+			} else {
+				i, unterminated = len(runes), true
+			}
+		case r == ':' && at(i+1) == ':':
+			i += 2
+		default:
+			i++
+		}
+
+		tokens = append(tokens, sqlToken{Kind: kind, Text: string(runes[start:i]), Unterminated: unterminated})
+	}
+	return tokens
+}
+
+// scanQuoted returns the index after the closing quote that matches runes[from]; doubled quotes
+// (and backslash escapes in E” strings) stay inside
+func scanQuoted(runes []rune, from int, quote rune, backslashEscapes bool) (int, bool) {
+	for i := from + 1; i < len(runes); i++ {
+		switch {
+		case backslashEscapes && runes[i] == '\\':
+			i++
+		case runes[i] == quote:
+			if i+1 < len(runes) && runes[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1, false
+		}
+	}
+	return len(runes), true
+}
+
+// indexRunes returns the index of the first occurrence of sub in runes at or after from, or -1
+func indexRunes(runes, sub []rune, from int) int {
+	for i := from; i+len(sub) <= len(runes); i++ {
+		if string(runes[i:i+len(sub)]) == string(sub) {
+			return i
+		}
+	}
+	return -1
+}
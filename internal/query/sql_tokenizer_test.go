@@ -0,0 +1,189 @@
+package query
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var translatorFields = []string{"Id", "Name", "Username", "Age", "CreatedAt", "Date", "Max", "Status"}
+
+func TestTranslateConditionQuotesOnlyFieldIdentifiers(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition string
+		want      string
+	}{
+		{"comparison", "Name = ?", `"Name" = ?`},
+		{"no spaces", "Age>=? AND Age<?", `"Age">=? AND "Age"<?`},
+		{"string literal", "Name = 'Name' OR Status = 'Age > 3'", `"Name" = 'Name' OR "Status" = 'Age > 3'`},
+		{"escaped quote in literal", "Name = 'it''s Name'", `"Name" = 'it''s Name'`},
+		{"escape string", `Name = E'Name\'s'`, `"Name" = E'Name\'s'`},
+		{"dollar quoted", "Name = $$Name$$ AND Age = $tag$Age$tag$", `"Name" = $$Name$$ AND "Age" = $tag$Age$tag$`},
+		{"already quoted", `"Name" = ? AND Age > ?`, `"Name" = ? AND "Age" > ?`},
+		{"quoted other identifier", `"Username" = ? AND Name = ?`, `"Username" = ? AND "Name" = ?`},
+		{"substring fields", "Username = ? OR Name = ?", `"Username" = ? OR "Name" = ?`},
+		{"field inside word", "Names = ? AND UsernameX = ?", "Names = ? AND UsernameX = ?"},
+		{"cast", "Age::text = ?", `"Age"::text = ?`},
+		{"cast to field named like type", "CreatedAt::Date = ?", `"CreatedAt"::Date = ?`},
+		{"cast with spaces", "CreatedAt :: Date > Date", `"CreatedAt" :: Date > "Date"`},
+		{"function", "LOWER(Name) LIKE ?", `LOWER("Name") LIKE ?`},
+		{"function named like field", "Max(Age) > Max", `Max("Age") > "Max"`},
+		{"function with space", "Max (Age) > ?", `Max ("Age") > ?`},
+		{"placeholders", "Id = $1 OR Name = @name", `"Id" = $1 OR "Name" = @name`},
+		{"line comment", "Name = ? -- Age", `"Name" = ? -- Age`},
+		{"block comment", "/* Name */ Age IS NULL", `/* Name */ "Age" IS NULL`},
+		{"qualified reference", "Id IN (SELECT UserId FROM Orders WHERE Orders.UserId = Users.Id)",
+			`"Id" IN (SELECT UserId FROM Orders WHERE "Orders"."UserId" = "Users"."Id")`},
+		{"keywords", "Name IS NOT NULL AND Age BETWEEN ? AND ? ORDER BY Name", `"Name" IS NOT NULL AND "Age" BETWEEN ? AND ? ORDER BY "Name"`},
+		{"unterminated literal", "Name = 'Age", `"Name" = 'Age`},
+	}
+
+	translator := NewPostgreSQLQueryTranslator()
+	translator.RegisterEntityFields("User", translatorFields)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := translator.TranslateQuery("User", tt.condition); got != tt.want {
+				t.Errorf("TranslateQuery(%q)\n got %s\nwant %s", tt.condition, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranslateConditionMapsColumns(t *testing.T) {
+	translator := NewPostgreSQLQueryTranslator()
+	translator.RegisterEntityFields("User", translatorFields)
+	translator.RegisterEntityColumns("User", map[string]string{"CreatedAt": "created_at", "Name": "name"})
+
+	got := translator.TranslateQuery("User", "CreatedAt > ? AND Name = 'CreatedAt' AND Age = ?")
+	want := `"created_at" > ? AND "name" = 'CreatedAt' AND "Age" = ?`
+	if got != want {
+		t.Errorf("got %s\nwant %s", got, want)
+	}
+	if got := translator.TranslateQuery("Unknown", "Name = ?"); got != "Name = ?" {
+		t.Errorf("unregistered entity translated to %s", got)
+	}
+}
+
+func TestTokenizeSQL(t *testing.T) {
+	tests := []struct {
+		sql   string
+		kinds []sqlTokenKind
+	}{
+		{"Age::int", []sqlTokenKind{tokenIdentifier, tokenOperator, tokenIdentifier}},
+		{`"A""b" 'c''d'`, []sqlTokenKind{tokenQuotedIdentifier, tokenSpace, tokenString}},
+		{"1.5e-3 .5 $2 ? @p", []sqlTokenKind{tokenNumber, tokenSpace, tokenNumber, tokenSpace, tokenPlaceholder,
+			tokenSpace, tokenPlaceholder, tokenSpace, tokenPlaceholder}},
+		{"$x$ a $x$b", []sqlTokenKind{tokenString, tokenIdentifier}},
+		{"a--c\nb", []sqlTokenKind{tokenIdentifier, tokenComment, tokenSpace, tokenIdentifier}},
+		{"ünïcode_1 <= x", []sqlTokenKind{tokenIdentifier, tokenSpace, tokenOperator, tokenOperator, tokenSpace, tokenIdentifier}},
+	}
+
+	for _, tt := range tests {
+		tokens := tokenizeSQL(tt.sql)
+		var joined strings.Builder
+		kinds := make([]sqlTokenKind, len(tokens))
+		for i, token := range tokens {
+			joined.WriteString(token.Text)
+			kinds[i] = token.Kind
+		}
+		if joined.String() != tt.sql {
+			t.Errorf("tokens of %q join to %q", tt.sql, joined.String())
+		}
+		if len(kinds) != len(tt.kinds) {
+			t.Errorf("%q: got kinds %v, want %v", tt.sql, kinds, tt.kinds)
+			continue
+		}
+		for i := range kinds {
+			if kinds[i] != tt.kinds[i] {
+				t.Errorf("%q: got kinds %v, want %v", tt.sql, kinds, tt.kinds)
+				break
+			}
+		}
+	}
+
+	for _, sql := range []string{"'open", `"open`, "/* open", "$$ open"} {
+		tokens := tokenizeSQL(sql)
+		if !tokens[len(tokens)-1].Unterminated {
+			t.Errorf("%q: last token not reported unterminated", sql)
+		}
+	}
+}
+
+var benchmarkConditions = []string{
+	"Name = ?",
+	"Username = ? OR Name = ? AND Age >= ?",
+	"LOWER(Name) LIKE ? AND Status IN ? AND CreatedAt::date = ? ORDER BY CreatedAt",
+	"Id IN (SELECT UserId FROM Orders WHERE Orders.UserId = Users.Id) AND Name = 'Age'",
+}
+
+func BenchmarkTranslateCondition(b *testing.B) {
+	translator := NewPostgreSQLQueryTranslator()
+	translator.RegisterEntityFields("User", translatorFields)
+
+	b.Run("tokenizer", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, condition := range benchmarkConditions {
+				translator.TranslateQuery("User", condition)
+			}
+		}
+	})
+	b.Run("regex", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, condition := range benchmarkConditions {
+				regexTranslateCondition(condition, translatorFields, nil)
+			}
+		}
+	})
+}
+
+// regexTranslateCondition is the regex translation the tokenizer replaced, kept for the benchmark
+func regexTranslateCondition(condition string, fieldNames []string, columns map[string]string) string {
+	result := condition
+
+	sortedFields := make([]string, len(fieldNames))
+	copy(sortedFields, fieldNames)
+	for i := 0; i < len(sortedFields)-1; i++ {
+		for j := i + 1; j < len(sortedFields); j++ {
+			if len(sortedFields[i]) < len(sortedFields[j]) {
+				sortedFields[i], sortedFields[j] = sortedFields[j], sortedFields[i]
+			}
+		}
+	}
+
+	for _, fieldName := range sortedFields {
+		column := fieldName
+		if mapped, exists := columns[fieldName]; exists {
+			column = mapped
+		}
+		if strings.Contains(result, "\""+column+"\"") {
+			continue
+		}
+
+		patterns := []string{
+			`\b` + regexp.QuoteMeta(fieldName) + `\s*(=|!=|<>|<|>|<=|>=)\s*`,
+			`\b` + regexp.QuoteMeta(fieldName) + `\s+(LIKE|ILIKE)\s+`,
+			`\b` + regexp.QuoteMeta(fieldName) + `\s+(IN|NOT\s+IN)\s+`,
+			`\b` + regexp.QuoteMeta(fieldName) + `(\s+IS\s+(NOT\s+)?NULL)`,
+			`\b` + regexp.QuoteMeta(fieldName) + `(\s+BETWEEN\s)`,
+			`(ORDER\s+BY\s+)` + regexp.QuoteMeta(fieldName) + `(\s|$)`,
+			`(GROUP\s+BY\s+)` + regexp.QuoteMeta(fieldName) + `(\s|$)`,
+			`(SELECT\s+)` + regexp.QuoteMeta(fieldName) + `(\s|,|$)`,
+			`(COUNT\s*\(\s*)` + regexp.QuoteMeta(fieldName) + `(\s*\))`,
+			`(SUM\s*\(\s*)` + regexp.QuoteMeta(fieldName) + `(\s*\))`,
+			`(AVG\s*\(\s*)` + regexp.QuoteMeta(fieldName) + `(\s*\))`,
+			`(MIN\s*\(\s*)` + regexp.QuoteMeta(fieldName) + `(\s*\))`,
+			`(MAX\s*\(\s*)` + regexp.QuoteMeta(fieldName) + `(\s*\))`,
+		}
+		for _, pattern := range patterns {
+			re := regexp.MustCompile(`(?i)` + pattern)
+			result = re.ReplaceAllStringFunc(result, func(match string) string {
+				return strings.ReplaceAll(match, fieldName, `"`+column+`"`)
+			})
+		}
+	}
+
+	return regexp.MustCompile(`(^|[^"\w.'])([A-Z]\w*)\.([A-Z]\w*)\b`).ReplaceAllString(result, `$1"$2"."$3"`)
+}
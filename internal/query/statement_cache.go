@@ -0,0 +1,266 @@
+package query
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+// DefaultStatementCacheSize is the number of prepared statements kept when no size is configured
+const DefaultStatementCacheSize = 256
+
+// StatementCacheStats reports prepared statement cache usage
+type StatementCacheStats struct {
+	Size      int   // Statements currently prepared
+	Capacity  int   // Maximum statements kept before the least recently used is closed
+	Hits      int64 // Executions that reused a prepared statement
+	Misses    int64 // Executions that had to prepare their statement
+	Evictions int64 // Statements closed to stay within Capacity
+}
+
+// HitRatio is Hits / (Hits + Misses), or 0 before the first execution
+func (s StatementCacheStats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// StatementCache is a GORM connection pool that prepares parameterized statements once and reuses them,
+// closing the least recently used statement when more than capacity are cached
+// Statements without arguments (DDL, multi-statement migration scripts) run unprepared
+type StatementCache struct {
+	db       *sql.DB
+	capacity int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List // Front is the most recently used *cachedStatement
+
+	hits      atomic.Int64
+	misses    atomic.Int64
+	evictions atomic.Int64
+}
+
+type cachedStatement struct {
+	query   string
+	stmt    *sql.Stmt
+	ready   chan struct{} // Closed once stmt / err are set
+	err     error
+	users   int  // Executions currently using stmt
+	evicted bool // Close stmt once the last user is done
+}
+
+// NewStatementCache wraps db; capacity <= 0 uses DefaultStatementCacheSize
+func NewStatementCache(db *sql.DB, capacity int) *StatementCache {
+	if capacity <= 0 {
+		capacity = DefaultStatementCacheSize
+	}
+	return &StatementCache{db: db, capacity: capacity, entries: make(map[string]*list.Element), order: list.New()}
+}
+
+// Install makes db run its statements through the cache
+func (c *StatementCache) Install(db *gorm.DB) {
+	db.ConnPool = c
+	db.Statement.ConnPool = c
+}
+
+// Stats returns the current cache counters
+func (c *StatementCache) Stats() StatementCacheStats {
+	c.mu.Lock()
+	size := c.order.Len()
+	c.mu.Unlock()
+
+	return StatementCacheStats{
+		Size:      size,
+		Capacity:  c.capacity,
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+	}
+}
+
+// Reset closes every cached statement, e.g. after a schema change invalidated their plans
+func (c *StatementCache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for c.order.Len() > 0 {
+		c.evict(c.order.Back())
+	}
+}
+
+// acquire returns the prepared statement for query, preparing it on a miss
+// Callers must release it when the execution has started
+func (c *StatementCache) acquire(ctx context.Context, query string) (*cachedStatement, error) {
+	c.mu.Lock()
+	if element, exists := c.entries[query]; exists {
+		entry := element.Value.(*cachedStatement)
+		entry.users++
+		c.order.MoveToFront(element)
+		c.mu.Unlock()
+
+		<-entry.ready
+		if entry.err != nil {
+			c.release(entry)
+			return nil, entry.err
+		}
+		c.hits.Add(1)
+		return entry, nil
+	}
+
+	entry := &cachedStatement{query: query, ready: make(chan struct{}), users: 1}
+	c.entries[query] = c.order.PushFront(entry)
+	for c.order.Len() > c.capacity {
+		c.evict(c.order.Back())
+		c.evictions.Add(1)
+	}
+	c.mu.Unlock()
+
+	c.misses.Add(1)
+	entry.stmt, entry.err = c.db.PrepareContext(ctx, query)
+	close(entry.ready)
+	if entry.err != nil {
+		// Do not cache failures: the next execution prepares again
+		c.mu.Lock()
+		if element, exists := c.entries[query]; exists && element.Value == entry {
+			c.order.Remove(element)
+			delete(c.entries, query)
+		}
+		c.mu.Unlock()
+		c.release(entry)
+		return nil, entry.err
+	}
+	return entry, nil
+}
+
+// release ends one use of a statement, closing it if it was evicted meanwhile
+// database/sql keeps a closed statement alive until rows read from it are closed
+func (c *StatementCache) release(entry *cachedStatement) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry.users--
+	if entry.evicted && entry.users == 0 && entry.stmt != nil {
+		entry.stmt.Close()
+		entry.stmt = nil
+	}
+}
+
+// evict removes an element from the cache; callers hold c.mu
+func (c *StatementCache) evict(element *list.Element) {
+	entry := element.Value.(*cachedStatement)
+	c.order.Remove(element)
+	delete(c.entries, entry.query)
+	entry.evicted = true
+
+	if entry.users == 0 && entry.stmt != nil {
+		entry.stmt.Close()
+		entry.stmt = nil
+	}
+}
+
+// GetDBConn exposes the underlying *sql.DB to gorm.DB.DB()
+func (c *StatementCache) GetDBConn() (*sql.DB, error) {
+	return c.db, nil
+}
+
+func (c *StatementCache) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return c.db.PrepareContext(ctx, query)
+}
+
+func (c *StatementCache) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if len(args) == 0 {
+		return c.db.ExecContext(ctx, query)
+	}
+	entry, err := c.acquire(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release(entry)
+	return entry.stmt.ExecContext(ctx, args...)
+}
+
+func (c *StatementCache) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if len(args) == 0 {
+		return c.db.QueryContext(ctx, query)
+	}
+	entry, err := c.acquire(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release(entry)
+	return entry.stmt.QueryContext(ctx, args...)
+}
+
+func (c *StatementCache) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if len(args) == 0 {
+		return c.db.QueryRowContext(ctx, query)
+	}
+	entry, err := c.acquire(ctx, query)
+	if err != nil {
+		// sql.Row cannot carry an error from outside; run unprepared so the caller sees the database's error
+		return c.db.QueryRowContext(ctx, query, args...)
+	}
+	defer c.release(entry)
+	return entry.stmt.QueryRowContext(ctx, args...)
+}
+
+// BeginTx starts a transaction whose statements reuse the cached ones
+func (c *StatementCache) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	tx, err := c.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &statementCacheTx{Tx: tx, cache: c}, nil
+}
+
+// statementCacheTx binds cached statements to a transaction; the bound copies close with it
+type statementCacheTx struct {
+	*sql.Tx
+	cache *StatementCache
+}
+
+func (t *statementCacheTx) GetDBConn() (*sql.DB, error) {
+	return t.cache.db, nil
+}
+
+func (t *statementCacheTx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if len(args) == 0 {
+		return t.Tx.ExecContext(ctx, query)
+	}
+	entry, err := t.cache.acquire(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer t.cache.release(entry)
+	return t.Tx.StmtContext(ctx, entry.stmt).ExecContext(ctx, args...)
+}
+
+func (t *statementCacheTx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if len(args) == 0 {
+		return t.Tx.QueryContext(ctx, query)
+	}
+	entry, err := t.cache.acquire(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer t.cache.release(entry)
+	return t.Tx.StmtContext(ctx, entry.stmt).QueryContext(ctx, args...)
+}
+
+func (t *statementCacheTx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if len(args) == 0 {
+		return t.Tx.QueryRowContext(ctx, query)
+	}
+	entry, err := t.cache.acquire(ctx, query)
+	if err != nil {
+		return t.Tx.QueryRowContext(ctx, query, args...)
+	}
+	defer t.cache.release(entry)
+	return t.Tx.StmtContext(ctx, entry.stmt).QueryRowContext(ctx, args...)
+}
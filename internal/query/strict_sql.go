@@ -0,0 +1,99 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/shepherrrd/gontext/internal/dberrors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StrictSQLPlugin rejects queries whose raw WHERE / HAVING conditions contain inline literals,
+// statement separators or comments (see AuditSQL), so only parameterized conditions reach the database
+// Conditions built by gontext itself (WhereField, WhereEntity, typed predicates) are always parameterized
+// Raw()/Exec() statements such as migrations are not checked
+type StrictSQLPlugin struct {
+	enabled atomic.Bool
+}
+
+// NewStrictSQLPlugin creates the plugin, initially enabled or not
+func NewStrictSQLPlugin(enabled bool) *StrictSQLPlugin {
+	plugin := &StrictSQLPlugin{}
+	plugin.enabled.Store(enabled)
+	return plugin
+}
+
+// Name returns the plugin name
+func (p *StrictSQLPlugin) Name() string {
+	return "gontext:strict_sql"
+}
+
+// SetEnabled switches strict mode at runtime
+func (p *StrictSQLPlugin) SetEnabled(enabled bool) {
+	p.enabled.Store(enabled)
+}
+
+// Enabled reports whether strict mode is on
+func (p *StrictSQLPlugin) Enabled() bool {
+	return p.enabled.Load()
+}
+
+// Initialize checks conditions before queries, row scans, updates and deletes
+func (p *StrictSQLPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Query().Before("gorm:query").Register("gontext:strict_sql_query", p.check); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("gontext:strict_sql_row", p.check); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("gontext:strict_sql_update", p.check); err != nil {
+		return err
+	}
+	return callbacks.Delete().Before("gorm:delete").Register("gontext:strict_sql_delete", p.check)
+}
+
+func (p *StrictSQLPlugin) check(db *gorm.DB) {
+	if !p.enabled.Load() || db.Error != nil {
+		return
+	}
+
+	var conditions []clause.Expression
+	if where, ok := db.Statement.Clauses["WHERE"].Expression.(clause.Where); ok {
+		conditions = append(conditions, where.Exprs...)
+	}
+	if groupBy, ok := db.Statement.Clauses["GROUP BY"].Expression.(clause.GroupBy); ok {
+		conditions = append(conditions, groupBy.Having...)
+	}
+
+	for _, sql := range rawConditions(conditions) {
+		if issues := AuditSQL(sql); len(issues) > 0 {
+			db.AddError(fmt.Errorf("%w: %q: %s", dberrors.ErrUnsafeSQL, sql, strings.Join(issues, ", ")))
+			return
+		}
+	}
+}
+
+// rawConditions collects the SQL text of raw string conditions, including nested groups
+func rawConditions(expressions []clause.Expression) []string {
+	var sqls []string
+	for _, expression := range expressions {
+		switch e := expression.(type) {
+		case clause.Expr:
+			sqls = append(sqls, e.SQL)
+		case clause.NamedExpr:
+			sqls = append(sqls, e.SQL)
+		case clause.AndConditions:
+			sqls = append(sqls, rawConditions(e.Exprs)...)
+		case clause.OrConditions:
+			sqls = append(sqls, rawConditions(e.Exprs)...)
+		case clause.NotConditions:
+			sqls = append(sqls, rawConditions(e.Exprs)...)
+		case clause.Where:
+			sqls = append(sqls, rawConditions(e.Exprs)...)
+		}
+	}
+	return sqls
+}
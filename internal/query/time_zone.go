@@ -0,0 +1,250 @@
+package query
+
+import (
+	"errors"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+)
+
+// TimeZonePlugin keeps time.Time values in one time zone across the application and the database:
+// values written (entity fields, update maps and query parameters) are converted to UTC, and values
+// loaded into entities are converted to a fixed location. Conversions keep the instant; they only
+// change the location, which matters for timestamp columns that store the wall clock alone
+type TimeZonePlugin struct {
+	utc      bool           // Convert written values to UTC
+	location *time.Location // Convert loaded values to this location, nil leaves them as scanned
+}
+
+// NewTimeZonePlugin creates the plugin; it registers nothing when utc is false and location is nil
+func NewTimeZonePlugin(utc bool, location *time.Location) *TimeZonePlugin {
+	return &TimeZonePlugin{utc: utc, location: location}
+}
+
+// Name returns the plugin name
+func (p *TimeZonePlugin) Name() string {
+	return "gontext:time_zone"
+}
+
+// Initialize converts values before statements are built and after rows are scanned
+func (p *TimeZonePlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	var errs []error
+	if p.utc {
+		errs = append(errs,
+			callbacks.Create().Before("gorm:create").Register("gontext:utc_create", p.normalizeWrite),
+			callbacks.Update().Before("gorm:update").Register("gontext:utc_update", p.normalizeWrite),
+			callbacks.Delete().Before("gorm:delete").Register("gontext:utc_delete", p.normalizeWrite),
+			callbacks.Query().Before("gorm:query").Register("gontext:utc_query", p.normalizeQuery),
+			callbacks.Row().Before("gorm:row").Register("gontext:utc_row", p.normalizeVars),
+			callbacks.Raw().Before("gorm:raw").Register("gontext:utc_raw", p.normalizeVars),
+		)
+	}
+	if p.location != nil {
+		errs = append(errs,
+			callbacks.Create().After("gorm:create").Register("gontext:location_create", p.localize),
+			callbacks.Update().After("gorm:update").Register("gontext:location_update", p.localize),
+			callbacks.Query().After("gorm:query").Register("gontext:location_query", p.localize),
+		)
+	}
+	return errors.Join(errs...)
+}
+
+// normalizeWrite converts the entities and values being written, and the WHERE conditions
+func (p *TimeZonePlugin) normalizeWrite(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	toUTC := func(t time.Time) time.Time { return t.UTC() }
+	// Dest is usually the model again; converting twice is harmless
+	convertTimes(db.Statement.ReflectValue, toUTC)
+	convertTimes(reflect.ValueOf(db.Statement.Dest), toUTC)
+
+	if where, ok := db.Statement.Clauses["WHERE"]; ok {
+		if conditions, ok := where.Expression.(clause.Where); ok {
+			conditions.Exprs = normalizeExpressions(conditions.Exprs)
+			where.Expression = conditions
+			db.Statement.Clauses["WHERE"] = where
+		}
+	}
+}
+
+// normalizeQuery builds the query ahead of gorm:query, which then runs it as built, so every parameter
+// - including those of joins and subqueries - is converted
+func (p *TimeZonePlugin) normalizeQuery(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	callbacks.BuildQuerySQL(db)
+	p.normalizeVars(db)
+}
+
+// normalizeVars converts the parameters of a statement whose SQL is already built
+func (p *TimeZonePlugin) normalizeVars(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	db.Statement.Vars = normalizeValues(db.Statement.Vars)
+}
+
+// localize converts the loaded entities to the configured location
+func (p *TimeZonePlugin) localize(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	convertTimes(db.Statement.ReflectValue, func(t time.Time) time.Time { return t.In(p.location) })
+}
+
+func normalizeExpressions(exprs []clause.Expression) []clause.Expression {
+	normalized := make([]clause.Expression, len(exprs))
+	for i, expr := range exprs {
+		normalized[i] = normalizeExpression(expr)
+	}
+	return normalized
+}
+
+// normalizeExpression converts the values of the condition expressions gorm builds from Where calls
+func normalizeExpression(expr clause.Expression) clause.Expression {
+	switch e := expr.(type) {
+	case clause.Expr:
+		e.Vars = normalizeValues(e.Vars)
+		return e
+	case clause.NamedExpr:
+		e.Vars = normalizeValues(e.Vars)
+		return e
+	case clause.Eq:
+		e.Value = normalizeValue(e.Value)
+		return e
+	case clause.Neq:
+		e.Value = normalizeValue(e.Value)
+		return e
+	case clause.Gt:
+		e.Value = normalizeValue(e.Value)
+		return e
+	case clause.Gte:
+		e.Value = normalizeValue(e.Value)
+		return e
+	case clause.Lt:
+		e.Value = normalizeValue(e.Value)
+		return e
+	case clause.Lte:
+		e.Value = normalizeValue(e.Value)
+		return e
+	case clause.IN:
+		e.Values = normalizeValues(e.Values)
+		return e
+	case clause.AndConditions:
+		e.Exprs = normalizeExpressions(e.Exprs)
+		return e
+	case clause.OrConditions:
+		e.Exprs = normalizeExpressions(e.Exprs)
+		return e
+	case clause.NotConditions:
+		e.Exprs = normalizeExpressions(e.Exprs)
+		return e
+	default:
+		return expr
+	}
+}
+
+func normalizeValues(values []interface{}) []interface{} {
+	for i, value := range values {
+		values[i] = normalizeValue(value)
+	}
+	return values
+}
+
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case time.Time:
+		return v.UTC()
+	case *time.Time:
+		if v != nil {
+			utc := v.UTC()
+			return &utc
+		}
+	case []time.Time:
+		utc := make([]time.Time, len(v))
+		for i, t := range v {
+			utc[i] = t.UTC()
+		}
+		return utc
+	case []interface{}:
+		return normalizeValues(append([]interface{}(nil), v...))
+	}
+	return value
+}
+
+// convertTimes applies convert to the non-zero time.Time and *time.Time fields of an entity, a slice
+// of entities or a column -> value map, including fields of embedded structs. Associations are loaded
+// and saved by statements of their own, so they are not followed
+func convertTimes(value reflect.Value, convert func(time.Time) time.Time) {
+	if !value.IsValid() {
+		return
+	}
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			convertTimes(value.Index(i), convert)
+		}
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return
+		}
+		iter := value.MapRange()
+		for iter.Next() {
+			if converted, ok := convertTime(iter.Value(), convert); ok {
+				value.SetMapIndex(iter.Key(), converted)
+			}
+		}
+	case reflect.Struct:
+		if !value.CanSet() || value.Type() == timeType {
+			return
+		}
+		structType := value.Type()
+		for i := 0; i < structType.NumField(); i++ {
+			field := structType.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			fieldValue := value.Field(i)
+			if converted, ok := convertTime(fieldValue, convert); ok {
+				fieldValue.Set(converted)
+			} else if field.Anonymous && fieldValue.Kind() == reflect.Struct {
+				convertTimes(fieldValue, convert)
+			}
+		}
+	}
+}
+
+// convertTime converts value when it holds a non-zero time.Time or *time.Time
+func convertTime(value reflect.Value, convert func(time.Time) time.Time) (reflect.Value, bool) {
+	if value.Kind() == reflect.Interface {
+		value = value.Elem()
+	}
+	if !value.IsValid() {
+		return value, false
+	}
+	switch {
+	case value.Type() == timeType:
+		if t := value.Interface().(time.Time); !t.IsZero() {
+			return reflect.ValueOf(convert(t)), true
+		}
+	case value.Type() == reflect.PointerTo(timeType) && !value.IsNil():
+		if t := value.Elem().Interface().(time.Time); !t.IsZero() {
+			converted := convert(t)
+			return reflect.ValueOf(&converted), true
+		}
+	}
+	return value, false
+}
@@ -0,0 +1,250 @@
+package scaffold
+
+import (
+	"fmt"
+	"go/format"
+	"strings"
+	"unicode"
+
+	"github.com/shepherrrd/gontext/internal/discovery"
+)
+
+// gontextImport is the import path generated files use for LinqDbSet
+const gontextImport = "github.com/shepherrrd/gontext"
+
+// RepositoryOptions configures repository generation for one DbContext
+type RepositoryOptions struct {
+	Context discovery.DbContextInfo
+	// Entities limits generation to these entity type or DbSet field names; empty generates every entity
+	Entities []string
+	// PackageName is the package of the generated files; empty uses the context's package
+	PackageName string
+	// ContextImport is the import path of the context's package, required when PackageName differs
+	ContextImport string
+}
+
+// GeneratedFile is a generated Go source file, named relative to the output directory
+type GeneratedFile struct {
+	Name    string
+	Content []byte
+}
+
+// GenerateRepositories emits a repository interface and implementation per entity, wired to the
+// context's LinqDbSet fields, plus a UnitOfWork exposing them and wrapping SaveChanges
+// Repositories only stage changes in the change tracker; UnitOfWork.SaveChanges writes them in one transaction
+func GenerateRepositories(opts RepositoryOptions) ([]GeneratedFile, error) {
+	entities, err := selectEntities(opts.Context, opts.Entities)
+	if err != nil {
+		return nil, err
+	}
+
+	gen := &repositoryGenerator{context: opts.Context.Name, pkg: opts.PackageName}
+	if gen.pkg == "" {
+		gen.pkg = opts.Context.PackageName
+	}
+	if gen.pkg != opts.Context.PackageName {
+		if opts.Context.PackageName == "main" {
+			return nil, fmt.Errorf("DbContext '%s' is in package main; generate into its package instead", opts.Context.Name)
+		}
+		if opts.ContextImport == "" {
+			return nil, fmt.Errorf("generating into package %s requires the import path of package %s", gen.pkg, opts.Context.PackageName)
+		}
+		gen.qualifier = opts.Context.PackageName + "."
+		gen.contextImport = opts.ContextImport
+	}
+
+	var files []GeneratedFile
+	for _, entity := range entities {
+		file, err := gen.file(toSnakeCase(entity.TypeName)+"_repository.go", gen.repository(entity))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	file, err := gen.file("unit_of_work.go", gen.unitOfWork(entities))
+	if err != nil {
+		return nil, err
+	}
+	return append(files, file), nil
+}
+
+// selectEntities resolves the requested names against the context's DbSet fields
+func selectEntities(ctx discovery.DbContextInfo, names []string) ([]discovery.EntityInfo, error) {
+	if len(ctx.Entities) == 0 {
+		return nil, fmt.Errorf("DbContext '%s' has no LinqDbSet fields", ctx.Name)
+	}
+	if len(names) == 0 {
+		return ctx.Entities, nil
+	}
+
+	var selected []discovery.EntityInfo
+	for _, name := range names {
+		found := false
+		for _, entity := range ctx.Entities {
+			if entity.TypeName == name || entity.Name == name {
+				selected = append(selected, entity)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("entity '%s' is not a LinqDbSet of DbContext '%s'", name, ctx.Name)
+		}
+	}
+	return selected, nil
+}
+
+type repositoryGenerator struct {
+	context       string // Context struct name
+	pkg           string // Package of the generated files
+	qualifier     string // "pkg." when the context lives in another package
+	contextImport string
+}
+
+// file adds the header and imports to body and gofmts the result
+func (g *repositoryGenerator) file(name, body string) (GeneratedFile, error) {
+	var src strings.Builder
+	src.WriteString("// Generated by gontext gen repository. Edit as needed; existing files are only replaced with --force.\n\n")
+	src.WriteString(fmt.Sprintf("package %s\n\n", g.pkg))
+	if strings.Contains(body, "gontext.") || g.contextImport != "" {
+		src.WriteString("import (\n")
+		if strings.Contains(body, "gontext.") {
+			src.WriteString(fmt.Sprintf("\t%q\n", gontextImport))
+		}
+		if g.contextImport != "" {
+			src.WriteString(fmt.Sprintf("\t%q\n", g.contextImport))
+		}
+		src.WriteString(")\n\n")
+	}
+	src.WriteString(body)
+
+	content, err := format.Source([]byte(src.String()))
+	if err != nil {
+		return GeneratedFile{}, fmt.Errorf("failed to format %s: %w", name, err)
+	}
+	return GeneratedFile{Name: name, Content: content}, nil
+}
+
+func (g *repositoryGenerator) repository(entity discovery.EntityInfo) string {
+	e := entity.TypeName
+	t := g.qualifier + e
+	ctx := g.qualifier + g.context
+	impl := lowerFirst(e) + "Repository"
+
+	return fmt.Sprintf(`// %[1]sSpec narrows a %[1]s query for List: filters, ordering, includes and paging
+type %[1]sSpec func(*gontext.LinqDbSet[%[2]s]) *gontext.LinqDbSet[%[2]s]
+
+// %[1]sRepository is the data access contract for %[1]s
+type %[1]sRepository interface {
+	// GetById returns the %[1]s with the given primary key, or nil when there is none
+	GetById(id interface{}) (*%[2]s, error)
+	// List returns the %[1]s rows matching spec; a nil spec returns all of them
+	List(spec %[1]sSpec) ([]%[2]s, error)
+	// Add stages entity for insertion; generated keys are set on SaveChanges
+	Add(entity *%[2]s)
+	// Update stages entity's changes
+	Update(entity *%[2]s)
+	// Delete stages entity for deletion
+	Delete(entity *%[2]s)
+}
+
+// %[3]s implements %[1]sRepository on %[4]s.%[5]s
+type %[3]s struct {
+	ctx *%[4]s
+}
+
+// New%[1]sRepository returns a %[1]sRepository staging its changes on ctx until SaveChanges
+func New%[1]sRepository(ctx *%[4]s) %[1]sRepository {
+	return &%[3]s{ctx: ctx}
+}
+
+func (r *%[3]s) GetById(id interface{}) (*%[2]s, error) {
+	return r.ctx.%[5]s.ById(id)
+}
+
+func (r *%[3]s) List(spec %[1]sSpec) ([]%[2]s, error) {
+	query := r.ctx.%[5]s
+	if spec != nil {
+		query = spec(query)
+	}
+	return query.ToList()
+}
+
+func (r *%[3]s) Add(entity *%[2]s) {
+	r.ctx.AddEntity(entity)
+}
+
+func (r *%[3]s) Update(entity *%[2]s) {
+	r.ctx.UpdateEntity(entity)
+}
+
+func (r *%[3]s) Delete(entity *%[2]s) {
+	r.ctx.RemoveEntity(entity)
+}
+`, e, t, impl, ctx, entity.Name)
+}
+
+func (g *repositoryGenerator) unitOfWork(entities []discovery.EntityInfo) string {
+	ctx := g.qualifier + g.context
+
+	var accessors, fields, inits, methods strings.Builder
+	for _, entity := range entities {
+		field := lowerFirst(entity.Name) + "Repo"
+		accessors.WriteString(fmt.Sprintf("\t%s() %sRepository\n", entity.Name, entity.TypeName))
+		fields.WriteString(fmt.Sprintf("\t%s %sRepository\n", field, entity.TypeName))
+		inits.WriteString(fmt.Sprintf("\t\t%s: New%sRepository(ctx),\n", field, entity.TypeName))
+		methods.WriteString(fmt.Sprintf("\nfunc (u *unitOfWork) %s() %sRepository {\n\treturn u.%s\n}\n", entity.Name, entity.TypeName, field))
+	}
+
+	return fmt.Sprintf(`// UnitOfWork groups the repositories of %[1]s; the changes they stage are written together by SaveChanges
+type UnitOfWork interface {
+%[2]s	// SaveChanges writes every staged change in one transaction
+	SaveChanges() error
+}
+
+type unitOfWork struct {
+	ctx *%[1]s
+%[3]s}
+
+// NewUnitOfWork returns a UnitOfWork over ctx; use one per request or business operation
+func NewUnitOfWork(ctx *%[1]s) UnitOfWork {
+	return &unitOfWork{
+		ctx: ctx,
+%[4]s	}
+}
+%[5]s
+func (u *unitOfWork) SaveChanges() error {
+	return u.ctx.SaveChanges()
+}
+`, ctx, accessors.String(), fields.String(), inits.String(), methods.String())
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	// Leading acronyms are lowered as a whole: ID -> id, HTTPLog -> httpLog
+	runes := []rune(s)
+	for i := 0; i < len(runes) && runes[i] >= 'A' && runes[i] <= 'Z'; i++ {
+		if i > 0 && i+1 < len(runes) && runes[i+1] >= 'a' && runes[i+1] <= 'z' {
+			break
+		}
+		runes[i] = runes[i] - 'A' + 'a'
+	}
+	return string(runes)
+}
+
+// toSnakeCase names files the Go way, keeping acronyms together: HTTPLog -> http_log
+func toSnakeCase(str string) string {
+	runes := []rune(str)
+	var result strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) &&
+			(unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			result.WriteRune('_')
+		}
+		result.WriteRune(unicode.ToLower(r))
+	}
+	return result.String()
+}
@@ -0,0 +1,196 @@
+package schedules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron is a parsed schedule: five fields (minute hour day-of-month month day-of-week) as in crontab,
+// a macro (@hourly, @daily, @weekly, @monthly, @yearly) or @every <duration>
+type Cron struct {
+	spec                   string
+	minute, hour, dom, dow uint64 // Bit n set when value n matches
+	month                  uint64
+	domAny, dowAny         bool          // The field was *, so matching is on the other day field alone
+	every                  time.Duration // Fixed interval of @every, 0 for calendar schedules
+}
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var dayNames = map[string]int{"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6}
+
+// ParseCron parses a schedule such as "*/15 * * * *", "0 9 * * MON-FRI", "@daily" or "@every 90s"
+func ParseCron(spec string) (*Cron, error) {
+	trimmed := strings.TrimSpace(spec)
+	if rest, ok := strings.CutPrefix(trimmed, "@every "); ok {
+		every, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || every < time.Second {
+			return nil, fmt.Errorf("invalid cron %q: @every needs a duration of at least 1s", spec)
+		}
+		return &Cron{spec: trimmed, every: every}, nil
+	}
+	if macro, ok := cronMacros[strings.ToLower(trimmed)]; ok {
+		trimmed = macro
+	}
+
+	fields := strings.Fields(trimmed)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron %q: expected 5 fields (minute hour day month weekday)", spec)
+	}
+
+	cron := &Cron{spec: strings.TrimSpace(spec)}
+	var err error
+	if cron.minute, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("invalid cron %q: minute: %w", spec, err)
+	}
+	if cron.hour, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("invalid cron %q: hour: %w", spec, err)
+	}
+	if cron.dom, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("invalid cron %q: day of month: %w", spec, err)
+	}
+	if cron.month, err = parseCronField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, fmt.Errorf("invalid cron %q: month: %w", spec, err)
+	}
+	if cron.dow, err = parseCronField(fields[4], 0, 7, dayNames); err != nil {
+		return nil, fmt.Errorf("invalid cron %q: day of week: %w", spec, err)
+	}
+	if cron.dow&(1<<7) != 0 { // 7 is Sunday too
+		cron.dow |= 1
+	}
+	cron.domAny = fields[2] == "*" || fields[2] == "?"
+	cron.dowAny = fields[4] == "*" || fields[4] == "?"
+	return cron, nil
+}
+
+// parseCronField parses a comma-separated list of values, ranges (a-b) and steps (*/n, a-b/n, a/n)
+func parseCronField(field string, min, max int, names map[string]int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepPart)
+			}
+		}
+
+		low, high := min, max
+		switch {
+		case rangePart == "*" || rangePart == "?":
+		case strings.Contains(rangePart, "-"):
+			lowPart, highPart, _ := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = cronValue(lowPart, names); err != nil {
+				return 0, err
+			}
+			if high, err = cronValue(highPart, names); err != nil {
+				return 0, err
+			}
+		default:
+			value, err := cronValue(rangePart, names)
+			if err != nil {
+				return 0, err
+			}
+			low = value
+			if !hasStep {
+				high = value // "5" is just 5, while "5/10" runs from 5 to the maximum
+			}
+		}
+		if low < min || high > max {
+			return 0, fmt.Errorf("%q is outside %d-%d", part, min, max)
+		}
+		if low > high {
+			return 0, fmt.Errorf("%q is an empty range", part)
+		}
+
+		for value := low; value <= high; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+	return bits, nil
+}
+
+func cronValue(text string, names map[string]int) (int, error) {
+	if value, ok := names[strings.ToLower(text)]; ok {
+		return value, nil
+	}
+	value, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", text)
+	}
+	return value, nil
+}
+
+// String returns the schedule as written
+func (c *Cron) String() string {
+	return c.spec
+}
+
+// Next returns the first time after after that matches the schedule, in after's location
+// Calendar schedules have minute resolution; the zero time is returned when nothing matches within
+// five years (e.g. "0 0 30 2 *")
+func (c *Cron) Next(after time.Time) time.Time {
+	if c.every > 0 {
+		return after.Add(c.every)
+	}
+
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			t = advance(t, time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location()))
+		case !c.dayMatches(t):
+			t = advance(t, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location()))
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = advance(t, time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location()))
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// advance returns next, or an hour after t when next fell in a DST gap: time.Date normalizes a skipped
+// wall clock time such as 02:00 to one before it, which would stop Next from moving on
+func advance(t, next time.Time) time.Time {
+	if !next.After(t) {
+		return t.Add(time.Hour)
+	}
+	return next
+}
+
+// dayMatches applies crontab's rule: when both day fields are restricted, either may match
+func (c *Cron) dayMatches(t time.Time) bool {
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case c.domAny && c.dowAny:
+		return true
+	case c.domAny:
+		return dowMatch
+	case c.dowAny:
+		return domMatch
+	default:
+		return domMatch || dowMatch
+	}
+}
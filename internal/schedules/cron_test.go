@@ -0,0 +1,122 @@
+package schedules
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	// 2024-01-31 is a Wednesday
+	after := time.Date(2024, 1, 31, 10, 7, 30, 0, time.UTC)
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 1, 31, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 1, 31, 10, 15, 0, 0, time.UTC)},
+		{"7 * * * *", time.Date(2024, 1, 31, 11, 7, 0, 0, time.UTC)},
+		{"5/20 9-17 * * *", time.Date(2024, 1, 31, 10, 25, 0, 0, time.UTC)},
+		{"0 9 * * MON-FRI", time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * sat,sun", time.Date(2024, 2, 3, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 2, 4, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 * *", time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)},
+		{"30 8 1 jun-aug ?", time.Date(2024, 6, 1, 8, 30, 0, 0, time.UTC)},
+		{"0 12 13 * FRI", time.Date(2024, 2, 2, 12, 0, 0, 0, time.UTC)}, // Either day field matches
+		{"@hourly", time.Date(2024, 1, 31, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2024, 2, 4, 0, 0, 0, 0, time.UTC)},
+		{"@Monthly", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", time.Date(2024, 1, 31, 10, 9, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		cron, err := ParseCron(tt.spec)
+		if err != nil {
+			t.Errorf("ParseCron(%q) error = %v", tt.spec, err)
+			continue
+		}
+		if got := cron.Next(after); !got.Equal(tt.want) {
+			t.Errorf("ParseCron(%q).Next(%s) = %s, want %s", tt.spec, after, got, tt.want)
+		}
+	}
+}
+
+func TestCronNextInLocation(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	cron, err := ParseCron("30 2 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	after := time.Date(2024, 3, 8, 12, 0, 0, 0, newYork)
+	if got, want := cron.Next(after), time.Date(2024, 3, 9, 2, 30, 0, 0, newYork); !got.Equal(want) || got.Location() != newYork {
+		t.Errorf("Next = %s, want %s", got, want)
+	}
+	// 02:30 does not exist on 2024-03-10, when the clocks spring forward
+	if got, want := cron.Next(time.Date(2024, 3, 9, 12, 0, 0, 0, newYork)), time.Date(2024, 3, 11, 2, 30, 0, 0, newYork); !got.Equal(want) {
+		t.Errorf("Next across the DST gap = %s, want %s", got, want)
+	}
+
+	// Cuba springs forward at midnight, so 2024-03-10 has no 00:00
+	havana, err := time.LoadLocation("America/Havana")
+	if err != nil {
+		t.Skip(err)
+	}
+	noon, err := ParseCron("0 12 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := noon.Next(time.Date(2024, 3, 9, 13, 0, 0, 0, havana)), time.Date(2024, 3, 10, 12, 0, 0, 0, havana); !got.Equal(want) {
+		t.Errorf("Next across a midnight DST gap = %s, want %s", got, want)
+	}
+}
+
+func TestParseCronRejectsInvalidSpecs(t *testing.T) {
+	tests := []struct {
+		spec string
+		err  string
+	}{
+		{"* * * *", "expected 5 fields"},
+		{"* * * * * *", "expected 5 fields"},
+		{"60 * * * *", `minute: "60" is outside 0-59`},
+		{"* 24 * * *", `hour: "24" is outside 0-23`},
+		{"* * 0 * *", `day of month: "0" is outside 1-31`},
+		{"* * * 13 *", `month: "13" is outside 1-12`},
+		{"* * * * 8", `day of week: "8" is outside 0-7`},
+		{"* * * foo *", `month: invalid value "foo"`},
+		{"* * * * mon-sun-tue", `day of week: invalid value "sun-tue"`},
+		{"30-10 * * * *", `"30-10" is an empty range`},
+		{"*/0 * * * *", `invalid step "0"`},
+		{"*/x * * * *", `invalid step "x"`},
+		{"1,,2 * * * *", `invalid value ""`},
+		{"@every 500ms", "@every needs a duration of at least 1s"},
+		{"@every soon", "@every needs a duration of at least 1s"},
+		{"@fortnightly", "expected 5 fields"},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseCron(tt.spec)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("ParseCron(%q) error = %v, want %q", tt.spec, err, tt.err)
+		}
+	}
+}
+
+func TestCronString(t *testing.T) {
+	for _, spec := range []string{"*/5 * * * *", "@daily", "@every 1m"} {
+		cron, err := ParseCron("  " + spec + " ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cron.String() != spec {
+			t.Errorf("String() = %q, want %q", cron.String(), spec)
+		}
+	}
+}
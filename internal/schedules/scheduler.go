@@ -0,0 +1,341 @@
+package schedules
+
+import (
+	stdcontext "context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/shepherrrd/gontext/internal/context"
+)
+
+// DefaultPollInterval is how often due schedules are checked when Options.PollInterval is 0
+const DefaultPollInterval = 15 * time.Second
+
+// Schedule is the stored definition and run state of a scheduled task, in the GontextSchedules table
+// Register it like any entity so migrations create the table: gontext.RegisterEntity[gontext.Schedule](ctx)
+type Schedule struct {
+	Name      string     `gorm:"primaryKey"`
+	Cron      string     `gorm:"not null"`
+	NextRunAt time.Time  `gorm:"not null"`
+	LastRunAt *time.Time // Start of the last run, successful or not
+	LastError *string    // Nil when the last run succeeded
+	RunCount  int64      `gorm:"not null"`
+}
+
+// TableName keeps the schedules apart from application tables
+func (Schedule) TableName() string {
+	return "GontextSchedules"
+}
+
+// ErrUnknownTask is returned by Trigger for names without a registered task
+var ErrUnknownTask = errors.New("no task is scheduled under this name")
+
+// Task is the body of a scheduled task. It runs inside a transaction that also records the run, so its
+// writes through tx commit exactly when the run is recorded; returning an error rolls them back
+type Task func(ctx stdcontext.Context, tx *gorm.DB) error
+
+// Options configures a scheduler
+type Options struct {
+	PollInterval time.Duration  // Default DefaultPollInterval
+	Location     *time.Location // Time zone cron fields are read in (default the context's TimeZone, else local)
+}
+
+type task struct {
+	name string
+	cron *Cron
+	run  Task
+}
+
+// Scheduler runs tasks on cron schedules from any number of application instances: each due run is
+// taken by the one instance that wins its advisory lock, and the next run time is stored in the database
+// so restarts and deploys neither skip nor repeat runs. Runs missed while no instance was up happen once
+type Scheduler struct {
+	ctx     *context.DbContext
+	db      *gorm.DB
+	options Options
+	mu      sync.RWMutex
+	tasks   map[string]*task
+	synced  bool
+}
+
+// New creates a scheduler for ctx's database
+func New(ctx *context.DbContext, options Options) *Scheduler {
+	if options.PollInterval <= 0 {
+		options.PollInterval = DefaultPollInterval
+	}
+	if options.Location == nil {
+		options.Location = ctx.TimeZone()
+	}
+	if options.Location == nil {
+		options.Location = time.Local
+	}
+	ctx.RegisterEntity(Schedule{})
+	return &Scheduler{ctx: ctx, db: ctx.GetDB(), options: options, tasks: make(map[string]*task)}
+}
+
+// Schedule registers run under name; spec is a cron expression (see ParseCron)
+// Changing a task's spec between deploys reschedules it from the next run
+func (s *Scheduler) Schedule(name, spec string, run Task) error {
+	cron, err := ParseCron(spec)
+	if err != nil {
+		return err
+	}
+	if cron.Next(time.Now().In(s.options.Location)).IsZero() {
+		return fmt.Errorf("cron %q of task %s never matches", spec, name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tasks[name] = &task{name: name, cron: cron, run: run}
+	s.synced = false
+	return nil
+}
+
+// EnsureTable creates the schedules table if needed, for applications that do not use migrations
+func EnsureTable(ctx *context.DbContext) error {
+	ctx.RegisterEntity(Schedule{})
+	return ctx.GetDB().AutoMigrate(&Schedule{})
+}
+
+// Run checks for due tasks every PollInterval until ctx is cancelled; it returns ctx's error on
+// cancellation or the first database error. Task errors are recorded in LastError and do not stop it
+func (s *Scheduler) Run(ctx stdcontext.Context) error {
+	for {
+		if _, err := s.RunDue(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(s.options.PollInterval):
+		}
+	}
+}
+
+// RunDue runs the tasks whose next run time has passed and returns how many this instance ran
+func (s *Scheduler) RunDue(ctx stdcontext.Context) (int, error) {
+	if err := s.sync(ctx); err != nil {
+		return 0, err
+	}
+
+	var due []Schedule
+	err := s.db.WithContext(ctx).
+		Where(clause.Lte{Column: clause.Column{Name: s.column("NextRunAt")}, Value: time.Now()}).
+		Find(&due).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to read due schedules: %w", err)
+	}
+
+	ran := 0
+	for _, schedule := range due {
+		s.mu.RLock()
+		task := s.tasks[schedule.Name]
+		s.mu.RUnlock()
+		if task == nil {
+			continue // Scheduled by another version of the application
+		}
+
+		didRun, err := s.runTask(ctx, task, schedule)
+		if err != nil {
+			return ran, err
+		}
+		if didRun {
+			ran++
+		}
+	}
+	return ran, nil
+}
+
+// Schedules returns the stored schedules, ordered by name
+func (s *Scheduler) Schedules(ctx stdcontext.Context) ([]Schedule, error) {
+	var schedules []Schedule
+	err := s.db.WithContext(ctx).Order(clause.OrderByColumn{Column: clause.Column{Name: s.column("Name")}}).Find(&schedules).Error
+	return schedules, err
+}
+
+// Trigger makes a task due now, so the next RunDue on any instance runs it
+func (s *Scheduler) Trigger(ctx stdcontext.Context, name string) error {
+	s.mu.RLock()
+	_, exists := s.tasks[name]
+	s.mu.RUnlock()
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrUnknownTask, name)
+	}
+	if err := s.sync(ctx); err != nil {
+		return err
+	}
+	return s.db.WithContext(ctx).Model(&Schedule{Name: name}).
+		Select("NextRunAt").
+		Updates(&Schedule{NextRunAt: time.Now()}).Error
+}
+
+// sync stores new task definitions and reschedules tasks whose cron expression changed
+func (s *Scheduler) sync(ctx stdcontext.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.synced {
+		return nil
+	}
+
+	names := make([]string, 0, len(s.tasks))
+	for name := range s.tasks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	now := time.Now().In(s.options.Location)
+	for _, name := range names {
+		task := s.tasks[name]
+		var stored Schedule
+		result := s.db.WithContext(ctx).Limit(1).Find(&stored, clause.Eq{Column: clause.Column{Name: s.column("Name")}, Value: name})
+		if result.Error != nil {
+			return fmt.Errorf("failed to read schedule %s: %w", name, result.Error)
+		}
+
+		switch {
+		case result.RowsAffected == 0:
+			// Another instance may insert it at the same time; either row is the same definition
+			err := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&Schedule{Name: name, Cron: task.cron.String(), NextRunAt: task.cron.Next(now)}).Error
+			if err != nil {
+				return fmt.Errorf("failed to store schedule %s: %w", name, err)
+			}
+		case stored.Cron != task.cron.String():
+			err := s.db.WithContext(ctx).Model(&stored).
+				Select("Cron", "NextRunAt").
+				Updates(&Schedule{Cron: task.cron.String(), NextRunAt: task.cron.Next(now)}).Error
+			if err != nil {
+				return fmt.Errorf("failed to reschedule %s: %w", name, err)
+			}
+		}
+	}
+	s.synced = true
+	return nil
+}
+
+// runTask runs one due task in a transaction holding its advisory lock, reporting whether this instance
+// ran it. Losing the lock, or finding the run already recorded, means another instance has it
+func (s *Scheduler) runTask(ctx stdcontext.Context, task *task, due Schedule) (bool, error) {
+	started := time.Now().In(s.options.Location)
+	next := Schedule{
+		NextRunAt: task.cron.Next(started),
+		LastRunAt: &started,
+		RunCount:  due.RunCount + 1,
+	}
+	// Only the run that still sees the NextRunAt it read may advance it
+	claim := func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&Schedule{Name: due.Name}).
+			Where(clause.Eq{Column: clause.Column{Name: s.column("NextRunAt")}, Value: due.NextRunAt}).
+			Select("NextRunAt", "LastRunAt", "LastError", "RunCount")
+	}
+
+	ran := false
+	var taskErr error
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		locked, err := s.tryLock(tx, due.Name)
+		if err != nil || !locked {
+			return err
+		}
+		defer s.unlock(tx, due.Name)
+
+		result := claim(tx).Updates(&next)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		ran = true
+
+		if taskErr = s.call(ctx, task, tx); taskErr != nil {
+			return taskErr // Roll back the task's writes; the failure is recorded below
+		}
+		return nil
+	})
+	if !ran {
+		if err != nil {
+			return false, fmt.Errorf("failed to run schedule %s: %w", due.Name, err)
+		}
+		return false, nil
+	}
+	if taskErr == nil {
+		if err != nil {
+			return true, fmt.Errorf("failed to record run of schedule %s: %w", due.Name, err)
+		}
+		return true, nil
+	}
+
+	// The rollback undid the claim too: advance the schedule outside the task's transaction
+	message := taskErr.Error()
+	next.LastError = &message
+	if err := claim(s.db.WithContext(stdcontext.WithoutCancel(ctx))).Updates(&next).Error; err != nil {
+		return true, fmt.Errorf("failed to record failed run of schedule %s: %w", due.Name, err)
+	}
+	return true, nil
+}
+
+// call runs the task, turning a panic into an error
+func (s *Scheduler) call(ctx stdcontext.Context, task *task, tx *gorm.DB) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("task panicked: %v", recovered)
+		}
+	}()
+	return task.run(ctx, tx)
+}
+
+// tryLock takes the task's advisory lock without waiting: a transaction-scoped pg_advisory_xact_lock on
+// PostgreSQL and a named GET_LOCK on MySQL. SQLite serializes writers, so the claim alone decides there
+func (s *Scheduler) tryLock(tx *gorm.DB, name string) (bool, error) {
+	var locked bool
+	switch s.ctx.GetDriver().Name() {
+	case "postgres":
+		err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", lockKey(name)).Scan(&locked).Error
+		return locked, err
+	case "mysql":
+		err := tx.Raw("SELECT GET_LOCK(?, 0) = 1", lockName(name)).Scan(&locked).Error
+		return locked, err
+	default:
+		return true, nil
+	}
+}
+
+// unlock releases a MySQL named lock; PostgreSQL releases transaction locks on commit or rollback
+func (s *Scheduler) unlock(tx *gorm.DB, name string) {
+	if s.ctx.GetDriver().Name() == "mysql" {
+		tx.Exec("DO RELEASE_LOCK(?)", lockName(name))
+	}
+}
+
+// column resolves a Schedule field to its column with the context's naming strategy
+func (s *Scheduler) column(field string) string {
+	stmt := &gorm.Statement{DB: s.db}
+	if err := stmt.Parse(&Schedule{}); err != nil {
+		return field
+	}
+	if schemaField := stmt.Schema.LookUpField(field); schemaField != nil {
+		return schemaField.DBName
+	}
+	return field
+}
+
+func lockName(name string) string {
+	return "gontext:schedule:" + name
+}
+
+// lockKey maps a lock name to the bigint key of PostgreSQL advisory locks
+func lockKey(name string) int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(lockName(name)))
+	return int64(hash.Sum64())
+}
@@ -0,0 +1,116 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// DefaultBackfillBatchSize is the number of rows read and indexed per page when BackfillOptions.BatchSize is 0
+const DefaultBackfillBatchSize = 500
+
+// BackfillOptions describes the table a backfill reads and the index it writes
+type BackfillOptions struct {
+	Table     string
+	KeyColumn string
+	// Columns are indexed as document fields; empty indexes every column
+	Columns   []string
+	Index     string
+	BatchSize int
+	// Progress is called after each indexed page with the running total
+	Progress func(indexed int)
+}
+
+// Backfill indexes every row of a table, paging with WHERE key > last ORDER BY key so it can run against a
+// live table; db's context cancels it between pages. It returns the number of documents indexed
+func Backfill(db *gorm.DB, engine Engine, options BackfillOptions) (int, error) {
+	if options.BatchSize <= 0 {
+		options.BatchSize = DefaultBackfillBatchSize
+	}
+
+	selectList := "*"
+	if len(options.Columns) > 0 {
+		quoted := []string{db.Statement.Quote(options.KeyColumn)}
+		for _, column := range options.Columns {
+			if column != options.KeyColumn {
+				quoted = append(quoted, db.Statement.Quote(column))
+			}
+		}
+		selectList = strings.Join(quoted, ", ")
+	}
+	indexed := func(column string) bool {
+		if len(options.Columns) == 0 {
+			return true
+		}
+		for _, name := range options.Columns {
+			if name == column {
+				return true
+			}
+		}
+		return false
+	}
+
+	var lastKey interface{}
+	count := 0
+	for {
+		if err := db.Statement.Context.Err(); err != nil {
+			return count, err
+		}
+
+		sql := fmt.Sprintf("SELECT %s FROM %s", selectList, db.Statement.Quote(options.Table))
+		var args []interface{}
+		if lastKey != nil {
+			sql += fmt.Sprintf(" WHERE %s > ?", db.Statement.Quote(options.KeyColumn))
+			args = append(args, lastKey)
+		}
+		sql += fmt.Sprintf(" ORDER BY %s LIMIT %d", db.Statement.Quote(options.KeyColumn), options.BatchSize)
+
+		var rows []map[string]interface{}
+		if err := db.Raw(sql, args...).Scan(&rows).Error; err != nil {
+			return count, fmt.Errorf("failed to read %s: %w", options.Table, err)
+		}
+		if len(rows) == 0 {
+			return count, nil
+		}
+
+		documents := make([]Document, 0, len(rows))
+		for _, row := range rows {
+			key, exists := row[options.KeyColumn]
+			if !exists {
+				return count, fmt.Errorf("table %s has no column %s", options.Table, options.KeyColumn)
+			}
+			lastKey = key
+
+			document := Document{ID: DocumentID(key), Fields: make(map[string]interface{}, len(row))}
+			for column, value := range row {
+				if indexed(column) {
+					if data, ok := value.([]byte); ok {
+						value = string(data)
+					}
+					document.Fields[column] = value
+				}
+			}
+			documents = append(documents, document)
+		}
+
+		if err := engine.Upsert(db.Statement.Context, options.Index, documents); err != nil {
+			return count, fmt.Errorf("failed to index %s: %w", options.Table, err)
+		}
+		count += len(documents)
+		if options.Progress != nil {
+			options.Progress(count)
+		}
+		if len(rows) < options.BatchSize {
+			return count, nil
+		}
+	}
+}
+
+// DocumentID formats a key value as a document ID
+func DocumentID(key interface{}) string {
+	if data, ok := key.([]byte); ok {
+		return string(data)
+	}
+	return fmt.Sprint(key)
+}
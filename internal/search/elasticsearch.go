@@ -0,0 +1,116 @@
+package search
+
+import (
+	"bytes"
+	stdcontext "context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Elasticsearch indexes documents through the Elasticsearch (or OpenSearch) bulk API
+type Elasticsearch struct {
+	URL string // e.g. http://localhost:9200
+	// APIKey is sent as "Authorization: ApiKey <key>"; otherwise Username/Password use basic auth when set
+	APIKey   string
+	Username string
+	Password string
+	Client   *http.Client // http.DefaultClient when nil
+}
+
+// Upsert indexes documents by ID, replacing existing ones
+func (e *Elasticsearch) Upsert(ctx stdcontext.Context, index string, documents []Document) error {
+	var body bytes.Buffer
+	for _, document := range documents {
+		if err := writeBulkLine(&body, "index", index, document.ID); err != nil {
+			return err
+		}
+		if err := writeJSONLine(&body, document.Fields); err != nil {
+			return fmt.Errorf("document %s: %w", document.ID, err)
+		}
+	}
+	return e.bulk(ctx, body.Bytes())
+}
+
+// Delete removes documents by ID
+func (e *Elasticsearch) Delete(ctx stdcontext.Context, index string, ids []string) error {
+	var body bytes.Buffer
+	for _, id := range ids {
+		if err := writeBulkLine(&body, "delete", index, id); err != nil {
+			return err
+		}
+	}
+	return e.bulk(ctx, body.Bytes())
+}
+
+func (e *Elasticsearch) bulk(ctx stdcontext.Context, body []byte) error {
+	if len(body) == 0 {
+		return nil
+	}
+
+	endpoint, err := url.JoinPath(e.URL, "_bulk")
+	if err != nil {
+		return fmt.Errorf("invalid Elasticsearch URL %q: %w", e.URL, err)
+	}
+	data, err := send(ctx, e.Client, http.MethodPost, endpoint, "application/x-ndjson", body, e.authorize)
+	if err != nil {
+		return err
+	}
+
+	// The bulk API answers 200 even when items fail; report the first failure
+	var response struct {
+		Errors bool                                `json:"errors"`
+		Items  []map[string]elasticsearchBulkItem `json:"items"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return fmt.Errorf("failed to read Elasticsearch bulk response: %w", err)
+	}
+	if !response.Errors {
+		return nil
+	}
+	for _, item := range response.Items {
+		for action, result := range item {
+			if result.Error == nil || (action == "delete" && result.Status == http.StatusNotFound) {
+				continue
+			}
+			return fmt.Errorf("elasticsearch %s of %s failed: %s: %s", action, result.ID, result.Error.Type, result.Error.Reason)
+		}
+	}
+	return nil
+}
+
+type elasticsearchBulkItem struct {
+	ID     string `json:"_id"`
+	Status int    `json:"status"`
+	Error  *struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error"`
+}
+
+func (e *Elasticsearch) authorize(request *http.Request) {
+	switch {
+	case e.APIKey != "":
+		request.Header.Set("Authorization", "ApiKey "+e.APIKey)
+	case e.Username != "":
+		request.SetBasicAuth(e.Username, e.Password)
+	}
+}
+
+func writeBulkLine(body *bytes.Buffer, action, index, id string) error {
+	return writeJSONLine(body, map[string]interface{}{
+		action: map[string]string{"_index": strings.ToLower(index), "_id": id},
+	})
+}
+
+func writeJSONLine(body *bytes.Buffer, value interface{}) error {
+	line, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	body.Write(line)
+	body.WriteByte('\n')
+	return nil
+}
@@ -0,0 +1,30 @@
+package search
+
+import (
+	stdcontext "context"
+	"errors"
+)
+
+// ErrSyncFailed is wrapped by errors from indexing changes that were already saved to the database
+var ErrSyncFailed = errors.New("search index sync failed")
+
+// Document is an entity as indexed: its key and its mapped column values by column name
+type Document struct {
+	ID     string
+	Fields map[string]interface{}
+}
+
+// Engine writes documents to a search engine index
+type Engine interface {
+	// Upsert creates or replaces documents by ID
+	Upsert(ctx stdcontext.Context, index string, documents []Document) error
+	// Delete removes documents by ID; IDs missing from the index are not an error
+	Delete(ctx stdcontext.Context, index string, ids []string) error
+}
+
+// Mapping declares the search index of an entity and the fields indexed in it
+type Mapping struct {
+	Index string
+	// Fields are the Go field names indexed; empty indexes every mapped field except encrypted ones
+	Fields []string
+}
@@ -0,0 +1,84 @@
+package search
+
+import (
+	stdcontext "context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Meilisearch indexes documents through the Meilisearch documents API
+// Meilisearch applies writes asynchronously, so a successful call means the write was queued
+type Meilisearch struct {
+	URL    string // e.g. http://localhost:7700
+	APIKey string // Sent as a bearer token when set
+	// PrimaryKey is the document attribute holding Document.ID (default "id")
+	PrimaryKey string
+	Client     *http.Client // http.DefaultClient when nil
+}
+
+// Upsert adds documents, replacing those with the same ID
+func (m *Meilisearch) Upsert(ctx stdcontext.Context, index string, documents []Document) error {
+	if len(documents) == 0 {
+		return nil
+	}
+
+	primaryKey := m.primaryKey()
+	body := make([]map[string]interface{}, len(documents))
+	for i, document := range documents {
+		fields := make(map[string]interface{}, len(document.Fields)+1)
+		for name, value := range document.Fields {
+			fields[name] = value
+		}
+		fields[primaryKey] = document.ID
+		body[i] = fields
+	}
+
+	endpoint, err := m.endpoint(index, "documents")
+	if err != nil {
+		return err
+	}
+	return m.post(ctx, endpoint+"?primaryKey="+url.QueryEscape(primaryKey), body)
+}
+
+// Delete removes documents by ID
+func (m *Meilisearch) Delete(ctx stdcontext.Context, index string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	endpoint, err := m.endpoint(index, "documents", "delete-batch")
+	if err != nil {
+		return err
+	}
+	return m.post(ctx, endpoint, ids)
+}
+
+func (m *Meilisearch) post(ctx stdcontext.Context, endpoint string, value interface{}) error {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = send(ctx, m.Client, http.MethodPost, endpoint, "application/json", body, func(request *http.Request) {
+		if m.APIKey != "" {
+			request.Header.Set("Authorization", "Bearer "+m.APIKey)
+		}
+	})
+	return err
+}
+
+func (m *Meilisearch) endpoint(index string, path ...string) (string, error) {
+	endpoint, err := url.JoinPath(m.URL, append([]string{"indexes", index}, path...)...)
+	if err != nil {
+		return "", fmt.Errorf("invalid Meilisearch URL %q: %w", m.URL, err)
+	}
+	return endpoint, nil
+}
+
+func (m *Meilisearch) primaryKey() string {
+	if m.PrimaryKey == "" {
+		return "id"
+	}
+	return m.PrimaryKey
+}
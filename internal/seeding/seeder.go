@@ -0,0 +1,160 @@
+package seeding
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shepherrrd/gontext/internal/context"
+	"github.com/shepherrrd/gontext/internal/models"
+)
+
+// Seeder inserts repeatable data (lookup tables, demo users, ...) - EF Core: HasData / custom seeding
+// Each seeder runs once per database; applied seeders are recorded in the SeedHistory table
+type Seeder interface {
+	// Name identifies the seeder in SeedHistory and in other seeders' Dependencies
+	Name() string
+	// Dependencies lists seeders that must run first
+	Dependencies() []string
+	// Run inserts the data, typically through ctx sets followed by ctx.SaveChanges()
+	Run(ctx *context.DbContext) error
+}
+
+// EnvironmentSeeder limits a seeder to some environments, e.g. []string{"dev", "staging"}
+// Seeders without it run in every environment
+type EnvironmentSeeder interface {
+	Environments() []string
+}
+
+// Runner applies registered seeders in dependency order
+type Runner struct {
+	context *context.DbContext
+	seeders map[string]Seeder
+}
+
+// NewRunner creates a runner for the given context
+func NewRunner(ctx *context.DbContext, seeders ...Seeder) *Runner {
+	runner := &Runner{context: ctx, seeders: make(map[string]Seeder)}
+	runner.Register(seeders...)
+	return runner
+}
+
+// Register adds seeders; a later seeder with the same name replaces the earlier one
+func (r *Runner) Register(seeders ...Seeder) {
+	for _, seeder := range seeders {
+		r.seeders[seeder.Name()] = seeder
+	}
+}
+
+// EnsureSeedTable creates the SeedHistory table if needed
+func (r *Runner) EnsureSeedTable() error {
+	return r.context.GetDB().AutoMigrate(&models.SeedHistory{})
+}
+
+// Applied returns the seed history, oldest first
+func (r *Runner) Applied() ([]models.SeedHistory, error) {
+	if err := r.EnsureSeedTable(); err != nil {
+		return nil, fmt.Errorf("failed to ensure seed table: %w", err)
+	}
+
+	var history []models.SeedHistory
+	if err := r.context.GetDB().Find(&history).Error; err != nil {
+		return nil, fmt.Errorf("failed to read seed history: %w", err)
+	}
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].AppliedAt.Before(history[j].AppliedAt)
+	})
+	return history, nil
+}
+
+// Run applies every seeder for env that has not run yet, dependencies first, and returns the names it ran
+// A seeder is recorded only after Run succeeds, so a failed seeder runs again next time;
+// seeders that save in several steps should tolerate rows left by a failed attempt
+func (r *Runner) Run(env string) ([]string, error) {
+	history, err := r.Applied()
+	if err != nil {
+		return nil, err
+	}
+	applied := make(map[string]bool, len(history))
+	for _, record := range history {
+		applied[record.Id] = true
+	}
+
+	ordered, err := r.order()
+	if err != nil {
+		return nil, err
+	}
+
+	var ran []string
+	for _, seeder := range ordered {
+		if applied[seeder.Name()] || !runsIn(seeder, env) {
+			continue
+		}
+
+		if err := seeder.Run(r.context); err != nil {
+			return ran, fmt.Errorf("seeder %s failed: %w", seeder.Name(), err)
+		}
+		record := &models.SeedHistory{Id: seeder.Name(), Environment: env, AppliedAt: time.Now()}
+		if err := r.context.GetDB().Create(record).Error; err != nil {
+			return ran, fmt.Errorf("failed to record seeder %s: %w", seeder.Name(), err)
+		}
+		ran = append(ran, seeder.Name())
+	}
+	return ran, nil
+}
+
+// order sorts seeders so dependencies run first; ties keep name order for repeatable runs
+func (r *Runner) order() ([]Seeder, error) {
+	names := make([]string, 0, len(r.seeders))
+	for name := range r.seeders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var ordered []Seeder
+	state := make(map[string]int) // 0 = pending, 1 = visiting, 2 = done
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		seeder, exists := r.seeders[name]
+		if !exists {
+			return fmt.Errorf("seeder %s depends on unregistered seeder %s", path[len(path)-1], name)
+		}
+		switch state[name] {
+		case 1:
+			return fmt.Errorf("seeder dependency cycle: %s -> %s", strings.Join(path, " -> "), name)
+		case 2:
+			return nil
+		}
+		state[name] = 1
+		for _, dependency := range seeder.Dependencies() {
+			if err := visit(dependency, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = 2
+		ordered = append(ordered, seeder)
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// runsIn reports whether a seeder applies to env; an empty env runs only unrestricted seeders
+func runsIn(seeder Seeder, env string) bool {
+	environmentSeeder, ok := seeder.(EnvironmentSeeder)
+	if !ok {
+		return true
+	}
+	for _, environment := range environmentSeeder.Environments() {
+		if strings.EqualFold(environment, env) {
+			return true
+		}
+	}
+	return false
+}
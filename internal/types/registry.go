@@ -0,0 +1,183 @@
+package types
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm/schema"
+)
+
+// SerializerName is the gorm serializer of fields whose type is stored through Encode and Decode:
+// Amount Money `gorm:"serializer:gontext"`
+const SerializerName = "gontext"
+
+// Mapping describes how values of a custom Go type are stored
+type Mapping struct {
+	// ColumnTypes is the column type per driver name ("postgres", "mysql", "sqlite")
+	ColumnTypes map[string]string
+	// ColumnType is used for drivers missing from ColumnTypes; empty leaves them to the default mapping
+	ColumnType string
+	// Encode converts a value to what is sent to the database, for types that do not implement
+	// driver.Valuer or need another representation; it receives the value, never a pointer to it
+	Encode func(value interface{}) (driver.Value, error)
+	// Decode builds a value from what the database returned, for types that do not implement sql.Scanner
+	Decode func(src interface{}) (interface{}, error)
+	// Equal reports whether two values are the same for change tracking
+	// (default: their encoded values are equal)
+	Equal func(a, b interface{}) bool
+}
+
+var registry sync.Map // reflect.Type -> *Mapping, plus Type.String() -> *Mapping for migrations
+
+func init() {
+	schema.RegisterSerializer(SerializerName, Serializer{})
+}
+
+// Register maps a custom type; a later registration of the same type replaces it
+// Panics on a pointer type or on Encode without Decode (or the reverse), since these are configuration bugs
+func Register(t reflect.Type, mapping Mapping) {
+	if t.Kind() == reflect.Ptr {
+		panic(fmt.Sprintf("Register the element type of %s, not the pointer", t))
+	}
+	if (mapping.Encode == nil) != (mapping.Decode == nil) {
+		panic(fmt.Sprintf("Mapping of %s needs both Encode and Decode, or neither", t))
+	}
+	registry.Store(t, &mapping)
+	registry.Store(t.String(), &mapping)
+}
+
+// Lookup returns the mapping of t, or of the type t points to
+func Lookup(t reflect.Type) (*Mapping, bool) {
+	if t == nil {
+		return nil, false
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	mapping, ok := registry.Load(t)
+	if !ok {
+		return nil, false
+	}
+	return mapping.(*Mapping), true
+}
+
+// LookupName returns the mapping of the type with the given name (reflect.Type.String), as recorded
+// in model snapshots
+func LookupName(goType string) (*Mapping, bool) {
+	mapping, ok := registry.Load(goType)
+	if !ok {
+		return nil, false
+	}
+	return mapping.(*Mapping), true
+}
+
+// ColumnTypeFor returns the column type for a driver, or "" to use the default mapping
+func (m *Mapping) ColumnTypeFor(driverName string) string {
+	if columnType, ok := m.ColumnTypes[driverName]; ok {
+		return columnType
+	}
+	return m.ColumnType
+}
+
+// Value converts value to what is sent to the database: Encode's result, else driver.Valuer's
+func (m *Mapping) Value(value interface{}) (driver.Value, error) {
+	if m.Encode != nil {
+		return m.Encode(value)
+	}
+	if valuer, ok := value.(driver.Valuer); ok {
+		return valuer.Value()
+	}
+	return value, nil
+}
+
+// Equals compares two values with Equal, else by their encoded values
+func (m *Mapping) Equals(a, b interface{}) bool {
+	if m.Equal != nil {
+		return m.Equal(a, b)
+	}
+	encodedA, errA := m.Value(a)
+	encodedB, errB := m.Value(b)
+	if errA != nil || errB != nil {
+		return reflect.DeepEqual(a, b)
+	}
+	return reflect.DeepEqual(encodedA, encodedB)
+}
+
+// Encode converts a query parameter of a registered type with its Encode function; other values,
+// including Valuer types the driver converts itself, are returned unchanged
+func Encode(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	mapping, ok := Lookup(reflect.TypeOf(value))
+	if !ok || mapping.Encode == nil {
+		return value, nil
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		value = rv.Elem().Interface()
+	}
+	return mapping.Encode(value)
+}
+
+// QueryValue is Encode for query parameters; an encoding error is returned when the query runs
+func QueryValue(value interface{}) interface{} {
+	encoded, err := Encode(value)
+	if err != nil {
+		return failedValue{err: err}
+	}
+	return encoded
+}
+
+// failedValue reports an encoding error through database/sql when the statement is executed
+type failedValue struct {
+	err error
+}
+
+func (v failedValue) Value() (driver.Value, error) {
+	return nil, v.err
+}
+
+// Serializer stores fields through the Encode and Decode functions of their type's mapping
+type Serializer struct{}
+
+// Scan decodes dbValue into the field of dst
+func (Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	target := field.ReflectValueOf(ctx, dst)
+	if dbValue == nil {
+		target.Set(reflect.Zero(field.FieldType))
+		return nil
+	}
+	mapping, ok := Lookup(field.FieldType)
+	if !ok || mapping.Decode == nil {
+		return fmt.Errorf("no decoder is registered for %s.%s (%s)", field.Schema.Name, field.Name, field.FieldType)
+	}
+
+	decoded, err := mapping.Decode(dbValue)
+	if err != nil {
+		return fmt.Errorf("failed to decode %s.%s: %w", field.Schema.Name, field.Name, err)
+	}
+	value := reflect.ValueOf(decoded)
+	if field.FieldType.Kind() == reflect.Ptr {
+		pointer := reflect.New(field.FieldType.Elem())
+		pointer.Elem().Set(value)
+		value = pointer
+	}
+	target.Set(value)
+	return nil
+}
+
+// Value encodes the field's value
+func (Serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	mapping, ok := Lookup(field.FieldType)
+	if !ok || mapping.Encode == nil {
+		return nil, fmt.Errorf("no encoder is registered for %s.%s (%s)", field.Schema.Name, field.Name, field.FieldType)
+	}
+	return Encode(fieldValue)
+}
@@ -0,0 +1,59 @@
+package gontext
+
+import (
+	stdcontext "context"
+
+	"github.com/shepherrrd/gontext/internal/jobs"
+)
+
+// Job is a background job stored in the GontextJobs table; register it so migrations create the table:
+//
+//	ctx.Jobs = gontext.RegisterEntity[gontext.Job](ctx)
+type Job = jobs.Job
+
+// JobStatus is Pending, Running, Succeeded or Failed
+type JobStatus = jobs.Status
+
+const (
+	JobPending   = jobs.Pending
+	JobRunning   = jobs.Running
+	JobSucceeded = jobs.Succeeded
+	JobFailed    = jobs.Failed
+)
+
+// EnqueueOptions sets a job's queue, earliest run time and maximum attempts
+type EnqueueOptions = jobs.EnqueueOptions
+
+// JobWorker claims and runs jobs of one queue; see NewJobWorker
+type JobWorker = jobs.Worker
+
+// JobWorkerOptions configures a worker's queue, concurrency, visibility timeout and retry backoff
+type JobWorkerOptions = jobs.WorkerOptions
+
+// JobHandler runs one job; an error fails the attempt and schedules a retry
+type JobHandler = jobs.Handler
+
+// Enqueue stages a job that the next SaveChanges inserts in the same transaction as the other changes:
+//
+//	ctx.Orders.Add(order)
+//	gontext.Enqueue(ctx, "send_receipt", ReceiptJob{OrderId: order.Id})
+//	err := ctx.SaveChanges() // Both or neither
+func Enqueue(ctx *DbContext, jobType string, payload interface{}, options ...EnqueueOptions) (*Job, error) {
+	return jobs.Enqueue(ctx, jobType, payload, options...)
+}
+
+// NewJobWorker creates a worker; register handlers, then Run it until the application stops
+func NewJobWorker(ctx *DbContext, options JobWorkerOptions) *JobWorker {
+	return jobs.NewWorker(ctx, options)
+}
+
+// HandleJob runs handler for the jobs of jobType with their payload decoded into a T
+// Usage: gontext.HandleJob(worker, "send_receipt", func(c context.Context, job ReceiptJob, _ *gontext.Job) error { ... })
+func HandleJob[T any](worker *JobWorker, jobType string, handler func(ctx stdcontext.Context, payload T, job *Job) error) {
+	jobs.HandleTyped(worker, jobType, handler)
+}
+
+// EnsureJobsTable creates the jobs table if needed, for applications that do not use migrations
+func EnsureJobsTable(ctx *DbContext) error {
+	return jobs.EnsureTable(ctx)
+}
@@ -1,6 +1,8 @@
 package gontext
 
 import (
+	"context"
+
 	"github.com/shepherrrd/gontext/internal/linq"
 )
 
@@ -14,4 +16,38 @@ func LINQ[T any](ctx *DbContext) *LinqQuery[T] {
 // Query creates a LINQ query for a DbSet (type-safe)
 func Query[T any](ctx *DbContext) *LinqQuery[T] {
 	return linq.NewLinqQuery[T](ctx.GetDB())
-}
\ No newline at end of file
+}
+// ParallelQuery is a query for Parallel, created with Defer
+type ParallelQuery = linq.ParallelQuery
+
+// Deferred holds a query for Parallel and, once Parallel returns, its typed Result and Err
+type Deferred[T any] = linq.Deferred[T]
+
+// Defer wraps a query for Parallel:
+//
+//	users := gontext.Defer(func(c context.Context) ([]User, error) { return ctx.Users.WithContext(c).ToList() })
+//	orders := gontext.Defer(func(c context.Context) (int64, error) { return ctx.Orders.WithContext(c).Count() })
+//	err := gontext.Parallel(r.Context(), users, orders) // users.Result, orders.Result
+func Defer[T any](query func(ctx context.Context) (T, error)) *Deferred[T] {
+	return linq.Defer(query)
+}
+
+// Parallel runs independent queries concurrently on separate pooled connections and waits for all of them
+// The first error cancels the remaining queries and is returned
+func Parallel(ctx context.Context, queries ...ParallelQuery) error {
+	return linq.Parallel(ctx, queries...)
+}
+
+// Future is a deferred query result from LinqDbSet.Future or FutureCount; reading any future of a
+// context sends all of its pending futures in one round trip
+type Future[V any] = linq.Future[V]
+
+// Mapped is a query projected onto a DTO by MapTo
+type Mapped[T any, D any] = linq.Mapped[T, D]
+
+// MapTo selects only the columns the DTO D declares and scans the rows into D:
+//
+//	dtos, err := gontext.MapTo[UserDto](ctx.Users.Where("IsActive", true)).ToList()
+func MapTo[D any, T any](ds *LinqDbSet[T]) *Mapped[T, D] {
+	return linq.MapTo[D](ds)
+}
@@ -1,23 +1,31 @@
 package gontext
 
 import (
+	"reflect"
+
 	"github.com/shepherrrd/gontext/internal/linq"
 )
 
 // LinqDbSet provides EF Core-style LINQ methods with type safety
 type LinqDbSet[T any] = linq.LinqDbSet[T]
 
+// HierarchyQuery runs recursive CTE queries over self-referencing entities
+type HierarchyQuery[T any] = linq.HierarchyQuery[T]
+
+// BatchLoader coalesces concurrent ById lookups into one WHERE pk IN (...) query; create one per request
+type BatchLoader[T any] = linq.BatchLoader[T]
+
 // PostgreSQLLinqDbSet provides PostgreSQL-specific LINQ methods with automatic query translation
 type PostgreSQLLinqDbSet[T any] = linq.PostgreSQLLinqDbSet[T]
 
 // NewLinqDbSet creates a new type-safe LINQ DbSet
 func NewLinqDbSet[T any](ctx *DbContext) *LinqDbSet[T] {
-	return linq.NewLinqDbSetWithContext[T](ctx.GetDB(), ctx)
+	return linq.NewLinqDbSetWithContext[T](ctx.DBFor(GetEntityType[T]()), ctx)
 }
 
 // NewPostgreSQLLinqDbSet creates a new PostgreSQL-aware LINQ DbSet
 func NewPostgreSQLLinqDbSet[T any](ctx *DbContext) *PostgreSQLLinqDbSet[T] {
-	return linq.NewPostgreSQLLinqDbSet[T](ctx.GetDB(), ctx)
+	return linq.NewPostgreSQLLinqDbSet[T](ctx.DBFor(GetEntityType[T]()), ctx)
 }
 
 // Expression represents a LINQ lambda expression
@@ -37,4 +45,45 @@ func WhereField[T any](fieldName string, value interface{}) func(*LinqDbSet[T])
 	return func(ds *LinqDbSet[T]) *LinqDbSet[T] {
 		return ds.WhereField(fieldName, value)
 	}
-}
\ No newline at end of file
+}
+// LockMode selects the row lock taken by LinqDbSet.WithLock
+type LockMode = linq.LockMode
+
+const (
+	LockForUpdate = linq.LockForUpdate
+	LockForShare  = linq.LockForShare
+	SkipLocked    = linq.SkipLocked
+	NoWait        = linq.NoWait
+)
+
+// Setter assigns a field in LinqDbSet.UpdateWhereIds
+type Setter = linq.Setter
+
+// Set assigns value to a field: ctx.Posts.UpdateWhereIds(ids, gontext.Set("IsPublished", true))
+func Set(fieldName string, value interface{}) Setter {
+	return linq.Set(fieldName, value)
+}
+
+// DefaultSimilarityThreshold is pg_trgm's default similarity threshold, from which WhereSimilar uses
+// the trigram index
+const DefaultSimilarityThreshold = linq.DefaultSimilarityThreshold
+
+// RawExpression is an SQL fragment with bound parameters; see RawExpr
+type RawExpression = linq.RawExpression
+
+// RawExpr creates an SQL fragment for Where, Or, OrderBy/ThenBy and SelectExpr whose ? placeholders are
+// bound to args: ctx.Posts.Where(gontext.RawExpr(`ts_rank("Search", plainto_tsquery(?)) > ?`, q, 0.1))
+func RawExpr(sql string, args ...interface{}) RawExpression {
+	return linq.RawExpr(sql, args...)
+}
+
+// QueryScope is a reusable query part applied with LinqDbSet.Scopes or registered with AddDefaultScope
+type QueryScope[T any] = linq.QueryScope[T]
+
+// AddDefaultScope applies scope to every query of T on ctx - EF Core: HasQueryFilter. Register it before
+// the set is created with RegisterEntity or NewLinqDbSet; IgnoreDefaultScopes opts a query out
+// Usage: gontext.AddDefaultScope(ctx, func(q *gontext.LinqDbSet[User]) *gontext.LinqDbSet[User] { return q.WhereFieldNull("DeletedAt") })
+func AddDefaultScope[T any](ctx *DbContext, scope QueryScope[T]) {
+	var zero T
+	ctx.AddDefaultScope(reflect.TypeOf(zero), scope)
+}
@@ -0,0 +1,38 @@
+package gontext
+
+import (
+	"github.com/shepherrrd/gontext/internal/linq"
+)
+
+// Listing answers list endpoints: filter, count, sort, page and map onto a DTO; see ListEndpoint
+type Listing[T any, D any] = linq.Listing[T, D]
+
+// ListRequest is the input of a list endpoint: Pagination, Sorting and Filters
+type ListRequest = linq.ListRequest
+
+// Pagination selects a page of a list endpoint; pages start at 1
+type Pagination = linq.Pagination
+
+// Sorting orders a list endpoint: "-CreatedAt,Name" sorts by CreatedAt descending, then Name
+type Sorting = linq.Sorting
+
+// Filter narrows a list endpoint: {Field: "Age", Operator: "gte", Value: "18"}
+type Filter = linq.Filter
+
+// ErrInvalidListRequest is wrapped by Listing errors caused by the request - a field missing from an
+// allowlist, an unknown operator, a value of the wrong type - match it with errors.Is to answer 400
+var ErrInvalidListRequest = linq.ErrInvalidListRequest
+
+// ListEndpoint creates the (items, total, err) boilerplate of admin list endpoints over ds: requests
+// may only sort and filter by the allowed fields, and rows are mapped onto D like MapTo
+//
+//	users := gontext.ListEndpoint[User, UserDto](ctx.Users.Where("TenantId", tenant)).
+//	    SortableBy("Name", "CreatedAt").
+//	    FilterableBy("Role", "Name", "CreatedAt").
+//	    DefaultSort("-CreatedAt")
+//
+//	var request gontext.ListRequest // ?page=2&pageSize=50&sort=name plus filters
+//	items, total, err := users.ListContext(r.Context(), request)
+func ListEndpoint[T any, D any](ds *LinqDbSet[T]) *Listing[T, D] {
+	return linq.ListEndpoint[T, D](ds)
+}
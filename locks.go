@@ -0,0 +1,27 @@
+package gontext
+
+import (
+	"github.com/shepherrrd/gontext/internal/locks"
+)
+
+// Lock is a held lock in the GontextLocks table; register it so migrations create the table:
+// gontext.RegisterEntity[gontext.Lock](ctx)
+type Lock = locks.Lock
+
+// LockManager hands out named, expiring locks shared by every instance; see DbContext.Locks
+type LockManager = locks.Manager
+
+// LockLease is a held lock; Extend it for long critical sections and Release it when done
+type LockLease = locks.Lease
+
+var (
+	// ErrLockHeld is returned by Acquire when another owner holds the lock
+	ErrLockHeld = locks.ErrLockHeld
+	// ErrLockLost is returned by Extend and Release when the lease expired and the lock was taken over
+	ErrLockLost = locks.ErrLockLost
+)
+
+// EnsureLocksTable creates the locks table if needed, for applications that do not use migrations
+func EnsureLocksTable(ctx *DbContext) error {
+	return ctx.Locks().EnsureTable()
+}
@@ -0,0 +1,44 @@
+package gontext
+
+import (
+	"github.com/shepherrrd/gontext/internal/context"
+	"github.com/shepherrrd/gontext/internal/models"
+)
+
+type EntityTypeBuilder = models.EntityTypeBuilder
+type PropertyBuilder = models.PropertyBuilder
+type IndexBuilder = models.IndexBuilder
+type RelationshipBuilder = models.RelationshipBuilder
+type KeyGenerator = models.KeyGenerator
+
+// SequenceBuilder configures a sequence declared with DbContext.HasSequence
+type SequenceBuilder = models.SequenceBuilder
+
+// TriggerBuilder configures a trigger declared with EntityTypeBuilder.HasTrigger
+type TriggerBuilder = models.TriggerBuilder
+
+// TriggerTiming is when a trigger fires: TriggerBefore or TriggerAfter the change
+type TriggerTiming = models.TriggerTiming
+
+// TriggerEvent is a change that fires a trigger
+type TriggerEvent = models.TriggerEvent
+
+const (
+	TriggerBefore   = models.TriggerBefore
+	TriggerAfter    = models.TriggerAfter
+	TriggerOnInsert = models.TriggerOnInsert
+	TriggerOnUpdate = models.TriggerOnUpdate
+	TriggerOnDelete = models.TriggerOnDelete
+)
+
+// Sequences hands out values of database sequences in blocks; see DbContext.Sequences
+type Sequences = context.Sequences
+
+// Entity returns a builder for configuring an entity's mapping - EF Core: modelBuilder.Entity<T>()
+// Usage: gontext.Entity[Post](ctx).Property("ID").UseHiLo("Post_hilo", 100)
+func Entity[T any](ctx *DbContext) *EntityTypeBuilder {
+	var zero T
+	ctx.RegisterEntity(zero)
+
+	return models.NewEntityTypeBuilder(ctx.GetEntityModel(GetEntityType[T]())).OnChange(ctx.ApplyEntityModel)
+}
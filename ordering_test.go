@@ -0,0 +1,118 @@
+package gontext_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/shepherrrd/gontext"
+)
+
+type orderedAuthor struct {
+	Id   int
+	Name string
+}
+
+type orderedPost struct {
+	Id       int
+	Title    string
+	AuthorId int
+	Author   *orderedAuthor `gorm:"foreignKey:AuthorId"`
+}
+
+type orderedComment struct {
+	Id     int
+	Text   string
+	PostId int
+	Post   *orderedPost `gorm:"foreignKey:PostId"`
+}
+
+// newOrderedContext returns a context on a SQLite file enforcing foreign keys, with the dependents
+// registered before the entities they reference
+func newOrderedContext(t *testing.T) *gontext.DbContext {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "gontext.db") + "?_foreign_keys=1"
+	ctx, err := gontext.NewDbContext(dsn, "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ctx.Close() })
+	gontext.RegisterEntity[orderedComment](ctx)
+	gontext.RegisterEntity[orderedPost](ctx)
+	gontext.RegisterEntity[orderedAuthor](ctx)
+	if err := ctx.EnsureCreated(); err != nil {
+		t.Fatal(err)
+	}
+	return ctx
+}
+
+func TestSaveChangesInsertsParentsFirst(t *testing.T) {
+	ctx := newOrderedContext(t)
+	comments := gontext.NewLinqDbSet[orderedComment](ctx)
+	posts := gontext.NewLinqDbSet[orderedPost](ctx)
+	authors := gontext.NewLinqDbSet[orderedAuthor](ctx)
+
+	// Tracked child-first; each insert would violate its foreign key if it ran in this order
+	for _, add := range []func() error{
+		func() error { _, err := comments.Add(orderedComment{Id: 1, Text: "first", PostId: 1}); return err },
+		func() error { _, err := comments.Add(orderedComment{Id: 2, Text: "second", PostId: 1}); return err },
+		func() error { _, err := posts.Add(orderedPost{Id: 1, Title: "hello", AuthorId: 1}); return err },
+		func() error { _, err := authors.Add(orderedAuthor{Id: 1, Name: "ada"}); return err },
+	} {
+		if err := add(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ctx.SaveChanges(); err != nil {
+		t.Fatalf("SaveChanges() = %v, want parents inserted before their dependents", err)
+	}
+
+	saved, err := comments.OrderBy("Id").ToList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 2 || saved[0].Text != "first" || saved[1].Text != "second" {
+		t.Errorf("saved comments %+v, want both in tracking order", saved)
+	}
+}
+
+func TestSaveChangesDeletesDependentsFirst(t *testing.T) {
+	ctx := newOrderedContext(t)
+	db := ctx.GetDB()
+	for _, row := range []interface{}{
+		&orderedAuthor{Id: 1, Name: "ada"},
+		&orderedPost{Id: 1, Title: "hello", AuthorId: 1},
+		&orderedComment{Id: 1, Text: "first", PostId: 1},
+	} {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Removed parent-first, while a new post of another author is added
+	gontext.NewLinqDbSet[orderedAuthor](ctx).Remove(orderedAuthor{Id: 1, Name: "ada"})
+	gontext.NewLinqDbSet[orderedPost](ctx).Remove(orderedPost{Id: 1, Title: "hello", AuthorId: 1})
+	gontext.NewLinqDbSet[orderedComment](ctx).Remove(orderedComment{Id: 1, Text: "first", PostId: 1})
+	if _, err := gontext.NewLinqDbSet[orderedPost](ctx).Add(orderedPost{Id: 2, Title: "new", AuthorId: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gontext.NewLinqDbSet[orderedAuthor](ctx).Add(orderedAuthor{Id: 2, Name: "grace"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctx.SaveChanges(); err != nil {
+		t.Fatalf("SaveChanges() = %v, want dependents deleted before the entities they reference", err)
+	}
+
+	for _, table := range []string{"ordered_authors", "ordered_posts", "ordered_comments"} {
+		var ids []int
+		if err := db.Table(table).Order("id").Pluck("id", &ids).Error; err != nil {
+			t.Fatal(err)
+		}
+		want := 1
+		if table == "ordered_comments" {
+			want = 0
+		}
+		if len(ids) != want || (want == 1 && ids[0] != 2) {
+			t.Errorf("%s holds ids %v after the save, want only the added row", table, ids)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package gontext
+
+import (
+	stdcontext "context"
+
+	"gorm.io/gorm"
+
+	"github.com/shepherrrd/gontext/internal/context"
+	"github.com/shepherrrd/gontext/internal/models"
+)
+
+// PartitionBuilder configures the partitioning declared with EntityTypeBuilder.PartitionByRange/PartitionByHash
+type PartitionBuilder = models.PartitionBuilder
+
+// PartitionInterval is the time span of each range partition
+type PartitionInterval = models.PartitionInterval
+
+const (
+	PartitionDaily   = models.PartitionDaily
+	PartitionMonthly = models.PartitionMonthly
+	PartitionYearly  = models.PartitionYearly
+)
+
+// Partitions creates and detaches range partitions; see DbContext.Partitions
+type Partitions = context.Partitions
+
+// PartitionMaintenance reports the partitions a maintenance run created and detached
+type PartitionMaintenance = context.PartitionMaintenance
+
+// SchedulePartitionMaintenance runs ctx.Partitions().Maintain on a cron schedule, once per run across
+// all application instances; a daily spec suits every interval
+// Usage: gontext.SchedulePartitionMaintenance(scheduler, ctx, "@daily")
+func SchedulePartitionMaintenance(scheduler *Scheduler, ctx *DbContext, spec string) error {
+	return scheduler.Schedule("gontext-partition-maintenance", spec, func(c stdcontext.Context, tx *gorm.DB) error {
+		_, err := ctx.Partitions().Maintain(c)
+		return err
+	})
+}
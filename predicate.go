@@ -0,0 +1,87 @@
+package gontext
+
+import (
+	"github.com/shepherrrd/gontext/internal/linq"
+)
+
+// TypedField names an entity field with its value type for compile-time checked predicates
+type TypedField[V any] = linq.TypedField[V]
+
+// Predicate is a composable condition accepted by Where and Or
+type Predicate = linq.Predicate
+
+// FieldOf creates a typed field reference; the name may be a navigation path like "Author.Username"
+func FieldOf[V any](name string) TypedField[V] { return linq.FieldOf[V](name) }
+
+// And matches when every predicate matches
+func And(predicates ...Predicate) Predicate { return linq.And(predicates...) }
+
+// Or matches when any predicate matches
+func Or(predicates ...Predicate) Predicate { return linq.Or(predicates...) }
+
+// Not negates a predicate
+func Not(predicate Predicate) Predicate { return linq.Not(predicate) }
+
+// Eq - field = value
+func Eq[V any](field TypedField[V], value V) Predicate { return linq.Eq(field, value) }
+
+// Ne - field <> value
+func Ne[V any](field TypedField[V], value V) Predicate { return linq.Ne(field, value) }
+
+// Gt - field > value
+func Gt[V any](field TypedField[V], value V) Predicate { return linq.Gt(field, value) }
+
+// Gte - field >= value
+func Gte[V any](field TypedField[V], value V) Predicate { return linq.Gte(field, value) }
+
+// Lt - field < value
+func Lt[V any](field TypedField[V], value V) Predicate { return linq.Lt(field, value) }
+
+// Lte - field <= value
+func Lte[V any](field TypedField[V], value V) Predicate { return linq.Lte(field, value) }
+
+// Like - field LIKE pattern
+func Like(field TypedField[string], pattern string) Predicate { return linq.Like(field, pattern) }
+
+// Contains - field LIKE %value%, with % and _ in value matched literally
+func Contains(field TypedField[string], value string) Predicate { return linq.Contains(field, value) }
+
+// StartsWith - field LIKE value%, with % and _ in value matched literally
+func StartsWith(field TypedField[string], prefix string) Predicate {
+	return linq.StartsWith(field, prefix)
+}
+
+// EndsWith - field LIKE %value, with % and _ in value matched literally
+func EndsWith(field TypedField[string], suffix string) Predicate { return linq.EndsWith(field, suffix) }
+
+// In - field IN (values...)
+func In[V any](field TypedField[V], values ...V) Predicate { return linq.In(field, values...) }
+
+// IsNull - field IS NULL
+func IsNull[V any](field TypedField[V]) Predicate { return linq.IsNull(field) }
+
+// IsNotNull - field IS NOT NULL
+func IsNotNull[V any](field TypedField[V]) Predicate { return linq.IsNotNull(field) }
+
+// Comparison is an explicit comparison value for WhereField, OrField and WhereEntity
+// The comparisons spell out their operators because Eq, Ne, Gt, Gte, Lt and Lte are the typed
+// predicates above: Where("Age", GreaterThan(40)) and Where(Gt(ageField, 40)) filter alike
+type Comparison = linq.Comparison
+
+// Equal - field = value, even when value is a string starting with an operator
+func Equal(value interface{}) Comparison { return linq.Equal(value) }
+
+// NotEqual - field <> value
+func NotEqual(value interface{}) Comparison { return linq.NotEqual(value) }
+
+// GreaterThan - field > value
+func GreaterThan(value interface{}) Comparison { return linq.GreaterThan(value) }
+
+// GreaterThanOrEqual - field >= value
+func GreaterThanOrEqual(value interface{}) Comparison { return linq.GreaterThanOrEqual(value) }
+
+// LessThan - field < value
+func LessThan(value interface{}) Comparison { return linq.LessThan(value) }
+
+// LessThanOrEqual - field <= value
+func LessThanOrEqual(value interface{}) Comparison { return linq.LessThanOrEqual(value) }
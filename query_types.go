@@ -0,0 +1,23 @@
+package gontext
+
+import (
+	"github.com/shepherrrd/gontext/internal/linq"
+)
+
+// QueryType is a read-only set over the rows of a parameterized SELECT; see RegisterQueryType
+type QueryType[T any] = linq.QueryType[T]
+
+// RegisterQueryType maps T to the rows of a SELECT with named @parameters instead of a table - EF Core's
+// keyless entity types mapped with ToSqlQuery. The result is a read-only set: bind parameters with
+// WithParam, then compose Where/OrderBy/Take over the rows and run ToList, Count, ...
+// Rows are never tracked and SaveChanges rejects them; T must not also be registered as an entity
+// Usage:
+//
+//	ctx.SalesReport = gontext.RegisterQueryType[SalesRow](ctx, `
+//	    SELECT "TenantId", date("CreatedAt") AS "Day", SUM("Total") AS "Total"
+//	    FROM "Orders" WHERE "TenantId" = @tenant GROUP BY "TenantId", date("CreatedAt")`)
+//	rows, err := ctx.SalesReport.WithParam("tenant", tenantID).OrderByDescending("Day").ToList()
+func RegisterQueryType[T any](ctx *DbContext, sql string) *QueryType[T] {
+	ctx.RegisterQueryType(GetEntityType[T](), sql)
+	return linq.NewQueryType[T](ctx.GetDB(), ctx, sql)
+}
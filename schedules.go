@@ -0,0 +1,46 @@
+package gontext
+
+import (
+	"github.com/shepherrrd/gontext/internal/schedules"
+)
+
+// Schedule is the stored state of a scheduled task in the GontextSchedules table; register it so
+// migrations create the table: ctx.Schedules = gontext.RegisterEntity[gontext.Schedule](ctx)
+type Schedule = schedules.Schedule
+
+// Scheduler runs tasks on cron schedules, once per due run across all application instances
+type Scheduler = schedules.Scheduler
+
+// SchedulerOptions configures the poll interval and the time zone cron fields are read in
+type SchedulerOptions = schedules.Options
+
+// ScheduledTask is a task body; its writes through tx commit together with the record of the run
+type ScheduledTask = schedules.Task
+
+// Cron is a parsed cron expression
+type Cron = schedules.Cron
+
+// ErrUnknownTask is returned by Scheduler.Trigger for names without a registered task
+var ErrUnknownTask = schedules.ErrUnknownTask
+
+// NewScheduler creates a scheduler; register tasks with Schedule, then Run it until the application stops
+// Usage:
+//
+//	scheduler := gontext.NewScheduler(ctx, gontext.SchedulerOptions{})
+//	scheduler.Schedule("expire-sessions", "*/10 * * * *", func(c context.Context, tx *gorm.DB) error {
+//	    return tx.Where("\"ExpiresAt\" < ?", time.Now()).Delete(&Session{}).Error
+//	})
+//	go scheduler.Run(appContext)
+func NewScheduler(ctx *DbContext, options SchedulerOptions) *Scheduler {
+	return schedules.New(ctx, options)
+}
+
+// ParseCron parses "*/15 * * * *", "0 9 * * MON-FRI", "@daily" or "@every 90s"
+func ParseCron(spec string) (*Cron, error) {
+	return schedules.ParseCron(spec)
+}
+
+// EnsureSchedulesTable creates the schedules table if needed, for applications that do not use migrations
+func EnsureSchedulesTable(ctx *DbContext) error {
+	return schedules.EnsureTable(ctx)
+}
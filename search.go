@@ -0,0 +1,29 @@
+package gontext
+
+import (
+	stdcontext "context"
+
+	"github.com/shepherrrd/gontext/internal/search"
+)
+
+// SearchEngine writes documents to a search index; see DbContext.SetSearchEngine
+type SearchEngine = search.Engine
+
+// SearchDocument is an entity as indexed: its key and its column values by column name
+type SearchDocument = search.Document
+
+// Elasticsearch indexes Searchable entities through the Elasticsearch (or OpenSearch) bulk API
+type Elasticsearch = search.Elasticsearch
+
+// Meilisearch indexes Searchable entities through the Meilisearch documents API
+type Meilisearch = search.Meilisearch
+
+// ErrSearchSyncFailed is wrapped by SaveChanges errors raised after the changes were committed, while
+// indexing them: the data is saved and only the search index is behind
+var ErrSearchSyncFailed = search.ErrSyncFailed
+
+// BackfillSearch indexes every existing row of the Searchable entity T
+// Usage: indexed, err := gontext.BackfillSearch[Product](context.Background(), ctx, 500)
+func BackfillSearch[T any](c stdcontext.Context, ctx *DbContext, batchSize int) (int, error) {
+	return ctx.BackfillSearch(c, GetEntityType[T](), batchSize)
+}
@@ -0,0 +1,24 @@
+package gontext
+
+import (
+	"github.com/shepherrrd/gontext/internal/seeding"
+)
+
+// Seeder inserts repeatable data once per database; see seeding.Seeder
+type Seeder = seeding.Seeder
+
+// EnvironmentSeeder limits a seeder to environments such as "dev" or "staging"
+type EnvironmentSeeder = seeding.EnvironmentSeeder
+
+var registeredSeeders []Seeder
+
+// RegisterSeeder registers seeders for Seed, typically from init() next to the seeder type
+func RegisterSeeder(seeders ...Seeder) {
+	registeredSeeders = append(registeredSeeders, seeders...)
+}
+
+// Seed runs every registered seeder for env that has not been applied yet and returns the names it ran
+// Usage: ran, err := gontext.Seed(ctx, "dev")
+func Seed(ctx *DbContext, env string) ([]string, error) {
+	return seeding.NewRunner(ctx, registeredSeeders...).Run(env)
+}
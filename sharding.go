@@ -0,0 +1,50 @@
+package gontext
+
+import (
+	"reflect"
+
+	"github.com/shepherrrd/gontext/internal/context"
+	"github.com/shepherrrd/gontext/internal/linq"
+)
+
+// ShardedSet queries an entity type spread over shard databases; see RegisterShardedEntity
+type ShardedSet[T any] = linq.ShardedSet[T]
+
+// Sharding configures the shards of an entity type: its shard key field, the shard databases and the
+// resolver mapping keys to shards
+type Sharding = context.Sharding
+
+// ShardResolver picks the shard of a shard key value, an index into Sharding.Databases
+type ShardResolver = context.ShardResolver
+
+// HashShards is the default ShardResolver, spreading keys evenly by hash
+func HashShards(entityType reflect.Type, key interface{}, shards int) int {
+	return context.HashShards(entityType, key, shards)
+}
+
+// RegisterShardedEntity registers T with its rows spread over shard databases added with AddDatabase,
+// for horizontally scaled multi-tenant data. SaveChanges writes each entity to the shard of its key;
+// queries pinning the key run on its shard, others fan out to every shard and combine the results:
+//
+//	for i, dsn := range shardDSNs {
+//	    gontext.AddDatabase(ctx.DbContext, fmt.Sprintf("shard%d", i), "postgres", gontext.DbContextOptions{ConnectionString: dsn})
+//	}
+//	ctx.Orders = gontext.RegisterShardedEntity[Order](ctx.DbContext, gontext.Sharding{
+//	    Key:       "TenantId",
+//	    Databases: []string{"shard0", "shard1", "shard2"},
+//	})
+//	orders, err := ctx.Orders.Where("TenantId", tenant).ToList() // shard of tenant only
+func RegisterShardedEntity[T any](ctx *DbContext, sharding Sharding) *ShardedSet[T] {
+	var zero T
+	ctx.ShardEntity(zero, sharding)
+
+	entityType := GetEntityType[T]()
+	connections := ctx.ShardConnections(entityType)
+	shards := make([]*LinqDbSet[T], len(connections))
+	for i, connection := range connections {
+		shards[i] = linq.NewLinqDbSetWithContext[T](connection, ctx)
+	}
+	return linq.NewShardedSet[T](ctx.ShardKey(entityType), shards, func(key interface{}) (int, error) {
+		return ctx.ShardIndex(entityType, key)
+	})
+}
@@ -0,0 +1,280 @@
+package gontext_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/shepherrrd/gontext"
+)
+
+type shardedOrder struct {
+	Id       int `gorm:"primaryKey;autoIncrement:false"`
+	TenantId int
+	Total    float64
+}
+
+type shardedNote struct {
+	Id     int `gorm:"primaryKey;autoIncrement:false"`
+	Tenant *string
+	Body   string
+}
+
+const shardCount = 3
+
+// newShardedContext returns a context with shardCount SQLite file shards
+func newShardedContext(t *testing.T) *gontext.DbContext {
+	t.Helper()
+	dir := t.TempDir()
+	ctx, err := gontext.NewDbContext(filepath.Join(dir, "main.db"), "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ctx.Close() })
+	for i := 0; i < shardCount; i++ {
+		name := fmt.Sprintf("shard%d", i)
+		options := gontext.DbContextOptions{ConnectionString: filepath.Join(dir, name+".db")}
+		if _, err := gontext.AddDatabase(ctx, name, "sqlite", options); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return ctx
+}
+
+func shardNames() []string {
+	names := make([]string, shardCount)
+	for i := range names {
+		names[i] = fmt.Sprintf("shard%d", i)
+	}
+	return names
+}
+
+// newShardedOrders returns orders 1-9 of tenants 1-9, with Total 10 times the id, spread over the shards
+func newShardedOrders(t *testing.T) (*gontext.DbContext, *gontext.ShardedSet[shardedOrder]) {
+	t.Helper()
+	ctx := newShardedContext(t)
+	orders := gontext.RegisterShardedEntity[shardedOrder](ctx, gontext.Sharding{Key: "TenantId", Databases: shardNames()})
+	if err := ctx.EnsureCreated(); err != nil {
+		t.Fatal(err)
+	}
+	for id := 1; id <= 9; id++ {
+		if _, err := orders.Add(shardedOrder{Id: id, TenantId: id, Total: float64(id * 10)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ctx.SaveChanges(); err != nil {
+		t.Fatal(err)
+	}
+	return ctx, orders
+}
+
+func orderIds(orders []shardedOrder) []int {
+	ids := make([]int, len(orders))
+	for i, order := range orders {
+		ids[i] = order.Id
+	}
+	return ids
+}
+
+func TestShardedSetWritesRowsToTheirShard(t *testing.T) {
+	ctx, orders := newShardedOrders(t)
+	entityType := reflect.TypeOf(shardedOrder{})
+
+	spread := make(map[int]bool)
+	for tenant := 1; tenant <= 9; tenant++ {
+		index, err := ctx.ShardIndex(entityType, tenant)
+		if err != nil {
+			t.Fatal(err)
+		}
+		spread[index] = true
+		shard, err := orders.Shard(tenant)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count, err := shard.Where("TenantId", tenant).Count(); err != nil || count != 1 {
+			t.Errorf("shard %d holds %d orders of tenant %d, %v; want 1", index, count, tenant, err)
+		}
+	}
+	if len(spread) < 2 {
+		t.Errorf("9 tenants landed on %d shard(s)", len(spread))
+	}
+}
+
+func TestShardedSetPinsOnlyPlainEquality(t *testing.T) {
+	_, orders := newShardedOrders(t)
+	tests := []struct {
+		name  string
+		query *gontext.ShardedSet[shardedOrder]
+		want  int64
+	}{
+		{"all", orders, 9},
+		{"equality", orders.Where("TenantId", 5), 1},
+		{"pointer equality", orders.Where("TenantId", func() *int { v := 5; return &v }()), 1},
+		{"Equal", orders.Where("TenantId", gontext.Equal(5)), 1},
+		{"text equality", orders.Where("TenantId", "5"), 1},
+		{"GreaterThan", orders.Where("TenantId", gontext.GreaterThan(3)), 6},
+		{"LessThanOrEqual", orders.Where("TenantId", gontext.LessThanOrEqual(3)), 3},
+		{"NotEqual", orders.Where("TenantId", gontext.NotEqual(5)), 8},
+		{"operator text", orders.Where("TenantId", ">3"), 6},
+		{"other field", orders.Where("Total", 50.0), 1},
+		{"condition", orders.Where("tenant_id > ?", 6), 3},
+		{"two equalities", orders.Where("TenantId", 2).Where("Total", 20.0), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, err := tt.query.Count()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != tt.want {
+				t.Errorf("Count = %d, want %d", count, tt.want)
+			}
+			rows, err := tt.query.ToList()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if int64(len(rows)) != tt.want {
+				t.Errorf("ToList returned %d rows, want %d", len(rows), tt.want)
+			}
+		})
+	}
+}
+
+func TestShardedSetMergesOrderedPages(t *testing.T) {
+	_, orders := newShardedOrders(t)
+	tests := []struct {
+		name  string
+		query *gontext.ShardedSet[shardedOrder]
+		want  []int
+	}{
+		{"ascending", orders.OrderBy("Total"), []int{1, 2, 3, 4, 5, 6, 7, 8, 9}},
+		{"descending", orders.OrderByDescending("Total"), []int{9, 8, 7, 6, 5, 4, 3, 2, 1}},
+		{"take", orders.OrderBy("Total").Take(4), []int{1, 2, 3, 4}},
+		{"skip", orders.OrderByDescending("Total").Skip(6), []int{3, 2, 1}},
+		{"page", orders.OrderBy("Total").Skip(2).Take(3), []int{3, 4, 5}},
+		{"filtered page", orders.Where("TenantId", gontext.GreaterThan(3)).OrderByDescending("Total").Skip(1).Take(2), []int{8, 7}},
+		{"skip past end", orders.OrderBy("Total").Skip(20), []int{}},
+		{"pinned page", orders.Where("TenantId", 4).Skip(0).Take(5), []int{4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := tt.query.ToList()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := orderIds(rows); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got ids %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	first, err := orders.OrderByDescending("Total").FirstOrDefault()
+	if err != nil || first == nil || first.Id != 9 {
+		t.Errorf("FirstOrDefault = %+v, %v; want order 9", first, err)
+	}
+	if any, err := orders.Where("TenantId", gontext.GreaterThan(8)).Any(); err != nil || !any {
+		t.Errorf("Any of tenant > 8 = %v, %v; want true", any, err)
+	}
+	if any, err := orders.Where("TenantId", gontext.GreaterThan(9)).Any(); err != nil || any {
+		t.Errorf("Any of tenant > 9 = %v, %v; want false", any, err)
+	}
+	if _, err := orders.OrderBy("Missing").ToList(); err == nil {
+		t.Error("ordering by an unknown field did not fail")
+	}
+}
+
+func TestShardedSetTracksMergedRows(t *testing.T) {
+	ctx, orders := newShardedOrders(t)
+	rows, err := orders.OrderBy("Total").ToList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range rows {
+		rows[i].Total++
+	}
+	if err := ctx.SaveChanges(); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := orders.OrderBy("Total").ToList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, order := range saved {
+		if order.Total != float64(order.Id*10+1) {
+			t.Errorf("order %d saved with total %v, want %v", order.Id, order.Total, order.Id*10+1)
+		}
+	}
+}
+
+func TestHashShardsDereferencesPointerKeys(t *testing.T) {
+	entityType := reflect.TypeOf(shardedNote{})
+	for _, key := range []interface{}{7, "tenant-a", int64(-3)} {
+		pointer := reflect.New(reflect.TypeOf(key))
+		pointer.Elem().Set(reflect.ValueOf(key))
+		want := gontext.HashShards(entityType, key, 16)
+		for i := 0; i < 4; i++ { // Fresh pointers, so an address-based hash would differ
+			copied := reflect.New(reflect.TypeOf(key))
+			copied.Elem().Set(reflect.ValueOf(key))
+			if got := gontext.HashShards(entityType, copied.Interface(), 16); got != want {
+				t.Errorf("HashShards(&%v) = %d, want %d like the value", key, got, want)
+			}
+		}
+	}
+	var nilKey *string
+	if got, want := gontext.HashShards(entityType, nilKey, 16), gontext.HashShards(entityType, nil, 16); got != want {
+		t.Errorf("HashShards of a nil pointer = %d, want %d like nil", got, want)
+	}
+}
+
+func TestShardedSetRoutesPointerKeys(t *testing.T) {
+	ctx := newShardedContext(t)
+	notes := gontext.RegisterShardedEntity[shardedNote](ctx, gontext.Sharding{Key: "Tenant", Databases: shardNames()})
+	if err := ctx.EnsureCreated(); err != nil {
+		t.Fatal(err)
+	}
+	tenants := []string{"acme", "globex", "initech", "umbrella"}
+	for i := 0; i < 12; i++ {
+		tenant := tenants[i%len(tenants)] // A new pointer per row
+		if _, err := notes.Add(shardedNote{Id: i + 1, Tenant: &tenant, Body: "note"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ctx.SaveChanges(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tenant := range tenants {
+		shard, err := notes.Shard(tenant)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count, err := shard.Where("Tenant", tenant).Count(); err != nil || count != 3 {
+			t.Errorf("shard of %s holds %d of its notes, %v; want 3", tenant, count, err)
+		}
+		if count, err := notes.Where("Tenant", tenant).Count(); err != nil || count != 3 {
+			t.Errorf("pinned query of %s counted %d notes, %v; want 3", tenant, count, err)
+		}
+	}
+	all, err := notes.ToList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := orderIdsOf(all)
+	sort.Ints(ids)
+	if len(ids) != 12 || ids[0] != 1 || ids[11] != 12 {
+		t.Errorf("fan-out read ids %v, want 1 to 12", ids)
+	}
+}
+
+func orderIdsOf(notes []shardedNote) []int {
+	ids := make([]int, len(notes))
+	for i, note := range notes {
+		ids[i] = note.Id
+	}
+	return ids
+}
@@ -0,0 +1,49 @@
+package gontext_test
+
+import (
+	"testing"
+
+	"github.com/shepherrrd/gontext"
+)
+
+type trackedTicket struct {
+	Id    int
+	Title string
+	Seats int
+}
+
+func TestSaveChangesInsertsIdenticalAddedEntities(t *testing.T) {
+	ctx, err := gontext.NewDbContext(":memory:", "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ctx.Close() })
+	tickets := gontext.RegisterEntity[trackedTicket](ctx)
+	if err := ctx.EnsureCreated(); err != nil {
+		t.Fatal(err)
+	}
+
+	added := make([]*trackedTicket, 3)
+	for i := range added {
+		if added[i], err = tickets.Add(trackedTicket{Title: "standing", Seats: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ctx.SaveChanges(); err != nil {
+		t.Fatal(err)
+	}
+
+	if count, err := tickets.Count(); err != nil || count != 3 {
+		t.Errorf("saved %d tickets, %v; want one row per Add", count, err)
+	}
+	ids := make(map[int]bool)
+	for i, ticket := range added {
+		if ticket.Id == 0 {
+			t.Errorf("ticket %d was not given its generated Id", i)
+		}
+		ids[ticket.Id] = true
+	}
+	if len(ids) != len(added) {
+		t.Errorf("added tickets got Ids %v, want a distinct Id each", ids)
+	}
+}
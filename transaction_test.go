@@ -0,0 +1,278 @@
+package gontext_test
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/shepherrrd/gontext"
+)
+
+type transferAccount struct {
+	Id      int
+	Owner   string
+	Balance float64
+}
+
+// newFileContext returns a context on a WAL SQLite file, where each pooled connection sees the same
+// database and readers outside a transaction see only committed rows
+func newFileContext(t *testing.T) *gontext.DbContext {
+	t.Helper()
+	return openFileContext(t, filepath.Join(t.TempDir(), "gontext.db")+"?_journal_mode=WAL&_busy_timeout=5000")
+}
+
+func openFileContext(t *testing.T, dsn string) *gontext.DbContext {
+	t.Helper()
+	ctx, err := gontext.NewDbContext(dsn, "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ctx.Close() })
+	gontext.RegisterEntity[transferAccount](ctx)
+	if err := ctx.EnsureCreated(); err != nil {
+		t.Fatal(err)
+	}
+	return ctx
+}
+
+func countAccounts(t *testing.T, ctx *gontext.DbContext) int64 {
+	t.Helper()
+	count, err := gontext.NewLinqDbSet[transferAccount](ctx).Count()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestWithTransactionIsolatesReceiver(t *testing.T) {
+	ctx := newFileContext(t)
+
+	err := gontext.WithTransaction(ctx, func(scope *gontext.Scope) error {
+		accounts := gontext.NewLinqDbSet[transferAccount](scope.Context())
+		if _, err := accounts.Add(transferAccount{Owner: "ada", Balance: 10}); err != nil {
+			return err
+		}
+		if err := scope.SaveChanges(); err != nil {
+			return err
+		}
+		if count := countAccounts(t, scope.Context()); count != 1 {
+			t.Errorf("scope sees %d accounts, want its own insert", count)
+		}
+
+		// Readers of the receiver run concurrently with the open transaction
+		var wg sync.WaitGroup
+		counts := make([]int64, 4)
+		for i := range counts {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				counts[i], _ = gontext.NewLinqDbSet[transferAccount](ctx).Count()
+			}(i)
+		}
+		wg.Wait()
+		for _, count := range counts {
+			if count != 0 {
+				t.Errorf("concurrent reader of the context saw %d uncommitted accounts", count)
+			}
+		}
+		if ctx.CurrentTransaction() != nil {
+			t.Error("the receiver was enlisted in the scope's transaction")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count := countAccounts(t, ctx); count != 1 {
+		t.Errorf("%d accounts after commit, want 1", count)
+	}
+}
+
+func TestWithTransactionRollsBack(t *testing.T) {
+	ctx := newFileContext(t)
+	failed := errors.New("transfer refused")
+
+	err := gontext.WithTransaction(ctx, func(scope *gontext.Scope) error {
+		if _, err := gontext.NewLinqDbSet[transferAccount](scope.Context()).Add(transferAccount{Owner: "ada"}); err != nil {
+			return err
+		}
+		if err := scope.SaveChanges(); err != nil {
+			return err
+		}
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("WithTransaction returned %v, want the function's error", err)
+	}
+	if count := countAccounts(t, ctx); count != 0 {
+		t.Errorf("%d accounts after rollback, want 0", count)
+	}
+}
+
+func TestWithTransactionNestsInSavepoint(t *testing.T) {
+	ctx := newFileContext(t)
+
+	err := gontext.WithTransaction(ctx, func(outer *gontext.Scope) error {
+		if err := outer.Tx().Create(&transferAccount{Owner: "kept"}).Error; err != nil {
+			return err
+		}
+		inner := gontext.WithTransaction(outer.Context(), func(inner *gontext.Scope) error {
+			if err := inner.Tx().Create(&transferAccount{Owner: "undone"}).Error; err != nil {
+				return err
+			}
+			if count := countAccounts(t, inner.Context()); count != 2 {
+				t.Errorf("inner scope sees %d accounts, want both inserts", count)
+			}
+			return errors.New("undo the inner scope")
+		})
+		if inner == nil {
+			t.Error("inner scope error was not returned")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	owners, err := gontext.NewLinqDbSet[transferAccount](ctx).ToList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(owners) != 1 || owners[0].Owner != "kept" {
+		t.Errorf("saved accounts %+v, want only the outer scope's", owners)
+	}
+}
+
+// newSharedContexts returns two contexts on the same database, as two modules would hold, and a third
+// one to read what was committed
+func newSharedContexts(t *testing.T) (orders, billing, reader *gontext.DbContext) {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "gontext.db") + "?_journal_mode=WAL&_busy_timeout=5000"
+	return openFileContext(t, dsn), openFileContext(t, dsn), openFileContext(t, dsn)
+}
+
+func TestUseTransactionCommitsContextsTogether(t *testing.T) {
+	for _, commit := range []bool{true, false} {
+		orders, billing, reader := newSharedContexts(t)
+		tx := orders.BeginTransaction()
+		for _, ctx := range []*gontext.DbContext{orders, billing} {
+			if err := ctx.UseTransaction(tx); err != nil {
+				t.Fatal(err)
+			}
+			if ctx.CurrentTransaction() == nil {
+				t.Error("CurrentTransaction() = nil for an enlisted context")
+			}
+		}
+
+		for owner, ctx := range map[string]*gontext.DbContext{"orders": orders, "billing": billing} {
+			if _, err := gontext.NewLinqDbSet[transferAccount](ctx).Add(transferAccount{Owner: owner}); err != nil {
+				t.Fatal(err)
+			}
+			if err := ctx.SaveChanges(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if count := countAccounts(t, billing); count != 2 {
+			t.Errorf("billing sees %d accounts inside the transaction, want both saves", count)
+		}
+		if count := countAccounts(t, reader); count != 0 {
+			t.Errorf("a context outside the transaction sees %d uncommitted accounts", count)
+		}
+
+		want := int64(2)
+		if commit {
+			if err := tx.Commit().Error; err != nil {
+				t.Fatal(err)
+			}
+		} else {
+			tx.Rollback()
+			want = 0
+		}
+		orders.UseTransaction(nil)
+		billing.UseTransaction(nil)
+		if billing.CurrentTransaction() != nil {
+			t.Error("UseTransaction(nil) left the context enlisted")
+		}
+		if count := countAccounts(t, reader); count != want {
+			t.Errorf("commit %v: %d accounts, want %d", commit, count, want)
+		}
+		// Detached contexts use their own connections again
+		if count := countAccounts(t, billing); count != want {
+			t.Errorf("commit %v: detached context sees %d accounts, want %d", commit, count, want)
+		}
+	}
+}
+
+func TestUseTransactionFailedSaveKeepsTransactionUsable(t *testing.T) {
+	orders, billing, reader := newSharedContexts(t)
+	if err := reader.GetDB().Create(&transferAccount{Id: 1, Owner: "taken"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	tx := orders.BeginTransaction()
+	if err := billing.UseTransaction(tx); err != nil {
+		t.Fatal(err)
+	}
+	defer billing.UseTransaction(nil)
+
+	if err := tx.Create(&transferAccount{Owner: "before"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gontext.NewLinqDbSet[transferAccount](billing).Add(transferAccount{Id: 1, Owner: "duplicate"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := billing.SaveChanges(); !errors.Is(err, gontext.ErrUniqueViolation) {
+		t.Fatalf("SaveChanges() = %v, want ErrUniqueViolation", err)
+	}
+	// The save rolled back to its savepoint; the owner's writes survive and the transaction goes on
+	if err := tx.Create(&transferAccount{Owner: "after"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit().Error; err != nil {
+		t.Fatal(err)
+	}
+	if count := countAccounts(t, reader); count != 3 {
+		t.Errorf("%d accounts after the commit, want the seed and the owner's two", count)
+	}
+}
+
+func TestUseSQLTransaction(t *testing.T) {
+	orders, billing, reader := newSharedContexts(t)
+	sqlDB, err := orders.GetDB().DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := sqlDB.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := billing.UseSQLTransaction(tx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec(`INSERT INTO transfer_accounts (owner, balance) VALUES ('raw', 1)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gontext.NewLinqDbSet[transferAccount](billing).Add(transferAccount{Owner: "tracked"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := billing.SaveChanges(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	billing.UseSQLTransaction(nil)
+	if count := countAccounts(t, reader); count != 0 {
+		t.Errorf("%d accounts after the rollback, want the raw and tracked inserts undone together", count)
+	}
+}
+
+func TestUseTransactionNeedsATransaction(t *testing.T) {
+	orders, billing, _ := newSharedContexts(t)
+	if err := billing.UseTransaction(orders.GetDB()); err == nil {
+		t.Error("UseTransaction accepted a handle that is not a transaction")
+	}
+	if billing.CurrentTransaction() != nil {
+		t.Error("a rejected UseTransaction enlisted the context")
+	}
+}
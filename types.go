@@ -0,0 +1,57 @@
+package gontext
+
+import (
+	"database/sql/driver"
+	"reflect"
+
+	"github.com/shepherrrd/gontext/internal/types"
+)
+
+// TypeSerializer is the gorm serializer of fields stored through a TypeMapping's Encode and Decode:
+// Price Money `gorm:"serializer:gontext"`
+const TypeSerializer = types.SerializerName
+
+// TypeMapping describes how values of a custom Go type T are stored; see RegisterType
+type TypeMapping[T any] struct {
+	// ColumnTypes is the column type per driver: "postgres", "mysql" or "sqlite"
+	ColumnTypes map[string]string
+	// ColumnType is used for drivers missing from ColumnTypes
+	ColumnType string
+	// Encode and Decode store a T that implements neither driver.Valuer nor sql.Scanner; its fields
+	// need the tag gorm:"serializer:gontext". Set both or neither
+	Encode func(value T) (driver.Value, error)
+	Decode func(src interface{}) (T, error)
+	// Equal reports whether two values are the same for change tracking (default: equal encoded values)
+	Equal func(a, b T) bool
+}
+
+// RegisterType maps a custom type - a driver.Valuer/sql.Scanner or any T with Encode and Decode - so
+// migrations create its column type instead of TEXT, Where conditions send its encoded value and change
+// tracking compares it as a value. Register types before entities that use them
+//
+//	gontext.RegisterType(gontext.TypeMapping[decimal.Decimal]{
+//	    ColumnTypes: map[string]string{"postgres": "NUMERIC(20,4)", "mysql": "DECIMAL(20,4)"},
+//	    Equal:       func(a, b decimal.Decimal) bool { return a.Equal(b) },
+//	})
+func RegisterType[T any](mapping TypeMapping[T]) {
+	registered := types.Mapping{
+		ColumnTypes: mapping.ColumnTypes,
+		ColumnType:  mapping.ColumnType,
+	}
+	if mapping.Encode != nil {
+		registered.Encode = func(value interface{}) (driver.Value, error) {
+			return mapping.Encode(value.(T))
+		}
+	}
+	if mapping.Decode != nil {
+		registered.Decode = func(src interface{}) (interface{}, error) {
+			return mapping.Decode(src)
+		}
+	}
+	if mapping.Equal != nil {
+		registered.Equal = func(a, b interface{}) bool {
+			return mapping.Equal(a.(T), b.(T))
+		}
+	}
+	types.Register(reflect.TypeOf((*T)(nil)).Elem(), registered)
+}
@@ -0,0 +1,120 @@
+package gontext_test
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/shepherrrd/gontext"
+)
+
+type validatedAccount struct {
+	Id    int
+	Email string
+	Age   int
+}
+
+// Validate has a pointer receiver, so it must be found for entities tracked by value too
+func (a *validatedAccount) Validate() error {
+	if !strings.Contains(a.Email, "@") {
+		return errors.New("email is invalid")
+	}
+	return nil
+}
+
+// ageValidator stands in for a struct-tag validator, reporting field violations without an entity name
+type ageValidator struct{}
+
+func (ageValidator) ValidateStruct(entity interface{}) error {
+	account, ok := entity.(*validatedAccount)
+	if !ok || account.Age >= 18 {
+		return nil
+	}
+	return &gontext.ValidationError{Violations: []gontext.ValidationViolation{{Field: "Age", Message: "must be at least 18"}}}
+}
+
+func newValidatedContext(t *testing.T) (*gontext.DbContext, *gontext.LinqDbSet[validatedAccount]) {
+	t.Helper()
+	ctx, err := gontext.NewDbContext(":memory:", "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ctx.Close() })
+	accounts := gontext.RegisterEntity[validatedAccount](ctx)
+	if err := ctx.EnsureCreated(); err != nil {
+		t.Fatal(err)
+	}
+	ctx.SetValidator(ageValidator{})
+	return ctx, accounts
+}
+
+func TestSaveChangesAggregatesViolations(t *testing.T) {
+	ctx, accounts := newValidatedContext(t)
+	if err := ctx.GetDB().Create(&validatedAccount{Id: 1, Email: "ada@example.com", Age: 36}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := accounts.Where("Id", 1).FirstOrDefault()
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded.Age = 12 // Modified into an invalid state
+	for _, account := range []validatedAccount{
+		{Email: "grace@example.com", Age: 40}, // Valid
+		{Email: "no-at-sign", Age: 16},        // Both rules fail
+	} {
+		if _, err := accounts.Add(account); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = ctx.SaveChanges()
+	var validationErr *gontext.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("SaveChanges() = %v, want a *ValidationError", err)
+	}
+	var got []string
+	for _, violation := range validationErr.Violations {
+		got = append(got, violation.Entity+"."+violation.Field+": "+violation.Message)
+	}
+	want := []string{
+		"validatedAccount.Age: must be at least 18",
+		"validatedAccount.: email is invalid",
+		"validatedAccount.Age: must be at least 18",
+	}
+	sort.Strings(got)
+	sort.Strings(want)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("violations %q, want %q", got, want)
+	}
+	if !strings.HasPrefix(err.Error(), "validation failed: ") || !strings.Contains(err.Error(), "validatedAccount: email is invalid") {
+		t.Errorf("Error() = %q", err.Error())
+	}
+
+	// Nothing was written, not even the valid entity
+	var rows []validatedAccount
+	if err := ctx.GetDB().Order("id").Find(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].Age != 36 {
+		t.Errorf("rows after the failed save %+v, want only the untouched seed", rows)
+	}
+}
+
+func TestSaveChangesSkipsValidationOfDeletes(t *testing.T) {
+	ctx, accounts := newValidatedContext(t)
+	invalid := validatedAccount{Id: 1, Email: "legacy", Age: 3}
+	if err := ctx.GetDB().Create(&invalid).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	accounts.Remove(invalid)
+	if err := ctx.SaveChanges(); err != nil {
+		t.Fatalf("SaveChanges() = %v, want an invalid row deletable", err)
+	}
+	if count, err := accounts.Count(); err != nil || count != 0 {
+		t.Errorf("%d accounts left, %v; want the invalid one deleted", count, err)
+	}
+}
@@ -0,0 +1,18 @@
+package gontext
+
+import (
+	"github.com/shepherrrd/gontext/internal/context"
+	"github.com/shepherrrd/gontext/internal/models"
+)
+
+// ViewBuilder configures a materialized view declared with EntityTypeBuilder.ToMaterializedView
+type ViewBuilder = models.ViewBuilder
+
+// Views refreshes materialized views; see DbContext.Views
+type Views = context.Views
+
+// RefreshOption changes how Views.Refresh recomputes a materialized view
+type RefreshOption = context.RefreshOption
+
+// Concurrently refreshes a materialized view without blocking its readers; the view needs a unique index
+const Concurrently = context.Concurrently